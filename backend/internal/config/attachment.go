@@ -0,0 +1,41 @@
+package config
+
+import "time"
+
+// AttachmentConfig 附件配置
+type AttachmentConfig struct {
+	Enabled bool `mapstructure:"enabled"` // 是否启用附件，未启用时不注册附件相关的路由
+	// MaxSize 单个附件的最大字节数
+	MaxSize int64 `mapstructure:"max_size"`
+	// AllowedTypes 允许上传的 MIME 类型，按文件内容识别，支持 image/* 形式的通配
+	AllowedTypes []string `mapstructure:"allowed_types"`
+	// URLTTL 下载链接的有效期
+	URLTTL time.Duration `mapstructure:"url_ttl"`
+	// CleanupInterval 清理已删除的附件文件的间隔
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+
+	Store string           `mapstructure:"store"` // 文件存储：local, s3
+	Local LocalStoreConfig `mapstructure:"local"` // 本地文件系统
+	S3    S3StoreConfig    `mapstructure:"s3"`    // S3 兼容的对象存储
+}
+
+// LocalStoreConfig 本地文件系统存储配置，文件通过服务自身的 /files 路由下载
+type LocalStoreConfig struct {
+	Path string `mapstructure:"path"` // 保存文件的目录
+	// SigningKey 下载链接的签名密钥，为空时每次启动随机生成，重启后之前的链接失效
+	SigningKey string `mapstructure:"signing_key"`
+	// BaseURL 下载链接的前缀，例如 https://api.example.com，为空时返回以 /files 开头的相对地址
+	BaseURL string `mapstructure:"base_url"`
+}
+
+// S3StoreConfig S3 兼容的对象存储配置，下载链接为对象存储的预签名地址
+type S3StoreConfig struct {
+	Endpoint        string `mapstructure:"endpoint"` // 例如 https://s3.us-east-1.amazonaws.com 或 http://localhost:9000
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	// PathStyle 使用 endpoint/bucket/key 形式的地址，MinIO 等自建服务通常需要开启
+	PathStyle bool          `mapstructure:"path_style"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
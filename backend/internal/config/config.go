@@ -2,18 +2,26 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config 应用程序配置
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	CORS     CORSConfig     `mapstructure:"cors"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Supabase SupabaseConfig `mapstructure:"supabase"`
-	Logger   LoggerConfig   `mapstructure:"logger"`
+	Server      ServerConfig     `mapstructure:"server"`
+	CORS        CORSConfig       `mapstructure:"cors"`
+	Database    DatabaseConfig   `mapstructure:"database"`
+	Supabase    SupabaseConfig   `mapstructure:"supabase"`
+	Logger      LoggerConfig     `mapstructure:"logger"`
+	Validation  ValidationConfig `mapstructure:"validation"`
+	Reminders   ReminderConfig   `mapstructure:"reminders"`
+	Attachments AttachmentConfig `mapstructure:"attachments"`
+	Sharing     SharingConfig    `mapstructure:"sharing"`
 }
 
 // ServerConfig 服务器配置
@@ -21,6 +29,9 @@ type ServerConfig struct {
 	Port int    `mapstructure:"port"`
 	Host string `mapstructure:"host"`
 	Mode string `mapstructure:"mode"`
+
+	// LegacySunset 旧版 POST 路由计划下线的日期，格式为 2006-01-02，为空时不发送 Sunset 响应头
+	LegacySunset string `mapstructure:"legacy_sunset"`
 }
 
 // CORSConfig CORS 配置
@@ -28,16 +39,41 @@ type CORSConfig struct {
 	AllowedOrigins []string `mapstructure:"allowed_origins"`
 	AllowedMethods []string `mapstructure:"allowed_methods"`
 	AllowedHeaders []string `mapstructure:"allowed_headers"`
+	ExposedHeaders []string `mapstructure:"exposed_headers"`
 }
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
+	Type     string `mapstructure:"type"` // 存储后端：memory, supabase, postgres, sqlite
 	Driver   string `mapstructure:"driver"`
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	User     string `mapstructure:"user"`
 	Password string `mapstructure:"password"`
 	DBName   string `mapstructure:"dbname"`
+	SSLMode  string `mapstructure:"sslmode"`
+	Path     string `mapstructure:"path"` // SQLite 数据库文件路径
+
+	// OperationTimeout 单次仓库操作的超时时间，为 0 时使用默认值
+	OperationTimeout time.Duration `mapstructure:"operation_timeout"`
+
+	// AutoMigrate 启动时自动执行未执行的迁移（postgres）
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+
+	// 连接池配置
+	MaxConns        int32         `mapstructure:"max_conns"`          // 最大连接数
+	MinConns        int32         `mapstructure:"min_conns"`          // 最小空闲连接数
+	MaxConnLifetime time.Duration `mapstructure:"max_conn_lifetime"`  // 连接最长存活时间
+	MaxConnIdleTime time.Duration `mapstructure:"max_conn_idle_time"` // 连接最长空闲时间
+	ConnectTimeout  time.Duration `mapstructure:"connect_timeout"`    // 建立连接超时时间
+
+	Memory MemoryConfig `mapstructure:"memory"` // 内存存储配置
+}
+
+// MemoryConfig 内存存储配置
+type MemoryConfig struct {
+	SnapshotPath     string        `mapstructure:"snapshot_path"`     // 快照文件路径，为空时不持久化
+	SnapshotInterval time.Duration `mapstructure:"snapshot_interval"` // 定期保存快照的间隔，为 0 时只在关闭时保存
 }
 
 // LoggerConfig 日志配置
@@ -78,6 +114,39 @@ func LoadConfig(path string) (*Config, error) {
 	v.AutomaticEnv()
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
+	// 设置默认值，未配置存储后端时沿用 Supabase，同时让 APP_DATABASE_TYPE 环境变量生效
+	v.SetDefault("database.type", "supabase")
+	v.SetDefault("database.path", "data/todos.db")
+	// 参数校验的默认规则
+	v.SetDefault("validation.todo.title_min_length", 1)
+	v.SetDefault("validation.todo.title_max_length", 200)
+	v.SetDefault("validation.todo.trim_title", true)
+	v.SetDefault("validation.todo.notes_max_length", 10000)
+	v.SetDefault("validation.todo.list_default_limit", 50)
+	v.SetDefault("validation.todo.list_max_limit", 200)
+	// 提醒调度器的默认参数，调度器本身默认不启动
+	v.SetDefault("reminders.interval", "15s")
+	v.SetDefault("reminders.lease", "1m")
+	v.SetDefault("reminders.batch_size", 50)
+	v.SetDefault("reminders.max_attempts", 5)
+	v.SetDefault("reminders.retry_backoff", "30s")
+	v.SetDefault("reminders.in_app.enabled", true)
+	v.SetDefault("reminders.webhook.timeout", "10s")
+	v.SetDefault("reminders.smtp.port", 587)
+	v.SetDefault("reminders.smtp.timeout", "10s")
+	// 附件的默认限制，附件本身默认不启用
+	v.SetDefault("attachments.max_size", 10<<20)
+	v.SetDefault("attachments.allowed_types", []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain"})
+	v.SetDefault("attachments.url_ttl", "15m")
+	v.SetDefault("attachments.cleanup_interval", "1m")
+	v.SetDefault("attachments.store", "local")
+	v.SetDefault("attachments.local.path", "data/attachments")
+	v.SetDefault("attachments.s3.region", "us-east-1")
+	v.SetDefault("attachments.s3.timeout", "30s")
+
+	v.SetDefault("sharing.invitation_ttl", "168h")
+	v.SetDefault("sharing.accept_without_email", false)
+
 	// 读取配置文件
 	if err := v.ReadInConfig(); err != nil {
 		// 如果找不到配置文件，尝试加载默认配置
@@ -99,3 +168,36 @@ func LoadConfig(path string) (*Config, error) {
 func (c *Config) GetServerAddress() string {
 	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
 }
+
+// PostgresDSN 根据数据库配置生成 PostgreSQL 连接串
+func (d *DatabaseConfig) PostgresDSN() string {
+	host := d.Host
+	if host == "" {
+		host = "localhost"
+	}
+	port := d.Port
+	if port == 0 {
+		port = 5432
+	}
+	sslMode := d.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	query := url.Values{}
+	query.Set("sslmode", sslMode)
+	// connect_timeout 以秒为单位，0 表示一直等待，不足一秒的部分向上取整
+	if d.ConnectTimeout > 0 {
+		seconds := int((d.ConnectTimeout + time.Second - 1) / time.Second)
+		query.Set("connect_timeout", strconv.Itoa(seconds))
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/" + d.DBName,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
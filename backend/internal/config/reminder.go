@@ -0,0 +1,43 @@
+package config
+
+import "time"
+
+// ReminderConfig 提醒调度器配置
+type ReminderConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`       // 是否启动提醒调度器
+	Interval     time.Duration `mapstructure:"interval"`      // 扫描到期提醒的间隔
+	Lease        time.Duration `mapstructure:"lease"`         // 领取提醒后的租约时长，超时未确认的提醒会被其他实例重新领取
+	BatchSize    int           `mapstructure:"batch_size"`    // 每次最多领取的提醒数量
+	MaxAttempts  int           `mapstructure:"max_attempts"`  // 发送失败时最多尝试的次数（包含第一次），超过后放弃
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"` // 发送失败后第一次重试前的等待时间，之后每次翻倍
+
+	InApp   InAppNotifierConfig   `mapstructure:"in_app"`  // 站内通知
+	Webhook WebhookNotifierConfig `mapstructure:"webhook"` // Webhook 通知
+	SMTP    SMTPNotifierConfig    `mapstructure:"smtp"`    // 邮件通知
+}
+
+// InAppNotifierConfig 站内通知配置
+type InAppNotifierConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// WebhookNotifierConfig Webhook 通知配置，URL 为空时不启用
+type WebhookNotifierConfig struct {
+	URL     string        `mapstructure:"url"`
+	Secret  string        `mapstructure:"secret"` // 非空时使用 HMAC-SHA256 对请求体签名
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// SMTPNotifierConfig 邮件通知配置，Host 为空时不启用
+type SMTPNotifierConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"` // 为空时不进行认证
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+	// StartTLS 要求使用 STARTTLS，本地测试用的 SMTP 服务器通常不支持
+	StartTLS bool          `mapstructure:"starttls"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	// Recipients 用户 ID 到邮箱地址的映射，未列出的用户通过 Supabase Auth 管理接口查询
+	Recipients map[string]string `mapstructure:"recipients"`
+}
@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// SharingConfig 共享和邀请配置
+type SharingConfig struct {
+	// InvitationTTL 邀请的有效期，过期后不能再接受
+	InvitationTTL time.Duration `mapstructure:"invitation_ttl"`
+	// AcceptURL 前端接受邀请的页面，令牌作为 token 查询参数附加在后面，
+	// 例如 https://app.example.com/invitations/accept，为空时邮件和响应中只包含令牌
+	AcceptURL string `mapstructure:"accept_url"`
+	// AcceptWithoutEmail 允许访问令牌中没有 email 的用户只凭邀请令牌接受邀请。
+	// 默认关闭，此时无法确认接受者是否是被邀请的邮箱，接受邀请返回 403
+	AcceptWithoutEmail bool `mapstructure:"accept_without_email"`
+}
@@ -1,12 +1,24 @@
 package config
 
+import "time"
+
 // SupabaseConfig Supabase 配置
 type SupabaseConfig struct {
-	ProjectID      string `mapstructure:"project_id"`       // 项目 ID
-	BaseURL        string `mapstructure:"base_url"`         // 项目 URL（不包含协议）
-	ServiceRoleKey string `mapstructure:"service_role_key"` // 服务角色密钥
-	AnonKey        string `mapstructure:"anon_key"`         // 匿名密钥
-	JWTSecret      string `mapstructure:"jwt_secret"`       // JWT 密钥
+	ProjectID      string      `mapstructure:"project_id"`       // 项目 ID
+	BaseURL        string      `mapstructure:"base_url"`         // 项目 URL（不包含协议）
+	ServiceRoleKey string      `mapstructure:"service_role_key"` // 服务角色密钥
+	AnonKey        string      `mapstructure:"anon_key"`         // 匿名密钥
+	JWTSecret      string      `mapstructure:"jwt_secret"`       // JWT 密钥
+	Retry          RetryConfig `mapstructure:"retry"`            // 请求重试策略
+}
+
+// RetryConfig 重试策略配置，未设置的字段使用默认值
+type RetryConfig struct {
+	MaxAttempts    int           `mapstructure:"max_attempts"`    // 最多尝试次数（包含第一次），1 表示不重试
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // 第一次重试前的等待时间
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`     // 单次等待时间上限
+	Multiplier     float64       `mapstructure:"multiplier"`      // 每次重试等待时间的增长倍数
+	Jitter         float64       `mapstructure:"jitter"`          // 随机抖动比例，0~1
 }
 
 func (c *Config) GetSupabaseConfig() *SupabaseConfig {
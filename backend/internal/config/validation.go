@@ -0,0 +1,20 @@
+package config
+
+// ValidationConfig 请求参数校验配置
+type ValidationConfig struct {
+	Todo TodoValidationConfig `mapstructure:"todo"` // 待办事项
+}
+
+// TodoValidationConfig 待办事项的校验规则，长度按字符数计算
+type TodoValidationConfig struct {
+	TitleMinLength    int    `mapstructure:"title_min_length"`    // 标题最小长度
+	TitleMaxLength    int    `mapstructure:"title_max_length"`    // 标题最大长度
+	TrimTitle         bool   `mapstructure:"trim_title"`          // 校验前去掉标题首尾空白
+	ForbiddenChars    string `mapstructure:"forbidden_chars"`     // 标题中不允许出现的字符
+	AllowControlChars bool   `mapstructure:"allow_control_chars"` // 是否允许换行、制表符等控制字符
+
+	NotesMaxLength int `mapstructure:"notes_max_length"` // 备注最大长度
+
+	ListDefaultLimit int `mapstructure:"list_default_limit"` // 列表未指定 limit 时每页的条数
+	ListMaxLimit     int `mapstructure:"list_max_limit"`     // 列表每页最多的条数
+}
@@ -1,15 +1,18 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"net/http"
+
+	"github.com/Brower/backend/internal/i18n"
 )
 
 // ErrorCode 定义错误码类型
 type ErrorCode int
 
+// 系统级错误码 (1000-1999)
 const (
-	// 系统级错误码 (1000-1999)
 	ErrInternal ErrorCode = 1000 + iota
 	ErrInvalidParams
 	ErrUnauthorized
@@ -17,47 +20,72 @@ const (
 	ErrNotFound
 	ErrTimeout
 	ErrTooManyRequests
-	
-	// 业务级错误码 (2000-2999)
+)
+
+// 业务级错误码 (2000-2999)，错误码对客户端公开，只能追加不能修改
+const (
 	ErrTodoNotFound ErrorCode = 2000 + iota
 	ErrTodoAlreadyExists
 	ErrInvalidTodoStatus
+	ErrNotificationNotFound
+	ErrTagNotFound
+	ErrTagAlreadyExists
+	ErrProjectNotFound
+	ErrInboxProject
+	ErrSubtaskNotFound
+	ErrAttachmentNotFound
+	ErrAttachmentTooLarge
+	ErrAttachmentType
+	ErrCommentNotFound
+	ErrShareNotFound
+	ErrInvitationNotFound
+	ErrWorkspaceNotFound
+	ErrMemberNotFound
+	ErrMemberAlreadyExists
+	ErrLastWorkspaceOwner
 )
 
 // Error 自定义错误类型
 type Error struct {
-	Code    ErrorCode   `json:"code"`    // 错误码
-	Message string      `json:"message"` // 错误消息
-	Err     error      `json:"-"`       // 原始错误
-	Data    any        `json:"data,omitempty"` // 附加数据
+	Code    ErrorCode `json:"code"`           // 错误码
+	Message string    `json:"message"`        // 错误消息
+	Err     error     `json:"-"`              // 原始错误
+	Data    any       `json:"data,omitempty"` // 附加数据
+
+	// key 本地化的详细消息，为空时使用错误码对应的消息
+	key MessageKey
+	// custom 为 true 时 Message 是调用方传入的固定文本，不做本地化
+	custom bool
 }
 
 // 错误码与HTTP状态码的映射
 var errorHTTPStatusMap = map[ErrorCode]int{
-	ErrInternal:         http.StatusInternalServerError,
-	ErrInvalidParams:    http.StatusBadRequest,
-	ErrUnauthorized:     http.StatusUnauthorized,
-	ErrForbidden:        http.StatusForbidden,
-	ErrNotFound:         http.StatusNotFound,
-	ErrTimeout:          http.StatusGatewayTimeout,
-	ErrTooManyRequests:  http.StatusTooManyRequests,
-	ErrTodoNotFound:     http.StatusNotFound,
-	ErrTodoAlreadyExists: http.StatusConflict,
-	ErrInvalidTodoStatus: http.StatusBadRequest,
-}
-
-// 错误码消息映射
-var errorMessageMap = map[ErrorCode]string{
-	ErrInternal:         "内部服务器错误",
-	ErrInvalidParams:    "无效的参数",
-	ErrUnauthorized:     "未授权",
-	ErrForbidden:        "禁止访问",
-	ErrNotFound:         "资源未找到",
-	ErrTimeout:          "请求超时",
-	ErrTooManyRequests:  "请求过于频繁",
-	ErrTodoNotFound:     "待办事项未找到",
-	ErrTodoAlreadyExists: "待办事项已存在",
-	ErrInvalidTodoStatus: "无效的待办事项状态",
+	ErrInternal:             http.StatusInternalServerError,
+	ErrInvalidParams:        http.StatusBadRequest,
+	ErrUnauthorized:         http.StatusUnauthorized,
+	ErrForbidden:            http.StatusForbidden,
+	ErrNotFound:             http.StatusNotFound,
+	ErrTimeout:              http.StatusGatewayTimeout,
+	ErrTooManyRequests:      http.StatusTooManyRequests,
+	ErrTodoNotFound:         http.StatusNotFound,
+	ErrTodoAlreadyExists:    http.StatusConflict,
+	ErrInvalidTodoStatus:    http.StatusBadRequest,
+	ErrNotificationNotFound: http.StatusNotFound,
+	ErrTagNotFound:          http.StatusNotFound,
+	ErrTagAlreadyExists:     http.StatusConflict,
+	ErrProjectNotFound:      http.StatusNotFound,
+	ErrInboxProject:         http.StatusConflict,
+	ErrSubtaskNotFound:      http.StatusNotFound,
+	ErrAttachmentNotFound:   http.StatusNotFound,
+	ErrAttachmentTooLarge:   http.StatusRequestEntityTooLarge,
+	ErrAttachmentType:       http.StatusUnsupportedMediaType,
+	ErrCommentNotFound:      http.StatusNotFound,
+	ErrShareNotFound:        http.StatusNotFound,
+	ErrInvitationNotFound:   http.StatusNotFound,
+	ErrWorkspaceNotFound:    http.StatusNotFound,
+	ErrMemberNotFound:       http.StatusNotFound,
+	ErrMemberAlreadyExists:  http.StatusConflict,
+	ErrLastWorkspaceOwner:   http.StatusConflict,
 }
 
 func (e *Error) Error() string {
@@ -67,20 +95,36 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("错误码: %d, 消息: %s", e.Code, e.Message)
 }
 
+// Unwrap 返回原始错误，便于使用标准库 errors.Is/As 判断
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
 // New 创建新的错误
 func New(code ErrorCode, err error) *Error {
 	return &Error{
 		Code:    code,
-		Message: errorMessageMap[code],
+		Message: Message(code, i18n.DefaultLocale),
 		Err:     err,
 	}
 }
 
-// NewWithMessage 创建带自定义消息的错误
+// NewWithMessage 创建带自定义消息的错误，消息不会被本地化，
+// 需要本地化时使用 NewWithKey
 func NewWithMessage(code ErrorCode, message string) *Error {
 	return &Error{
 		Code:    code,
 		Message: message,
+		custom:  true,
+	}
+}
+
+// NewWithKey 创建带本地化详细消息的错误
+func NewWithKey(code ErrorCode, key MessageKey) *Error {
+	return &Error{
+		Code:    code,
+		Message: Detail(key, i18n.DefaultLocale),
+		key:     key,
 	}
 }
 
@@ -88,7 +132,7 @@ func NewWithMessage(code ErrorCode, message string) *Error {
 func NewWithData(code ErrorCode, data any) *Error {
 	return &Error{
 		Code:    code,
-		Message: errorMessageMap[code],
+		Message: Message(code, i18n.DefaultLocale),
 		Data:    data,
 	}
 }
@@ -101,18 +145,55 @@ func (e *Error) GetHTTPStatus() int {
 	return http.StatusInternalServerError
 }
 
+// LocalizedMessage 返回指定语言的错误消息，错误码保持不变
+func (e *Error) LocalizedMessage(locale i18n.Locale) string {
+	switch {
+	case e.custom:
+		return e.Message
+	case e.key != "":
+		return Detail(e.key, locale)
+	default:
+		return Message(e.Code, locale)
+	}
+}
+
+// Is 等同于标准库 errors.Is，便于导入本包的代码直接使用
+func Is(err, target error) bool {
+	return stderrors.Is(err, target)
+}
+
+// As 从错误链中取出 *Error，不存在时返回 false
+func As(err error) (*Error, bool) {
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// From 将任意错误转换为 *Error，无法识别的错误统一视为内部错误
+func From(err error) *Error {
+	if e, ok := As(err); ok {
+		return e
+	}
+	return New(ErrInternal, err)
+}
+
 // IsNotFound 判断是否为未找到错误
 func IsNotFound(err error) bool {
-	if e, ok := err.(*Error); ok {
-		return e.Code == ErrNotFound || e.Code == ErrTodoNotFound
+	if e, ok := As(err); ok {
+		return e.Code == ErrNotFound || e.Code == ErrTodoNotFound || e.Code == ErrNotificationNotFound ||
+			e.Code == ErrTagNotFound || e.Code == ErrProjectNotFound || e.Code == ErrSubtaskNotFound ||
+			e.Code == ErrAttachmentNotFound || e.Code == ErrCommentNotFound || e.Code == ErrShareNotFound ||
+			e.Code == ErrInvitationNotFound || e.Code == ErrWorkspaceNotFound || e.Code == ErrMemberNotFound
 	}
 	return false
 }
 
 // IsInvalidParams 判断是否为参数无效错误
 func IsInvalidParams(err error) bool {
-	if e, ok := err.(*Error); ok {
+	if e, ok := As(err); ok {
 		return e.Code == ErrInvalidParams
 	}
 	return false
-} 
\ No newline at end of file
+}
@@ -0,0 +1,133 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+// allCodes 返回所有已定义的错误码，两个区间都是连续的
+func allCodes() []ErrorCode {
+	var codes []ErrorCode
+	for code := ErrInternal; code <= ErrTooManyRequests; code++ {
+		codes = append(codes, code)
+	}
+	for code := ErrTodoNotFound; code <= ErrLastWorkspaceOwner; code++ {
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+func TestGetHTTPStatus(t *testing.T) {
+	// 错误码对客户端公开，这里逐个列出期望的状态码，新增错误码时必须补充
+	want := map[ErrorCode]int{
+		ErrInternal:             http.StatusInternalServerError,
+		ErrInvalidParams:        http.StatusBadRequest,
+		ErrUnauthorized:         http.StatusUnauthorized,
+		ErrForbidden:            http.StatusForbidden,
+		ErrNotFound:             http.StatusNotFound,
+		ErrTimeout:              http.StatusGatewayTimeout,
+		ErrTooManyRequests:      http.StatusTooManyRequests,
+		ErrTodoNotFound:         http.StatusNotFound,
+		ErrTodoAlreadyExists:    http.StatusConflict,
+		ErrInvalidTodoStatus:    http.StatusBadRequest,
+		ErrNotificationNotFound: http.StatusNotFound,
+		ErrTagNotFound:          http.StatusNotFound,
+		ErrTagAlreadyExists:     http.StatusConflict,
+		ErrProjectNotFound:      http.StatusNotFound,
+		ErrInboxProject:         http.StatusConflict,
+		ErrSubtaskNotFound:      http.StatusNotFound,
+		ErrAttachmentNotFound:   http.StatusNotFound,
+		ErrAttachmentTooLarge:   http.StatusRequestEntityTooLarge,
+		ErrAttachmentType:       http.StatusUnsupportedMediaType,
+		ErrCommentNotFound:      http.StatusNotFound,
+		ErrShareNotFound:        http.StatusNotFound,
+		ErrInvitationNotFound:   http.StatusNotFound,
+		ErrWorkspaceNotFound:    http.StatusNotFound,
+		ErrMemberNotFound:       http.StatusNotFound,
+		ErrMemberAlreadyExists:  http.StatusConflict,
+		ErrLastWorkspaceOwner:   http.StatusConflict,
+	}
+
+	codes := allCodes()
+	if len(want) != len(codes) {
+		t.Errorf("test covers %d codes, %d are defined", len(want), len(codes))
+	}
+	for _, code := range codes {
+		status, ok := want[code]
+		if !ok {
+			t.Errorf("code %d has no expected status in this test", code)
+			continue
+		}
+		if _, ok := errorHTTPStatusMap[code]; !ok {
+			t.Errorf("code %d is missing from errorHTTPStatusMap", code)
+		}
+		if got := New(code, nil).GetHTTPStatus(); got != status {
+			t.Errorf("GetHTTPStatus(%d) = %d, want %d", code, got, status)
+		}
+	}
+
+	if got := (&Error{Code: 9999}).GetHTTPStatus(); got != http.StatusInternalServerError {
+		t.Errorf("GetHTTPStatus(unknown) = %d, want %d", got, http.StatusInternalServerError)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	cause := stderrors.New("连接被拒绝")
+	typed := New(ErrTodoNotFound, cause)
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode ErrorCode
+		wantSame bool
+	}{
+		{"typed", typed, ErrTodoNotFound, true},
+		{"wrapped typed", fmt.Errorf("获取待办事项: %w", typed), ErrTodoNotFound, true},
+		{"untyped", cause, ErrInternal, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Code != tt.wantCode {
+				t.Errorf("From().Code = %d, want %d", got.Code, tt.wantCode)
+			}
+			if (got == typed) != tt.wantSame {
+				t.Errorf("From() returned the original error = %v, want %v", got == typed, tt.wantSame)
+			}
+			if !stderrors.Is(got, cause) {
+				t.Errorf("From() lost the cause %v", cause)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	notFound := map[ErrorCode]bool{
+		ErrNotFound:             true,
+		ErrTodoNotFound:         true,
+		ErrNotificationNotFound: true,
+		ErrTagNotFound:          true,
+		ErrProjectNotFound:      true,
+		ErrSubtaskNotFound:      true,
+		ErrAttachmentNotFound:   true,
+		ErrCommentNotFound:      true,
+		ErrShareNotFound:        true,
+		ErrInvitationNotFound:   true,
+		ErrWorkspaceNotFound:    true,
+		ErrMemberNotFound:       true,
+	}
+	for _, code := range allCodes() {
+		if got := IsNotFound(New(code, nil)); got != notFound[code] {
+			t.Errorf("IsNotFound(%d) = %v, want %v", code, got, notFound[code])
+		}
+		// 所有“未找到”类错误都应该映射为 404
+		if notFound[code] && New(code, nil).GetHTTPStatus() != http.StatusNotFound {
+			t.Errorf("code %d is a not-found error but maps to %d", code, New(code, nil).GetHTTPStatus())
+		}
+	}
+	if IsNotFound(stderrors.New("其他错误")) {
+		t.Error("IsNotFound(untyped) = true, want false")
+	}
+}
@@ -0,0 +1,225 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/Brower/backend/internal/i18n"
+)
+
+// MessageKey 详细错误消息的键，用于同一错误码下更具体的提示
+type MessageKey string
+
+// 详细错误消息的键
+const (
+	MsgInvalidUserID     MessageKey = "invalid_user_id"
+	MsgMissingAuthHeader MessageKey = "missing_auth_header"
+	MsgInvalidAuthHeader MessageKey = "invalid_auth_header"
+
+	// 字段校验规则的提示，部分消息带有格式化参数
+	MsgFieldRequired       MessageKey = "field_required"
+	MsgFieldTooShort       MessageKey = "field_too_short"       // 参数：最小长度
+	MsgFieldTooLong        MessageKey = "field_too_long"        // 参数：最大长度
+	MsgFieldForbiddenChars MessageKey = "field_forbidden_chars" // 参数：不允许的字符
+	MsgFieldControlChars   MessageKey = "field_control_chars"
+	MsgFieldInvalidType    MessageKey = "field_invalid_type"
+	MsgInvalidJSON         MessageKey = "invalid_json"
+	MsgFieldInteger        MessageKey = "field_integer"
+	MsgFieldRange          MessageKey = "field_range" // 参数：最小值、最大值
+	MsgFieldBoolean        MessageKey = "field_boolean"
+	MsgFieldDateTime       MessageKey = "field_datetime"
+	MsgFieldEnum           MessageKey = "field_enum" // 参数：允许的值
+	MsgInvalidCursor       MessageKey = "invalid_cursor"
+	MsgFieldTimezone       MessageKey = "field_timezone"
+	MsgFieldRRule          MessageKey = "field_rrule"
+	MsgRecurrenceNeedsDue  MessageKey = "recurrence_needs_due"
+	MsgMoveTarget          MessageKey = "move_target"
+	MsgMoveSelf            MessageKey = "move_self"
+	MsgFieldColor          MessageKey = "field_color"
+	MsgFieldTooMany        MessageKey = "field_too_many" // 参数：最大个数
+	MsgMoveProjectSelf     MessageKey = "move_project_self"
+	MsgCascadeMoveTo       MessageKey = "cascade_move_to"
+	MsgMoveToDeleted       MessageKey = "move_to_deleted"
+	MsgMoveSubtaskSelf     MessageKey = "move_subtask_self"
+	MsgNotCommentAuthor    MessageKey = "not_comment_author"
+	MsgFieldEmail          MessageKey = "field_email"
+	MsgShareEditor         MessageKey = "share_editor"
+	MsgShareOwner          MessageKey = "share_owner"
+	MsgInvitationEmail     MessageKey = "invitation_email"
+	MsgInvitationNoEmail   MessageKey = "invitation_no_email"
+	MsgInvitationOwner     MessageKey = "invitation_owner"
+	MsgWorkspaceRole       MessageKey = "workspace_role"
+	MsgWorkspaceAdmin      MessageKey = "workspace_admin"
+	MsgWorkspaceMismatch   MessageKey = "workspace_mismatch"
+	MsgFieldUUID           MessageKey = "field_uuid"
+)
+
+// errorMessages 各语言下错误码对应的消息，新增错误码时必须补全所有语言
+var errorMessages = map[i18n.Locale]map[ErrorCode]string{
+	i18n.LocaleZH: {
+		ErrInternal:             "内部服务器错误",
+		ErrInvalidParams:        "无效的参数",
+		ErrUnauthorized:         "未授权",
+		ErrForbidden:            "禁止访问",
+		ErrNotFound:             "资源未找到",
+		ErrTimeout:              "请求超时",
+		ErrTooManyRequests:      "请求过于频繁",
+		ErrTodoNotFound:         "待办事项未找到",
+		ErrTodoAlreadyExists:    "待办事项已存在",
+		ErrInvalidTodoStatus:    "无效的待办事项状态",
+		ErrNotificationNotFound: "通知未找到",
+		ErrTagNotFound:          "标签未找到",
+		ErrTagAlreadyExists:     "同名标签已存在",
+		ErrProjectNotFound:      "项目未找到",
+		ErrInboxProject:         "收件箱不能删除、归档或移动",
+		ErrSubtaskNotFound:      "子任务未找到",
+		ErrAttachmentNotFound:   "附件未找到",
+		ErrAttachmentTooLarge:   "附件超过大小限制",
+		ErrAttachmentType:       "不允许上传该类型的文件",
+		ErrCommentNotFound:      "评论未找到",
+		ErrShareNotFound:        "共享未找到",
+		ErrInvitationNotFound:   "邀请不存在、已过期或已被接受",
+		ErrWorkspaceNotFound:    "工作区未找到",
+		ErrMemberNotFound:       "工作区成员未找到",
+		ErrMemberAlreadyExists:  "用户已经是工作区的成员",
+		ErrLastWorkspaceOwner:   "工作区至少需要一个所有者",
+	},
+	i18n.LocaleEN: {
+		ErrInternal:             "Internal server error",
+		ErrInvalidParams:        "Invalid parameters",
+		ErrUnauthorized:         "Unauthorized",
+		ErrForbidden:            "Forbidden",
+		ErrNotFound:             "Resource not found",
+		ErrTimeout:              "Request timed out",
+		ErrTooManyRequests:      "Too many requests",
+		ErrTodoNotFound:         "Todo not found",
+		ErrTodoAlreadyExists:    "Todo already exists",
+		ErrInvalidTodoStatus:    "Invalid todo status",
+		ErrNotificationNotFound: "Notification not found",
+		ErrTagNotFound:          "Tag not found",
+		ErrTagAlreadyExists:     "A tag with this name already exists",
+		ErrProjectNotFound:      "Project not found",
+		ErrInboxProject:         "The inbox cannot be deleted, archived or moved",
+		ErrSubtaskNotFound:      "Subtask not found",
+		ErrAttachmentNotFound:   "Attachment not found",
+		ErrAttachmentTooLarge:   "Attachment exceeds the size limit",
+		ErrAttachmentType:       "This file type is not allowed",
+		ErrCommentNotFound:      "Comment not found",
+		ErrShareNotFound:        "Share not found",
+		ErrInvitationNotFound:   "Invitation not found, expired or already accepted",
+		ErrWorkspaceNotFound:    "Workspace not found",
+		ErrMemberNotFound:       "Workspace member not found",
+		ErrMemberAlreadyExists:  "User is already a member of the workspace",
+		ErrLastWorkspaceOwner:   "A workspace must have at least one owner",
+	},
+}
+
+// detailMessages 各语言下详细错误消息
+var detailMessages = map[i18n.Locale]map[MessageKey]string{
+	i18n.LocaleZH: {
+		MsgInvalidUserID:       "用户 ID 类型错误",
+		MsgMissingAuthHeader:   "缺少认证头",
+		MsgInvalidAuthHeader:   "认证头格式错误",
+		MsgFieldRequired:       "不能为空",
+		MsgFieldTooShort:       "长度不能少于 %d 个字符",
+		MsgFieldTooLong:        "长度不能超过 %d 个字符",
+		MsgFieldForbiddenChars: "不能包含字符 %s",
+		MsgFieldControlChars:   "不能包含控制字符",
+		MsgFieldInvalidType:    "类型错误",
+		MsgInvalidJSON:         "请求体不是合法的 JSON",
+		MsgFieldInteger:        "必须是整数",
+		MsgFieldRange:          "必须在 %d 到 %d 之间",
+		MsgFieldBoolean:        "必须是 true 或 false",
+		MsgFieldDateTime:       "必须是 RFC 3339 格式的时间，例如 2024-01-02T15:04:05Z",
+		MsgFieldEnum:           "必须是以下值之一: %s",
+		MsgInvalidCursor:       "游标无效或与当前排序方式不匹配",
+		MsgFieldTimezone:       "必须是 IANA 时区名称，例如 Asia/Shanghai",
+		MsgFieldRRule:          "必须是 RFC 5545 格式的重复规则，FREQ 支持 DAILY、WEEKLY、MONTHLY、YEARLY，例如 FREQ=WEEKLY;BYDAY=MO",
+		MsgRecurrenceNeedsDue:  "设置重复规则时必须设置截止时间",
+		MsgMoveTarget:          "before 和 after 必须且只能提供一个",
+		MsgMoveSelf:            "不能相对于待办事项自身移动",
+		MsgFieldColor:          "必须是 #RRGGBB 格式的颜色，例如 #ff8800",
+		MsgFieldTooMany:        "不能超过 %d 个",
+		MsgMoveProjectSelf:     "不能相对于项目自身移动",
+		MsgCascadeMoveTo:       "cascade 为 true 时不能指定",
+		MsgMoveToDeleted:       "不能是被删除的项目",
+		MsgMoveSubtaskSelf:     "不能相对于子任务自身移动",
+		MsgNotCommentAuthor:    "只能修改或删除自己的评论",
+		MsgFieldEmail:          "必须是合法的邮箱地址",
+		MsgShareEditor:         "共享给你的角色只能查看，不能修改",
+		MsgShareOwner:          "只有所有者可以执行该操作",
+		MsgInvitationEmail:     "邀请是发给其他邮箱的",
+		MsgInvitationNoEmail:   "访问令牌中没有邮箱，无法确认你是被邀请的用户",
+		MsgInvitationOwner:     "你已经是所有者，不需要接受邀请",
+		MsgWorkspaceRole:       "你在工作区中的角色不能执行该操作",
+		MsgWorkspaceAdmin:      "只有工作区的所有者可以管理所有者和管理员",
+		MsgWorkspaceMismatch:   "请求头中的工作区与路径中的不一致",
+		MsgFieldUUID:           "必须是 UUID 格式的 ID",
+	},
+	i18n.LocaleEN: {
+		MsgInvalidUserID:       "Invalid user ID type",
+		MsgMissingAuthHeader:   "Authorization header is required",
+		MsgInvalidAuthHeader:   "Invalid authorization header format",
+		MsgFieldRequired:       "is required",
+		MsgFieldTooShort:       "must be at least %d characters",
+		MsgFieldTooLong:        "must be at most %d characters",
+		MsgFieldForbiddenChars: "must not contain %s",
+		MsgFieldControlChars:   "must not contain control characters",
+		MsgFieldInvalidType:    "has an invalid type",
+		MsgInvalidJSON:         "Request body is not valid JSON",
+		MsgFieldInteger:        "must be an integer",
+		MsgFieldRange:          "must be between %d and %d",
+		MsgFieldBoolean:        "must be true or false",
+		MsgFieldDateTime:       "must be an RFC 3339 timestamp, e.g. 2024-01-02T15:04:05Z",
+		MsgFieldEnum:           "must be one of: %s",
+		MsgInvalidCursor:       "Cursor is invalid or does not match the current sort order",
+		MsgFieldTimezone:       "must be an IANA time zone name, e.g. America/New_York",
+		MsgFieldRRule:          "must be an RFC 5545 recurrence rule with FREQ of DAILY, WEEKLY, MONTHLY or YEARLY, e.g. FREQ=WEEKLY;BYDAY=MO",
+		MsgRecurrenceNeedsDue:  "is required for repeating todos",
+		MsgMoveTarget:          "exactly one of before and after is required",
+		MsgMoveSelf:            "must not be the todo being moved",
+		MsgFieldColor:          "must be a color in #RRGGBB format, e.g. #ff8800",
+		MsgFieldTooMany:        "must contain at most %d items",
+		MsgMoveProjectSelf:     "must not be the project being moved",
+		MsgCascadeMoveTo:       "must not be set when cascade is true",
+		MsgMoveToDeleted:       "must not be the project being deleted",
+		MsgMoveSubtaskSelf:     "must not be the subtask being moved",
+		MsgNotCommentAuthor:    "Only the author can edit or delete a comment",
+		MsgFieldEmail:          "must be a valid email address",
+		MsgShareEditor:         "You have view-only access",
+		MsgShareOwner:          "Only an owner can do this",
+		MsgInvitationEmail:     "This invitation was sent to a different email address",
+		MsgInvitationNoEmail:   "Your access token has no email, so the invitation cannot be verified",
+		MsgInvitationOwner:     "You already own this and do not need to accept the invitation",
+		MsgWorkspaceRole:       "Your workspace role does not allow this",
+		MsgWorkspaceAdmin:      "Only a workspace owner can manage owners and admins",
+		MsgWorkspaceMismatch:   "The workspace in the header does not match the one in the path",
+		MsgFieldUUID:           "must be a UUID",
+	},
+}
+
+// Message 返回错误码在指定语言下的消息，缺少翻译时依次回退到默认语言和通用内部错误
+func Message(code ErrorCode, locale i18n.Locale) string {
+	if msg, ok := errorMessages[locale][code]; ok {
+		return msg
+	}
+	if msg, ok := errorMessages[i18n.DefaultLocale][code]; ok {
+		return msg
+	}
+	return Message(ErrInternal, locale)
+}
+
+// Detail 返回详细消息在指定语言下的文本，缺少翻译时回退到默认语言
+func Detail(key MessageKey, locale i18n.Locale) string {
+	if msg, ok := detailMessages[locale][key]; ok {
+		return msg
+	}
+	if msg, ok := detailMessages[i18n.DefaultLocale][key]; ok {
+		return msg
+	}
+	return string(key)
+}
+
+// Detailf 返回格式化后的详细消息
+func Detailf(key MessageKey, locale i18n.Locale, args ...any) string {
+	return fmt.Sprintf(Detail(key, locale), args...)
+}
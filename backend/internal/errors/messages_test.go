@@ -0,0 +1,116 @@
+package errors
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"testing"
+
+	"github.com/Brower/backend/internal/i18n"
+)
+
+// messageKeys 从 messages.go 中取出所有 MessageKey 常量的值，新增的键无需在测试中登记
+func messageKeys(t *testing.T) []MessageKey {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "messages.go", nil, 0)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	var keys []MessageKey
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if ident, ok := value.Type.(*ast.Ident); !ok || ident.Name != "MessageKey" {
+				continue
+			}
+			for _, v := range value.Values {
+				lit := v.(*ast.BasicLit)
+				keys = append(keys, MessageKey(lit.Value[1:len(lit.Value)-1]))
+			}
+		}
+	}
+	if len(keys) == 0 {
+		t.Fatal("no MessageKey constants found in messages.go")
+	}
+	return keys
+}
+
+// verbs 匹配格式化动词，两种语言的消息必须使用相同的参数
+var verbs = regexp.MustCompile(`%[a-z]`)
+
+func TestErrorMessagesComplete(t *testing.T) {
+	for _, locale := range i18n.Supported() {
+		messages, ok := errorMessages[locale]
+		if !ok {
+			t.Errorf("errorMessages has no entry for locale %q", locale)
+			continue
+		}
+		for _, code := range allCodes() {
+			if messages[code] == "" {
+				t.Errorf("errorMessages[%q] is missing code %d", locale, code)
+			}
+		}
+		if len(messages) != len(allCodes()) {
+			t.Errorf("errorMessages[%q] has %d entries, want %d", locale, len(messages), len(allCodes()))
+		}
+	}
+}
+
+func TestDetailMessagesComplete(t *testing.T) {
+	keys := messageKeys(t)
+	for _, locale := range i18n.Supported() {
+		messages, ok := detailMessages[locale]
+		if !ok {
+			t.Errorf("detailMessages has no entry for locale %q", locale)
+			continue
+		}
+		for _, key := range keys {
+			msg := messages[key]
+			if msg == "" {
+				t.Errorf("detailMessages[%q] is missing key %q", locale, key)
+				continue
+			}
+			want := verbs.FindAllString(detailMessages[i18n.DefaultLocale][key], -1)
+			if got := verbs.FindAllString(msg, -1); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("detailMessages[%q][%q] verbs = %v, want %v", locale, key, got, want)
+			}
+		}
+		if len(messages) != len(keys) {
+			t.Errorf("detailMessages[%q] has %d entries, want %d", locale, len(messages), len(keys))
+		}
+	}
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    *Error
+		locale i18n.Locale
+		want   string
+	}{
+		{"code zh", New(ErrTodoNotFound, nil), i18n.LocaleZH, "待办事项未找到"},
+		{"code en", New(ErrTodoNotFound, nil), i18n.LocaleEN, "Todo not found"},
+		{"key en", NewWithKey(ErrInvalidParams, MsgInvalidJSON), i18n.LocaleEN, "Request body is not valid JSON"},
+		{"custom message is not translated", NewWithMessage(ErrInvalidParams, "自定义"), i18n.LocaleEN, "自定义"},
+		{"unsupported locale falls back to default", New(ErrTodoNotFound, nil), "fr", "待办事项未找到"},
+		{"unknown code falls back to internal error", &Error{Code: 9999}, i18n.LocaleEN, "Internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.LocalizedMessage(tt.locale); got != tt.want {
+				t.Errorf("LocalizedMessage(%q) = %q, want %q", tt.locale, got, tt.want)
+			}
+		})
+	}
+
+	if got := Detail("no_such_key", i18n.LocaleEN); got != "no_such_key" {
+		t.Errorf("Detail(unknown) = %q, want the key itself", got)
+	}
+}
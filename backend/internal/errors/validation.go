@@ -0,0 +1,56 @@
+package errors
+
+// Violation 单个字段违反的校验规则
+type Violation struct {
+	Field   string `json:"field"`   // 字段名，与请求体中的 JSON 字段一致
+	Rule    string `json:"rule"`    // 规则名，例如 required、max_length
+	Message string `json:"message"` // 本地化的提示消息
+}
+
+// ValidationData 参数校验失败时 Error.Data 的内容
+type ValidationData struct {
+	Violations []Violation `json:"violations"`
+}
+
+// NewValidation 创建参数校验失败的错误，Data 中列出所有违反的规则
+func NewValidation(violations ...Violation) *Error {
+	return NewWithData(ErrInvalidParams, ValidationData{Violations: violations})
+}
+
+// Violations 返回参数校验错误中的所有违反规则，不是校验错误时返回 nil
+func Violations(err error) []Violation {
+	e, ok := As(err)
+	if !ok {
+		return nil
+	}
+	data, ok := e.Data.(ValidationData)
+	if !ok {
+		return nil
+	}
+	return data.Violations
+}
+
+// 校验规则名，对客户端公开，前端可以据此显示对应的提示
+const (
+	RuleRequired       = "required"
+	RuleMinLength      = "min_length"
+	RuleMaxLength      = "max_length"
+	RuleForbiddenChars = "forbidden_chars"
+	RuleControlChars   = "control_chars"
+	RuleType           = "type"
+	RuleJSON           = "json"
+	RuleInteger        = "integer"
+	RuleRange          = "range"
+	RuleBoolean        = "boolean"
+	RuleDateTime       = "datetime"
+	RuleEnum           = "enum"
+	RuleCursor         = "cursor"
+	RuleTimezone       = "timezone"
+	RuleRRule          = "rrule"
+	RuleMoveTarget     = "move_target"
+	RuleColor          = "color"
+	RuleMaxItems       = "max_items"
+	RuleExclusive      = "exclusive"
+	RuleEmail          = "email"
+	RuleUUID           = "uuid"
+)
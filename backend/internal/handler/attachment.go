@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentFormField 上传附件时 multipart 请求体中文件所在的字段
+const attachmentFormField = "file"
+
+// AttachmentHandler 处理附件相关的HTTP请求
+type AttachmentHandler struct {
+	service service.AttachmentService
+}
+
+func NewAttachmentHandler(service service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes 在版本化的路由组（例如 /api/v1）下注册路由。
+// 附件路由挂在 /todos/:id 下，参数名与待办事项的路由保持一致。
+func (h *AttachmentHandler) RegisterRoutes(r *gin.RouterGroup) {
+	attachments := r.Group("/todos/:id/attachments")
+	{
+		attachments.GET("", h.List)
+		attachments.POST("", h.Upload)
+		attachments.GET("/:attachmentId", h.Get)
+		attachments.DELETE("/:attachmentId", h.Delete)
+		attachments.GET("/:attachmentId/download", h.Download)
+	}
+}
+
+// List 获取待办事项的所有附件
+func (h *AttachmentHandler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service.List(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// Upload 上传附件。请求体为 multipart/form-data，文件在 file 字段中；
+// 文件内容直接从请求体流式读取，不会先缓存到临时文件
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	// 请求体不是 multipart 或没有 file 字段时 Body 为空，由校验返回 file 必填
+	var req models.UploadAttachmentRequest
+	if reader, err := c.Request.MultipartReader(); err == nil {
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			if part.FormName() == attachmentFormField {
+				req.Filename = part.FileName()
+				req.Body = part
+				break
+			}
+		}
+	}
+
+	attachment, err := h.service.Upload(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// Get 获取附件的元数据和下载链接
+func (h *AttachmentHandler) Get(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	attachment, err := h.service.Get(c.Request.Context(), userID, c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// Download 重定向到附件的下载链接
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	attachment, err := h.service.Get(c.Request.Context(), userID, c.Param("id"), c.Param("attachmentId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, attachment.URL)
+}
+
+// Delete 删除附件
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("id"), c.Param("attachmentId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CommentHandler 处理评论相关的HTTP请求
+type CommentHandler struct {
+	service service.CommentService
+}
+
+func NewCommentHandler(service service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes 在版本化的路由组（例如 /api/v1）下注册路由。
+// 评论路由挂在 /todos/:id 下，参数名与待办事项的路由保持一致。
+func (h *CommentHandler) RegisterRoutes(r *gin.RouterGroup) {
+	comments := r.Group("/todos/:id/comments")
+	{
+		comments.GET("", h.List)
+		comments.POST("", h.Create)
+		comments.PATCH("/:commentId", h.Update)
+		comments.DELETE("/:commentId", h.Delete)
+	}
+}
+
+// List 按创建时间正序分页获取待办事项的评论
+func (h *CommentHandler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ListCommentsRequest
+	_ = c.ShouldBindQuery(&req)
+
+	comments, err := h.service.List(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// Create 给待办事项添加评论
+func (h *CommentHandler) Create(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	comment, err := h.service.Create(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// Update 修改自己发表的评论
+func (h *CommentHandler) Update(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCommentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	comment, err := h.service.Update(c.Request.Context(), userID, c.Param("id"), c.Param("commentId"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// Delete 删除自己发表的评论
+func (h *CommentHandler) Delete(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("id"), c.Param("commentId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
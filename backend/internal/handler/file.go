@@ -0,0 +1,72 @@
+package handler
+
+import (
+	stderrors "errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// FileHandler 通过签名链接下载本地存储中的附件，链接本身就是凭证，不需要认证
+type FileHandler struct {
+	store *storage.LocalStore
+}
+
+func NewFileHandler(store *storage.LocalStore) *FileHandler {
+	return &FileHandler{
+		store: store,
+	}
+}
+
+// RegisterRoutes 在根路由下注册 storage.FilesPath 路由，不经过认证中间件
+func (h *FileHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET(storage.FilesPath+"/*key", h.Download)
+	r.HEAD(storage.FilesPath+"/*key", h.Download)
+}
+
+// Download 校验签名后返回文件。下载的类型和文件名以链接中签名的参数为准，
+// 总是以附件形式下载，并禁止浏览器猜测类型，避免上传的文件在本站以页面形式打开
+func (h *FileHandler) Download(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	opts, err := h.store.Verify(key, c.Request.URL.Query(), time.Now())
+	if err != nil {
+		c.Error(errors.New(errors.ErrForbidden, err))
+		return
+	}
+
+	file, err := h.store.Open(key)
+	if err != nil {
+		if stderrors.Is(err, fs.ErrNotExist) {
+			c.Error(errors.New(errors.ErrAttachmentNotFound, err))
+			return
+		}
+		c.Error(errors.New(errors.ErrInternal, err))
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		c.Error(errors.New(errors.ErrInternal, err))
+		return
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := c.Writer.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": opts.Filename}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "private, max-age=0")
+
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime(), file)
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler 处理站内通知相关的HTTP请求
+type NotificationHandler struct {
+	service service.NotificationService
+}
+
+func NewNotificationHandler(service service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes 在版本化的路由组（例如 /api/v1）下注册路由
+func (h *NotificationHandler) RegisterRoutes(r *gin.RouterGroup) {
+	notifications := r.Group("/notifications")
+	{
+		notifications.GET("", h.List)
+		notifications.POST("/read-all", h.MarkAllRead)
+		notifications.POST("/:id/read", h.MarkRead)
+	}
+}
+
+// List 分页获取站内通知，unread=true 时只返回未读通知
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ListNotificationsRequest
+	_ = c.ShouldBindQuery(&req)
+
+	notifications, err := h.service.List(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkRead 将一条通知标记为已读
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	notification, err := h.service.MarkRead(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// MarkAllRead 将所有未读通知标记为已读
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ProjectHandler 处理项目相关的HTTP请求
+type ProjectHandler struct {
+	service service.ProjectService
+	// basePath 项目资源的路径，用于生成 Location 响应头
+	basePath string
+}
+
+func NewProjectHandler(service service.ProjectService) *ProjectHandler {
+	return &ProjectHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes 在版本化的路由组（例如 /api/v1）下注册路由
+func (h *ProjectHandler) RegisterRoutes(r *gin.RouterGroup) {
+	projects := r.Group("/projects")
+	h.basePath = projects.BasePath()
+	{
+		projects.GET("", h.List)
+		projects.POST("", h.Create)
+		projects.GET("/:id", h.Get)
+		projects.PATCH("/:id", h.Update)
+		projects.DELETE("/:id", h.Delete)
+		projects.POST("/:id/move", h.Move)
+		projects.POST("/:id/todos", h.MoveTodos)
+	}
+}
+
+// List 获取当前用户的项目
+func (h *ProjectHandler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ListProjectsRequest
+	_ = c.ShouldBindQuery(&req)
+
+	projects, err := h.service.List(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+// Get 获取单个项目
+func (h *ProjectHandler) Get(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	project, err := h.service.Get(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// Create 创建项目
+func (h *ProjectHandler) Create(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateProjectRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	project, err := h.service.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if h.basePath != "" {
+		c.Header("Location", resourceLocation(c, h.basePath, project.ID))
+	}
+	c.JSON(http.StatusCreated, project)
+}
+
+// Update 部分更新项目，包括归档和取消归档
+func (h *ProjectHandler) Update(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProjectRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	project, err := h.service.Update(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// Move 把项目移动到另一个项目之前或之后
+func (h *ProjectHandler) Move(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MoveProjectRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	project, err := h.service.Move(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, project)
+}
+
+// Delete 删除项目，查询参数决定项目中的待办事项被删除还是移动
+func (h *ProjectHandler) Delete(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.DeleteProjectRequest
+	_ = c.ShouldBindQuery(&req)
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("id"), req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// MoveTodos 把多个待办事项移动到项目中
+func (h *ProjectHandler) MoveTodos(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MoveTodosRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.MoveTodos(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ShareHandler 处理共享和邀请相关的HTTP请求
+type ShareHandler struct {
+	service service.ShareService
+}
+
+func NewShareHandler(service service.ShareService) *ShareHandler {
+	return &ShareHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes 在版本化的路由组（例如 /api/v1）下注册路由。
+// 待办事项和项目的共享路由分别挂在 /todos/:id 和 /projects/:id 下，处理方式相同。
+func (h *ShareHandler) RegisterRoutes(r *gin.RouterGroup) {
+	for _, resource := range []struct {
+		path string
+		typ  models.ShareResourceType
+	}{
+		{"/todos/:id", models.ShareTodo},
+		{"/projects/:id", models.ShareProject},
+	} {
+		group := r.Group(resource.path)
+		{
+			group.GET("/shares", h.list(resource.typ))
+			group.POST("/shares", h.invite(resource.typ))
+			group.DELETE("/shares/:shareId", h.revokeShare(resource.typ))
+			group.DELETE("/invitations/:invitationId", h.revokeInvitation(resource.typ))
+		}
+	}
+
+	r.POST("/invitations/accept", h.Accept)
+	r.GET("/shared", h.SharedWithMe)
+}
+
+// list 获取资源的共享和还没有接受的邀请
+func (h *ShareHandler) list(resourceType models.ShareResourceType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := getUserID(c)
+		if !ok {
+			return
+		}
+
+		shares, err := h.service.List(c.Request.Context(), userID, resourceType, c.Param("id"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, shares)
+	}
+}
+
+// invite 通过邮箱邀请用户共享资源
+func (h *ShareHandler) invite(resourceType models.ShareResourceType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := getUserID(c)
+		if !ok {
+			return
+		}
+
+		var req models.CreateInvitationRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+
+		invitation, err := h.service.Invite(c.Request.Context(), userID, resourceType, c.Param("id"), req)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, invitation)
+	}
+}
+
+// revokeShare 撤销一条共享
+func (h *ShareHandler) revokeShare(resourceType models.ShareResourceType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := getUserID(c)
+		if !ok {
+			return
+		}
+
+		if err := h.service.RevokeShare(c.Request.Context(), userID, resourceType, c.Param("id"), c.Param("shareId")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// revokeInvitation 撤销还没有接受的邀请
+func (h *ShareHandler) revokeInvitation(resourceType models.ShareResourceType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := getUserID(c)
+		if !ok {
+			return
+		}
+
+		if err := h.service.RevokeInvitation(c.Request.Context(), userID, resourceType, c.Param("id"), c.Param("invitationId")); err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// Accept 接受邀请，访问令牌中的邮箱必须与邀请的邮箱相同
+func (h *ShareHandler) Accept(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AcceptInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	share, err := h.service.Accept(c.Request.Context(), userID, c.GetString("email"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, share)
+}
+
+// SharedWithMe 获取共享给当前用户的待办事项和项目
+func (h *ShareHandler) SharedWithMe(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	shares, err := h.service.SharedWithMe(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, shares)
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// SubtaskHandler 处理子任务相关的HTTP请求，修改子任务的接口都返回整个清单和所属的待办事项
+type SubtaskHandler struct {
+	service service.SubtaskService
+}
+
+func NewSubtaskHandler(service service.SubtaskService) *SubtaskHandler {
+	return &SubtaskHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes 在版本化的路由组（例如 /api/v1）下注册路由。
+// 子任务路由挂在 /todos/:id 下，参数名与待办事项的路由保持一致。
+func (h *SubtaskHandler) RegisterRoutes(r *gin.RouterGroup) {
+	subtasks := r.Group("/todos/:id/subtasks")
+	{
+		subtasks.GET("", h.List)
+		subtasks.POST("", h.Create)
+		subtasks.PATCH("/:subtaskId", h.Update)
+		subtasks.DELETE("/:subtaskId", h.Delete)
+		subtasks.POST("/:subtaskId/toggle", h.Toggle)
+		subtasks.POST("/:subtaskId/move", h.Move)
+	}
+}
+
+// List 获取待办事项的子任务清单
+func (h *SubtaskHandler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	checklist, err := h.service.List(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checklist)
+}
+
+// Create 给待办事项添加子任务
+func (h *SubtaskHandler) Create(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateSubtaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	checklist, err := h.service.Create(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checklist)
+}
+
+// Update 部分更新子任务的标题和完成状态
+func (h *SubtaskHandler) Update(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSubtaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	checklist, err := h.service.Update(c.Request.Context(), userID, c.Param("id"), c.Param("subtaskId"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checklist)
+}
+
+// Toggle 切换子任务的完成状态
+func (h *SubtaskHandler) Toggle(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	checklist, err := h.service.Toggle(c.Request.Context(), userID, c.Param("id"), c.Param("subtaskId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checklist)
+}
+
+// Move 把子任务移动到另一个子任务之前或之后
+func (h *SubtaskHandler) Move(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.MoveSubtaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	checklist, err := h.service.Move(c.Request.Context(), userID, c.Param("id"), c.Param("subtaskId"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checklist)
+}
+
+// Delete 删除子任务
+func (h *SubtaskHandler) Delete(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	checklist, err := h.service.Delete(c.Request.Context(), userID, c.Param("id"), c.Param("subtaskId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checklist)
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// TagHandler 处理标签相关的HTTP请求
+type TagHandler struct {
+	service service.TagService
+	// basePath 标签资源的路径，用于生成 Location 响应头
+	basePath string
+}
+
+func NewTagHandler(service service.TagService) *TagHandler {
+	return &TagHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes 在版本化的路由组（例如 /api/v1）下注册路由。
+// 待办事项的标签路由挂在 /todos/:id 下，参数名与待办事项的路由保持一致。
+func (h *TagHandler) RegisterRoutes(r *gin.RouterGroup) {
+	tags := r.Group("/tags")
+	h.basePath = tags.BasePath()
+	{
+		tags.GET("", h.List)
+		tags.POST("", h.Create)
+		tags.GET("/:id", h.Get)
+		tags.PATCH("/:id", h.Update)
+		tags.DELETE("/:id", h.Delete)
+	}
+
+	todoTags := r.Group("/todos/:id/tags")
+	{
+		todoTags.PUT("", h.SetTodoTags)
+		todoTags.POST("/:tagId", h.AddTodoTag)
+		todoTags.DELETE("/:tagId", h.RemoveTodoTag)
+	}
+}
+
+// List 获取当前用户的所有标签
+func (h *TagHandler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// Get 获取单个标签
+func (h *TagHandler) Get(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	tag, err := h.service.Get(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Create 创建标签
+func (h *TagHandler) Create(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tag, err := h.service.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if h.basePath != "" {
+		c.Header("Location", resourceLocation(c, h.basePath, tag.ID))
+	}
+	c.JSON(http.StatusCreated, tag)
+}
+
+// Update 部分更新标签
+func (h *TagHandler) Update(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tag, err := h.service.Update(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tag)
+}
+
+// Delete 删除标签
+func (h *TagHandler) Delete(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// SetTodoTags 替换待办事项的所有标签
+func (h *TagHandler) SetTodoTags(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SetTodoTagsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	tags, err := h.service.SetTodoTags(c.Request.Context(), userID, c.Param("id"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// AddTodoTag 给待办事项添加一个标签
+func (h *TagHandler) AddTodoTag(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.service.AddTodoTag(c.Request.Context(), userID, c.Param("id"), c.Param("tagId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// RemoveTodoTag 去掉待办事项的一个标签
+func (h *TagHandler) RemoveTodoTag(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.service.RemoveTodoTag(c.Request.Context(), userID, c.Param("id"), c.Param("tagId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
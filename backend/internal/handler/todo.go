@@ -1,28 +1,24 @@
 package handler
 
 import (
+	"encoding/json"
+	stderrors "errors"
 	"net/http"
+	"net/url"
+	"strings"
 
-	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/i18n"
 	"github.com/Brower/backend/internal/models"
 	"github.com/Brower/backend/internal/service"
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 )
 
-// TodoService 定义了Todo服务的接口
-type TodoService interface {
-	GetAll() ([]models.Todo, error)
-	GetByID(id string) (*models.Todo, error)
-	Create(req models.CreateTodoRequest) (*models.Todo, error)
-	Update(id string, req models.UpdateTodoRequest) (*models.Todo, error)
-	Toggle(id string) (*models.Todo, error)
-	Delete(id string) error
-}
-
 // TodoHandler 处理Todo相关的HTTP请求
 type TodoHandler struct {
 	service service.TodoService
+	// basePath 待办事项资源的路径，用于生成 Location 响应头
+	basePath string
 }
 
 // Response 统一的响应结构
@@ -37,51 +33,124 @@ func NewTodoHandler(service service.TodoService) *TodoHandler {
 	}
 }
 
-// RegisterRoutes 注册路由
-func (h *TodoHandler) RegisterRoutes(r gin.IRouter) {
+// RegisterRoutes 在版本化的路由组（例如 /api/v1）下注册 REST 路由
+func (h *TodoHandler) RegisterRoutes(r *gin.RouterGroup) {
 	todos := r.Group("/todos")
+	h.basePath = todos.BasePath()
 	{
-		todos.POST("/list", h.List)
-		todos.POST("/get/:id", h.Get)
-		todos.POST("/create", h.Create)
-		todos.POST("/update/:id", h.Update)
-		todos.POST("/toggle/:id", h.Toggle)
-		todos.POST("/delete/:id", h.Delete)
+		todos.GET("", h.List)
+		todos.POST("", h.Create)
+		todos.GET("/search", h.Search)
+		todos.GET("/:id", h.Get)
+		todos.PATCH("/:id", h.Update)
+		todos.PUT("/:id", h.Replace)
+		todos.DELETE("/:id", h.Delete)
+		todos.POST("/:id/toggle", h.Toggle)
+		todos.PUT("/:id/recurrence", h.SetRecurrence)
+		todos.DELETE("/:id/recurrence", h.StopRecurrence)
+		todos.POST("/:id/move", h.Move)
 	}
 }
 
 // getUserID 从上下文中获取用户 ID
 func (h *TodoHandler) getUserID(c *gin.Context) (string, bool) {
+	return getUserID(c)
+}
+
+// getUserID 从上下文中获取认证中间件写入的用户 ID。
+// 错误通过 c.Error 交给 ErrorHandler 中间件统一渲染，处理器本身不写错误响应。
+func getUserID(c *gin.Context) (string, bool) {
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权访问"})
+		c.Error(errors.New(errors.ErrUnauthorized, nil))
 		return "", false
 	}
 
 	userIDStr, ok := userID.(string)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "用户 ID 类型错误"})
+		c.Error(errors.NewWithKey(errors.ErrInternal, errors.MsgInvalidUserID))
 		return "", false
 	}
 
 	return userIDStr, true
 }
 
-// List 获取所有待办事项
+// resourceLocation 生成新建资源的 Location 响应头。
+// 路由挂在 /workspaces/:workspaceId 下时，basePath 中的路径参数替换为请求中的工作区 ID
+func resourceLocation(c *gin.Context, basePath, id string) string {
+	if workspaceID := c.Param("workspaceId"); workspaceID != "" {
+		basePath = strings.Replace(basePath, ":workspaceId", url.PathEscape(workspaceID), 1)
+	}
+	return basePath + "/" + url.PathEscape(id)
+}
+
+// bindJSON 解析 JSON 请求体，失败时以参数校验错误的形式交给 ErrorHandler
+func bindJSON(c *gin.Context, obj any) bool {
+	err := c.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	locale := i18n.FromContext(c.Request.Context())
+
+	// 字段类型不匹配时可以定位到具体字段，其他情况视为整个请求体无效
+	violation := errors.Violation{
+		Field:   "body",
+		Rule:    errors.RuleJSON,
+		Message: errors.Detail(errors.MsgInvalidJSON, locale),
+	}
+	var typeErr *json.UnmarshalTypeError
+	if stderrors.As(err, &typeErr) && typeErr.Field != "" {
+		violation = errors.Violation{
+			Field:   typeErr.Field,
+			Rule:    errors.RuleType,
+			Message: errors.Detail(errors.MsgFieldInvalidType, locale),
+		}
+	}
+
+	validationErr := errors.NewValidation(violation)
+	validationErr.Err = err
+	c.Error(validationErr)
+	return false
+}
+
+// List 分页获取待办事项，支持过滤和排序
 func (h *TodoHandler) List(c *gin.Context) {
 	userID, ok := h.getUserID(c)
 	if !ok {
 		return
 	}
 
-	todos, err := h.service.List(userID)
+	// 查询参数在服务层校验，这里只按字符串读取，不会出错
+	var req models.ListTodosRequest
+	_ = c.ShouldBindQuery(&req)
+
+	todos, err := h.service.List(c.Request.Context(), userID, req)
 	if err != nil {
-		logger.Error("获取待办事项列表失败", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"items": todos})
+	c.JSON(http.StatusOK, todos)
+}
+
+// Search 按相关度搜索待办事项，分页方式与 List 相同
+func (h *TodoHandler) Search(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.SearchTodosRequest
+	_ = c.ShouldBindQuery(&req)
+
+	results, err := h.service.Search(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
 }
 
 // Get 获取单个待办事项
@@ -92,15 +161,13 @@ func (h *TodoHandler) Get(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少 ID 参数"})
-		return
-	}
 
-	todo, err := h.service.Get(userID, id)
+	var req models.GetTodoRequest
+	_ = c.ShouldBindQuery(&req)
+
+	todo, err := h.service.Get(c.Request.Context(), userID, id, req)
 	if err != nil {
-		logger.Error("获取待办事项失败", zap.String("id", id), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -115,22 +182,23 @@ func (h *TodoHandler) Create(c *gin.Context) {
 	}
 
 	var req models.CreateTodoRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	todo, err := h.service.Create(userID, req)
+	todo, err := h.service.Create(c.Request.Context(), userID, req)
 	if err != nil {
-		logger.Error("创建待办事项失败", zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
+	if h.basePath != "" {
+		c.Header("Location", resourceLocation(c, h.basePath, todo.ID))
+	}
 	c.JSON(http.StatusCreated, todo)
 }
 
-// Update 更新待办事项
+// Update 部分更新待办事项，只修改请求中出现的字段
 func (h *TodoHandler) Update(c *gin.Context) {
 	userID, ok := h.getUserID(c)
 	if !ok {
@@ -138,21 +206,38 @@ func (h *TodoHandler) Update(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少 ID 参数"})
+
+	var req models.UpdateTodoRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	var req models.UpdateTodoRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+	todo, err := h.service.Update(c.Request.Context(), userID, id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// Replace 整体替换待办事项，所有可写字段都必须提供
+func (h *TodoHandler) Replace(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+
+	var req models.ReplaceTodoRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	todo, err := h.service.Update(userID, id, req)
+	todo, err := h.service.Replace(c.Request.Context(), userID, id, req)
 	if err != nil {
-		logger.Error("更新待办事项失败", zap.String("id", id), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -167,40 +252,94 @@ func (h *TodoHandler) Toggle(c *gin.Context) {
 	}
 
 	id := c.Param("id")
-	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少 ID 参数"})
+
+	todo, err := h.service.Toggle(c.Request.Context(), userID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// SetRecurrence 设置或修改待办事项所属重复系列的规则
+func (h *TodoHandler) SetRecurrence(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+
+	var req models.RecurrenceRequest
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	todo, err := h.service.Toggle(userID, id)
+	todo, err := h.service.SetRecurrence(c.Request.Context(), userID, id, req)
 	if err != nil {
-		logger.Error("切换待办事项状态失败", zap.String("id", id), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, todo)
 }
 
-// Delete 删除待办事项
-func (h *TodoHandler) Delete(c *gin.Context) {
+// Move 把待办事项移动到另一个待办事项之前或之后
+func (h *TodoHandler) Move(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+
+	var req models.MoveTodoRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	todo, err := h.service.Move(c.Request.Context(), userID, id, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, todo)
+}
+
+// StopRecurrence 停止待办事项所属的重复系列
+func (h *TodoHandler) StopRecurrence(c *gin.Context) {
 	userID, ok := h.getUserID(c)
 	if !ok {
 		return
 	}
 
 	id := c.Param("id")
-	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少 ID 参数"})
+
+	todo, err := h.service.StopRecurrence(c.Request.Context(), userID, id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	err := h.service.Delete(userID, id)
+	c.JSON(http.StatusOK, todo)
+}
+
+// Delete 删除待办事项
+func (h *TodoHandler) Delete(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+
+	err := h.service.Delete(c.Request.Context(), userID, id)
 	if err != nil {
-		logger.Error("删除待办事项失败", zap.String("id", id), zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Brower/backend/internal/middleware"
+	"github.com/Brower/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterLegacyRoutes 注册旧版的 POST 路由，仅为兼容已有客户端保留。
+// 响应中带有 Deprecation 头，sunset 不为零时带有 Sunset 头，新代码请使用 RegisterRoutes 注册的 REST 路由。
+func (h *TodoHandler) RegisterLegacyRoutes(r *gin.RouterGroup, successor string, sunset time.Time) {
+	todos := r.Group("/todos")
+	todos.Use(middleware.Deprecated(successor, sunset))
+	{
+		todos.POST("/list", h.legacyList)
+		todos.POST("/get/:id", h.Get)
+		todos.POST("/create", h.Create)
+		todos.POST("/update/:id", h.Update)
+		todos.POST("/toggle/:id", h.Toggle)
+		todos.POST("/delete/:id", h.legacyDelete)
+	}
+}
+
+// legacyList 获取全部待办事项，沿用分页之前的响应格式。
+// 旧版客户端不会传回游标，这里按下一页的游标逐页读取，直到没有更多数据
+func (h *TodoHandler) legacyList(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	items := []models.TodoResponse{}
+	var req models.ListTodosRequest
+	for {
+		page, err := h.service.List(c.Request.Context(), userID, req)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		req.Cursor = page.NextCursor
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// legacyDelete 删除待办事项，沿用旧版返回消息体的响应格式
+func (h *TodoHandler) legacyDelete(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	id := c.Param("id")
+
+	err := h.service.Delete(c.Request.Context(), userID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
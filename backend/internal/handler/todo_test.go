@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/middleware"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+	"github.com/Brower/backend/internal/service"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// testSunset 测试中旧版路由的下线日期
+var testSunset = time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+
+// todoRouter 返回与 main.go 相同布局的路由，使用内存存储，请求固定以 user-1 的身份访问
+func todoRouter() *gin.Engine {
+	todos := service.NewTodoService(repository.NewInMemoryTodoRepository(), service.NewTodoValidator(config.TodoValidationConfig{}))
+
+	r := gin.New()
+	r.Use(middleware.Locale(), middleware.ErrorHandler())
+	api := r.Group("/api")
+	api.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+		c.Next()
+	})
+	v1 := api.Group("/v1")
+	NewTodoHandler(todos).RegisterLegacyRoutes(api, v1.BasePath()+"/todos", testSunset)
+	NewTodoHandler(todos).RegisterRoutes(v1)
+	return r
+}
+
+// serve 发送请求并返回响应
+func serve(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// createTodo 通过 REST 路由创建待办事项并返回其 ID
+func createTodo(t *testing.T, r http.Handler, title string) string {
+	t.Helper()
+
+	w := serve(r, http.MethodPost, "/api/v1/todos", `{"title":"`+title+`"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /api/v1/todos = %d %s, want 201", w.Code, w.Body)
+	}
+	var todo models.TodoResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &todo); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error = %v", w.Body, err)
+	}
+	return todo.ID
+}
+
+func TestTodoHandlerCreate(t *testing.T) {
+	r := todoRouter()
+
+	w := serve(r, http.MethodPost, "/api/v1/todos", `{"title":"写周报"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d %s, want 201", w.Code, w.Body)
+	}
+	var todo models.TodoResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &todo); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error = %v", w.Body, err)
+	}
+	if todo.ID == "" || todo.Title != "写周报" {
+		t.Errorf("body = %s, want the created todo", w.Body)
+	}
+	if got, want := w.Header().Get("Location"), "/api/v1/todos/"+todo.ID; got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+
+	// Location 指向的资源可以直接读取
+	w = serve(r, http.MethodGet, "/api/v1/todos/"+todo.ID, "")
+	if w.Code != http.StatusOK {
+		t.Errorf("GET Location = %d %s, want 200", w.Code, w.Body)
+	}
+}
+
+func TestTodoHandlerDelete(t *testing.T) {
+	r := todoRouter()
+	id := createTodo(t, r, "删除我")
+
+	w := serve(r, http.MethodDelete, "/api/v1/todos/"+id, "")
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("DELETE = %d %s, want 204 without body", w.Code, w.Body)
+	}
+
+	w = serve(r, http.MethodDelete, "/api/v1/todos/"+id, "")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("DELETE again = %d %s, want 404", w.Code, w.Body)
+	}
+	var resp middleware.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != int(errors.ErrTodoNotFound) {
+		t.Errorf("body = %s, want code %d", w.Body, errors.ErrTodoNotFound)
+	}
+}
+
+func TestTodoHandlerMalformedJSON(t *testing.T) {
+	r := todoRouter()
+	id := createTodo(t, r, "原标题")
+
+	tests := []struct {
+		name      string
+		method    string
+		path      string
+		body      string
+		wantField string
+		wantRule  string
+	}{
+		{"create syntax error", http.MethodPost, "/api/v1/todos", `{"title":`, "body", errors.RuleJSON},
+		{"create wrong type", http.MethodPost, "/api/v1/todos", `{"title":42}`, "title", errors.RuleType},
+		{"update syntax error", http.MethodPatch, "/api/v1/todos/" + id, `not json`, "body", errors.RuleJSON},
+		{"legacy create syntax error", http.MethodPost, "/api/todos/create", `{`, "body", errors.RuleJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(r, tt.method, tt.path, tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d %s, want 400", w.Code, w.Body)
+			}
+			var resp struct {
+				Code int                   `json:"code"`
+				Data errors.ValidationData `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", w.Body, err)
+			}
+			if resp.Code != int(errors.ErrInvalidParams) || len(resp.Data.Violations) != 1 {
+				t.Fatalf("body = %s, want one violation", w.Body)
+			}
+			if v := resp.Data.Violations[0]; v.Field != tt.wantField || v.Rule != tt.wantRule || v.Message == "" {
+				t.Errorf("violation = %+v, want field %q rule %q", v, tt.wantField, tt.wantRule)
+			}
+		})
+	}
+
+	// 请求被拒绝后数据保持不变
+	w := serve(r, http.MethodGet, "/api/v1/todos/"+id, "")
+	if !strings.Contains(w.Body.String(), "原标题") {
+		t.Errorf("GET after rejected update = %s, want unchanged title", w.Body)
+	}
+}
+
+func TestTodoHandlerLegacyRoutes(t *testing.T) {
+	r := todoRouter()
+	id := createTodo(t, r, "旧版")
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"list", "/api/todos/list", "", http.StatusOK},
+		{"get", "/api/todos/get/" + id, "", http.StatusOK},
+		{"create", "/api/todos/create", `{"title":"旧版创建"}`, http.StatusCreated},
+		{"update", "/api/todos/update/" + id, `{"title":"旧版修改"}`, http.StatusOK},
+		{"toggle", "/api/todos/toggle/" + id, "", http.StatusOK},
+		{"delete", "/api/todos/delete/" + id, "", http.StatusOK},
+		// 出错的响应同样带有废弃相关的响应头
+		{"not found", "/api/todos/get/" + id, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(r, http.MethodPost, tt.path, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d %s, want %d", w.Code, w.Body, tt.wantStatus)
+			}
+			if got := w.Header().Get("Deprecation"); got != "true" {
+				t.Errorf("Deprecation = %q, want true", got)
+			}
+			if got, want := w.Header().Get("Sunset"), "Wed, 30 Jun 2027 00:00:00 GMT"; got != want {
+				t.Errorf("Sunset = %q, want %q", got, want)
+			}
+			if got, want := w.Header().Get("Link"), `</api/v1/todos>; rel="successor-version"`; got != want {
+				t.Errorf("Link = %q, want %q", got, want)
+			}
+			// 旧版路由不知道资源的 REST 路径，不返回 Location
+			if got := w.Header().Get("Location"); got != "" {
+				t.Errorf("Location = %q, want none", got)
+			}
+		})
+	}
+
+	// REST 路由不带废弃相关的响应头
+	w := serve(r, http.MethodGet, "/api/v1/todos", "")
+	for _, header := range []string{"Deprecation", "Sunset", "Link"} {
+		if got := w.Header().Get(header); got != "" {
+			t.Errorf("GET /api/v1/todos %s = %q, want none", header, got)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHandler 处理工作区和成员相关的HTTP请求
+type WorkspaceHandler struct {
+	service service.WorkspaceService
+	// basePath 工作区资源的路径，用于生成 Location 响应头
+	basePath string
+}
+
+func NewWorkspaceHandler(service service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		service: service,
+	}
+}
+
+// RegisterRoutes 在版本化的路由组（例如 /api/v1）下注册路由。
+// 路径参数名与 /workspaces/:workspaceId 下的其他路由保持一致，认证中间件据此确认成员身份。
+func (h *WorkspaceHandler) RegisterRoutes(r *gin.RouterGroup) {
+	workspaces := r.Group("/workspaces")
+	h.basePath = workspaces.BasePath()
+	{
+		workspaces.GET("", h.List)
+		workspaces.POST("", h.Create)
+		workspaces.GET("/:workspaceId", h.Get)
+		workspaces.PATCH("/:workspaceId", h.Update)
+		workspaces.DELETE("/:workspaceId", h.Delete)
+		workspaces.GET("/:workspaceId/members", h.ListMembers)
+		workspaces.POST("/:workspaceId/members", h.AddMember)
+		workspaces.PATCH("/:workspaceId/members/:userId", h.UpdateMember)
+		workspaces.DELETE("/:workspaceId/members/:userId", h.RemoveMember)
+	}
+}
+
+// List 获取当前用户加入的工作区
+func (h *WorkspaceHandler) List(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	workspaces, err := h.service.List(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// Create 创建工作区
+func (h *WorkspaceHandler) Create(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWorkspaceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	workspace, err := h.service.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if h.basePath != "" {
+		c.Header("Location", resourceLocation(c, h.basePath, workspace.ID))
+	}
+	c.JSON(http.StatusCreated, workspace)
+}
+
+// Get 获取单个工作区
+func (h *WorkspaceHandler) Get(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	workspace, err := h.service.Get(c.Request.Context(), userID, c.Param("workspaceId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// Update 修改工作区
+func (h *WorkspaceHandler) Update(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWorkspaceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	workspace, err := h.service.Update(c.Request.Context(), userID, c.Param("workspaceId"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, workspace)
+}
+
+// Delete 删除工作区和其中所有的数据
+func (h *WorkspaceHandler) Delete(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), userID, c.Param("workspaceId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListMembers 获取工作区的成员
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListMembers(c.Request.Context(), userID, c.Param("workspaceId"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// AddMember 添加工作区成员
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddMemberRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	member, err := h.service.AddMember(c.Request.Context(), userID, c.Param("workspaceId"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// UpdateMember 修改工作区成员的角色
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateMemberRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	member, err := h.service.UpdateMember(c.Request.Context(), userID, c.Param("workspaceId"), c.Param("userId"), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveMember 移除工作区成员，成员也可以移除自己以退出工作区
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), userID, c.Param("workspaceId"), c.Param("userId")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"context"
+
+	"golang.org/x/text/language"
+)
+
+// Locale 语言区域，与前端 locales 目录下的语言保持一致
+type Locale string
+
+// 支持的语言
+const (
+	LocaleZH Locale = "zh"
+	LocaleEN Locale = "en"
+)
+
+// DefaultLocale 无法确定语言时使用的默认语言
+const DefaultLocale = LocaleZH
+
+// supported 支持的语言，顺序与 matcher 中的标签一一对应，第一个为默认语言
+var supported = []Locale{LocaleZH, LocaleEN}
+
+var matcher = language.NewMatcher([]language.Tag{
+	language.Chinese,
+	language.English,
+})
+
+// Supported 返回所有支持的语言
+func Supported() []Locale {
+	return append([]Locale(nil), supported...)
+}
+
+// Parse 解析单个语言标签，例如 "en"、"en-US"、"zh-Hans-CN"，不支持时返回 false
+func Parse(tag string) (Locale, bool) {
+	t, err := language.Parse(tag)
+	if err != nil {
+		return "", false
+	}
+	_, index, confidence := matcher.Match(t)
+	if confidence == language.No {
+		return "", false
+	}
+	return supported[index], true
+}
+
+// FromAcceptLanguage 按 Accept-Language 请求头的权重选择最合适的语言，
+// 请求头为空或无法匹配时返回默认语言
+func FromAcceptLanguage(header string) Locale {
+	if header == "" {
+		return DefaultLocale
+	}
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return supported[index]
+}
+
+// localeKey 上下文中语言的键
+type localeKey struct{}
+
+// WithLocale 在上下文中记录请求使用的语言
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext 返回上下文中记录的语言，未设置时返回默认语言
+func FromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(localeKey{}).(Locale); ok {
+		return locale
+	}
+	return DefaultLocale
+}
@@ -0,0 +1,67 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFromAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   Locale
+	}{
+		{"empty", "", DefaultLocale},
+		{"english", "en", LocaleEN},
+		{"english region", "en-US", LocaleEN},
+		{"chinese script and region", "zh-Hans-CN", LocaleZH},
+		{"traditional chinese", "zh-TW", LocaleZH},
+		{"first of equal weights", "en, zh", LocaleEN},
+		{"q-value prefers chinese", "en;q=0.5, zh;q=0.9", LocaleZH},
+		{"q-value prefers english", "zh-CN;q=0.3, en-GB;q=0.8", LocaleEN},
+		{"unsupported before supported", "fr-FR, de;q=0.9, en;q=0.1", LocaleEN},
+		{"q zero excludes a language", "en;q=0, zh;q=0.1", LocaleZH},
+		{"wildcard", "*", DefaultLocale},
+		{"only unsupported", "fr, de", DefaultLocale},
+		{"malformed", ";;;q=abc", DefaultLocale},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromAcceptLanguage(tt.header); got != tt.want {
+				t.Errorf("FromAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   Locale
+		wantOK bool
+	}{
+		{"en", LocaleEN, true},
+		{"EN-us", LocaleEN, true},
+		{"zh-Hans-CN", LocaleZH, true},
+		{"fr", "", false},
+		{"", "", false},
+		{"not a tag", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, ok := Parse(tt.tag)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Parse(%q) = %q, %v, want %q, %v", tt.tag, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != DefaultLocale {
+		t.Errorf("FromContext(empty) = %q, want %q", got, DefaultLocale)
+	}
+	if got := FromContext(WithLocale(context.Background(), LocaleEN)); got != LocaleEN {
+		t.Errorf("FromContext(en) = %q, want %q", got, LocaleEN)
+	}
+}
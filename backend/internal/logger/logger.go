@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"context"
+
 	"github.com/Brower/backend/internal/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -153,4 +155,28 @@ func Panicf(format string, args ...interface{}) {
 // Fatalf 输出格式化的 Fatal 级别日志
 func Fatalf(format string, args ...interface{}) {
 	Sugar.Fatalf(format, args...)
-}
\ No newline at end of file
+}
+
+// fieldsKey 上下文中日志字段的键
+type fieldsKey struct{}
+
+// ContextWithFields 在上下文中附加日志字段，例如请求 ID、用户 ID
+func ContextWithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithContext 返回附加了上下文中日志字段的日志实例，l 为 nil 时使用全局实例
+func WithContext(ctx context.Context, l *zap.Logger) *zap.Logger {
+	if l == nil {
+		l = Log
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
@@ -0,0 +1,452 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// hardBreak 段落中表示硬换行的占位符，ToHTML 会替换原文中的 NUL，不会与原文冲突
+const hardBreak = "\x00"
+
+// allowedSchemes 链接允许的协议，其他协议（例如 javascript:、data:）和相对地址只保留文字
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+var (
+	angleAutolink = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^<>\x00-\x20]*)>`)
+	emailAutolink = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*)>`)
+	bareAutolink  = regexp.MustCompile(`^https?://[^\s<]+`)
+)
+
+// inlineNode 行内解析的一个节点。delim 为 0 时是已经渲染好的 HTML，
+// 为 *、_、~ 时是尚未配对的强调分隔符，为 [ 时是链接的起始括号
+type inlineNode struct {
+	html  string
+	delim byte
+	count int
+
+	canOpen, canClose bool
+	// image 起始括号前是否有 !，active 起始括号是否还能组成链接（链接中不能再有链接）
+	image, active bool
+}
+
+// renderInline 渲染段落中的行内内容，行首空白被去掉，行尾的两个空格或反斜杠表示硬换行
+func renderInline(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		line = strings.TrimLeft(line, " \t")
+		if i == len(lines)-1 {
+			b.WriteString(strings.TrimRight(line, " \t"))
+			break
+		}
+		trimmed := strings.TrimRight(line, " \t")
+		switch {
+		case strings.HasSuffix(trimmed, `\`) && len(trimmed) == len(line):
+			b.WriteString(strings.TrimSuffix(trimmed, `\`) + hardBreak)
+		case len(line)-len(trimmed) >= 2:
+			b.WriteString(trimmed + hardBreak)
+		default:
+			b.WriteString(trimmed + "\n")
+		}
+	}
+	return renderNodes(processEmphasis(parseInline(b.String())))
+}
+
+// parseInline 把文本切分为节点，代码、自动链接和转义在这里直接渲染，
+// 遇到 ] 时与之前的起始括号组成链接
+func parseInline(s string) []inlineNode {
+	var (
+		nodes []inlineNode
+		text  strings.Builder
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, inlineNode{html: html.EscapeString(text.String())})
+			text.Reset()
+		}
+	}
+	emit := func(fragment string) {
+		flush()
+		nodes = append(nodes, inlineNode{html: fragment})
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+			text.WriteByte(s[i+1])
+			i += 2
+
+		case c == hardBreak[0]:
+			emit("<br>\n")
+			i++
+
+		case c == '`':
+			n := runLength(s, i, '`')
+			if end := closingBackticks(s, i+n, n); end >= 0 {
+				emit("<code>" + html.EscapeString(codeSpanContent(s[i+n:end])) + "</code>")
+				i = end + n
+			} else {
+				text.WriteString(s[i : i+n])
+				i += n
+			}
+
+		case c == '*' || c == '_' || c == '~':
+			n := runLength(s, i, c)
+			if c == '~' && n != 2 {
+				text.WriteString(s[i : i+n])
+				i += n
+				continue
+			}
+			before, _ := utf8.DecodeLastRuneInString(s[:i])
+			after, _ := utf8.DecodeRuneInString(s[i+n:])
+			if i == 0 {
+				before = ' '
+			}
+			if i+n == len(s) {
+				after = ' '
+			}
+			left := !isSpace(after) && (!isPunct(after) || isSpace(before) || isPunct(before))
+			right := !isSpace(before) && (!isPunct(before) || isSpace(after) || isPunct(after))
+			node := inlineNode{delim: c, count: n, canOpen: left, canClose: right}
+			if c == '_' {
+				node.canOpen = left && (!right || isPunct(before))
+				node.canClose = right && (!left || isPunct(after))
+			}
+			flush()
+			nodes = append(nodes, node)
+			i += n
+
+		case c == '!' && i+1 < len(s) && s[i+1] == '[':
+			flush()
+			nodes = append(nodes, inlineNode{html: "![", delim: '[', image: true, active: true})
+			i += 2
+
+		case c == '[':
+			flush()
+			nodes = append(nodes, inlineNode{html: "[", delim: '[', active: true})
+			i++
+
+		case c == ']':
+			flush()
+			opener := -1
+			for j := len(nodes) - 1; j >= 0; j-- {
+				if nodes[j].delim == '[' {
+					opener = j
+					break
+				}
+			}
+			if opener < 0 {
+				text.WriteByte(']')
+				i++
+				continue
+			}
+			dest, title, end, ok := parseLinkTail(s, i+1)
+			if !ok || !nodes[opener].active {
+				// 不能组成链接的括号按普通文字输出
+				nodes[opener].delim = 0
+				text.WriteByte(']')
+				i++
+				continue
+			}
+
+			image := nodes[opener].image
+			inner := renderNodes(processEmphasis(nodes[opener+1:]))
+			nodes = append(nodes[:opener], inlineNode{html: link(dest, title, inner)})
+			if !image {
+				for j := range nodes {
+					if nodes[j].delim == '[' && !nodes[j].image {
+						nodes[j].active = false
+					}
+				}
+			}
+			i = end
+
+		case c == '<':
+			if m := angleAutolink.FindStringSubmatch(s[i:]); m != nil {
+				emit(link(m[1], "", html.EscapeString(m[1])))
+				i += len(m[0])
+			} else if m := emailAutolink.FindStringSubmatch(s[i:]); m != nil {
+				emit(link("mailto:"+m[1], "", html.EscapeString(m[1])))
+				i += len(m[0])
+			} else {
+				text.WriteByte('<')
+				i++
+			}
+
+		case (c == 'h' || c == 'H') && atWordStart(s, i) && bareAutolink.MatchString(s[i:]):
+			url := trimAutolink(bareAutolink.FindString(s[i:]))
+			emit(link(url, "", html.EscapeString(url)))
+			i += len(url)
+
+		default:
+			text.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return nodes
+}
+
+// processEmphasis 把配对的强调分隔符替换为 <em>、<strong> 和 <del>，
+// 规则按 CommonMark 简化：从左到右处理每个可以闭合的分隔符，与最近的同类可开启分隔符配对
+func processEmphasis(nodes []inlineNode) []inlineNode {
+	for closer := 0; closer < len(nodes); {
+		c := &nodes[closer]
+		if !isEmphasis(c.delim) || !c.canClose || c.count == 0 {
+			closer++
+			continue
+		}
+
+		opener := -1
+		for j := closer - 1; j >= 0; j-- {
+			o := &nodes[j]
+			if o.delim != c.delim || !o.canOpen || o.count == 0 {
+				continue
+			}
+			// 两侧都可以开启或闭合时，长度之和是 3 的倍数的分隔符不能配对（CommonMark 的“3 的倍数”规则）
+			if (o.canClose || c.canOpen) && (o.count+c.count)%3 == 0 && (o.count%3 != 0 || c.count%3 != 0) {
+				continue
+			}
+			opener = j
+			break
+		}
+		if opener < 0 {
+			closer++
+			continue
+		}
+
+		o := &nodes[opener]
+		n, tag := 1, "em"
+		switch {
+		case c.delim == '~':
+			n, tag = 2, "del"
+		case o.count >= 2 && c.count >= 2:
+			n, tag = 2, "strong"
+		}
+		o.count -= n
+		c.count -= n
+
+		wrapped := inlineNode{html: "<" + tag + ">" + renderNodes(nodes[opener+1:closer]) + "</" + tag + ">"}
+		rest := append([]inlineNode{wrapped}, nodes[closer:]...)
+		nodes = append(nodes[:opener+1], rest...)
+		closer = opener + 2
+	}
+	return nodes
+}
+
+// renderNodes 拼接节点的 HTML，未配对的分隔符按原文输出
+func renderNodes(nodes []inlineNode) string {
+	var b strings.Builder
+	for _, node := range nodes {
+		if isEmphasis(node.delim) {
+			b.WriteString(strings.Repeat(string(node.delim), node.count))
+			continue
+		}
+		b.WriteString(node.html)
+	}
+	return b.String()
+}
+
+// link 渲染链接，协议不被允许时只输出文字。图片同样渲染为链接
+func link(dest, title, inner string) string {
+	if !safeURL(dest) {
+		return inner
+	}
+	var b strings.Builder
+	b.WriteString(`<a href="`)
+	b.WriteString(html.EscapeString(dest))
+	b.WriteString(`"`)
+	if title != "" {
+		b.WriteString(` title="`)
+		b.WriteString(html.EscapeString(title))
+		b.WriteString(`"`)
+	}
+	b.WriteString(` rel="nofollow noopener noreferrer">`)
+	b.WriteString(inner)
+	b.WriteString("</a>")
+	return b.String()
+}
+
+// safeURL 判断链接地址是否使用允许的协议，地址中不能有空白或控制字符
+func safeURL(dest string) bool {
+	if dest == "" || strings.IndexFunc(dest, func(r rune) bool { return r <= ' ' || r == 0x7f }) >= 0 {
+		return false
+	}
+	scheme, _, ok := strings.Cut(dest, ":")
+	return ok && allowedSchemes[strings.ToLower(scheme)]
+}
+
+// parseLinkTail 解析 ] 之后的 (地址 "标题")，返回地址、标题和结束位置
+func parseLinkTail(s string, i int) (dest, title string, end int, ok bool) {
+	if i >= len(s) || s[i] != '(' {
+		return "", "", 0, false
+	}
+	i = skipSpace(s, i+1)
+
+	if i < len(s) && s[i] == '<' {
+		j := i + 1
+		for j < len(s) && s[j] != '>' && s[j] != '<' && s[j] != '\n' {
+			if s[j] == '\\' && j+1 < len(s) {
+				j++
+			}
+			j++
+		}
+		if j >= len(s) || s[j] != '>' {
+			return "", "", 0, false
+		}
+		dest, i = s[i+1:j], j+1
+	} else {
+		j, depth := i, 0
+		for ; j < len(s); j++ {
+			ch := s[j]
+			if ch == '\\' && j+1 < len(s) && isASCIIPunct(s[j+1]) {
+				j++
+				continue
+			}
+			if ch <= ' ' {
+				break
+			}
+			if ch == '(' {
+				depth++
+			} else if ch == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+		dest, i = s[i:j], j
+	}
+
+	start := i
+	i = skipSpace(s, i)
+	if i < len(s) && i > start && (s[i] == '"' || s[i] == '\'' || s[i] == '(') {
+		closing := s[i]
+		if closing == '(' {
+			closing = ')'
+		}
+		j := i + 1
+		for j < len(s) && s[j] != closing {
+			if s[j] == '\\' && j+1 < len(s) {
+				j++
+			}
+			j++
+		}
+		if j >= len(s) {
+			return "", "", 0, false
+		}
+		title, i = s[i+1:j], skipSpace(s, j+1)
+	}
+
+	if i >= len(s) || s[i] != ')' {
+		return "", "", 0, false
+	}
+	return unescape(dest), unescape(title), i + 1, true
+}
+
+// closingBackticks 从 i 开始查找恰好 n 个反引号组成的结束标记，没有时返回 -1
+func closingBackticks(s string, i, n int) int {
+	for i < len(s) {
+		j := strings.IndexByte(s[i:], '`')
+		if j < 0 {
+			return -1
+		}
+		i += j
+		m := runLength(s, i, '`')
+		if m == n {
+			return i
+		}
+		i += m
+	}
+	return -1
+}
+
+// codeSpanContent 规范化行内代码的内容：换行视为空格，两端各有一个空格时去掉
+func codeSpanContent(code string) string {
+	code = strings.ReplaceAll(code, "\n", " ")
+	code = strings.ReplaceAll(code, hardBreak, " ")
+	if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+		code = code[1 : len(code)-1]
+	}
+	return code
+}
+
+// trimAutolink 去掉自动链接末尾的标点，以及没有配对的右括号
+func trimAutolink(url string) string {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte(`?!.,:*_~'";`, last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, ")") > strings.Count(url, "("):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
+
+// unescape 去掉反斜杠转义
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// runLength 返回从 i 开始连续的字符 c 的个数
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// skipSpace 跳过空白和换行
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == hardBreak[0]) {
+		i++
+	}
+	return i
+}
+
+// atWordStart 判断位置 i 是否在词首，自动链接只在词首识别
+func atWordStart(s string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// isEmphasis 判断是否为强调分隔符
+func isEmphasis(delim byte) bool {
+	return delim == '*' || delim == '_' || delim == '~'
+}
+
+// isASCIIPunct 判断是否为可以被反斜杠转义的 ASCII 标点
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// isSpace 判断是否为空白字符，硬换行的占位符也视为空白
+func isSpace(r rune) bool {
+	return unicode.IsSpace(r) || r == 0
+}
+
+// isPunct 判断是否为标点或符号
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
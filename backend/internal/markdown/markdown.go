@@ -0,0 +1,344 @@
+// Package markdown 把待办事项备注中的 Markdown 渲染为安全的 HTML。
+//
+// 支持 CommonMark 中常用的部分：ATX 和 Setext 标题、段落、硬换行、引用、有序和无序列表
+// （包括嵌套列表和 GFM 任务列表）、围栏代码块、分隔线，以及行内的强调、删除线、代码、
+// 链接和自动链接。图片按链接渲染，不会在客户端加载外部资源。
+//
+// 渲染结果在构造时就是安全的：原文中的 HTML 一律转义，只会输出固定的几种标签，
+// 链接只允许 http、https 和 mailto 协议，其他链接只保留文字。
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxDepth 引用和列表最多嵌套的层数，更深的内容按普通段落渲染，防止恶意输入导致过深的递归
+const maxDepth = 16
+
+var (
+	atxHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	thematicBreak = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	setextLine    = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	fenceOpen     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*?)[ \t]*$")
+	listMarker    = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])(?:([ \t]+)(.*))?$`)
+	quoteMarker   = regexp.MustCompile(`^ {0,3}> ?`)
+	taskMarker    = regexp.MustCompile(`^\[([ xX])\](?:[ \t]+|$)`)
+	languageClean = regexp.MustCompile(`[^A-Za-z0-9_+#.-]`)
+)
+
+// ToHTML 把 Markdown 渲染为安全的 HTML，空白文本返回空字符串
+func ToHTML(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.ReplaceAll(source, "\x00", "�")
+	if strings.TrimSpace(source) == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(source, "\n"), 0, false)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// renderBlocks 渲染一组行中的块级元素。tight 为 true 时段落不包裹 <p>，用于紧凑列表的列表项
+func renderBlocks(b *strings.Builder, lines []string, depth int, tight bool) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		if tight {
+			b.WriteString(renderInline(paragraph))
+			b.WriteString("\n")
+		} else {
+			b.WriteString("<p>")
+			b.WriteString(renderInline(paragraph))
+			b.WriteString("</p>\n")
+		}
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); {
+		line := expandTabs(lines[i])
+
+		if strings.TrimSpace(line) == "" {
+			flush()
+			i++
+			continue
+		}
+
+		// 段落后紧跟的 === 或 --- 把段落变成标题
+		if len(paragraph) > 0 {
+			if m := setextLine.FindStringSubmatch(line); m != nil {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				writeHeading(b, level, renderInline(paragraph))
+				paragraph = nil
+				i++
+				continue
+			}
+		}
+
+		if m := fenceOpen.FindStringSubmatch(line); m != nil && !(m[2][0] == '`' && strings.Contains(m[3], "`")) {
+			flush()
+			i = renderFence(b, lines, i, len(m[1]), m[2], m[3])
+			continue
+		}
+
+		if m := atxHeading.FindStringSubmatch(line); m != nil {
+			flush()
+			writeHeading(b, len(m[1]), renderInline([]string{m[2]}))
+			i++
+			continue
+		}
+
+		if thematicBreak.MatchString(line) {
+			flush()
+			b.WriteString("<hr>\n")
+			i++
+			continue
+		}
+
+		if depth < maxDepth && quoteMarker.MatchString(line) {
+			flush()
+			var quoted []string
+			for ; i < len(lines); i++ {
+				l := expandTabs(lines[i])
+				if !quoteMarker.MatchString(l) {
+					break
+				}
+				quoted = append(quoted, quoteMarker.ReplaceAllString(l, ""))
+			}
+			b.WriteString("<blockquote>\n")
+			renderBlocks(b, quoted, depth+1, false)
+			b.WriteString("</blockquote>\n")
+			continue
+		}
+
+		// 列表可以打断段落，但有序列表只有从 1 开始时才可以，避免把句子中的数字当作列表
+		if m := listMarker.FindStringSubmatch(line); depth < maxDepth && m != nil && (len(paragraph) == 0 || canInterrupt(m)) {
+			flush()
+			i = renderList(b, lines, i, depth)
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+		i++
+	}
+	flush()
+}
+
+// renderFence 渲染从第 start 行开始的围栏代码块，返回代码块之后的行号。
+// 没有结束围栏时代码块延续到最后
+func renderFence(b *strings.Builder, lines []string, start, indent int, fence, info string) int {
+	b.WriteString("<pre><code")
+	if language, _, _ := strings.Cut(info, " "); language != "" {
+		if language = languageClean.ReplaceAllString(language, ""); language != "" {
+			b.WriteString(` class="language-`)
+			b.WriteString(html.EscapeString(language))
+			b.WriteString(`"`)
+		}
+	}
+	b.WriteString(">")
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := expandTabs(lines[i])
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) <= 3 && strings.HasPrefix(trimmed, fence) &&
+			strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+			i++
+			break
+		}
+		// 去掉与开始围栏相同的缩进
+		for n := 0; n < indent && strings.HasPrefix(line, " "); n++ {
+			line = line[1:]
+		}
+		b.WriteString(html.EscapeString(line))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// listItem 列表中的一项
+type listItem struct {
+	lines []string
+	// blankAfter 该项之后是否有空行，列表中任意两项之间有空行时为松散列表
+	blankAfter bool
+}
+
+// renderList 渲染从第 start 行开始的列表，返回列表之后的行号。
+// 同一个列表中的项使用相同的标记（同一个符号，或同一种有序列表的分隔符）
+func renderList(b *strings.Builder, lines []string, start, depth int) int {
+	first := listMarker.FindStringSubmatch(expandTabs(lines[start]))
+	ordered := first[2][0] >= '0' && first[2][0] <= '9'
+	kind := first[2][len(first[2])-1:]
+
+	var (
+		items []listItem
+		loose bool
+	)
+	i := start
+	for i < len(lines) {
+		m := listMarker.FindStringSubmatch(expandTabs(lines[i]))
+		if m == nil || m[2][len(m[2])-1:] != kind || (m[2][0] >= '0' && m[2][0] <= '9') != ordered {
+			break
+		}
+
+		// 内容缩进为标记之后的第一个非空白字符所在的列，标记后超过 4 个空格时只算一个
+		spacing := len(m[3])
+		if spacing == 0 || spacing > 4 {
+			spacing = 1
+		}
+		contentIndent := len(m[1]) + len(m[2]) + spacing
+		item := listItem{lines: []string{m[4]}}
+		if spacing == 1 && len(m[3]) > 1 {
+			item.lines[0] = strings.Repeat(" ", len(m[3])-1) + m[4]
+		}
+
+		i++
+		lastBlank := false
+		for ; i < len(lines); i++ {
+			line := expandTabs(lines[i])
+			if strings.TrimSpace(line) == "" {
+				item.lines = append(item.lines, "")
+				lastBlank = true
+				continue
+			}
+			if indentOf(line) >= contentIndent {
+				item.lines = append(item.lines, line[contentIndent:])
+				lastBlank = false
+				continue
+			}
+			// 没有缩进的行紧跟在文字之后时是段落的延续
+			if !lastBlank && !startsBlock(line) {
+				item.lines = append(item.lines, strings.TrimLeft(line, " "))
+				continue
+			}
+			break
+		}
+
+		// 项末尾的空行属于项之间，不算在项中
+		for len(item.lines) > 0 && item.lines[len(item.lines)-1] == "" {
+			item.lines = item.lines[:len(item.lines)-1]
+			item.blankAfter = true
+		}
+		if len(items) > 0 && items[len(items)-1].blankAfter {
+			loose = true
+		}
+		if containsBlankBetweenBlocks(item.lines) {
+			loose = true
+		}
+		items = append(items, item)
+	}
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		if n, _ := strconv.Atoi(first[2][:len(first[2])-1]); n != 1 {
+			b.WriteString(`<ol start="` + strconv.Itoa(n) + `">` + "\n")
+		} else {
+			b.WriteString("<ol>\n")
+		}
+	} else {
+		b.WriteString("<ul>\n")
+	}
+
+	for _, item := range items {
+		var checkbox string
+		if m := taskMarker.FindStringSubmatch(item.lines[0]); m != nil {
+			checkbox = `<input type="checkbox" disabled>`
+			if m[1] != " " {
+				checkbox = `<input type="checkbox" checked disabled>`
+			}
+			item.lines[0] = item.lines[0][len(m[0]):]
+		}
+
+		if checkbox != "" {
+			b.WriteString(`<li class="task-list-item">` + checkbox + " ")
+		} else {
+			b.WriteString("<li>")
+		}
+		var content strings.Builder
+		renderBlocks(&content, item.lines, depth+1, !loose)
+		body := content.String()
+		if !loose || checkbox != "" {
+			body = strings.TrimSuffix(body, "\n")
+		} else {
+			b.WriteString("\n")
+		}
+		b.WriteString(body)
+		b.WriteString("</li>\n")
+	}
+	b.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// canInterrupt 判断列表标记能否打断正在进行的段落：标记后必须有内容，有序列表必须从 1 开始
+func canInterrupt(m []string) bool {
+	if strings.TrimSpace(m[4]) == "" {
+		return false
+	}
+	if marker := m[2]; marker[0] >= '0' && marker[0] <= '9' {
+		return marker[:len(marker)-1] == "1"
+	}
+	return true
+}
+
+// startsBlock 判断没有缩进的一行是否开始一个新的块，这样的行不能作为段落的延续
+func startsBlock(line string) bool {
+	return listMarker.MatchString(line) || atxHeading.MatchString(line) || thematicBreak.MatchString(line) ||
+		quoteMarker.MatchString(line) || fenceOpen.MatchString(line)
+}
+
+// containsBlankBetweenBlocks 判断列表项内部是否有空行分隔的两个块
+func containsBlankBetweenBlocks(lines []string) bool {
+	for i := 1; i < len(lines)-1; i++ {
+		if lines[i] == "" && lines[i-1] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHeading 输出一个标题，content 为已经渲染的行内内容
+func writeHeading(b *strings.Builder, level int, content string) {
+	tag := "h" + strconv.Itoa(level)
+	b.WriteString("<" + tag + ">" + content + "</" + tag + ">\n")
+}
+
+// indentOf 返回行首空格的个数
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// expandTabs 把行首的制表符展开为空格，制表位为 4
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\t':
+			n := 4 - col%4
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case ' ':
+			b.WriteByte(' ')
+			col++
+		default:
+			b.WriteString(line[i:])
+			return b.String()
+		}
+	}
+	return b.String()
+}
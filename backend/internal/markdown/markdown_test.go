@@ -0,0 +1,200 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// xssVectors 常见的注入方式，渲染结果不能包含可执行的内容
+var xssVectors = []string{
+	"<script>alert(1)</script>",
+	"<SCRIPT SRC=https://evil.example/x.js></SCRIPT>",
+	"<img src=x onerror=alert(1)>",
+	"<svg/onload=alert(1)>",
+	"<iframe src=\"javascript:alert(1)\"></iframe>",
+	"<a href=\"javascript:alert(1)\">x</a>",
+	"<div>\nraw\n</div>",
+	"<!-- <script>alert(1)</script> -->",
+	"[x](javascript:alert(1))",
+	"[x](JaVaScRiPt:alert(1))",
+	"[x](<javascript:alert(1)>)",
+	"[x](&#106;avascript:alert(1))",
+	"[x](java\tscript:alert(1))",
+	"[x](vbscript:msgbox(1))",
+	"[x](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)",
+	"![x](data:image/svg+xml,<svg onload=alert(1)>)",
+	"<javascript:alert(1)>",
+	"<data:text/html,<script>alert(1)</script>>",
+	"[x](https://example.com/\"onmouseover=\"alert(1))",
+	"[x](https://example.com \"a\\\" onmouseover=\\\"alert(1)\")",
+	"![x](https://example.com/a.png\" onerror=\"alert(1))",
+	"[<img src=x onerror=alert(1)>](https://example.com)",
+	"https://example.com/<script>alert(1)</script>",
+	"```js\" onclick=\"alert(1)\n<script>alert(1)</script>\n```",
+	"`<script>alert(1)</script>`",
+	"**<script>alert(1)</script>**",
+	"- [x] <img src=x onerror=alert(1)>",
+	"> <script>alert(1)</script>",
+	"# <script>alert(1)</script>",
+}
+
+var (
+	tagPattern  = regexp.MustCompile(`<(/?)([A-Za-z][A-Za-z0-9]*)([^<>]*)>`)
+	attrPattern = regexp.MustCompile(`^\s+([a-z]+)(?:="([^"<>]*)")?`)
+)
+
+// allowedTags 渲染结果中允许出现的标签和属性
+var allowedTags = map[string]map[string]bool{
+	"a":          {"href": true, "title": true, "rel": true},
+	"blockquote": {},
+	"br":         {},
+	"code":       {"class": true},
+	"del":        {},
+	"em":         {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {},
+	"input":      {"type": true, "checked": true, "disabled": true},
+	"li":         {"class": true},
+	"ol":         {"start": true},
+	"p":          {},
+	"pre":        {},
+	"strong":     {},
+	"ul":         {},
+}
+
+// assertSafe 检查 HTML 只包含允许的标签和属性，链接只使用允许的协议，其余的尖括号都已转义
+func assertSafe(t *testing.T, source, output string) {
+	t.Helper()
+
+	rest := tagPattern.ReplaceAllStringFunc(output, func(tag string) string {
+		m := tagPattern.FindStringSubmatch(tag)
+		attrs, ok := allowedTags[m[2]]
+		if !ok {
+			t.Errorf("ToHTML(%q) contains tag %q", source, tag)
+			return ""
+		}
+		for s := m[3]; strings.TrimSpace(s) != ""; {
+			a := attrPattern.FindStringSubmatch(s)
+			if a == nil {
+				t.Errorf("ToHTML(%q) contains malformed attributes in %q", source, tag)
+				break
+			}
+			if m[1] != "" || !attrs[a[1]] {
+				t.Errorf("ToHTML(%q) contains attribute %q in %q", source, a[1], tag)
+			}
+			if a[1] == "href" && !safeURL(html.UnescapeString(a[2])) {
+				t.Errorf("ToHTML(%q) contains unsafe link %q", source, a[2])
+			}
+			s = s[len(a[0]):]
+		}
+		return ""
+	})
+	if strings.ContainsAny(rest, "<>") {
+		t.Errorf("ToHTML(%q) contains unescaped angle brackets: %q", source, output)
+	}
+}
+
+func TestToHTMLSanitizes(t *testing.T) {
+	for _, source := range xssVectors {
+		assertSafe(t, source, ToHTML(source))
+	}
+	// 嵌套在列表和引用中的内容同样安全
+	nested := "> - " + strings.Join(xssVectors, "\n>   ")
+	assertSafe(t, nested, ToHTML(nested))
+}
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name, source, want string
+	}{
+		{
+			name:   "script tag is escaped",
+			source: "<script>alert(1)</script>",
+			want:   "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>",
+		},
+		{
+			name:   "raw html block is escaped",
+			source: "<div>\nraw\n</div>",
+			want:   "<p>&lt;div&gt;\nraw\n&lt;/div&gt;</p>",
+		},
+		{
+			name:   "html comment is escaped",
+			source: "<!-- c -->",
+			want:   "<p>&lt;!-- c --&gt;</p>",
+		},
+		{
+			name:   "inline html inside emphasis is escaped",
+			source: "**<i>bold</i>**",
+			want:   "<p><strong>&lt;i&gt;bold&lt;/i&gt;</strong></p>",
+		},
+		{
+			name:   "javascript link keeps only text",
+			source: "[x](javascript:alert(1))",
+			want:   "<p>x</p>",
+		},
+		{
+			name:   "mixed case javascript link keeps only text",
+			source: "[x](JaVaScRiPt:alert(1))",
+			want:   "<p>x</p>",
+		},
+		{
+			name:   "data link keeps only text",
+			source: "[x](data:text/html;base64,PHNjcmlwdD4=)",
+			want:   "<p>x</p>",
+		},
+		{
+			name:   "javascript autolink keeps only text",
+			source: "<javascript:alert(1)>",
+			want:   "<p>javascript:alert(1)</p>",
+		},
+		{
+			name:   "protocol relative link keeps only text",
+			source: "[x](//evil.example)",
+			want:   "<p>x</p>",
+		},
+		{
+			name:   "quote in destination is escaped",
+			source: "[x](https://example.com/\"onmouseover=\"alert(1))",
+			want:   `<p><a href="https://example.com/&#34;onmouseover=&#34;alert(1)" rel="nofollow noopener noreferrer">x</a></p>`,
+		},
+		{
+			name:   "quote in title is escaped",
+			source: "[x](https://example.com \"a\\\" onmouseover=\\\"alert(1)\")",
+			want:   `<p><a href="https://example.com" title="a&#34; onmouseover=&#34;alert(1)" rel="nofollow noopener noreferrer">x</a></p>`,
+		},
+		{
+			name:   "fence language is cleaned",
+			source: "```js\" onclick=\"x\n<b>\n```",
+			want:   "<pre><code class=\"language-js\">&lt;b&gt;\n</code></pre>",
+		},
+		{
+			name:   "task list item content is escaped",
+			source: "- [x] <b>done</b>",
+			want:   "<ul>\n<li class=\"task-list-item\"><input type=\"checkbox\" checked disabled> &lt;b&gt;done&lt;/b&gt;</li>\n</ul>",
+		},
+		{
+			name:   "safe link",
+			source: "[x](https://example.com)",
+			want:   `<p><a href="https://example.com" rel="nofollow noopener noreferrer">x</a></p>`,
+		},
+		{
+			name:   "mailto autolink",
+			source: "<alice@example.com>",
+			want:   `<p><a href="mailto:alice@example.com" rel="nofollow noopener noreferrer">alice@example.com</a></p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToHTML(tt.source); got != tt.want {
+				t.Errorf("ToHTML(%q) = %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
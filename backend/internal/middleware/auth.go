@@ -1,59 +1,67 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/i18n"
 	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
-// AuthMiddleware 创建一个认证中间件，验证 Supabase JWT 令牌
-func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		logger.Info("开始处理认证请求")
+// WorkspaceHeader 选择团队工作区的请求头，也可以通过路径 /workspaces/:workspaceId 选择
+const WorkspaceHeader = "X-Workspace-ID"
 
-		// 打印完整的配置信息
-		logger.Info("当前配置信息",
-			zap.String("project_id", cfg.Supabase.ProjectID),
-			zap.Int("jwt_secret_length", len(cfg.Supabase.JWTSecret)),
-			zap.String("jwt_secret_preview", cfg.Supabase.JWTSecret[:10]+"..."),
-		)
+// MembershipResolver 查询用户在工作区中的成员身份，由 repository.WorkspaceRepository 实现
+type MembershipResolver interface {
+	GetMember(ctx context.Context, workspaceID, userID string) (*models.Member, error)
+}
+
+// AuthMiddleware 创建一个认证中间件，验证 Supabase JWT 令牌。
+// 请求选择了工作区时同时通过 members 确认用户是工作区的成员，
+// 把工作区 ID 和角色保存到 Gin 上下文，成员身份保存到请求上下文供服务层检查角色；
+// members 为 nil 时存储后端不支持工作区，选择工作区的请求都返回工作区不存在
+func AuthMiddleware(cfg *config.Config, members MembershipResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger.Debug("开始处理认证请求")
 
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			logger.Error("未提供认证头")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			c.Error(errors.NewWithKey(errors.ErrUnauthorized, errors.MsgMissingAuthHeader))
 			c.Abort()
 			return
 		}
 
-		logger.Info("收到认证头", zap.String("auth_header", authHeader))
-
+		// 认证头和令牌都是凭据，日志中只记录长度
 		bearerToken := strings.Split(authHeader, " ")
 		if len(bearerToken) != 2 || strings.ToLower(bearerToken[0]) != "bearer" {
-			logger.Error("认证头格式错误", zap.Strings("token_parts", bearerToken))
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			logger.Error("认证头格式错误",
+				zap.Int("token_parts", len(bearerToken)),
+				zap.Int("auth_header_length", len(authHeader)),
+			)
+			c.Error(errors.NewWithKey(errors.ErrUnauthorized, errors.MsgInvalidAuthHeader))
 			c.Abort()
 			return
 		}
 
 		tokenString := bearerToken[1]
-		logger.Info("开始验证令牌",
-			zap.String("token_preview", tokenString[:10]+"..."),
-			zap.Int("token_length", len(tokenString)),
-		)
+		logger.Debug("开始验证令牌", zap.Int("token_length", len(tokenString)))
 
 		// 解析但不验证令牌以检查头部
 		token, _, err := jwt.NewParser().ParseUnverified(tokenString, jwt.MapClaims{})
 		if err != nil {
 			logger.Error("令牌解析失败", zap.Error(err))
-			c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("Failed to parse token: %v", err)})
+			c.Error(errors.New(errors.ErrUnauthorized, err))
 			c.Abort()
 			return
 		}
@@ -71,10 +79,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 				logger.Error("意外的签名方法", zap.String("alg", fmt.Sprintf("%v", token.Header["alg"])))
 				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			}
-			logger.Info("使用 JWT Secret 验证令牌",
-				zap.String("secret_length", fmt.Sprintf("%d", len(cfg.Supabase.JWTSecret))),
-				zap.String("secret_preview", cfg.Supabase.JWTSecret[:10]+"..."),
-			)
+			logger.Debug("使用 JWT Secret 验证令牌", zap.Int("secret_length", len(cfg.Supabase.JWTSecret)))
 			return []byte(cfg.Supabase.JWTSecret), nil
 		})
 
@@ -83,7 +88,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 				zap.Error(err),
 				zap.String("error_type", fmt.Sprintf("%T", err)),
 			)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("Invalid token: %v", err)})
+			c.Error(errors.New(errors.ErrUnauthorized, err))
 			c.Abort()
 			return
 		}
@@ -98,13 +103,78 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			if sub, ok := claims["sub"].(string); ok {
 				logger.Info("成功提取用户 ID", zap.String("user_id", sub))
 				c.Set("user_id", sub)
+				// Supabase 的令牌带有已验证的邮箱，接受共享邀请时用于核对收件人
+				if email, ok := claims["email"].(string); ok && email != "" {
+					c.Set("email", email)
+				}
+				ctx := logger.ContextWithFields(c.Request.Context(), zap.String("user_id", sub))
+				// 用户设置的语言优先于 Accept-Language
+				if locale, ok := userLocale(claims); ok {
+					ctx = i18n.WithLocale(ctx, locale)
+					c.Header("Content-Language", string(locale))
+				}
+				if ctx, ok = resolveWorkspace(ctx, c, members, sub); !ok {
+					c.Abort()
+					return
+				}
+				c.Request = c.Request.WithContext(ctx)
 				c.Next()
 				return
 			}
 		}
 
 		logger.Error("无效的令牌声明")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		c.Error(errors.New(errors.ErrUnauthorized, nil))
 		c.Abort()
 	}
 }
+
+// userLocale 从令牌的 user_metadata.locale 中读取用户设置的语言
+func userLocale(claims jwt.MapClaims) (i18n.Locale, bool) {
+	metadata, ok := claims["user_metadata"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	tag, ok := metadata["locale"].(string)
+	if !ok || tag == "" {
+		return "", false
+	}
+	return i18n.Parse(tag)
+}
+
+// resolveWorkspace 确认用户是请求所选工作区的成员，并把成员身份保存到上下文。
+// 没有选择工作区时原样返回 ctx；失败时已经通过 c.Error 记录错误，返回 false
+func resolveWorkspace(ctx context.Context, c *gin.Context, members MembershipResolver, userID string) (context.Context, bool) {
+	workspaceID := c.Param("workspaceId")
+	if header := c.GetHeader(WorkspaceHeader); header != "" {
+		if workspaceID != "" && !strings.EqualFold(header, workspaceID) {
+			c.Error(errors.NewWithKey(errors.ErrInvalidParams, errors.MsgWorkspaceMismatch))
+			return ctx, false
+		}
+		workspaceID = header
+	}
+	if workspaceID == "" {
+		return ctx, true
+	}
+
+	// 格式错误、不存在和不是成员的工作区一样返回不存在，不暴露工作区是否存在
+	id, err := uuid.Parse(workspaceID)
+	if members == nil || err != nil {
+		c.Error(errors.New(errors.ErrWorkspaceNotFound, err))
+		return ctx, false
+	}
+	member, err := members.GetMember(ctx, id.String(), userID)
+	if err != nil {
+		code := errors.ErrInternal
+		if errors.Is(err, repository.ErrMemberNotFound) {
+			code = errors.ErrWorkspaceNotFound
+		}
+		c.Error(errors.New(code, err))
+		return ctx, false
+	}
+
+	c.Set("workspace_id", member.WorkspaceID)
+	c.Set("workspace_role", member.Role)
+	ctx = logger.ContextWithFields(ctx, zap.String("workspace_id", member.WorkspaceID))
+	return models.ContextWithMember(ctx, member), true
+}
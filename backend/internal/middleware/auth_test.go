@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Brower/backend/internal/config"
+	apperrors "github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+const (
+	testSecret    = "short"
+	testWorkspace = "0b7c9c38-6f0a-4f5e-9c59-5d2f3b3c1a10"
+)
+
+// fakeMembers 按工作区 ID 和用户 ID 返回成员身份，err 不为 nil 时总是返回该错误
+type fakeMembers struct {
+	members map[string]models.WorkspaceRole
+	err     error
+}
+
+func (f fakeMembers) GetMember(ctx context.Context, workspaceID, userID string) (*models.Member, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	role, ok := f.members[workspaceID+"/"+userID]
+	if !ok {
+		return nil, repository.ErrMemberNotFound
+	}
+	return &models.Member{WorkspaceID: workspaceID, UserID: userID, Role: role}, nil
+}
+
+// signToken 使用测试密钥签发令牌
+func signToken(t *testing.T, method jwt.SigningMethod, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return token
+}
+
+// authRouter 返回使用认证中间件的路由，处理器把上下文中的用户和工作区信息作为响应返回
+func authRouter(members MembershipResolver) *gin.Engine {
+	cfg := &config.Config{}
+	cfg.Supabase.JWTSecret = testSecret
+
+	r := gin.New()
+	r.Use(ErrorHandler())
+	handler := func(c *gin.Context) {
+		body := gin.H{"user_id": c.GetString("user_id")}
+		if member, ok := models.MemberFromContext(c.Request.Context()); ok {
+			body["workspace_id"] = member.WorkspaceID
+			body["role"] = member.Role
+		}
+		c.JSON(http.StatusOK, body)
+	}
+	auth := AuthMiddleware(cfg, members)
+	r.GET("/todos", auth, handler)
+	r.GET("/workspaces/:workspaceId/todos", auth, handler)
+	return r
+}
+
+func TestAuthMiddlewareToken(t *testing.T) {
+	valid := signToken(t, jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()})
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{"valid", "Bearer " + valid, http.StatusOK},
+		{"lowercase scheme", "bearer " + valid, http.StatusOK},
+		{"missing header", "", http.StatusUnauthorized},
+		// 令牌短于日志预览的长度时不能 panic
+		{"short token", "Bearer x", http.StatusUnauthorized},
+		{"empty token", "Bearer ", http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + valid, http.StatusUnauthorized},
+		{"extra parts", "Bearer " + valid + " extra", http.StatusUnauthorized},
+		{"expired", "Bearer " + signToken(t, jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(-time.Hour).Unix()}), http.StatusUnauthorized},
+		{"wrong secret", "Bearer " + mustSign(t, "another-secret"), http.StatusUnauthorized},
+		{"unsigned", "Bearer " + unsignedToken(t), http.StatusUnauthorized},
+		{"missing subject", "Bearer " + signToken(t, jwt.SigningMethodHS256, jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/todos", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			authRouter(nil).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
+
+// mustSign 使用指定密钥签发有效期内的令牌
+func mustSign(t *testing.T, secret string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return token
+}
+
+// unsignedToken 返回 alg 为 none 的令牌
+func unsignedToken(t *testing.T) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"sub": "user-1"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return token
+}
+
+func TestAuthMiddlewareWorkspace(t *testing.T) {
+	token := "Bearer " + signToken(t, jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix()})
+	members := fakeMembers{members: map[string]models.WorkspaceRole{testWorkspace + "/user-1": models.WorkspaceGuest}}
+
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		members    MembershipResolver
+		wantStatus int
+		wantCode   apperrors.ErrorCode
+		wantRole   models.WorkspaceRole
+	}{
+		{name: "personal", path: "/todos", members: members, wantStatus: http.StatusOK},
+		{name: "path", path: "/workspaces/" + testWorkspace + "/todos", members: members, wantStatus: http.StatusOK, wantRole: models.WorkspaceGuest},
+		{name: "header", path: "/todos", header: testWorkspace, members: members, wantStatus: http.StatusOK, wantRole: models.WorkspaceGuest},
+		{name: "header matches path in other case", path: "/workspaces/" + testWorkspace + "/todos", header: "0B7C9C38-6F0A-4F5E-9C59-5D2F3B3C1A10", members: members, wantStatus: http.StatusOK, wantRole: models.WorkspaceGuest},
+		{name: "header differs from path", path: "/workspaces/" + testWorkspace + "/todos", header: "5e0e1f4a-3c1b-4a4e-8f3f-2f1d0c9b8a70", members: members, wantStatus: http.StatusBadRequest, wantCode: apperrors.ErrInvalidParams},
+		{name: "not a member", path: "/workspaces/5e0e1f4a-3c1b-4a4e-8f3f-2f1d0c9b8a70/todos", members: members, wantStatus: http.StatusNotFound, wantCode: apperrors.ErrWorkspaceNotFound},
+		{name: "malformed id", path: "/workspaces/not-a-uuid/todos", members: members, wantStatus: http.StatusNotFound, wantCode: apperrors.ErrWorkspaceNotFound},
+		{name: "workspaces unsupported", path: "/workspaces/" + testWorkspace + "/todos", wantStatus: http.StatusNotFound, wantCode: apperrors.ErrWorkspaceNotFound},
+		{name: "lookup failure", path: "/workspaces/" + testWorkspace + "/todos", members: fakeMembers{err: errors.New("连接失败")}, wantStatus: http.StatusInternalServerError, wantCode: apperrors.ErrInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", token)
+			if tt.header != "" {
+				req.Header.Set(WorkspaceHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			authRouter(tt.members).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				var resp ErrorResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != int(tt.wantCode) {
+					t.Errorf("body = %s, want code %d", w.Body, tt.wantCode)
+				}
+				return
+			}
+
+			var body map[string]string
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if body["user_id"] != "user-1" {
+				t.Errorf("user_id = %q, want user-1", body["user_id"])
+			}
+			if tt.wantRole == "" {
+				if _, ok := body["workspace_id"]; ok {
+					t.Errorf("workspace_id = %q, want none", body["workspace_id"])
+				}
+				return
+			}
+			if body["workspace_id"] != testWorkspace || body["role"] != string(tt.wantRole) {
+				t.Errorf("member = %s/%s, want %s/%s", body["workspace_id"], body["role"], testWorkspace, tt.wantRole)
+			}
+		})
+	}
+}
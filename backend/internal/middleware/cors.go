@@ -22,6 +22,11 @@ func CORS(cfg *config.Config) gin.HandlerFunc {
 		// 设置允许的头部
 		c.Writer.Header().Set("Access-Control-Allow-Headers", joinStrings(cfg.CORS.AllowedHeaders))
 		
+		// 设置允许前端读取的响应头
+		if len(cfg.CORS.ExposedHeaders) > 0 {
+			c.Writer.Header().Set("Access-Control-Expose-Headers", joinStrings(cfg.CORS.ExposedHeaders))
+		}
+
 		// 允许携带凭证
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
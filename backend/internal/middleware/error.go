@@ -1,61 +1,68 @@
 package middleware
 
 import (
+	"net/http"
+
 	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/i18n"
 	"github.com/Brower/backend/internal/logger"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
-	"net/http"
 )
 
 // ErrorResponse 定义统一的错误响应结构
 type ErrorResponse struct {
-	Code    int         `json:"code"`    // 错误码
-	Message string      `json:"message"` // 错误消息
-	Data    any        `json:"data,omitempty"` // 附加数据
+	Code    int    `json:"code"`           // 错误码
+	Message string `json:"message"`        // 错误消息
+	Data    any    `json:"data,omitempty"` // 附加数据
 }
 
-// ErrorHandler 统一错误处理中间件
+// ErrorHandler 统一错误处理中间件。
+// 处理器通过 c.Error 传入错误，这里统一转换为 {code,message,data} 响应，
+// message 按请求的语言本地化，code 在所有语言下保持不变；
+// 原始错误只写入日志，不会返回给客户端。
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 处理请求
 		c.Next()
 
 		// 检查是否有错误
-		if len(c.Errors) > 0 {
-			// 获取最后一个错误
-			err := c.Errors.Last()
-			
-			// 转换为自定义错误类型
-			var response ErrorResponse
-			if customErr, ok := err.Err.(*errors.Error); ok {
-				response = ErrorResponse{
-					Code:    int(customErr.Code),
-					Message: customErr.Message,
-					Data:    customErr.Data,
-				}
-				// 设置HTTP状态码
-				c.Status(customErr.GetHTTPStatus())
-			} else {
-				// 处理非自定义错误
-				response = ErrorResponse{
-					Code:    int(errors.ErrInternal),
-					Message: "内部服务器错误",
-				}
-				c.Status(http.StatusInternalServerError)
-			}
-
-			// 记录错误日志
-			logger.Error("请求处理错误",
-				zap.String("path", c.Request.URL.Path),
-				zap.String("method", c.Request.Method),
-				zap.Int("code", response.Code),
-				zap.String("message", response.Message),
-				zap.Error(err.Err),
-			)
-
-			c.JSON(c.Writer.Status(), response)
-			c.Abort()
+		if len(c.Errors) == 0 {
+			return
 		}
+
+		// 获取最后一个错误，并转换为自定义错误类型
+		err := c.Errors.Last()
+		customErr := errors.From(err.Err)
+		status := customErr.GetHTTPStatus()
+
+		response := ErrorResponse{
+			Code:    int(customErr.Code),
+			Message: customErr.LocalizedMessage(i18n.FromContext(c.Request.Context())),
+			Data:    customErr.Data,
+		}
+
+		// 记录错误日志，客户端错误只记录警告
+		fields := []zap.Field{
+			zap.String("path", c.Request.URL.Path),
+			zap.String("method", c.Request.Method),
+			zap.Int("status", status),
+			zap.Int("code", response.Code),
+			zap.String("message", response.Message),
+			zap.Error(err.Err),
+		}
+		log := logger.WithContext(c.Request.Context(), logger.Log)
+		if status >= http.StatusInternalServerError {
+			log.Error("请求处理错误", fields...)
+		} else {
+			log.Warn("请求处理错误", fields...)
+		}
+
+		// 响应已经写出时无法再修改
+		if c.Writer.Written() {
+			return
+		}
+
+		c.AbortWithStatusJSON(status, response)
 	}
-} 
\ No newline at end of file
+}
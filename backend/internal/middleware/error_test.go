@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Brower/backend/internal/errors"
+)
+
+// errorRouter 返回使用错误处理中间件的路由，处理器通过 c.Error 传入 err
+func errorRouter(err error, write bool) *gin.Engine {
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/", func(c *gin.Context) {
+		if write {
+			c.Status(http.StatusAccepted)
+			c.Writer.WriteHeaderNow()
+		}
+		if err != nil {
+			c.Error(err)
+		}
+	})
+	return r
+}
+
+func TestErrorHandler(t *testing.T) {
+	violation := apperrors.Violation{Field: "title", Rule: apperrors.RuleRequired, Message: "不能为空"}
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    apperrors.ErrorCode
+		wantMessage string
+		wantData    bool
+	}{
+		{
+			name:        "typed",
+			err:         apperrors.New(apperrors.ErrTodoNotFound, stderrors.New("sql: no rows in result set")),
+			wantStatus:  http.StatusNotFound,
+			wantCode:    apperrors.ErrTodoNotFound,
+			wantMessage: "待办事项未找到",
+		},
+		{
+			name:        "wrapped typed",
+			err:         fmt.Errorf("更新待办事项: %w", apperrors.New(apperrors.ErrTodoAlreadyExists, nil)),
+			wantStatus:  http.StatusConflict,
+			wantCode:    apperrors.ErrTodoAlreadyExists,
+			wantMessage: "待办事项已存在",
+		},
+		{
+			name:        "validation",
+			err:         apperrors.NewValidation(violation),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    apperrors.ErrInvalidParams,
+			wantMessage: "无效的参数",
+			wantData:    true,
+		},
+		{
+			name:        "untyped",
+			err:         stderrors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    apperrors.ErrInternal,
+			wantMessage: "内部服务器错误",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			errorRouter(tt.err, false).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var resp struct {
+				Code    int                       `json:"code"`
+				Message string                    `json:"message"`
+				Data    *apperrors.ValidationData `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", w.Body, err)
+			}
+			if resp.Code != int(tt.wantCode) || resp.Message != tt.wantMessage {
+				t.Errorf("body = %s, want code %d and message %q", w.Body, tt.wantCode, tt.wantMessage)
+			}
+			if tt.wantData != (resp.Data != nil) {
+				t.Errorf("data = %+v, want present %v", resp.Data, tt.wantData)
+			}
+			if tt.wantData && (len(resp.Data.Violations) != 1 || resp.Data.Violations[0] != violation) {
+				t.Errorf("violations = %+v, want [%+v]", resp.Data.Violations, violation)
+			}
+			// 原始错误只写入日志，不返回给客户端
+			if cause := stderrors.Unwrap(tt.err); cause != nil && strings.Contains(w.Body.String(), cause.Error()) {
+				t.Errorf("body %s leaks the cause %q", w.Body, cause)
+			}
+			if strings.Contains(w.Body.String(), "connection refused") {
+				t.Errorf("body %s leaks the raw error", w.Body)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerPassThrough(t *testing.T) {
+	// 没有错误时不修改响应
+	w := httptest.NewRecorder()
+	errorRouter(nil, true).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusAccepted || w.Body.Len() != 0 {
+		t.Errorf("response = %d %s, want 202 without body", w.Code, w.Body)
+	}
+
+	// 响应已经写出时保留原来的状态码
+	w = httptest.NewRecorder()
+	errorRouter(apperrors.New(apperrors.ErrInternal, nil), true).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusAccepted || w.Body.Len() != 0 {
+		t.Errorf("response = %d %s, want 202 without body", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"github.com/Brower/backend/internal/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// Locale 根据 Accept-Language 请求头确定请求使用的语言，并写入请求上下文。
+// 认证通过后，用户在个人资料中设置的语言会覆盖这里的结果。
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := i18n.FromAcceptLanguage(c.GetHeader("Accept-Language"))
+		c.Request = c.Request.WithContext(i18n.WithLocale(c.Request.Context(), locale))
+		c.Header("Content-Language", string(locale))
+
+		c.Next()
+	}
+}
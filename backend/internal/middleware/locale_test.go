@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Brower/backend/internal/errors"
+)
+
+func TestLocalizedErrorResponse(t *testing.T) {
+	r := gin.New()
+	r.Use(Locale(), ErrorHandler())
+	r.GET("/", func(c *gin.Context) {
+		c.Error(apperrors.NewWithKey(apperrors.ErrInvalidParams, apperrors.MsgInvalidJSON))
+	})
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		wantLanguage   string
+		wantMessage    string
+	}{
+		{"default", "", "zh", "请求体不是合法的 JSON"},
+		{"english", "en-US,en;q=0.9", "en", "Request body is not valid JSON"},
+		{"q-value", "en;q=0.2, zh-CN;q=0.8", "zh", "请求体不是合法的 JSON"},
+		{"unsupported", "fr-FR", "zh", "请求体不是合法的 JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Language"); got != tt.wantLanguage {
+				t.Errorf("Content-Language = %q, want %q", got, tt.wantLanguage)
+			}
+			var resp ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", w.Body, err)
+			}
+			// 错误码在所有语言下保持不变
+			if w.Code != http.StatusBadRequest || resp.Code != int(apperrors.ErrInvalidParams) || resp.Message != tt.wantMessage {
+				t.Errorf("response = %d %+v, want 400 code %d message %q", w.Code, resp, apperrors.ErrInvalidParams, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/Brower/backend/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader 请求 ID 的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID 为每个请求分配请求 ID，并把它写入请求上下文的日志字段，
+// 之后服务层和仓库层通过 logger.WithContext 输出的日志都会带上请求 ID
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = uuid.New().String()
+		}
+
+		c.Set("request_id", requestID)
+		c.Writer.Header().Set(RequestIDHeader, requestID)
+
+		ctx := logger.ContextWithFields(c.Request.Context(), zap.String("request_id", requestID))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
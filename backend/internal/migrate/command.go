@@ -0,0 +1,101 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/migrations"
+)
+
+// commandUsage migrate 子命令的用法说明
+const commandUsage = `用法: backend migrate <命令>
+
+命令:
+  status         查看所有迁移的执行状态
+  up             执行所有未执行的迁移
+  down [n]       回滚最近的 n 个迁移（默认 1）
+  to <version>   迁移到指定版本（0 表示回滚全部）`
+
+// RunCommand 执行 migrate 子命令，数据库连接使用 database 配置
+func RunCommand(ctx context.Context, cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("缺少命令\n%s", commandUsage)
+	}
+
+	m, err := New(ctx, cfg.Database.PostgresDSN(), migrations.FS)
+	if err != nil {
+		return err
+	}
+	defer m.Close(context.Background())
+
+	switch args[0] {
+	case "status":
+		return printStatus(ctx, m, out)
+
+	case "up":
+		if err := m.Up(ctx); err != nil {
+			return err
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("回滚步数无效: %s", args[1])
+			}
+		}
+		if err := m.Down(ctx, steps); err != nil {
+			return err
+		}
+
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf("缺少目标版本\n%s", commandUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("目标版本无效: %s", args[1])
+		}
+		if err := m.To(ctx, version); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("未知命令 %q\n%s", args[0], commandUsage)
+	}
+
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "当前版本: %d\n", version)
+	return nil
+}
+
+// printStatus 以表格形式输出迁移状态
+func printStatus(ctx context.Context, m *Migrator, out io.Writer) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", "-"
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Local().Format(time.DateTime)
+		}
+		if s.Modified {
+			state += " (modified)"
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
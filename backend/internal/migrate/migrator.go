@@ -0,0 +1,254 @@
+// Package migrate 提供内嵌 SQL 迁移的执行器，使用 schema_migrations 表记录已执行的版本。
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"go.uber.org/zap"
+)
+
+// ErrChecksumMismatch 已执行的迁移文件被修改过
+var ErrChecksumMismatch = errors.New("migration checksum mismatch")
+
+// Status 单个迁移的执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Modified 表示迁移已执行，但文件内容与执行时不一致
+	Modified bool
+}
+
+// appliedMigration schema_migrations 中的一条记录
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// store 迁移执行器依赖的数据库操作，PostgreSQL 的实现见 pgStore
+type store interface {
+	// lock 获取迁移锁，返回释放锁的函数
+	lock(ctx context.Context) (func() error, error)
+	// applied 查询已执行的迁移
+	applied(ctx context.Context) (map[int64]appliedMigration, error)
+	// version 返回已执行的最高版本，没有时返回 0
+	version(ctx context.Context) (int64, error)
+	// apply 在事务中执行 up 脚本并记录版本
+	apply(ctx context.Context, migration Migration) error
+	// rollback 在事务中执行 down 脚本并删除版本记录
+	rollback(ctx context.Context, migration Migration) error
+	close(ctx context.Context) error
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	store      store
+	migrations []Migration
+	logger     *zap.Logger
+}
+
+// New 连接数据库并加载迁移文件
+func New(ctx context.Context, dsn string, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newPgStore(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(store, migrations), nil
+}
+
+// newMigrator 使用指定的 store 创建迁移执行器，migrations 需按版本号升序排列
+func newMigrator(store store, migrations []Migration) *Migrator {
+	return &Migrator{
+		store:      store,
+		migrations: migrations,
+		logger:     logger.Log.With(zap.String("component", "Migrator")),
+	}
+}
+
+// Close 关闭数据库连接
+func (m *Migrator) Close(ctx context.Context) error {
+	return m.store.close(ctx)
+}
+
+// Latest 返回最新的迁移版本，没有迁移时返回 0
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status 返回所有迁移的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.store.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.appliedAt
+			status.Modified = record.checksum != migration.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Version 返回当前已执行的最高版本，没有执行过任何迁移时返回 0
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	return m.store.version(ctx)
+}
+
+// Up 执行所有尚未执行的迁移
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down 回滚最近执行的 steps 个迁移
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("回滚步数必须大于 0")
+	}
+
+	return m.withLock(ctx, func() error {
+		applied, err := m.store.applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := m.rollback(ctx, migration); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// To 迁移到指定版本：执行不高于该版本的未执行迁移，回滚高于该版本的已执行迁移
+func (m *Migrator) To(ctx context.Context, target int64) error {
+	if target != 0 && !m.hasVersion(target) {
+		return fmt.Errorf("迁移版本 %d 不存在", target)
+	}
+
+	return m.withLock(ctx, func() error {
+		applied, err := m.store.applied(ctx)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		// 先回滚高于目标版本的迁移，从新到旧
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version <= target {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				if err := m.rollback(ctx, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		// 再执行不高于目标版本的未执行迁移，从旧到新
+		for _, migration := range m.migrations {
+			if migration.Version > target {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// apply 执行一个迁移并记录版本
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	m.logger.Info("执行迁移",
+		zap.Int64("version", migration.Version),
+		zap.String("name", migration.Name))
+
+	return m.store.apply(ctx, migration)
+}
+
+// rollback 回滚一个迁移并删除版本记录
+func (m *Migrator) rollback(ctx context.Context, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("迁移 %d_%s 没有 down 脚本，无法回滚", migration.Version, migration.Name)
+	}
+
+	m.logger.Info("回滚迁移",
+		zap.Int64("version", migration.Version),
+		zap.String("name", migration.Name))
+
+	return m.store.rollback(ctx, migration)
+}
+
+// verify 检查已执行的迁移文件是否被修改，以及数据库中是否有未知的版本
+func (m *Migrator) verify(applied map[int64]appliedMigration) error {
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		if ok && record.checksum != migration.Checksum {
+			return fmt.Errorf("%w: 版本 %d (%s) 执行后被修改", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+	for version, record := range applied {
+		if !m.hasVersion(version) {
+			return fmt.Errorf("数据库中存在未知的迁移版本 %d (%s)", version, record.name)
+		}
+	}
+	return nil
+}
+
+// hasVersion 判断迁移版本是否存在
+func (m *Migrator) hasVersion(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// withLock 持有迁移锁执行 fn
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	unlock, err := m.store.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := unlock(); err != nil {
+			m.logger.Warn("释放迁移锁失败", zap.Error(err))
+		}
+	}()
+
+	return fn()
+}
@@ -0,0 +1,354 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Brower/backend/internal/logger"
+	"go.uber.org/zap"
+	_ "modernc.org/sqlite"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// sqliteStore 基于 SQLite 的 store 实现，只用于测试迁移执行器的逻辑
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func newSQLiteStore(t *testing.T) *sqliteStore {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	// 内存数据库每个连接独立，限制为一个连接
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		t.Fatalf("创建 schema_migrations 表失败: %v", err)
+	}
+	// events 记录 up/down 脚本的执行顺序
+	if _, err := db.Exec(`CREATE TABLE events (id INTEGER PRIMARY KEY AUTOINCREMENT, event TEXT NOT NULL)`); err != nil {
+		t.Fatalf("创建 events 表失败: %v", err)
+	}
+	return &sqliteStore{db: db}
+}
+
+func (s *sqliteStore) close(context.Context) error { return nil }
+
+func (s *sqliteStore) lock(context.Context) (func() error, error) {
+	return func() error { return nil }, nil
+}
+
+func (s *sqliteStore) applied(ctx context.Context) (map[int64]appliedMigration, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var (
+			version int64
+			record  appliedMigration
+		)
+		if err := rows.Scan(&version, &record.name, &record.checksum, &record.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = record
+	}
+	return applied, rows.Err()
+}
+
+func (s *sqliteStore) version(ctx context.Context) (int64, error) {
+	var version int64
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+func (s *sqliteStore) apply(ctx context.Context, migration Migration) error {
+	return s.inTx(ctx, migration.Up,
+		`INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)`,
+		migration.Version, migration.Name, migration.Checksum)
+}
+
+func (s *sqliteStore) rollback(ctx context.Context, migration Migration) error {
+	return s.inTx(ctx, migration.Down, `DELETE FROM schema_migrations WHERE version = ?`, migration.Version)
+}
+
+func (s *sqliteStore) inTx(ctx context.Context, script, record string, args ...any) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// events 返回 up/down 脚本的执行顺序
+func (s *sqliteStore) events(t *testing.T) []string {
+	t.Helper()
+
+	rows, err := s.db.Query(`SELECT event FROM events ORDER BY id`)
+	if err != nil {
+		t.Fatalf("查询 events 失败: %v", err)
+	}
+	defer rows.Close()
+
+	var events []string
+	for rows.Next() {
+		var event string
+		if err := rows.Scan(&event); err != nil {
+			t.Fatalf("解析 events 失败: %v", err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// testFS 返回 n 个迁移，每个迁移创建一张表，并在 events 中记录执行
+func testFS(n int) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for v := 1; v <= n; v++ {
+		fsys[fmt.Sprintf("%03d_table%d.up.sql", v, v)] = &fstest.MapFile{Data: []byte(fmt.Sprintf(
+			"CREATE TABLE t%d (id INTEGER); INSERT INTO events (event) VALUES ('up %d');", v, v))}
+		fsys[fmt.Sprintf("%03d_table%d.down.sql", v, v)] = &fstest.MapFile{Data: []byte(fmt.Sprintf(
+			"DROP TABLE t%d; INSERT INTO events (event) VALUES ('down %d');", v, v))}
+	}
+	return fsys
+}
+
+func newTestMigrator(t *testing.T, store store, fsys fstest.MapFS) *Migrator {
+	t.Helper()
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return newMigrator(store, migrations)
+}
+
+// appliedVersions 返回 Status 中已执行的版本
+func appliedVersions(t *testing.T, m *Migrator) []int64 {
+	t.Helper()
+
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	var versions []int64
+	for _, status := range statuses {
+		if status.Applied {
+			versions = append(versions, status.Version)
+		}
+	}
+	return versions
+}
+
+func TestMigratorUpAppliesPendingInOrder(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLiteStore(t)
+	m := newTestMigrator(t, store, testFS(3))
+
+	if err := m.To(ctx, 1); err != nil {
+		t.Fatalf("To(1) error = %v", err)
+	}
+	if got := appliedVersions(t, m); !slices.Equal(got, []int64{1}) {
+		t.Fatalf("applied = %v, want [1]", got)
+	}
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	// 已执行的迁移不会重复执行
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("second Up() error = %v", err)
+	}
+
+	if got := appliedVersions(t, m); !slices.Equal(got, []int64{1, 2, 3}) {
+		t.Errorf("applied = %v, want [1 2 3]", got)
+	}
+	if got, want := store.events(t), []string{"up 1", "up 2", "up 3"}; !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if version, err := m.Version(ctx); err != nil || version != 3 {
+		t.Errorf("Version() = %d, %v, want 3", version, err)
+	}
+}
+
+func TestMigratorDownRollsBackNewestFirst(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLiteStore(t)
+	m := newTestMigrator(t, store, testFS(4))
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if err := m.Down(ctx, 2); err != nil {
+		t.Fatalf("Down(2) error = %v", err)
+	}
+
+	want := []string{"up 1", "up 2", "up 3", "up 4", "down 4", "down 3"}
+	if got := store.events(t); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if got := appliedVersions(t, m); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("applied = %v, want [1 2]", got)
+	}
+
+	// 步数超过已执行的迁移数时全部回滚
+	if err := m.Down(ctx, 10); err != nil {
+		t.Fatalf("Down(10) error = %v", err)
+	}
+	if got := appliedVersions(t, m); len(got) != 0 {
+		t.Errorf("applied = %v, want none", got)
+	}
+	if got := store.events(t)[len(want):]; !slices.Equal(got, []string{"down 2", "down 1"}) {
+		t.Errorf("events = %v, want [down 2 down 1]", got)
+	}
+
+	if err := m.Down(ctx, 0); err == nil {
+		t.Error("Down(0) error = nil, want error")
+	}
+}
+
+func TestMigratorToRollsBackThenApplies(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLiteStore(t)
+	m := newTestMigrator(t, store, testFS(3))
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if err := m.To(ctx, 1); err != nil {
+		t.Fatalf("To(1) error = %v", err)
+	}
+	if err := m.To(ctx, 2); err != nil {
+		t.Fatalf("To(2) error = %v", err)
+	}
+	if err := m.To(ctx, 0); err != nil {
+		t.Fatalf("To(0) error = %v", err)
+	}
+
+	want := []string{
+		"up 1", "up 2", "up 3",
+		"down 3", "down 2",
+		"up 2",
+		"down 2", "down 1",
+	}
+	if got := store.events(t); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	if err := m.To(ctx, 9); err == nil {
+		t.Error("To(9) error = nil, want unknown version error")
+	}
+}
+
+func TestMigratorChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLiteStore(t)
+	fsys := testFS(2)
+	if err := newTestMigrator(t, store, fsys).To(ctx, 1); err != nil {
+		t.Fatalf("To(1) error = %v", err)
+	}
+
+	// 修改已执行的迁移后，执行器拒绝继续迁移
+	fsys["001_table1.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE t1 (id INTEGER, name TEXT);")}
+	m := newTestMigrator(t, store, fsys)
+
+	if err := m.Up(ctx); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Up() error = %v, want ErrChecksumMismatch", err)
+	}
+	if got := appliedVersions(t, m); !slices.Equal(got, []int64{1}) {
+		t.Errorf("applied = %v, want [1]", got)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !statuses[0].Modified || statuses[1].Modified {
+		t.Errorf("Modified = %v, %v, want true, false", statuses[0].Modified, statuses[1].Modified)
+	}
+}
+
+func TestMigratorUnknownAppliedVersion(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLiteStore(t)
+	if err := newTestMigrator(t, store, testFS(3)).Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	// 数据库由更新的版本迁移过，当前只有前两个迁移文件
+	m := newTestMigrator(t, store, testFS(2))
+	err := m.Up(ctx)
+	if err == nil || errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("Up() error = %v, want unknown version error", err)
+	}
+	if got := len(store.events(t)); got != 3 {
+		t.Errorf("events = %d, want 3", got)
+	}
+}
+
+func TestMigratorRollbackWithoutDown(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLiteStore(t)
+	fsys := testFS(2)
+	delete(fsys, "002_table2.down.sql")
+	m := newTestMigrator(t, store, fsys)
+
+	if err := m.Up(ctx); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if err := m.Down(ctx, 1); err == nil {
+		t.Fatal("Down(1) error = nil, want missing down script error")
+	}
+	if got := appliedVersions(t, m); !slices.Equal(got, []int64{1, 2}) {
+		t.Errorf("applied = %v, want [1 2]", got)
+	}
+}
+
+func TestMigratorFailedApplyIsNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	store := newSQLiteStore(t)
+	fsys := testFS(3)
+	fsys["002_table2.up.sql"] = &fstest.MapFile{Data: []byte("INSERT INTO events (event) VALUES ('up 2'); CREATE TABLE t1 (id INTEGER);")}
+	m := newTestMigrator(t, store, fsys)
+
+	if err := m.Up(ctx); err == nil {
+		t.Fatal("Up() error = nil, want error")
+	}
+	// 失败的迁移在事务中回滚，后续迁移不再执行
+	if got := appliedVersions(t, m); !slices.Equal(got, []int64{1}) {
+		t.Errorf("applied = %v, want [1]", got)
+	}
+	if got := store.events(t); !slices.Equal(got, []string{"up 1"}) {
+		t.Errorf("events = %v, want [up 1]", got)
+	}
+}
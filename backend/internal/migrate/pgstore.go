@@ -0,0 +1,114 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// advisoryLockKey 迁移使用的咨询锁，防止多个实例同时执行迁移
+const advisoryLockKey int64 = 0x746f646f6d6967 // "todomig"
+
+// createMigrationsTable 版本记录表
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	checksum TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`
+
+// pgStore 基于 PostgreSQL 的 store 实现
+type pgStore struct {
+	conn *pgx.Conn
+}
+
+// newPgStore 连接数据库并创建 schema_migrations 表
+func newPgStore(ctx context.Context, dsn string) (*pgStore, error) {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, createMigrationsTable); err != nil {
+		conn.Close(ctx)
+		return nil, fmt.Errorf("创建 schema_migrations 表失败: %w", err)
+	}
+	return &pgStore{conn: conn}, nil
+}
+
+func (s *pgStore) close(ctx context.Context) error {
+	return s.conn.Close(ctx)
+}
+
+// lock 获取咨询锁
+func (s *pgStore) lock(ctx context.Context) (func() error, error) {
+	if _, err := s.conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockKey); err != nil {
+		return nil, fmt.Errorf("获取迁移锁失败: %w", err)
+	}
+	return func() error {
+		// 使用独立的上下文释放锁，避免 ctx 已取消时锁无法释放
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := s.conn.Exec(unlockCtx, `SELECT pg_advisory_unlock($1)`, advisoryLockKey)
+		return err
+	}, nil
+}
+
+func (s *pgStore) applied(ctx context.Context) (map[int64]appliedMigration, error) {
+	rows, err := s.conn.Query(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("查询已执行的迁移失败: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var (
+			version int64
+			record  appliedMigration
+		)
+		if err := rows.Scan(&version, &record.name, &record.checksum, &record.appliedAt); err != nil {
+			return nil, fmt.Errorf("解析已执行的迁移失败: %w", err)
+		}
+		applied[version] = record
+	}
+	return applied, rows.Err()
+}
+
+func (s *pgStore) version(ctx context.Context) (int64, error) {
+	var version int64
+	err := s.conn.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("查询当前版本失败: %w", err)
+	}
+	return version, nil
+}
+
+func (s *pgStore) apply(ctx context.Context, migration Migration) error {
+	return pgx.BeginFunc(ctx, s.conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, migration.Up); err != nil {
+			return fmt.Errorf("执行迁移 %d_%s 失败: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+			migration.Version, migration.Name, migration.Checksum,
+		); err != nil {
+			return fmt.Errorf("记录迁移版本 %d 失败: %w", migration.Version, err)
+		}
+		return nil
+	})
+}
+
+func (s *pgStore) rollback(ctx context.Context, migration Migration) error {
+	return pgx.BeginFunc(ctx, s.conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, migration.Down); err != nil {
+			return fmt.Errorf("回滚迁移 %d_%s 失败: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version); err != nil {
+			return fmt.Errorf("删除迁移版本 %d 失败: %w", migration.Version, err)
+		}
+		return nil
+	})
+}
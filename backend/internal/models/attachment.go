@@ -0,0 +1,56 @@
+package models
+
+import (
+	"io"
+	"time"
+)
+
+// Attachment 待办事项的附件。文件保存在 BlobStore 中，这里只保存元数据；
+// TodoID 为空表示附件或其所属的待办事项已被删除，文件等待后台任务清理
+type Attachment struct {
+	ID     string `json:"id"`
+	TodoID string `json:"todo_id"`
+	UserID string `json:"user_id"`
+	// Filename 上传时的文件名，只用于下载时的 Content-Disposition
+	Filename string `json:"filename"`
+	// ContentType 按文件内容识别的 MIME 类型，与客户端声明的类型无关
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	// StorageKey 文件在 BlobStore 中的键，由服务端生成，不包含文件名
+	StorageKey string    `json:"storage_key"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// UploadAttachmentRequest 上传附件请求，由处理器从 multipart 请求体的 file 字段中读取，
+// Body 只读取一次，超过大小限制的部分不会被读取
+type UploadAttachmentRequest struct {
+	Filename string
+	Body     io.Reader
+}
+
+// AttachmentResponse 附件响应，URL 为有效期到 URLExpiresAt 的下载链接
+type AttachmentResponse struct {
+	ID           string    `json:"id"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	CreatedAt    time.Time `json:"createdAt"`
+	URL          string    `json:"url"`
+	URLExpiresAt time.Time `json:"urlExpiresAt"`
+}
+
+// AttachmentListResponse 附件列表的响应，按上传时间排序
+type AttachmentListResponse struct {
+	Items []AttachmentResponse `json:"items"`
+}
+
+// ToResponse 将 Attachment 转换为 AttachmentResponse，下载链接由调用方填充
+func (a *Attachment) ToResponse() AttachmentResponse {
+	return AttachmentResponse{
+		ID:          a.ID,
+		Filename:    a.Filename,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		CreatedAt:   a.CreatedAt,
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// Comment 待办事项下的一条评论，按创建时间正序排列，删除待办事项时一并删除。
+// 评论只能由作者修改和删除
+type Comment struct {
+	ID     string `json:"id"`
+	TodoID string `json:"todo_id"`
+	// UserID 评论的作者
+	UserID    string    `json:"user_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CommentListOptions 仓库层的评论查询参数，按创建时间正序分页
+type CommentListOptions struct {
+	// Limit 单页最多返回的条数，由服务层保证大于 0
+	Limit int
+	// After 上一页最后一条评论的游标，为 nil 时从第一页开始
+	After *Cursor
+}
+
+// CommentPage 一页评论
+type CommentPage struct {
+	Items []Comment
+	// Next 下一页的游标，没有更多数据时为 nil
+	Next *Cursor
+}
+
+// NewCommentCursor 根据一条评论生成指向它之后的游标
+func NewCommentCursor(c Comment) *Cursor {
+	return &Cursor{
+		SortBy:  SortByCreatedAt,
+		SortDir: SortAsc,
+		Values:  []string{c.CreatedAt.UTC().Format(time.RFC3339Nano)},
+		ID:      c.ID,
+	}
+}
+
+// ListCommentsRequest 评论列表的查询参数，由服务层校验并转换
+type ListCommentsRequest struct {
+	Limit  string `form:"limit"`
+	Cursor string `form:"cursor"`
+}
+
+// CreateCommentRequest 添加评论请求
+type CreateCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// UpdateCommentRequest 修改评论请求
+type UpdateCommentRequest struct {
+	Body string `json:"body"`
+}
+
+// CommentResponse 评论响应，Edited 表示评论在创建后被修改过
+type CommentResponse struct {
+	ID        string    `json:"id"`
+	TodoID    string    `json:"todoId"`
+	UserID    string    `json:"userId"`
+	Body      string    `json:"body"`
+	Edited    bool      `json:"edited"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ToResponse 将 Comment 转换为 CommentResponse
+func (c *Comment) ToResponse() CommentResponse {
+	return CommentResponse{
+		ID:        c.ID,
+		TodoID:    c.TodoID,
+		UserID:    c.UserID,
+		Body:      c.Body,
+		Edited:    c.UpdatedAt.After(c.CreatedAt),
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// CommentListResponse 评论列表的响应
+type CommentListResponse struct {
+	Items []CommentResponse `json:"items"`
+	// NextCursor 获取下一页时作为 cursor 参数传回，没有更多数据时为空
+	NextCursor string `json:"nextCursor,omitempty"`
+	// Total 待办事项的评论总数，与分页无关
+	Total int `json:"total"`
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SortField 列表排序字段
+type SortField string
+
+// 支持的排序字段
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByUpdatedAt SortField = "updated_at"
+	SortByTitle     SortField = "title"
+	// SortByPosition 按手动排序的位置
+	SortByPosition SortField = "position"
+	// SortByPriority 按优先级，同一优先级内按位置：降序时优先级从高到低、位置从前到后，升序时完全相反
+	SortByPriority SortField = "priority"
+)
+
+// SortDirection 排序方向
+type SortDirection string
+
+// 排序方向
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// DueFilter 按截止时间过滤的快捷方式
+type DueFilter string
+
+// 截止时间过滤，“今天”和“本周”按请求中的时区计算，一周从周一开始
+const (
+	DueOverdue  DueFilter = "overdue" // 已过截止时间且未完成
+	DueToday    DueFilter = "today"   // 今天截止
+	DueThisWeek DueFilter = "week"    // 本周截止
+)
+
+// TodoFilter 列表过滤条件，为 nil 的条件不生效；时间范围包含起点、不包含终点。
+// 截止时间范围只匹配设置了截止时间的待办事项。
+type TodoFilter struct {
+	Completed     *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	// SeriesID 只返回指定重复系列的实例，为空时不过滤
+	SeriesID string
+	// TagIDs 只返回带有这些标签的待办事项，为空时不过滤；
+	// TagMatch 为 all 时必须带有全部标签，否则带有任意一个即可
+	TagIDs   []string
+	TagMatch TagMatch
+	// ProjectID 只返回指定项目中的待办事项，为空时不过滤
+	ProjectID string
+}
+
+// TodoListOptions 仓库层的列表查询参数
+type TodoListOptions struct {
+	Filter  TodoFilter
+	SortBy  SortField
+	SortDir SortDirection
+	// Limit 单页最多返回的条数，由服务层保证大于 0
+	Limit int
+	// After 上一页最后一条记录的游标，为 nil 时从第一页开始
+	After *Cursor
+}
+
+// TodoPage 一页待办事项
+type TodoPage struct {
+	Items []Todo
+	// Next 下一页的游标，没有更多数据时为 nil
+	Next *Cursor
+	// Total 符合过滤条件的总条数，与分页无关
+	Total int
+}
+
+// Cursor 键集分页的游标，记录上一页最后一条记录的排序值和 ID。
+// 排序字段和方向也记录在游标中，防止客户端换了排序方式继续使用旧游标。
+type Cursor struct {
+	SortBy  SortField     `json:"s"`
+	SortDir SortDirection `json:"d"`
+	// Values 排序字段的值，按排序字段的顺序排列；时间使用 RFC3339Nano 格式
+	Values []string `json:"v"`
+	ID     string   `json:"id"`
+}
+
+// NewCursor 根据一条记录生成指向它之后的游标
+func NewCursor(sortBy SortField, sortDir SortDirection, todo Todo) *Cursor {
+	return &Cursor{
+		SortBy:  sortBy,
+		SortDir: sortDir,
+		Values:  todo.SortValues(sortBy),
+		ID:      todo.ID,
+	}
+}
+
+// Encode 编码为不透明的字符串，客户端只需要原样传回
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor 解析 Encode 生成的游标
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("游标格式错误: %w", err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("游标格式错误: %w", err)
+	}
+	if len(c.Values) == 0 || c.ID == "" {
+		return nil, fmt.Errorf("游标缺少排序值")
+	}
+	return &c, nil
+}
+
+// Value 返回第一个排序字段的值
+func (c *Cursor) Value() string {
+	return c.Values[0]
+}
+
+// Time 将第一个排序字段的值解析为时间
+func (c *Cursor) Time() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, c.Values[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("游标时间格式错误: %w", err)
+	}
+	return t, nil
+}
+
+// SortValues 返回记录在指定排序字段上依次比较的值，格式与游标中的一致；优先级使用数值
+func (t *Todo) SortValues(field SortField) []string {
+	switch field {
+	case SortByUpdatedAt:
+		return []string{t.UpdatedAt.UTC().Format(time.RFC3339Nano)}
+	case SortByTitle:
+		return []string{t.Title}
+	case SortByPosition:
+		return []string{t.Position}
+	case SortByPriority:
+		return []string{strconv.Itoa(int(t.Priority)), t.Position}
+	default:
+		return []string{t.CreatedAt.UTC().Format(time.RFC3339Nano)}
+	}
+}
+
+// ListTodosRequest 列表接口的查询参数，原样保留字符串，由服务层校验并转换
+type ListTodosRequest struct {
+	Limit         string `form:"limit"`
+	Cursor        string `form:"cursor"`
+	Completed     string `form:"completed"`
+	CreatedAfter  string `form:"created_after"`
+	CreatedBefore string `form:"created_before"`
+	UpdatedAfter  string `form:"updated_after"`
+	UpdatedBefore string `form:"updated_before"`
+	Due           string `form:"due"`       // overdue, today, week
+	TZ            string `form:"tz"`        // 计算今天和本周使用的 IANA 时区，默认为 UTC
+	Series        string `form:"series"`    // 重复系列的 ID
+	Tags          string `form:"tags"`      // 逗号分隔的标签 ID
+	TagMatch      string `form:"tag_match"` // any, all，默认为 any
+	Project       string `form:"project"`   // 项目的 ID
+	Sort          string `form:"sort"`      // created_at, updated_at, title, position, priority
+	Order         string `form:"order"`     // asc, desc，默认按位置排序时为 asc，其他为 desc
+	Render        string `form:"render"`    // html 时同时返回渲染后的备注
+}
+
+// TodoListResponse 列表接口的响应
+type TodoListResponse struct {
+	Items []TodoResponse `json:"items"`
+	// NextCursor 获取下一页时作为 cursor 参数传回，没有更多数据时为空
+	NextCursor string `json:"nextCursor,omitempty"`
+	Total      int    `json:"total"`
+}
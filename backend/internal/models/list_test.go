@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/base64"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCursorEncodeDecode(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 8, 30, 0, 123456789, time.FixedZone("CST", 8*3600))
+	todo := Todo{ID: "todo-1", Title: "写周报", CreatedAt: createdAt, Priority: PriorityHigh, Position: "V"}
+
+	tests := []struct {
+		sortBy     SortField
+		sortDir    SortDirection
+		wantValues []string
+	}{
+		{SortByCreatedAt, SortDesc, []string{"2026-03-01T00:30:00.123456789Z"}},
+		{SortByTitle, SortAsc, []string{"写周报"}},
+		{SortByPosition, SortAsc, []string{"V"}},
+		{SortByPriority, SortDesc, []string{"3", "V"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sortBy), func(t *testing.T) {
+			encoded := NewCursor(tt.sortBy, tt.sortDir, todo).Encode()
+
+			cursor, err := DecodeCursor(encoded)
+			if err != nil {
+				t.Fatalf("DecodeCursor(%q) error = %v", encoded, err)
+			}
+			if cursor.SortBy != tt.sortBy || cursor.SortDir != tt.sortDir || cursor.ID != todo.ID {
+				t.Errorf("cursor = %+v, want sort %s %s id %s", cursor, tt.sortBy, tt.sortDir, todo.ID)
+			}
+			if !slices.Equal(cursor.Values, tt.wantValues) {
+				t.Errorf("Values = %q, want %q", cursor.Values, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestCursorTime(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 8, 30, 0, 5, time.UTC)
+	cursor, err := DecodeCursor(NewCursor(SortByCreatedAt, SortAsc, Todo{ID: "a", CreatedAt: createdAt}).Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	got, err := cursor.Time()
+	if err != nil || !got.Equal(createdAt) {
+		t.Errorf("Time() = %v, %v, want %v", got, err, createdAt)
+	}
+
+	cursor, err = DecodeCursor(NewCursor(SortByTitle, SortAsc, Todo{ID: "a", Title: "不是时间"}).Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if _, err := cursor.Time(); err == nil {
+		t.Error("Time() error = nil, want error for non-time value")
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := map[string]string{
+		"not base64":     "!!!",
+		"not json":       encode("title:a:1"),
+		"missing values": encode(`{"s":"title","d":"asc","id":"1"}`),
+		"empty values":   encode(`{"s":"title","d":"asc","v":[],"id":"1"}`),
+		"missing id":     encode(`{"s":"title","d":"asc","v":["a"]}`),
+		"wrong types":    encode(`{"s":"title","d":"asc","v":"a","id":1}`),
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if cursor, err := DecodeCursor(input); err == nil {
+				t.Errorf("DecodeCursor(%q) = %+v, want error", input, cursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NullableTime 请求中可选且可以为 null 的时间字段，能够区分字段未出现和显式设为 null。
+// 时间使用 RFC 3339 格式并保留其中的时区偏移。
+type NullableTime struct {
+	// Set 请求中出现了该字段
+	Set bool
+	// Time 字段的值，为 nil 表示 null
+	Time *time.Time
+	// Invalid 字段不是合法的时间。解析时不返回错误，由服务层校验并报告具体字段
+	Invalid bool
+}
+
+// UnmarshalJSON 解析 JSON 中的时间字段
+func (t *NullableTime) UnmarshalJSON(data []byte) error {
+	*t = NullableTime{Set: true}
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Invalid = true
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		t.Invalid = true
+		return nil
+	}
+	t.Time = &parsed
+	return nil
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Priority 待办事项的优先级，数据库中以整数存储，数值越大优先级越高；JSON 中使用名称
+type Priority int
+
+// 优先级从低到高
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+// priorityNames 优先级的名称，下标为优先级的数值
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// PriorityNames 返回所有优先级的名称，从低到高排列
+func PriorityNames() []string {
+	return append([]string(nil), priorityNames...)
+}
+
+// ParsePriority 解析优先级名称
+func ParsePriority(name string) (Priority, bool) {
+	for i, n := range priorityNames {
+		if n == name {
+			return Priority(i), true
+		}
+	}
+	return PriorityNone, false
+}
+
+// Valid 判断是否是已定义的优先级
+func (p Priority) Valid() bool {
+	return p >= PriorityNone && p <= PriorityUrgent
+}
+
+// String 返回优先级的名称
+func (p Priority) String() string {
+	if !p.Valid() {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// MarshalJSON 编码为优先级名称
+func (p Priority) MarshalJSON() ([]byte, error) {
+	if !p.Valid() {
+		return nil, fmt.Errorf("无效的优先级: %d", int(p))
+	}
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON 解析优先级名称，缺少该字段的旧数据保持为 none
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("优先级必须是字符串: %w", err)
+	}
+	priority, ok := ParsePriority(name)
+	if !ok {
+		return fmt.Errorf("无效的优先级: %q", name)
+	}
+	*p = priority
+	return nil
+}
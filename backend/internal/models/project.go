@@ -0,0 +1,136 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultProjectColor 创建项目时未指定颜色使用的颜色
+	DefaultProjectColor = "#6b7280"
+
+	// InboxProjectName 默认项目的名称，前端可以根据 inbox 字段显示本地化的名称
+	InboxProjectName = "Inbox"
+)
+
+// Project 用户的项目（清单），每个待办事项属于一个项目。
+// 每个用户有且只有一个收件箱（Inbox），第一次使用项目时自动创建，
+// 没有指定项目的待办事项都放在收件箱中。
+type Project struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	// Color #rrggbb 格式的颜色，统一为小写
+	Color string `json:"color"`
+	// Icon 可选的图标名称或 emoji，由前端解释
+	Icon     string `json:"icon,omitempty"`
+	Archived bool   `json:"archived"`
+	// Inbox 是否是收件箱，收件箱不能删除、归档或移动
+	Inbox bool `json:"inbox"`
+	// Position 侧边栏中手动排序的位置，由 rank 包生成，只能通过移动接口修改
+	Position  string    `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ProjectCounts 项目中待办事项的数量
+type ProjectCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+}
+
+// ListProjectsRequest 项目列表的查询参数
+type ListProjectsRequest struct {
+	// IncludeArchived 为 true 时同时返回已归档的项目
+	IncludeArchived string `form:"include_archived"`
+}
+
+// CreateProjectRequest 创建项目请求，字段校验由服务层完成
+type CreateProjectRequest struct {
+	Name string `json:"name"`
+	// Color 可选，为空时使用 DefaultProjectColor
+	Color string `json:"color"`
+	Icon  string `json:"icon"`
+}
+
+// UpdateProjectRequest 更新项目请求，未出现的字段保持不变
+type UpdateProjectRequest struct {
+	Name     *string `json:"name"`
+	Color    *string `json:"color"`
+	Icon     *string `json:"icon"`
+	Archived *bool   `json:"archived"`
+}
+
+// MoveProjectRequest 移动项目的请求，before 和 after 必须且只能提供一个，
+// 分别表示移动到该项目之前或之后
+type MoveProjectRequest struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// DeleteProjectRequest 删除项目的查询参数。
+// cascade 为 true 时同时删除项目中的待办事项，否则把它们移动到 move_to 指定的项目，
+// 未指定 move_to 时移动到收件箱
+type DeleteProjectRequest struct {
+	Cascade string `form:"cascade"`
+	MoveTo  string `form:"move_to"`
+}
+
+// MoveTodosRequest 把多个待办事项移动到同一个项目的请求
+type MoveTodosRequest struct {
+	TodoIDs []string `json:"todoIds"`
+}
+
+// MoveTodosResponse 批量移动待办事项的响应，moved 为实际移动的条数
+type MoveTodosResponse struct {
+	Moved int `json:"moved"`
+}
+
+// ProjectResponse 项目响应，counts 为项目中待办事项的数量
+type ProjectResponse struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Color     string        `json:"color"`
+	Icon      string        `json:"icon"`
+	Archived  bool          `json:"archived"`
+	Inbox     bool          `json:"inbox"`
+	Position  string        `json:"position"`
+	Counts    ProjectCounts `json:"counts"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// ProjectListResponse 项目列表的响应
+type ProjectListResponse struct {
+	Items []ProjectResponse `json:"items"`
+}
+
+// ToResponse 将 Project 转换为 ProjectResponse
+func (p *Project) ToResponse(counts ProjectCounts) ProjectResponse {
+	return ProjectResponse{
+		ID:        p.ID,
+		Name:      p.Name,
+		Color:     p.Color,
+		Icon:      p.Icon,
+		Archived:  p.Archived,
+		Inbox:     p.Inbox,
+		Position:  p.Position,
+		Counts:    counts,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// SortProjects 按侧边栏的顺序排序项目：收件箱总在最前，其余按位置排序，位置相同时按 ID 排序
+func SortProjects(projects []Project) {
+	sort.Slice(projects, func(i, j int) bool {
+		a, b := projects[i], projects[j]
+		if a.Inbox != b.Inbox {
+			return a.Inbox
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.ID < b.ID
+	})
+}
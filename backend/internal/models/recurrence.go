@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Recurrence 重复系列的规则，保存在系列当前未完成的实例上。
+// 实例被标记完成时，规则随下一个实例一起转移，已完成的实例只保留 SeriesID。
+type Recurrence struct {
+	// Rule 规范化后的 RFC 5545 RRULE，不带 "RRULE:" 前缀
+	Rule string `json:"rule"`
+	// Timezone 计算实例日期使用的 IANA 时区
+	Timezone string `json:"timezone"`
+	// Start 系列的起始时间（DTSTART），COUNT 从这里开始计数，实例的本地时刻也取自这里
+	Start time.Time `json:"start"`
+}
+
+// RecurrenceRequest 设置或修改重复规则的请求
+type RecurrenceRequest struct {
+	Rule string `json:"rule"`
+	// Timezone 为空时使用 UTC
+	Timezone string `json:"timezone"`
+}
+
+// RecurrenceResponse 重复规则的响应
+type RecurrenceResponse struct {
+	Rule     string    `json:"rule"`
+	Timezone string    `json:"timezone"`
+	Start    time.Time `json:"start"`
+}
+
+// ToResponse 将 Recurrence 转换为 RecurrenceResponse，nil 返回 nil
+func (r *Recurrence) ToResponse() *RecurrenceResponse {
+	if r == nil {
+		return nil
+	}
+	return &RecurrenceResponse{
+		Rule:     r.Rule,
+		Timezone: r.Timezone,
+		Start:    r.Start,
+	}
+}
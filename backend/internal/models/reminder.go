@@ -0,0 +1,127 @@
+package models
+
+import "time"
+
+// Reminder 一个到期的提醒，由提醒调度器领取后通过各通知渠道发送
+type Reminder struct {
+	TodoID string
+	// UserID 接收提醒的用户。仓库领取时为待办事项的所有者，工作区的待办事项为工作区 ID，
+	// 调度器再把它展开为每个成员一份提醒
+	UserID string
+	// WorkspaceID 待办事项所属的工作区，个人的待办事项为空
+	WorkspaceID string
+	// Recipients 工作区成员的用户 ID，按 ID 排序，由仓库领取时一并查出；个人的待办事项为空
+	Recipients []string
+	Title      string
+	DueAt      *time.Time
+	RemindAt   time.Time
+	// Attempts 此前发送失败的次数
+	Attempts int
+}
+
+// Key 提醒的幂等键。同一待办事项的同一提醒时间只对应一个键，
+// 修改提醒时间后产生新的提醒和新的键；工作区的每个成员收到的提醒的键各不相同
+func (r *Reminder) Key() string {
+	remindAt := r.RemindAt.UTC().Format(time.RFC3339Nano)
+	if r.WorkspaceID != "" {
+		return "reminder:" + r.TodoID + ":" + r.UserID + ":" + remindAt
+	}
+	return "reminder:" + r.TodoID + ":" + remindAt
+}
+
+// NotificationKind 站内通知的类型
+type NotificationKind string
+
+// 站内通知类型
+const (
+	NotificationReminder NotificationKind = "reminder"
+)
+
+// Notification 站内通知
+type Notification struct {
+	ID     string           `json:"id"`
+	UserID string           `json:"user_id"`
+	TodoID string           `json:"todoId,omitempty"`
+	Kind   NotificationKind `json:"kind"`
+	// Title 通知标题，提醒通知为待办事项的标题
+	Title string     `json:"title"`
+	DueAt *time.Time `json:"dueAt,omitempty"`
+	// Key 幂等键，同一用户相同键的通知只保存一次
+	Key       string     `json:"key"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ReadAt    *time.Time `json:"readAt,omitempty"`
+}
+
+// NotificationListOptions 仓库层的站内通知查询参数，按创建时间倒序分页
+type NotificationListOptions struct {
+	UnreadOnly bool
+	// Limit 单页最多返回的条数，由服务层保证大于 0
+	Limit int
+	// After 上一页最后一条通知的游标，为 nil 时从第一页开始
+	After *Cursor
+}
+
+// NotificationPage 一页站内通知
+type NotificationPage struct {
+	Items []Notification
+	// Next 下一页的游标，没有更多数据时为 nil
+	Next *Cursor
+	// Unread 未读通知的总数，与分页和过滤条件无关
+	Unread int
+}
+
+// NewNotificationCursor 根据一条通知生成指向它之后的游标
+func NewNotificationCursor(n Notification) *Cursor {
+	return &Cursor{
+		SortBy:  SortByCreatedAt,
+		SortDir: SortDesc,
+		Values:  []string{n.CreatedAt.UTC().Format(time.RFC3339Nano)},
+		ID:      n.ID,
+	}
+}
+
+// ListNotificationsRequest 站内通知列表的查询参数，由服务层校验并转换
+type ListNotificationsRequest struct {
+	Limit  string `form:"limit"`
+	Cursor string `form:"cursor"`
+	Unread string `form:"unread"` // 为 true 时只返回未读通知
+}
+
+// NotificationResponse 站内通知响应
+type NotificationResponse struct {
+	ID        string           `json:"id"`
+	TodoID    string           `json:"todoId,omitempty"`
+	Kind      NotificationKind `json:"kind"`
+	Title     string           `json:"title"`
+	DueAt     *time.Time       `json:"dueAt"`
+	CreatedAt time.Time        `json:"createdAt"`
+	ReadAt    *time.Time       `json:"readAt"`
+}
+
+// ToResponse 将 Notification 转换为 NotificationResponse
+func (n *Notification) ToResponse() NotificationResponse {
+	return NotificationResponse{
+		ID:        n.ID,
+		TodoID:    n.TodoID,
+		Kind:      n.Kind,
+		Title:     n.Title,
+		DueAt:     n.DueAt,
+		CreatedAt: n.CreatedAt,
+		ReadAt:    n.ReadAt,
+	}
+}
+
+// NotificationListResponse 站内通知列表的响应
+type NotificationListResponse struct {
+	Items []NotificationResponse `json:"items"`
+	// NextCursor 获取下一页时作为 cursor 参数传回，没有更多数据时为空
+	NextCursor string `json:"nextCursor,omitempty"`
+	// Unread 未读通知的总数
+	Unread int `json:"unread"`
+}
+
+// MarkAllReadResponse 全部标记为已读的响应
+type MarkAllReadResponse struct {
+	// Updated 本次标记为已读的通知数量
+	Updated int `json:"updated"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminderKey(t *testing.T) {
+	remindAt := time.Date(2026, 5, 1, 9, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	personal := Reminder{TodoID: "todo-1", UserID: "user-1", RemindAt: remindAt}
+	if got, want := personal.Key(), "reminder:todo-1:2026-05-01T01:00:00Z"; got != want {
+		t.Errorf("personal Key() = %q, want %q", got, want)
+	}
+
+	alice := Reminder{TodoID: "todo-1", UserID: "alice", WorkspaceID: "ws-1", RemindAt: remindAt}
+	bob := alice
+	bob.UserID = "bob"
+	if got, want := alice.Key(), "reminder:todo-1:alice:2026-05-01T01:00:00Z"; got != want {
+		t.Errorf("workspace Key() = %q, want %q", got, want)
+	}
+	if alice.Key() == bob.Key() {
+		t.Errorf("members share the key %q", alice.Key())
+	}
+}
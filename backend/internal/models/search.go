@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// SortByRelevance 搜索结果按相关度排序，只出现在搜索接口的游标中
+const SortByRelevance SortField = "relevance"
+
+// TodoSearchOptions 仓库层的搜索参数
+type TodoSearchOptions struct {
+	// Query 去掉首尾空白后的查询串，由服务层保证不为空
+	Query     string
+	Completed *bool
+	// Limit 单页最多返回的条数，由服务层保证大于 0
+	Limit int
+	// After 上一页最后一条结果的游标，为 nil 时从第一页开始
+	After *Cursor
+}
+
+// TodoSearchHit 一条搜索结果
+type TodoSearchHit struct {
+	Todo  Todo
+	Score float64
+}
+
+// TodoSearchPage 一页搜索结果，按相关度从高到低排序，相关度相同时按 ID 倒序
+type TodoSearchPage struct {
+	Hits []TodoSearchHit
+	// Next 下一页的游标，没有更多数据时为 nil
+	Next *Cursor
+	// Total 匹配的总条数，与分页无关
+	Total int
+}
+
+// NewSearchCursor 根据一条搜索结果生成指向它之后的游标
+func NewSearchCursor(hit TodoSearchHit) *Cursor {
+	return &Cursor{
+		SortBy:  SortByRelevance,
+		SortDir: SortDesc,
+		Values:  []string{FormatScore(hit.Score)},
+		ID:      hit.Todo.ID,
+	}
+}
+
+// FormatScore 将相关度格式化为游标中的值。相关度按 float32 精度计算，
+// 使用最短的 float32 表示可以原样解析回来，与数据库中 real 类型的值精确比较
+func FormatScore(score float64) string {
+	return strconv.FormatFloat(score, 'g', -1, 32)
+}
+
+// Score 将第一个排序字段的值解析为相关度
+func (c *Cursor) Score() (float64, error) {
+	score, err := strconv.ParseFloat(c.Values[0], 32)
+	if err != nil {
+		return 0, fmt.Errorf("游标相关度格式错误: %w", err)
+	}
+	return score, nil
+}
+
+// SearchTodosRequest 搜索接口的查询参数，原样保留字符串，由服务层校验并转换
+type SearchTodosRequest struct {
+	Query     string `form:"q"`
+	Limit     string `form:"limit"`
+	Cursor    string `form:"cursor"`
+	Completed string `form:"completed"`
+	Render    string `form:"render"` // html 时同时返回渲染后的备注
+}
+
+// TextRange 文本中的一个区间，按 Unicode 字符（码点）计算，包含 Start、不包含 End
+type TextRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// TodoSearchResult 一条搜索结果的响应
+type TodoSearchResult struct {
+	TodoResponse
+	// Score 相关度，包含查询串的结果不小于 1，只有模糊匹配的结果在 0 到 1 之间
+	Score float32 `json:"score"`
+	// Highlights 按字段名列出需要高亮的区间，例如 {"title": [{"start": 0, "end": 3}]}
+	Highlights map[string][]TextRange `json:"highlights"`
+}
+
+// TodoSearchResponse 搜索接口的响应
+type TodoSearchResponse struct {
+	Items []TodoSearchResult `json:"items"`
+	// NextCursor 获取下一页时作为 cursor 参数传回，没有更多数据时为空
+	NextCursor string `json:"nextCursor,omitempty"`
+	Total      int    `json:"total"`
+}
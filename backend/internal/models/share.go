@@ -0,0 +1,184 @@
+package models
+
+import "time"
+
+// ShareRole 共享的角色，权限依次递增：viewer 只能查看，editor 可以修改内容，
+// owner 与所有者相同，还可以删除和管理共享
+type ShareRole string
+
+const (
+	ShareRoleViewer ShareRole = "viewer"
+	ShareRoleEditor ShareRole = "editor"
+	ShareRoleOwner  ShareRole = "owner"
+)
+
+// ShareRoles 所有的共享角色，按权限从低到高排列
+var ShareRoles = []ShareRole{ShareRoleViewer, ShareRoleEditor, ShareRoleOwner}
+
+// ParseShareRole 解析共享角色
+func ParseShareRole(s string) (ShareRole, bool) {
+	for _, role := range ShareRoles {
+		if string(role) == s {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// rank 角色的权限等级，未知的角色为 0
+func (r ShareRole) rank() int {
+	switch r {
+	case ShareRoleViewer:
+		return 1
+	case ShareRoleEditor:
+		return 2
+	case ShareRoleOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Allows 判断该角色是否具有 need 要求的权限
+func (r ShareRole) Allows(need ShareRole) bool {
+	return r.rank() > 0 && r.rank() >= need.rank()
+}
+
+// MaxShareRole 返回权限最高的角色，roles 为空时返回空字符串
+func MaxShareRole(roles ...ShareRole) ShareRole {
+	var max ShareRole
+	for _, role := range roles {
+		if role.rank() > max.rank() {
+			max = role
+		}
+	}
+	return max
+}
+
+// ShareResourceType 可以共享的资源类型
+type ShareResourceType string
+
+const (
+	// ShareTodo 共享单个待办事项，以及它的子任务、评论和附件
+	ShareTodo ShareResourceType = "todo"
+	// ShareProject 共享项目中的所有待办事项，包括之后新加入的
+	ShareProject ShareResourceType = "project"
+)
+
+// Access 用户对一个待办事项或项目的访问权限。
+// OwnerID 是资源的所有者，读写资源时以所有者的身份访问仓库层
+type Access struct {
+	OwnerID string
+	Role    ShareRole
+}
+
+// Share 把待办事项或项目共享给另一个用户，同一用户对同一资源只有一条记录。
+// 删除资源时一并删除
+type Share struct {
+	ID           string            `json:"id"`
+	ResourceType ShareResourceType `json:"resource_type"`
+	ResourceID   string            `json:"resource_id"`
+	// OwnerID 资源的所有者，UserID 被共享的用户
+	OwnerID string    `json:"owner_id"`
+	UserID  string    `json:"user_id"`
+	Role    ShareRole `json:"role"`
+	// CreatedBy 发出邀请的用户，可能是所有者，也可能是角色为 owner 的共享用户
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Invitation 通过邮箱发出的共享邀请。令牌只在创建时返回一次，这里只保存它的 SHA-256；
+// 接受后邀请被删除，过期的邀请不能再接受
+type Invitation struct {
+	ID           string            `json:"id"`
+	ResourceType ShareResourceType `json:"resource_type"`
+	ResourceID   string            `json:"resource_id"`
+	OwnerID      string            `json:"owner_id"`
+	// Email 被邀请的邮箱，统一为小写
+	Email     string    `json:"email"`
+	Role      ShareRole `json:"role"`
+	TokenHash string    `json:"token_hash"`
+	InvitedBy string    `json:"invited_by"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CreateInvitationRequest 邀请用户共享待办事项或项目
+type CreateInvitationRequest struct {
+	Email string `json:"email"`
+	// Role 可选，默认为 viewer
+	Role string `json:"role"`
+}
+
+// AcceptInvitationRequest 接受邀请，Token 为邀请邮件或创建邀请时返回的令牌
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+// ShareResponse 共享响应
+type ShareResponse struct {
+	ID           string            `json:"id"`
+	ResourceType ShareResourceType `json:"resourceType"`
+	ResourceID   string            `json:"resourceId"`
+	OwnerID      string            `json:"ownerId"`
+	UserID       string            `json:"userId"`
+	Role         ShareRole         `json:"role"`
+	CreatedBy    string            `json:"createdBy"`
+	CreatedAt    time.Time         `json:"createdAt"`
+}
+
+// ToResponse 将 Share 转换为 ShareResponse
+func (s *Share) ToResponse() ShareResponse {
+	return ShareResponse{
+		ID:           s.ID,
+		ResourceType: s.ResourceType,
+		ResourceID:   s.ResourceID,
+		OwnerID:      s.OwnerID,
+		UserID:       s.UserID,
+		Role:         s.Role,
+		CreatedBy:    s.CreatedBy,
+		CreatedAt:    s.CreatedAt,
+	}
+}
+
+// InvitationResponse 邀请响应。Token 和 AcceptURL 只在创建邀请时返回
+type InvitationResponse struct {
+	ID           string            `json:"id"`
+	ResourceType ShareResourceType `json:"resourceType"`
+	ResourceID   string            `json:"resourceId"`
+	Email        string            `json:"email"`
+	Role         ShareRole         `json:"role"`
+	InvitedBy    string            `json:"invitedBy"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	ExpiresAt    time.Time         `json:"expiresAt"`
+	Token        string            `json:"token,omitempty"`
+	AcceptURL    string            `json:"acceptUrl,omitempty"`
+	// EmailSent 是否已经发送邀请邮件，只在创建邀请时返回
+	EmailSent *bool `json:"emailSent,omitempty"`
+}
+
+// ToResponse 将 Invitation 转换为 InvitationResponse
+func (i *Invitation) ToResponse() InvitationResponse {
+	return InvitationResponse{
+		ID:           i.ID,
+		ResourceType: i.ResourceType,
+		ResourceID:   i.ResourceID,
+		Email:        i.Email,
+		Role:         i.Role,
+		InvitedBy:    i.InvitedBy,
+		CreatedAt:    i.CreatedAt,
+		ExpiresAt:    i.ExpiresAt,
+	}
+}
+
+// ShareListResponse 资源的共享列表，Invitations 为还没有接受且未过期的邀请
+type ShareListResponse struct {
+	Items       []ShareResponse      `json:"items"`
+	Invitations []InvitationResponse `json:"invitations"`
+}
+
+// SharedWithMeResponse 共享给当前用户的待办事项和项目
+type SharedWithMeResponse struct {
+	Items []ShareResponse `json:"items"`
+}
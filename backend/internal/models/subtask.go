@@ -0,0 +1,109 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Subtask 待办事项的子任务（检查清单中的一项），有自己的完成状态，
+// 按位置排序，不出现在待办事项列表中，删除待办事项时一并删除
+type Subtask struct {
+	ID        string `json:"id"`
+	TodoID    string `json:"todo_id"`
+	UserID    string `json:"user_id"`
+	Title     string `json:"title"`
+	Completed bool   `json:"completed"`
+	// Position 在清单中手动排序的位置，由 rank 包生成，只能通过移动接口修改
+	Position  string    `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SubtaskProgress 子任务的完成进度，例如 3/5
+type SubtaskProgress struct {
+	Completed int `json:"completed"`
+	Total     int `json:"total"`
+}
+
+// Done 判断是否有子任务且全部已完成
+func (p SubtaskProgress) Done() bool {
+	return p.Total > 0 && p.Completed == p.Total
+}
+
+// CreateSubtaskRequest 添加子任务请求，新的子任务排在清单的最后
+type CreateSubtaskRequest struct {
+	Title     string `json:"title"`
+	Completed bool   `json:"completed"`
+}
+
+// UpdateSubtaskRequest 更新子任务请求，未出现的字段保持不变
+type UpdateSubtaskRequest struct {
+	Title     *string `json:"title"`
+	Completed *bool   `json:"completed"`
+}
+
+// MoveSubtaskRequest 移动子任务的请求，before 和 after 必须且只能提供一个，
+// 分别表示移动到同一清单中该子任务之前或之后
+type MoveSubtaskRequest struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// SubtaskResponse 子任务响应
+type SubtaskResponse struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Completed bool      `json:"completed"`
+	Position  string    `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SubtaskListResponse 子任务清单的响应，同时返回所属的待办事项，
+// 其中带有最新的完成进度，以及自动完成时的完成状态和下一个重复实例
+type SubtaskListResponse struct {
+	Items []SubtaskResponse `json:"items"`
+	Todo  TodoResponse      `json:"todo"`
+}
+
+// ToResponse 将 Subtask 转换为 SubtaskResponse
+func (s *Subtask) ToResponse() SubtaskResponse {
+	return SubtaskResponse{
+		ID:        s.ID,
+		Title:     s.Title,
+		Completed: s.Completed,
+		Position:  s.Position,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// ToSubtaskResponseList 将 Subtask 列表转换为 SubtaskResponse 列表，nil 转换为空数组
+func ToSubtaskResponseList(subtasks []Subtask) []SubtaskResponse {
+	result := make([]SubtaskResponse, len(subtasks))
+	for i := range subtasks {
+		result[i] = subtasks[i].ToResponse()
+	}
+	return result
+}
+
+// NewSubtaskProgress 统计子任务的完成进度
+func NewSubtaskProgress(subtasks []Subtask) SubtaskProgress {
+	progress := SubtaskProgress{Total: len(subtasks)}
+	for i := range subtasks {
+		if subtasks[i].Completed {
+			progress.Completed++
+		}
+	}
+	return progress
+}
+
+// SortSubtasks 按位置排序子任务，位置相同时按 ID 排序
+func SortSubtasks(subtasks []Subtask) {
+	sort.Slice(subtasks, func(i, j int) bool {
+		if subtasks[i].Position != subtasks[j].Position {
+			return subtasks[i].Position < subtasks[j].Position
+		}
+		return subtasks[i].ID < subtasks[j].ID
+	})
+}
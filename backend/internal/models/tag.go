@@ -0,0 +1,94 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultTagColor 创建标签时未指定颜色使用的颜色
+const DefaultTagColor = "#6b7280"
+
+// Tag 用户的标签，同一用户的标签名称不区分大小写唯一。
+// 待办事项与标签是多对多关系，一个待办事项可以带有多个标签。
+type Tag struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Name   string `json:"name"`
+	// Color #rrggbb 格式的颜色，统一为小写
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TagMatch 按多个标签过滤时的匹配方式
+type TagMatch string
+
+// 标签匹配方式
+const (
+	TagMatchAny TagMatch = "any" // 带有任意一个标签
+	TagMatchAll TagMatch = "all" // 带有全部标签
+)
+
+// CreateTagRequest 创建标签请求，字段校验由服务层完成
+type CreateTagRequest struct {
+	Name string `json:"name"`
+	// Color 可选，为空时使用 DefaultTagColor
+	Color string `json:"color"`
+}
+
+// UpdateTagRequest 更新标签请求，未出现的字段保持不变
+type UpdateTagRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+// SetTodoTagsRequest 替换待办事项全部标签的请求，为空数组时去掉所有标签
+type SetTodoTagsRequest struct {
+	TagIDs []string `json:"tagIds"`
+}
+
+// TagResponse 标签响应
+type TagResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TagListResponse 标签列表的响应
+type TagListResponse struct {
+	Items []TagResponse `json:"items"`
+}
+
+// ToResponse 将 Tag 转换为 TagResponse
+func (t *Tag) ToResponse() TagResponse {
+	return TagResponse{
+		ID:        t.ID,
+		Name:      t.Name,
+		Color:     t.Color,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+}
+
+// ToTagResponseList 将标签列表转换为响应列表，没有标签时返回空数组而不是 nil
+func ToTagResponseList(tags []Tag) []TagResponse {
+	result := make([]TagResponse, len(tags))
+	for i := range tags {
+		result[i] = tags[i].ToResponse()
+	}
+	return result
+}
+
+// SortTags 按名称排序标签，不区分大小写，名称相同时按 ID 排序
+func SortTags(tags []Tag) {
+	sort.Slice(tags, func(i, j int) bool {
+		a, b := strings.ToLower(tags[i].Name), strings.ToLower(tags[j].Name)
+		if a != b {
+			return a < b
+		}
+		return tags[i].ID < tags[j].ID
+	})
+}
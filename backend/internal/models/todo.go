@@ -4,12 +4,33 @@ import "time"
 
 // Todo 表示一个待办事项
 type Todo struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	Title     string    `json:"title" binding:"required"`
-	Completed bool      `json:"completed"`
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Title     string `json:"title" binding:"required"`
+	Completed bool   `json:"completed"`
+	// DueAt 截止时间，RemindAt 提醒时间，都是可选的
+	DueAt    *time.Time `json:"dueAt,omitempty"`
+	RemindAt *time.Time `json:"remindAt,omitempty"`
+	// SeriesID 所属重复系列的 ID，Recurrence 为系列的重复规则，只有系列当前的实例带有规则
+	SeriesID   string      `json:"seriesId,omitempty"`
+	Recurrence *Recurrence `json:"recurrence,omitempty"`
+	Priority   Priority    `json:"priority"`
+	// Position 手动排序的位置，由 rank 包生成，按字节比较，只能通过移动接口修改
+	Position string `json:"position"`
+	// ProjectID 所属项目的 ID，为空表示还没有分配项目，创建收件箱时会被移入收件箱
+	ProjectID string `json:"projectId,omitempty"`
+	// AutoComplete 为 true 时，所有子任务都完成后自动完成待办事项
+	AutoComplete bool `json:"autoComplete,omitempty"`
+	// Notes Markdown 格式的备注，保存原文，渲染由 markdown 包完成
+	Notes     string    `json:"notes,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+	// Tags 待办事项的标签，按名称排序。标签单独存储，由服务层在返回前填充
+	Tags []Tag `json:"-"`
+	// Progress 子任务的完成进度，子任务单独存储，由服务层在返回前填充
+	Progress SubtaskProgress `json:"-"`
+	// CommentCount 评论数，评论单独存储，由服务层在返回前填充
+	CommentCount int `json:"-"`
 }
 
 // TodoList 表示待办事项列表
@@ -17,25 +38,90 @@ type TodoList struct {
 	Items []Todo `json:"items"`
 }
 
-// CreateTodoRequest 创建待办事项请求
+// CreateTodoRequest 创建待办事项请求，字段校验由服务层的 TodoValidator 完成
 type CreateTodoRequest struct {
-	Title     string `json:"title" binding:"required"`
-	Completed bool   `json:"completed"`
+	Title     string       `json:"title"`
+	Completed bool         `json:"completed"`
+	DueAt     NullableTime `json:"dueAt"`
+	RemindAt  NullableTime `json:"remindAt"`
+	// Recurrence 可选的重复规则，设置时必须同时设置 dueAt
+	Recurrence *RecurrenceRequest `json:"recurrence"`
+	// Priority 优先级名称，为空时为 none
+	Priority string `json:"priority"`
+	// ProjectID 所属项目，为空时放入收件箱
+	ProjectID string `json:"projectId"`
+	// AutoComplete 所有子任务都完成后是否自动完成待办事项
+	AutoComplete bool `json:"autoComplete"`
+	// Notes Markdown 格式的备注
+	Notes string `json:"notes"`
 }
 
-// UpdateTodoRequest 更新待办事项请求
+// UpdateTodoRequest 更新待办事项请求。
+// 时间字段未出现时保持不变，为 null 时清除。
 type UpdateTodoRequest struct {
-	Title     *string `json:"title"`
-	Completed *bool   `json:"completed"`
+	Title        *string      `json:"title"`
+	Completed    *bool        `json:"completed"`
+	DueAt        NullableTime `json:"dueAt"`
+	RemindAt     NullableTime `json:"remindAt"`
+	Priority     *string      `json:"priority"`
+	ProjectID    *string      `json:"projectId"`
+	AutoComplete *bool        `json:"autoComplete"`
+	Notes        *string      `json:"notes"`
 }
 
-// TodoResponse 待办事项响应
+// ReplaceTodoRequest 整体替换待办事项请求（PUT），title 和 completed 必须提供，
+// 未提供的时间字段视为 null，未提供的优先级视为 none，未提供的项目视为收件箱，
+// 未提供的 autoComplete 视为 false，未提供的备注视为空
+type ReplaceTodoRequest struct {
+	Title        string       `json:"title"`
+	Completed    *bool        `json:"completed"`
+	DueAt        NullableTime `json:"dueAt"`
+	RemindAt     NullableTime `json:"remindAt"`
+	Priority     string       `json:"priority"`
+	ProjectID    string       `json:"projectId"`
+	AutoComplete bool         `json:"autoComplete"`
+	Notes        string       `json:"notes"`
+}
+
+// GetTodoRequest 获取单个待办事项的查询参数，由服务层校验
+type GetTodoRequest struct {
+	Render string `form:"render"` // html 时同时返回渲染后的备注
+}
+
+// RenderHTML render 参数的取值，表示同时返回渲染为 HTML 的备注
+const RenderHTML = "html"
+
+// MoveTodoRequest 移动待办事项的请求，before 和 after 必须且只能提供一个，
+// 分别表示移动到该待办事项之前或之后
+type MoveTodoRequest struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// TodoResponse 待办事项响应，未设置的时间字段为 null
 type TodoResponse struct {
-	ID        string    `json:"id"`
-	Title     string    `json:"title"`
-	Completed bool      `json:"completed"`
+	ID         string              `json:"id"`
+	Title      string              `json:"title"`
+	Completed  bool                `json:"completed"`
+	DueAt      *time.Time          `json:"dueAt"`
+	RemindAt   *time.Time          `json:"remindAt"`
+	SeriesID   *string             `json:"seriesId"`
+	Recurrence *RecurrenceResponse `json:"recurrence"`
+	Priority   Priority            `json:"priority"`
+	Position   string              `json:"position"`
+	ProjectID  *string             `json:"projectId"`
+	Tags       []TagResponse       `json:"tags"`
+	// Progress 子任务的完成进度，AutoComplete 所有子任务完成后是否自动完成
+	Progress     SubtaskProgress `json:"progress"`
+	AutoComplete bool            `json:"autoComplete"`
+	CommentCount int             `json:"commentCount"`
+	// Notes Markdown 格式的备注原文，NotesHTML 为渲染后的 HTML，只在请求 render=html 时返回
+	Notes     string    `json:"notes"`
+	NotesHTML string    `json:"notesHtml,omitempty"`
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
+	// NextOccurrence 本次操作完成了重复系列的实例时，自动创建的下一个实例
+	NextOccurrence *TodoResponse `json:"nextOccurrence,omitempty"`
 }
 
 // TodosResponse 多个待办事项的响应
@@ -59,13 +145,32 @@ func NewTodosResponse(todos []Todo) TodosResponse {
 
 // ToResponse 将 Todo 转换为 TodoResponse
 func (t *Todo) ToResponse() TodoResponse {
-	return TodoResponse{
-		ID:        t.ID,
-		Title:     t.Title,
-		Completed: t.Completed,
-		CreatedAt: t.CreatedAt,
-		UpdatedAt: t.UpdatedAt,
+	response := TodoResponse{
+		ID:           t.ID,
+		Title:        t.Title,
+		Completed:    t.Completed,
+		DueAt:        t.DueAt,
+		RemindAt:     t.RemindAt,
+		Recurrence:   t.Recurrence.ToResponse(),
+		Priority:     t.Priority,
+		Position:     t.Position,
+		Tags:         ToTagResponseList(t.Tags),
+		Progress:     t.Progress,
+		AutoComplete: t.AutoComplete,
+		CommentCount: t.CommentCount,
+		Notes:        t.Notes,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+	if t.SeriesID != "" {
+		seriesID := t.SeriesID
+		response.SeriesID = &seriesID
+	}
+	if t.ProjectID != "" {
+		projectID := t.ProjectID
+		response.ProjectID = &projectID
 	}
+	return response
 }
 
 // ToResponseList 将 Todo 列表转换为 TodoResponse 列表
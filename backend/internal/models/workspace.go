@@ -0,0 +1,173 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// WorkspaceRole 工作区成员的角色，权限依次递增：guest 只能查看，member 可以修改内容，
+// admin 还可以删除、管理共享和成员，owner 还可以管理管理员和删除工作区
+type WorkspaceRole string
+
+const (
+	WorkspaceGuest  WorkspaceRole = "guest"
+	WorkspaceMember WorkspaceRole = "member"
+	WorkspaceAdmin  WorkspaceRole = "admin"
+	WorkspaceOwner  WorkspaceRole = "owner"
+)
+
+// WorkspaceRoles 所有的工作区角色，按权限从低到高排列
+var WorkspaceRoles = []WorkspaceRole{WorkspaceGuest, WorkspaceMember, WorkspaceAdmin, WorkspaceOwner}
+
+// ParseWorkspaceRole 解析工作区角色
+func ParseWorkspaceRole(s string) (WorkspaceRole, bool) {
+	for _, role := range WorkspaceRoles {
+		if string(role) == s {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// rank 角色的权限等级，未知的角色为 0
+func (r WorkspaceRole) rank() int {
+	switch r {
+	case WorkspaceGuest:
+		return 1
+	case WorkspaceMember:
+		return 2
+	case WorkspaceAdmin:
+		return 3
+	case WorkspaceOwner:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Allows 判断该角色是否具有 need 要求的权限
+func (r WorkspaceRole) Allows(need WorkspaceRole) bool {
+	return r.rank() > 0 && r.rank() >= need.rank()
+}
+
+// ShareRole 该角色对工作区中的待办事项、项目和标签的权限，与共享的角色相同：
+// guest 为 viewer，member 为 editor，admin 和 owner 为 owner
+func (r WorkspaceRole) ShareRole() ShareRole {
+	switch r {
+	case WorkspaceGuest:
+		return ShareRoleViewer
+	case WorkspaceMember:
+		return ShareRoleEditor
+	case WorkspaceAdmin, WorkspaceOwner:
+		return ShareRoleOwner
+	default:
+		return ""
+	}
+}
+
+// Workspace 团队的工作区。工作区中的项目、待办事项和标签以工作区 ID 作为所有者保存，
+// 删除工作区时一并删除
+type Workspace struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedBy string `json:"created_by"`
+	// Role 当前用户在工作区中的角色，只在按用户获取工作区列表时填充
+	Role      WorkspaceRole `json:"role,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// Member 工作区的成员，同一用户在同一工作区中只有一条记录
+type Member struct {
+	WorkspaceID string        `json:"workspace_id"`
+	UserID      string        `json:"user_id"`
+	Role        WorkspaceRole `json:"role"`
+	CreatedAt   time.Time     `json:"createdAt"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+}
+
+// memberContextKey 请求上下文中当前工作区成员身份的键
+type memberContextKey struct{}
+
+// ContextWithMember 把当前请求所在工作区的成员身份保存到上下文中，由认证中间件调用
+func ContextWithMember(ctx context.Context, member *Member) context.Context {
+	return context.WithValue(ctx, memberContextKey{}, member)
+}
+
+// MemberFromContext 返回当前请求所在工作区的成员身份，请求没有选择工作区时返回 false
+func MemberFromContext(ctx context.Context) (*Member, bool) {
+	member, ok := ctx.Value(memberContextKey{}).(*Member)
+	return member, ok && member != nil
+}
+
+// CreateWorkspaceRequest 创建工作区请求
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+// UpdateWorkspaceRequest 修改工作区请求
+type UpdateWorkspaceRequest struct {
+	Name *string `json:"name"`
+}
+
+// AddMemberRequest 添加工作区成员请求
+type AddMemberRequest struct {
+	UserID string `json:"userId"`
+	// Role 可选，默认为 member
+	Role string `json:"role"`
+}
+
+// UpdateMemberRequest 修改工作区成员角色请求
+type UpdateMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// WorkspaceResponse 工作区响应，Role 为当前用户的角色
+type WorkspaceResponse struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	CreatedBy string        `json:"createdBy"`
+	Role      WorkspaceRole `json:"role"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// ToResponse 将 Workspace 转换为 WorkspaceResponse
+func (w *Workspace) ToResponse() WorkspaceResponse {
+	return WorkspaceResponse{
+		ID:        w.ID,
+		Name:      w.Name,
+		CreatedBy: w.CreatedBy,
+		Role:      w.Role,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// WorkspaceListResponse 当前用户加入的工作区列表
+type WorkspaceListResponse struct {
+	Items []WorkspaceResponse `json:"items"`
+}
+
+// MemberResponse 工作区成员响应
+type MemberResponse struct {
+	UserID    string        `json:"userId"`
+	Role      WorkspaceRole `json:"role"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// ToResponse 将 Member 转换为 MemberResponse
+func (m *Member) ToResponse() MemberResponse {
+	return MemberResponse{
+		UserID:    m.UserID,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+// MemberListResponse 工作区的成员列表
+type MemberListResponse struct {
+	Items []MemberResponse `json:"items"`
+}
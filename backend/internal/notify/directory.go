@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/repository/supabase"
+)
+
+// UserDirectory 查询用户的邮箱地址
+type UserDirectory interface {
+	// Email 返回用户的邮箱地址，用户不存在或没有邮箱时返回空字符串
+	Email(ctx context.Context, userID string) (string, error)
+}
+
+// StaticDirectory 使用配置中固定的用户 ID 到邮箱的映射
+type StaticDirectory map[string]string
+
+// Email 返回映射中的邮箱地址
+func (d StaticDirectory) Email(_ context.Context, userID string) (string, error) {
+	return d[userID], nil
+}
+
+// ChainDirectory 依次查询多个目录，返回第一个非空的邮箱地址
+type ChainDirectory []UserDirectory
+
+// Email 依次查询各个目录
+func (d ChainDirectory) Email(ctx context.Context, userID string) (string, error) {
+	for _, directory := range d {
+		email, err := directory.Email(ctx, userID)
+		if err != nil {
+			return "", err
+		}
+		if email != "" {
+			return email, nil
+		}
+	}
+	return "", nil
+}
+
+// SupabaseDirectory 通过 Supabase Auth 的管理接口查询用户的邮箱，需要服务角色密钥
+type SupabaseDirectory struct {
+	client *supabase.Client
+}
+
+// NewSupabaseDirectory 创建 Supabase 用户目录
+func NewSupabaseDirectory(cfg *config.SupabaseConfig) (*SupabaseDirectory, error) {
+	client, err := supabase.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SupabaseDirectory{client: client}, nil
+}
+
+// Email 调用 GET /auth/v1/admin/users/{id} 查询用户
+func (d *SupabaseDirectory) Email(ctx context.Context, userID string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		d.client.GetAuthURL()+"/admin/users/"+url.PathEscape(userID), nil)
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("apikey", d.client.GetAPIKey())
+	req.Header.Set("Authorization", "Bearer "+d.client.GetAPIKey())
+
+	resp, err := d.client.GetHTTPClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("查询用户失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", nil
+	case resp.StatusCode >= http.StatusBadRequest:
+		return "", fmt.Errorf("查询用户失败: 状态码 %d", resp.StatusCode)
+	}
+
+	var user struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return "", fmt.Errorf("解析用户信息失败: %w", err)
+	}
+	return user.Email, nil
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// InAppNotifier 把提醒保存为站内通知
+type InAppNotifier struct {
+	repo repository.NotificationRepository
+}
+
+// NewInAppNotifier 创建站内通知渠道
+func NewInAppNotifier(repo repository.NotificationRepository) *InAppNotifier {
+	return &InAppNotifier{repo: repo}
+}
+
+// Name 渠道名称
+func (n *InAppNotifier) Name() string {
+	return "in_app"
+}
+
+// Notify 保存站内通知，同一个提醒重复发送时只保存一次
+func (n *InAppNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	return n.repo.CreateNotification(ctx, &models.Notification{
+		UserID:    reminder.UserID,
+		TodoID:    reminder.TodoID,
+		Kind:      models.NotificationReminder,
+		Title:     reminder.Title,
+		DueAt:     reminder.DueAt,
+		Key:       reminder.Key(),
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,55 @@
+// Package notify 实现提醒的各个通知渠道：站内通知、Webhook 和邮件。
+//
+// 提醒调度器保证同一个提醒不会被多个实例同时发送，但实例在发送后、确认前退出时，
+// 提醒会在租约到期后再次发送。每个渠道都带上 Reminder.Key 作为幂等键：
+// 站内通知按键去重，Webhook 通过 Idempotency-Key 请求头、邮件通过 Message-ID 交给接收方去重。
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// Notifier 一个通知渠道
+type Notifier interface {
+	// Name 渠道名称，用于日志
+	Name() string
+
+	// Notify 发送一个提醒。返回错误时调度器会稍后重试，
+	// 因此永远不会成功的情况（例如用户没有邮箱）应当直接返回 nil
+	Notify(ctx context.Context, reminder models.Reminder) error
+}
+
+// FromConfig 根据 reminders 配置创建启用的通知渠道。
+// 站内通知需要存储后端实现 NotificationRepository，notifications 为 nil 时跳过；
+// 邮件的收件人先查 smtp.recipients，配置了 Supabase 时再通过 Supabase Auth 查询。
+func FromConfig(cfg *config.Config, notifications repository.NotificationRepository) ([]Notifier, error) {
+	reminders := cfg.Reminders
+
+	var notifiers []Notifier
+	if reminders.InApp.Enabled && notifications != nil {
+		notifiers = append(notifiers, NewInAppNotifier(notifications))
+	}
+	if reminders.Webhook.URL != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(reminders.Webhook))
+	}
+	if reminders.SMTP.Host != "" {
+		if reminders.SMTP.From == "" {
+			return nil, fmt.Errorf("设置了 reminders.smtp.host 但缺少 reminders.smtp.from")
+		}
+		directory := ChainDirectory{StaticDirectory(reminders.SMTP.Recipients)}
+		if cfg.Supabase.BaseURL != "" && cfg.Supabase.ServiceRoleKey != "" {
+			supabaseDirectory, err := NewSupabaseDirectory(&cfg.Supabase)
+			if err != nil {
+				return nil, err
+			}
+			directory = append(directory, supabaseDirectory)
+		}
+		notifiers = append(notifiers, NewSMTPNotifier(reminders.SMTP, directory))
+	}
+	return notifiers, nil
+}
@@ -0,0 +1,206 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+// defaultSMTPTimeout 未配置 smtp.timeout 时一次发送的超时时间
+const defaultSMTPTimeout = 10 * time.Second
+
+// SMTPNotifier 通过 SMTP 发送提醒邮件，也用于发送共享邀请。
+// 收件人通过 UserDirectory 查询；同一个提醒的邮件使用相同的 Message-ID，便于收件方去重。
+type SMTPNotifier struct {
+	cfg       config.SMTPNotifierConfig
+	addr      string
+	timeout   time.Duration
+	directory UserDirectory
+	logger    *zap.Logger
+}
+
+// NewSMTPNotifier 创建邮件通知渠道
+func NewSMTPNotifier(cfg config.SMTPNotifierConfig, directory UserDirectory) *SMTPNotifier {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+	return &SMTPNotifier{
+		cfg:       cfg,
+		addr:      net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		timeout:   timeout,
+		directory: directory,
+		logger:    logger.Log.With(zap.String("component", "SMTPNotifier")),
+	}
+}
+
+// Name 渠道名称
+func (n *SMTPNotifier) Name() string {
+	return "smtp"
+}
+
+// Notify 发送提醒邮件。用户没有邮箱时跳过，不视为失败。
+func (n *SMTPNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	to, err := n.directory.Email(ctx, reminder.UserID)
+	if err != nil {
+		return fmt.Errorf("查询收件人失败: %w", err)
+	}
+	if to == "" {
+		logger.WithContext(ctx, n.logger).Warn("用户没有邮箱，跳过邮件提醒",
+			zap.String("userID", reminder.UserID),
+			zap.String("todoID", reminder.TodoID))
+		return nil
+	}
+
+	msg, err := n.message(to, reminder)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+	return n.send(ctx, to, msg)
+}
+
+// send 连接 SMTP 服务器并发送一封邮件
+func (n *SMTPNotifier) send(ctx context.Context, to string, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		return fmt.Errorf("SMTP 握手失败: %w", err)
+	}
+	defer client.Close()
+
+	if n.cfg.StartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: n.cfg.Host}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS 失败: %w", err)
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.fromAddress()); err != nil {
+		return fmt.Errorf("SMTP MAIL 命令失败: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("SMTP RCPT 命令失败: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA 命令失败: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return fmt.Errorf("写入邮件内容失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	// 邮件已被服务器接受，QUIT 失败不影响结果
+	_ = client.Quit()
+	return nil
+}
+
+// message 生成提醒邮件的内容
+func (n *SMTPNotifier) message(to string, reminder models.Reminder) ([]byte, error) {
+	lines := []string{"待办事项提醒：" + reminder.Title}
+	if reminder.DueAt != nil {
+		lines = append(lines, "截止时间："+reminder.DueAt.UTC().Format(time.RFC3339))
+	}
+	return n.compose(to, "待办事项提醒："+reminder.Title, n.messageID(reminder.Key()), lines)
+}
+
+// SendInvitation 发送共享邀请邮件，link 为接受邀请的链接或令牌。
+// 同一个邀请的邮件使用相同的 Message-ID
+func (n *SMTPNotifier) SendInvitation(ctx context.Context, invitation models.Invitation, link string) error {
+	subject := "你收到了一个共享待办事项的邀请"
+	if invitation.ResourceType == models.ShareProject {
+		subject = "你收到了一个共享项目的邀请"
+	}
+	msg, err := n.compose(invitation.Email, subject, n.messageID("invitation:"+invitation.ID), []string{
+		subject + "，角色：" + string(invitation.Role),
+		"接受邀请：" + link,
+		"有效期至：" + invitation.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+	return n.send(ctx, invitation.Email, msg)
+}
+
+// compose 生成邮件内容，正文使用 quoted-printable 编码的 UTF-8 纯文本，每个元素一行
+func (n *SMTPNotifier) compose(to, subject, messageID string, lines []string) ([]byte, error) {
+	var body bytes.Buffer
+	qp := quotedprintable.NewWriter(&body)
+	for _, line := range lines {
+		fmt.Fprintf(qp, "%s\r\n", line)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("编码邮件正文失败: %w", err)
+	}
+
+	var msg bytes.Buffer
+	header := func(key, value string) {
+		msg.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", n.cfg.From)
+	header("To", to)
+	header("Subject", mime.QEncoding.Encode("UTF-8", subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID)
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// messageID 根据幂等键生成固定的 Message-ID
+func (n *SMTPNotifier) messageID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	domain := "localhost"
+	if _, host, ok := strings.Cut(n.fromAddress(), "@"); ok && host != "" {
+		domain = host
+	}
+	return "<" + hex.EncodeToString(sum[:16]) + "@" + domain + ">"
+}
+
+// fromAddress 返回发件人的邮箱地址，From 可以是 "名称 <地址>" 的形式
+func (n *SMTPNotifier) fromAddress() string {
+	if addr, err := mail.ParseAddress(n.cfg.From); err == nil {
+		return addr.Address
+	}
+	return n.cfg.From
+}
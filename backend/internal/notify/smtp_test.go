@@ -0,0 +1,306 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"mime"
+	"net"
+	"net/mail"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// smtpMessage 测试 SMTP 服务器收到的一封邮件
+type smtpMessage struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// fakeSMTPServer 只实现发送邮件所需命令的 SMTP 服务器，rejectRcpt 中的收件人返回 550
+type fakeSMTPServer struct {
+	listener   net.Listener
+	rejectRcpt string
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	s := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// config 返回连接到该服务器的配置
+func (s *fakeSMTPServer) config() config.SMTPNotifierConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return config.SMTPNotifierConfig{
+		Host:    "127.0.0.1",
+		Port:    addr.Port,
+		From:    "Todo <todo@example.com>",
+		Timeout: 5 * time.Second,
+	}
+}
+
+func (s *fakeSMTPServer) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	var msg smtpMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			_, credentials, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(credentials)
+			msg.auth = string(decoded)
+			reply("235 ok")
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			reply("250 ok")
+		case "RCPT":
+			to := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if to == s.rejectRcpt {
+				reply("550 no such user")
+				continue
+			}
+			msg.to = append(msg.to, to)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			msg = smtpMessage{}
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func testReminder() models.Reminder {
+	dueAt := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	return models.Reminder{
+		TodoID:   "todo-1",
+		UserID:   "user-1",
+		Title:    "提交报销单",
+		DueAt:    &dueAt,
+		RemindAt: dueAt.Add(-time.Hour),
+	}
+}
+
+func TestSMTPNotifierNotify(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	n := NewSMTPNotifier(server.config(), StaticDirectory{"user-1": "alice@example.com"})
+	reminder := testReminder()
+
+	if err := n.Notify(context.Background(), reminder); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if err := n.Notify(context.Background(), reminder); err != nil {
+		t.Fatalf("second Notify() error = %v", err)
+	}
+
+	messages := server.received()
+	if len(messages) != 2 {
+		t.Fatalf("received %d messages, want 2", len(messages))
+	}
+	got := messages[0]
+	if got.from != "todo@example.com" {
+		t.Errorf("MAIL FROM = %q, want todo@example.com", got.from)
+	}
+	if len(got.to) != 1 || got.to[0] != "alice@example.com" {
+		t.Errorf("RCPT TO = %v, want [alice@example.com]", got.to)
+	}
+	if got.auth != "" {
+		t.Errorf("AUTH = %q, want none without username", got.auth)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatalf("mail.ReadMessage() error = %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "待办事项提醒：提交报销单" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+	if to := msg.Header.Get("To"); to != "alice@example.com" {
+		t.Errorf("To = %q, want alice@example.com", to)
+	}
+	if ct := msg.Header.Get("Content-Type"); ct != "text/plain; charset=UTF-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	// 同一个提醒的邮件使用相同的 Message-ID，收件方据此去重
+	id := msg.Header.Get("Message-ID")
+	if !strings.HasPrefix(id, "<") || !strings.HasSuffix(id, "@example.com>") {
+		t.Errorf("Message-ID = %q, want <...@example.com>", id)
+	}
+	second, err := mail.ReadMessage(strings.NewReader(messages[1].data))
+	if err != nil {
+		t.Fatalf("mail.ReadMessage() error = %v", err)
+	}
+	if second.Header.Get("Message-ID") != id {
+		t.Errorf("Message-ID changed between sends: %q, %q", id, second.Header.Get("Message-ID"))
+	}
+
+	// 修改提醒时间后是新的提醒，Message-ID 也不同
+	reminder.RemindAt = reminder.RemindAt.Add(time.Minute)
+	if err := n.Notify(context.Background(), reminder); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	third, _ := mail.ReadMessage(strings.NewReader(server.received()[2].data))
+	if third.Header.Get("Message-ID") == id {
+		t.Error("Message-ID unchanged after RemindAt changed")
+	}
+}
+
+func TestSMTPNotifierAuth(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	cfg := server.config()
+	cfg.Username = "mailer"
+	cfg.Password = "secret"
+	n := NewSMTPNotifier(cfg, StaticDirectory{"user-1": "alice@example.com"})
+
+	if err := n.Notify(context.Background(), testReminder()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got := server.received()[0].auth; got != "\x00mailer\x00secret" {
+		t.Errorf("AUTH PLAIN = %q, want \\x00mailer\\x00secret", got)
+	}
+}
+
+func TestSMTPNotifierSkipsUserWithoutEmail(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	n := NewSMTPNotifier(server.config(), StaticDirectory{})
+
+	if err := n.Notify(context.Background(), testReminder()); err != nil {
+		t.Fatalf("Notify() error = %v, want nil for user without email", err)
+	}
+	if got := len(server.received()); got != 0 {
+		t.Errorf("received %d messages, want 0", got)
+	}
+}
+
+func TestSMTPNotifierErrors(t *testing.T) {
+	t.Run("rejected recipient", func(t *testing.T) {
+		server := newFakeSMTPServer(t)
+		server.rejectRcpt = "alice@example.com"
+		n := NewSMTPNotifier(server.config(), StaticDirectory{"user-1": "alice@example.com"})
+
+		if err := n.Notify(context.Background(), testReminder()); err == nil {
+			t.Fatal("Notify() error = nil, want RCPT error")
+		}
+	})
+
+	t.Run("connection refused", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("net.Listen() error = %v", err)
+		}
+		port := listener.Addr().(*net.TCPAddr).Port
+		listener.Close()
+
+		n := NewSMTPNotifier(config.SMTPNotifierConfig{Host: "127.0.0.1", Port: port, From: "todo@example.com"},
+			StaticDirectory{"user-1": "alice@example.com"})
+		if err := n.Notify(context.Background(), testReminder()); err == nil {
+			t.Fatal("Notify() error = nil, want connection error")
+		}
+	})
+}
+
+func TestSMTPNotifierSendInvitation(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	n := NewSMTPNotifier(server.config(), nil)
+	invitation := models.Invitation{
+		ID:           "inv-1",
+		ResourceType: models.ShareProject,
+		Email:        "bob@example.com",
+		Role:         models.ShareRoleEditor,
+		ExpiresAt:    time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if err := n.SendInvitation(context.Background(), invitation, "https://todo.example.com/invite?token=abc"); err != nil {
+		t.Fatalf("SendInvitation() error = %v", err)
+	}
+	messages := server.received()
+	if len(messages) != 1 || len(messages[0].to) != 1 || messages[0].to[0] != "bob@example.com" {
+		t.Fatalf("received = %+v, want one message to bob@example.com", messages)
+	}
+	msg, err := mail.ReadMessage(strings.NewReader(messages[0].data))
+	if err != nil {
+		t.Fatalf("mail.ReadMessage() error = %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "你收到了一个共享项目的邀请" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if !strings.Contains(messages[0].data, "token=3Dabc") {
+		t.Errorf("body does not contain the quoted-printable link: %q", messages[0].data)
+	}
+}
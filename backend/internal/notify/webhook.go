@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/models"
+)
+
+// defaultWebhookTimeout 未配置 webhook.timeout 时单次请求的超时时间
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookEventReminder 提醒事件的类型
+const WebhookEventReminder = "todo.reminder"
+
+// WebhookPayload Webhook 请求体
+type WebhookPayload struct {
+	Event  string `json:"event"`
+	Key    string `json:"key"`
+	UserID string `json:"user_id"`
+	// WorkspaceID 待办事项所属的工作区，个人的待办事项不包含该字段
+	WorkspaceID string     `json:"workspace_id,omitempty"`
+	TodoID      string     `json:"todo_id"`
+	Title       string     `json:"title"`
+	DueAt       *time.Time `json:"due_at"`
+	RemindAt    time.Time  `json:"remind_at"`
+	SentAt      time.Time  `json:"sent_at"`
+}
+
+// WebhookNotifier 以 JSON POST 请求把提醒发送到配置的 URL。
+// 请求头 Idempotency-Key 为提醒的幂等键；配置了 secret 时，
+// X-Signature 为请求体的 HMAC-SHA256 签名，格式为 sha256=<十六进制>。
+// 响应状态码不是 2xx 时视为失败。
+type WebhookNotifier struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookNotifier 创建 Webhook 通知渠道
+func NewWebhookNotifier(cfg config.WebhookNotifierConfig) *WebhookNotifier {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookNotifier{
+		url:    cfg.URL,
+		secret: []byte(cfg.Secret),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name 渠道名称
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify 发送 Webhook 请求
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	body, err := json.Marshal(WebhookPayload{
+		Event:       WebhookEventReminder,
+		Key:         reminder.Key(),
+		UserID:      reminder.UserID,
+		WorkspaceID: reminder.WorkspaceID,
+		TodoID:      reminder.TodoID,
+		Title:       reminder.Title,
+		DueAt:       reminder.DueAt,
+		RemindAt:    reminder.RemindAt,
+		SentAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("序列化 Webhook 请求体失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建 Webhook 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", reminder.Key())
+	if len(n.secret) > 0 {
+		req.Header.Set("X-Signature", Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送 Webhook 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	// 读完响应体以便复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign 计算请求体的签名，接收方可以用同样的方法校验
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
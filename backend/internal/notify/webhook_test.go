@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+)
+
+// webhookRequest 测试服务器收到的一个 Webhook 请求
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newWebhookServer 启动测试服务器，依次返回 statuses 中的状态码，用完后返回 200
+func newWebhookServer(t *testing.T, statuses ...int) (*httptest.Server, chan webhookRequest) {
+	t.Helper()
+
+	requests := make(chan webhookRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- webhookRequest{header: r.Header.Clone(), body: body}
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestWebhookNotifierNotify(t *testing.T) {
+	server, requests := newWebhookServer(t)
+	secret := "whsec-test"
+	n := NewWebhookNotifier(config.WebhookNotifierConfig{URL: server.URL, Secret: secret})
+	reminder := testReminder()
+
+	if err := n.Notify(context.Background(), reminder); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	req := <-requests
+
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := req.header.Get("Idempotency-Key"); got != reminder.Key() {
+		t.Errorf("Idempotency-Key = %q, want %q", got, reminder.Key())
+	}
+
+	// 接收方按文档用同一个密钥重新计算签名
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(req.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.header.Get("X-Signature"); got != want {
+		t.Errorf("X-Signature = %q, want %q", got, want)
+	}
+	if got := Sign([]byte("other-secret"), req.body); got == want {
+		t.Error("Sign() with a different secret produced the same signature")
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if payload.Event != WebhookEventReminder || payload.Key != reminder.Key() ||
+		payload.TodoID != reminder.TodoID || payload.UserID != reminder.UserID || payload.Title != reminder.Title {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.DueAt == nil || !payload.DueAt.Equal(*reminder.DueAt) || !payload.RemindAt.Equal(reminder.RemindAt) {
+		t.Errorf("payload times = %v, %v", payload.DueAt, payload.RemindAt)
+	}
+	if time.Since(payload.SentAt) > time.Minute {
+		t.Errorf("SentAt = %v, want now", payload.SentAt)
+	}
+}
+
+func TestWebhookNotifierWithoutSecret(t *testing.T) {
+	server, requests := newWebhookServer(t)
+	n := NewWebhookNotifier(config.WebhookNotifierConfig{URL: server.URL})
+
+	if err := n.Notify(context.Background(), testReminder()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got := (<-requests).header.Get("X-Signature"); got != "" {
+		t.Errorf("X-Signature = %q, want none without secret", got)
+	}
+}
+
+func TestWebhookNotifierStatus(t *testing.T) {
+	tests := []struct {
+		status  int
+		wantErr bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusAccepted, false},
+		{http.StatusNoContent, false},
+		{http.StatusMovedPermanently, true},
+		{http.StatusBadRequest, true},
+		{http.StatusInternalServerError, true},
+		{http.StatusServiceUnavailable, true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server, _ := newWebhookServer(t, tt.status)
+			n := NewWebhookNotifier(config.WebhookNotifierConfig{URL: server.URL})
+
+			err := n.Notify(context.Background(), testReminder())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	n := NewWebhookNotifier(config.WebhookNotifierConfig{URL: server.URL, Timeout: 50 * time.Millisecond})
+	if err := n.Notify(context.Background(), testReminder()); err == nil {
+		t.Fatal("Notify() error = nil, want timeout")
+	}
+}
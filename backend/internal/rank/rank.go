@@ -0,0 +1,119 @@
+// Package rank 生成用于手动排序的字典序位置。
+//
+// 位置是由 0-9A-Za-z 组成的字符串，按字节比较大小，可以看作 [0, 1) 之间的 62 进制小数。
+// 任意两个不同的位置之间总能生成一个新位置，因此移动一条记录只需要修改它自己的位置。
+// 位置不以 0 结尾，保证在它之前总有空间。PostgreSQL 中需要使用 COLLATE "C" 按字节排序。
+package rank
+
+import (
+	"fmt"
+	"strings"
+)
+
+// digits 位置使用的字符，按字节升序排列
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Between 返回严格位于 a 和 b 之间的位置。a 为空表示最前，b 为空表示最后，
+// 两者都为空时返回列表中第一个位置。b 为空时在 a 的基础上递增，连续追加时位置长度只按对数增长。
+func Between(a, b string) (string, error) {
+	if err := validate(a); err != nil {
+		return "", err
+	}
+	if err := validate(b); err != nil {
+		return "", err
+	}
+	if a != "" && b != "" && a >= b {
+		return "", fmt.Errorf("位置 %q 必须小于 %q", a, b)
+	}
+	if a != "" && b == "" {
+		return increment(a), nil
+	}
+	return midpoint(a, b), nil
+}
+
+// Valid 判断字符串是否是合法的位置
+func Valid(s string) bool {
+	return s != "" && validate(s) == nil
+}
+
+// validate 检查位置只包含合法字符且不以 0 结尾，空字符串表示列表的一端
+func validate(s string) error {
+	if s == "" {
+		return nil
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(digits, s[i]) < 0 {
+			return fmt.Errorf("位置 %q 包含非法字符", s)
+		}
+	}
+	if s[len(s)-1] == digits[0] {
+		return fmt.Errorf("位置 %q 不能以 0 结尾", s)
+	}
+	return nil
+}
+
+// midpoint 返回 a 和 b 之间的位置，b 为空表示 1
+func midpoint(a, b string) string {
+	if b != "" {
+		// 跳过公共前缀，a 较短时按 0 补齐
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(suffix(a, n), b[n:])
+		}
+	}
+
+	// 首位不同：首位之间还有空间时取中间的字符
+	low, high := 0, len(digits)
+	if a != "" {
+		low = strings.IndexByte(digits, a[0])
+	}
+	if b != "" {
+		high = strings.IndexByte(digits, b[0])
+	}
+	if high-low > 1 {
+		return string(digits[(low+high)/2])
+	}
+
+	// 首位相邻：b 有多位时它的首位本身就在两者之间，否则保留 a 的首位并在后面继续取中间值
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[low]) + midpoint(suffix(a, 1), "")
+}
+
+// increment 把 a 看作定长的 62 进制整数加一，进位后末位为 0 时改为 1。
+// a 的每一位都是 z 时无法进位，在后面补上同样位数的 0…01，之后可以在新的位数上继续递增。
+func increment(a string) string {
+	b := []byte(a)
+	for i := len(b) - 1; i >= 0; i-- {
+		d := strings.IndexByte(digits, b[i])
+		if d < len(digits)-1 {
+			b[i] = digits[d+1]
+			if b[len(b)-1] == digits[0] {
+				b[len(b)-1] = digits[1]
+			}
+			return string(b)
+		}
+		b[i] = digits[0]
+	}
+	return a + strings.Repeat(digits[:1], len(a)-1) + digits[1:2]
+}
+
+// digitAt 返回第 i 位的字符，超出长度时为 0
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+// suffix 返回第 i 位之后的部分
+func suffix(s string, i int) string {
+	if i < len(s) {
+		return s[i:]
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package rank
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"", "", "V"},
+		{"", "V", "F"},
+		{"V", "", "W"},
+		{"1", "2", "1V"},
+		{"1", "3", "2"},
+		{"1z", "2", "1zV"},
+		{"1", "1V", "1F"},
+		{"z", "", "z1"},
+		{"1z", "", "21"},
+		{"zz", "", "zz01"},
+		{"zz01", "", "zz02"},
+		{"zzzz", "", "zzzz0001"},
+	}
+	for _, tt := range tests {
+		got, err := Between(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("Between(%q, %q) error = %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Between(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestBetweenOrdering(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	positions := []string{}
+	for i := 0; i < 2000; i++ {
+		// 随机选择插入点，空字符串表示两端
+		var a, b string
+		if n := len(positions); n > 0 {
+			j := r.Intn(n + 1)
+			if j > 0 {
+				a = positions[j-1]
+			}
+			if j < n {
+				b = positions[j]
+			}
+		}
+		got, err := Between(a, b)
+		if err != nil {
+			t.Fatalf("Between(%q, %q) error = %v", a, b, err)
+		}
+		if !Valid(got) || (a != "" && got <= a) || (b != "" && got >= b) {
+			t.Fatalf("Between(%q, %q) = %q, not strictly between", a, b, got)
+		}
+		positions = append(positions, got)
+		sort.Strings(positions)
+	}
+	for i := 1; i < len(positions); i++ {
+		if positions[i-1] == positions[i] {
+			t.Fatalf("duplicate position %q", positions[i])
+		}
+	}
+}
+
+func TestBetweenAppendLength(t *testing.T) {
+	var last string
+	for i := 0; i < 100000; i++ {
+		next, err := Between(last, "")
+		if err != nil {
+			t.Fatalf("Between(%q, \"\") error = %v", last, err)
+		}
+		if next <= last {
+			t.Fatalf("Between(%q, \"\") = %q, want greater", last, next)
+		}
+		last = next
+	}
+	// 连续追加时位置长度按对数增长
+	if len(last) > 8 {
+		t.Errorf("position after 100000 appends = %q, want at most 8 characters", last)
+	}
+}
+
+func TestBetweenInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"illegal character", "a-b", ""},
+		{"illegal character in upper bound", "", "é"},
+		{"trailing zero", "V0", ""},
+		{"trailing zero in upper bound", "", "10"},
+		{"equal", "V", "V"},
+		{"reversed", "W", "V"},
+		{"prefix reversed", "V1", "V"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Between(tt.a, tt.b); err == nil {
+				t.Errorf("Between(%q, %q) = %q, want error", tt.a, tt.b, got)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := map[string]bool{
+		"":    false,
+		"V":   true,
+		"0V":  true,
+		"zz1": true,
+		"0":   false,
+		"V0":  false,
+		"V V": false,
+	}
+	for s, want := range tests {
+		if got := Valid(s); got != want {
+			t.Errorf("Valid(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
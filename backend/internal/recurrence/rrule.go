@@ -0,0 +1,522 @@
+// Package recurrence 解析 RFC 5545 的 RRULE 重复规则，并计算重复系列的下一个实例。
+//
+// 支持 FREQ 为 DAILY、WEEKLY、MONTHLY、YEARLY，以及 INTERVAL、COUNT、UNTIL、BYMONTH、
+// BYMONTHDAY、BYDAY、BYSETPOS 和 WKST，不支持精确到小时以下的规则部分。
+// 每个实例的时刻取自系列的起始时间（DTSTART），日期在起始时间所在的时区中计算，
+// 因此跨越夏令时切换时实例保持相同的本地时刻。
+package recurrence
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency 重复频率
+type Frequency string
+
+// 支持的重复频率
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods 计算下一个实例时最多检查的周期数，防止永远不会产生实例的规则
+// （例如 FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30）无限循环
+const maxPeriods = 5000
+
+// weekdayNames RRULE 中的星期缩写
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// WeekdayNum BYDAY 中的一项。N 不为 0 时表示月内或年内的第 N 个该星期几，负数从末尾数起
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// String 返回 RRULE 中的写法，例如 MO、1FR、-1SU
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Weekday]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Weekday]
+}
+
+// Rule 解析后的重复规则
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	// Count 系列的实例总数（包括第一个），为 0 表示不限
+	Count int
+	// Until 最后一个实例的时间上限，为 nil 表示不限
+	Until      *Until
+	ByMonth    []time.Month
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// Until UNTIL 的取值。RFC 5545 允许 UTC 时间、不带时区的本地时间和日期三种形式，
+// 后两种在系列所在的时区中解释
+type Until struct {
+	Time time.Time
+	// Local 为不带时区的本地时间，Time 的时区没有意义
+	Local bool
+	// DateOnly 只有日期，包含当天的所有实例
+	DateOnly bool
+}
+
+// String 返回 RRULE 中的写法
+func (u *Until) String() string {
+	switch {
+	case u.DateOnly:
+		return u.Time.Format("20060102")
+	case u.Local:
+		return u.Time.Format("20060102T150405")
+	default:
+		return u.Time.UTC().Format("20060102T150405Z")
+	}
+}
+
+// in 返回 UNTIL 在指定时区中对应的时间上限
+func (u *Until) in(loc *time.Location) time.Time {
+	t := u.Time
+	switch {
+	case u.DateOnly:
+		return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 999999999, loc)
+	case u.Local:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+	default:
+		return t
+	}
+}
+
+// Parse 解析 RRULE 字符串，可以带有 "RRULE:" 前缀，规则部分的名称和取值不区分大小写
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 6 && strings.EqualFold(s[:6], "RRULE:") {
+		s = s[6:]
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("规则部分 %q 格式错误", part)
+		}
+		name, value = strings.ToUpper(strings.TrimSpace(name)), strings.ToUpper(strings.TrimSpace(value))
+		if seen[name] {
+			return nil, fmt.Errorf("规则部分 %s 重复出现", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			switch freq := Frequency(value); freq {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = freq
+			default:
+				err = fmt.Errorf("不支持的重复频率 %s", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseInt(value, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseInt(value, 1, 10000)
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "BYMONTH":
+			err = parseList(value, func(v string) error {
+				month, err := parseInt(v, 1, 12)
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+				return err
+			})
+		case "BYMONTHDAY":
+			err = parseList(value, func(v string) error {
+				day, err := parseSignedInt(v, 31)
+				rule.ByMonthDay = append(rule.ByMonthDay, day)
+				return err
+			})
+		case "BYDAY":
+			err = parseList(value, func(v string) error {
+				day, err := parseWeekdayNum(v)
+				rule.ByDay = append(rule.ByDay, day)
+				return err
+			})
+		case "BYSETPOS":
+			err = parseList(value, func(v string) error {
+				pos, err := parseSignedInt(v, 366)
+				rule.BySetPos = append(rule.BySetPos, pos)
+				return err
+			})
+		case "WKST":
+			rule.WeekStart, err = parseWeekday(value)
+		default:
+			err = fmt.Errorf("不支持的规则部分 %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rule.check(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// check 检查规则部分之间的约束
+func (r *Rule) check() error {
+	if r.Freq == "" {
+		return fmt.Errorf("缺少 FREQ")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return fmt.Errorf("COUNT 和 UNTIL 不能同时出现")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return fmt.Errorf("FREQ=WEEKLY 时不能使用 BYMONTHDAY")
+	}
+	if r.Freq != Monthly && r.Freq != Yearly {
+		for _, day := range r.ByDay {
+			if day.N != 0 {
+				return fmt.Errorf("只有 FREQ=MONTHLY 或 YEARLY 时 BYDAY 才能带序号")
+			}
+		}
+	}
+	if len(r.BySetPos) > 0 && len(r.ByMonth)+len(r.ByMonthDay)+len(r.ByDay) == 0 {
+		return fmt.Errorf("BYSETPOS 必须与其他 BY 规则一起使用")
+	}
+	return nil
+}
+
+// String 返回规范化的 RRULE 字符串，不带 "RRULE:" 前缀，规则部分按固定顺序排列
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.String())
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next 返回起始时间为 start 的系列中严格晚于 after 的第一个实例。
+// 实例在 start 所在的时区中计算；系列已经结束（COUNT 或 UNTIL）时 ok 为 false。
+func (r *Rule) Next(start, after time.Time) (next time.Time, ok bool) {
+	loc := start.Location()
+	var until time.Time
+	if r.Until != nil {
+		until = r.Until.in(loc)
+		if until.Before(start) {
+			return time.Time{}, false
+		}
+	}
+
+	// 没有 COUNT 时不需要从头数实例个数，直接跳到 after 之前的周期
+	first := 0
+	if r.Count == 0 {
+		first = max(r.periodsBetween(start, after.In(loc))-1, 0)
+	}
+
+	count := 0
+	for period := first; period < first+maxPeriods; period++ {
+		for _, t := range r.occurrences(start, period) {
+			if t.Before(start) {
+				continue
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return time.Time{}, false
+			}
+			if r.Until != nil && t.After(until) {
+				return time.Time{}, false
+			}
+			if t.After(after) {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// periodsBetween 返回 start 到 t 之间完整的周期数，用于跳过不需要检查的周期
+func (r *Rule) periodsBetween(start, t time.Time) int {
+	if !t.After(start) {
+		return 0
+	}
+	var n int
+	switch r.Freq {
+	case Daily:
+		n = daysBetween(start, t)
+	case Weekly:
+		n = daysBetween(start, t) / 7
+	case Monthly:
+		n = (t.Year()-start.Year())*12 + int(t.Month()-start.Month())
+	case Yearly:
+		n = t.Year() - start.Year()
+	}
+	return n / r.Interval
+}
+
+// occurrences 返回第 period 个周期中的所有实例，按时间升序排列
+func (r *Rule) occurrences(start time.Time, period int) []time.Time {
+	year, month, day := start.Date()
+	step := period * r.Interval
+
+	// 周期内的第一天和天数
+	var first time.Time
+	var days int
+	switch r.Freq {
+	case Daily:
+		first, days = date(year, month, day+step), 1
+	case Weekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		first, days = date(year, month, day-offset+7*step), 7
+	case Monthly:
+		first = date(year, month+time.Month(step), 1)
+		days = daysIn(first.Year(), first.Month())
+	case Yearly:
+		first = date(year+step, time.January, 1)
+		days = daysInYear(first.Year())
+	}
+
+	var dates []time.Time
+	for i := 0; i < days; i++ {
+		d := first.AddDate(0, 0, i)
+		if r.matches(start, d) {
+			dates = append(dates, d)
+		}
+	}
+	dates = r.applySetPos(dates)
+
+	hour, minute, second := start.Clock()
+	result := make([]time.Time, len(dates))
+	for i, d := range dates {
+		result[i] = time.Date(d.Year(), d.Month(), d.Day(), hour, minute, second, start.Nanosecond(), start.Location())
+	}
+	return result
+}
+
+// matches 判断日期 d 是否属于周期内的实例。
+// 没有任何 BY 规则时，按频率使用起始时间的星期、日期和月份，与 RFC 5545 一致。
+func (r *Rule) matches(start, d time.Time) bool {
+	noDayRules := len(r.ByMonthDay) == 0 && len(r.ByDay) == 0
+
+	byMonth := r.ByMonth
+	if r.Freq == Yearly && noDayRules && len(byMonth) == 0 {
+		byMonth = []time.Month{start.Month()}
+	}
+	if len(byMonth) > 0 && !slices.Contains(byMonth, d.Month()) {
+		return false
+	}
+
+	byMonthDay := r.ByMonthDay
+	if (r.Freq == Monthly || r.Freq == Yearly) && noDayRules {
+		byMonthDay = []int{start.Day()}
+	}
+	if len(byMonthDay) > 0 && !matchesMonthDay(byMonthDay, d) {
+		return false
+	}
+
+	byDay := r.ByDay
+	if r.Freq == Weekly && len(byDay) == 0 {
+		byDay = []WeekdayNum{{Weekday: start.Weekday()}}
+	}
+	if len(byDay) > 0 && !r.matchesWeekday(byDay, d) {
+		return false
+	}
+	return true
+}
+
+// matchesMonthDay 判断日期是否是 BYMONTHDAY 中的某一天，负数从月末数起
+func matchesMonthDay(monthDays []int, d time.Time) bool {
+	last := daysIn(d.Year(), d.Month())
+	for _, day := range monthDays {
+		if day == d.Day() || (day < 0 && last+day+1 == d.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday 判断日期是否是 BYDAY 中的某个星期几。带序号时，
+// FREQ=MONTHLY 或指定了 BYMONTH 的 YEARLY 在月内计数，否则在年内计数
+func (r *Rule) matchesWeekday(weekdays []WeekdayNum, d time.Time) bool {
+	for _, w := range weekdays {
+		if w.Weekday != d.Weekday() {
+			continue
+		}
+		if w.N == 0 {
+			return true
+		}
+
+		index, total := d.Day()-1, daysIn(d.Year(), d.Month())
+		if r.Freq == Yearly && len(r.ByMonth) == 0 {
+			index, total = d.YearDay()-1, daysInYear(d.Year())
+		}
+		if w.N > 0 && index/7+1 == w.N {
+			return true
+		}
+		if w.N < 0 && -((total-1-index)/7+1) == w.N {
+			return true
+		}
+	}
+	return false
+}
+
+// applySetPos 按 BYSETPOS 从周期内的实例中选出指定位置的实例，负数从末尾数起
+func (r *Rule) applySetPos(dates []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(dates) == 0 {
+		return dates
+	}
+	var selected []time.Time
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(dates) + pos
+		}
+		if i >= 0 && i < len(dates) && !slices.ContainsFunc(selected, dates[i].Equal) {
+			selected = append(selected, dates[i])
+		}
+	}
+	slices.SortFunc(selected, time.Time.Compare)
+	return selected
+}
+
+// date 返回 UTC 中的日期，超出范围的月和日会自动进位
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// daysIn 返回指定月份的天数
+func daysIn(year int, month time.Month) int {
+	return date(year, month+1, 0).Day()
+}
+
+// daysInYear 返回指定年份的天数
+func daysInYear(year int) int {
+	return date(year, time.December, 31).YearDay()
+}
+
+// daysBetween 返回两个时间在各自时区中的日期相差的天数
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return int(date(by, bm, bd).Sub(date(ay, am, ad)).Hours() / 24)
+}
+
+// parseInt 解析取值范围为 [lo, hi] 的整数
+func parseInt(s string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%q 必须是 %d 到 %d 之间的整数", s, lo, hi)
+	}
+	return n, nil
+}
+
+// parseSignedInt 解析绝对值为 [1, limit] 的整数，允许负数
+func parseSignedInt(s string, limit int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n == 0 || n < -limit || n > limit {
+		return 0, fmt.Errorf("%q 必须是绝对值为 1 到 %d 的整数", s, limit)
+	}
+	return n, nil
+}
+
+// parseList 解析逗号分隔的列表
+func parseList(s string, parse func(string) error) error {
+	for _, item := range strings.Split(s, ",") {
+		if err := parse(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseWeekday 解析星期缩写
+func parseWeekday(s string) (time.Weekday, error) {
+	i := slices.Index(weekdayNames, s)
+	if i < 0 {
+		return 0, fmt.Errorf("%q 不是有效的星期", s)
+	}
+	return time.Weekday(i), nil
+}
+
+// parseWeekdayNum 解析 BYDAY 中的一项，例如 MO、+2TU、-1FR
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("%q 不是有效的星期", s)
+	}
+	weekday, err := parseWeekday(s[len(s)-2:])
+	if err != nil {
+		return WeekdayNum{}, err
+	}
+	w := WeekdayNum{Weekday: weekday}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		if w.N, err = parseSignedInt(strings.TrimPrefix(prefix, "+"), 53); err != nil {
+			return WeekdayNum{}, err
+		}
+	}
+	return w, nil
+}
+
+// parseUntil 解析 UNTIL 的三种形式
+func parseUntil(s string) (*Until, error) {
+	layouts := []struct {
+		layout string
+		until  Until
+	}{
+		{"20060102T150405Z", Until{}},
+		{"20060102T150405", Until{Local: true}},
+		{"20060102", Until{Local: true, DateOnly: true}},
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l.layout, s); err == nil {
+			until := l.until
+			until.Time = t
+			return &until, nil
+		}
+	}
+	return nil, fmt.Errorf("UNTIL %q 不是有效的时间", s)
+}
+
+// joinInts 将整数列表拼接为逗号分隔的文本
+func joinInts[T ~int](values []T) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(int(v))
+	}
+	return strings.Join(parts, ",")
+}
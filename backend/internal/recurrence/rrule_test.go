@@ -0,0 +1,291 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// instances 返回系列的前 n 个实例，系列提前结束时返回的实例少于 n 个
+func instances(t *testing.T, rule string, start time.Time, n int) []time.Time {
+	t.Helper()
+
+	r, err := Parse(rule)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", rule, err)
+	}
+	var result []time.Time
+	after := start.Add(-time.Nanosecond)
+	for len(result) < n {
+		next, ok := r.Next(start, after)
+		if !ok {
+			break
+		}
+		result = append(result, next)
+		after = next
+	}
+	return result
+}
+
+// day 返回 UTC 中的日期，时刻为 09:00
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 9, 0, 0, 0, time.UTC)
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		n     int
+		want  []time.Time
+	}{
+		{
+			name:  "last friday of month",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: day(2026, 1, 30),
+			n:     5,
+			want:  []time.Time{day(2026, 1, 30), day(2026, 2, 27), day(2026, 3, 27), day(2026, 4, 24), day(2026, 5, 29)},
+		},
+		{
+			name:  "second tuesday of month",
+			rule:  "FREQ=MONTHLY;BYDAY=2TU",
+			start: day(2026, 1, 13),
+			n:     3,
+			want:  []time.Time{day(2026, 1, 13), day(2026, 2, 10), day(2026, 3, 10)},
+		},
+		{
+			name:  "last weekday of month",
+			rule:  "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			start: day(2026, 1, 30),
+			n:     5,
+			want:  []time.Time{day(2026, 1, 30), day(2026, 2, 27), day(2026, 3, 31), day(2026, 4, 30), day(2026, 5, 29)},
+		},
+		{
+			name:  "first and last day of month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1,-1",
+			start: day(2026, 1, 1),
+			n:     4,
+			want:  []time.Time{day(2026, 1, 1), day(2026, 1, 31), day(2026, 2, 1), day(2026, 2, 28)},
+		},
+		{
+			name:  "31st skips short months",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			start: day(2026, 1, 31),
+			n:     5,
+			want:  []time.Time{day(2026, 1, 31), day(2026, 3, 31), day(2026, 5, 31), day(2026, 7, 31), day(2026, 8, 31)},
+		},
+		{
+			name:  "monthly from the 31st without BYMONTHDAY",
+			rule:  "FREQ=MONTHLY",
+			start: day(2026, 3, 31),
+			n:     3,
+			want:  []time.Time{day(2026, 3, 31), day(2026, 5, 31), day(2026, 7, 31)},
+		},
+		{
+			name:  "yearly on february 29",
+			rule:  "FREQ=YEARLY",
+			start: day(2024, 2, 29),
+			n:     3,
+			want:  []time.Time{day(2024, 2, 29), day(2028, 2, 29), day(2032, 2, 29)},
+		},
+		{
+			name:  "last day of february every year",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1",
+			start: day(2024, 2, 29),
+			n:     3,
+			want:  []time.Time{day(2024, 2, 29), day(2025, 2, 28), day(2026, 2, 28)},
+		},
+		{
+			name:  "count with interval",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			start: day(2026, 1, 5),
+			n:     10,
+			want:  []time.Time{day(2026, 1, 5), day(2026, 1, 19), day(2026, 2, 2)},
+		},
+		{
+			name:  "count with interval and several days per week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=5",
+			start: day(2026, 1, 5),
+			n:     10,
+			want:  []time.Time{day(2026, 1, 5), day(2026, 1, 9), day(2026, 1, 19), day(2026, 1, 23), day(2026, 2, 2)},
+		},
+		{
+			name:  "count includes the first instance",
+			rule:  "FREQ=DAILY;INTERVAL=3;COUNT=4",
+			start: day(2026, 1, 30),
+			n:     10,
+			want:  []time.Time{day(2026, 1, 30), day(2026, 2, 2), day(2026, 2, 5), day(2026, 2, 8)},
+		},
+		{
+			name:  "until date is inclusive",
+			rule:  "FREQ=DAILY;UNTIL=20260103",
+			start: day(2026, 1, 1),
+			n:     10,
+			want:  []time.Time{day(2026, 1, 1), day(2026, 1, 2), day(2026, 1, 3)},
+		},
+		{
+			name:  "until utc time",
+			rule:  "FREQ=DAILY;UNTIL=20260103T085959Z",
+			start: day(2026, 1, 1),
+			n:     10,
+			want:  []time.Time{day(2026, 1, 1), day(2026, 1, 2)},
+		},
+		{
+			name:  "never matching rule ends",
+			rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+			start: day(2026, 1, 1),
+			n:     1,
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := instances(t, tt.rule, tt.start, tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d instances %v, want %v", len(got), got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("instance %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNextAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []time.Time
+	}{
+		{
+			// 2026-03-08 开始夏令时，本地时刻保持 09:00，UTC 时刻提前一小时
+			name:  "spring forward keeps local time",
+			rule:  "FREQ=DAILY",
+			start: time.Date(2026, 3, 7, 9, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 7, 14, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 8, 13, 0, 0, 0, time.UTC),
+				time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// 2026-11-01 结束夏令时
+			name:  "fall back keeps local time",
+			rule:  "FREQ=WEEKLY",
+			start: time.Date(2026, 10, 25, 9, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 10, 25, 13, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 1, 14, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 8, 14, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// 02:30 在切换当天不存在，结果与 time.Date 一致，之后恢复为本地 02:30
+			name:  "nonexistent local time",
+			rule:  "FREQ=DAILY",
+			start: time.Date(2026, 3, 7, 2, 30, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 7, 7, 30, 0, 0, time.UTC),
+				time.Date(2026, 3, 8, 2, 30, 0, 0, ny),
+				time.Date(2026, 3, 9, 6, 30, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := instances(t, tt.rule, tt.start, len(tt.want))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d instances, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("instance %d = %v, want %v", i, got[i].UTC(), tt.want[i])
+				}
+				if got[i].Location() != ny {
+					t.Errorf("instance %d location = %v, want %v", i, got[i].Location(), ny)
+				}
+			}
+		})
+	}
+}
+
+func TestNextAfterSkipsPeriods(t *testing.T) {
+	r, err := Parse("FREQ=DAILY;INTERVAL=7")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	start := day(2000, 1, 1)
+	next, ok := r.Next(start, day(2026, 1, 1))
+	if !ok || !next.Equal(day(2026, 1, 3)) {
+		t.Errorf("Next() = %v, %v, want 2026-01-03", next, ok)
+	}
+}
+
+func TestParseString(t *testing.T) {
+	tests := map[string]string{
+		"FREQ=DAILY":                           "FREQ=DAILY",
+		"rrule:freq=weekly;byday=mo,we":        "FREQ=WEEKLY;BYDAY=MO,WE",
+		" FREQ=MONTHLY;INTERVAL=1;BYDAY=+2TU":  "FREQ=MONTHLY;BYDAY=2TU",
+		"BYSETPOS=-1;BYDAY=MO,FR;FREQ=MONTHLY": "FREQ=MONTHLY;BYDAY=MO,FR;BYSETPOS=-1",
+		"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1;": "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1",
+		"FREQ=WEEKLY;WKST=SU;COUNT=3":          "FREQ=WEEKLY;COUNT=3;WKST=SU",
+		"FREQ=DAILY;UNTIL=20260103T085959Z":    "FREQ=DAILY;UNTIL=20260103T085959Z",
+		"FREQ=DAILY;UNTIL=20260103T085959":     "FREQ=DAILY;UNTIL=20260103T085959",
+		"FREQ=DAILY;UNTIL=20260103":            "FREQ=DAILY;UNTIL=20260103",
+	}
+	for input, want := range tests {
+		r, err := Parse(input)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", input, err)
+			continue
+		}
+		if got := r.String(); got != want {
+			t.Errorf("Parse(%q).String() = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":                    "",
+		"missing freq":             "INTERVAL=2",
+		"unsupported freq":         "FREQ=HOURLY",
+		"missing value":            "FREQ=",
+		"missing equals":           "FREQ",
+		"duplicate part":           "FREQ=DAILY;FREQ=WEEKLY",
+		"unsupported part":         "FREQ=DAILY;BYHOUR=9",
+		"zero interval":            "FREQ=DAILY;INTERVAL=0",
+		"interval too large":       "FREQ=DAILY;INTERVAL=1001",
+		"non numeric count":        "FREQ=DAILY;COUNT=x",
+		"count and until":          "FREQ=DAILY;COUNT=2;UNTIL=20260101",
+		"invalid until":            "FREQ=DAILY;UNTIL=2026-01-01",
+		"month out of range":       "FREQ=YEARLY;BYMONTH=13",
+		"zero month day":           "FREQ=MONTHLY;BYMONTHDAY=0",
+		"month day out of range":   "FREQ=MONTHLY;BYMONTHDAY=32",
+		"empty list item":          "FREQ=MONTHLY;BYMONTHDAY=1,,2",
+		"invalid weekday":          "FREQ=WEEKLY;BYDAY=XX",
+		"weekday number too large": "FREQ=MONTHLY;BYDAY=54MO",
+		"zero weekday number":      "FREQ=MONTHLY;BYDAY=0MO",
+		"numbered weekday weekly":  "FREQ=WEEKLY;BYDAY=1MO",
+		"month day with weekly":    "FREQ=WEEKLY;BYMONTHDAY=1",
+		"setpos alone":             "FREQ=MONTHLY;BYSETPOS=-1",
+		"setpos out of range":      "FREQ=MONTHLY;BYDAY=MO;BYSETPOS=367",
+		"invalid wkst":             "FREQ=WEEKLY;WKST=XY",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if r, err := Parse(input); err == nil {
+				t.Errorf("Parse(%q) = %v, want error", input, r)
+			}
+		})
+	}
+}
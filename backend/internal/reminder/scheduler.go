@@ -0,0 +1,259 @@
+// Package reminder 实现后台的提醒调度器。
+//
+// 调度器按间隔从存储后端领取到期的提醒，依次交给各通知渠道发送。领取时记录实例标识和租约，
+// 多个实例共用一个数据库时同一个提醒只会被一个实例领取；服务重启后未确认的提醒在租约到期后重新领取，
+// 已确认的提醒不会再次发送。实例在发送后、确认前退出的情况下提醒会再发送一次，
+// 各通知渠道依靠提醒的幂等键去重，详见 notify 包。
+package reminder
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/notify"
+	"github.com/Brower/backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// 未配置时使用的默认值
+const (
+	defaultInterval     = 15 * time.Second
+	defaultLease        = time.Minute
+	defaultBatchSize    = 50
+	defaultMaxAttempts  = 5
+	defaultRetryBackoff = 30 * time.Second
+
+	// maxRetryBackoff 重试等待时间的上限
+	maxRetryBackoff = time.Hour
+)
+
+// Scheduler 提醒调度器
+type Scheduler struct {
+	repo      repository.ReminderRepository
+	notifiers []notify.Notifier
+
+	interval     time.Duration
+	lease        time.Duration
+	batchSize    int
+	maxAttempts  int
+	retryBackoff time.Duration
+
+	// owner 本实例的标识，记录在领取的提醒上
+	owner  string
+	now    func() time.Time
+	logger *zap.Logger
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewScheduler 创建提醒调度器，未配置的参数使用默认值。
+// 工作区的待办事项的提醒发给工作区的每个成员
+func NewScheduler(repo repository.ReminderRepository, notifiers []notify.Notifier, cfg config.ReminderConfig) *Scheduler {
+	s := &Scheduler{
+		repo:         repo,
+		notifiers:    notifiers,
+		interval:     cfg.Interval,
+		lease:        cfg.Lease,
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+		retryBackoff: cfg.RetryBackoff,
+		owner:        newOwnerID(),
+		now:          time.Now,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	if s.interval <= 0 {
+		s.interval = defaultInterval
+	}
+	if s.lease <= 0 {
+		s.lease = defaultLease
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = defaultBatchSize
+	}
+	if s.maxAttempts <= 0 {
+		s.maxAttempts = defaultMaxAttempts
+	}
+	if s.retryBackoff <= 0 {
+		s.retryBackoff = defaultRetryBackoff
+	}
+	s.logger = logger.Log.With(zap.String("component", "ReminderScheduler"), zap.String("owner", s.owner))
+	return s
+}
+
+// Start 在后台启动调度循环，Stop 后结束
+func (s *Scheduler) Start() {
+	names := make([]string, len(s.notifiers))
+	for i, n := range s.notifiers {
+		names[i] = n.Name()
+	}
+	s.logger.Info("启动提醒调度器",
+		zap.Strings("notifiers", names),
+		zap.Duration("interval", s.interval),
+		zap.Duration("lease", s.lease))
+
+	go s.run()
+}
+
+// Stop 停止调度循环，并等待正在发送的提醒处理完
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		s.logger.Info("提醒调度器已停止")
+	})
+}
+
+// run 调度循环。一批提醒领满时说明还有积压，不等待下一个间隔直接继续领取
+func (s *Scheduler) run() {
+	defer close(s.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.stop
+		cancel()
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-timer.C:
+		}
+
+		claimed, err := s.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("处理提醒失败", zap.Error(err))
+		}
+
+		wait := s.interval
+		if err == nil && claimed >= s.batchSize {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// RunOnce 领取一批到期的提醒并发送，返回领取的数量
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	claimedAt := s.now()
+	reminders, err := s.repo.ClaimDueReminders(ctx, s.owner, claimedAt, s.lease, s.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("领取提醒失败: %w", err)
+	}
+	if len(reminders) == 0 {
+		return 0, nil
+	}
+	s.logger.Debug("领取到期提醒", zap.Int("count", len(reminders)))
+
+	// 租约到期后提醒可能已被其他实例领取，只在租约期内发送
+	leaseCtx, cancel := context.WithDeadline(ctx, claimedAt.Add(s.lease))
+	defer cancel()
+
+	for _, reminder := range reminders {
+		if leaseCtx.Err() != nil {
+			s.logger.Warn("租约已到期，剩余的提醒留给下次领取",
+				zap.String("todoID", reminder.TodoID))
+			break
+		}
+		s.deliver(leaseCtx, ctx, reminder)
+	}
+	return len(reminders), nil
+}
+
+// recipients 返回提醒的每个接收者各自的一份提醒。个人的待办事项的提醒保持不变；
+// 工作区的待办事项以工作区 ID 作为所有者，仓库领取时已经查出了工作区的成员，提醒发给每个成员
+func recipients(reminder models.Reminder) []models.Reminder {
+	if reminder.WorkspaceID == "" {
+		return []models.Reminder{reminder}
+	}
+
+	result := make([]models.Reminder, len(reminder.Recipients))
+	for i, userID := range reminder.Recipients {
+		result[i] = reminder
+		result[i].UserID = userID
+		result[i].Recipients = nil
+	}
+	return result
+}
+
+// deliver 把一个提醒发送到所有通知渠道，然后确认或释放。
+// 任一渠道失败时整个提醒稍后重试，已成功的渠道会再收到一次相同幂等键的提醒。
+// 确认和释放使用 ctx 而不是租约的上下文，发送耗尽租约时也能记录结果。
+func (s *Scheduler) deliver(leaseCtx, ctx context.Context, reminder models.Reminder) {
+	log := s.logger.With(
+		zap.String("todoID", reminder.TodoID),
+		zap.String("userID", reminder.UserID),
+		zap.Time("remindAt", reminder.RemindAt),
+		zap.Int("attempt", reminder.Attempts+1))
+
+	var failed error
+	for _, recipient := range recipients(reminder) {
+		for _, n := range s.notifiers {
+			if err := n.Notify(leaseCtx, recipient); err != nil {
+				log.Warn("发送提醒失败", zap.String("notifier", n.Name()),
+					zap.String("recipient", recipient.UserID), zap.Error(err))
+				failed = err
+			}
+		}
+	}
+
+	switch {
+	case failed == nil:
+		if err := s.repo.CompleteReminder(ctx, s.owner, reminder); err != nil {
+			log.Error("确认提醒失败，租约到期后会再次发送", zap.Error(err))
+			return
+		}
+		log.Info("提醒已发送")
+
+	case reminder.Attempts+1 >= s.maxAttempts:
+		// 放弃后标记为已提醒，避免一直重试
+		if err := s.repo.CompleteReminder(ctx, s.owner, reminder); err != nil {
+			log.Error("确认提醒失败，租约到期后会再次发送", zap.Error(err))
+			return
+		}
+		log.Error("提醒多次发送失败，已放弃", zap.Int("maxAttempts", s.maxAttempts), zap.Error(failed))
+
+	default:
+		retryAt := s.now().Add(s.backoff(reminder.Attempts))
+		if err := s.repo.ReleaseReminder(ctx, s.owner, reminder, retryAt); err != nil {
+			log.Error("释放提醒失败，租约到期后会再次发送", zap.Error(err))
+			return
+		}
+		log.Info("提醒将稍后重试", zap.Time("retryAt", retryAt))
+	}
+}
+
+// backoff 第 attempts+1 次失败后的等待时间，每次翻倍，不超过 maxRetryBackoff
+func (s *Scheduler) backoff(attempts int) time.Duration {
+	wait := s.retryBackoff
+	for i := 0; i < attempts && wait < maxRetryBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxRetryBackoff)
+}
+
+// newOwnerID 生成本实例的标识：主机名、进程号和随机后缀，重启后不会与之前的租约混淆
+func newOwnerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,322 @@
+package reminder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/notify"
+	"github.com/Brower/backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// webhookRecorder 记录收到的 Webhook 请求的幂等键，依次返回 statuses 中的状态码，用完后返回 200
+type webhookRecorder struct {
+	mu       sync.Mutex
+	statuses []int
+	keys     []string
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = append(r.keys, req.Header.Get("Idempotency-Key"))
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *webhookRecorder) received() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.keys...)
+}
+
+// newTestScheduler 创建使用 Webhook 渠道的调度器，时间固定为 *now
+func newTestScheduler(t *testing.T, repo repository.ReminderRepository, recorder *webhookRecorder, cfg config.ReminderConfig, now *time.Time) *Scheduler {
+	t.Helper()
+
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+
+	webhook := notify.NewWebhookNotifier(config.WebhookNotifierConfig{URL: server.URL})
+	s := NewScheduler(repo, []notify.Notifier{webhook}, cfg)
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+// createTodos 创建 n 个提醒时间为 remindAt 的待办事项
+func createTodos(t *testing.T, repo repository.TodoRepository, n int, remindAt time.Time) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		todo := &models.Todo{Title: fmt.Sprintf("提醒 %d", i), RemindAt: &remindAt}
+		if err := repo.Create(context.Background(), "user-1", todo); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+}
+
+func TestSchedulerRetriesFailedWebhook(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	now := time.Now().UTC()
+	createTodos(t, repo, 1, now.Add(-time.Minute))
+
+	recorder := &webhookRecorder{statuses: []int{http.StatusServiceUnavailable, http.StatusInternalServerError}}
+	cfg := config.ReminderConfig{Lease: time.Minute, RetryBackoff: 10 * time.Second, MaxAttempts: 5}
+	s := newTestScheduler(t, repo, recorder, cfg, &now)
+	ctx := context.Background()
+
+	runAt := func(at time.Time, wantClaimed int) {
+		t.Helper()
+		now = at
+		claimed, err := s.RunOnce(ctx)
+		if err != nil || claimed != wantClaimed {
+			t.Fatalf("RunOnce() at %v = %d, %v, want %d", at, claimed, err, wantClaimed)
+		}
+	}
+
+	start := now
+	runAt(start, 1) // 第一次失败，10 秒后重试
+	runAt(start.Add(9*time.Second), 0)
+	runAt(start.Add(10*time.Second), 1) // 第二次失败，等待时间翻倍为 20 秒
+	runAt(start.Add(29*time.Second), 0)
+	runAt(start.Add(30*time.Second), 1) // 第三次成功
+	runAt(start.Add(time.Hour), 0)
+
+	keys := recorder.received()
+	if len(keys) != 3 {
+		t.Fatalf("webhook received %d requests, want 3", len(keys))
+	}
+	// 重试使用相同的幂等键，接收方可以据此去重
+	if keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Errorf("Idempotency-Key = %v, want the same key for every attempt", keys)
+	}
+}
+
+func TestSchedulerGivesUpAfterMaxAttempts(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	now := time.Now().UTC()
+	createTodos(t, repo, 1, now.Add(-time.Minute))
+
+	recorder := &webhookRecorder{statuses: []int{500, 500, 500, 500}}
+	cfg := config.ReminderConfig{Lease: time.Minute, RetryBackoff: time.Second, MaxAttempts: 2}
+	s := newTestScheduler(t, repo, recorder, cfg, &now)
+
+	for i := 0; i < 5; i++ {
+		if _, err := s.RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce() error = %v", err)
+		}
+		now = now.Add(time.Minute)
+	}
+	if got := len(recorder.received()); got != 2 {
+		t.Errorf("webhook received %d requests, want 2", got)
+	}
+}
+
+func TestSchedulerConcurrentInstancesDeliverOnce(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	now := time.Now().UTC()
+	createTodos(t, repo, 30, now.Add(-time.Minute))
+
+	recorder := &webhookRecorder{}
+	cfg := config.ReminderConfig{Lease: time.Minute, BatchSize: 4}
+	schedulers := make([]*Scheduler, 4)
+	for i := range schedulers {
+		schedulers[i] = newTestScheduler(t, repo, recorder, cfg, &now)
+	}
+
+	// 多个实例同时领取并发送，直到没有到期的提醒
+	var wg sync.WaitGroup
+	for _, s := range schedulers {
+		wg.Add(1)
+		go func(s *Scheduler) {
+			defer wg.Done()
+			for {
+				claimed, err := s.RunOnce(context.Background())
+				if err != nil {
+					t.Errorf("RunOnce() error = %v", err)
+					return
+				}
+				if claimed == 0 {
+					return
+				}
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	keys := recorder.received()
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if seen[key] {
+			t.Errorf("提醒 %s 发送了多次", key)
+		}
+		seen[key] = true
+	}
+	if len(seen) != 30 {
+		t.Errorf("delivered %d reminders, want 30", len(seen))
+	}
+}
+
+func TestSchedulerExpiredLeaseIsReclaimed(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	now := time.Now().UTC()
+	createTodos(t, repo, 1, now.Add(-time.Minute))
+	ctx := context.Background()
+
+	// 另一个实例领取后没有确认就退出
+	if claimed, err := repo.ClaimDueReminders(ctx, "crashed", now, time.Minute, 10); err != nil || len(claimed) != 1 {
+		t.Fatalf("ClaimDueReminders() = %d, %v, want 1", len(claimed), err)
+	}
+
+	recorder := &webhookRecorder{}
+	s := newTestScheduler(t, repo, recorder, config.ReminderConfig{Lease: time.Minute}, &now)
+
+	if claimed, err := s.RunOnce(ctx); err != nil || claimed != 0 {
+		t.Fatalf("RunOnce() during lease = %d, %v, want 0", claimed, err)
+	}
+	now = now.Add(time.Minute)
+	if claimed, err := s.RunOnce(ctx); err != nil || claimed != 1 {
+		t.Fatalf("RunOnce() after lease = %d, %v, want 1", claimed, err)
+	}
+	now = now.Add(time.Hour)
+	if claimed, err := s.RunOnce(ctx); err != nil || claimed != 0 {
+		t.Fatalf("RunOnce() after delivery = %d, %v, want 0", claimed, err)
+	}
+	if got := len(recorder.received()); got != 1 {
+		t.Errorf("webhook received %d requests, want 1", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	s := &Scheduler{retryBackoff: 30 * time.Second}
+	tests := map[int]time.Duration{
+		0:  30 * time.Second,
+		1:  time.Minute,
+		2:  2 * time.Minute,
+		6:  32 * time.Minute,
+		7:  maxRetryBackoff,
+		50: maxRetryBackoff,
+	}
+	for attempts, want := range tests {
+		if got := s.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestSchedulerWorkspaceReminderGoesToMembers(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	workspace := &models.Workspace{Name: "团队", CreatedBy: "alice"}
+	if err := repo.CreateWorkspace(ctx, workspace); err != nil {
+		t.Fatalf("CreateWorkspace() error = %v", err)
+	}
+	if err := repo.AddMember(ctx, &models.Member{WorkspaceID: workspace.ID, UserID: "bob", Role: models.WorkspaceMember}); err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	remindAt := now.Add(-time.Minute)
+	// 工作区的待办事项以工作区 ID 作为所有者
+	if err := repo.Create(ctx, workspace.ID, &models.Todo{Title: "团队周会", RemindAt: &remindAt}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	// 个人的待办事项仍然只发给所有者
+	if err := repo.Create(ctx, "carol", &models.Todo{Title: "个人", RemindAt: &remindAt}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+	notifiers := []notify.Notifier{
+		notify.NewInAppNotifier(repo),
+		notify.NewWebhookNotifier(config.WebhookNotifierConfig{URL: server.URL}),
+	}
+	s := NewScheduler(repo, notifiers, config.ReminderConfig{})
+	s.now = func() time.Time { return now }
+
+	if claimed, err := s.RunOnce(ctx); err != nil || claimed != 2 {
+		t.Fatalf("RunOnce() = %d, %v, want 2", claimed, err)
+	}
+
+	for user, want := range map[string]string{"alice": "团队周会", "bob": "团队周会", "carol": "个人", workspace.ID: ""} {
+		page, err := repo.ListNotifications(ctx, user, models.NotificationListOptions{Limit: 10})
+		if err != nil {
+			t.Fatalf("ListNotifications(%s) error = %v", user, err)
+		}
+		if want == "" {
+			if len(page.Items) != 0 {
+				t.Errorf("notifications for %s = %+v, want none", user, page.Items)
+			}
+			continue
+		}
+		if len(page.Items) != 1 || page.Items[0].Title != want {
+			t.Errorf("notifications for %s = %+v, want one %q", user, page.Items, want)
+		}
+	}
+
+	// 每个接收者的幂等键不同，接收方不会把不同成员的提醒当作重复
+	keys := recorder.received()
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		seen[key] = true
+	}
+	if len(keys) != 3 || len(seen) != 3 {
+		t.Errorf("Idempotency-Key = %v, want 3 distinct keys", keys)
+	}
+}
+
+func TestRecipients(t *testing.T) {
+	remindAt := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		reminder models.Reminder
+		want     []string
+	}{
+		{
+			name:     "personal",
+			reminder: models.Reminder{TodoID: "todo-1", UserID: "alice", RemindAt: remindAt},
+			want:     []string{"alice"},
+		},
+		{
+			name:     "workspace",
+			reminder: models.Reminder{TodoID: "todo-1", UserID: "ws-1", WorkspaceID: "ws-1", Recipients: []string{"alice", "bob"}, RemindAt: remindAt},
+			want:     []string{"alice", "bob"},
+		},
+		{
+			name:     "workspace without members",
+			reminder: models.Reminder{TodoID: "todo-1", UserID: "ws-1", WorkspaceID: "ws-1", RemindAt: remindAt},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := recipients(tt.reminder)
+			if len(got) != len(tt.want) {
+				t.Fatalf("recipients() = %+v, want users %v", got, tt.want)
+			}
+			for i, r := range got {
+				if r.UserID != tt.want[i] || r.WorkspaceID != tt.reminder.WorkspaceID || r.TodoID != tt.reminder.TodoID {
+					t.Errorf("recipients()[%d] = %+v, want user %s in workspace %q", i, r, tt.want[i], tt.reminder.WorkspaceID)
+				}
+			}
+		})
+	}
+}
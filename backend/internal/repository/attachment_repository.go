@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// AttachmentRepository 附件元数据的仓库接口，存储后端通过类型断言获取。
+//
+// 删除附件或其所属的待办事项时，元数据不会立即删除，而是与待办事项脱离（TodoID 变为空），
+// 由清理任务删除文件后再通过 PurgeAttachment 删除元数据。这样无论待办事项以何种方式被删除
+// （包括级联删除项目），文件都会被清理，删除文件失败时也可以稍后重试。
+type AttachmentRepository interface {
+	// ListAttachments 获取待办事项的所有附件，按上传时间排序
+	ListAttachments(ctx context.Context, userID, todoID string) ([]models.Attachment, error)
+
+	// GetAttachment 获取待办事项的一个附件，不存在时返回 ErrAttachmentNotFound
+	GetAttachment(ctx context.Context, userID, todoID, id string) (*models.Attachment, error)
+
+	// CreateAttachment 保存附件的元数据，未设置的 ID 和时间戳会自动填充；
+	// 待办事项不存在时返回 ErrTodoNotFound
+	CreateAttachment(ctx context.Context, userID string, attachment *models.Attachment) error
+
+	// DeleteAttachment 把附件与待办事项脱离，之后不再出现在列表中，
+	// 不存在时返回 ErrAttachmentNotFound
+	DeleteAttachment(ctx context.Context, userID, todoID, id string) error
+
+	// ListDetachedAttachments 获取所有用户已经脱离待办事项的附件，最多 limit 个，供清理任务使用
+	ListDetachedAttachments(ctx context.Context, limit int) ([]models.Attachment, error)
+
+	// PurgeAttachment 删除已经脱离待办事项的附件的元数据，不存在时不返回错误
+	PurgeAttachment(ctx context.Context, id string) error
+}
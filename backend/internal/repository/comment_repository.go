@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// CommentRepository 评论的仓库接口，存储后端通过类型断言获取。
+//
+// 评论属于一个待办事项，删除待办事项时一并删除。方法不检查调用方能否查看待办事项，
+// 由服务层在调用之前确认；修改和删除只作用于 userID 作为作者的评论。
+type CommentRepository interface {
+	// ListComments 按创建时间正序分页获取待办事项的评论，时间相同时按 ID 排序
+	ListComments(ctx context.Context, todoID string, opts models.CommentListOptions) (*models.CommentPage, error)
+
+	// CommentCounts 批量统计待办事项的评论数，按待办事项 ID 索引，
+	// 没有评论的待办事项不出现在结果中
+	CommentCounts(ctx context.Context, todoIDs []string) (map[string]int, error)
+
+	// GetComment 获取待办事项的一条评论，不存在时返回 ErrCommentNotFound
+	GetComment(ctx context.Context, todoID, id string) (*models.Comment, error)
+
+	// CreateComment 保存评论，作者为 comment.UserID，未设置的 ID 和时间戳会自动填充；
+	// 待办事项不存在时返回 ErrTodoNotFound
+	CreateComment(ctx context.Context, comment *models.Comment) error
+
+	// UpdateComment 修改评论的内容，并把保存后的评论写回 comment。
+	// 评论不存在、不属于 comment.TodoID 或作者不是 comment.UserID 时返回 ErrCommentNotFound
+	UpdateComment(ctx context.Context, comment *models.Comment) error
+
+	// DeleteComment 删除 userID 作为作者的一条评论，不存在时返回 ErrCommentNotFound
+	DeleteComment(ctx context.Context, userID, todoID, id string) error
+}
+
+// newCommentPage 根据多查询一条的结果生成分页
+func newCommentPage(items []models.Comment, opts models.CommentListOptions) *models.CommentPage {
+	page := &models.CommentPage{Items: items}
+	if len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		page.Next = models.NewCommentCursor(page.Items[len(page.Items)-1])
+	}
+	if page.Items == nil {
+		page.Items = []models.Comment{}
+	}
+	return page
+}
@@ -4,5 +4,18 @@ import "errors"
 
 // 仓库层错误定义
 var (
-	ErrTodoNotFound = errors.New("todo not found")
+	ErrTodoNotFound         = errors.New("todo not found")
+	ErrTodoAlreadyExists    = errors.New("todo already exists")
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrTagNotFound          = errors.New("tag not found")
+	ErrTagAlreadyExists     = errors.New("tag already exists")
+	ErrProjectNotFound      = errors.New("project not found")
+	ErrSubtaskNotFound      = errors.New("subtask not found")
+	ErrAttachmentNotFound   = errors.New("attachment not found")
+	ErrCommentNotFound      = errors.New("comment not found")
+	ErrShareNotFound        = errors.New("share not found")
+	ErrInvitationNotFound   = errors.New("invitation not found")
+	ErrWorkspaceNotFound    = errors.New("workspace not found")
+	ErrMemberNotFound       = errors.New("workspace member not found")
+	ErrMemberAlreadyExists  = errors.New("workspace member already exists")
 )
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/Brower/backend/internal/config"
+)
+
+// 支持的存储后端
+const (
+	BackendMemory   = "memory"
+	BackendSupabase = "supabase"
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+)
+
+// TodoRepositoryFactory 根据配置创建 TodoRepository
+type TodoRepositoryFactory func(cfg *config.Config) (TodoRepository, error)
+
+// ConfigValidator 校验某个存储后端所需的配置
+type ConfigValidator func(cfg *config.Config) error
+
+// backend 描述一个已注册的存储后端
+type backend struct {
+	validate ConfigValidator
+	create   TodoRepositoryFactory
+}
+
+// backends 已注册的存储后端，键为 database.type 的取值
+var backends = map[string]backend{}
+
+func init() {
+	RegisterBackend(BackendMemory, validateMemoryConfig, func(cfg *config.Config) (TodoRepository, error) {
+		memory := cfg.Database.Memory
+		if memory.SnapshotPath == "" {
+			return NewInMemoryTodoRepository(), nil
+		}
+		return NewInMemoryTodoRepositoryWithSnapshot(memory.SnapshotPath, memory.SnapshotInterval)
+	})
+	RegisterBackend(BackendSupabase, validateSupabaseConfig, func(cfg *config.Config) (TodoRepository, error) {
+		return NewSupabaseTodoRepository(cfg)
+	})
+	RegisterBackend(BackendPostgres, validatePostgresConfig, func(cfg *config.Config) (TodoRepository, error) {
+		return NewPostgresTodoRepository(cfg)
+	})
+	RegisterBackend(BackendSQLite, validateSQLiteConfig, func(cfg *config.Config) (TodoRepository, error) {
+		return NewSQLiteTodoRepository(cfg)
+	})
+}
+
+// RegisterBackend 注册一个存储后端，validate 可以为 nil。重复注册会覆盖之前的实现。
+func RegisterBackend(name string, validate ConfigValidator, create TodoRepositoryFactory) {
+	backends[normalizeBackendName(name)] = backend{
+		validate: validate,
+		create:   create,
+	}
+}
+
+// NewTodoRepository 根据 database.type 创建对应的 TodoRepository。
+// 创建前会校验该后端所需的配置，任何缺失都会以明确的错误返回。
+func NewTodoRepository(cfg *config.Config) (TodoRepository, error) {
+	name := normalizeBackendName(cfg.Database.Type)
+	if name == "" {
+		name = BackendSupabase
+	}
+
+	b, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("不支持的存储后端 %q，可选值: %s", name, strings.Join(RegisteredBackends(), ", "))
+	}
+
+	if b.validate != nil {
+		if err := b.validate(cfg); err != nil {
+			return nil, fmt.Errorf("存储后端 %s 配置无效: %w", name, err)
+		}
+	}
+
+	repo, err := b.create(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("初始化存储后端 %s 失败: %w", name, err)
+	}
+
+	return repo, nil
+}
+
+// RegisteredBackends 返回所有已注册的存储后端名称
+func RegisteredBackends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// normalizeBackendName 统一存储后端名称的格式
+func normalizeBackendName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// validateMemoryConfig 校验内存存储后端的配置
+func validateMemoryConfig(cfg *config.Config) error {
+	memory := cfg.Database.Memory
+	if memory.SnapshotInterval < 0 {
+		return fmt.Errorf("database.memory.snapshot_interval 不能为负数: %s", memory.SnapshotInterval)
+	}
+	if memory.SnapshotInterval > 0 && memory.SnapshotPath == "" {
+		return fmt.Errorf("设置了 database.memory.snapshot_interval 但缺少 database.memory.snapshot_path")
+	}
+	return nil
+}
+
+// validateSupabaseConfig 校验 Supabase 存储后端的配置
+func validateSupabaseConfig(cfg *config.Config) error {
+	var missing []string
+	if cfg.Supabase.BaseURL == "" {
+		missing = append(missing, "supabase.base_url")
+	}
+	if cfg.Supabase.ServiceRoleKey == "" {
+		missing = append(missing, "supabase.service_role_key")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("缺少配置项: %s", strings.Join(missing, ", "))
+	}
+	if strings.Contains(cfg.Supabase.BaseURL, "://") {
+		return fmt.Errorf("supabase.base_url 不应包含协议: %s", cfg.Supabase.BaseURL)
+	}
+	return nil
+}
+
+// validatePostgresConfig 校验 PostgreSQL 存储后端的配置
+func validatePostgresConfig(cfg *config.Config) error {
+	db := cfg.Database
+
+	var missing []string
+	if db.User == "" {
+		missing = append(missing, "database.user")
+	}
+	if db.DBName == "" {
+		missing = append(missing, "database.dbname")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("缺少配置项: %s", strings.Join(missing, ", "))
+	}
+
+	if db.Port < 0 || db.Port > 65535 {
+		return fmt.Errorf("database.port 超出范围: %d", db.Port)
+	}
+	if db.MaxConns < 0 || db.MinConns < 0 {
+		return fmt.Errorf("database.max_conns 和 database.min_conns 不能为负数")
+	}
+	if db.MaxConns > 0 && db.MinConns > db.MaxConns {
+		return fmt.Errorf("database.min_conns (%d) 不能大于 database.max_conns (%d)", db.MinConns, db.MaxConns)
+	}
+
+	switch db.SSLMode {
+	case "", "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		return fmt.Errorf("database.sslmode 取值无效: %s", db.SSLMode)
+	}
+
+	return nil
+}
+
+// validateSQLiteConfig 校验 SQLite 存储后端的配置：database.path 不能为空，
+// 数据库文件已经存在时必须可写，否则它所在的目录（或最近一级已经存在的上级目录）必须可写
+func validateSQLiteConfig(cfg *config.Config) error {
+	path := strings.TrimSpace(cfg.Database.Path)
+	if path == "" {
+		return fmt.Errorf("缺少配置项: database.path")
+	}
+	if path == ":memory:" {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if info.IsDir() {
+			return fmt.Errorf("database.path 是一个目录: %s", path)
+		}
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return fmt.Errorf("database.path 不可写: %w", err)
+		}
+		return f.Close()
+	case !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, syscall.ENOTDIR):
+		// 上级路径中有普通文件时 stat 返回 ENOTDIR，交给下面逐级检查上级目录时给出明确的错误
+		return fmt.Errorf("无法访问 database.path: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("database.path 的上级路径不是目录: %s", dir)
+			}
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("无法访问 database.path 的目录: %w", err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	// 在目录中创建并删除一个临时文件，确认之后可以创建数据库文件
+	f, err := os.CreateTemp(dir, ".sqlite-check-*")
+	if err != nil {
+		return fmt.Errorf("database.path 所在的目录 %s 不可写: %w", dir, err)
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
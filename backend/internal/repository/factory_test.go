@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+)
+
+func TestNewTodoRepository(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(cfg *config.Config)
+		wantErr string
+		want    string
+	}{
+		{
+			name:    "unknown type",
+			setup:   func(cfg *config.Config) { cfg.Database.Type = "mongo" },
+			wantErr: `不支持的存储后端 "mongo"，可选值: memory, postgres, sqlite, supabase`,
+		},
+		{
+			name:    "empty type defaults to supabase",
+			setup:   func(cfg *config.Config) {},
+			wantErr: "存储后端 supabase 配置无效: 缺少配置项: supabase.base_url, supabase.service_role_key",
+		},
+		{
+			name:  "type is case insensitive",
+			setup: func(cfg *config.Config) { cfg.Database.Type = " Memory " },
+			want:  "*repository.InMemoryTodoRepository",
+		},
+		{
+			name: "memory with snapshot path",
+			setup: func(cfg *config.Config) {
+				cfg.Database.Type = BackendMemory
+				cfg.Database.Memory.SnapshotPath = filepath.Join(dir, "snapshot.json")
+				cfg.Database.Memory.SnapshotInterval = time.Minute
+			},
+			want: "*repository.InMemoryTodoRepository",
+		},
+		{
+			name: "memory with interval but no snapshot path",
+			setup: func(cfg *config.Config) {
+				cfg.Database.Type = BackendMemory
+				cfg.Database.Memory.SnapshotInterval = time.Minute
+			},
+			wantErr: "存储后端 memory 配置无效: 设置了 database.memory.snapshot_interval 但缺少 database.memory.snapshot_path",
+		},
+		{
+			name: "sqlite",
+			setup: func(cfg *config.Config) {
+				cfg.Database.Type = BackendSQLite
+				cfg.Database.Path = filepath.Join(dir, "data", "todos.db")
+			},
+			want: "*repository.SQLiteTodoRepository",
+		},
+		{
+			name: "sqlite without path",
+			setup: func(cfg *config.Config) {
+				cfg.Database.Type = BackendSQLite
+			},
+			wantErr: "存储后端 sqlite 配置无效: 缺少配置项: database.path",
+		},
+		{
+			name: "sqlite path under a file",
+			setup: func(cfg *config.Config) {
+				cfg.Database.Type = BackendSQLite
+				cfg.Database.Path = filepath.Join(file, "todos.db")
+			},
+			wantErr: "存储后端 sqlite 配置无效: database.path 的上级路径不是目录: " + file,
+		},
+		{
+			name: "sqlite path is a directory",
+			setup: func(cfg *config.Config) {
+				cfg.Database.Type = BackendSQLite
+				cfg.Database.Path = dir
+			},
+			wantErr: "存储后端 sqlite 配置无效: database.path 是一个目录: " + dir,
+		},
+		{
+			name: "postgres without credentials",
+			setup: func(cfg *config.Config) {
+				cfg.Database.Type = BackendPostgres
+			},
+			wantErr: "存储后端 postgres 配置无效: 缺少配置项: database.user, database.dbname",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			tt.setup(cfg)
+
+			repo, err := NewTodoRepository(cfg)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("NewTodoRepository() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTodoRepository() error = %v", err)
+			}
+			if closer, ok := repo.(io.Closer); ok {
+				defer closer.Close()
+			}
+			if got := fmt.Sprintf("%T", repo); got != tt.want {
+				t.Errorf("NewTodoRepository() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSQLiteConfigUnwritable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root 用户不受文件权限限制")
+	}
+
+	dir := t.TempDir()
+	readOnlyDir := filepath.Join(dir, "readonly")
+	if err := os.Mkdir(readOnlyDir, 0o555); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	readOnlyFile := filepath.Join(dir, "readonly.db")
+	if err := os.WriteFile(readOnlyFile, nil, 0o444); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"existing file", readOnlyFile, "database.path 不可写"},
+		{"missing file in read-only directory", filepath.Join(readOnlyDir, "todos.db"), "database.path 所在的目录 " + readOnlyDir + " 不可写"},
+		{"missing parents under read-only directory", filepath.Join(readOnlyDir, "a", "b", "todos.db"), "database.path 所在的目录 " + readOnlyDir + " 不可写"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Database.Path = tt.path
+			err := validateSQLiteConfig(cfg)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("validateSQLiteConfig() error = %v, want prefix %q", err, tt.wantErr)
+			}
+		})
+	}
+}
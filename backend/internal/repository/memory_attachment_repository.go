@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+var _ AttachmentRepository = (*InMemoryTodoRepository)(nil)
+
+// ListAttachments 获取待办事项的所有附件，按上传时间排序
+func (r *InMemoryTodoRepository) ListAttachments(ctx context.Context, userID, todoID string) ([]models.Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	attachments := make([]models.Attachment, 0)
+	for _, attachment := range r.attachments {
+		if attachment.TodoID == todoID && attachment.UserID == userID {
+			attachments = append(attachments, *attachment)
+		}
+	}
+	r.mu.RUnlock()
+
+	sortAttachments(attachments)
+	return attachments, nil
+}
+
+// GetAttachment 获取待办事项的一个附件
+func (r *InMemoryTodoRepository) GetAttachment(ctx context.Context, userID, todoID, id string) (*models.Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attachment, ok := r.attachments[id]
+	if !ok || attachment.TodoID == "" || attachment.TodoID != todoID || attachment.UserID != userID {
+		return nil, ErrAttachmentNotFound
+	}
+	result := *attachment
+	return &result, nil
+}
+
+// CreateAttachment 保存附件的元数据
+func (r *InMemoryTodoRepository) CreateAttachment(ctx context.Context, userID string, attachment *models.Attachment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUser[userID][attachment.TodoID]; !ok {
+		return ErrTodoNotFound
+	}
+
+	if attachment.ID == "" {
+		attachment.ID = uuid.New().String()
+	}
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = time.Now()
+	}
+	attachment.UserID = userID
+
+	stored := *attachment
+	r.attachments[attachment.ID] = &stored
+	r.version++
+	return nil
+}
+
+// DeleteAttachment 把附件与待办事项脱离
+func (r *InMemoryTodoRepository) DeleteAttachment(ctx context.Context, userID, todoID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attachment, ok := r.attachments[id]
+	if !ok || attachment.TodoID == "" || attachment.TodoID != todoID || attachment.UserID != userID {
+		return ErrAttachmentNotFound
+	}
+	attachment.TodoID = ""
+	r.version++
+	return nil
+}
+
+// ListDetachedAttachments 获取已经脱离待办事项的附件
+func (r *InMemoryTodoRepository) ListDetachedAttachments(ctx context.Context, limit int) ([]models.Attachment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	attachments := make([]models.Attachment, 0)
+	for _, attachment := range r.attachments {
+		if attachment.TodoID == "" {
+			attachments = append(attachments, *attachment)
+		}
+	}
+	r.mu.RUnlock()
+
+	sortAttachments(attachments)
+	if len(attachments) > limit {
+		attachments = attachments[:limit]
+	}
+	return attachments, nil
+}
+
+// PurgeAttachment 删除已经脱离待办事项的附件的元数据
+func (r *InMemoryTodoRepository) PurgeAttachment(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attachment, ok := r.attachments[id]; ok && attachment.TodoID == "" {
+		delete(r.attachments, id)
+		r.version++
+	}
+	return nil
+}
+
+// sortAttachments 按上传时间排序附件，时间相同时按 ID 排序
+func sortAttachments(attachments []models.Attachment) {
+	sort.Slice(attachments, func(i, j int) bool {
+		if c := attachments[i].CreatedAt.Compare(attachments[j].CreatedAt); c != 0 {
+			return c < 0
+		}
+		return attachments[i].ID < attachments[j].ID
+	})
+}
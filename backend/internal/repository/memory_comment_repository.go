@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+var _ CommentRepository = (*InMemoryTodoRepository)(nil)
+
+// ListComments 按创建时间正序分页获取待办事项的评论
+func (r *InMemoryTodoRepository) ListComments(ctx context.Context, todoID string, opts models.CommentListOptions) (*models.CommentPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var after *models.Comment
+	if opts.After != nil {
+		t, err := opts.After.Time()
+		if err != nil {
+			return nil, err
+		}
+		after = &models.Comment{ID: opts.After.ID, CreatedAt: t}
+	}
+
+	r.mu.RLock()
+	var matched []models.Comment
+	for _, comment := range r.comments[todoID] {
+		if after != nil && compareComments(comment, after) <= 0 {
+			continue
+		}
+		matched = append(matched, *comment)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		return compareComments(&matched[i], &matched[j]) < 0
+	})
+	if len(matched) > opts.Limit+1 {
+		matched = matched[:opts.Limit+1]
+	}
+
+	return newCommentPage(matched, opts), nil
+}
+
+// CommentCounts 批量统计待办事项的评论数
+func (r *InMemoryTodoRepository) CommentCounts(ctx context.Context, todoIDs []string) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]int)
+	for _, todoID := range todoIDs {
+		if n := len(r.comments[todoID]); n > 0 {
+			result[todoID] = n
+		}
+	}
+	return result, nil
+}
+
+// GetComment 获取待办事项的一条评论
+func (r *InMemoryTodoRepository) GetComment(ctx context.Context, todoID, id string) (*models.Comment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, ok := r.comments[todoID][id]
+	if !ok {
+		return nil, ErrCommentNotFound
+	}
+	result := *comment
+	return &result, nil
+}
+
+// CreateComment 保存评论
+func (r *InMemoryTodoRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.owners[comment.TodoID]; !ok {
+		return ErrTodoNotFound
+	}
+
+	if comment.ID == "" {
+		comment.ID = uuid.New().String()
+	}
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+	comment.UpdatedAt = comment.CreatedAt
+
+	stored := *comment
+	if r.comments[comment.TodoID] == nil {
+		r.comments[comment.TodoID] = make(map[string]*models.Comment)
+	}
+	r.comments[comment.TodoID][comment.ID] = &stored
+	r.version++
+	return nil
+}
+
+// UpdateComment 修改评论的内容
+func (r *InMemoryTodoRepository) UpdateComment(ctx context.Context, comment *models.Comment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.comments[comment.TodoID][comment.ID]
+	if !ok || existing.UserID != comment.UserID {
+		return ErrCommentNotFound
+	}
+	existing.Body = comment.Body
+	existing.UpdatedAt = time.Now()
+	r.version++
+
+	*comment = *existing
+	return nil
+}
+
+// DeleteComment 删除 userID 作为作者的一条评论
+func (r *InMemoryTodoRepository) DeleteComment(ctx context.Context, userID, todoID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	comment, ok := r.comments[todoID][id]
+	if !ok || comment.UserID != userID {
+		return ErrCommentNotFound
+	}
+	delete(r.comments[todoID], id)
+	if len(r.comments[todoID]) == 0 {
+		delete(r.comments, todoID)
+	}
+	r.version++
+	return nil
+}
+
+// compareComments 按创建时间和 ID 比较两条评论
+func compareComments(a, b *models.Comment) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
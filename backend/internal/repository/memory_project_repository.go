@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ListProjects 获取指定用户的所有项目，收件箱在最前，其余按位置排序
+func (r *InMemoryTodoRepository) ListProjects(ctx context.Context, userID string) ([]models.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	projects := make([]models.Project, 0)
+	for _, project := range r.projects {
+		if project.UserID == userID {
+			projects = append(projects, *project)
+		}
+	}
+	r.mu.RUnlock()
+
+	models.SortProjects(projects)
+	return projects, nil
+}
+
+// GetProject 获取指定用户的单个项目
+func (r *InMemoryTodoRepository) GetProject(ctx context.Context, userID, id string) (*models.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok || project.UserID != userID {
+		return nil, ErrProjectNotFound
+	}
+
+	result := *project
+	return &result, nil
+}
+
+// EnsureInbox 返回指定用户的收件箱，不存在时创建，并把还没有分配项目的待办事项移入收件箱
+func (r *InMemoryTodoRepository) EnsureInbox(ctx context.Context, userID string) (*models.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, project := range r.projects {
+		if project.UserID == userID && project.Inbox {
+			result := *project
+			return &result, nil
+		}
+	}
+
+	now := time.Now()
+	inbox := &models.Project{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      models.InboxProjectName,
+		Color:     models.DefaultProjectColor,
+		Inbox:     true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	r.projects[inbox.ID] = inbox
+	for _, todo := range r.byUser[userID] {
+		if todo.ProjectID == "" {
+			todo.ProjectID = inbox.ID
+		}
+	}
+	r.version++
+
+	result := *inbox
+	return &result, nil
+}
+
+// CreateProject 创建项目
+func (r *InMemoryTodoRepository) CreateProject(ctx context.Context, userID string, project *models.Project) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if project.ID == "" {
+		project.ID = uuid.New().String()
+	}
+	if project.CreatedAt.IsZero() {
+		project.CreatedAt = time.Now()
+	}
+	project.UpdatedAt = project.CreatedAt
+	project.UserID = userID
+
+	stored := *project
+	r.projects[project.ID] = &stored
+	r.version++
+	return nil
+}
+
+// UpdateProject 修改项目的名称、颜色、图标、归档状态和位置
+func (r *InMemoryTodoRepository) UpdateProject(ctx context.Context, userID string, project *models.Project) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.projects[project.ID]
+	if !ok || existing.UserID != userID {
+		return ErrProjectNotFound
+	}
+
+	existing.Name = project.Name
+	existing.Color = project.Color
+	existing.Icon = project.Icon
+	existing.Archived = project.Archived
+	existing.Position = project.Position
+	existing.UpdatedAt = time.Now()
+	r.version++
+
+	*project = *existing
+	return nil
+}
+
+// DeleteProject 删除项目，moveTo 不为空时把项目中的待办事项移动到 moveTo，否则一并删除
+func (r *InMemoryTodoRepository) DeleteProject(ctx context.Context, userID, id, moveTo string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	project, ok := r.projects[id]
+	if !ok || project.UserID != userID {
+		return ErrProjectNotFound
+	}
+	if moveTo != "" {
+		if target, ok := r.projects[moveTo]; !ok || target.UserID != userID {
+			return ErrProjectNotFound
+		}
+	}
+
+	now := time.Now()
+	for todoID, todo := range r.byUser[userID] {
+		if todo.ProjectID != id {
+			continue
+		}
+		if moveTo == "" {
+			r.remove(userID, todoID)
+			continue
+		}
+		todo.ProjectID = moveTo
+		todo.UpdatedAt = now
+	}
+	delete(r.projects, id)
+	r.removeShares(models.ShareProject, id)
+	r.version++
+	return nil
+}
+
+// ProjectCounts 统计指定用户每个项目中待办事项的数量
+func (r *InMemoryTodoRepository) ProjectCounts(ctx context.Context, userID string) (map[string]models.ProjectCounts, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]models.ProjectCounts)
+	for _, todo := range r.byUser[userID] {
+		if todo.ProjectID == "" {
+			continue
+		}
+		c := counts[todo.ProjectID]
+		c.Total++
+		if todo.Completed {
+			c.Completed++
+		}
+		counts[todo.ProjectID] = c
+	}
+	return counts, nil
+}
+
+// MoveTodos 把指定用户的多个待办事项移动到 projectID
+func (r *InMemoryTodoRepository) MoveTodos(ctx context.Context, userID string, todoIDs []string, projectID string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	moved := 0
+	for _, id := range todoIDs {
+		if todo, ok := r.byUser[userID][id]; ok {
+			todo.ProjectID = projectID
+			todo.UpdatedAt = now
+			moved++
+		}
+	}
+	if moved > 0 {
+		r.version++
+	}
+	return moved, nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// memoryReminderState 一个待办事项的提醒状态，对应数据库中的 todo_reminders 表
+type memoryReminderState struct {
+	// RemindedFor 最近一次已提醒的提醒时间
+	RemindedFor *time.Time `json:"reminded_for,omitempty"`
+	LeaseOwner  string     `json:"lease_owner,omitempty"`
+	LeaseUntil  *time.Time `json:"lease_until,omitempty"`
+	Attempts    int        `json:"attempts,omitempty"`
+}
+
+// ClaimDueReminders 领取到期的提醒。内存存储只在单个进程内使用，加写锁即可保证不会重复领取。
+func (r *InMemoryTodoRepository) ClaimDueReminders(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]models.Reminder, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*models.Todo
+	for _, todos := range r.byUser {
+		for _, todo := range todos {
+			if r.reminderDue(todo, now) {
+				due = append(due, todo)
+			}
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if c := due[i].RemindAt.Compare(*due[j].RemindAt); c != 0 {
+			return c < 0
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leaseUntil := now.Add(lease)
+	reminders := make([]models.Reminder, len(due))
+	for i, todo := range due {
+		state, ok := r.reminders[todo.ID]
+		if !ok {
+			state = &memoryReminderState{}
+			r.reminders[todo.ID] = state
+		}
+		state.LeaseOwner = owner
+		state.LeaseUntil = &leaseUntil
+
+		reminders[i] = models.Reminder{
+			TodoID:   todo.ID,
+			UserID:   todo.UserID,
+			Title:    todo.Title,
+			DueAt:    todo.DueAt,
+			RemindAt: *todo.RemindAt,
+			Attempts: state.Attempts,
+		}
+		// 工作区的待办事项以工作区 ID 作为所有者，同时带上成员，提醒发给每个成员
+		if _, ok := r.workspaces[todo.UserID]; ok {
+			reminders[i].WorkspaceID = todo.UserID
+			for userID := range r.members[todo.UserID] {
+				reminders[i].Recipients = append(reminders[i].Recipients, userID)
+			}
+			sort.Strings(reminders[i].Recipients)
+		}
+	}
+	if len(reminders) > 0 {
+		r.version++
+	}
+	return reminders, nil
+}
+
+// CompleteReminder 将领取的提醒标记为已提醒并释放租约
+func (r *InMemoryTodoRepository) CompleteReminder(ctx context.Context, owner string, reminder models.Reminder) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.reminders[reminder.TodoID]
+	if !ok || state.LeaseOwner != owner {
+		return nil
+	}
+	remindedFor := reminder.RemindAt
+	*state = memoryReminderState{RemindedFor: &remindedFor}
+	r.version++
+	return nil
+}
+
+// ReleaseReminder 释放领取的提醒，retryAt 之前不会再被领取
+func (r *InMemoryTodoRepository) ReleaseReminder(ctx context.Context, owner string, reminder models.Reminder, retryAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, ok := r.reminders[reminder.TodoID]
+	if !ok || state.LeaseOwner != owner {
+		return nil
+	}
+	state.LeaseOwner = ""
+	state.LeaseUntil = &retryAt
+	state.Attempts = reminder.Attempts + 1
+	r.version++
+	return nil
+}
+
+// reminderDue 判断待办事项的提醒是否到期且可以领取，调用方需持有锁
+func (r *InMemoryTodoRepository) reminderDue(todo *models.Todo, now time.Time) bool {
+	if todo.RemindAt == nil || todo.Completed || todo.RemindAt.After(now) {
+		return false
+	}
+	state, ok := r.reminders[todo.ID]
+	if !ok {
+		return true
+	}
+	if state.RemindedFor != nil && state.RemindedFor.Equal(*todo.RemindAt) {
+		return false
+	}
+	return state.LeaseUntil == nil || !state.LeaseUntil.After(now)
+}
+
+// CreateNotification 保存一条站内通知，相同 Key 的通知只保存一次
+func (r *InMemoryTodoRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range r.notifications[notification.UserID] {
+		if n.Key == notification.Key {
+			*notification = *n
+			return nil
+		}
+	}
+
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+	stored := *notification
+	r.notifications[stored.UserID] = append(r.notifications[stored.UserID], &stored)
+	r.version++
+	return nil
+}
+
+// ListNotifications 按创建时间倒序分页获取指定用户的站内通知
+func (r *InMemoryTodoRepository) ListNotifications(ctx context.Context, userID string, opts models.NotificationListOptions) (*models.NotificationPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var after *models.Notification
+	if opts.After != nil {
+		t, err := opts.After.Time()
+		if err != nil {
+			return nil, err
+		}
+		after = &models.Notification{ID: opts.After.ID, CreatedAt: t}
+	}
+
+	r.mu.RLock()
+	unread := 0
+	var matched []models.Notification
+	for _, n := range r.notifications[userID] {
+		if n.ReadAt == nil {
+			unread++
+		}
+		if opts.UnreadOnly && n.ReadAt != nil {
+			continue
+		}
+		if after != nil && compareNotifications(n, after) >= 0 {
+			continue
+		}
+		matched = append(matched, *n)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		return compareNotifications(&matched[i], &matched[j]) > 0
+	})
+	if len(matched) > opts.Limit+1 {
+		matched = matched[:opts.Limit+1]
+	}
+
+	return newNotificationPage(matched, unread, opts), nil
+}
+
+// MarkNotificationRead 将通知标记为已读
+func (r *InMemoryTodoRepository) MarkNotificationRead(ctx context.Context, userID, id string, at time.Time) (*models.Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range r.notifications[userID] {
+		if n.ID != id {
+			continue
+		}
+		if n.ReadAt == nil {
+			n.ReadAt = &at
+			r.version++
+		}
+		result := *n
+		return &result, nil
+	}
+	return nil, ErrNotificationNotFound
+}
+
+// MarkAllNotificationsRead 将指定用户的所有未读通知标记为已读
+func (r *InMemoryTodoRepository) MarkAllNotificationsRead(ctx context.Context, userID string, at time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated := 0
+	for _, n := range r.notifications[userID] {
+		if n.ReadAt == nil {
+			n.ReadAt = &at
+			updated++
+		}
+	}
+	if updated > 0 {
+		r.version++
+	}
+	return updated, nil
+}
+
+// compareNotifications 按创建时间比较两条通知，时间相同时比较 ID
+func compareNotifications(a, b *models.Notification) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return strings.Compare(a.ID, b.ID)
+}
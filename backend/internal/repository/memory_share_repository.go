@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+var _ ShareRepository = (*InMemoryTodoRepository)(nil)
+
+// TodoAccess 返回用户对待办事项的权限
+func (r *InMemoryTodoRepository) TodoAccess(ctx context.Context, userID, todoID string) (*models.Access, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ownerID, ok := r.owners[todoID]
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	projectID := r.byUser[ownerID][todoID].ProjectID
+	var roles []models.ShareRole
+	for _, share := range r.shares {
+		if share.UserID != userID {
+			continue
+		}
+		if (share.ResourceType == models.ShareTodo && share.ResourceID == todoID) ||
+			(share.ResourceType == models.ShareProject && projectID != "" && share.ResourceID == projectID) {
+			roles = append(roles, share.Role)
+		}
+	}
+	access, ok := newAccess(userID, ownerID, roles)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	return access, nil
+}
+
+// ProjectAccess 返回用户对项目的权限
+func (r *InMemoryTodoRepository) ProjectAccess(ctx context.Context, userID, projectID string) (*models.Access, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[projectID]
+	if !ok {
+		return nil, ErrProjectNotFound
+	}
+
+	var roles []models.ShareRole
+	if share := r.findShare(models.ShareProject, projectID, userID); share != nil {
+		roles = append(roles, share.Role)
+	}
+	access, ok := newAccess(userID, project.UserID, roles)
+	if !ok {
+		return nil, ErrProjectNotFound
+	}
+	return access, nil
+}
+
+// ListShares 获取资源的所有共享，按创建时间排序
+func (r *InMemoryTodoRepository) ListShares(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Share, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	shares := make([]models.Share, 0)
+	for _, share := range r.shares {
+		if share.ResourceType == resourceType && share.ResourceID == resourceID {
+			shares = append(shares, *share)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(shares, compareShares)
+	return shares, nil
+}
+
+// ListSharedWith 获取共享给指定用户的所有资源，按创建时间排序
+func (r *InMemoryTodoRepository) ListSharedWith(ctx context.Context, userID string) ([]models.Share, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	shares := make([]models.Share, 0)
+	for _, share := range r.shares {
+		if share.UserID == userID {
+			shares = append(shares, *share)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(shares, compareShares)
+	return shares, nil
+}
+
+// GetShare 获取资源的一条共享
+func (r *InMemoryTodoRepository) GetShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) (*models.Share, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	share, ok := r.shares[id]
+	if !ok || share.ResourceType != resourceType || share.ResourceID != resourceID {
+		return nil, ErrShareNotFound
+	}
+	result := *share
+	return &result, nil
+}
+
+// DeleteShare 撤销资源的一条共享
+func (r *InMemoryTodoRepository) DeleteShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	share, ok := r.shares[id]
+	if !ok || share.ResourceType != resourceType || share.ResourceID != resourceID {
+		return ErrShareNotFound
+	}
+	delete(r.shares, id)
+	r.version++
+	return nil
+}
+
+// CreateInvitation 保存邀请，资源必须存在
+func (r *InMemoryTodoRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch invitation.ResourceType {
+	case models.ShareTodo:
+		if _, ok := r.owners[invitation.ResourceID]; !ok {
+			return ErrTodoNotFound
+		}
+	case models.ShareProject:
+		if _, ok := r.projects[invitation.ResourceID]; !ok {
+			return ErrProjectNotFound
+		}
+	}
+
+	if invitation.ID == "" {
+		invitation.ID = uuid.New().String()
+	}
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+
+	stored := *invitation
+	r.invitations[stored.ID] = &stored
+	r.version++
+	return nil
+}
+
+// ListInvitations 获取资源的所有邀请，按创建时间排序
+func (r *InMemoryTodoRepository) ListInvitations(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	invitations := make([]models.Invitation, 0)
+	for _, invitation := range r.invitations {
+		if invitation.ResourceType == resourceType && invitation.ResourceID == resourceID {
+			invitations = append(invitations, *invitation)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(invitations, func(a, b models.Invitation) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return invitations, nil
+}
+
+// GetInvitationByToken 按令牌的 SHA-256 获取邀请
+func (r *InMemoryTodoRepository) GetInvitationByToken(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TokenHash == tokenHash {
+			result := *invitation
+			return &result, nil
+		}
+	}
+	return nil, ErrInvitationNotFound
+}
+
+// DeleteInvitation 撤销资源的一条邀请
+func (r *InMemoryTodoRepository) DeleteInvitation(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invitation, ok := r.invitations[id]
+	if !ok || invitation.ResourceType != resourceType || invitation.ResourceID != resourceID {
+		return ErrInvitationNotFound
+	}
+	delete(r.invitations, id)
+	r.version++
+	return nil
+}
+
+// AcceptInvitation 删除邀请并把资源共享给 userID，已有共享时保留权限较高的角色
+func (r *InMemoryTodoRepository) AcceptInvitation(ctx context.Context, invitation *models.Invitation, userID string) (*models.Share, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.invitations[invitation.ID]; !ok {
+		return nil, ErrInvitationNotFound
+	}
+	delete(r.invitations, invitation.ID)
+
+	now := time.Now()
+	share := r.findShare(invitation.ResourceType, invitation.ResourceID, userID)
+	if share == nil {
+		share = &models.Share{
+			ID:           uuid.New().String(),
+			ResourceType: invitation.ResourceType,
+			ResourceID:   invitation.ResourceID,
+			OwnerID:      invitation.OwnerID,
+			UserID:       userID,
+			Role:         invitation.Role,
+			CreatedBy:    invitation.InvitedBy,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		r.shares[share.ID] = share
+	} else if role := models.MaxShareRole(share.Role, invitation.Role); role != share.Role {
+		share.Role = role
+		share.UpdatedAt = now
+	}
+	r.version++
+
+	result := *share
+	return &result, nil
+}
+
+// findShare 查找用户对资源的共享，调用方需持有锁
+func (r *InMemoryTodoRepository) findShare(resourceType models.ShareResourceType, resourceID, userID string) *models.Share {
+	for _, share := range r.shares {
+		if share.ResourceType == resourceType && share.ResourceID == resourceID && share.UserID == userID {
+			return share
+		}
+	}
+	return nil
+}
+
+// removeShares 删除资源的所有共享和邀请，调用方需持有写锁
+func (r *InMemoryTodoRepository) removeShares(resourceType models.ShareResourceType, resourceID string) {
+	for id, share := range r.shares {
+		if share.ResourceType == resourceType && share.ResourceID == resourceID {
+			delete(r.shares, id)
+		}
+	}
+	for id, invitation := range r.invitations {
+		if invitation.ResourceType == resourceType && invitation.ResourceID == resourceID {
+			delete(r.invitations, id)
+		}
+	}
+}
+
+// compareShares 按创建时间和 ID 比较两条共享
+func compareShares(a, b models.Share) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+var _ SubtaskRepository = (*InMemoryTodoRepository)(nil)
+
+// ListSubtasks 获取待办事项的所有子任务，按位置排序
+func (r *InMemoryTodoRepository) ListSubtasks(ctx context.Context, userID, todoID string) ([]models.Subtask, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	subtasks := make([]models.Subtask, 0, len(r.subtasks[todoID]))
+	for _, subtask := range r.subtasks[todoID] {
+		if subtask.UserID == userID {
+			subtasks = append(subtasks, *subtask)
+		}
+	}
+	r.mu.RUnlock()
+
+	models.SortSubtasks(subtasks)
+	return subtasks, nil
+}
+
+// SubtaskProgress 批量统计待办事项的子任务进度
+func (r *InMemoryTodoRepository) SubtaskProgress(ctx context.Context, userID string, todoIDs []string) (map[string]models.SubtaskProgress, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]models.SubtaskProgress)
+	for _, todoID := range todoIDs {
+		var progress models.SubtaskProgress
+		for _, subtask := range r.subtasks[todoID] {
+			if subtask.UserID != userID {
+				continue
+			}
+			progress.Total++
+			if subtask.Completed {
+				progress.Completed++
+			}
+		}
+		if progress.Total > 0 {
+			result[todoID] = progress
+		}
+	}
+	return result, nil
+}
+
+// CreateSubtask 创建子任务
+func (r *InMemoryTodoRepository) CreateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUser[userID][subtask.TodoID]; !ok {
+		return ErrTodoNotFound
+	}
+
+	if subtask.ID == "" {
+		subtask.ID = uuid.New().String()
+	}
+	if subtask.CreatedAt.IsZero() {
+		subtask.CreatedAt = time.Now()
+	}
+	subtask.UpdatedAt = subtask.CreatedAt
+	subtask.UserID = userID
+
+	stored := *subtask
+	if r.subtasks[subtask.TodoID] == nil {
+		r.subtasks[subtask.TodoID] = make(map[string]*models.Subtask)
+	}
+	r.subtasks[subtask.TodoID][subtask.ID] = &stored
+	r.version++
+	return nil
+}
+
+// UpdateSubtask 修改子任务的标题、完成状态和位置
+func (r *InMemoryTodoRepository) UpdateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.subtasks[subtask.TodoID][subtask.ID]
+	if !ok || existing.UserID != userID {
+		return ErrSubtaskNotFound
+	}
+
+	existing.Title = subtask.Title
+	existing.Completed = subtask.Completed
+	existing.Position = subtask.Position
+	existing.UpdatedAt = time.Now()
+	r.version++
+
+	*subtask = *existing
+	return nil
+}
+
+// DeleteSubtask 删除待办事项的一个子任务
+func (r *InMemoryTodoRepository) DeleteSubtask(ctx context.Context, userID, todoID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.subtasks[todoID][id]
+	if !ok || existing.UserID != userID {
+		return ErrSubtaskNotFound
+	}
+
+	delete(r.subtasks[todoID], id)
+	if len(r.subtasks[todoID]) == 0 {
+		delete(r.subtasks, todoID)
+	}
+	r.version++
+	return nil
+}
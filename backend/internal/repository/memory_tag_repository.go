@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ListTags 获取指定用户的所有标签，按名称排序
+func (r *InMemoryTodoRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	tags := make([]models.Tag, 0)
+	for _, tag := range r.tags {
+		if tag.UserID == userID {
+			tags = append(tags, *tag)
+		}
+	}
+	r.mu.RUnlock()
+
+	models.SortTags(tags)
+	return tags, nil
+}
+
+// GetTag 获取指定用户的单个标签
+func (r *InMemoryTodoRepository) GetTag(ctx context.Context, userID, id string) (*models.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tag, ok := r.tags[id]
+	if !ok || tag.UserID != userID {
+		return nil, ErrTagNotFound
+	}
+
+	result := *tag
+	return &result, nil
+}
+
+// CreateTag 创建标签，同一用户的标签名称不区分大小写唯一
+func (r *InMemoryTodoRepository) CreateTag(ctx context.Context, userID string, tag *models.Tag) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if tag.ID == "" {
+		tag.ID = uuid.New().String()
+	}
+	if _, exists := r.tags[tag.ID]; exists || r.tagNameTaken(userID, tag.Name, "") {
+		return ErrTagAlreadyExists
+	}
+
+	if tag.CreatedAt.IsZero() {
+		tag.CreatedAt = time.Now()
+	}
+	tag.UpdatedAt = tag.CreatedAt
+	tag.UserID = userID
+
+	stored := *tag
+	r.tags[tag.ID] = &stored
+	r.version++
+	return nil
+}
+
+// UpdateTag 修改标签的名称和颜色
+func (r *InMemoryTodoRepository) UpdateTag(ctx context.Context, userID string, tag *models.Tag) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.tags[tag.ID]
+	if !ok || existing.UserID != userID {
+		return ErrTagNotFound
+	}
+	if r.tagNameTaken(userID, tag.Name, tag.ID) {
+		return ErrTagAlreadyExists
+	}
+
+	existing.Name = tag.Name
+	existing.Color = tag.Color
+	existing.UpdatedAt = time.Now()
+	r.version++
+
+	*tag = *existing
+	return nil
+}
+
+// DeleteTag 删除标签及其与待办事项的关联
+func (r *InMemoryTodoRepository) DeleteTag(ctx context.Context, userID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tag, ok := r.tags[id]
+	if !ok || tag.UserID != userID {
+		return ErrTagNotFound
+	}
+
+	delete(r.tags, id)
+	for todoID, tagIDs := range r.todoTags {
+		delete(tagIDs, id)
+		if len(tagIDs) == 0 {
+			delete(r.todoTags, todoID)
+		}
+	}
+	r.version++
+	return nil
+}
+
+// TodoTags 批量获取待办事项的标签
+func (r *InMemoryTodoRepository) TodoTags(ctx context.Context, userID string, todoIDs []string) (map[string][]models.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	result := make(map[string][]models.Tag)
+	for _, todoID := range todoIDs {
+		if _, ok := r.byUser[userID][todoID]; !ok {
+			continue
+		}
+		for tagID := range r.todoTags[todoID] {
+			if tag, ok := r.tags[tagID]; ok {
+				result[todoID] = append(result[todoID], *tag)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, tags := range result {
+		models.SortTags(tags)
+	}
+	return result, nil
+}
+
+// SetTodoTags 把待办事项的标签替换为 tagIDs
+func (r *InMemoryTodoRepository) SetTodoTags(ctx context.Context, userID, todoID string, tagIDs []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUser[userID][todoID]; !ok {
+		return ErrTodoNotFound
+	}
+
+	delete(r.todoTags, todoID)
+	for _, tagID := range tagIDs {
+		if err := r.linkTag(userID, todoID, tagID); err != nil {
+			return err
+		}
+	}
+	r.version++
+	return nil
+}
+
+// AddTodoTag 给待办事项添加一个标签
+func (r *InMemoryTodoRepository) AddTodoTag(ctx context.Context, userID, todoID, tagID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUser[userID][todoID]; !ok {
+		return ErrTodoNotFound
+	}
+	if err := r.linkTag(userID, todoID, tagID); err != nil {
+		return err
+	}
+	r.version++
+	return nil
+}
+
+// RemoveTodoTag 去掉待办事项的一个标签
+func (r *InMemoryTodoRepository) RemoveTodoTag(ctx context.Context, userID, todoID, tagID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUser[userID][todoID]; !ok {
+		return ErrTodoNotFound
+	}
+
+	delete(r.todoTags[todoID], tagID)
+	if len(r.todoTags[todoID]) == 0 {
+		delete(r.todoTags, todoID)
+	}
+	r.version++
+	return nil
+}
+
+// linkTag 关联待办事项和标签，与数据库的外键一样要求标签存在，调用方需持有写锁
+func (r *InMemoryTodoRepository) linkTag(userID, todoID, tagID string) error {
+	if tag, ok := r.tags[tagID]; !ok || tag.UserID != userID {
+		return ErrTagNotFound
+	}
+	tagIDs, ok := r.todoTags[todoID]
+	if !ok {
+		tagIDs = make(map[string]struct{})
+		r.todoTags[todoID] = tagIDs
+	}
+	tagIDs[tagID] = struct{}{}
+	return nil
+}
+
+// tagNameTaken 判断用户是否已有同名的其他标签，名称比较不区分大小写，调用方需持有锁
+func (r *InMemoryTodoRepository) tagNameTaken(userID, name, exceptID string) bool {
+	for id, tag := range r.tags {
+		if id != exceptID && tag.UserID == userID && strings.EqualFold(tag.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTags 判断待办事项的标签是否满足过滤条件，调用方需持有锁
+func (r *InMemoryTodoRepository) hasTags(todoID string, filter models.TodoFilter) bool {
+	if len(filter.TagIDs) == 0 {
+		return true
+	}
+	tagIDs := r.todoTags[todoID]
+	for _, tagID := range filter.TagIDs {
+		_, ok := tagIDs[tagID]
+		if ok && filter.TagMatch != models.TagMatchAll {
+			return true
+		}
+		if !ok && filter.TagMatch == models.TagMatchAll {
+			return false
+		}
+	}
+	return filter.TagMatch == models.TagMatchAll
+}
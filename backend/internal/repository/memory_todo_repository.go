@@ -0,0 +1,757 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// memorySnapshotVersion 快照文件的格式版本
+const memorySnapshotVersion = 1
+
+// memorySnapshot 快照文件的内容
+type memorySnapshot struct {
+	Version int           `json:"version"`
+	SavedAt time.Time     `json:"saved_at"`
+	Todos   []models.Todo `json:"todos"`
+	// Reminders 按待办事项 ID 索引的提醒状态，Notifications 为所有站内通知，旧版快照中没有这两项
+	Reminders     map[string]memoryReminderState `json:"reminders,omitempty"`
+	Notifications []models.Notification          `json:"notifications,omitempty"`
+	// Tags 所有标签，TodoTags 按待办事项 ID 索引的标签 ID，旧版快照中没有这两项
+	Tags     []models.Tag        `json:"tags,omitempty"`
+	TodoTags map[string][]string `json:"todo_tags,omitempty"`
+	// Projects 所有项目，旧版快照中没有这一项
+	Projects []models.Project `json:"projects,omitempty"`
+	// Subtasks 所有子任务，旧版快照中没有这一项
+	Subtasks []models.Subtask `json:"subtasks,omitempty"`
+	// Attachments 所有附件的元数据，旧版快照中没有这一项
+	Attachments []models.Attachment `json:"attachments,omitempty"`
+	// Comments 所有待办事项的评论，旧版快照中没有这一项
+	Comments []models.Comment `json:"comments,omitempty"`
+	// Shares 所有共享，Invitations 所有邀请，旧版快照中没有这两项
+	Shares      []models.Share      `json:"shares,omitempty"`
+	Invitations []models.Invitation `json:"invitations,omitempty"`
+	// Workspaces 所有工作区，Members 所有工作区成员，旧版快照中没有这两项
+	Workspaces []models.Workspace `json:"workspaces,omitempty"`
+	Members    []models.Member    `json:"members,omitempty"`
+}
+
+// InMemoryTodoRepository 是一个内存实现的 TodoRepository，并发安全，
+// 可选地定期把数据快照到 JSON 文件，并在启动时重新加载
+type InMemoryTodoRepository struct {
+	mu sync.RWMutex
+	// byUser 按用户和 ID 索引的待办事项
+	byUser map[string]map[string]*models.Todo
+	// owners 记录每个 ID 所属的用户，保证 ID 全局唯一
+	owners map[string]string
+	// reminders 按待办事项 ID 索引的提醒状态
+	reminders map[string]*memoryReminderState
+	// notifications 按用户索引的站内通知
+	notifications map[string][]*models.Notification
+	// tags 按 ID 索引的标签，todoTags 按待办事项 ID 索引的标签 ID 集合
+	tags     map[string]*models.Tag
+	todoTags map[string]map[string]struct{}
+	// projects 按 ID 索引的项目
+	projects map[string]*models.Project
+	// subtasks 按待办事项 ID 和子任务 ID 索引的子任务
+	subtasks map[string]map[string]*models.Subtask
+	// attachments 按 ID 索引的附件元数据
+	attachments map[string]*models.Attachment
+	// comments 按待办事项 ID 和评论 ID 索引的评论
+	comments map[string]map[string]*models.Comment
+	// shares 按 ID 索引的共享，invitations 按 ID 索引的邀请
+	shares      map[string]*models.Share
+	invitations map[string]*models.Invitation
+	// workspaces 按 ID 索引的工作区，members 按工作区 ID 和用户 ID 索引的成员
+	workspaces map[string]*models.Workspace
+	members    map[string]map[string]*models.Member
+
+	// version 每次写操作递增，savedVersion 为最近一次快照时的版本
+	version      uint64
+	savedVersion uint64
+
+	snapshotPath string
+	stop         chan struct{}
+	done         chan struct{}
+	closeOnce    sync.Once
+	logger       *zap.Logger
+}
+
+// NewInMemoryTodoRepository 创建一个新的内存 TodoRepository，数据不持久化
+func NewInMemoryTodoRepository() *InMemoryTodoRepository {
+	return &InMemoryTodoRepository{
+		byUser:        make(map[string]map[string]*models.Todo),
+		owners:        make(map[string]string),
+		reminders:     make(map[string]*memoryReminderState),
+		notifications: make(map[string][]*models.Notification),
+		tags:          make(map[string]*models.Tag),
+		todoTags:      make(map[string]map[string]struct{}),
+		projects:      make(map[string]*models.Project),
+		subtasks:      make(map[string]map[string]*models.Subtask),
+		attachments:   make(map[string]*models.Attachment),
+		comments:      make(map[string]map[string]*models.Comment),
+		shares:        make(map[string]*models.Share),
+		invitations:   make(map[string]*models.Invitation),
+		workspaces:    make(map[string]*models.Workspace),
+		members:       make(map[string]map[string]*models.Member),
+		logger:        logger.Log.With(zap.String("component", "InMemoryTodoRepository")),
+	}
+}
+
+// NewInMemoryTodoRepositoryWithSnapshot 创建一个带快照持久化的内存 TodoRepository。
+// 启动时从 path 加载已有快照；interval 大于 0 时按间隔保存，Close 时总会保存一次。
+func NewInMemoryTodoRepositoryWithSnapshot(path string, interval time.Duration) (*InMemoryTodoRepository, error) {
+	r := NewInMemoryTodoRepository()
+	r.snapshotPath = path
+
+	if err := r.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		r.stop = make(chan struct{})
+		r.done = make(chan struct{})
+		go r.snapshotLoop(interval)
+	}
+
+	r.logger.Info("初始化内存 Todo Repository",
+		zap.String("snapshotPath", path),
+		zap.Duration("snapshotInterval", interval),
+		zap.Int("loaded", len(r.owners)))
+
+	return r, nil
+}
+
+// Close 停止定期快照，并保存最后一次快照
+func (r *InMemoryTodoRepository) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		if r.stop != nil {
+			close(r.stop)
+			<-r.done
+		}
+		err = r.saveSnapshot()
+	})
+	return err
+}
+
+// List 按过滤条件、排序和游标分页获取指定用户的待办事项
+func (r *InMemoryTodoRepository) List(ctx context.Context, userID string, opts models.TodoListOptions) (*models.TodoPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	keys, err := todoSortKeys(opts.SortBy, opts.SortDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *models.Todo
+	if opts.After != nil {
+		if after, err = cursorTodo(opts.After, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	matched := make([]models.Todo, 0, len(r.byUser[userID]))
+	for _, todo := range r.byUser[userID] {
+		if matchesTodoFilter(todo, opts.Filter) && r.hasTags(todo.ID, opts.Filter) {
+			matched = append(matched, cloneTodo(todo))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		return compareTodos(&matched[i], &matched[j], keys) < 0
+	})
+
+	// 跳过游标及之前的记录
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return compareTodos(&matched[i], after, keys) > 0
+		})
+	}
+
+	end := start + opts.Limit + 1
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	return newTodoPage(matched[start:end], len(matched), opts), nil
+}
+
+// Search 按相关度搜索指定用户的待办事项
+func (r *InMemoryTodoRepository) Search(ctx context.Context, userID string, opts models.TodoSearchOptions) (*models.TodoSearchPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filter := models.TodoFilter{Completed: opts.Completed}
+
+	r.mu.RLock()
+	candidates := make([]models.Todo, 0, len(r.byUser[userID]))
+	for _, todo := range r.byUser[userID] {
+		if matchesTodoFilter(todo, filter) {
+			candidates = append(candidates, cloneTodo(todo))
+		}
+	}
+	r.mu.RUnlock()
+
+	return searchTodos(candidates, opts)
+}
+
+// Get 获取指定用户的单个待办事项
+func (r *InMemoryTodoRepository) Get(ctx context.Context, userID, id string) (*models.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todo, ok := r.byUser[userID][id]
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	result := cloneTodo(todo)
+	return &result, nil
+}
+
+// Create 创建一个新的待办事项，未设置的 ID 和时间戳会自动填充
+func (r *InMemoryTodoRepository) Create(ctx context.Context, userID string, todo *models.Todo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if todo.ID == "" {
+		todo.ID = uuid.New().String()
+	}
+	if _, exists := r.owners[todo.ID]; exists {
+		return ErrTodoAlreadyExists
+	}
+
+	now := time.Now()
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = now
+	}
+	todo.UpdatedAt = todo.CreatedAt
+	todo.UserID = userID
+
+	stored := cloneTodo(todo)
+	r.put(&stored)
+	r.version++
+	return nil
+}
+
+// Update 更新待办事项，所属用户和创建时间保持不变
+func (r *InMemoryTodoRepository) Update(ctx context.Context, userID string, todo *models.Todo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.byUser[userID][todo.ID]
+	if !ok {
+		return ErrTodoNotFound
+	}
+
+	existing.Title = todo.Title
+	existing.Completed = todo.Completed
+	existing.DueAt = cloneTime(todo.DueAt)
+	existing.RemindAt = cloneTime(todo.RemindAt)
+	existing.SeriesID = todo.SeriesID
+	existing.Recurrence = cloneRecurrence(todo.Recurrence)
+	existing.Priority = todo.Priority
+	existing.ProjectID = todo.ProjectID
+	existing.AutoComplete = todo.AutoComplete
+	existing.Notes = todo.Notes
+	existing.UpdatedAt = time.Now()
+	r.version++
+
+	*todo = cloneTodo(existing)
+	return nil
+}
+
+// Move 修改待办事项的位置
+func (r *InMemoryTodoRepository) Move(ctx context.Context, userID, id, position string) (*models.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	todo, ok := r.byUser[userID][id]
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+
+	todo.Position = position
+	todo.UpdatedAt = time.Now()
+	r.version++
+
+	result := cloneTodo(todo)
+	return &result, nil
+}
+
+// Toggle 切换待办事项的完成状态
+func (r *InMemoryTodoRepository) Toggle(ctx context.Context, userID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	todo, ok := r.byUser[userID][id]
+	if !ok {
+		return ErrTodoNotFound
+	}
+
+	todo.Completed = !todo.Completed
+	todo.UpdatedAt = time.Now()
+	r.version++
+	return nil
+}
+
+// Delete 删除待办事项
+func (r *InMemoryTodoRepository) Delete(ctx context.Context, userID, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUser[userID][id]; !ok {
+		return ErrTodoNotFound
+	}
+
+	r.remove(userID, id)
+	r.version++
+	return nil
+}
+
+// remove 删除待办事项及其提醒状态、标签关联、评论和共享，并解除通知和附件的关联，调用方需持有写锁
+func (r *InMemoryTodoRepository) remove(userID, id string) {
+	delete(r.byUser[userID], id)
+	if len(r.byUser[userID]) == 0 {
+		delete(r.byUser, userID)
+	}
+	delete(r.owners, id)
+	delete(r.reminders, id)
+	delete(r.todoTags, id)
+	delete(r.subtasks, id)
+	delete(r.comments, id)
+	r.removeShares(models.ShareTodo, id)
+	for _, n := range r.notifications[userID] {
+		if n.TodoID == id {
+			n.TodoID = ""
+		}
+	}
+	for _, attachment := range r.attachments {
+		if attachment.TodoID == id {
+			attachment.TodoID = ""
+		}
+	}
+}
+
+// matchesTodoFilter 判断待办事项是否满足过滤条件
+func matchesTodoFilter(todo *models.Todo, filter models.TodoFilter) bool {
+	if filter.Completed != nil && todo.Completed != *filter.Completed {
+		return false
+	}
+	if filter.CreatedAfter != nil && todo.CreatedAt.Before(*filter.CreatedAfter) {
+		return false
+	}
+	if filter.CreatedBefore != nil && !todo.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+	if filter.UpdatedAfter != nil && todo.UpdatedAt.Before(*filter.UpdatedAfter) {
+		return false
+	}
+	if filter.UpdatedBefore != nil && !todo.UpdatedAt.Before(*filter.UpdatedBefore) {
+		return false
+	}
+	if (filter.DueAfter != nil || filter.DueBefore != nil) && todo.DueAt == nil {
+		return false
+	}
+	if filter.DueAfter != nil && todo.DueAt.Before(*filter.DueAfter) {
+		return false
+	}
+	if filter.DueBefore != nil && !todo.DueAt.Before(*filter.DueBefore) {
+		return false
+	}
+	if filter.SeriesID != "" && todo.SeriesID != filter.SeriesID {
+		return false
+	}
+	if filter.ProjectID != "" && todo.ProjectID != filter.ProjectID {
+		return false
+	}
+	return true
+}
+
+// compareTodos 按排序的各列依次比较两个待办事项，小于 0 表示 a 排在 b 之前
+func compareTodos(a, b *models.Todo, keys []todoSortKey) int {
+	for _, key := range keys {
+		var c int
+		switch key.column {
+		case "created_at":
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		case "updated_at":
+			c = a.UpdatedAt.Compare(b.UpdatedAt)
+		case "title":
+			c = strings.Compare(a.Title, b.Title)
+		case "priority":
+			c = cmp.Compare(a.Priority, b.Priority)
+		case "position":
+			c = strings.Compare(a.Position, b.Position)
+		default:
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if key.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// cursorTodo 将游标还原为只包含排序字段和 ID 的待办事项，便于与记录比较
+func cursorTodo(cursor *models.Cursor, keys []todoSortKey) (*models.Todo, error) {
+	values, err := cursorSortValues(cursor, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	todo := &models.Todo{ID: cursor.ID}
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			todo.CreatedAt, todo.UpdatedAt = v, v
+		case int:
+			todo.Priority = models.Priority(v)
+		case string:
+			if keys[i].column == "title" {
+				todo.Title = v
+			} else {
+				todo.Position = v
+			}
+		}
+	}
+	return todo, nil
+}
+
+// cloneTodo 复制待办事项，指针字段指向新的值，避免调用方修改存储中的数据
+func cloneTodo(todo *models.Todo) models.Todo {
+	result := *todo
+	result.DueAt = cloneTime(todo.DueAt)
+	result.RemindAt = cloneTime(todo.RemindAt)
+	result.Recurrence = cloneRecurrence(todo.Recurrence)
+	result.Tags = append([]models.Tag(nil), todo.Tags...)
+	return result
+}
+
+// cloneTime 复制可选的时间，nil 时返回 nil
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	result := *t
+	return &result
+}
+
+// cloneRecurrence 复制可选的重复规则，nil 时返回 nil
+func cloneRecurrence(recurrence *models.Recurrence) *models.Recurrence {
+	if recurrence == nil {
+		return nil
+	}
+	result := *recurrence
+	return &result
+}
+
+// put 写入索引，调用方需持有写锁
+func (r *InMemoryTodoRepository) put(todo *models.Todo) {
+	todos, ok := r.byUser[todo.UserID]
+	if !ok {
+		todos = make(map[string]*models.Todo)
+		r.byUser[todo.UserID] = todos
+	}
+	todos[todo.ID] = todo
+	r.owners[todo.ID] = todo.UserID
+}
+
+// snapshotLoop 按间隔保存快照，直到 Close 被调用
+func (r *InMemoryTodoRepository) snapshotLoop(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.saveSnapshot(); err != nil {
+				r.logger.Error("保存快照失败", zap.Error(err))
+			}
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// loadSnapshot 从快照文件加载数据，文件不存在时视为空数据
+func (r *InMemoryTodoRepository) loadSnapshot() error {
+	if r.snapshotPath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.snapshotPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("读取快照文件失败: %w", err)
+	}
+
+	var snapshot memorySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("解析快照文件失败: %w", err)
+	}
+	if snapshot.Version != memorySnapshotVersion {
+		return fmt.Errorf("不支持的快照版本: %d", snapshot.Version)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backfillMemoryPositions(snapshot.Todos)
+	for i := range snapshot.Todos {
+		todo := snapshot.Todos[i]
+		r.put(&todo)
+	}
+	for id, state := range snapshot.Reminders {
+		r.reminders[id] = &state
+	}
+	for i := range snapshot.Notifications {
+		n := snapshot.Notifications[i]
+		r.notifications[n.UserID] = append(r.notifications[n.UserID], &n)
+	}
+	for i := range snapshot.Tags {
+		tag := snapshot.Tags[i]
+		r.tags[tag.ID] = &tag
+	}
+	for todoID, tagIDs := range snapshot.TodoTags {
+		set := make(map[string]struct{}, len(tagIDs))
+		for _, tagID := range tagIDs {
+			set[tagID] = struct{}{}
+		}
+		r.todoTags[todoID] = set
+	}
+	for i := range snapshot.Projects {
+		project := snapshot.Projects[i]
+		r.projects[project.ID] = &project
+	}
+	for i := range snapshot.Subtasks {
+		subtask := snapshot.Subtasks[i]
+		if r.subtasks[subtask.TodoID] == nil {
+			r.subtasks[subtask.TodoID] = make(map[string]*models.Subtask)
+		}
+		r.subtasks[subtask.TodoID][subtask.ID] = &subtask
+	}
+	for i := range snapshot.Attachments {
+		attachment := snapshot.Attachments[i]
+		r.attachments[attachment.ID] = &attachment
+	}
+	for i := range snapshot.Comments {
+		comment := snapshot.Comments[i]
+		if r.comments[comment.TodoID] == nil {
+			r.comments[comment.TodoID] = make(map[string]*models.Comment)
+		}
+		r.comments[comment.TodoID][comment.ID] = &comment
+	}
+	for i := range snapshot.Shares {
+		share := snapshot.Shares[i]
+		r.shares[share.ID] = &share
+	}
+	for i := range snapshot.Invitations {
+		invitation := snapshot.Invitations[i]
+		r.invitations[invitation.ID] = &invitation
+	}
+	for i := range snapshot.Workspaces {
+		workspace := snapshot.Workspaces[i]
+		r.workspaces[workspace.ID] = &workspace
+	}
+	for i := range snapshot.Members {
+		member := snapshot.Members[i]
+		if r.members[member.WorkspaceID] == nil {
+			r.members[member.WorkspaceID] = make(map[string]*models.Member)
+		}
+		r.members[member.WorkspaceID][member.UserID] = &member
+	}
+	return nil
+}
+
+// backfillMemoryPositions 为旧版快照中没有位置的待办事项按创建时间依次分配位置，
+// 格式与 008 迁移为已有数据生成的位置相同
+func backfillMemoryPositions(todos []models.Todo) {
+	missing := make(map[string][]*models.Todo)
+	for i := range todos {
+		if todos[i].Position == "" {
+			missing[todos[i].UserID] = append(missing[todos[i].UserID], &todos[i])
+		}
+	}
+	for _, list := range missing {
+		sort.Slice(list, func(i, j int) bool {
+			if c := list[i].CreatedAt.Compare(list[j].CreatedAt); c != 0 {
+				return c < 0
+			}
+			return list[i].ID < list[j].ID
+		})
+		for i, todo := range list {
+			todo.Position = fmt.Sprintf("%012dV", i+1)
+		}
+	}
+}
+
+// saveSnapshot 将数据写入快照文件。先写临时文件再重命名，避免写到一半时留下损坏的快照。
+func (r *InMemoryTodoRepository) saveSnapshot() error {
+	if r.snapshotPath == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	if r.version == r.savedVersion {
+		r.mu.RUnlock()
+		return nil
+	}
+	version := r.version
+	snapshot := memorySnapshot{
+		Version: memorySnapshotVersion,
+		SavedAt: time.Now(),
+		Todos:   make([]models.Todo, 0, len(r.owners)),
+	}
+	for _, todos := range r.byUser {
+		for _, todo := range todos {
+			snapshot.Todos = append(snapshot.Todos, cloneTodo(todo))
+		}
+	}
+	if len(r.reminders) > 0 {
+		snapshot.Reminders = make(map[string]memoryReminderState, len(r.reminders))
+		for id, state := range r.reminders {
+			snapshot.Reminders[id] = *state
+		}
+	}
+	for _, notifications := range r.notifications {
+		for _, n := range notifications {
+			snapshot.Notifications = append(snapshot.Notifications, *n)
+		}
+	}
+	for _, tag := range r.tags {
+		snapshot.Tags = append(snapshot.Tags, *tag)
+	}
+	for _, project := range r.projects {
+		snapshot.Projects = append(snapshot.Projects, *project)
+	}
+	for _, subtasks := range r.subtasks {
+		for _, subtask := range subtasks {
+			snapshot.Subtasks = append(snapshot.Subtasks, *subtask)
+		}
+	}
+	for _, attachment := range r.attachments {
+		snapshot.Attachments = append(snapshot.Attachments, *attachment)
+	}
+	for _, comments := range r.comments {
+		for _, comment := range comments {
+			snapshot.Comments = append(snapshot.Comments, *comment)
+		}
+	}
+	for _, share := range r.shares {
+		snapshot.Shares = append(snapshot.Shares, *share)
+	}
+	for _, invitation := range r.invitations {
+		snapshot.Invitations = append(snapshot.Invitations, *invitation)
+	}
+	for _, workspace := range r.workspaces {
+		snapshot.Workspaces = append(snapshot.Workspaces, *workspace)
+	}
+	for _, members := range r.members {
+		for _, member := range members {
+			snapshot.Members = append(snapshot.Members, *member)
+		}
+	}
+	if len(r.todoTags) > 0 {
+		snapshot.TodoTags = make(map[string][]string, len(r.todoTags))
+		for todoID, tagIDs := range r.todoTags {
+			for tagID := range tagIDs {
+				snapshot.TodoTags[todoID] = append(snapshot.TodoTags[todoID], tagID)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("序列化快照失败: %w", err)
+	}
+
+	dir := filepath.Dir(r.snapshotPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建快照目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(r.snapshotPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时快照文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入快照失败: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入快照失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入快照失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.snapshotPath); err != nil {
+		return fmt.Errorf("替换快照文件失败: %w", err)
+	}
+
+	r.mu.Lock()
+	if version > r.savedVersion {
+		r.savedVersion = version
+	}
+	r.mu.Unlock()
+
+	r.logger.Debug("已保存快照",
+		zap.String("path", r.snapshotPath),
+		zap.Int("todos", len(snapshot.Todos)))
+	return nil
+}
@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+func TestInMemoryConcurrentAccess(t *testing.T) {
+	repo := NewInMemoryTodoRepository()
+	ctx := context.Background()
+
+	// 多个用户同时创建、更新和列出待办事项，配合 -race 检查数据竞争
+	const (
+		users   = 4
+		perUser = 50
+	)
+	var wg sync.WaitGroup
+	for u := 0; u < users; u++ {
+		userID := fmt.Sprintf("user-%d", u)
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perUser; i++ {
+				due := time.Now().Add(time.Duration(i) * time.Hour)
+				todo := &models.Todo{Title: fmt.Sprintf("待办 %d", i), DueAt: &due}
+				if err := repo.Create(ctx, userID, todo); err != nil {
+					t.Errorf("Create() error = %v", err)
+					return
+				}
+				todo.Title += " 已修改"
+				todo.Completed = i%2 == 0
+				if err := repo.Update(ctx, userID, todo); err != nil {
+					t.Errorf("Update() error = %v", err)
+					return
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perUser; i++ {
+				page, err := repo.List(ctx, userID, models.TodoListOptions{SortBy: models.SortByCreatedAt, SortDir: models.SortAsc, Limit: 10})
+				if err != nil {
+					t.Errorf("List() error = %v", err)
+					return
+				}
+				for _, todo := range page.Items {
+					if todo.UserID != userID {
+						t.Errorf("List(%q) returned todo of %q", userID, todo.UserID)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for u := 0; u < users; u++ {
+		userID := fmt.Sprintf("user-%d", u)
+		page, err := repo.List(ctx, userID, models.TodoListOptions{SortBy: models.SortByCreatedAt, SortDir: models.SortAsc, Limit: perUser})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if page.Total != perUser {
+			t.Errorf("List(%q) total = %d, want %d", userID, page.Total, perUser)
+		}
+	}
+}
+
+func TestInMemoryReturnsCopies(t *testing.T) {
+	repo := NewInMemoryTodoRepository()
+	ctx := context.Background()
+
+	due := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	start := due
+	todo := &models.Todo{
+		Title:      "周报",
+		DueAt:      &due,
+		RemindAt:   &due,
+		Recurrence: &models.Recurrence{Rule: "FREQ=WEEKLY", Timezone: "UTC", Start: start},
+	}
+	if err := repo.Create(ctx, "user-1", todo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// 修改传入的值和返回的值都不能影响存储中的数据
+	due = due.Add(24 * time.Hour)
+	todo.Recurrence.Rule = "FREQ=DAILY"
+
+	got, err := repo.Get(ctx, "user-1", todo.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	*got.DueAt = got.DueAt.Add(time.Hour)
+	*got.RemindAt = got.RemindAt.Add(time.Hour)
+	got.Recurrence.Rule = "FREQ=MONTHLY"
+
+	page, err := repo.List(ctx, "user-1", models.TodoListOptions{SortBy: models.SortByCreatedAt, SortDir: models.SortAsc, Limit: 10})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	page.Items[0].Recurrence.Timezone = "Asia/Shanghai"
+
+	want := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	got, err = repo.Get(ctx, "user-1", todo.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !got.DueAt.Equal(want) || !got.RemindAt.Equal(want) {
+		t.Errorf("stored times = %v, %v, want %v", got.DueAt, got.RemindAt, want)
+	}
+	if got.Recurrence.Rule != "FREQ=WEEKLY" || got.Recurrence.Timezone != "UTC" {
+		t.Errorf("stored recurrence = %+v, want unchanged", got.Recurrence)
+	}
+
+	// Update 之后再修改传入的指针同样不能影响存储
+	updated := *got
+	newDue := want.Add(48 * time.Hour)
+	updated.DueAt = &newDue
+	if err := repo.Update(ctx, "user-1", &updated); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	newDue = newDue.Add(time.Hour)
+	*updated.DueAt = updated.DueAt.Add(time.Hour)
+
+	got, err = repo.Get(ctx, "user-1", todo.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !got.DueAt.Equal(want.Add(48 * time.Hour)) {
+		t.Errorf("stored due after update = %v, want %v", got.DueAt, want.Add(48*time.Hour))
+	}
+}
+
+func TestInMemorySnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	repo, err := NewInMemoryTodoRepositoryWithSnapshot(path, 0)
+	if err != nil {
+		t.Fatalf("NewInMemoryTodoRepositoryWithSnapshot() error = %v", err)
+	}
+	due := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	todo := &models.Todo{
+		Title:      "周报",
+		DueAt:      &due,
+		Recurrence: &models.Recurrence{Rule: "FREQ=WEEKLY", Timezone: "UTC", Start: due},
+		Position:   "V",
+		Notes:      "**备注**",
+	}
+	if err := repo.Create(ctx, "user-1", todo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	other := &models.Todo{Title: "其他用户", Position: "V"}
+	if err := repo.Create(ctx, "user-2", other); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	tag := &models.Tag{Name: "工作", Color: "#ff0000"}
+	if err := repo.CreateTag(ctx, "user-1", tag); err != nil {
+		t.Fatalf("CreateTag() error = %v", err)
+	}
+	if err := repo.SetTodoTags(ctx, "user-1", todo.ID, []string{tag.ID}); err != nil {
+		t.Fatalf("SetTodoTags() error = %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	loaded, err := NewInMemoryTodoRepositoryWithSnapshot(path, 0)
+	if err != nil {
+		t.Fatalf("NewInMemoryTodoRepositoryWithSnapshot() reload error = %v", err)
+	}
+	defer loaded.Close()
+
+	got, err := loaded.Get(ctx, "user-1", todo.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Title != todo.Title || got.Notes != todo.Notes || got.Position != todo.Position || got.UserID != "user-1" {
+		t.Errorf("reloaded todo = %+v, want %+v", got, todo)
+	}
+	if got.DueAt == nil || !got.DueAt.Equal(due) {
+		t.Errorf("reloaded due = %v, want %v", got.DueAt, due)
+	}
+	if got.Recurrence == nil || *got.Recurrence != *todo.Recurrence {
+		t.Errorf("reloaded recurrence = %+v, want %+v", got.Recurrence, todo.Recurrence)
+	}
+	if !got.CreatedAt.Equal(todo.CreatedAt) {
+		t.Errorf("reloaded createdAt = %v, want %v", got.CreatedAt, todo.CreatedAt)
+	}
+	if _, err := loaded.Get(ctx, "user-1", other.ID); err != ErrTodoNotFound {
+		t.Errorf("Get(other user's todo) error = %v, want %v", err, ErrTodoNotFound)
+	}
+	if _, err := loaded.Get(ctx, "user-2", other.ID); err != nil {
+		t.Errorf("Get(user-2) error = %v", err)
+	}
+
+	tags, err := loaded.TodoTags(ctx, "user-1", []string{todo.ID})
+	if err != nil {
+		t.Fatalf("TodoTags() error = %v", err)
+	}
+	if len(tags[todo.ID]) != 1 || tags[todo.ID][0].Name != "工作" {
+		t.Errorf("reloaded tags = %+v, want [工作]", tags[todo.ID])
+	}
+
+	// 重新加载后的 ID 仍然全局唯一
+	if err := loaded.Create(ctx, "user-2", &models.Todo{ID: todo.ID, Title: "重复"}); err != ErrTodoAlreadyExists {
+		t.Errorf("Create(duplicate id) error = %v, want %v", err, ErrTodoAlreadyExists)
+	}
+}
+
+func TestInMemorySnapshotBackfillPositions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	// 旧版快照中的待办事项没有位置，两个用户的待办事项交错排列
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := memorySnapshot{
+		Version: memorySnapshotVersion,
+		Todos: []models.Todo{
+			{ID: "a3", UserID: "user-a", Title: "a3", CreatedAt: base.Add(3 * time.Hour)},
+			{ID: "b1", UserID: "user-b", Title: "b1", CreatedAt: base.Add(1 * time.Hour)},
+			{ID: "a1", UserID: "user-a", Title: "a1", CreatedAt: base.Add(1 * time.Hour)},
+			{ID: "a2", UserID: "user-a", Title: "a2", CreatedAt: base.Add(1 * time.Hour)},
+			{ID: "b2", UserID: "user-b", Title: "b2", CreatedAt: base.Add(2 * time.Hour), Position: "zz"},
+		},
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	repo, err := NewInMemoryTodoRepositoryWithSnapshot(path, 0)
+	if err != nil {
+		t.Fatalf("NewInMemoryTodoRepositoryWithSnapshot() error = %v", err)
+	}
+	defer repo.Close()
+
+	// 位置按创建时间和 ID 分配，已有的位置保持不变
+	want := map[string]string{
+		"a1": "000000000001V",
+		"a2": "000000000002V",
+		"a3": "000000000003V",
+		"b1": "000000000001V",
+		"b2": "zz",
+	}
+	for id, position := range want {
+		userID := "user-" + id[:1]
+		got, err := repo.Get(ctx, userID, id)
+		if err != nil {
+			t.Fatalf("Get(%q, %q) error = %v", userID, id, err)
+		}
+		if got.Position != position {
+			t.Errorf("Get(%q).Position = %q, want %q", id, got.Position, position)
+		}
+	}
+
+	// byUser 和 owners 两个索引保持一致
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	if len(repo.owners) != len(want) {
+		t.Errorf("len(owners) = %d, want %d", len(repo.owners), len(want))
+	}
+	count := 0
+	for userID, todos := range repo.byUser {
+		for id, todo := range todos {
+			count++
+			if repo.owners[id] != userID || todo.UserID != userID {
+				t.Errorf("todo %q indexed under %q, owner %q, UserID %q", id, userID, repo.owners[id], todo.UserID)
+			}
+		}
+	}
+	if count != len(repo.owners) {
+		t.Errorf("byUser holds %d todos, owners holds %d", count, len(repo.owners))
+	}
+}
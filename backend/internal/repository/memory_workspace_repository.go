@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// ListWorkspaces 获取用户加入的所有工作区，按创建时间排序
+func (r *InMemoryTodoRepository) ListWorkspaces(ctx context.Context, userID string) ([]models.Workspace, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	workspaces := make([]models.Workspace, 0)
+	for id, members := range r.members {
+		if member, ok := members[userID]; ok {
+			workspace := *r.workspaces[id]
+			workspace.Role = member.Role
+			workspaces = append(workspaces, workspace)
+		}
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(workspaces, func(a, b models.Workspace) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return workspaces, nil
+}
+
+// GetWorkspace 获取工作区
+func (r *InMemoryTodoRepository) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workspace, ok := r.workspaces[id]
+	if !ok {
+		return nil, ErrWorkspaceNotFound
+	}
+	result := *workspace
+	return &result, nil
+}
+
+// CreateWorkspace 创建工作区，并把创建者添加为所有者
+func (r *InMemoryTodoRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if workspace.ID == "" {
+		workspace.ID = uuid.New().String()
+	}
+	if workspace.CreatedAt.IsZero() {
+		workspace.CreatedAt = time.Now()
+	}
+	workspace.UpdatedAt = workspace.CreatedAt
+	workspace.Role = ""
+
+	stored := *workspace
+	r.workspaces[stored.ID] = &stored
+	r.members[stored.ID] = map[string]*models.Member{
+		stored.CreatedBy: {
+			WorkspaceID: stored.ID,
+			UserID:      stored.CreatedBy,
+			Role:        models.WorkspaceOwner,
+			CreatedAt:   stored.CreatedAt,
+			UpdatedAt:   stored.CreatedAt,
+		},
+	}
+	r.version++
+	return nil
+}
+
+// UpdateWorkspace 修改工作区的名称
+func (r *InMemoryTodoRepository) UpdateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.workspaces[workspace.ID]
+	if !ok {
+		return ErrWorkspaceNotFound
+	}
+
+	existing.Name = workspace.Name
+	existing.UpdatedAt = time.Now()
+	r.version++
+
+	*workspace = *existing
+	return nil
+}
+
+// DeleteWorkspace 删除工作区、它的成员，以及以工作区为所有者的项目、待办事项和标签
+func (r *InMemoryTodoRepository) DeleteWorkspace(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.workspaces[id]; !ok {
+		return ErrWorkspaceNotFound
+	}
+
+	for todoID := range r.byUser[id] {
+		r.remove(id, todoID)
+	}
+	for projectID, project := range r.projects {
+		if project.UserID == id {
+			delete(r.projects, projectID)
+			r.removeShares(models.ShareProject, projectID)
+		}
+	}
+	for tagID, tag := range r.tags {
+		if tag.UserID == id {
+			delete(r.tags, tagID)
+		}
+	}
+	delete(r.notifications, id)
+	delete(r.members, id)
+	delete(r.workspaces, id)
+	r.version++
+	return nil
+}
+
+// GetMember 获取用户在工作区中的成员身份
+func (r *InMemoryTodoRepository) GetMember(ctx context.Context, workspaceID, userID string) (*models.Member, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[workspaceID][userID]
+	if !ok {
+		return nil, ErrMemberNotFound
+	}
+	result := *member
+	return &result, nil
+}
+
+// ListMembers 获取工作区的所有成员，按加入时间排序
+func (r *InMemoryTodoRepository) ListMembers(ctx context.Context, workspaceID string) ([]models.Member, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	members := make([]models.Member, 0, len(r.members[workspaceID]))
+	for _, member := range r.members[workspaceID] {
+		members = append(members, *member)
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(members, func(a, b models.Member) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.UserID, b.UserID)
+	})
+	return members, nil
+}
+
+// AddMember 添加成员
+func (r *InMemoryTodoRepository) AddMember(ctx context.Context, member *models.Member) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.workspaces[member.WorkspaceID]; !ok {
+		return ErrWorkspaceNotFound
+	}
+	if _, exists := r.members[member.WorkspaceID][member.UserID]; exists {
+		return ErrMemberAlreadyExists
+	}
+
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now()
+	}
+	member.UpdatedAt = member.CreatedAt
+
+	stored := *member
+	r.members[stored.WorkspaceID][stored.UserID] = &stored
+	r.version++
+	return nil
+}
+
+// UpdateMember 修改成员的角色
+func (r *InMemoryTodoRepository) UpdateMember(ctx context.Context, member *models.Member) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.members[member.WorkspaceID][member.UserID]
+	if !ok {
+		return ErrMemberNotFound
+	}
+
+	existing.Role = member.Role
+	existing.UpdatedAt = time.Now()
+	r.version++
+
+	*member = *existing
+	return nil
+}
+
+// RemoveMember 移除成员
+func (r *InMemoryTodoRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[workspaceID][userID]; !ok {
+		return ErrMemberNotFound
+	}
+
+	delete(r.members[workspaceID], userID)
+	r.version++
+	return nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var _ AttachmentRepository = (*PostgresTodoRepository)(nil)
+
+// attachmentColumns 查询附件时返回的列，todo_id 为 NULL 时返回空字符串
+const attachmentColumns = `id::text, COALESCE(todo_id::text, ''), user_id::text, filename, content_type, size, storage_key, created_at`
+
+// ListAttachments 获取待办事项的所有附件，按上传时间排序
+func (r *PostgresTodoRepository) ListAttachments(ctx context.Context, userID, todoID string) ([]models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+attachmentColumns+` FROM attachments
+		WHERE todo_id = $1 AND user_id = $2
+		ORDER BY created_at, id`, todoID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取附件列表失败: %w", err)
+	}
+
+	attachments, err := pgx.CollectRows(rows, scanPostgresAttachment)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return []models.Attachment{}, nil
+		}
+		return nil, fmt.Errorf("获取附件列表失败: %w", err)
+	}
+	return attachments, nil
+}
+
+// GetAttachment 获取待办事项的一个附件
+func (r *PostgresTodoRepository) GetAttachment(ctx context.Context, userID, todoID, id string) (*models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+attachmentColumns+` FROM attachments
+		WHERE id = $1 AND todo_id = $2 AND user_id = $3`, id, todoID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取附件失败: %w", err)
+	}
+
+	attachment, err := pgx.CollectExactlyOneRow(rows, scanPostgresAttachment)
+	if err != nil {
+		return nil, mapPostgresAttachmentError("获取附件失败", err)
+	}
+	return &attachment, nil
+}
+
+// CreateAttachment 保存附件的元数据，只有待办事项属于该用户时才会插入
+func (r *PostgresTodoRepository) CreateAttachment(ctx context.Context, userID string, attachment *models.Attachment) error {
+	logger.WithContext(ctx, r.logger).Debug("创建附件",
+		zap.String("userID", userID),
+		zap.String("todoID", attachment.TodoID),
+		zap.String("filename", attachment.Filename))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := attachment.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	rows, err := r.pool.Query(ctx, `INSERT INTO attachments
+			(id, todo_id, user_id, filename, content_type, size, storage_key, created_at)
+		SELECT COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()), id, user_id, $4, $5, $6, $7, $8
+		FROM todos WHERE id = $2 AND user_id = $3
+		RETURNING `+attachmentColumns,
+		attachment.ID, attachment.TodoID, userID, attachment.Filename, attachment.ContentType,
+		attachment.Size, attachment.StorageKey, createdAt)
+	if err != nil {
+		return fmt.Errorf("创建附件失败: %w", err)
+	}
+
+	created, err := pgx.CollectExactlyOneRow(rows, scanPostgresAttachment)
+	if err != nil {
+		return mapPostgresTodoError("创建附件失败", err)
+	}
+
+	*attachment = created
+	return nil
+}
+
+// DeleteAttachment 把附件与待办事项脱离
+func (r *PostgresTodoRepository) DeleteAttachment(ctx context.Context, userID, todoID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除附件",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `UPDATE attachments SET todo_id = NULL
+		WHERE id = $1 AND todo_id = $2 AND user_id = $3`, id, todoID, userID)
+	if err != nil {
+		return mapPostgresAttachmentError("删除附件失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// ListDetachedAttachments 获取已经脱离待办事项的附件
+func (r *PostgresTodoRepository) ListDetachedAttachments(ctx context.Context, limit int) ([]models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+attachmentColumns+` FROM attachments
+		WHERE todo_id IS NULL
+		ORDER BY created_at, id
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("获取待清理的附件失败: %w", err)
+	}
+
+	attachments, err := pgx.CollectRows(rows, scanPostgresAttachment)
+	if err != nil {
+		return nil, fmt.Errorf("获取待清理的附件失败: %w", err)
+	}
+	return attachments, nil
+}
+
+// PurgeAttachment 删除已经脱离待办事项的附件的元数据
+func (r *PostgresTodoRepository) PurgeAttachment(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if _, err := r.pool.Exec(ctx, `DELETE FROM attachments WHERE id = $1 AND todo_id IS NULL`, id); err != nil {
+		if isPostgresInvalidInput(err) {
+			return nil
+		}
+		return fmt.Errorf("删除附件元数据失败: %w", err)
+	}
+	return nil
+}
+
+// scanPostgresAttachment 将一行查询结果扫描为 Attachment
+func scanPostgresAttachment(row pgx.CollectableRow) (models.Attachment, error) {
+	var attachment models.Attachment
+	err := row.Scan(&attachment.ID, &attachment.TodoID, &attachment.UserID, &attachment.Filename,
+		&attachment.ContentType, &attachment.Size, &attachment.StorageKey, &attachment.CreatedAt)
+	return attachment, err
+}
+
+// mapPostgresAttachmentError 将数据库错误转换为仓库层错误。
+// 未找到记录或 ID 不是合法的 UUID，都视为附件不存在
+func mapPostgresAttachmentError(operation string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) {
+		return ErrAttachmentNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
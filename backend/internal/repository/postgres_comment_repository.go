@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var _ CommentRepository = (*PostgresTodoRepository)(nil)
+
+// commentColumns 查询评论时返回的列
+const commentColumns = `id::text, todo_id::text, user_id::text, body, created_at, updated_at`
+
+// ListComments 按创建时间正序分页获取待办事项的评论
+func (r *PostgresTodoRepository) ListComments(ctx context.Context, todoID string, opts models.CommentListOptions) (*models.CommentPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE todo_id = $1`
+	args := []any{todoID}
+	if opts.After != nil {
+		t, err := opts.After.Time()
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (created_at, id) > ($2, $3::uuid)`
+		args = append(args, t, opts.After.ID)
+	}
+	query += fmt.Sprintf(` ORDER BY created_at, id LIMIT $%d`, len(args)+1)
+	args = append(args, opts.Limit+1)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取评论列表失败: %w", err)
+	}
+
+	comments, err := pgx.CollectRows(rows, scanPostgresComment)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return newCommentPage(nil, opts), nil
+		}
+		return nil, fmt.Errorf("获取评论列表失败: %w", err)
+	}
+	return newCommentPage(comments, opts), nil
+}
+
+// CommentCounts 批量统计待办事项的评论数
+func (r *PostgresTodoRepository) CommentCounts(ctx context.Context, todoIDs []string) (map[string]int, error) {
+	result := make(map[string]int)
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT todo_id::text, COUNT(*)
+		FROM comments
+		WHERE todo_id::text = ANY($1)
+		GROUP BY todo_id`, todoIDs)
+	if err != nil {
+		return nil, fmt.Errorf("统计评论数失败: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID string
+			count  int
+		)
+		if err := rows.Scan(&todoID, &count); err != nil {
+			return nil, fmt.Errorf("解析评论数失败: %w", err)
+		}
+		result[todoID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("统计评论数失败: %w", err)
+	}
+	return result, nil
+}
+
+// GetComment 获取待办事项的一条评论
+func (r *PostgresTodoRepository) GetComment(ctx context.Context, todoID, id string) (*models.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+commentColumns+` FROM comments
+		WHERE id = $1 AND todo_id = $2`, id, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("获取评论失败: %w", err)
+	}
+
+	comment, err := pgx.CollectExactlyOneRow(rows, scanPostgresComment)
+	if err != nil {
+		return nil, mapPostgresCommentError("获取评论失败", err)
+	}
+	return &comment, nil
+}
+
+// CreateComment 保存评论，只有待办事项存在时才会插入
+func (r *PostgresTodoRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	logger.WithContext(ctx, r.logger).Debug("创建评论",
+		zap.String("userID", comment.UserID),
+		zap.String("todoID", comment.TodoID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := comment.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	rows, err := r.pool.Query(ctx, `INSERT INTO comments (id, todo_id, user_id, body, created_at, updated_at)
+		SELECT COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()), id, $3, $4, $5, $5
+		FROM todos WHERE id = $2
+		RETURNING `+commentColumns,
+		comment.ID, comment.TodoID, comment.UserID, comment.Body, createdAt)
+	if err != nil {
+		return fmt.Errorf("创建评论失败: %w", err)
+	}
+
+	created, err := pgx.CollectExactlyOneRow(rows, scanPostgresComment)
+	if err != nil {
+		return mapPostgresTodoError("创建评论失败", err)
+	}
+
+	*comment = created
+	return nil
+}
+
+// UpdateComment 修改评论的内容
+func (r *PostgresTodoRepository) UpdateComment(ctx context.Context, comment *models.Comment) error {
+	logger.WithContext(ctx, r.logger).Debug("更新评论",
+		zap.String("userID", comment.UserID),
+		zap.String("todoID", comment.TodoID),
+		zap.String("id", comment.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `UPDATE comments SET body = $1, updated_at = NOW()
+		WHERE id = $2 AND todo_id = $3 AND user_id = $4
+		RETURNING `+commentColumns,
+		comment.Body, comment.ID, comment.TodoID, comment.UserID)
+	if err != nil {
+		return fmt.Errorf("更新评论失败: %w", err)
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, scanPostgresComment)
+	if err != nil {
+		return mapPostgresCommentError("更新评论失败", err)
+	}
+
+	*comment = updated
+	return nil
+}
+
+// DeleteComment 删除 userID 作为作者的一条评论
+func (r *PostgresTodoRepository) DeleteComment(ctx context.Context, userID, todoID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除评论",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM comments WHERE id = $1 AND todo_id = $2 AND user_id = $3`,
+		id, todoID, userID)
+	if err != nil {
+		return mapPostgresCommentError("删除评论失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// scanPostgresComment 将一行查询结果扫描为 Comment
+func scanPostgresComment(row pgx.CollectableRow) (models.Comment, error) {
+	var comment models.Comment
+	err := row.Scan(&comment.ID, &comment.TodoID, &comment.UserID, &comment.Body,
+		&comment.CreatedAt, &comment.UpdatedAt)
+	return comment, err
+}
+
+// mapPostgresCommentError 将数据库错误转换为仓库层错误。
+// 未找到记录或 ID 不是合法的 UUID，都视为评论不存在
+func mapPostgresCommentError(operation string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) {
+		return ErrCommentNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
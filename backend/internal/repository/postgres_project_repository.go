@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// projectColumns 查询项目时返回的列
+const projectColumns = `id::text, user_id::text, name, color, icon, archived, is_inbox, position, created_at, updated_at`
+
+// ListProjects 获取指定用户的所有项目，收件箱在最前，其余按位置排序
+func (r *PostgresTodoRepository) ListProjects(ctx context.Context, userID string) ([]models.Project, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取项目列表", zap.String("userID", userID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+projectColumns+` FROM projects WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取项目列表失败: %w", err)
+	}
+
+	projects, err := pgx.CollectRows(rows, scanPostgresProject)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return []models.Project{}, nil
+		}
+		return nil, fmt.Errorf("获取项目列表失败: %w", err)
+	}
+
+	models.SortProjects(projects)
+	return projects, nil
+}
+
+// GetProject 获取指定用户的单个项目
+func (r *PostgresTodoRepository) GetProject(ctx context.Context, userID, id string) (*models.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+projectColumns+` FROM projects WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取项目失败: %w", err)
+	}
+
+	project, err := pgx.CollectExactlyOneRow(rows, scanPostgresProject)
+	if err != nil {
+		return nil, mapPostgresProjectError("获取项目失败", err)
+	}
+	return &project, nil
+}
+
+// EnsureInbox 返回指定用户的收件箱，不存在时在一个事务中创建收件箱，
+// 并把还没有分配项目的待办事项移入收件箱。并发创建由 idx_projects_user_inbox 唯一索引保证只有一个成功
+func (r *PostgresTodoRepository) EnsureInbox(ctx context.Context, userID string) (*models.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	const selectInbox = `SELECT ` + projectColumns + ` FROM projects WHERE user_id = $1 AND is_inbox`
+
+	rows, err := r.pool.Query(ctx, selectInbox, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取收件箱失败: %w", err)
+	}
+	inbox, err := pgx.CollectExactlyOneRow(rows, scanPostgresProject)
+	if err == nil {
+		return &inbox, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, mapPostgresProjectError("获取收件箱失败", err)
+	}
+
+	logger.WithContext(ctx, r.logger).Info("创建收件箱", zap.String("userID", userID))
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err = tx.Query(ctx, `INSERT INTO projects (user_id, name, color, is_inbox)
+		VALUES ($1, $2, $3, TRUE)
+		ON CONFLICT (user_id) WHERE is_inbox DO NOTHING
+		RETURNING `+projectColumns,
+		userID, models.InboxProjectName, models.DefaultProjectColor)
+	if err != nil {
+		return nil, fmt.Errorf("创建收件箱失败: %w", err)
+	}
+	inbox, err = pgx.CollectExactlyOneRow(rows, scanPostgresProject)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// 其他请求已经创建了收件箱，也已经移动了待办事项
+		rows, err = tx.Query(ctx, selectInbox, userID)
+		if err != nil {
+			return nil, fmt.Errorf("获取收件箱失败: %w", err)
+		}
+		inbox, err = pgx.CollectExactlyOneRow(rows, scanPostgresProject)
+		if err != nil {
+			return nil, mapPostgresProjectError("获取收件箱失败", err)
+		}
+		return &inbox, nil
+	}
+	if err != nil {
+		return nil, mapPostgresProjectError("创建收件箱失败", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE todos SET project_id = $1
+		WHERE user_id = $2 AND project_id IS NULL`, inbox.ID, userID); err != nil {
+		return nil, fmt.Errorf("移动待办事项到收件箱失败: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+	return &inbox, nil
+}
+
+// CreateProject 创建项目
+func (r *PostgresTodoRepository) CreateProject(ctx context.Context, userID string, project *models.Project) error {
+	logger.WithContext(ctx, r.logger).Debug("创建项目",
+		zap.String("userID", userID),
+		zap.String("name", project.Name))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := project.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	rows, err := r.pool.Query(ctx, `INSERT INTO projects
+			(id, user_id, name, color, icon, archived, is_inbox, position, created_at, updated_at)
+		VALUES (COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()), $2, $3, $4, $5, $6, FALSE, $7, $8, $8)
+		RETURNING `+projectColumns,
+		project.ID, userID, project.Name, project.Color, project.Icon, project.Archived, project.Position, createdAt)
+	if err != nil {
+		return fmt.Errorf("创建项目失败: %w", err)
+	}
+
+	created, err := pgx.CollectExactlyOneRow(rows, scanPostgresProject)
+	if err != nil {
+		return fmt.Errorf("创建项目失败: %w", err)
+	}
+
+	*project = created
+	return nil
+}
+
+// UpdateProject 修改项目的名称、颜色、图标、归档状态和位置
+func (r *PostgresTodoRepository) UpdateProject(ctx context.Context, userID string, project *models.Project) error {
+	logger.WithContext(ctx, r.logger).Debug("更新项目",
+		zap.String("userID", userID),
+		zap.String("id", project.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `UPDATE projects
+		SET name = $3, color = $4, icon = $5, archived = $6, position = $7, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+projectColumns,
+		project.ID, userID, project.Name, project.Color, project.Icon, project.Archived, project.Position)
+	if err != nil {
+		return fmt.Errorf("更新项目失败: %w", err)
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, scanPostgresProject)
+	if err != nil {
+		return mapPostgresProjectError("更新项目失败", err)
+	}
+
+	*project = updated
+	return nil
+}
+
+// DeleteProject 在一个事务中移动项目中的待办事项并删除项目，
+// moveTo 为空时待办事项由外键级联删除
+func (r *PostgresTodoRepository) DeleteProject(ctx context.Context, userID, id, moveTo string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除项目",
+		zap.String("userID", userID),
+		zap.String("id", id),
+		zap.String("moveTo", moveTo))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if moveTo != "" {
+		// 锁定目标项目，防止它在移动期间被删除
+		var locked string
+		if err := tx.QueryRow(ctx, `SELECT id::text FROM projects WHERE id = $1 AND user_id = $2 FOR SHARE`,
+			moveTo, userID).Scan(&locked); err != nil {
+			return mapPostgresProjectError("删除项目失败", err)
+		}
+		if _, err := tx.Exec(ctx, `UPDATE todos SET project_id = $1
+			WHERE project_id = $2 AND user_id = $3`, moveTo, id, userID); err != nil {
+			return mapPostgresProjectError("移动项目中的待办事项失败", err)
+		}
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM projects WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return mapPostgresProjectError("删除项目失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrProjectNotFound
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
+// ProjectCounts 调用 010 迁移创建的 project_todo_counts 函数统计每个项目中待办事项的数量
+func (r *PostgresTodoRepository) ProjectCounts(ctx context.Context, userID string) (map[string]models.ProjectCounts, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT project_id::text, total, completed FROM project_todo_counts($1)`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("统计项目中的待办事项失败: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]models.ProjectCounts)
+	for rows.Next() {
+		var (
+			projectID        string
+			total, completed int64
+		)
+		if err := rows.Scan(&projectID, &total, &completed); err != nil {
+			return nil, fmt.Errorf("解析项目统计失败: %w", err)
+		}
+		counts[projectID] = models.ProjectCounts{Total: int(total), Completed: int(completed)}
+	}
+	if err := rows.Err(); err != nil {
+		if isPostgresInvalidInput(err) {
+			return counts, nil
+		}
+		return nil, fmt.Errorf("统计项目中的待办事项失败: %w", err)
+	}
+	return counts, nil
+}
+
+// MoveTodos 把指定用户的多个待办事项移动到 projectID，非法的 ID 视为不存在
+func (r *PostgresTodoRepository) MoveTodos(ctx context.Context, userID string, todoIDs []string, projectID string) (int, error) {
+	if len(todoIDs) == 0 {
+		return 0, nil
+	}
+
+	logger.WithContext(ctx, r.logger).Debug("移动待办事项到项目",
+		zap.String("userID", userID),
+		zap.Strings("todoIDs", todoIDs),
+		zap.String("projectID", projectID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// 按文本比较 ID，不是合法 UUID 的值只是匹配不到，不会让整条语句失败
+	tag, err := r.pool.Exec(ctx, `UPDATE todos SET project_id = $1::uuid
+		WHERE user_id = $2 AND id::text = ANY($3::text[])`, projectID, userID, todoIDs)
+	if err != nil {
+		return 0, mapPostgresProjectError("移动待办事项到项目失败", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// scanPostgresProject 将一行查询结果扫描为 Project
+func scanPostgresProject(row pgx.CollectableRow) (models.Project, error) {
+	var project models.Project
+	err := row.Scan(&project.ID, &project.UserID, &project.Name, &project.Color, &project.Icon,
+		&project.Archived, &project.Inbox, &project.Position, &project.CreatedAt, &project.UpdatedAt)
+	return project, err
+}
+
+// mapPostgresProjectError 将数据库错误转换为仓库层错误。
+// 未找到记录、ID 不是合法的 UUID 或引用项目时违反外键，都视为项目不存在
+func mapPostgresProjectError(operation string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) || isPostgresError(err, pgForeignKeyViolation) {
+		return ErrProjectNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
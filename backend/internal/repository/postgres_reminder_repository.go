@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// notificationColumns 查询站内通知时返回的列
+const notificationColumns = `id::text, user_id::text, COALESCE(todo_id::text, ''), kind, title, due_at, key, created_at, read_at`
+
+// ClaimDueReminders 调用 006 迁移创建的 claim_due_reminders 函数领取到期的提醒
+func (r *PostgresTodoRepository) ClaimDueReminders(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]models.Reminder, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// 工作区的提醒同时返回工作区 ID 和成员，个人的提醒两列都为空
+	rows, err := r.pool.Query(ctx, `SELECT todo_id::text, user_id::text, title, due_at, remind_at, attempts,
+		  COALESCE(workspace_id::text, ''), recipients::text[]
+		FROM claim_due_reminders($1, $2, $3, $4)`, owner, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("领取提醒失败: %w", err)
+	}
+
+	reminders, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Reminder, error) {
+		var reminder models.Reminder
+		err := row.Scan(
+			&reminder.TodoID,
+			&reminder.UserID,
+			&reminder.Title,
+			&reminder.DueAt,
+			&reminder.RemindAt,
+			&reminder.Attempts,
+			&reminder.WorkspaceID,
+			&reminder.Recipients,
+		)
+		return reminder, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("领取提醒失败: %w", err)
+	}
+	return reminders, nil
+}
+
+// CompleteReminder 将领取的提醒标记为已提醒并释放租约
+func (r *PostgresTodoRepository) CompleteReminder(ctx context.Context, owner string, reminder models.Reminder) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, `UPDATE todo_reminders
+		SET reminded_for = $3, lease_owner = NULL, lease_until = NULL, attempts = 0
+		WHERE todo_id = $1 AND lease_owner = $2`, reminder.TodoID, owner, reminder.RemindAt)
+	if err != nil {
+		return fmt.Errorf("标记提醒失败: %w", err)
+	}
+	return nil
+}
+
+// ReleaseReminder 释放领取的提醒，retryAt 之前不会再被领取
+func (r *PostgresTodoRepository) ReleaseReminder(ctx context.Context, owner string, reminder models.Reminder, retryAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.pool.Exec(ctx, `UPDATE todo_reminders
+		SET lease_owner = NULL, lease_until = $3, attempts = $4
+		WHERE todo_id = $1 AND lease_owner = $2`, reminder.TodoID, owner, retryAt, reminder.Attempts+1)
+	if err != nil {
+		return fmt.Errorf("释放提醒失败: %w", err)
+	}
+	return nil
+}
+
+// CreateNotification 保存一条站内通知，相同 Key 的通知只保存一次
+func (r *PostgresTodoRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	logger.WithContext(ctx, r.logger).Debug("创建站内通知",
+		zap.String("userID", notification.UserID),
+		zap.String("key", notification.Key))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := notification.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	// 冲突时做一次无实际修改的更新，使 RETURNING 总能返回已存在的行
+	rows, err := r.pool.Query(ctx, `INSERT INTO notifications (id, user_id, todo_id, kind, title, due_at, key, created_at)
+		VALUES (COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()), $2, NULLIF($3::text, '')::uuid, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, key) DO UPDATE SET key = EXCLUDED.key
+		RETURNING `+notificationColumns,
+		notification.ID, notification.UserID, notification.TodoID, notification.Kind,
+		notification.Title, notification.DueAt, notification.Key, createdAt)
+	if err != nil {
+		return fmt.Errorf("创建站内通知失败: %w", err)
+	}
+
+	stored, err := pgx.CollectExactlyOneRow(rows, scanPostgresNotification)
+	if err != nil {
+		return fmt.Errorf("创建站内通知失败: %w", err)
+	}
+
+	*notification = stored
+	return nil
+}
+
+// ListNotifications 按创建时间倒序分页获取指定用户的站内通知
+func (r *PostgresTodoRepository) ListNotifications(ctx context.Context, userID string, opts models.NotificationListOptions) (*models.NotificationPage, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取站内通知列表",
+		zap.String("userID", userID),
+		zap.Int("limit", opts.Limit))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var unread int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`,
+		userID).Scan(&unread); err != nil {
+		if isPostgresInvalidInput(err) {
+			return newNotificationPage(nil, 0, opts), nil
+		}
+		return nil, fmt.Errorf("统计未读通知失败: %w", err)
+	}
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = $1`
+	args := []any{userID}
+	if opts.UnreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	if opts.After != nil {
+		t, err := opts.After.Time()
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (created_at, id) < ($2, $3)`
+		args = append(args, t, opts.After.ID)
+	}
+	query += fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT $%d`, len(args)+1)
+	args = append(args, opts.Limit+1)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取站内通知列表失败: %w", err)
+	}
+
+	notifications, err := pgx.CollectRows(rows, scanPostgresNotification)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return newNotificationPage(nil, unread, opts), nil
+		}
+		return nil, fmt.Errorf("获取站内通知列表失败: %w", err)
+	}
+
+	return newNotificationPage(notifications, unread, opts), nil
+}
+
+// MarkNotificationRead 将通知标记为已读
+func (r *PostgresTodoRepository) MarkNotificationRead(ctx context.Context, userID, id string, at time.Time) (*models.Notification, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `UPDATE notifications SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+notificationColumns, id, userID, at)
+	if err != nil {
+		return nil, fmt.Errorf("标记通知已读失败: %w", err)
+	}
+
+	n, err := pgx.CollectExactlyOneRow(rows, scanPostgresNotification)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, fmt.Errorf("标记通知已读失败: %w", err)
+	}
+	return &n, nil
+}
+
+// MarkAllNotificationsRead 将指定用户的所有未读通知标记为已读
+func (r *PostgresTodoRepository) MarkAllNotificationsRead(ctx context.Context, userID string, at time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `UPDATE notifications SET read_at = $2
+		WHERE user_id = $1 AND read_at IS NULL`, userID, at)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("标记通知已读失败: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// scanPostgresNotification 将一行查询结果扫描为 Notification
+func scanPostgresNotification(row pgx.CollectableRow) (models.Notification, error) {
+	var n models.Notification
+	err := row.Scan(
+		&n.ID,
+		&n.UserID,
+		&n.TodoID,
+		&n.Kind,
+		&n.Title,
+		&n.DueAt,
+		&n.Key,
+		&n.CreatedAt,
+		&n.ReadAt,
+	)
+	return n, err
+}
@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var _ ShareRepository = (*PostgresTodoRepository)(nil)
+
+// 查询共享和邀请时返回的列，资源由 todo_id 和 project_id 中不为空的一列确定
+const (
+	shareColumns = `id::text, COALESCE(todo_id::text, ''), COALESCE(project_id::text, ''), owner_id::text,
+		user_id::text, role, created_by::text, created_at, updated_at`
+	invitationColumns = `id::text, COALESCE(todo_id::text, ''), COALESCE(project_id::text, ''), owner_id::text,
+		email, role, token_hash, invited_by::text, created_at, expires_at`
+)
+
+// TodoAccess 返回用户对待办事项的权限，同时查询待办事项本身和它所属项目的共享
+func (r *PostgresTodoRepository) TodoAccess(ctx context.Context, userID, todoID string) (*models.Access, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT t.user_id::text, COALESCE(s.role, '')
+		FROM todos t
+		LEFT JOIN shares s ON s.user_id = $1
+			AND (s.todo_id = t.id OR (t.project_id IS NOT NULL AND s.project_id = t.project_id))
+		WHERE t.id = $2`, userID, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("查询待办事项的权限失败: %w", err)
+	}
+	access, err := collectPostgresAccess(rows, userID)
+	if err != nil {
+		return nil, mapPostgresTodoError("查询待办事项的权限失败", err)
+	}
+	if access == nil {
+		return nil, ErrTodoNotFound
+	}
+	return access, nil
+}
+
+// ProjectAccess 返回用户对项目的权限
+func (r *PostgresTodoRepository) ProjectAccess(ctx context.Context, userID, projectID string) (*models.Access, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT p.user_id::text, COALESCE(s.role, '')
+		FROM projects p
+		LEFT JOIN shares s ON s.user_id = $1 AND s.project_id = p.id
+		WHERE p.id = $2`, userID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("查询项目的权限失败: %w", err)
+	}
+	access, err := collectPostgresAccess(rows, userID)
+	if err != nil {
+		return nil, mapPostgresProjectError("查询项目的权限失败", err)
+	}
+	if access == nil {
+		return nil, ErrProjectNotFound
+	}
+	return access, nil
+}
+
+// ListShares 获取资源的所有共享，按创建时间排序
+func (r *PostgresTodoRepository) ListShares(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Share, error) {
+	return r.listShares(ctx, shareResourceColumn(resourceType)+` = $1`, resourceID)
+}
+
+// ListSharedWith 获取共享给指定用户的所有资源，按创建时间排序
+func (r *PostgresTodoRepository) ListSharedWith(ctx context.Context, userID string) ([]models.Share, error) {
+	return r.listShares(ctx, `user_id = $1`, userID)
+}
+
+// GetShare 获取资源的一条共享
+func (r *PostgresTodoRepository) GetShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) (*models.Share, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+shareColumns+` FROM shares
+		WHERE id = $1 AND `+shareResourceColumn(resourceType)+` = $2`, id, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("获取共享失败: %w", err)
+	}
+
+	share, err := pgx.CollectExactlyOneRow(rows, scanPostgresShare)
+	if err != nil {
+		return nil, mapPostgresShareError("获取共享失败", err)
+	}
+	return &share, nil
+}
+
+// DeleteShare 撤销资源的一条共享
+func (r *PostgresTodoRepository) DeleteShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("撤销共享",
+		zap.String("resourceType", string(resourceType)),
+		zap.String("resourceID", resourceID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM shares
+		WHERE id = $1 AND `+shareResourceColumn(resourceType)+` = $2`, id, resourceID)
+	if err != nil {
+		return mapPostgresShareError("撤销共享失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// CreateInvitation 保存邀请，只有资源存在时才会插入
+func (r *PostgresTodoRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	logger.WithContext(ctx, r.logger).Debug("创建邀请",
+		zap.String("resourceType", string(invitation.ResourceType)),
+		zap.String("resourceID", invitation.ResourceID),
+		zap.String("invitedBy", invitation.InvitedBy))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := invitation.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	rows, err := r.pool.Query(ctx, `INSERT INTO invitations
+			(id, `+shareResourceColumn(invitation.ResourceType)+`, owner_id, email, role, token_hash, invited_by, created_at, expires_at)
+		SELECT COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()), id, $3, $4, $5, $6, $7, $8, $9
+		FROM `+shareResourceTable(invitation.ResourceType)+` WHERE id = $2
+		RETURNING `+invitationColumns,
+		invitation.ID, invitation.ResourceID, invitation.OwnerID, invitation.Email, string(invitation.Role),
+		invitation.TokenHash, invitation.InvitedBy, createdAt, invitation.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("创建邀请失败: %w", err)
+	}
+
+	created, err := pgx.CollectExactlyOneRow(rows, scanPostgresInvitation)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) {
+			return shareResourceNotFound(invitation.ResourceType)
+		}
+		return fmt.Errorf("创建邀请失败: %w", err)
+	}
+
+	*invitation = created
+	return nil
+}
+
+// ListInvitations 获取资源的所有邀请，按创建时间排序
+func (r *PostgresTodoRepository) ListInvitations(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+invitationColumns+` FROM invitations
+		WHERE `+shareResourceColumn(resourceType)+` = $1
+		ORDER BY created_at, id`, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("获取邀请列表失败: %w", err)
+	}
+
+	invitations, err := pgx.CollectRows(rows, scanPostgresInvitation)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return []models.Invitation{}, nil
+		}
+		return nil, fmt.Errorf("获取邀请列表失败: %w", err)
+	}
+	return invitations, nil
+}
+
+// GetInvitationByToken 按令牌的 SHA-256 获取邀请
+func (r *PostgresTodoRepository) GetInvitationByToken(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+invitationColumns+` FROM invitations
+		WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return nil, fmt.Errorf("获取邀请失败: %w", err)
+	}
+
+	invitation, err := pgx.CollectExactlyOneRow(rows, scanPostgresInvitation)
+	if err != nil {
+		return nil, mapPostgresInvitationError("获取邀请失败", err)
+	}
+	return &invitation, nil
+}
+
+// DeleteInvitation 撤销资源的一条邀请
+func (r *PostgresTodoRepository) DeleteInvitation(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("撤销邀请",
+		zap.String("resourceType", string(resourceType)),
+		zap.String("resourceID", resourceID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM invitations
+		WHERE id = $1 AND `+shareResourceColumn(resourceType)+` = $2`, id, resourceID)
+	if err != nil {
+		return mapPostgresInvitationError("撤销邀请失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation 在同一个事务中删除邀请并把资源共享给 userID。
+// 已有共享时只在邀请的角色更高时升级，不会降低已有的权限
+func (r *PostgresTodoRepository) AcceptInvitation(ctx context.Context, invitation *models.Invitation, userID string) (*models.Share, error) {
+	logger.WithContext(ctx, r.logger).Debug("接受邀请",
+		zap.String("userID", userID),
+		zap.String("invitationID", invitation.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM invitations WHERE id = $1`, invitation.ID)
+	if err != nil {
+		return nil, mapPostgresInvitationError("删除邀请失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrInvitationNotFound
+	}
+
+	column := shareResourceColumn(invitation.ResourceType)
+	if _, err := tx.Exec(ctx, `INSERT INTO shares (`+column+`, owner_id, user_id, role, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (`+column+`, user_id) WHERE `+column+` IS NOT NULL DO NOTHING`,
+		invitation.ResourceID, invitation.OwnerID, userID, string(invitation.Role), invitation.InvitedBy); err != nil {
+		return nil, mapPostgresShareError("创建共享失败", err)
+	}
+
+	if below := shareRolesBelow(invitation.Role); len(below) > 0 {
+		if _, err := tx.Exec(ctx, `UPDATE shares SET role = $1
+			WHERE `+column+` = $2 AND user_id = $3 AND role = ANY($4)`,
+			string(invitation.Role), invitation.ResourceID, userID, below); err != nil {
+			return nil, fmt.Errorf("更新共享的角色失败: %w", err)
+		}
+	}
+
+	rows, err := tx.Query(ctx, `SELECT `+shareColumns+` FROM shares
+		WHERE `+column+` = $1 AND user_id = $2`, invitation.ResourceID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取共享失败: %w", err)
+	}
+	share, err := pgx.CollectExactlyOneRow(rows, scanPostgresShare)
+	if err != nil {
+		return nil, fmt.Errorf("获取共享失败: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("提交事务失败: %w", err)
+	}
+	return &share, nil
+}
+
+// listShares 按条件获取共享，按创建时间排序
+func (r *PostgresTodoRepository) listShares(ctx context.Context, where string, args ...any) ([]models.Share, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+shareColumns+` FROM shares
+		WHERE `+where+`
+		ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取共享列表失败: %w", err)
+	}
+
+	shares, err := pgx.CollectRows(rows, scanPostgresShare)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return []models.Share{}, nil
+		}
+		return nil, fmt.Errorf("获取共享列表失败: %w", err)
+	}
+	return shares, nil
+}
+
+// collectPostgresAccess 读取资源的所有者和共享角色，资源不存在或没有共享给用户时返回 nil
+func collectPostgresAccess(rows pgx.Rows, userID string) (*models.Access, error) {
+	type accessRow struct {
+		ownerID string
+		role    string
+	}
+	result, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (accessRow, error) {
+		var a accessRow
+		err := row.Scan(&a.ownerID, &a.role)
+		return a, err
+	})
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+
+	var roles []models.ShareRole
+	for _, a := range result {
+		if a.role != "" {
+			roles = append(roles, models.ShareRole(a.role))
+		}
+	}
+	access, _ := newAccess(userID, result[0].ownerID, roles)
+	return access, nil
+}
+
+// scanPostgresShare 将一行查询结果扫描为 Share
+func scanPostgresShare(row pgx.CollectableRow) (models.Share, error) {
+	var (
+		share             models.Share
+		todoID, projectID string
+		role              string
+	)
+	err := row.Scan(&share.ID, &todoID, &projectID, &share.OwnerID, &share.UserID, &role,
+		&share.CreatedBy, &share.CreatedAt, &share.UpdatedAt)
+	share.ResourceType, share.ResourceID = shareResource(todoID, projectID)
+	share.Role = models.ShareRole(role)
+	return share, err
+}
+
+// scanPostgresInvitation 将一行查询结果扫描为 Invitation
+func scanPostgresInvitation(row pgx.CollectableRow) (models.Invitation, error) {
+	var (
+		invitation        models.Invitation
+		todoID, projectID string
+		role              string
+	)
+	err := row.Scan(&invitation.ID, &todoID, &projectID, &invitation.OwnerID, &invitation.Email, &role,
+		&invitation.TokenHash, &invitation.InvitedBy, &invitation.CreatedAt, &invitation.ExpiresAt)
+	invitation.ResourceType, invitation.ResourceID = shareResource(todoID, projectID)
+	invitation.Role = models.ShareRole(role)
+	return invitation, err
+}
+
+// mapPostgresShareError 将数据库错误转换为仓库层错误。
+// 未找到记录或 ID 不是合法的 UUID，都视为共享不存在
+func mapPostgresShareError(operation string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) {
+		return ErrShareNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
+// mapPostgresInvitationError 将数据库错误转换为仓库层错误。
+// 未找到记录或 ID 不是合法的 UUID，都视为邀请不存在
+func mapPostgresInvitationError(operation string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) {
+		return ErrInvitationNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
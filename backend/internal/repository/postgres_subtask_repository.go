@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var _ SubtaskRepository = (*PostgresTodoRepository)(nil)
+
+// subtaskColumns 查询子任务时返回的列
+const subtaskColumns = `id::text, todo_id::text, user_id::text, title, completed, position, created_at, updated_at`
+
+// ListSubtasks 获取待办事项的所有子任务，按位置排序
+func (r *PostgresTodoRepository) ListSubtasks(ctx context.Context, userID, todoID string) ([]models.Subtask, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+subtaskColumns+` FROM subtasks
+		WHERE todo_id = $1 AND user_id = $2
+		ORDER BY position, id`, todoID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取子任务列表失败: %w", err)
+	}
+
+	subtasks, err := pgx.CollectRows(rows, scanPostgresSubtask)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return []models.Subtask{}, nil
+		}
+		return nil, fmt.Errorf("获取子任务列表失败: %w", err)
+	}
+	return subtasks, nil
+}
+
+// SubtaskProgress 批量统计待办事项的子任务进度
+func (r *PostgresTodoRepository) SubtaskProgress(ctx context.Context, userID string, todoIDs []string) (map[string]models.SubtaskProgress, error) {
+	result := make(map[string]models.SubtaskProgress)
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT todo_id::text, COUNT(*) FILTER (WHERE completed), COUNT(*)
+		FROM subtasks
+		WHERE user_id = $1 AND todo_id = ANY($2::uuid[])
+		GROUP BY todo_id`, userID, todoIDs)
+	if err != nil {
+		return nil, fmt.Errorf("统计子任务进度失败: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID   string
+			progress models.SubtaskProgress
+		)
+		if err := rows.Scan(&todoID, &progress.Completed, &progress.Total); err != nil {
+			return nil, fmt.Errorf("解析子任务进度失败: %w", err)
+		}
+		result[todoID] = progress
+	}
+	if err := rows.Err(); err != nil {
+		if isPostgresInvalidInput(err) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("统计子任务进度失败: %w", err)
+	}
+	return result, nil
+}
+
+// CreateSubtask 创建子任务，只有待办事项属于该用户时才会插入
+func (r *PostgresTodoRepository) CreateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error {
+	logger.WithContext(ctx, r.logger).Debug("创建子任务",
+		zap.String("userID", userID),
+		zap.String("todoID", subtask.TodoID),
+		zap.String("title", subtask.Title))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := subtask.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	rows, err := r.pool.Query(ctx, `INSERT INTO subtasks (id, todo_id, user_id, title, completed, position, created_at, updated_at)
+		SELECT COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()), id, user_id, $4, $5, $6, $7, $7
+		FROM todos WHERE id = $2 AND user_id = $3
+		RETURNING `+subtaskColumns,
+		subtask.ID, subtask.TodoID, userID, subtask.Title, subtask.Completed, subtask.Position, createdAt)
+	if err != nil {
+		return fmt.Errorf("创建子任务失败: %w", err)
+	}
+
+	created, err := pgx.CollectExactlyOneRow(rows, scanPostgresSubtask)
+	if err != nil {
+		return mapPostgresTodoError("创建子任务失败", err)
+	}
+
+	*subtask = created
+	return nil
+}
+
+// UpdateSubtask 修改子任务的标题、完成状态和位置
+func (r *PostgresTodoRepository) UpdateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error {
+	logger.WithContext(ctx, r.logger).Debug("更新子任务",
+		zap.String("userID", userID),
+		zap.String("todoID", subtask.TodoID),
+		zap.String("id", subtask.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `UPDATE subtasks SET title = $4, completed = $5, position = $6, updated_at = NOW()
+		WHERE id = $1 AND todo_id = $2 AND user_id = $3
+		RETURNING `+subtaskColumns,
+		subtask.ID, subtask.TodoID, userID, subtask.Title, subtask.Completed, subtask.Position)
+	if err != nil {
+		return fmt.Errorf("更新子任务失败: %w", err)
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, scanPostgresSubtask)
+	if err != nil {
+		return mapPostgresSubtaskError("更新子任务失败", err)
+	}
+
+	*subtask = updated
+	return nil
+}
+
+// DeleteSubtask 删除待办事项的一个子任务
+func (r *PostgresTodoRepository) DeleteSubtask(ctx context.Context, userID, todoID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除子任务",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM subtasks WHERE id = $1 AND todo_id = $2 AND user_id = $3`,
+		id, todoID, userID)
+	if err != nil {
+		return mapPostgresSubtaskError("删除子任务失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSubtaskNotFound
+	}
+	return nil
+}
+
+// scanPostgresSubtask 将一行查询结果扫描为 Subtask
+func scanPostgresSubtask(row pgx.CollectableRow) (models.Subtask, error) {
+	var subtask models.Subtask
+	err := row.Scan(&subtask.ID, &subtask.TodoID, &subtask.UserID, &subtask.Title, &subtask.Completed,
+		&subtask.Position, &subtask.CreatedAt, &subtask.UpdatedAt)
+	return subtask, err
+}
+
+// mapPostgresSubtaskError 将数据库错误转换为仓库层错误。
+// 未找到记录或 ID 不是合法的 UUID，都视为子任务不存在
+func mapPostgresSubtaskError(operation string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) {
+		return ErrSubtaskNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// tagColumns 查询标签时返回的列
+const tagColumns = `id::text, user_id::text, name, color, created_at, updated_at`
+
+// ListTags 获取指定用户的所有标签，按名称排序
+func (r *PostgresTodoRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取标签列表", zap.String("userID", userID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+tagColumns+` FROM tags WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+
+	tags, err := pgx.CollectRows(rows, scanPostgresTag)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return []models.Tag{}, nil
+		}
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+
+	models.SortTags(tags)
+	return tags, nil
+}
+
+// GetTag 获取指定用户的单个标签
+func (r *PostgresTodoRepository) GetTag(ctx context.Context, userID, id string) (*models.Tag, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+tagColumns+` FROM tags WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签失败: %w", err)
+	}
+
+	tag, err := pgx.CollectExactlyOneRow(rows, scanPostgresTag)
+	if err != nil {
+		return nil, mapPostgresTagError("获取标签失败", err)
+	}
+	return &tag, nil
+}
+
+// CreateTag 创建标签，同名标签由 idx_tags_user_name 唯一索引拒绝
+func (r *PostgresTodoRepository) CreateTag(ctx context.Context, userID string, tag *models.Tag) error {
+	logger.WithContext(ctx, r.logger).Debug("创建标签",
+		zap.String("userID", userID),
+		zap.String("name", tag.Name))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := tag.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	rows, err := r.pool.Query(ctx, `INSERT INTO tags (id, user_id, name, color, created_at, updated_at)
+		VALUES (COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()), $2, $3, $4, $5, $5)
+		RETURNING `+tagColumns,
+		tag.ID, userID, tag.Name, tag.Color, createdAt)
+	if err != nil {
+		return fmt.Errorf("创建标签失败: %w", err)
+	}
+
+	created, err := pgx.CollectExactlyOneRow(rows, scanPostgresTag)
+	if err != nil {
+		return mapPostgresTagError("创建标签失败", err)
+	}
+
+	*tag = created
+	return nil
+}
+
+// UpdateTag 修改标签的名称和颜色
+func (r *PostgresTodoRepository) UpdateTag(ctx context.Context, userID string, tag *models.Tag) error {
+	logger.WithContext(ctx, r.logger).Debug("更新标签",
+		zap.String("userID", userID),
+		zap.String("id", tag.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `UPDATE tags SET name = $3, color = $4, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING `+tagColumns,
+		tag.ID, userID, tag.Name, tag.Color)
+	if err != nil {
+		return fmt.Errorf("更新标签失败: %w", err)
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, scanPostgresTag)
+	if err != nil {
+		return mapPostgresTagError("更新标签失败", err)
+	}
+
+	*tag = updated
+	return nil
+}
+
+// DeleteTag 删除标签，关联由外键级联删除
+func (r *PostgresTodoRepository) DeleteTag(ctx context.Context, userID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除标签",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM tags WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return mapPostgresTagError("删除标签失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// TodoTags 批量获取待办事项的标签
+func (r *PostgresTodoRepository) TodoTags(ctx context.Context, userID string, todoIDs []string) (map[string][]models.Tag, error) {
+	result := make(map[string][]models.Tag)
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT tt.todo_id::text, t.id::text, t.user_id::text, t.name, t.color, t.created_at, t.updated_at
+		FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.user_id = $1 AND tt.todo_id = ANY($2::uuid[])`, userID, todoIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取待办事项的标签失败: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID string
+			tag    models.Tag
+		)
+		if err := rows.Scan(&todoID, &tag.ID, &tag.UserID, &tag.Name, &tag.Color, &tag.CreatedAt, &tag.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("解析标签失败: %w", err)
+		}
+		result[todoID] = append(result[todoID], tag)
+	}
+	if err := rows.Err(); err != nil {
+		if isPostgresInvalidInput(err) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("获取待办事项的标签失败: %w", err)
+	}
+
+	for _, tags := range result {
+		models.SortTags(tags)
+	}
+	return result, nil
+}
+
+// SetTodoTags 在一个事务中删除待办事项原有的关联并添加新的关联
+func (r *PostgresTodoRepository) SetTodoTags(ctx context.Context, userID, todoID string, tagIDs []string) error {
+	logger.WithContext(ctx, r.logger).Debug("设置待办事项的标签",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.Strings("tagIDs", tagIDs))
+
+	return r.updateTodoTags(ctx, userID, todoID, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM todo_tags WHERE todo_id = $1`, todoID); err != nil {
+			return err
+		}
+		return insertPostgresTodoTags(ctx, tx, userID, todoID, tagIDs)
+	})
+}
+
+// AddTodoTag 给待办事项添加一个标签
+func (r *PostgresTodoRepository) AddTodoTag(ctx context.Context, userID, todoID, tagID string) error {
+	return r.updateTodoTags(ctx, userID, todoID, func(tx pgx.Tx) error {
+		return insertPostgresTodoTags(ctx, tx, userID, todoID, []string{tagID})
+	})
+}
+
+// RemoveTodoTag 去掉待办事项的一个标签
+func (r *PostgresTodoRepository) RemoveTodoTag(ctx context.Context, userID, todoID, tagID string) error {
+	return r.updateTodoTags(ctx, userID, todoID, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `DELETE FROM todo_tags WHERE todo_id = $1 AND tag_id = $2`, todoID, tagID)
+		return err
+	})
+}
+
+// updateTodoTags 在事务中锁定待办事项后修改它的关联，待办事项不存在时返回 ErrTodoNotFound
+func (r *PostgresTodoRepository) updateTodoTags(ctx context.Context, userID, todoID string, update func(tx pgx.Tx) error) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var locked string
+	if err := tx.QueryRow(ctx, `SELECT id::text FROM todos WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		todoID, userID).Scan(&locked); err != nil {
+		return mapPostgresTodoError("修改待办事项的标签失败", err)
+	}
+
+	if err := update(tx); err != nil {
+		return mapPostgresTagError("修改待办事项的标签失败", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
+// insertPostgresTodoTags 添加关联，已存在的关联保持不变
+func insertPostgresTodoTags(ctx context.Context, tx pgx.Tx, userID, todoID string, tagIDs []string) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	_, err := tx.Exec(ctx, `INSERT INTO todo_tags (todo_id, tag_id, user_id)
+		SELECT $1, tag_id, $3 FROM unnest($2::uuid[]) AS tag_id
+		ON CONFLICT (todo_id, tag_id) DO NOTHING`, todoID, tagIDs, userID)
+	return err
+}
+
+// scanPostgresTag 将一行查询结果扫描为 Tag
+func scanPostgresTag(row pgx.CollectableRow) (models.Tag, error) {
+	var tag models.Tag
+	err := row.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Color, &tag.CreatedAt, &tag.UpdatedAt)
+	return tag, err
+}
+
+// mapPostgresTagError 将数据库错误转换为仓库层错误。
+// 未找到记录、ID 不是合法的 UUID 或关联时违反外键，都视为标签不存在
+func mapPostgresTagError(operation string, err error) error {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows), isPostgresInvalidInput(err), isPostgresError(err, pgForeignKeyViolation):
+		return ErrTagNotFound
+	case isPostgresError(err, pgUniqueViolation):
+		return ErrTagAlreadyExists
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// PostgreSQL 错误码
+const (
+	// pgInvalidTextRepresentation 非法输入格式（例如非法 UUID）
+	pgInvalidTextRepresentation = "22P02"
+	// pgUniqueViolation 违反唯一约束
+	pgUniqueViolation = "23505"
+	// pgForeignKeyViolation 违反外键约束
+	pgForeignKeyViolation = "23503"
+)
+
+// todoColumns 查询待办事项时返回的列
+const todoColumns = `id::text, user_id::text, title, completed, due_at, remind_at,
+	series_id::text, recurrence_rule, recurrence_timezone, recurrence_start, priority, position,
+	project_id::text, auto_complete, notes, created_at, updated_at`
+
+// postgresTodoStatements 预编译语句，在每个连接建立时准备
+var postgresTodoStatements = map[string]string{
+	"todo_get": `SELECT ` + todoColumns + ` FROM todos
+		WHERE id = $1 AND user_id = $2`,
+	"todo_create": `INSERT INTO todos (id, user_id, title, completed, due_at, remind_at,
+			series_id, recurrence_rule, recurrence_timezone, recurrence_start, priority, position,
+			project_id, auto_complete, notes, created_at, updated_at)
+		VALUES (COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()), $2, $3, $4, $6, $7,
+			$8::uuid, $9, $10, $11, $12, $13, $14::uuid, $15, $16, $5, $5)
+		RETURNING ` + todoColumns,
+	"todo_update": `UPDATE todos SET title = $3, completed = $4, due_at = $5, remind_at = $6,
+			series_id = $7::uuid, recurrence_rule = $8, recurrence_timezone = $9, recurrence_start = $10,
+			priority = $11, project_id = $12::uuid, auto_complete = $13, notes = $14, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + todoColumns,
+	"todo_toggle": `UPDATE todos SET completed = NOT completed, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + todoColumns,
+	"todo_move": `UPDATE todos SET position = $3, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + todoColumns,
+	"todo_delete": `DELETE FROM todos WHERE id = $1 AND user_id = $2`,
+}
+
+// PostgresTodoRepository 是一个直连 PostgreSQL 实现的 TodoRepository
+type PostgresTodoRepository struct {
+	pool    *pgxpool.Pool
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewPostgresTodoRepository 创建一个新的 PostgresTodoRepository
+func NewPostgresTodoRepository(cfg *config.Config) (*PostgresTodoRepository, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.Database.PostgresDSN())
+	if err != nil {
+		return nil, fmt.Errorf("解析数据库连接配置失败: %w", err)
+	}
+
+	if cfg.Database.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.Database.MaxConns
+	}
+	if cfg.Database.MinConns > 0 {
+		poolConfig.MinConns = cfg.Database.MinConns
+	}
+	if cfg.Database.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.Database.MaxConnLifetime
+	}
+	if cfg.Database.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.Database.MaxConnIdleTime
+	}
+
+	// 每个新连接建立后准备好所有语句
+	poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		for name, sql := range postgresTodoStatements {
+			if _, err := conn.Prepare(ctx, name, sql); err != nil {
+				return fmt.Errorf("准备语句 %s 失败: %w", name, err)
+			}
+		}
+		return nil
+	}
+
+	timeout := operationTimeout(cfg.Database.OperationTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("创建数据库连接池失败: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+
+	logger.Info("初始化 PostgreSQL Todo Repository",
+		zap.String("host", poolConfig.ConnConfig.Host),
+		zap.Uint16("port", poolConfig.ConnConfig.Port),
+		zap.String("database", poolConfig.ConnConfig.Database),
+		zap.Int32("maxConns", poolConfig.MaxConns))
+
+	return &PostgresTodoRepository{
+		pool:    pool,
+		timeout: timeout,
+		logger:  logger.Log.With(zap.String("component", "PostgresTodoRepository")),
+	}, nil
+}
+
+// Close 关闭数据库连接池
+func (r *PostgresTodoRepository) Close() error {
+	r.pool.Close()
+	return nil
+}
+
+// List 按过滤条件、排序和游标分页获取指定用户的待办事项
+func (r *PostgresTodoRepository) List(ctx context.Context, userID string, opts models.TodoListOptions) (*models.TodoPage, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取待办事项列表",
+		zap.String("userID", userID),
+		zap.String("sortBy", string(opts.SortBy)),
+		zap.String("sortDir", string(opts.SortDir)),
+		zap.Int("limit", opts.Limit))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := newTodoListQuery(postgresDialect, userID, opts.Filter)
+
+	var total int
+	if err := r.pool.QueryRow(ctx, query.countSQL(), query.args...).Scan(&total); err != nil {
+		if isPostgresInvalidInput(err) {
+			return newTodoPage(nil, 0, opts), nil
+		}
+		return nil, fmt.Errorf("统计待办事项失败: %w", err)
+	}
+
+	sql, args, err := query.pageSQL(todoColumns, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取待办事项列表失败: %w", err)
+	}
+
+	todos, err := pgx.CollectRows(rows, scanPostgresTodo)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return newTodoPage(nil, 0, opts), nil
+		}
+		return nil, fmt.Errorf("获取待办事项列表失败: %w", err)
+	}
+
+	return newTodoPage(todos, total, opts), nil
+}
+
+// Search 调用 004 迁移创建的 search_todos 函数按相关度搜索指定用户的待办事项
+func (r *PostgresTodoRepository) Search(ctx context.Context, userID string, opts models.TodoSearchOptions) (*models.TodoSearchPage, error) {
+	logger.WithContext(ctx, r.logger).Debug("搜索待办事项",
+		zap.String("userID", userID),
+		zap.String("query", opts.Query),
+		zap.Int("limit", opts.Limit))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var (
+		afterScore *float32
+		afterID    *string
+	)
+	if opts.After != nil {
+		score, err := opts.After.Score()
+		if err != nil {
+			return nil, err
+		}
+		s := float32(score)
+		afterScore, afterID = &s, &opts.After.ID
+	}
+
+	// search_todos 返回 todos 表的整行，展开后按 todoColumns 读取
+	rows, err := r.pool.Query(ctx, `SELECT `+todoColumns+`, score, total
+		FROM (SELECT (s.todo).*, s.score, s.total FROM search_todos($1, $2, $3, $4, $5, $6) s) t`,
+		userID, opts.Query, opts.Completed, opts.Limit+1, afterScore, afterID)
+	if err != nil {
+		return nil, fmt.Errorf("搜索待办事项失败: %w", err)
+	}
+
+	// 总数随每一行返回，游标之后没有结果时无法得知，此时为 0
+	var total int64
+	hits, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.TodoSearchHit, error) {
+		var (
+			hit   models.TodoSearchHit
+			score float32
+		)
+		scan := postgresTodoScan(&hit.Todo)
+		err := row.Scan(append(scan.dest, &score, &total)...)
+		scan.finish()
+		hit.Score = float64(score)
+		return hit, err
+	})
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return newTodoSearchPage(nil, 0, opts), nil
+		}
+		return nil, fmt.Errorf("搜索待办事项失败: %w", err)
+	}
+
+	return newTodoSearchPage(hits, int(total), opts), nil
+}
+
+// Get 获取指定用户的单个待办事项
+func (r *PostgresTodoRepository) Get(ctx context.Context, userID, id string) (*models.Todo, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取待办事项",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, "todo_get", id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取待办事项失败: %w", err)
+	}
+
+	todo, err := pgx.CollectExactlyOneRow(rows, scanPostgresTodo)
+	if err != nil {
+		return nil, mapPostgresTodoError("获取待办事项失败", err)
+	}
+
+	return &todo, nil
+}
+
+// Create 创建一个新的待办事项
+func (r *PostgresTodoRepository) Create(ctx context.Context, userID string, todo *models.Todo) error {
+	logger.WithContext(ctx, r.logger).Debug("创建待办事项",
+		zap.String("userID", userID),
+		zap.String("title", todo.Title))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := todo.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	seriesID, rule, timezone, start := recurrenceArgs(todo)
+	rows, err := r.pool.Query(ctx, "todo_create", todo.ID, userID, todo.Title, todo.Completed, createdAt,
+		todo.DueAt, todo.RemindAt, seriesID, rule, timezone, start, int16(todo.Priority), todo.Position, projectArg(todo),
+		todo.AutoComplete, todo.Notes)
+	if err != nil {
+		return fmt.Errorf("创建待办事项失败: %w", err)
+	}
+
+	created, err := pgx.CollectExactlyOneRow(rows, scanPostgresTodo)
+	if err != nil {
+		if isPostgresError(err, pgUniqueViolation) {
+			return ErrTodoAlreadyExists
+		}
+		if isPostgresError(err, pgForeignKeyViolation) {
+			return ErrProjectNotFound
+		}
+		return fmt.Errorf("创建待办事项失败: %w", err)
+	}
+
+	*todo = created
+	return nil
+}
+
+// Update 更新待办事项
+func (r *PostgresTodoRepository) Update(ctx context.Context, userID string, todo *models.Todo) error {
+	logger.WithContext(ctx, r.logger).Debug("更新待办事项",
+		zap.String("userID", userID),
+		zap.String("id", todo.ID),
+		zap.String("title", todo.Title))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	seriesID, rule, timezone, start := recurrenceArgs(todo)
+	rows, err := r.pool.Query(ctx, "todo_update", todo.ID, userID, todo.Title, todo.Completed,
+		todo.DueAt, todo.RemindAt, seriesID, rule, timezone, start, int16(todo.Priority), projectArg(todo), todo.AutoComplete,
+		todo.Notes)
+	if err != nil {
+		return fmt.Errorf("更新待办事项失败: %w", err)
+	}
+
+	updated, err := pgx.CollectExactlyOneRow(rows, scanPostgresTodo)
+	if err != nil {
+		if isPostgresError(err, pgForeignKeyViolation) {
+			return ErrProjectNotFound
+		}
+		return mapPostgresTodoError("更新待办事项失败", err)
+	}
+
+	*todo = updated
+	return nil
+}
+
+// Move 修改待办事项的位置
+func (r *PostgresTodoRepository) Move(ctx context.Context, userID, id, position string) (*models.Todo, error) {
+	logger.WithContext(ctx, r.logger).Debug("移动待办事项",
+		zap.String("userID", userID),
+		zap.String("id", id),
+		zap.String("position", position))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, "todo_move", id, userID, position)
+	if err != nil {
+		return nil, fmt.Errorf("移动待办事项失败: %w", err)
+	}
+
+	moved, err := pgx.CollectExactlyOneRow(rows, scanPostgresTodo)
+	if err != nil {
+		return nil, mapPostgresTodoError("移动待办事项失败", err)
+	}
+	return &moved, nil
+}
+
+// Toggle 切换待办事项的完成状态
+func (r *PostgresTodoRepository) Toggle(ctx context.Context, userID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("切换待办事项状态",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, "todo_toggle", id, userID)
+	if err != nil {
+		return fmt.Errorf("切换待办事项状态失败: %w", err)
+	}
+
+	if _, err := pgx.CollectExactlyOneRow(rows, scanPostgresTodo); err != nil {
+		return mapPostgresTodoError("切换待办事项状态失败", err)
+	}
+
+	return nil
+}
+
+// Delete 删除待办事项
+func (r *PostgresTodoRepository) Delete(ctx context.Context, userID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除待办事项",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, "todo_delete", id, userID)
+	if err != nil {
+		return mapPostgresTodoError("删除待办事项失败", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrTodoNotFound
+	}
+
+	return nil
+}
+
+// scanPostgresTodo 将一行查询结果扫描为 Todo
+func scanPostgresTodo(row pgx.CollectableRow) (models.Todo, error) {
+	var todo models.Todo
+	scan := postgresTodoScan(&todo)
+	err := row.Scan(scan.dest...)
+	scan.finish()
+	return todo, err
+}
+
+// postgresTodoTarget 按 todoColumns 的顺序扫描一行到 Todo，
+// 可以为空的系列和重复规则列先扫描到临时变量，由 finish 写回
+type postgresTodoTarget struct {
+	dest   []any
+	finish func()
+}
+
+// postgresTodoScan 返回扫描到 todo 的目标
+func postgresTodoScan(todo *models.Todo) postgresTodoTarget {
+	var (
+		seriesID, rule, timezone *string
+		projectID                *string
+		start                    *time.Time
+		priority                 int16
+	)
+	return postgresTodoTarget{
+		dest: []any{
+			&todo.ID,
+			&todo.UserID,
+			&todo.Title,
+			&todo.Completed,
+			&todo.DueAt,
+			&todo.RemindAt,
+			&seriesID,
+			&rule,
+			&timezone,
+			&start,
+			&priority,
+			&todo.Position,
+			&projectID,
+			&todo.AutoComplete,
+			&todo.Notes,
+			&todo.CreatedAt,
+			&todo.UpdatedAt,
+		},
+		finish: func() {
+			if seriesID != nil {
+				todo.SeriesID = *seriesID
+			}
+			if projectID != nil {
+				todo.ProjectID = *projectID
+			}
+			todo.Recurrence = newRecurrence(rule, timezone, start)
+			todo.Priority = models.Priority(priority)
+		},
+	}
+}
+
+// mapPostgresTodoError 将数据库错误转换为仓库层错误
+func mapPostgresTodoError(operation string, err error) error {
+	// 未找到记录，或 ID 不是合法的 UUID，都视为待办事项不存在
+	if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) {
+		return ErrTodoNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
+// isPostgresInvalidInput 判断是否为非法输入格式错误
+func isPostgresInvalidInput(err error) bool {
+	return isPostgresError(err, pgInvalidTextRepresentation)
+}
+
+// isPostgresError 判断是否为指定错误码的数据库错误
+func isPostgresError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// 查询工作区和成员时返回的列
+const (
+	workspaceColumns = `id::text, name, created_by::text, created_at, updated_at`
+	memberColumns    = `workspace_id::text, user_id::text, role, created_at, updated_at`
+)
+
+// ListWorkspaces 获取用户加入的所有工作区，按创建时间排序
+func (r *PostgresTodoRepository) ListWorkspaces(ctx context.Context, userID string) ([]models.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT w.id::text, w.name, w.created_by::text, w.created_at, w.updated_at, m.role
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.created_at, w.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作区列表失败: %w", err)
+	}
+
+	workspaces, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.Workspace, error) {
+		var (
+			workspace models.Workspace
+			role      string
+		)
+		err := row.Scan(&workspace.ID, &workspace.Name, &workspace.CreatedBy, &workspace.CreatedAt, &workspace.UpdatedAt, &role)
+		workspace.Role = models.WorkspaceRole(role)
+		return workspace, err
+	})
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return []models.Workspace{}, nil
+		}
+		return nil, fmt.Errorf("获取工作区列表失败: %w", err)
+	}
+	return workspaces, nil
+}
+
+// GetWorkspace 获取工作区
+func (r *PostgresTodoRepository) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+workspaceColumns+` FROM workspaces WHERE id = $1`, id)
+	if err != nil {
+		return nil, mapPostgresWorkspaceError("获取工作区失败", err)
+	}
+	workspace, err := pgx.CollectExactlyOneRow(rows, scanPostgresWorkspace)
+	if err != nil {
+		return nil, mapPostgresWorkspaceError("获取工作区失败", err)
+	}
+	return &workspace, nil
+}
+
+// CreateWorkspace 在同一个事务中创建工作区并把创建者添加为所有者，ID 未设置时由数据库生成
+func (r *PostgresTodoRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	logger.WithContext(ctx, r.logger).Debug("创建工作区",
+		zap.String("createdBy", workspace.CreatedBy))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := workspace.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `INSERT INTO workspaces (id, name, created_by, created_at, updated_at)
+		VALUES (COALESCE(NULLIF($1::text, '')::uuid, gen_random_uuid()), $2, $3, $4, $4)
+		RETURNING `+workspaceColumns,
+		workspace.ID, workspace.Name, workspace.CreatedBy, createdAt)
+	if err != nil {
+		return fmt.Errorf("创建工作区失败: %w", err)
+	}
+	created, err := pgx.CollectExactlyOneRow(rows, scanPostgresWorkspace)
+	if err != nil {
+		return fmt.Errorf("创建工作区失败: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)`,
+		created.ID, created.CreatedBy, string(models.WorkspaceOwner), created.CreatedAt); err != nil {
+		return fmt.Errorf("添加工作区所有者失败: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	*workspace = created
+	return nil
+}
+
+// UpdateWorkspace 修改工作区的名称，updated_at 由触发器刷新
+func (r *PostgresTodoRepository) UpdateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	logger.WithContext(ctx, r.logger).Debug("修改工作区",
+		zap.String("id", workspace.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `UPDATE workspaces SET name = $1
+		WHERE id = $2
+		RETURNING `+workspaceColumns, workspace.Name, workspace.ID)
+	if err != nil {
+		return mapPostgresWorkspaceError("修改工作区失败", err)
+	}
+	updated, err := pgx.CollectExactlyOneRow(rows, scanPostgresWorkspace)
+	if err != nil {
+		return mapPostgresWorkspaceError("修改工作区失败", err)
+	}
+	*workspace = updated
+	return nil
+}
+
+// DeleteWorkspace 在同一个事务中删除工作区和它的数据，待办事项的关联数据由外键级联删除
+func (r *PostgresTodoRepository) DeleteWorkspace(ctx context.Context, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除工作区",
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `DELETE FROM workspaces WHERE id = $1`, id)
+	if err != nil {
+		return mapPostgresWorkspaceError("删除工作区失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWorkspaceNotFound
+	}
+
+	for _, table := range []string{"todos", "projects", "tags", "notifications"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE user_id = $1`, id); err != nil {
+			return fmt.Errorf("删除工作区的 %s 失败: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+	return nil
+}
+
+// GetMember 获取用户在工作区中的成员身份
+func (r *PostgresTodoRepository) GetMember(ctx context.Context, workspaceID, userID string) (*models.Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+memberColumns+` FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+	if err != nil {
+		return nil, mapPostgresMemberError("获取工作区成员失败", err)
+	}
+	member, err := pgx.CollectExactlyOneRow(rows, scanPostgresMember)
+	if err != nil {
+		return nil, mapPostgresMemberError("获取工作区成员失败", err)
+	}
+	return &member, nil
+}
+
+// ListMembers 获取工作区的所有成员，按加入时间排序
+func (r *PostgresTodoRepository) ListMembers(ctx context.Context, workspaceID string) ([]models.Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `SELECT `+memberColumns+` FROM workspace_members
+		WHERE workspace_id = $1
+		ORDER BY created_at, user_id`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作区成员列表失败: %w", err)
+	}
+
+	members, err := pgx.CollectRows(rows, scanPostgresMember)
+	if err != nil {
+		if isPostgresInvalidInput(err) {
+			return []models.Member{}, nil
+		}
+		return nil, fmt.Errorf("获取工作区成员列表失败: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember 添加成员
+func (r *PostgresTodoRepository) AddMember(ctx context.Context, member *models.Member) error {
+	logger.WithContext(ctx, r.logger).Debug("添加工作区成员",
+		zap.String("workspaceID", member.WorkspaceID),
+		zap.String("userID", member.UserID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	createdAt := member.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	rows, err := r.pool.Query(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING `+memberColumns,
+		member.WorkspaceID, member.UserID, string(member.Role), createdAt)
+	if err != nil {
+		return mapPostgresMemberError("添加工作区成员失败", err)
+	}
+	created, err := pgx.CollectExactlyOneRow(rows, scanPostgresMember)
+	if err != nil {
+		return mapPostgresMemberError("添加工作区成员失败", err)
+	}
+	*member = created
+	return nil
+}
+
+// UpdateMember 修改成员的角色，updated_at 由触发器刷新
+func (r *PostgresTodoRepository) UpdateMember(ctx context.Context, member *models.Member) error {
+	logger.WithContext(ctx, r.logger).Debug("修改工作区成员",
+		zap.String("workspaceID", member.WorkspaceID),
+		zap.String("userID", member.UserID),
+		zap.String("role", string(member.Role)))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.pool.Query(ctx, `UPDATE workspace_members SET role = $1
+		WHERE workspace_id = $2 AND user_id = $3
+		RETURNING `+memberColumns, string(member.Role), member.WorkspaceID, member.UserID)
+	if err != nil {
+		return mapPostgresMemberError("修改工作区成员失败", err)
+	}
+	updated, err := pgx.CollectExactlyOneRow(rows, scanPostgresMember)
+	if err != nil {
+		return mapPostgresMemberError("修改工作区成员失败", err)
+	}
+	*member = updated
+	return nil
+}
+
+// RemoveMember 移除成员
+func (r *PostgresTodoRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	logger.WithContext(ctx, r.logger).Debug("移除工作区成员",
+		zap.String("workspaceID", workspaceID),
+		zap.String("userID", userID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2`, workspaceID, userID)
+	if err != nil {
+		return mapPostgresMemberError("移除工作区成员失败", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// scanPostgresWorkspace 将一行查询结果扫描为 Workspace
+func scanPostgresWorkspace(row pgx.CollectableRow) (models.Workspace, error) {
+	var workspace models.Workspace
+	err := row.Scan(&workspace.ID, &workspace.Name, &workspace.CreatedBy, &workspace.CreatedAt, &workspace.UpdatedAt)
+	return workspace, err
+}
+
+// scanPostgresMember 将一行查询结果扫描为 Member
+func scanPostgresMember(row pgx.CollectableRow) (models.Member, error) {
+	var (
+		member models.Member
+		role   string
+	)
+	err := row.Scan(&member.WorkspaceID, &member.UserID, &role, &member.CreatedAt, &member.UpdatedAt)
+	member.Role = models.WorkspaceRole(role)
+	return member, err
+}
+
+// mapPostgresWorkspaceError 将数据库错误转换为仓库层错误。
+// 未找到记录或 ID 不是合法的 UUID，都视为工作区不存在
+func mapPostgresWorkspaceError(operation string, err error) error {
+	if errors.Is(err, pgx.ErrNoRows) || isPostgresInvalidInput(err) {
+		return ErrWorkspaceNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
+// mapPostgresMemberError 将数据库错误转换为仓库层错误。
+// 未找到记录或 ID 不是合法的 UUID 视为成员不存在，违反外键视为工作区不存在
+func mapPostgresMemberError(operation string, err error) error {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows), isPostgresInvalidInput(err):
+		return ErrMemberNotFound
+	case isPostgresError(err, pgForeignKeyViolation):
+		return ErrWorkspaceNotFound
+	case isPostgresError(err, pgUniqueViolation):
+		return ErrMemberAlreadyExists
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
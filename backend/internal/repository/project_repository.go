@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// ProjectRepository 项目的仓库接口，存储后端通过类型断言获取。
+//
+// 每个待办事项属于一个项目，删除项目时项目中的待办事项随之删除，
+// 需要保留时由调用方先把它们移动到其他项目。每个用户最多有一个收件箱，
+// 仓库层不阻止删除或归档收件箱，由服务层检查。
+type ProjectRepository interface {
+	// ListProjects 获取指定用户的所有项目，包括已归档的项目，按 models.SortProjects 排序
+	ListProjects(ctx context.Context, userID string) ([]models.Project, error)
+
+	// GetProject 获取指定用户的单个项目，不存在时返回 ErrProjectNotFound
+	GetProject(ctx context.Context, userID, id string) (*models.Project, error)
+
+	// EnsureInbox 返回指定用户的收件箱，不存在时创建。
+	// 创建收件箱时，该用户所有还没有分配项目的待办事项都被移入收件箱。
+	EnsureInbox(ctx context.Context, userID string) (*models.Project, error)
+
+	// CreateProject 创建项目，未设置的 ID 和时间戳会自动填充
+	CreateProject(ctx context.Context, userID string, project *models.Project) error
+
+	// UpdateProject 修改项目的名称、颜色、图标、归档状态和位置，并把保存后的项目写回 project
+	UpdateProject(ctx context.Context, userID string, project *models.Project) error
+
+	// DeleteProject 删除项目。moveTo 不为空时先把项目中的待办事项移动到 moveTo，
+	// 否则同时删除项目中的待办事项。项目不存在时返回 ErrProjectNotFound
+	DeleteProject(ctx context.Context, userID, id, moveTo string) error
+
+	// ProjectCounts 统计指定用户每个项目中待办事项的数量，按项目 ID 索引，
+	// 没有待办事项的项目不出现在结果中
+	ProjectCounts(ctx context.Context, userID string) (map[string]models.ProjectCounts, error)
+
+	// MoveTodos 把指定用户的多个待办事项移动到 projectID，不存在的 ID 被忽略，
+	// 返回实际移动的条数。不检查项目的归属，由服务层在调用之前确认
+	MoveTodos(ctx context.Context, userID string, todoIDs []string, projectID string) (int, error)
+}
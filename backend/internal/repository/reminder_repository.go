@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// ReminderRepository 提醒调度器使用的仓库接口。
+//
+// 每个到期的提醒需要先由某个调度器实例领取：领取时记录实例标识和租约到期时间，
+// 租约期内其他实例不会再领取同一个提醒。发送完成后调用 CompleteReminder 标记为已提醒，
+// 发送失败时调用 ReleaseReminder 释放租约并设置下次重试的时间。实例在发送途中退出时，
+// 租约到期后提醒会被重新领取，因此通知渠道需要依靠 Reminder.Key 去重。
+//
+// 提醒状态与提醒时间绑定：修改提醒时间后，新的提醒时间到期时会再次提醒。
+// 存储后端通过类型断言获取该接口，不支持时提醒调度器不会启动。
+type ReminderRepository interface {
+	// ClaimDueReminders 领取最多 limit 个到期的提醒，按提醒时间从早到晚排序。
+	// 到期指提醒时间不晚于 now、待办事项未完成、该提醒时间尚未提醒过，并且没有有效的租约。
+	ClaimDueReminders(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]models.Reminder, error)
+
+	// CompleteReminder 将领取的提醒标记为已提醒并释放租约。
+	// 租约已经被其他实例接管时不做任何修改。
+	CompleteReminder(ctx context.Context, owner string, reminder models.Reminder) error
+
+	// ReleaseReminder 释放领取的提醒，失败次数加一，retryAt 之前不会再被领取
+	ReleaseReminder(ctx context.Context, owner string, reminder models.Reminder, retryAt time.Time) error
+}
+
+// NotificationRepository 站内通知的仓库接口，存储后端通过类型断言获取
+type NotificationRepository interface {
+	// CreateNotification 保存一条站内通知。同一用户已存在相同 Key 的通知时不重复保存，
+	// 而是把已存在的通知写回 notification
+	CreateNotification(ctx context.Context, notification *models.Notification) error
+
+	// ListNotifications 按创建时间倒序分页获取指定用户的站内通知，时间相同时按 ID 倒序
+	ListNotifications(ctx context.Context, userID string, opts models.NotificationListOptions) (*models.NotificationPage, error)
+
+	// MarkNotificationRead 将通知标记为已读，已读的通知保持原来的已读时间
+	MarkNotificationRead(ctx context.Context, userID, id string, at time.Time) (*models.Notification, error)
+
+	// MarkAllNotificationsRead 将指定用户的所有未读通知标记为已读，返回标记的数量
+	MarkAllNotificationsRead(ctx context.Context, userID string, at time.Time) (int, error)
+}
+
+// newNotificationPage 根据多查询一条的结果生成分页
+func newNotificationPage(items []models.Notification, unread int, opts models.NotificationListOptions) *models.NotificationPage {
+	page := &models.NotificationPage{Items: items, Unread: unread}
+	if len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		page.Next = models.NewNotificationCursor(page.Items[len(page.Items)-1])
+	}
+	if page.Items == nil {
+		page.Items = []models.Notification{}
+	}
+	return page
+}
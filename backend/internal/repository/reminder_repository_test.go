@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	os.Exit(m.Run())
+}
+
+// reminderStore 测试用的存储后端，同时实现待办事项和提醒的仓库接口
+type reminderStore interface {
+	TodoRepository
+	ReminderRepository
+}
+
+// reminderStores 返回需要测试的存储后端
+func reminderStores(t *testing.T) map[string]func(t *testing.T) reminderStore {
+	return map[string]func(t *testing.T) reminderStore{
+		"memory": func(t *testing.T) reminderStore {
+			return NewInMemoryTodoRepository()
+		},
+		"sqlite": func(t *testing.T) reminderStore {
+			cfg := &config.Config{}
+			cfg.Database.Path = filepath.Join(t.TempDir(), "todos.db")
+			repo, err := NewSQLiteTodoRepository(cfg)
+			if err != nil {
+				t.Fatalf("NewSQLiteTodoRepository() error = %v", err)
+			}
+			t.Cleanup(func() { repo.Close() })
+			return repo
+		},
+	}
+}
+
+// createReminders 创建 n 个提醒时间为 remindAt 的待办事项，返回它们的 ID
+func createReminders(t *testing.T, repo reminderStore, n int, remindAt time.Time) []string {
+	t.Helper()
+
+	ids := make([]string, n)
+	for i := range ids {
+		todo := &models.Todo{Title: fmt.Sprintf("提醒 %d", i), RemindAt: &remindAt}
+		if err := repo.Create(context.Background(), "user-1", todo); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids[i] = todo.ID
+	}
+	return ids
+}
+
+func TestClaimDueRemindersConcurrent(t *testing.T) {
+	for name, newStore := range reminderStores(t) {
+		t.Run(name, func(t *testing.T) {
+			repo := newStore(t)
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			ids := createReminders(t, repo, 20, now.Add(-time.Minute))
+
+			// 多个实例同时领取，每个提醒只能被一个实例领取
+			const workers = 8
+			var (
+				wg      sync.WaitGroup
+				mu      sync.Mutex
+				claimed = make(map[string]string)
+			)
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func(owner string) {
+					defer wg.Done()
+					reminders, err := repo.ClaimDueReminders(context.Background(), owner, now, time.Minute, 5)
+					if err != nil {
+						t.Errorf("ClaimDueReminders(%s) error = %v", owner, err)
+						return
+					}
+					mu.Lock()
+					defer mu.Unlock()
+					for _, reminder := range reminders {
+						if previous, ok := claimed[reminder.TodoID]; ok {
+							t.Errorf("提醒 %s 同时被 %s 和 %s 领取", reminder.TodoID, previous, owner)
+						}
+						claimed[reminder.TodoID] = owner
+					}
+				}(fmt.Sprintf("owner-%d", w))
+			}
+			wg.Wait()
+
+			if len(claimed) != len(ids) {
+				t.Errorf("claimed %d reminders, want %d", len(claimed), len(ids))
+			}
+			// 租约期内不会再被领取
+			again, err := repo.ClaimDueReminders(context.Background(), "late", now, time.Minute, 100)
+			if err != nil || len(again) != 0 {
+				t.Errorf("ClaimDueReminders() after claim = %d, %v, want none", len(again), err)
+			}
+		})
+	}
+}
+
+func TestClaimDueRemindersExpiredLease(t *testing.T) {
+	for name, newStore := range reminderStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newStore(t)
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			createReminders(t, repo, 1, now.Add(-time.Minute))
+
+			first, err := repo.ClaimDueReminders(ctx, "crashed", now, time.Minute, 10)
+			if err != nil || len(first) != 1 {
+				t.Fatalf("ClaimDueReminders(crashed) = %d, %v, want 1", len(first), err)
+			}
+
+			// 租约到期前其他实例领取不到
+			if got, err := repo.ClaimDueReminders(ctx, "other", now.Add(59*time.Second), time.Minute, 10); err != nil || len(got) != 0 {
+				t.Fatalf("ClaimDueReminders() before expiry = %d, %v, want none", len(got), err)
+			}
+
+			// 领取的实例没有确认就退出，租约到期后由其他实例重新领取
+			retaken, err := repo.ClaimDueReminders(ctx, "other", now.Add(time.Minute), time.Minute, 10)
+			if err != nil || len(retaken) != 1 || retaken[0].TodoID != first[0].TodoID {
+				t.Fatalf("ClaimDueReminders() after expiry = %+v, %v, want %s", retaken, err, first[0].TodoID)
+			}
+
+			// 原来的实例恢复后确认不生效，租约仍属于新的实例
+			if err := repo.CompleteReminder(ctx, "crashed", first[0]); err != nil {
+				t.Fatalf("CompleteReminder(crashed) error = %v", err)
+			}
+			if got, err := repo.ClaimDueReminders(ctx, "third", now.Add(2*time.Minute), time.Minute, 10); err != nil || len(got) != 1 {
+				t.Fatalf("ClaimDueReminders() after stale complete = %d, %v, want 1", len(got), err)
+			}
+
+			// 持有租约的实例确认后不再领取
+			if err := repo.CompleteReminder(ctx, "third", retaken[0]); err != nil {
+				t.Fatalf("CompleteReminder(third) error = %v", err)
+			}
+			if got, err := repo.ClaimDueReminders(ctx, "fourth", now.Add(time.Hour), time.Minute, 10); err != nil || len(got) != 0 {
+				t.Errorf("ClaimDueReminders() after complete = %d, %v, want none", len(got), err)
+			}
+		})
+	}
+}
+
+func TestReleaseReminderRetry(t *testing.T) {
+	for name, newStore := range reminderStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newStore(t)
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			createReminders(t, repo, 1, now.Add(-time.Minute))
+
+			claimed, err := repo.ClaimDueReminders(ctx, "a", now, time.Minute, 10)
+			if err != nil || len(claimed) != 1 {
+				t.Fatalf("ClaimDueReminders() = %d, %v, want 1", len(claimed), err)
+			}
+			retryAt := now.Add(30 * time.Second)
+			if err := repo.ReleaseReminder(ctx, "a", claimed[0], retryAt); err != nil {
+				t.Fatalf("ReleaseReminder() error = %v", err)
+			}
+
+			if got, err := repo.ClaimDueReminders(ctx, "b", retryAt.Add(-time.Second), time.Minute, 10); err != nil || len(got) != 0 {
+				t.Fatalf("ClaimDueReminders() before retryAt = %d, %v, want none", len(got), err)
+			}
+			got, err := repo.ClaimDueReminders(ctx, "b", retryAt, time.Minute, 10)
+			if err != nil || len(got) != 1 {
+				t.Fatalf("ClaimDueReminders() at retryAt = %d, %v, want 1", len(got), err)
+			}
+			if got[0].Attempts != 1 {
+				t.Errorf("Attempts = %d, want 1", got[0].Attempts)
+			}
+		})
+	}
+}
+
+func TestClaimDueRemindersWorkspaceRecipients(t *testing.T) {
+	for name, newStore := range reminderStores(t) {
+		t.Run(name, func(t *testing.T) {
+			repo := newStore(t)
+			workspaces, ok := repo.(WorkspaceRepository)
+			if !ok {
+				t.Fatalf("%T does not implement WorkspaceRepository", repo)
+			}
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			remindAt := now.Add(-time.Minute)
+
+			workspace := &models.Workspace{ID: "6a3c1f8e-5b2d-4c7a-9e1f-0d2b3c4a5e6f", Name: "团队", CreatedBy: "user-c"}
+			if err := workspaces.CreateWorkspace(ctx, workspace); err != nil {
+				t.Fatalf("CreateWorkspace() error = %v", err)
+			}
+			for _, userID := range []string{"user-a", "user-b"} {
+				if err := workspaces.AddMember(ctx, &models.Member{WorkspaceID: workspace.ID, UserID: userID, Role: models.WorkspaceMember}); err != nil {
+					t.Fatalf("AddMember(%s) error = %v", userID, err)
+				}
+			}
+
+			// 工作区的待办事项以工作区 ID 作为所有者，个人的待办事项以用户 ID 作为所有者
+			team := &models.Todo{Title: "团队周会", RemindAt: &remindAt}
+			if err := repo.Create(ctx, workspace.ID, team); err != nil {
+				t.Fatalf("Create(workspace) error = %v", err)
+			}
+			personal := &models.Todo{Title: "个人", RemindAt: &remindAt}
+			if err := repo.Create(ctx, "user-a", personal); err != nil {
+				t.Fatalf("Create(personal) error = %v", err)
+			}
+
+			reminders, err := repo.ClaimDueReminders(ctx, "worker-1", now, time.Minute, 10)
+			if err != nil {
+				t.Fatalf("ClaimDueReminders() error = %v", err)
+			}
+			if len(reminders) != 2 {
+				t.Fatalf("ClaimDueReminders() = %d reminders, want 2", len(reminders))
+			}
+			for _, reminder := range reminders {
+				switch reminder.TodoID {
+				case team.ID:
+					want := []string{"user-a", "user-b", "user-c"}
+					if reminder.UserID != workspace.ID || reminder.WorkspaceID != workspace.ID || fmt.Sprint(reminder.Recipients) != fmt.Sprint(want) {
+						t.Errorf("workspace reminder = %+v, want workspace %s with recipients %v", reminder, workspace.ID, want)
+					}
+				case personal.ID:
+					if reminder.UserID != "user-a" || reminder.WorkspaceID != "" || len(reminder.Recipients) != 0 {
+						t.Errorf("personal reminder = %+v, want user-a without workspace", reminder)
+					}
+				default:
+					t.Errorf("unexpected reminder %+v", reminder)
+				}
+			}
+		})
+	}
+}
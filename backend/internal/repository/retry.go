@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"go.uber.org/zap"
+)
+
+// 重试策略的默认值
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 2 * time.Second
+	defaultRetryMultiplier     = 2.0
+	defaultRetryJitter         = 0.2
+)
+
+// RetryPolicy 指数退避重试策略，只重试临时性错误
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+	// AttemptTimeout 单次尝试的超时时间，为 0 时不限制
+	AttemptTimeout time.Duration
+}
+
+// NewRetryPolicy 根据配置创建重试策略，未配置的字段使用默认值
+func NewRetryPolicy(cfg config.RetryConfig, attemptTimeout time.Duration) RetryPolicy {
+	p := RetryPolicy{
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		Multiplier:     cfg.Multiplier,
+		Jitter:         cfg.Jitter,
+		AttemptTimeout: attemptTimeout,
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = defaultRetryMultiplier
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = defaultRetryJitter
+	}
+	return p
+}
+
+// Do 执行 fn，遇到临时性错误时按策略重试。attempt 从 1 开始计数。
+// 上下文取消时立即停止等待并返回；非临时性错误直接返回，不会重试。
+func (p RetryPolicy) Do(ctx context.Context, log *zap.Logger, operation string, fn func(ctx context.Context, attempt int) error) error {
+	var lastErr error
+	for attempt := 1; attempt <= p.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			wait := p.backoff(attempt - 1)
+			if retryAfter := retryAfterOf(lastErr); retryAfter > wait {
+				// 服务端要求的等待时间超过上限时不再重试，直接把错误交给调用方
+				if retryAfter > p.MaxBackoff {
+					return lastErr
+				}
+				wait = retryAfter
+			}
+
+			log.Warn("操作重试",
+				zap.String("operation", operation),
+				zap.Int("attempt", attempt),
+				zap.Duration("wait", wait),
+				zap.Error(lastErr),
+			)
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%s: %w", operation, ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		err := p.attempt(ctx, attempt, fn)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		// 调用方已经取消，或者错误不可重试
+		if ctx.Err() != nil || !IsTransientError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("%s: 尝试%d次后失败: %w", operation, p.MaxAttempts, lastErr)
+}
+
+// attempt 在单次尝试的超时时间内执行 fn
+func (p RetryPolicy) attempt(ctx context.Context, attempt int, fn func(ctx context.Context, attempt int) error) error {
+	if p.AttemptTimeout <= 0 {
+		return fn(ctx, attempt)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, p.AttemptTimeout)
+	defer cancel()
+	return fn(attemptCtx, attempt)
+}
+
+// backoff 第 n 次重试前的等待时间：InitialBackoff * Multiplier^(n-1)，
+// 不超过 MaxBackoff，并加上 ±Jitter 比例的随机抖动，避免多个请求同时重试
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(n-1))
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// IsTransientError 判断错误是否为临时性错误：网络错误、单次尝试超时、5xx 和带有 Retry-After 的 429。
+// 没有 Retry-After 的 429 说明服务端不希望客户端自行重试
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *supabase.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return apiErr.RetryAfter > 0
+		case apiErr.StatusCode == http.StatusNotImplemented:
+			return false
+		case apiErr.StatusCode >= http.StatusInternalServerError:
+			return true
+		default:
+			return false
+		}
+	}
+
+	// 单次尝试超时（调用方上下文仍然有效时才会走到这里）
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// retryAfterOf 返回服务端通过 Retry-After 要求的等待时间
+func retryAfterOf(err error) time.Duration {
+	var apiErr *supabase.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
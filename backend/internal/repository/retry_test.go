@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"go.uber.org/zap"
+)
+
+// testRetryPolicy 不等待的重试策略，避免测试依赖真实的退避时间
+func testRetryPolicy(maxAttempts int) RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Nanosecond,
+		MaxBackoff:     50 * time.Millisecond,
+		Multiplier:     2,
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"500", &supabase.Error{StatusCode: http.StatusInternalServerError}, true},
+		{"502 wrapped", fmt.Errorf("查询失败: %w", &supabase.Error{StatusCode: http.StatusBadGateway}), true},
+		{"503", &supabase.Error{StatusCode: http.StatusServiceUnavailable}, true},
+		{"501", &supabase.Error{StatusCode: http.StatusNotImplemented}, false},
+		{"429 with Retry-After", &supabase.Error{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second}, true},
+		{"429 without Retry-After", &supabase.Error{StatusCode: http.StatusTooManyRequests}, false},
+		{"400", &supabase.Error{StatusCode: http.StatusBadRequest}, false},
+		{"404", &supabase.Error{StatusCode: http.StatusNotFound}, false},
+		{"unique violation", &supabase.Error{StatusCode: http.StatusConflict, Code: supabase.CodeUniqueViolation}, false},
+		{"attempt timeout", fmt.Errorf("请求失败: %w", context.DeadlineExceeded), true},
+		{"canceled", context.Canceled, false},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"connection reset", &net.OpError{Op: "read", Err: syscall.ECONNRESET}, true},
+		{"connection refused", fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{"broken pipe", syscall.EPIPE, true},
+		{"dns error", &net.DNSError{Err: "no such host", Name: "example.invalid"}, true},
+		{"plain error", errors.New("解析响应失败"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransientError(tt.err); got != tt.want {
+				t.Errorf("IsTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfterOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want time.Duration
+	}{
+		{"supabase error", &supabase.Error{StatusCode: http.StatusTooManyRequests, RetryAfter: 2 * time.Second}, 2 * time.Second},
+		{"wrapped", fmt.Errorf("查询失败: %w", &supabase.Error{StatusCode: http.StatusServiceUnavailable, RetryAfter: time.Second}), time.Second},
+		{"without header", &supabase.Error{StatusCode: http.StatusServiceUnavailable}, 0},
+		{"other error", io.ErrUnexpectedEOF, 0},
+		{"nil", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfterOf(tt.err); got != tt.want {
+				t.Errorf("retryAfterOf(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	// 抖动不超过 ±Jitter 比例
+	p.Jitter = 0.2
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(2); got < 160*time.Millisecond || got > 240*time.Millisecond {
+			t.Fatalf("backoff(2) with jitter = %v, want within [160ms, 240ms]", got)
+		}
+	}
+}
+
+func TestNewRetryPolicyDefaults(t *testing.T) {
+	p := NewRetryPolicy(config.RetryConfig{Multiplier: 0.5, Jitter: 2}, time.Second)
+	want := RetryPolicy{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Multiplier:     defaultRetryMultiplier,
+		Jitter:         defaultRetryJitter,
+		AttemptTimeout: time.Second,
+	}
+	if p != want {
+		t.Errorf("NewRetryPolicy() = %+v, want %+v", p, want)
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	unavailable := &supabase.Error{StatusCode: http.StatusServiceUnavailable}
+	tests := []struct {
+		name         string
+		errs         []error // 依次作为每次尝试的结果，超出部分视为成功
+		wantAttempts int
+		wantErr      error
+	}{
+		{"success", nil, 1, nil},
+		{"transient then success", []error{unavailable, io.ErrUnexpectedEOF}, 3, nil},
+		{"gives up after max attempts", []error{unavailable, unavailable, unavailable, unavailable}, 3, unavailable},
+		{"permanent error", []error{&supabase.Error{StatusCode: http.StatusBadRequest}}, 1, &supabase.Error{StatusCode: http.StatusBadRequest}},
+		{"429 without Retry-After", []error{&supabase.Error{StatusCode: http.StatusTooManyRequests}}, 1, &supabase.Error{StatusCode: http.StatusTooManyRequests}},
+		{"transient then permanent", []error{unavailable, &supabase.Error{StatusCode: http.StatusNotFound}}, 2, &supabase.Error{StatusCode: http.StatusNotFound}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := testRetryPolicy(3).Do(context.Background(), zap.NewNop(), "测试", func(ctx context.Context, attempt int) error {
+				attempts++
+				if attempt != attempts {
+					t.Errorf("attempt = %d, want %d", attempt, attempts)
+				}
+				if attempt <= len(tt.errs) {
+					return tt.errs[attempt-1]
+				}
+				return nil
+			})
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Do() error = %v, want nil", err)
+				}
+				return
+			}
+			var apiErr *supabase.Error
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantErr.(*supabase.Error).StatusCode {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoHonorsRetryAfter(t *testing.T) {
+	p := testRetryPolicy(2)
+	retryAfter := 20 * time.Millisecond
+
+	attempts := 0
+	start := time.Now()
+	err := p.Do(context.Background(), zap.NewNop(), "测试", func(ctx context.Context, attempt int) error {
+		attempts++
+		if attempt == 1 {
+			return &supabase.Error{StatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter}
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("Do() = %v after %d attempts, want success after 2", err, attempts)
+	}
+	if elapsed := time.Since(start); elapsed < retryAfter {
+		t.Errorf("waited %v before retrying, want at least Retry-After %v", elapsed, retryAfter)
+	}
+
+	// 要求的等待时间超过 MaxBackoff 时不再重试
+	attempts = 0
+	tooLong := &supabase.Error{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}
+	err = p.Do(context.Background(), zap.NewNop(), "测试", func(ctx context.Context, attempt int) error {
+		attempts++
+		return tooLong
+	})
+	if err != tooLong || attempts != 1 {
+		t.Errorf("Do() = %v after %d attempts, want Retry-After error after 1", err, attempts)
+	}
+}
+
+func TestRetryPolicyDoCanceled(t *testing.T) {
+	p := testRetryPolicy(5)
+	p.InitialBackoff = time.Hour
+	p.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	err := p.Do(ctx, zap.NewNop(), "测试", func(ctx context.Context, attempt int) error {
+		attempts++
+		cancel()
+		return io.ErrUnexpectedEOF
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want the attempt error or context.Canceled", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestRetryPolicyDoAttemptTimeout(t *testing.T) {
+	p := testRetryPolicy(2)
+	p.AttemptTimeout = 10 * time.Millisecond
+
+	attempts := 0
+	err := p.Do(context.Background(), zap.NewNop(), "测试", func(ctx context.Context, attempt int) error {
+		attempts++
+		if attempt == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Errorf("Do() = %v after %d attempts, want success after retrying the timed out attempt", err, attempts)
+	}
+}
+
+// fakePostgREST 模拟 PostgREST 的 todos 表：插入按 responses 依次返回预设的错误，之后正常写入
+type fakePostgREST struct {
+	mu        sync.Mutex
+	responses []int // 每次插入返回的状态码，0 表示正常写入
+	rows      map[string]map[string]any
+	inserts   int
+	selects   int
+}
+
+func (f *fakePostgREST) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodPost:
+		f.inserts++
+		var row map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&row); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id, _ := row["id"].(string)
+
+		status := 0
+		if len(f.responses) > 0 {
+			status, f.responses = f.responses[0], f.responses[1:]
+		}
+		switch status {
+		case 0:
+		case http.StatusGatewayTimeout:
+			// 写入成功，但响应没有返回给客户端
+			f.rows[id] = row
+			w.WriteHeader(status)
+			return
+		default:
+			w.WriteHeader(status)
+			return
+		}
+
+		if _, exists := f.rows[id]; exists {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"code": supabase.CodeUniqueViolation, "message": "duplicate key value violates unique constraint"})
+			return
+		}
+		f.rows[id] = row
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode([]map[string]any{row})
+	case http.MethodGet:
+		f.selects++
+		id := strings.TrimPrefix(r.URL.Query().Get("id"), "eq.")
+		result := []map[string]any{}
+		if row, ok := f.rows[id]; ok {
+			result = append(result, row)
+		}
+		json.NewEncoder(w).Encode(result)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// newFakeSupabaseRepository 创建连接到 fakePostgREST 的 SupabaseTodoRepository
+func newFakeSupabaseRepository(t *testing.T, fake *fakePostgREST) *SupabaseTodoRepository {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(fake)
+	t.Cleanup(srv.Close)
+
+	client, err := supabase.NewClient(&config.SupabaseConfig{BaseURL: srv.Listener.Addr().String()})
+	if err != nil {
+		t.Fatalf("supabase.NewClient() error = %v", err)
+	}
+	client.GetHTTPClient().Transport = srv.Client().Transport
+
+	return &SupabaseTodoRepository{client: client, retry: testRetryPolicy(3), logger: zap.NewNop()}
+}
+
+func TestSupabaseCreateRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		responses   []int
+		existing    bool // 待办事项的 ID 在第一次插入前就已经存在
+		wantErr     error
+		wantInserts int
+		wantSelects int
+	}{
+		{name: "success", wantInserts: 1},
+		{name: "retried after 503", responses: []int{http.StatusServiceUnavailable}, wantInserts: 2},
+		// 第一次插入实际已经成功，重试遇到唯一约束冲突时读取已创建的记录
+		{name: "retry hits unique violation", responses: []int{http.StatusGatewayTimeout}, wantInserts: 2, wantSelects: 1},
+		// 第一次插入就冲突说明 ID 被别的记录占用，不能当作自己的记录返回，也不重试
+		{name: "conflict on first attempt", existing: true, wantErr: ErrTodoAlreadyExists, wantInserts: 1},
+		{name: "bad request is not retried", responses: []int{http.StatusBadRequest}, wantErr: errAny, wantInserts: 1},
+		{name: "429 without Retry-After is not retried", responses: []int{http.StatusTooManyRequests}, wantErr: errAny, wantInserts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakePostgREST{responses: tt.responses, rows: make(map[string]map[string]any)}
+			repo := newFakeSupabaseRepository(t, fake)
+
+			todo := &models.Todo{ID: "6f1c2a4e-0000-4000-8000-000000000001", Title: "周报", Position: "V"}
+			if tt.existing {
+				fake.rows[todo.ID] = map[string]any{"id": todo.ID, "user_id": "user-2", "title": "别人的待办事项"}
+			}
+
+			err := repo.Create(context.Background(), "user-1", todo)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("Create() error = %v", err)
+			case tt.wantErr == errAny && err == nil:
+				t.Fatal("Create() error = nil, want error")
+			case tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (todo.UserID != "user-1" || todo.Title != "周报") {
+				t.Errorf("Create() todo = %+v, want the created row", todo)
+			}
+
+			fake.mu.Lock()
+			defer fake.mu.Unlock()
+			if fake.inserts != tt.wantInserts || fake.selects != tt.wantSelects {
+				t.Errorf("inserts = %d, selects = %d, want %d and %d", fake.inserts, fake.selects, tt.wantInserts, tt.wantSelects)
+			}
+		})
+	}
+}
+
+// errAny 表示期望任意错误
+var errAny = errors.New("any error")
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// ShareRepository 共享和邀请的仓库接口，存储后端通过类型断言获取。
+//
+// 仓库层只保存共享关系，不检查调用方的权限，由服务层通过 TodoAccess 和 ProjectAccess
+// 确认后，再以资源所有者的身份调用其他仓库方法。共享项目等同于共享项目中的所有待办事项。
+// 删除待办事项或项目时，它的共享和邀请一并删除
+type ShareRepository interface {
+	// TodoAccess 返回用户对待办事项的权限：所有者为 owner，否则取该待办事项和它所属项目的
+	// 共享中权限最高的角色。待办事项不存在或没有共享给该用户时返回 ErrTodoNotFound
+	TodoAccess(ctx context.Context, userID, todoID string) (*models.Access, error)
+
+	// ProjectAccess 返回用户对项目的权限，项目不存在或没有共享给该用户时返回 ErrProjectNotFound
+	ProjectAccess(ctx context.Context, userID, projectID string) (*models.Access, error)
+
+	// ListShares 获取资源的所有共享，按创建时间排序
+	ListShares(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Share, error)
+
+	// ListSharedWith 获取共享给指定用户的所有资源，按创建时间排序
+	ListSharedWith(ctx context.Context, userID string) ([]models.Share, error)
+
+	// GetShare 获取资源的一条共享，不存在时返回 ErrShareNotFound
+	GetShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) (*models.Share, error)
+
+	// DeleteShare 撤销资源的一条共享，不存在时返回 ErrShareNotFound
+	DeleteShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error
+
+	// CreateInvitation 保存邀请，未设置的 ID 和创建时间会自动填充。
+	// 资源不存在时返回 ErrTodoNotFound 或 ErrProjectNotFound
+	CreateInvitation(ctx context.Context, invitation *models.Invitation) error
+
+	// ListInvitations 获取资源的所有邀请，包括已过期的，按创建时间排序
+	ListInvitations(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Invitation, error)
+
+	// GetInvitationByToken 按令牌的 SHA-256 获取邀请，不存在时返回 ErrInvitationNotFound
+	GetInvitationByToken(ctx context.Context, tokenHash string) (*models.Invitation, error)
+
+	// DeleteInvitation 撤销资源的一条邀请，不存在时返回 ErrInvitationNotFound
+	DeleteInvitation(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error
+
+	// AcceptInvitation 在同一个事务中删除邀请并把资源共享给 userID。
+	// 用户已经有这个资源的共享时保留权限较高的角色；邀请已被删除时返回 ErrInvitationNotFound
+	AcceptInvitation(ctx context.Context, invitation *models.Invitation, userID string) (*models.Share, error)
+}
+
+// shareResourceColumn 资源类型在 shares 和 invitations 表中对应的列，shareResourceTable 对应的表
+func shareResourceColumn(resourceType models.ShareResourceType) string {
+	if resourceType == models.ShareProject {
+		return "project_id"
+	}
+	return "todo_id"
+}
+
+func shareResourceTable(resourceType models.ShareResourceType) string {
+	if resourceType == models.ShareProject {
+		return "projects"
+	}
+	return "todos"
+}
+
+// shareResource 根据 todo_id 和 project_id 两列确定资源的类型和 ID
+func shareResource(todoID, projectID string) (models.ShareResourceType, string) {
+	if projectID != "" {
+		return models.ShareProject, projectID
+	}
+	return models.ShareTodo, todoID
+}
+
+// shareResourceNotFound 资源不存在时返回的错误
+func shareResourceNotFound(resourceType models.ShareResourceType) error {
+	if resourceType == models.ShareProject {
+		return ErrProjectNotFound
+	}
+	return ErrTodoNotFound
+}
+
+// shareRolesBelow 返回权限低于 role 的所有角色，接受邀请时只把这些角色升级为 role
+func shareRolesBelow(role models.ShareRole) []string {
+	var below []string
+	for _, r := range models.ShareRoles {
+		if r == role {
+			break
+		}
+		below = append(below, string(r))
+	}
+	return below
+}
+
+// newAccess 根据资源的所有者和用户得到的共享角色生成访问权限。
+// 用户就是所有者时角色为 owner；没有任何共享时返回 false
+func newAccess(userID, ownerID string, roles []models.ShareRole) (*models.Access, bool) {
+	if ownerID == userID {
+		return &models.Access{OwnerID: ownerID, Role: models.ShareRoleOwner}, true
+	}
+	role := models.MaxShareRole(roles...)
+	if role == "" {
+		return nil, false
+	}
+	return &models.Access{OwnerID: ownerID, Role: role}, true
+}
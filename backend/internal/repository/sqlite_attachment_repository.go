@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var _ AttachmentRepository = (*SQLiteTodoRepository)(nil)
+
+// sqliteAttachmentColumns 查询附件时返回的列
+const sqliteAttachmentColumns = `id, todo_id, user_id, filename, content_type, size, storage_key, created_at`
+
+// ListAttachments 获取待办事项的所有附件，按上传时间排序
+func (r *SQLiteTodoRepository) ListAttachments(ctx context.Context, userID, todoID string) ([]models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.queryAttachments(ctx, `SELECT `+sqliteAttachmentColumns+` FROM attachments
+		WHERE todo_id = ? AND user_id = ?
+		ORDER BY created_at, id`, todoID, userID)
+}
+
+// GetAttachment 获取待办事项的一个附件
+func (r *SQLiteTodoRepository) GetAttachment(ctx context.Context, userID, todoID, id string) (*models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	attachment, err := scanSQLiteAttachment(r.db.QueryRowContext(ctx, `SELECT `+sqliteAttachmentColumns+` FROM attachments
+		WHERE id = ? AND todo_id = ? AND user_id = ?`, id, todoID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("获取附件失败: %w", err)
+	}
+	return attachment, nil
+}
+
+// CreateAttachment 保存附件的元数据，只有待办事项属于该用户时才会插入
+func (r *SQLiteTodoRepository) CreateAttachment(ctx context.Context, userID string, attachment *models.Attachment) error {
+	logger.WithContext(ctx, r.logger).Debug("创建附件",
+		zap.String("userID", userID),
+		zap.String("todoID", attachment.TodoID),
+		zap.String("filename", attachment.Filename))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if attachment.ID == "" {
+		attachment.ID = uuid.New().String()
+	}
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = time.Now()
+	}
+
+	created, err := scanSQLiteAttachment(r.db.QueryRowContext(ctx, `INSERT INTO attachments
+			(id, todo_id, user_id, filename, content_type, size, storage_key, created_at)
+		SELECT ?, id, user_id, ?, ?, ?, ?, ?
+		FROM todos WHERE id = ? AND user_id = ?
+		RETURNING `+sqliteAttachmentColumns,
+		attachment.ID, attachment.Filename, attachment.ContentType, attachment.Size, attachment.StorageKey,
+		formatSQLiteTime(attachment.CreatedAt), attachment.TodoID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTodoNotFound
+		}
+		return fmt.Errorf("创建附件失败: %w", err)
+	}
+
+	*attachment = *created
+	return nil
+}
+
+// DeleteAttachment 把附件与待办事项脱离
+func (r *SQLiteTodoRepository) DeleteAttachment(ctx context.Context, userID, todoID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除附件",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE attachments SET todo_id = NULL
+		WHERE id = ? AND todo_id = ? AND user_id = ?`, id, todoID, userID)
+	if err != nil {
+		return fmt.Errorf("删除附件失败: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("获取受影响行数失败: %w", err)
+	} else if affected == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// ListDetachedAttachments 获取已经脱离待办事项的附件
+func (r *SQLiteTodoRepository) ListDetachedAttachments(ctx context.Context, limit int) ([]models.Attachment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.queryAttachments(ctx, `SELECT `+sqliteAttachmentColumns+` FROM attachments
+		WHERE todo_id IS NULL
+		ORDER BY created_at, id
+		LIMIT ?`, limit)
+}
+
+// PurgeAttachment 删除已经脱离待办事项的附件的元数据
+func (r *SQLiteTodoRepository) PurgeAttachment(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM attachments WHERE id = ? AND todo_id IS NULL`, id); err != nil {
+		return fmt.Errorf("删除附件元数据失败: %w", err)
+	}
+	return nil
+}
+
+// queryAttachments 执行返回 sqliteAttachmentColumns 的查询
+func (r *SQLiteTodoRepository) queryAttachments(ctx context.Context, query string, args ...any) ([]models.Attachment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取附件列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := make([]models.Attachment, 0)
+	for rows.Next() {
+		attachment, err := scanSQLiteAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析附件失败: %w", err)
+		}
+		attachments = append(attachments, *attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取附件列表失败: %w", err)
+	}
+	return attachments, nil
+}
+
+// scanSQLiteAttachment 将一行查询结果扫描为 Attachment，todo_id 为 NULL 时 TodoID 为空
+func scanSQLiteAttachment(row sqliteScanner) (*models.Attachment, error) {
+	var (
+		attachment models.Attachment
+		todoID     sql.NullString
+		createdAt  string
+	)
+	if err := row.Scan(&attachment.ID, &todoID, &attachment.UserID, &attachment.Filename, &attachment.ContentType,
+		&attachment.Size, &attachment.StorageKey, &createdAt); err != nil {
+		return nil, err
+	}
+	attachment.TodoID = todoID.String
+
+	var err error
+	if attachment.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
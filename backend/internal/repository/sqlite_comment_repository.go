@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var _ CommentRepository = (*SQLiteTodoRepository)(nil)
+
+// sqliteCommentColumns 查询评论时返回的列
+const sqliteCommentColumns = `id, todo_id, user_id, body, created_at, updated_at`
+
+// ListComments 按创建时间正序分页获取待办事项的评论
+func (r *SQLiteTodoRepository) ListComments(ctx context.Context, todoID string, opts models.CommentListOptions) (*models.CommentPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := `SELECT ` + sqliteCommentColumns + ` FROM comments WHERE todo_id = ?`
+	args := []any{todoID}
+	if opts.After != nil {
+		t, err := opts.After.Time()
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (created_at, id) > (?, ?)`
+		args = append(args, formatSQLiteTime(t), opts.After.ID)
+	}
+	query += ` ORDER BY created_at, id LIMIT ?`
+	args = append(args, opts.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取评论列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+	for rows.Next() {
+		comment, err := scanSQLiteComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析评论失败: %w", err)
+		}
+		comments = append(comments, *comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取评论列表失败: %w", err)
+	}
+
+	return newCommentPage(comments, opts), nil
+}
+
+// CommentCounts 批量统计待办事项的评论数
+func (r *SQLiteTodoRepository) CommentCounts(ctx context.Context, todoIDs []string) (map[string]int, error) {
+	result := make(map[string]int)
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	args := make([]any, len(todoIDs))
+	for i, id := range todoIDs {
+		args[i] = id
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT todo_id, COUNT(*)
+		FROM comments
+		WHERE todo_id IN (?`+strings.Repeat(", ?", len(todoIDs)-1)+`)
+		GROUP BY todo_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("统计评论数失败: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID string
+			count  int
+		)
+		if err := rows.Scan(&todoID, &count); err != nil {
+			return nil, fmt.Errorf("解析评论数失败: %w", err)
+		}
+		result[todoID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("统计评论数失败: %w", err)
+	}
+	return result, nil
+}
+
+// GetComment 获取待办事项的一条评论
+func (r *SQLiteTodoRepository) GetComment(ctx context.Context, todoID, id string) (*models.Comment, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	comment, err := scanSQLiteComment(r.db.QueryRowContext(ctx, `SELECT `+sqliteCommentColumns+` FROM comments
+		WHERE id = ? AND todo_id = ?`, id, todoID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("获取评论失败: %w", err)
+	}
+	return comment, nil
+}
+
+// CreateComment 保存评论，只有待办事项存在时才会插入
+func (r *SQLiteTodoRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	logger.WithContext(ctx, r.logger).Debug("创建评论",
+		zap.String("userID", comment.UserID),
+		zap.String("todoID", comment.TodoID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if comment.ID == "" {
+		comment.ID = uuid.New().String()
+	}
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+	createdAt := formatSQLiteTime(comment.CreatedAt)
+
+	created, err := scanSQLiteComment(r.db.QueryRowContext(ctx, `INSERT INTO comments
+			(id, todo_id, user_id, body, created_at, updated_at)
+		SELECT ?, id, ?, ?, ?, ?
+		FROM todos WHERE id = ?
+		RETURNING `+sqliteCommentColumns,
+		comment.ID, comment.UserID, comment.Body, createdAt, createdAt, comment.TodoID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTodoNotFound
+		}
+		return fmt.Errorf("创建评论失败: %w", err)
+	}
+
+	*comment = *created
+	return nil
+}
+
+// UpdateComment 修改评论的内容
+func (r *SQLiteTodoRepository) UpdateComment(ctx context.Context, comment *models.Comment) error {
+	logger.WithContext(ctx, r.logger).Debug("更新评论",
+		zap.String("userID", comment.UserID),
+		zap.String("todoID", comment.TodoID),
+		zap.String("id", comment.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	updated, err := scanSQLiteComment(r.db.QueryRowContext(ctx, `UPDATE comments
+		SET body = ?, updated_at = `+sqliteNow+`
+		WHERE id = ? AND todo_id = ? AND user_id = ?
+		RETURNING `+sqliteCommentColumns,
+		comment.Body, comment.ID, comment.TodoID, comment.UserID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		return fmt.Errorf("更新评论失败: %w", err)
+	}
+
+	*comment = *updated
+	return nil
+}
+
+// DeleteComment 删除 userID 作为作者的一条评论
+func (r *SQLiteTodoRepository) DeleteComment(ctx context.Context, userID, todoID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除评论",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM comments WHERE id = ? AND todo_id = ? AND user_id = ?`,
+		id, todoID, userID)
+	if err != nil {
+		return fmt.Errorf("删除评论失败: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("获取受影响行数失败: %w", err)
+	} else if affected == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// scanSQLiteComment 将一行查询结果扫描为 Comment
+func scanSQLiteComment(row sqliteScanner) (*models.Comment, error) {
+	var (
+		comment              models.Comment
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&comment.ID, &comment.TodoID, &comment.UserID, &comment.Body, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if comment.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if comment.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
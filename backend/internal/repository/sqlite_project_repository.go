@@ -0,0 +1,303 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteProjectColumns 查询项目时返回的列
+const sqliteProjectColumns = `id, user_id, name, color, icon, archived, is_inbox, position, created_at, updated_at`
+
+// ListProjects 获取指定用户的所有项目，收件箱在最前，其余按位置排序
+func (r *SQLiteTodoRepository) ListProjects(ctx context.Context, userID string) ([]models.Project, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取项目列表", zap.String("userID", userID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sqliteProjectColumns+` FROM projects WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取项目列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	projects := make([]models.Project, 0)
+	for rows.Next() {
+		project, err := scanSQLiteProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析项目失败: %w", err)
+		}
+		projects = append(projects, *project)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取项目列表失败: %w", err)
+	}
+
+	models.SortProjects(projects)
+	return projects, nil
+}
+
+// GetProject 获取指定用户的单个项目
+func (r *SQLiteTodoRepository) GetProject(ctx context.Context, userID, id string) (*models.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.getProject(ctx, r.db, `id = ? AND user_id = ?`, id, userID)
+}
+
+// EnsureInbox 返回指定用户的收件箱，不存在时在一个事务中创建收件箱，
+// 并把还没有分配项目的待办事项移入收件箱
+func (r *SQLiteTodoRepository) EnsureInbox(ctx context.Context, userID string) (*models.Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	inbox, err := r.getProject(ctx, r.db, `user_id = ? AND is_inbox = 1`, userID)
+	if !errors.Is(err, ErrProjectNotFound) {
+		return inbox, err
+	}
+
+	logger.WithContext(ctx, r.logger).Info("创建收件箱", zap.String("userID", userID))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 连接池只有一个连接，事务之间不会并发；仍然忽略唯一索引冲突，保证只有一个收件箱
+	result, err := tx.ExecContext(ctx, `INSERT INTO projects (id, user_id, name, color, is_inbox)
+		VALUES (?, ?, ?, ?, 1)
+		ON CONFLICT DO NOTHING`,
+		uuid.New().String(), userID, models.InboxProjectName, models.DefaultProjectColor)
+	if err != nil {
+		return nil, fmt.Errorf("创建收件箱失败: %w", err)
+	}
+	inbox, err = r.getProject(ctx, tx, `user_id = ? AND is_inbox = 1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	if created, _ := result.RowsAffected(); created > 0 {
+		if _, err := tx.ExecContext(ctx, `UPDATE todos SET project_id = ?
+			WHERE user_id = ? AND project_id IS NULL`, inbox.ID, userID); err != nil {
+			return nil, fmt.Errorf("移动待办事项到收件箱失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("创建收件箱失败: %w", err)
+	}
+	return inbox, nil
+}
+
+// CreateProject 创建项目
+func (r *SQLiteTodoRepository) CreateProject(ctx context.Context, userID string, project *models.Project) error {
+	logger.WithContext(ctx, r.logger).Debug("创建项目",
+		zap.String("userID", userID),
+		zap.String("name", project.Name))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if project.ID == "" {
+		project.ID = uuid.New().String()
+	}
+	if project.CreatedAt.IsZero() {
+		project.CreatedAt = time.Now()
+	}
+	createdAt := formatSQLiteTime(project.CreatedAt)
+
+	created, err := scanSQLiteProject(r.db.QueryRowContext(ctx, `INSERT INTO projects
+			(id, user_id, name, color, icon, archived, is_inbox, position, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?)
+		RETURNING `+sqliteProjectColumns,
+		project.ID, userID, project.Name, project.Color, project.Icon, project.Archived, project.Position,
+		createdAt, createdAt))
+	if err != nil {
+		return fmt.Errorf("创建项目失败: %w", err)
+	}
+
+	*project = *created
+	return nil
+}
+
+// UpdateProject 修改项目的名称、颜色、图标、归档状态和位置
+func (r *SQLiteTodoRepository) UpdateProject(ctx context.Context, userID string, project *models.Project) error {
+	logger.WithContext(ctx, r.logger).Debug("更新项目",
+		zap.String("userID", userID),
+		zap.String("id", project.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	updated, err := scanSQLiteProject(r.db.QueryRowContext(ctx, `UPDATE projects
+		SET name = ?, color = ?, icon = ?, archived = ?, position = ?, updated_at = `+sqliteNow+`
+		WHERE id = ? AND user_id = ?
+		RETURNING `+sqliteProjectColumns,
+		project.Name, project.Color, project.Icon, project.Archived, project.Position, project.ID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrProjectNotFound
+		}
+		return fmt.Errorf("更新项目失败: %w", err)
+	}
+
+	*project = *updated
+	return nil
+}
+
+// DeleteProject 在一个事务中移动项目中的待办事项并删除项目，
+// moveTo 为空时待办事项由外键级联删除
+func (r *SQLiteTodoRepository) DeleteProject(ctx context.Context, userID, id, moveTo string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除项目",
+		zap.String("userID", userID),
+		zap.String("id", id),
+		zap.String("moveTo", moveTo))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if moveTo != "" {
+		if _, err := r.getProject(ctx, tx, `id = ? AND user_id = ?`, moveTo, userID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE todos SET project_id = ?
+			WHERE project_id = ? AND user_id = ?`, moveTo, id, userID); err != nil {
+			return fmt.Errorf("移动项目中的待办事项失败: %w", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM projects WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("删除项目失败: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("获取受影响行数失败: %w", err)
+	} else if affected == 0 {
+		return ErrProjectNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("删除项目失败: %w", err)
+	}
+	return nil
+}
+
+// ProjectCounts 统计指定用户每个项目中待办事项的数量
+func (r *SQLiteTodoRepository) ProjectCounts(ctx context.Context, userID string) (map[string]models.ProjectCounts, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT project_id, COUNT(*), SUM(completed)
+		FROM todos
+		WHERE user_id = ? AND project_id IS NOT NULL
+		GROUP BY project_id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("统计项目中的待办事项失败: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]models.ProjectCounts)
+	for rows.Next() {
+		var (
+			projectID string
+			c         models.ProjectCounts
+		)
+		if err := rows.Scan(&projectID, &c.Total, &c.Completed); err != nil {
+			return nil, fmt.Errorf("解析项目统计失败: %w", err)
+		}
+		counts[projectID] = c
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("统计项目中的待办事项失败: %w", err)
+	}
+	return counts, nil
+}
+
+// MoveTodos 把指定用户的多个待办事项移动到 projectID
+func (r *SQLiteTodoRepository) MoveTodos(ctx context.Context, userID string, todoIDs []string, projectID string) (int, error) {
+	if len(todoIDs) == 0 {
+		return 0, nil
+	}
+
+	logger.WithContext(ctx, r.logger).Debug("移动待办事项到项目",
+		zap.String("userID", userID),
+		zap.Strings("todoIDs", todoIDs),
+		zap.String("projectID", projectID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	args := make([]any, 0, len(todoIDs)+2)
+	args = append(args, projectID, userID)
+	for _, id := range todoIDs {
+		args = append(args, id)
+	}
+
+	result, err := r.db.ExecContext(ctx, `UPDATE todos SET project_id = ?
+		WHERE user_id = ? AND id IN (?`+strings.Repeat(", ?", len(todoIDs)-1)+`)`, args...)
+	if err != nil {
+		if isSQLiteConstraint(err, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY) {
+			return 0, ErrProjectNotFound
+		}
+		return 0, fmt.Errorf("移动待办事项到项目失败: %w", err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("获取受影响行数失败: %w", err)
+	}
+	return int(moved), nil
+}
+
+// sqliteQuerier 抽象 *sql.DB 与 *sql.Tx 的 QueryRowContext 方法
+type sqliteQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// getProject 按条件查询单个项目，没有时返回 ErrProjectNotFound
+func (r *SQLiteTodoRepository) getProject(ctx context.Context, q sqliteQuerier, where string, args ...any) (*models.Project, error) {
+	project, err := scanSQLiteProject(q.QueryRowContext(ctx, `SELECT `+sqliteProjectColumns+` FROM projects WHERE `+where, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("获取项目失败: %w", err)
+	}
+	return project, nil
+}
+
+// scanSQLiteProject 将一行查询结果扫描为 Project
+func scanSQLiteProject(row sqliteScanner) (*models.Project, error) {
+	var (
+		project              models.Project
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&project.ID, &project.UserID, &project.Name, &project.Color, &project.Icon,
+		&project.Archived, &project.Inbox, &project.Position, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if project.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if project.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// sqliteNotificationColumns 查询站内通知时返回的列
+const sqliteNotificationColumns = `id, user_id, COALESCE(todo_id, ''), kind, title, due_at, key, created_at, read_at`
+
+// ClaimDueReminders 领取到期的提醒。
+// 整个领取过程在一个事务中完成，第一条语句就是写操作，多个进程共用同一个数据库文件时也会串行执行。
+func (r *SQLiteTodoRepository) ClaimDueReminders(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]models.Reminder, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	nowText := formatSQLiteTime(now)
+
+	// 为到期的待办事项补齐提醒状态
+	if _, err := tx.ExecContext(ctx, `INSERT INTO todo_reminders (todo_id)
+		SELECT t.id FROM todos t
+		WHERE t.remind_at IS NOT NULL AND t.remind_at <= ? AND t.completed = 0
+		  AND NOT EXISTS (SELECT 1 FROM todo_reminders r WHERE r.todo_id = t.id)`, nowText); err != nil {
+		return nil, fmt.Errorf("领取提醒失败: %w", err)
+	}
+
+	// 工作区的待办事项以工作区 ID 作为所有者，同时查出成员，提醒发给每个成员
+	rows, err := tx.QueryContext(ctx, `SELECT t.id, t.user_id, t.title, t.due_at, t.remind_at, r.attempts,
+		  w.id, (SELECT group_concat(m.user_id) FROM workspace_members m WHERE m.workspace_id = w.id)
+		FROM todo_reminders r JOIN todos t ON t.id = r.todo_id
+		LEFT JOIN workspaces w ON w.id = t.user_id
+		WHERE t.remind_at IS NOT NULL AND t.remind_at <= ? AND t.completed = 0
+		  AND (r.reminded_for IS NULL OR r.reminded_for <> t.remind_at)
+		  AND (r.lease_until IS NULL OR r.lease_until <= ?)
+		ORDER BY t.remind_at, t.id
+		LIMIT ?`, nowText, nowText, limit)
+	if err != nil {
+		return nil, fmt.Errorf("领取提醒失败: %w", err)
+	}
+
+	var reminders []models.Reminder
+	for rows.Next() {
+		var (
+			reminder    models.Reminder
+			dueAt       sql.NullString
+			remindAt    string
+			workspaceID sql.NullString
+			recipients  sql.NullString
+		)
+		if err := rows.Scan(&reminder.TodoID, &reminder.UserID, &reminder.Title, &dueAt, &remindAt, &reminder.Attempts,
+			&workspaceID, &recipients); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("解析提醒失败: %w", err)
+		}
+		if reminder.DueAt, err = parseSQLiteNullTime(dueAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if reminder.RemindAt, err = parseSQLiteTime(remindAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if workspaceID.Valid {
+			reminder.WorkspaceID = workspaceID.String
+			if recipients.String != "" {
+				reminder.Recipients = strings.Split(recipients.String, ",")
+				sort.Strings(reminder.Recipients)
+			}
+		}
+		reminders = append(reminders, reminder)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("领取提醒失败: %w", err)
+	}
+
+	leaseUntil := formatSQLiteTime(now.Add(lease))
+	for _, reminder := range reminders {
+		if _, err := tx.ExecContext(ctx, `UPDATE todo_reminders SET lease_owner = ?, lease_until = ?
+			WHERE todo_id = ?`, owner, leaseUntil, reminder.TodoID); err != nil {
+			return nil, fmt.Errorf("领取提醒失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("领取提醒失败: %w", err)
+	}
+	return reminders, nil
+}
+
+// CompleteReminder 将领取的提醒标记为已提醒并释放租约
+func (r *SQLiteTodoRepository) CompleteReminder(ctx context.Context, owner string, reminder models.Reminder) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `UPDATE todo_reminders
+		SET reminded_for = ?, lease_owner = NULL, lease_until = NULL, attempts = 0
+		WHERE todo_id = ? AND lease_owner = ?`,
+		formatSQLiteTime(reminder.RemindAt), reminder.TodoID, owner)
+	if err != nil {
+		return fmt.Errorf("标记提醒失败: %w", err)
+	}
+	return nil
+}
+
+// ReleaseReminder 释放领取的提醒，retryAt 之前不会再被领取
+func (r *SQLiteTodoRepository) ReleaseReminder(ctx context.Context, owner string, reminder models.Reminder, retryAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `UPDATE todo_reminders
+		SET lease_owner = NULL, lease_until = ?, attempts = ?
+		WHERE todo_id = ? AND lease_owner = ?`,
+		formatSQLiteTime(retryAt), reminder.Attempts+1, reminder.TodoID, owner)
+	if err != nil {
+		return fmt.Errorf("释放提醒失败: %w", err)
+	}
+	return nil
+}
+
+// CreateNotification 保存一条站内通知，相同 Key 的通知只保存一次
+func (r *SQLiteTodoRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	logger.WithContext(ctx, r.logger).Debug("创建站内通知",
+		zap.String("userID", notification.UserID),
+		zap.String("key", notification.Key))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	var todoID any
+	if notification.TodoID != "" {
+		todoID = notification.TodoID
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO notifications (id, user_id, todo_id, kind, title, due_at, key, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, key) DO NOTHING`,
+		notification.ID,
+		notification.UserID,
+		todoID,
+		notification.Kind,
+		notification.Title,
+		formatSQLiteNullTime(notification.DueAt),
+		notification.Key,
+		formatSQLiteTime(notification.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("创建站内通知失败: %w", err)
+	}
+
+	// 无论是否插入成功，都读取该键对应的通知
+	stored, err := scanSQLiteNotification(r.db.QueryRowContext(ctx,
+		`SELECT `+sqliteNotificationColumns+` FROM notifications WHERE user_id = ? AND key = ?`,
+		notification.UserID, notification.Key))
+	if err != nil {
+		return fmt.Errorf("创建站内通知失败: %w", err)
+	}
+
+	*notification = *stored
+	return nil
+}
+
+// ListNotifications 按创建时间倒序分页获取指定用户的站内通知
+func (r *SQLiteTodoRepository) ListNotifications(ctx context.Context, userID string, opts models.NotificationListOptions) (*models.NotificationPage, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取站内通知列表",
+		zap.String("userID", userID),
+		zap.Int("limit", opts.Limit))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	var unread int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`,
+		userID).Scan(&unread); err != nil {
+		return nil, fmt.Errorf("统计未读通知失败: %w", err)
+	}
+
+	query := `SELECT ` + sqliteNotificationColumns + ` FROM notifications WHERE user_id = ?`
+	args := []any{userID}
+	if opts.UnreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	if opts.After != nil {
+		t, err := opts.After.Time()
+		if err != nil {
+			return nil, err
+		}
+		query += ` AND (created_at, id) < (?, ?)`
+		args = append(args, formatSQLiteTime(t), opts.After.ID)
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, opts.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取站内通知列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		n, err := scanSQLiteNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析站内通知失败: %w", err)
+		}
+		notifications = append(notifications, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取站内通知列表失败: %w", err)
+	}
+
+	return newNotificationPage(notifications, unread, opts), nil
+}
+
+// MarkNotificationRead 将通知标记为已读
+func (r *SQLiteTodoRepository) MarkNotificationRead(ctx context.Context, userID, id string, at time.Time) (*models.Notification, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	n, err := scanSQLiteNotification(r.db.QueryRowContext(ctx, `UPDATE notifications
+		SET read_at = COALESCE(read_at, ?)
+		WHERE id = ? AND user_id = ?
+		RETURNING `+sqliteNotificationColumns,
+		formatSQLiteTime(at), id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, fmt.Errorf("标记通知已读失败: %w", err)
+	}
+	return n, nil
+}
+
+// MarkAllNotificationsRead 将指定用户的所有未读通知标记为已读
+func (r *SQLiteTodoRepository) MarkAllNotificationsRead(ctx context.Context, userID string, at time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `UPDATE notifications SET read_at = ?
+		WHERE user_id = ? AND read_at IS NULL`, formatSQLiteTime(at), userID)
+	if err != nil {
+		return 0, fmt.Errorf("标记通知已读失败: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("获取受影响行数失败: %w", err)
+	}
+	return int(affected), nil
+}
+
+// scanSQLiteNotification 将一行查询结果扫描为 Notification
+func scanSQLiteNotification(row sqliteScanner) (*models.Notification, error) {
+	var (
+		n             models.Notification
+		dueAt, readAt sql.NullString
+		createdAt     string
+	)
+	if err := row.Scan(&n.ID, &n.UserID, &n.TodoID, &n.Kind, &n.Title, &dueAt, &n.Key, &createdAt, &readAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if n.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if n.DueAt, err = parseSQLiteNullTime(dueAt); err != nil {
+		return nil, err
+	}
+	if n.ReadAt, err = parseSQLiteNullTime(readAt); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
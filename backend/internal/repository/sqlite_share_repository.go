@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var _ ShareRepository = (*SQLiteTodoRepository)(nil)
+
+// 查询共享和邀请时返回的列，资源由 todo_id 和 project_id 中不为空的一列确定
+const (
+	sqliteShareColumns = `id, COALESCE(todo_id, ''), COALESCE(project_id, ''), owner_id, user_id, role,
+		created_by, created_at, updated_at`
+	sqliteInvitationColumns = `id, COALESCE(todo_id, ''), COALESCE(project_id, ''), owner_id, email, role,
+		token_hash, invited_by, created_at, expires_at`
+)
+
+// TodoAccess 返回用户对待办事项的权限，同时查询待办事项本身和它所属项目的共享
+func (r *SQLiteTodoRepository) TodoAccess(ctx context.Context, userID, todoID string) (*models.Access, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT t.user_id, COALESCE(s.role, '')
+		FROM todos t
+		LEFT JOIN shares s ON s.user_id = ?
+			AND (s.todo_id = t.id OR (t.project_id IS NOT NULL AND s.project_id = t.project_id))
+		WHERE t.id = ?`, userID, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("查询待办事项的权限失败: %w", err)
+	}
+	access, err := scanSQLiteAccess(rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询待办事项的权限失败: %w", err)
+	}
+	if access == nil {
+		return nil, ErrTodoNotFound
+	}
+	return access, nil
+}
+
+// ProjectAccess 返回用户对项目的权限
+func (r *SQLiteTodoRepository) ProjectAccess(ctx context.Context, userID, projectID string) (*models.Access, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT p.user_id, COALESCE(s.role, '')
+		FROM projects p
+		LEFT JOIN shares s ON s.user_id = ? AND s.project_id = p.id
+		WHERE p.id = ?`, userID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("查询项目的权限失败: %w", err)
+	}
+	access, err := scanSQLiteAccess(rows, userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询项目的权限失败: %w", err)
+	}
+	if access == nil {
+		return nil, ErrProjectNotFound
+	}
+	return access, nil
+}
+
+// ListShares 获取资源的所有共享，按创建时间排序
+func (r *SQLiteTodoRepository) ListShares(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Share, error) {
+	return r.listShares(ctx, shareResourceColumn(resourceType)+` = ?`, resourceID)
+}
+
+// ListSharedWith 获取共享给指定用户的所有资源，按创建时间排序
+func (r *SQLiteTodoRepository) ListSharedWith(ctx context.Context, userID string) ([]models.Share, error) {
+	return r.listShares(ctx, `user_id = ?`, userID)
+}
+
+// GetShare 获取资源的一条共享
+func (r *SQLiteTodoRepository) GetShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) (*models.Share, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	share, err := scanSQLiteShare(r.db.QueryRowContext(ctx, `SELECT `+sqliteShareColumns+` FROM shares
+		WHERE id = ? AND `+shareResourceColumn(resourceType)+` = ?`, id, resourceID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShareNotFound
+		}
+		return nil, fmt.Errorf("获取共享失败: %w", err)
+	}
+	return share, nil
+}
+
+// DeleteShare 撤销资源的一条共享
+func (r *SQLiteTodoRepository) DeleteShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("撤销共享",
+		zap.String("resourceType", string(resourceType)),
+		zap.String("resourceID", resourceID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM shares
+		WHERE id = ? AND `+shareResourceColumn(resourceType)+` = ?`, id, resourceID)
+	if err != nil {
+		return fmt.Errorf("撤销共享失败: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("获取受影响行数失败: %w", err)
+	} else if affected == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// CreateInvitation 保存邀请，只有资源存在时才会插入
+func (r *SQLiteTodoRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	logger.WithContext(ctx, r.logger).Debug("创建邀请",
+		zap.String("resourceType", string(invitation.ResourceType)),
+		zap.String("resourceID", invitation.ResourceID),
+		zap.String("invitedBy", invitation.InvitedBy))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if invitation.ID == "" {
+		invitation.ID = uuid.New().String()
+	}
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+
+	created, err := scanSQLiteInvitation(r.db.QueryRowContext(ctx, `INSERT INTO invitations
+			(id, `+shareResourceColumn(invitation.ResourceType)+`, owner_id, email, role, token_hash, invited_by, created_at, expires_at)
+		SELECT ?, id, ?, ?, ?, ?, ?, ?, ?
+		FROM `+shareResourceTable(invitation.ResourceType)+` WHERE id = ?
+		RETURNING `+sqliteInvitationColumns,
+		invitation.ID, invitation.OwnerID, invitation.Email, string(invitation.Role), invitation.TokenHash,
+		invitation.InvitedBy, formatSQLiteTime(invitation.CreatedAt), formatSQLiteTime(invitation.ExpiresAt),
+		invitation.ResourceID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return shareResourceNotFound(invitation.ResourceType)
+		}
+		return fmt.Errorf("创建邀请失败: %w", err)
+	}
+
+	*invitation = *created
+	return nil
+}
+
+// ListInvitations 获取资源的所有邀请，按创建时间排序
+func (r *SQLiteTodoRepository) ListInvitations(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sqliteInvitationColumns+` FROM invitations
+		WHERE `+shareResourceColumn(resourceType)+` = ?
+		ORDER BY created_at, id`, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("获取邀请列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := make([]models.Invitation, 0)
+	for rows.Next() {
+		invitation, err := scanSQLiteInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析邀请失败: %w", err)
+		}
+		invitations = append(invitations, *invitation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取邀请列表失败: %w", err)
+	}
+	return invitations, nil
+}
+
+// GetInvitationByToken 按令牌的 SHA-256 获取邀请
+func (r *SQLiteTodoRepository) GetInvitationByToken(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	invitation, err := scanSQLiteInvitation(r.db.QueryRowContext(ctx, `SELECT `+sqliteInvitationColumns+` FROM invitations
+		WHERE token_hash = ?`, tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("获取邀请失败: %w", err)
+	}
+	return invitation, nil
+}
+
+// DeleteInvitation 撤销资源的一条邀请
+func (r *SQLiteTodoRepository) DeleteInvitation(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("撤销邀请",
+		zap.String("resourceType", string(resourceType)),
+		zap.String("resourceID", resourceID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM invitations
+		WHERE id = ? AND `+shareResourceColumn(resourceType)+` = ?`, id, resourceID)
+	if err != nil {
+		return fmt.Errorf("撤销邀请失败: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("获取受影响行数失败: %w", err)
+	} else if affected == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation 在同一个事务中删除邀请并把资源共享给 userID。
+// 已有共享时只在邀请的角色更高时升级，不会降低已有的权限
+func (r *SQLiteTodoRepository) AcceptInvitation(ctx context.Context, invitation *models.Invitation, userID string) (*models.Share, error) {
+	logger.WithContext(ctx, r.logger).Debug("接受邀请",
+		zap.String("userID", userID),
+		zap.String("invitationID", invitation.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM invitations WHERE id = ?`, invitation.ID)
+	if err != nil {
+		return nil, fmt.Errorf("删除邀请失败: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("获取受影响行数失败: %w", err)
+	} else if affected == 0 {
+		return nil, ErrInvitationNotFound
+	}
+
+	column := shareResourceColumn(invitation.ResourceType)
+	now := formatSQLiteTime(time.Now())
+	if _, err := tx.ExecContext(ctx, `INSERT INTO shares
+			(id, `+column+`, owner_id, user_id, role, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (`+column+`, user_id) WHERE `+column+` IS NOT NULL DO NOTHING`,
+		uuid.New().String(), invitation.ResourceID, invitation.OwnerID, userID, string(invitation.Role),
+		invitation.InvitedBy, now, now); err != nil {
+		return nil, fmt.Errorf("创建共享失败: %w", err)
+	}
+
+	if below := shareRolesBelow(invitation.Role); len(below) > 0 {
+		args := []any{string(invitation.Role), now, invitation.ResourceID, userID}
+		for _, role := range below {
+			args = append(args, role)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE shares SET role = ?, updated_at = ?
+			WHERE `+column+` = ? AND user_id = ? AND role IN (?`+strings.Repeat(", ?", len(below)-1)+`)`,
+			args...); err != nil {
+			return nil, fmt.Errorf("更新共享的角色失败: %w", err)
+		}
+	}
+
+	share, err := scanSQLiteShare(tx.QueryRowContext(ctx, `SELECT `+sqliteShareColumns+` FROM shares
+		WHERE `+column+` = ? AND user_id = ?`, invitation.ResourceID, userID))
+	if err != nil {
+		return nil, fmt.Errorf("获取共享失败: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("接受邀请失败: %w", err)
+	}
+	return share, nil
+}
+
+// listShares 按条件获取共享，按创建时间排序
+func (r *SQLiteTodoRepository) listShares(ctx context.Context, where string, args ...any) ([]models.Share, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sqliteShareColumns+` FROM shares
+		WHERE `+where+`
+		ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取共享列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	shares := make([]models.Share, 0)
+	for rows.Next() {
+		share, err := scanSQLiteShare(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析共享失败: %w", err)
+		}
+		shares = append(shares, *share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取共享列表失败: %w", err)
+	}
+	return shares, nil
+}
+
+// scanSQLiteAccess 读取资源的所有者和共享角色，资源不存在或没有共享给用户时返回 nil
+func scanSQLiteAccess(rows *sql.Rows, userID string) (*models.Access, error) {
+	defer rows.Close()
+
+	var (
+		ownerID string
+		roles   []models.ShareRole
+		found   bool
+	)
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&ownerID, &role); err != nil {
+			return nil, err
+		}
+		found = true
+		if role != "" {
+			roles = append(roles, models.ShareRole(role))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	access, _ := newAccess(userID, ownerID, roles)
+	return access, nil
+}
+
+// scanSQLiteShare 将一行查询结果扫描为 Share
+func scanSQLiteShare(row sqliteScanner) (*models.Share, error) {
+	var (
+		share                models.Share
+		todoID, projectID    string
+		role                 string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&share.ID, &todoID, &projectID, &share.OwnerID, &share.UserID, &role,
+		&share.CreatedBy, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	share.ResourceType, share.ResourceID = shareResource(todoID, projectID)
+	share.Role = models.ShareRole(role)
+
+	var err error
+	if share.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if share.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// scanSQLiteInvitation 将一行查询结果扫描为 Invitation
+func scanSQLiteInvitation(row sqliteScanner) (*models.Invitation, error) {
+	var (
+		invitation           models.Invitation
+		todoID, projectID    string
+		role                 string
+		createdAt, expiresAt string
+	)
+	if err := row.Scan(&invitation.ID, &todoID, &projectID, &invitation.OwnerID, &invitation.Email, &role,
+		&invitation.TokenHash, &invitation.InvitedBy, &createdAt, &expiresAt); err != nil {
+		return nil, err
+	}
+	invitation.ResourceType, invitation.ResourceID = shareResource(todoID, projectID)
+	invitation.Role = models.ShareRole(role)
+
+	var err error
+	if invitation.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if invitation.ExpiresAt, err = parseSQLiteTime(expiresAt); err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var _ SubtaskRepository = (*SQLiteTodoRepository)(nil)
+
+// sqliteSubtaskColumns 查询子任务时返回的列
+const sqliteSubtaskColumns = `id, todo_id, user_id, title, completed, position, created_at, updated_at`
+
+// ListSubtasks 获取待办事项的所有子任务，按位置排序
+func (r *SQLiteTodoRepository) ListSubtasks(ctx context.Context, userID, todoID string) ([]models.Subtask, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sqliteSubtaskColumns+` FROM subtasks
+		WHERE todo_id = ? AND user_id = ?
+		ORDER BY position, id`, todoID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取子任务列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	subtasks := make([]models.Subtask, 0)
+	for rows.Next() {
+		subtask, err := scanSQLiteSubtask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析子任务失败: %w", err)
+		}
+		subtasks = append(subtasks, *subtask)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取子任务列表失败: %w", err)
+	}
+	return subtasks, nil
+}
+
+// SubtaskProgress 批量统计待办事项的子任务进度
+func (r *SQLiteTodoRepository) SubtaskProgress(ctx context.Context, userID string, todoIDs []string) (map[string]models.SubtaskProgress, error) {
+	result := make(map[string]models.SubtaskProgress)
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	args := make([]any, 0, len(todoIDs)+1)
+	args = append(args, userID)
+	for _, id := range todoIDs {
+		args = append(args, id)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT todo_id, SUM(completed), COUNT(*)
+		FROM subtasks
+		WHERE user_id = ? AND todo_id IN (?`+strings.Repeat(", ?", len(todoIDs)-1)+`)
+		GROUP BY todo_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("统计子任务进度失败: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID   string
+			progress models.SubtaskProgress
+		)
+		if err := rows.Scan(&todoID, &progress.Completed, &progress.Total); err != nil {
+			return nil, fmt.Errorf("解析子任务进度失败: %w", err)
+		}
+		result[todoID] = progress
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("统计子任务进度失败: %w", err)
+	}
+	return result, nil
+}
+
+// CreateSubtask 创建子任务，只有待办事项属于该用户时才会插入
+func (r *SQLiteTodoRepository) CreateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error {
+	logger.WithContext(ctx, r.logger).Debug("创建子任务",
+		zap.String("userID", userID),
+		zap.String("todoID", subtask.TodoID),
+		zap.String("title", subtask.Title))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if subtask.ID == "" {
+		subtask.ID = uuid.New().String()
+	}
+	if subtask.CreatedAt.IsZero() {
+		subtask.CreatedAt = time.Now()
+	}
+	createdAt := formatSQLiteTime(subtask.CreatedAt)
+
+	created, err := scanSQLiteSubtask(r.db.QueryRowContext(ctx, `INSERT INTO subtasks
+			(id, todo_id, user_id, title, completed, position, created_at, updated_at)
+		SELECT ?, id, user_id, ?, ?, ?, ?, ?
+		FROM todos WHERE id = ? AND user_id = ?
+		RETURNING `+sqliteSubtaskColumns,
+		subtask.ID, subtask.Title, subtask.Completed, subtask.Position, createdAt, createdAt,
+		subtask.TodoID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTodoNotFound
+		}
+		return fmt.Errorf("创建子任务失败: %w", err)
+	}
+
+	*subtask = *created
+	return nil
+}
+
+// UpdateSubtask 修改子任务的标题、完成状态和位置
+func (r *SQLiteTodoRepository) UpdateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error {
+	logger.WithContext(ctx, r.logger).Debug("更新子任务",
+		zap.String("userID", userID),
+		zap.String("todoID", subtask.TodoID),
+		zap.String("id", subtask.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	updated, err := scanSQLiteSubtask(r.db.QueryRowContext(ctx, `UPDATE subtasks
+		SET title = ?, completed = ?, position = ?, updated_at = `+sqliteNow+`
+		WHERE id = ? AND todo_id = ? AND user_id = ?
+		RETURNING `+sqliteSubtaskColumns,
+		subtask.Title, subtask.Completed, subtask.Position, subtask.ID, subtask.TodoID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSubtaskNotFound
+		}
+		return fmt.Errorf("更新子任务失败: %w", err)
+	}
+
+	*subtask = *updated
+	return nil
+}
+
+// DeleteSubtask 删除待办事项的一个子任务
+func (r *SQLiteTodoRepository) DeleteSubtask(ctx context.Context, userID, todoID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除子任务",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM subtasks WHERE id = ? AND todo_id = ? AND user_id = ?`,
+		id, todoID, userID)
+	if err != nil {
+		return fmt.Errorf("删除子任务失败: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("获取受影响行数失败: %w", err)
+	} else if affected == 0 {
+		return ErrSubtaskNotFound
+	}
+	return nil
+}
+
+// scanSQLiteSubtask 将一行查询结果扫描为 Subtask
+func scanSQLiteSubtask(row sqliteScanner) (*models.Subtask, error) {
+	var (
+		subtask              models.Subtask
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&subtask.ID, &subtask.TodoID, &subtask.UserID, &subtask.Title, &subtask.Completed,
+		&subtask.Position, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if subtask.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if subtask.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &subtask, nil
+}
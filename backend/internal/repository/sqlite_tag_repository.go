@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteTagColumns 查询标签时返回的列
+const sqliteTagColumns = `id, user_id, name, color, created_at, updated_at`
+
+// ListTags 获取指定用户的所有标签，按名称排序
+func (r *SQLiteTodoRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取标签列表", zap.String("userID", userID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sqliteTagColumns+` FROM tags WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]models.Tag, 0)
+	for rows.Next() {
+		tag, err := scanSQLiteTag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析标签失败: %w", err)
+		}
+		tags = append(tags, *tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+
+	models.SortTags(tags)
+	return tags, nil
+}
+
+// GetTag 获取指定用户的单个标签
+func (r *SQLiteTodoRepository) GetTag(ctx context.Context, userID, id string) (*models.Tag, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tag, err := scanSQLiteTag(r.db.QueryRowContext(ctx,
+		`SELECT `+sqliteTagColumns+` FROM tags WHERE id = ? AND user_id = ?`, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("获取标签失败: %w", err)
+	}
+	return tag, nil
+}
+
+// CreateTag 创建标签，同名标签由唯一约束拒绝
+func (r *SQLiteTodoRepository) CreateTag(ctx context.Context, userID string, tag *models.Tag) error {
+	logger.WithContext(ctx, r.logger).Debug("创建标签",
+		zap.String("userID", userID),
+		zap.String("name", tag.Name))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if tag.ID == "" {
+		tag.ID = uuid.New().String()
+	}
+	if tag.CreatedAt.IsZero() {
+		tag.CreatedAt = time.Now()
+	}
+	createdAt := formatSQLiteTime(tag.CreatedAt)
+
+	created, err := scanSQLiteTag(r.db.QueryRowContext(ctx, `INSERT INTO tags (id, user_id, name, color, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING `+sqliteTagColumns,
+		tag.ID, userID, tag.Name, tag.Color, createdAt, createdAt))
+	if err != nil {
+		if isSQLiteConstraint(err, sqlite3.SQLITE_CONSTRAINT_UNIQUE) || isSQLitePrimaryKeyViolation(err) {
+			return ErrTagAlreadyExists
+		}
+		return fmt.Errorf("创建标签失败: %w", err)
+	}
+
+	*tag = *created
+	return nil
+}
+
+// UpdateTag 修改标签的名称和颜色
+func (r *SQLiteTodoRepository) UpdateTag(ctx context.Context, userID string, tag *models.Tag) error {
+	logger.WithContext(ctx, r.logger).Debug("更新标签",
+		zap.String("userID", userID),
+		zap.String("id", tag.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	updated, err := scanSQLiteTag(r.db.QueryRowContext(ctx, `UPDATE tags SET name = ?, color = ?, updated_at = `+sqliteNow+`
+		WHERE id = ? AND user_id = ?
+		RETURNING `+sqliteTagColumns,
+		tag.Name, tag.Color, tag.ID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTagNotFound
+		}
+		if isSQLiteConstraint(err, sqlite3.SQLITE_CONSTRAINT_UNIQUE) {
+			return ErrTagAlreadyExists
+		}
+		return fmt.Errorf("更新标签失败: %w", err)
+	}
+
+	*tag = *updated
+	return nil
+}
+
+// DeleteTag 删除标签，关联由外键级联删除
+func (r *SQLiteTodoRepository) DeleteTag(ctx context.Context, userID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除标签",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tags WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("删除标签失败: %w", err)
+	}
+	if err := checkSQLiteAffected(result); err != nil {
+		if errors.Is(err, ErrTodoNotFound) {
+			return ErrTagNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// TodoTags 批量获取待办事项的标签
+func (r *SQLiteTodoRepository) TodoTags(ctx context.Context, userID string, todoIDs []string) (map[string][]models.Tag, error) {
+	result := make(map[string][]models.Tag)
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	args := make([]any, 0, len(todoIDs)+1)
+	args = append(args, userID)
+	for _, id := range todoIDs {
+		args = append(args, id)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT tt.todo_id, t.id, t.user_id, t.name, t.color, t.created_at, t.updated_at
+		FROM todo_tags tt JOIN tags t ON t.id = tt.tag_id
+		WHERE tt.user_id = ? AND tt.todo_id IN (?`+strings.Repeat(", ?", len(todoIDs)-1)+`)`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取待办事项的标签失败: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			todoID               string
+			tag                  models.Tag
+			createdAt, updatedAt string
+		)
+		if err := rows.Scan(&todoID, &tag.ID, &tag.UserID, &tag.Name, &tag.Color, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("解析标签失败: %w", err)
+		}
+		if tag.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+			return nil, err
+		}
+		if tag.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+			return nil, err
+		}
+		result[todoID] = append(result[todoID], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取待办事项的标签失败: %w", err)
+	}
+
+	for _, tags := range result {
+		models.SortTags(tags)
+	}
+	return result, nil
+}
+
+// SetTodoTags 在一个事务中删除待办事项原有的关联并添加新的关联
+func (r *SQLiteTodoRepository) SetTodoTags(ctx context.Context, userID, todoID string, tagIDs []string) error {
+	logger.WithContext(ctx, r.logger).Debug("设置待办事项的标签",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.Strings("tagIDs", tagIDs))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := sqliteTodoExists(ctx, tx, userID, todoID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM todo_tags WHERE todo_id = ?`, todoID); err != nil {
+		return fmt.Errorf("设置待办事项的标签失败: %w", err)
+	}
+	for _, tagID := range tagIDs {
+		if err := insertSQLiteTodoTag(ctx, tx, userID, todoID, tagID); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("设置待办事项的标签失败: %w", err)
+	}
+	return nil
+}
+
+// AddTodoTag 给待办事项添加一个标签
+func (r *SQLiteTodoRepository) AddTodoTag(ctx context.Context, userID, todoID, tagID string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := sqliteTodoExists(ctx, tx, userID, todoID); err != nil {
+		return err
+	}
+	if err := insertSQLiteTodoTag(ctx, tx, userID, todoID, tagID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("添加待办事项的标签失败: %w", err)
+	}
+	return nil
+}
+
+// RemoveTodoTag 去掉待办事项的一个标签
+func (r *SQLiteTodoRepository) RemoveTodoTag(ctx context.Context, userID, todoID, tagID string) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := sqliteTodoExists(ctx, tx, userID, todoID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM todo_tags WHERE todo_id = ? AND tag_id = ?`, todoID, tagID); err != nil {
+		return fmt.Errorf("去掉待办事项的标签失败: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("去掉待办事项的标签失败: %w", err)
+	}
+	return nil
+}
+
+// sqliteTodoExists 待办事项不存在时返回 ErrTodoNotFound
+func sqliteTodoExists(ctx context.Context, tx *sql.Tx, userID, todoID string) error {
+	var exists int
+	err := tx.QueryRowContext(ctx, `SELECT 1 FROM todos WHERE id = ? AND user_id = ?`, todoID, userID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTodoNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("获取待办事项失败: %w", err)
+	}
+	return nil
+}
+
+// insertSQLiteTodoTag 添加一条关联，已存在时忽略。待办事项已在同一事务中确认存在，
+// 外键冲突说明标签不存在
+func insertSQLiteTodoTag(ctx context.Context, tx *sql.Tx, userID, todoID, tagID string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO todo_tags (todo_id, tag_id, user_id)
+		VALUES (?, ?, ?)
+		ON CONFLICT (todo_id, tag_id) DO NOTHING`, todoID, tagID, userID)
+	if err != nil {
+		if isSQLiteConstraint(err, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY) {
+			return ErrTagNotFound
+		}
+		return fmt.Errorf("添加待办事项的标签失败: %w", err)
+	}
+	return nil
+}
+
+// scanSQLiteTag 将一行查询结果扫描为 Tag
+func scanSQLiteTag(row sqliteScanner) (*models.Tag, error) {
+	var (
+		tag                  models.Tag
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.Color, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if tag.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if tag.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// isSQLiteConstraint 判断是否为指定类型的约束冲突
+func isSQLiteConstraint(err error, code int) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == code
+}
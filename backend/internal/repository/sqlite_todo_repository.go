@@ -0,0 +1,741 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	// sqliteTimeLayout SQLite 中时间的存储格式，与 strftime('%Y-%m-%dT%H:%M:%fZ') 保持一致，
+	// 固定宽度的 UTC 文本可以直接按字典序比较和排序
+	sqliteTimeLayout = "2006-01-02T15:04:05.000Z"
+
+	// sqliteNow SQL 中获取当前时间的表达式
+	sqliteNow = `strftime('%Y-%m-%dT%H:%M:%fZ', 'now')`
+)
+
+// sqliteSchema SQLite 的建表语句，按版本顺序执行，已执行的版本记录在 PRAGMA user_version 中。
+// 结构与 migrations/001_create_todos_table.sql 保持一致。
+var sqliteSchema = []string{
+	// 1: 创建 todos 表
+	`CREATE TABLE IF NOT EXISTS todos (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		title TEXT NOT NULL,
+		completed INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		updated_at TEXT NOT NULL DEFAULT (` + sqliteNow + `)
+	);
+
+	` + sqliteUpdatedAtTrigger + `
+
+	CREATE INDEX IF NOT EXISTS idx_todos_completed ON todos(completed);
+	CREATE INDEX IF NOT EXISTS idx_todos_created_at ON todos(created_at);
+	CREATE INDEX IF NOT EXISTS idx_todos_user_id ON todos(user_id);
+	CREATE INDEX IF NOT EXISTS idx_todos_completed_created_at ON todos(completed, created_at DESC);`,
+
+	// 2: 列表分页使用的复合索引
+	`CREATE INDEX IF NOT EXISTS idx_todos_user_created_at ON todos(user_id, created_at, id);
+	CREATE INDEX IF NOT EXISTS idx_todos_user_updated_at ON todos(user_id, updated_at, id);
+	CREATE INDEX IF NOT EXISTS idx_todos_user_title ON todos(user_id, title, id);`,
+
+	// 3: 截止时间和提醒时间
+	`ALTER TABLE todos ADD COLUMN due_at TEXT;
+	ALTER TABLE todos ADD COLUMN remind_at TEXT;
+	CREATE INDEX IF NOT EXISTS idx_todos_user_due_at ON todos(user_id, due_at) WHERE due_at IS NOT NULL;
+	CREATE INDEX IF NOT EXISTS idx_todos_remind_at ON todos(remind_at) WHERE remind_at IS NOT NULL AND completed = 0;`,
+
+	// 4: 提醒状态和站内通知
+	`CREATE TABLE IF NOT EXISTS todo_reminders (
+		todo_id TEXT PRIMARY KEY REFERENCES todos(id) ON DELETE CASCADE,
+		reminded_for TEXT,
+		lease_owner TEXT,
+		lease_until TEXT,
+		attempts INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE IF NOT EXISTS notifications (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		todo_id TEXT REFERENCES todos(id) ON DELETE SET NULL,
+		kind TEXT NOT NULL,
+		title TEXT NOT NULL,
+		due_at TEXT,
+		key TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		read_at TEXT,
+		UNIQUE (user_id, key)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notifications_user_created_at ON notifications(user_id, created_at, id);
+	CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_id) WHERE read_at IS NULL;`,
+
+	// 5: 重复系列
+	`ALTER TABLE todos ADD COLUMN series_id TEXT;
+	ALTER TABLE todos ADD COLUMN recurrence_rule TEXT;
+	ALTER TABLE todos ADD COLUMN recurrence_timezone TEXT;
+	ALTER TABLE todos ADD COLUMN recurrence_start TEXT;
+	CREATE INDEX IF NOT EXISTS idx_todos_user_series ON todos(user_id, series_id) WHERE series_id IS NOT NULL;`,
+
+	// 6: 优先级和手动排序的位置。已有的待办事项按创建时间分配位置，与 008 迁移相同，
+	// 回填时暂时去掉触发器，避免刷新 updated_at
+	`ALTER TABLE todos ADD COLUMN priority INTEGER NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 4);
+	ALTER TABLE todos ADD COLUMN position TEXT NOT NULL DEFAULT '';
+	DROP TRIGGER IF EXISTS update_todos_updated_at;
+	UPDATE todos SET position = (
+		SELECT printf('%012dV', r.rn)
+		FROM (SELECT id, row_number() OVER (PARTITION BY user_id ORDER BY created_at, id) AS rn FROM todos) r
+		WHERE r.id = todos.id
+	);
+	` + sqliteUpdatedAtTrigger + `
+	CREATE INDEX IF NOT EXISTS idx_todos_user_position ON todos(user_id, position, id);
+	CREATE INDEX IF NOT EXISTS idx_todos_user_priority ON todos(user_id, priority DESC, position, id);`,
+
+	// 7: 标签和待办事项与标签的关联，同一用户的标签名称不区分大小写唯一
+	`CREATE TABLE IF NOT EXISTS tags (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL COLLATE NOCASE,
+		color TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		updated_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		UNIQUE (user_id, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS todo_tags (
+		todo_id TEXT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+		tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+		user_id TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		PRIMARY KEY (todo_id, tag_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_todo_tags_tag ON todo_tags(tag_id, todo_id);`,
+
+	// 8: 项目，每个用户最多一个收件箱。删除项目时级联删除其中的待办事项，
+	// 已有的待办事项在创建收件箱时移入收件箱
+	`CREATE TABLE IF NOT EXISTS projects (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		color TEXT NOT NULL,
+		icon TEXT NOT NULL DEFAULT '',
+		archived INTEGER NOT NULL DEFAULT 0,
+		is_inbox INTEGER NOT NULL DEFAULT 0,
+		position TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		updated_at TEXT NOT NULL DEFAULT (` + sqliteNow + `)
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_user_inbox ON projects(user_id) WHERE is_inbox = 1;
+	CREATE INDEX IF NOT EXISTS idx_projects_user_position ON projects(user_id, position, id);
+
+	ALTER TABLE todos ADD COLUMN project_id TEXT REFERENCES projects(id) ON DELETE CASCADE;
+	CREATE INDEX IF NOT EXISTS idx_todos_project ON todos(project_id, completed);`,
+
+	// 9: 子任务，删除待办事项时级联删除；auto_complete 为 1 时子任务全部完成后自动完成待办事项
+	`CREATE TABLE IF NOT EXISTS subtasks (
+		id TEXT PRIMARY KEY,
+		todo_id TEXT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+		user_id TEXT NOT NULL,
+		title TEXT NOT NULL,
+		completed INTEGER NOT NULL DEFAULT 0,
+		position TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		updated_at TEXT NOT NULL DEFAULT (` + sqliteNow + `)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_subtasks_todo_position ON subtasks(todo_id, position, id);
+
+	ALTER TABLE todos ADD COLUMN auto_complete INTEGER NOT NULL DEFAULT 0;`,
+
+	// 10: Markdown 格式的备注
+	`ALTER TABLE todos ADD COLUMN notes TEXT NOT NULL DEFAULT '';`,
+
+	// 11: 附件的元数据，删除待办事项时 todo_id 置空，文件由清理任务删除
+	`CREATE TABLE IF NOT EXISTS attachments (
+		id TEXT PRIMARY KEY,
+		todo_id TEXT REFERENCES todos(id) ON DELETE SET NULL,
+		user_id TEXT NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		storage_key TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_attachments_todo_created_at ON attachments(todo_id, created_at, id);
+	CREATE INDEX IF NOT EXISTS idx_attachments_detached ON attachments(created_at) WHERE todo_id IS NULL;`,
+
+	// 12: 评论，删除待办事项时级联删除
+	`CREATE TABLE IF NOT EXISTS comments (
+		id TEXT PRIMARY KEY,
+		todo_id TEXT NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+		user_id TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		updated_at TEXT NOT NULL DEFAULT (` + sqliteNow + `)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_comments_todo_created_at ON comments(todo_id, created_at, id);`,
+
+	// 13: 共享和邀请，todo_id 和 project_id 有且只有一个不为空，删除资源时级联删除
+	`CREATE TABLE IF NOT EXISTS shares (
+		id TEXT PRIMARY KEY,
+		todo_id TEXT REFERENCES todos(id) ON DELETE CASCADE,
+		project_id TEXT REFERENCES projects(id) ON DELETE CASCADE,
+		owner_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		created_by TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		updated_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		CHECK ((todo_id IS NULL) <> (project_id IS NULL))
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_shares_todo_user ON shares(todo_id, user_id) WHERE todo_id IS NOT NULL;
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_shares_project_user ON shares(project_id, user_id) WHERE project_id IS NOT NULL;
+	CREATE INDEX IF NOT EXISTS idx_shares_user ON shares(user_id, created_at);
+
+	CREATE TABLE IF NOT EXISTS invitations (
+		id TEXT PRIMARY KEY,
+		todo_id TEXT REFERENCES todos(id) ON DELETE CASCADE,
+		project_id TEXT REFERENCES projects(id) ON DELETE CASCADE,
+		owner_id TEXT NOT NULL,
+		email TEXT NOT NULL,
+		role TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		invited_by TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		expires_at TEXT NOT NULL,
+		CHECK ((todo_id IS NULL) <> (project_id IS NULL))
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_invitations_todo ON invitations(todo_id) WHERE todo_id IS NOT NULL;
+	CREATE INDEX IF NOT EXISTS idx_invitations_project ON invitations(project_id) WHERE project_id IS NOT NULL;`,
+
+	// 14: 工作区和成员，工作区的数据以工作区 ID 作为 user_id 保存，删除工作区时由仓库层一并删除
+	`CREATE TABLE IF NOT EXISTS workspaces (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		created_by TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		updated_at TEXT NOT NULL DEFAULT (` + sqliteNow + `)
+	);
+
+	CREATE TABLE IF NOT EXISTS workspace_members (
+		workspace_id TEXT NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+		user_id TEXT NOT NULL,
+		role TEXT NOT NULL,
+		created_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		updated_at TEXT NOT NULL DEFAULT (` + sqliteNow + `),
+		PRIMARY KEY (workspace_id, user_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_workspace_members_user ON workspace_members(user_id);`,
+}
+
+// sqliteUpdatedAtTrigger 更新时自动刷新 updated_at，除非语句本身已经修改了它
+const sqliteUpdatedAtTrigger = `CREATE TRIGGER IF NOT EXISTS update_todos_updated_at
+		AFTER UPDATE ON todos
+		FOR EACH ROW
+		WHEN NEW.updated_at = OLD.updated_at
+	BEGIN
+		UPDATE todos SET updated_at = ` + sqliteNow + ` WHERE id = NEW.id;
+	END;`
+
+// sqliteTodoColumns 查询待办事项时返回的列
+const sqliteTodoColumns = `id, user_id, title, completed, due_at, remind_at,
+	series_id, recurrence_rule, recurrence_timezone, recurrence_start, priority, position,
+	project_id, auto_complete, notes, created_at, updated_at`
+
+// sqliteTodoStatements 启动时预编译的语句
+var sqliteTodoStatements = map[string]string{
+	"get": `SELECT ` + sqliteTodoColumns + ` FROM todos
+		WHERE id = ? AND user_id = ?`,
+	"create": `INSERT INTO todos (id, user_id, title, completed, due_at, remind_at,
+			series_id, recurrence_rule, recurrence_timezone, recurrence_start, priority, position,
+			project_id, auto_complete, notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	"update": `UPDATE todos SET title = ?, completed = ?, due_at = ?, remind_at = ?,
+			series_id = ?, recurrence_rule = ?, recurrence_timezone = ?, recurrence_start = ?, priority = ?,
+			project_id = ?, auto_complete = ?, notes = ?
+		WHERE id = ? AND user_id = ?`,
+	"move": `UPDATE todos SET position = ?
+		WHERE id = ? AND user_id = ?`,
+	"toggle": `UPDATE todos SET completed = NOT completed
+		WHERE id = ? AND user_id = ?`,
+	"delete": `DELETE FROM todos WHERE id = ? AND user_id = ?`,
+}
+
+// SQLiteTodoRepository 是一个使用嵌入式 SQLite 实现的 TodoRepository
+type SQLiteTodoRepository struct {
+	db      *sql.DB
+	stmts   map[string]*sql.Stmt
+	timeout time.Duration
+	logger  *zap.Logger
+}
+
+// NewSQLiteTodoRepository 创建一个新的 SQLiteTodoRepository，并自动创建表结构
+func NewSQLiteTodoRepository(cfg *config.Config) (*SQLiteTodoRepository, error) {
+	path := cfg.Database.Path
+
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("创建数据库目录失败: %w", err)
+		}
+	}
+
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("打开 SQLite 数据库失败: %w", err)
+	}
+
+	// SQLite 同一时间只允许一个写入者，单连接可以避免 SQLITE_BUSY，也让 :memory: 数据库可用
+	db.SetMaxOpenConns(1)
+
+	timeout := operationTimeout(cfg.Database.OperationTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := migrateSQLiteSchema(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	stmts := make(map[string]*sql.Stmt, len(sqliteTodoStatements))
+	for name, query := range sqliteTodoStatements {
+		stmt, err := db.PrepareContext(ctx, query)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("准备语句 %s 失败: %w", name, err)
+		}
+		stmts[name] = stmt
+	}
+
+	logger.Info("初始化 SQLite Todo Repository", zap.String("path", path))
+
+	return &SQLiteTodoRepository{
+		db:      db,
+		stmts:   stmts,
+		timeout: timeout,
+		logger:  logger.Log.With(zap.String("component", "SQLiteTodoRepository")),
+	}, nil
+}
+
+// migrateSQLiteSchema 执行尚未应用的建表语句
+func migrateSQLiteSchema(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("读取数据库版本失败: %w", err)
+	}
+
+	for i := version; i < len(sqliteSchema); i++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("开始事务失败: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, sqliteSchema[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("执行数据库结构版本 %d 失败: %w", i+1, err)
+		}
+		// PRAGMA 不支持参数绑定
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("更新数据库版本失败: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("提交数据库结构版本 %d 失败: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Close 关闭数据库连接
+func (r *SQLiteTodoRepository) Close() error {
+	for _, stmt := range r.stmts {
+		stmt.Close()
+	}
+	return r.db.Close()
+}
+
+// List 按过滤条件、排序和游标分页获取指定用户的待办事项
+func (r *SQLiteTodoRepository) List(ctx context.Context, userID string, opts models.TodoListOptions) (*models.TodoPage, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取待办事项列表",
+		zap.String("userID", userID),
+		zap.String("sortBy", string(opts.SortBy)),
+		zap.String("sortDir", string(opts.SortDir)),
+		zap.Int("limit", opts.Limit))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := newTodoListQuery(sqliteDialect, userID, opts.Filter)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, query.countSQL(), query.args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("统计待办事项失败: %w", err)
+	}
+
+	sql, args, err := query.pageSQL(sqliteTodoColumns, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取待办事项列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	todos := make([]models.Todo, 0, opts.Limit+1)
+	for rows.Next() {
+		todo, err := scanSQLiteTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析待办事项列表失败: %w", err)
+		}
+		todos = append(todos, *todo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取待办事项列表失败: %w", err)
+	}
+
+	return newTodoPage(todos, total, opts), nil
+}
+
+// Search 按相关度搜索指定用户的待办事项。
+// SQLite 没有三元组索引，读取该用户符合过滤条件的全部记录后在 Go 中打分。
+func (r *SQLiteTodoRepository) Search(ctx context.Context, userID string, opts models.TodoSearchOptions) (*models.TodoSearchPage, error) {
+	logger.WithContext(ctx, r.logger).Debug("搜索待办事项",
+		zap.String("userID", userID),
+		zap.String("query", opts.Query),
+		zap.Int("limit", opts.Limit))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	query := newTodoListQuery(sqliteDialect, userID, models.TodoFilter{Completed: opts.Completed})
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+sqliteTodoColumns+" FROM todos"+query.whereClause(), query.args...)
+	if err != nil {
+		return nil, fmt.Errorf("搜索待办事项失败: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []models.Todo
+	for rows.Next() {
+		todo, err := scanSQLiteTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析待办事项列表失败: %w", err)
+		}
+		candidates = append(candidates, *todo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("搜索待办事项失败: %w", err)
+	}
+
+	return searchTodos(candidates, opts)
+}
+
+// Get 获取指定用户的单个待办事项
+func (r *SQLiteTodoRepository) Get(ctx context.Context, userID, id string) (*models.Todo, error) {
+	logger.WithContext(ctx, r.logger).Debug("获取待办事项",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.get(ctx, userID, id)
+}
+
+// get 在给定上下文中查询单个待办事项
+func (r *SQLiteTodoRepository) get(ctx context.Context, userID, id string) (*models.Todo, error) {
+	todo, err := scanSQLiteTodo(r.stmts["get"].QueryRowContext(ctx, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTodoNotFound
+		}
+		return nil, fmt.Errorf("获取待办事项失败: %w", err)
+	}
+	return todo, nil
+}
+
+// Create 创建一个新的待办事项
+func (r *SQLiteTodoRepository) Create(ctx context.Context, userID string, todo *models.Todo) error {
+	logger.WithContext(ctx, r.logger).Debug("创建待办事项",
+		zap.String("userID", userID),
+		zap.String("title", todo.Title))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if todo.ID == "" {
+		todo.ID = uuid.New().String()
+	}
+	todo.UserID = userID
+
+	now := time.Now()
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = now
+	}
+	todo.UpdatedAt = todo.CreatedAt
+
+	seriesID, rule, timezone, start := recurrenceArgs(todo)
+	_, err := r.stmts["create"].ExecContext(ctx,
+		todo.ID,
+		todo.UserID,
+		todo.Title,
+		todo.Completed,
+		formatSQLiteNullTime(todo.DueAt),
+		formatSQLiteNullTime(todo.RemindAt),
+		seriesID,
+		rule,
+		timezone,
+		formatSQLiteNullTime(start),
+		int(todo.Priority),
+		todo.Position,
+		projectArg(todo),
+		todo.AutoComplete,
+		todo.Notes,
+		formatSQLiteTime(todo.CreatedAt),
+		formatSQLiteTime(todo.UpdatedAt),
+	)
+	if err != nil {
+		if isSQLitePrimaryKeyViolation(err) {
+			return ErrTodoAlreadyExists
+		}
+		if isSQLiteConstraint(err, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY) {
+			return ErrProjectNotFound
+		}
+		return fmt.Errorf("创建待办事项失败: %w", err)
+	}
+
+	created, err := r.get(ctx, userID, todo.ID)
+	if err != nil {
+		return err
+	}
+
+	*todo = *created
+	return nil
+}
+
+// Update 更新待办事项
+func (r *SQLiteTodoRepository) Update(ctx context.Context, userID string, todo *models.Todo) error {
+	logger.WithContext(ctx, r.logger).Debug("更新待办事项",
+		zap.String("userID", userID),
+		zap.String("id", todo.ID),
+		zap.String("title", todo.Title))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	seriesID, rule, timezone, start := recurrenceArgs(todo)
+	result, err := r.stmts["update"].ExecContext(ctx, todo.Title, todo.Completed,
+		formatSQLiteNullTime(todo.DueAt), formatSQLiteNullTime(todo.RemindAt),
+		seriesID, rule, timezone, formatSQLiteNullTime(start), int(todo.Priority), projectArg(todo), todo.AutoComplete,
+		todo.Notes, todo.ID, userID)
+	if err != nil {
+		if isSQLiteConstraint(err, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY) {
+			return ErrProjectNotFound
+		}
+		return fmt.Errorf("更新待办事项失败: %w", err)
+	}
+	if err := checkSQLiteAffected(result); err != nil {
+		return err
+	}
+
+	// 触发器会刷新 updated_at，重新读取以返回最新的数据
+	updated, err := r.get(ctx, userID, todo.ID)
+	if err != nil {
+		return err
+	}
+
+	*todo = *updated
+	return nil
+}
+
+// Move 修改待办事项的位置
+func (r *SQLiteTodoRepository) Move(ctx context.Context, userID, id, position string) (*models.Todo, error) {
+	logger.WithContext(ctx, r.logger).Debug("移动待办事项",
+		zap.String("userID", userID),
+		zap.String("id", id),
+		zap.String("position", position))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.stmts["move"].ExecContext(ctx, position, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("移动待办事项失败: %w", err)
+	}
+	if err := checkSQLiteAffected(result); err != nil {
+		return nil, err
+	}
+
+	// 触发器会刷新 updated_at，重新读取以返回最新的数据
+	return r.get(ctx, userID, id)
+}
+
+// Toggle 切换待办事项的完成状态
+func (r *SQLiteTodoRepository) Toggle(ctx context.Context, userID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("切换待办事项状态",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.stmts["toggle"].ExecContext(ctx, id, userID)
+	if err != nil {
+		return fmt.Errorf("切换待办事项状态失败: %w", err)
+	}
+
+	return checkSQLiteAffected(result)
+}
+
+// Delete 删除待办事项
+func (r *SQLiteTodoRepository) Delete(ctx context.Context, userID, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除待办事项",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.stmts["delete"].ExecContext(ctx, id, userID)
+	if err != nil {
+		return fmt.Errorf("删除待办事项失败: %w", err)
+	}
+
+	return checkSQLiteAffected(result)
+}
+
+// sqliteScanner 抽象 *sql.Row 与 *sql.Rows 的 Scan 方法
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSQLiteTodo 将一行查询结果扫描为 Todo
+func scanSQLiteTodo(row sqliteScanner) (*models.Todo, error) {
+	var (
+		todo                  models.Todo
+		dueAt, remindAt       sql.NullString
+		seriesID, projectID   sql.NullString
+		rule, timezone, start *string
+		createdAt, updatedAt  string
+	)
+	if err := row.Scan(
+		&todo.ID,
+		&todo.UserID,
+		&todo.Title,
+		&todo.Completed,
+		&dueAt,
+		&remindAt,
+		&seriesID,
+		&rule,
+		&timezone,
+		&start,
+		&todo.Priority,
+		&todo.Position,
+		&projectID,
+		&todo.AutoComplete,
+		&todo.Notes,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if todo.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if todo.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+	if todo.DueAt, err = parseSQLiteNullTime(dueAt); err != nil {
+		return nil, err
+	}
+	if todo.RemindAt, err = parseSQLiteNullTime(remindAt); err != nil {
+		return nil, err
+	}
+	todo.SeriesID = seriesID.String
+	todo.ProjectID = projectID.String
+	if start != nil {
+		startAt, err := parseSQLiteTime(*start)
+		if err != nil {
+			return nil, err
+		}
+		todo.Recurrence = newRecurrence(rule, timezone, &startAt)
+	}
+
+	return &todo, nil
+}
+
+// checkSQLiteAffected 没有行受影响时返回 ErrTodoNotFound
+func checkSQLiteAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("获取受影响行数失败: %w", err)
+	}
+	if affected == 0 {
+		return ErrTodoNotFound
+	}
+	return nil
+}
+
+// isSQLitePrimaryKeyViolation 判断是否为主键冲突
+func isSQLitePrimaryKeyViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// formatSQLiteTime 将时间格式化为 SQLite 中的存储格式
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+// formatSQLiteNullTime 格式化可选的时间，nil 存储为 NULL
+func formatSQLiteNullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatSQLiteTime(*t)
+}
+
+// parseSQLiteNullTime 解析可选的时间，NULL 返回 nil
+func parseSQLiteNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseSQLiteTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseSQLiteTime 解析 SQLite 中存储的时间
+func parseSQLiteTime(s string) (time.Time, error) {
+	t, err := time.Parse(sqliteTimeLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析时间 %q 失败: %w", s, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/models"
+)
+
+// newSQLiteRepository 打开 path 处的 SQLite 仓库，测试结束时关闭
+func newSQLiteRepository(t *testing.T, path string) *SQLiteTodoRepository {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Database.Path = path
+	repo, err := NewSQLiteTodoRepository(cfg)
+	if err != nil {
+		t.Fatalf("NewSQLiteTodoRepository(%q) error = %v", path, err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+// schemaVersion 返回数据库记录的结构版本
+func schemaVersion(t *testing.T, db *sql.DB) int {
+	t.Helper()
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		t.Fatalf("PRAGMA user_version error = %v", err)
+	}
+	return version
+}
+
+func TestSQLiteSchemaBootstrap(t *testing.T) {
+	repo := newSQLiteRepository(t, ":memory:")
+	if got := schemaVersion(t, repo.db); got != len(sqliteSchema) {
+		t.Errorf("user_version = %d, want %d", got, len(sqliteSchema))
+	}
+
+	// 已经是最新版本的数据库再次打开时不重复执行建表语句，数据保持不变
+	path := filepath.Join(t.TempDir(), "todos.db")
+	first := newSQLiteRepository(t, path)
+	todo := &models.Todo{Title: "保留", Position: "V"}
+	if err := first.Create(context.Background(), "user-1", todo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	first.Close()
+
+	second := newSQLiteRepository(t, path)
+	if got := schemaVersion(t, second.db); got != len(sqliteSchema) {
+		t.Errorf("user_version after reopen = %d, want %d", got, len(sqliteSchema))
+	}
+	if _, err := second.Get(context.Background(), "user-1", todo.ID); err != nil {
+		t.Errorf("Get() after reopen error = %v", err)
+	}
+}
+
+func TestSQLiteCRUD(t *testing.T) {
+	repo := newSQLiteRepository(t, ":memory:")
+	ctx := context.Background()
+
+	due := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+	remind := due.Add(-time.Hour)
+	todo := &models.Todo{
+		Title:      "周报",
+		DueAt:      &due,
+		RemindAt:   &remind,
+		SeriesID:   "series-1",
+		Recurrence: &models.Recurrence{Rule: "FREQ=WEEKLY", Timezone: "Asia/Shanghai", Start: due},
+		Priority:   models.PriorityHigh,
+		Position:   "V",
+		Notes:      "**备注**",
+	}
+	if err := repo.Create(ctx, "user-1", todo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if todo.ID == "" || todo.CreatedAt.IsZero() {
+		t.Fatalf("Create() todo = %+v, want generated ID and timestamps", todo)
+	}
+	if err := repo.Create(ctx, "user-2", &models.Todo{ID: todo.ID, Title: "重复", Position: "V"}); err != ErrTodoAlreadyExists {
+		t.Errorf("Create(duplicate id) error = %v, want %v", err, ErrTodoAlreadyExists)
+	}
+
+	got, err := repo.Get(ctx, "user-1", todo.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Title != "周报" || got.UserID != "user-1" || got.Priority != models.PriorityHigh || got.Position != "V" || got.Notes != "**备注**" {
+		t.Errorf("Get() = %+v, want the created todo", got)
+	}
+	if got.DueAt == nil || !got.DueAt.Equal(due) || got.RemindAt == nil || !got.RemindAt.Equal(remind) {
+		t.Errorf("Get() times = %v, %v, want %v, %v", got.DueAt, got.RemindAt, due, remind)
+	}
+	if got.SeriesID != "series-1" || got.Recurrence == nil || got.Recurrence.Rule != "FREQ=WEEKLY" ||
+		got.Recurrence.Timezone != "Asia/Shanghai" || !got.Recurrence.Start.Equal(due) {
+		t.Errorf("Get() recurrence = %q, %+v, want series-1 weekly", got.SeriesID, got.Recurrence)
+	}
+	if _, err := repo.Get(ctx, "user-2", todo.ID); err != ErrTodoNotFound {
+		t.Errorf("Get(other user) error = %v, want %v", err, ErrTodoNotFound)
+	}
+
+	got.Title = "月报"
+	got.DueAt = nil
+	got.Recurrence = nil
+	if err := repo.Update(ctx, "user-1", got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := repo.Toggle(ctx, "user-1", todo.ID); err != nil {
+		t.Fatalf("Toggle() error = %v", err)
+	}
+	moved, err := repo.Move(ctx, "user-1", todo.ID, "W")
+	if err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+	if moved.Title != "月报" || !moved.Completed || moved.DueAt != nil || moved.Recurrence != nil || moved.Position != "W" {
+		t.Errorf("after Update, Toggle and Move = %+v, want completed 月报 at W without due date", moved)
+	}
+	if !moved.CreatedAt.Equal(todo.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want unchanged %v", moved.CreatedAt, todo.CreatedAt)
+	}
+
+	if err := repo.Update(ctx, "user-2", got); err != ErrTodoNotFound {
+		t.Errorf("Update(other user) error = %v, want %v", err, ErrTodoNotFound)
+	}
+	if err := repo.Delete(ctx, "user-2", todo.ID); err != ErrTodoNotFound {
+		t.Errorf("Delete(other user) error = %v, want %v", err, ErrTodoNotFound)
+	}
+	if err := repo.Delete(ctx, "user-1", todo.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := repo.Get(ctx, "user-1", todo.ID); err != ErrTodoNotFound {
+		t.Errorf("Get() after Delete error = %v, want %v", err, ErrTodoNotFound)
+	}
+}
+
+func TestSQLiteListCursor(t *testing.T) {
+	repo := newSQLiteRepository(t, ":memory:")
+	ctx := context.Background()
+
+	// 标题有重复，翻页时按 ID 区分
+	titles := []string{"d", "a", "c", "b", "a", "e", "c"}
+	for i, title := range titles {
+		todo := &models.Todo{Title: title, Completed: i%2 == 1, Position: fmt.Sprintf("%c", 'A'+i)}
+		if err := repo.Create(ctx, "user-1", todo); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if err := repo.Create(ctx, "user-2", &models.Todo{Title: "other", Position: "V"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		sortDir   models.SortDirection
+		completed *bool
+		want      []string
+	}{
+		{"ascending", models.SortAsc, nil, []string{"a", "a", "b", "c", "c", "d", "e"}},
+		{"descending", models.SortDesc, nil, []string{"e", "d", "c", "c", "b", "a", "a"}},
+		{"completed only", models.SortAsc, ptr(true), []string{"a", "b", "e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got   []string
+				seen  = make(map[string]bool)
+				after *models.Cursor
+			)
+			for pages := 0; ; pages++ {
+				if pages > len(titles) {
+					t.Fatalf("paging did not terminate, got %v", got)
+				}
+				page, err := repo.List(ctx, "user-1", models.TodoListOptions{
+					Filter:  models.TodoFilter{Completed: tt.completed},
+					SortBy:  models.SortByTitle,
+					SortDir: tt.sortDir,
+					Limit:   2,
+					After:   after,
+				})
+				if err != nil {
+					t.Fatalf("List() error = %v", err)
+				}
+				if page.Total != len(tt.want) {
+					t.Errorf("Total = %d, want %d", page.Total, len(tt.want))
+				}
+				for _, todo := range page.Items {
+					if seen[todo.ID] {
+						t.Errorf("todo %s returned twice", todo.ID)
+					}
+					seen[todo.ID] = true
+					got = append(got, todo.Title)
+				}
+				if page.Next == nil {
+					break
+				}
+				after = page.Next
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("titles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteSearch(t *testing.T) {
+	repo := newSQLiteRepository(t, ":memory:")
+	ctx := context.Background()
+
+	for _, todo := range []*models.Todo{
+		{Title: "weekly report", Position: "A"},
+		{Title: "groceries", Notes: "attach the report", Position: "B", Completed: true},
+		{Title: "call mom", Position: "C"},
+	} {
+		if err := repo.Create(ctx, "user-1", todo); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	if err := repo.Create(ctx, "user-2", &models.Todo{Title: "report", Position: "V"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	page, err := repo.Search(ctx, "user-1", models.TodoSearchOptions{Query: "report", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if page.Total != 2 || len(page.Hits) != 2 {
+		t.Fatalf("Search() = %+v, want 2 hits of user-1", page)
+	}
+	for _, hit := range page.Hits {
+		if hit.Todo.UserID != "user-1" || hit.Score < 1 {
+			t.Errorf("hit = %+v, want user-1 substring match", hit)
+		}
+	}
+
+	page, err = repo.Search(ctx, "user-1", models.TodoSearchOptions{Query: "report", Completed: ptr(false), Limit: 10})
+	if err != nil {
+		t.Fatalf("Search(completed=false) error = %v", err)
+	}
+	if len(page.Hits) != 1 || page.Hits[0].Todo.Title != "weekly report" {
+		t.Errorf("Search(completed=false) = %+v, want weekly report", page.Hits)
+	}
+}
+
+func TestSQLiteBackfillPositions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.db")
+
+	// 按版本 6 之前的结构创建数据库文件并写入没有位置的待办事项
+	db, err := sql.Open("sqlite", "file:"+path)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	const before = 5
+	for i := 0; i < before; i++ {
+		if _, err := db.Exec(sqliteSchema[i]); err != nil {
+			t.Fatalf("schema %d error = %v", i+1, err)
+		}
+	}
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", before)); err != nil {
+		t.Fatalf("PRAGMA user_version error = %v", err)
+	}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	updatedAt := formatSQLiteTime(base.Add(-time.Hour))
+	rows := []struct {
+		id, userID string
+		createdAt  time.Time
+	}{
+		{"a3", "user-a", base.Add(3 * time.Hour)},
+		{"b1", "user-b", base.Add(1 * time.Hour)},
+		{"a2", "user-a", base.Add(1 * time.Hour)},
+		{"a1", "user-a", base.Add(1 * time.Hour)},
+	}
+	for _, row := range rows {
+		if _, err := db.Exec(`INSERT INTO todos (id, user_id, title, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`,
+			row.id, row.userID, row.id, formatSQLiteTime(row.createdAt), updatedAt); err != nil {
+			t.Fatalf("insert %s error = %v", row.id, err)
+		}
+	}
+	db.Close()
+
+	repo := newSQLiteRepository(t, path)
+	if got := schemaVersion(t, repo.db); got != len(sqliteSchema) {
+		t.Errorf("user_version = %d, want %d", got, len(sqliteSchema))
+	}
+
+	// 每个用户按创建时间和 ID 分配位置，回填不刷新 updated_at
+	want := map[string]string{"a1": "000000000001V", "a2": "000000000002V", "a3": "000000000003V", "b1": "000000000001V"}
+	for _, row := range rows {
+		got, err := repo.Get(context.Background(), row.userID, row.id)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", row.id, err)
+		}
+		if got.Position != want[row.id] {
+			t.Errorf("Get(%s).Position = %q, want %q", row.id, got.Position, want[row.id])
+		}
+		if formatSQLiteTime(got.UpdatedAt) != updatedAt {
+			t.Errorf("Get(%s).UpdatedAt = %v, want unchanged %s", row.id, got.UpdatedAt, updatedAt)
+		}
+	}
+}
+
+// ptr 返回指向 v 的指针
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// 查询工作区和成员时返回的列
+const (
+	sqliteWorkspaceColumns = `id, name, created_by, created_at, updated_at`
+	sqliteMemberColumns    = `workspace_id, user_id, role, created_at, updated_at`
+)
+
+// ListWorkspaces 获取用户加入的所有工作区，按创建时间排序
+func (r *SQLiteTodoRepository) ListWorkspaces(ctx context.Context, userID string) ([]models.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT w.id, w.name, w.created_by, w.created_at, w.updated_at, m.role
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = ?
+		ORDER BY w.created_at, w.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作区列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	workspaces := make([]models.Workspace, 0)
+	for rows.Next() {
+		var (
+			workspace            models.Workspace
+			role                 string
+			createdAt, updatedAt string
+		)
+		if err := rows.Scan(&workspace.ID, &workspace.Name, &workspace.CreatedBy, &createdAt, &updatedAt, &role); err != nil {
+			return nil, fmt.Errorf("解析工作区失败: %w", err)
+		}
+		if workspace.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+			return nil, fmt.Errorf("解析工作区失败: %w", err)
+		}
+		if workspace.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+			return nil, fmt.Errorf("解析工作区失败: %w", err)
+		}
+		workspace.Role = models.WorkspaceRole(role)
+		workspaces = append(workspaces, workspace)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取工作区列表失败: %w", err)
+	}
+	return workspaces, nil
+}
+
+// GetWorkspace 获取工作区
+func (r *SQLiteTodoRepository) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	workspace, err := scanSQLiteWorkspace(r.db.QueryRowContext(ctx, `SELECT `+sqliteWorkspaceColumns+` FROM workspaces
+		WHERE id = ?`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, fmt.Errorf("获取工作区失败: %w", err)
+	}
+	return workspace, nil
+}
+
+// CreateWorkspace 在同一个事务中创建工作区并把创建者添加为所有者
+func (r *SQLiteTodoRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	logger.WithContext(ctx, r.logger).Debug("创建工作区",
+		zap.String("createdBy", workspace.CreatedBy))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if workspace.ID == "" {
+		workspace.ID = uuid.New().String()
+	}
+	if workspace.CreatedAt.IsZero() {
+		workspace.CreatedAt = time.Now()
+	}
+	workspace.UpdatedAt = workspace.CreatedAt
+	workspace.Role = ""
+	now := formatSQLiteTime(workspace.CreatedAt)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO workspaces (id, name, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		workspace.ID, workspace.Name, workspace.CreatedBy, now, now); err != nil {
+		return fmt.Errorf("创建工作区失败: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		workspace.ID, workspace.CreatedBy, string(models.WorkspaceOwner), now, now); err != nil {
+		return fmt.Errorf("添加工作区所有者失败: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("创建工作区失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateWorkspace 修改工作区的名称
+func (r *SQLiteTodoRepository) UpdateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	logger.WithContext(ctx, r.logger).Debug("修改工作区",
+		zap.String("id", workspace.ID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	updated, err := scanSQLiteWorkspace(r.db.QueryRowContext(ctx, `UPDATE workspaces SET name = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING `+sqliteWorkspaceColumns,
+		workspace.Name, formatSQLiteTime(time.Now()), workspace.ID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrWorkspaceNotFound
+		}
+		return fmt.Errorf("修改工作区失败: %w", err)
+	}
+	*workspace = *updated
+	return nil
+}
+
+// DeleteWorkspace 在同一个事务中删除工作区和它的数据，待办事项的关联数据由外键级联删除
+func (r *SQLiteTodoRepository) DeleteWorkspace(ctx context.Context, id string) error {
+	logger.WithContext(ctx, r.logger).Debug("删除工作区",
+		zap.String("id", id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开始事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM workspaces WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("删除工作区失败: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("获取受影响行数失败: %w", err)
+	} else if affected == 0 {
+		return ErrWorkspaceNotFound
+	}
+
+	for _, table := range []string{"todos", "projects", "tags", "notifications"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id = ?`, id); err != nil {
+			return fmt.Errorf("删除工作区的 %s 失败: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("删除工作区失败: %w", err)
+	}
+	return nil
+}
+
+// GetMember 获取用户在工作区中的成员身份
+func (r *SQLiteTodoRepository) GetMember(ctx context.Context, workspaceID, userID string) (*models.Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	member, err := scanSQLiteMember(r.db.QueryRowContext(ctx, `SELECT `+sqliteMemberColumns+` FROM workspace_members
+		WHERE workspace_id = ? AND user_id = ?`, workspaceID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("获取工作区成员失败: %w", err)
+	}
+	return member, nil
+}
+
+// ListMembers 获取工作区的所有成员，按加入时间排序
+func (r *SQLiteTodoRepository) ListMembers(ctx context.Context, workspaceID string) ([]models.Member, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sqliteMemberColumns+` FROM workspace_members
+		WHERE workspace_id = ?
+		ORDER BY created_at, user_id`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作区成员列表失败: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]models.Member, 0)
+	for rows.Next() {
+		member, err := scanSQLiteMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析工作区成员失败: %w", err)
+		}
+		members = append(members, *member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("获取工作区成员列表失败: %w", err)
+	}
+	return members, nil
+}
+
+// AddMember 添加成员
+func (r *SQLiteTodoRepository) AddMember(ctx context.Context, member *models.Member) error {
+	logger.WithContext(ctx, r.logger).Debug("添加工作区成员",
+		zap.String("workspaceID", member.WorkspaceID),
+		zap.String("userID", member.UserID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now()
+	}
+	member.UpdatedAt = member.CreatedAt
+	now := formatSQLiteTime(member.CreatedAt)
+
+	if _, err := r.db.ExecContext(ctx, `INSERT INTO workspace_members (workspace_id, user_id, role, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		member.WorkspaceID, member.UserID, string(member.Role), now, now); err != nil {
+		if isSQLiteConstraint(err, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY) {
+			return ErrWorkspaceNotFound
+		}
+		if isSQLitePrimaryKeyViolation(err) {
+			return ErrMemberAlreadyExists
+		}
+		return fmt.Errorf("添加工作区成员失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateMember 修改成员的角色
+func (r *SQLiteTodoRepository) UpdateMember(ctx context.Context, member *models.Member) error {
+	logger.WithContext(ctx, r.logger).Debug("修改工作区成员",
+		zap.String("workspaceID", member.WorkspaceID),
+		zap.String("userID", member.UserID),
+		zap.String("role", string(member.Role)))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	updated, err := scanSQLiteMember(r.db.QueryRowContext(ctx, `UPDATE workspace_members SET role = ?, updated_at = ?
+		WHERE workspace_id = ? AND user_id = ?
+		RETURNING `+sqliteMemberColumns,
+		string(member.Role), formatSQLiteTime(time.Now()), member.WorkspaceID, member.UserID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMemberNotFound
+		}
+		return fmt.Errorf("修改工作区成员失败: %w", err)
+	}
+	*member = *updated
+	return nil
+}
+
+// RemoveMember 移除成员
+func (r *SQLiteTodoRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	logger.WithContext(ctx, r.logger).Debug("移除工作区成员",
+		zap.String("workspaceID", workspaceID),
+		zap.String("userID", userID))
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM workspace_members
+		WHERE workspace_id = ? AND user_id = ?`, workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("移除工作区成员失败: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("获取受影响行数失败: %w", err)
+	} else if affected == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// scanSQLiteWorkspace 将一行查询结果扫描为 Workspace
+func scanSQLiteWorkspace(row sqliteScanner) (*models.Workspace, error) {
+	var (
+		workspace            models.Workspace
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&workspace.ID, &workspace.Name, &workspace.CreatedBy, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if workspace.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if workspace.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// scanSQLiteMember 将一行查询结果扫描为 Member
+func scanSQLiteMember(row sqliteScanner) (*models.Member, error) {
+	var (
+		member               models.Member
+		role                 string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&member.WorkspaceID, &member.UserID, &role, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	member.Role = models.WorkspaceRole(role)
+
+	var err error
+	if member.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return nil, err
+	}
+	if member.UpdatedAt, err = parseSQLiteTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
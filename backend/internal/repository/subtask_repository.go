@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// SubtaskRepository 子任务的仓库接口，存储后端通过类型断言获取。
+//
+// 子任务属于一个待办事项，删除待办事项时一并删除。除 CreateSubtask 外，
+// 方法不检查待办事项的归属，由服务层在调用之前确认待办事项属于该用户。
+type SubtaskRepository interface {
+	// ListSubtasks 获取待办事项的所有子任务，按 models.SortSubtasks 排序
+	ListSubtasks(ctx context.Context, userID, todoID string) ([]models.Subtask, error)
+
+	// SubtaskProgress 批量统计待办事项的子任务进度，按待办事项 ID 索引，
+	// 没有子任务的待办事项不出现在结果中
+	SubtaskProgress(ctx context.Context, userID string, todoIDs []string) (map[string]models.SubtaskProgress, error)
+
+	// CreateSubtask 创建子任务，未设置的 ID 和时间戳会自动填充；
+	// 待办事项不存在时返回 ErrTodoNotFound
+	CreateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error
+
+	// UpdateSubtask 修改子任务的标题、完成状态和位置，并把保存后的子任务写回 subtask。
+	// 子任务不存在或不属于 subtask.TodoID 时返回 ErrSubtaskNotFound
+	UpdateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error
+
+	// DeleteSubtask 删除待办事项的一个子任务，不存在时返回 ErrSubtaskNotFound
+	DeleteSubtask(ctx context.Context, userID, todoID, id string) error
+}
@@ -1,3 +1,4 @@
+// Package supabase 提供访问 Supabase REST（PostgREST）接口的客户端，所有请求都支持 context。
 package supabase
 
 import (
@@ -6,46 +7,46 @@ import (
 	"time"
 
 	"github.com/Brower/backend/internal/config"
-	"github.com/supabase-community/postgrest-go"
 )
 
+// defaultHTTPTimeout HTTP 客户端的兜底超时时间，单次操作的超时由调用方的 context 控制
+const defaultHTTPTimeout = 30 * time.Second
+
+// Client Supabase 客户端
 type Client struct {
-	db        *postgrest.Client
-	projectID string
-	apiKey    string
+	restURL    string
+	authURL    string
+	apiKey     string
 	httpClient *http.Client
 }
 
+// NewClient 创建 Supabase 客户端，使用服务角色密钥访问
 func NewClient(cfg *config.SupabaseConfig) (*Client, error) {
-	// Supabase REST URL 格式：https://<project_id>.supabase.co/rest/v1
-	restUrl := fmt.Sprintf("https://%s.supabase.co/rest/v1", cfg.ProjectID)
-	
-	// 创建自定义的 HTTP 客户端
-	httpClient := &http.Client{
-		Timeout: time.Second * 10,
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("缺少 Supabase base_url 配置")
 	}
 
-	// 创建 Postgrest 客户端
-	client := postgrest.NewClient(restUrl, "", map[string]string{
-		"apikey": cfg.ServiceRoleKey,
-		"Authorization": "Bearer " + cfg.ServiceRoleKey,
-	})
+	// Supabase REST URL 格式：https://<base_url>/rest/v1
+	baseURL := "https://" + cfg.BaseURL
 
 	return &Client{
-		db:         client,
-		projectID:  cfg.ProjectID,
-		apiKey:     cfg.ServiceRoleKey,
-		httpClient: httpClient,
+		restURL: baseURL + "/rest/v1",
+		authURL: baseURL + "/auth/v1",
+		apiKey:  cfg.ServiceRoleKey,
+		httpClient: &http.Client{
+			Timeout: defaultHTTPTimeout,
+		},
 	}, nil
 }
 
-func (c *Client) DB() *postgrest.Client {
-	return c.db
+// RestURL 返回 Supabase REST API 的基础 URL
+func (c *Client) RestURL() string {
+	return c.restURL
 }
 
 // GetAuthURL 返回 Supabase Auth API 的基础 URL
 func (c *Client) GetAuthURL() string {
-	return fmt.Sprintf("https://%s.supabase.co/auth/v1", c.projectID)
+	return c.authURL
 }
 
 // GetAPIKey 返回 API Key
@@ -56,4 +57,22 @@ func (c *Client) GetAPIKey() string {
 // GetHTTPClient 返回 HTTP 客户端
 func (c *Client) GetHTTPClient() *http.Client {
 	return c.httpClient
-} 
\ No newline at end of file
+}
+
+// From 创建针对指定表的查询
+func (c *Client) From(table string) *Query {
+	return newQuery(c, http.MethodGet, "/"+table)
+}
+
+// RPC 创建调用数据库函数的请求
+func (c *Client) RPC(function string, params any) *Query {
+	q := newQuery(c, http.MethodPost, "/rpc/"+function)
+	q.body = params
+	return q
+}
+
+// setAuthHeaders 设置访问 Supabase 所需的认证头
+func (c *Client) setAuthHeaders(req *http.Request) {
+	req.Header.Set("apikey", c.apiKey)
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+}
@@ -0,0 +1,61 @@
+package supabase
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// PostgREST / PostgreSQL 常见错误码
+const (
+	CodeUniqueViolation     = "23505" // 唯一约束冲突
+	CodeForeignKeyViolation = "23503" // 外键约束冲突
+	CodeInvalidTextInput    = "22P02" // 非法输入格式，例如非法 UUID
+	CodeNoRowsForSingleton  = "PGRST116"
+)
+
+// Error PostgREST 返回的错误
+type Error struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Details    string `json:"details"`
+	Hint       string `json:"hint"`
+	// RetryAfter 响应中 Retry-After 头指定的等待时间，未指定时为 0
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("supabase: HTTP %d (%s) %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("supabase: HTTP %d %s", e.StatusCode, e.Message)
+}
+
+// newError 根据错误响应创建 *Error
+func newError(resp *http.Response, body []byte) *Error {
+	e := &Error{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(body, e); err != nil || e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	e.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	return e
+}
+
+// parseRetryAfter 解析 Retry-After 头，支持秒数和 HTTP 日期两种格式
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
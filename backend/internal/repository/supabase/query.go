@@ -0,0 +1,256 @@
+package supabase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Query 一次 PostgREST 请求，通过链式调用设置过滤、排序和分页
+type Query struct {
+	client  *Client
+	method  string
+	path    string
+	params  url.Values
+	prefer  []string
+	headers http.Header
+	body    any
+}
+
+// Response PostgREST 的响应
+type Response struct {
+	StatusCode int
+	Body       []byte
+	// Count 使用 Count() 时返回的总行数，否则为 -1
+	Count int64
+}
+
+// newQuery 创建一个请求
+func newQuery(client *Client, method, path string) *Query {
+	return &Query{
+		client:  client,
+		method:  method,
+		path:    path,
+		params:  url.Values{},
+		headers: http.Header{},
+	}
+}
+
+// Select 查询指定的列
+func (q *Query) Select(columns string) *Query {
+	q.method = http.MethodGet
+	q.params.Set("select", columns)
+	return q
+}
+
+// Insert 插入数据，并返回插入后的行
+func (q *Query) Insert(values any) *Query {
+	q.method = http.MethodPost
+	q.body = values
+	q.prefer = append(q.prefer, "return=representation")
+	return q
+}
+
+// Upsert 插入数据，冲突时更新或忽略已存在的行
+func (q *Query) Upsert(values any, onConflict string, ignoreDuplicates bool) *Query {
+	q.method = http.MethodPost
+	q.body = values
+	if onConflict != "" {
+		q.params.Set("on_conflict", onConflict)
+	}
+	if ignoreDuplicates {
+		q.prefer = append(q.prefer, "resolution=ignore-duplicates")
+	} else {
+		q.prefer = append(q.prefer, "resolution=merge-duplicates")
+	}
+	q.prefer = append(q.prefer, "return=representation")
+	return q
+}
+
+// Update 更新匹配过滤条件的行，并返回更新后的行
+func (q *Query) Update(values any) *Query {
+	q.method = http.MethodPatch
+	q.body = values
+	q.prefer = append(q.prefer, "return=representation")
+	return q
+}
+
+// Delete 删除匹配过滤条件的行，并返回被删除的行
+func (q *Query) Delete() *Query {
+	q.method = http.MethodDelete
+	q.prefer = append(q.prefer, "return=representation")
+	return q
+}
+
+// Filter 添加过滤条件，例如 Filter("user_id", "eq", id)
+func (q *Query) Filter(column, operator, value string) *Query {
+	q.params.Add(column, operator+"."+value)
+	return q
+}
+
+// Eq 添加等值过滤条件
+func (q *Query) Eq(column, value string) *Query {
+	return q.Filter(column, "eq", value)
+}
+
+// In 添加 IN 过滤条件
+func (q *Query) In(column string, values []string) *Query {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = QuoteValue(v)
+	}
+	return q.Filter(column, "in", "("+strings.Join(quoted, ",")+")")
+}
+
+// NotIn 添加 NOT IN 过滤条件
+func (q *Query) NotIn(column string, values []string) *Query {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = QuoteValue(v)
+	}
+	return q.Filter(column, "not.in", "("+strings.Join(quoted, ",")+")")
+}
+
+// Or 添加 OR 条件组，例如 Or("completed.eq.true,title.ilike.*go*")
+func (q *Query) Or(filters string) *Query {
+	q.params.Add("or", "("+filters+")")
+	return q
+}
+
+// Order 添加排序，可多次调用
+func (q *Query) Order(column string, ascending bool) *Query {
+	direction := "desc"
+	if ascending {
+		direction = "asc"
+	}
+	order := column + "." + direction
+	if existing := q.params.Get("order"); existing != "" {
+		order = existing + "," + order
+	}
+	q.params.Set("order", order)
+	return q
+}
+
+// Limit 限制返回的行数
+func (q *Query) Limit(n int) *Query {
+	q.params.Set("limit", strconv.Itoa(n))
+	return q
+}
+
+// Offset 跳过前 n 行
+func (q *Query) Offset(n int) *Query {
+	q.params.Set("offset", strconv.Itoa(n))
+	return q
+}
+
+// Count 要求返回符合条件的总行数
+func (q *Query) Count() *Query {
+	q.prefer = append(q.prefer, "count=exact")
+	return q
+}
+
+// Header 设置额外的请求头
+func (q *Query) Header(key, value string) *Query {
+	q.headers.Set(key, value)
+	return q
+}
+
+// Execute 发送请求。状态码大于等于 400 时返回 *Error。
+func (q *Query) Execute(ctx context.Context) (*Response, error) {
+	var body io.Reader
+	if q.body != nil {
+		data, err := json.Marshal(q.body)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求体失败: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	reqURL := q.client.restURL + q.path
+	if len(q.params) > 0 {
+		reqURL += "?" + q.params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, q.method, reqURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	q.client.setAuthHeaders(req)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Profile", "public")
+	req.Header.Set("Content-Profile", "public")
+	if len(q.prefer) > 0 {
+		req.Header.Set("Prefer", strings.Join(q.prefer, ","))
+	}
+	for key, values := range q.headers {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+
+	resp, err := q.client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, newError(resp, respBody)
+	}
+
+	return &Response{
+		StatusCode: resp.StatusCode,
+		Body:       respBody,
+		Count:      parseCount(resp.Header.Get("Content-Range")),
+	}, nil
+}
+
+// ExecuteTo 发送请求并把响应解析到 dest，返回总行数（未使用 Count() 时为 -1）
+func (q *Query) ExecuteTo(ctx context.Context, dest any) (int64, error) {
+	resp, err := q.Execute(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if dest != nil && len(resp.Body) > 0 {
+		if err := json.Unmarshal(resp.Body, dest); err != nil {
+			return 0, fmt.Errorf("解析响应失败: %w", err)
+		}
+	}
+	return resp.Count, nil
+}
+
+// QuoteValue 为过滤值加上双引号，避免值中的逗号、括号等被 PostgREST 当作语法
+func QuoteValue(v string) string {
+	if !strings.ContainsAny(v, `,.:()"\ `) {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
+
+// parseCount 从 Content-Range 头（例如 0-9/42）中解析总行数
+func parseCount(contentRange string) int64 {
+	_, total, ok := strings.Cut(contentRange, "/")
+	if !ok || total == "*" {
+		return -1
+	}
+	count, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return count
+}
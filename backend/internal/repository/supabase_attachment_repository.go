@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var _ AttachmentRepository = (*SupabaseTodoRepository)(nil)
+
+// supabaseAttachmentRow attachments 表中的一行
+type supabaseAttachmentRow struct {
+	ID          string    `json:"id"`
+	TodoID      *string   `json:"todo_id"`
+	UserID      string    `json:"user_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"storage_key"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// toModel 转换为 Attachment 实体，todo_id 为 NULL 时 TodoID 为空
+func (row supabaseAttachmentRow) toModel() models.Attachment {
+	attachment := models.Attachment{
+		ID:          row.ID,
+		UserID:      row.UserID,
+		Filename:    row.Filename,
+		ContentType: row.ContentType,
+		Size:        row.Size,
+		StorageKey:  row.StorageKey,
+		CreatedAt:   row.CreatedAt,
+	}
+	if row.TodoID != nil {
+		attachment.TodoID = *row.TodoID
+	}
+	return attachment
+}
+
+// toAttachments 转换为 Attachment 实体的列表
+func toAttachments(rows []supabaseAttachmentRow) []models.Attachment {
+	attachments := make([]models.Attachment, len(rows))
+	for i, row := range rows {
+		attachments[i] = row.toModel()
+	}
+	return attachments
+}
+
+// ListAttachments 获取待办事项的所有附件，按上传时间排序
+func (r *SupabaseTodoRepository) ListAttachments(ctx context.Context, userID, todoID string) ([]models.Attachment, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseAttachmentRow
+	err := r.retry.Do(ctx, log, "获取附件列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("attachments").
+			Select("*").
+			Eq("todo_id", todoID).
+			Eq("user_id", userID).
+			Order("created_at", true).
+			Order("id", true).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return []models.Attachment{}, nil
+		}
+		return nil, fmt.Errorf("获取附件列表失败: %w", err)
+	}
+	return toAttachments(rows), nil
+}
+
+// GetAttachment 获取待办事项的一个附件
+func (r *SupabaseTodoRepository) GetAttachment(ctx context.Context, userID, todoID, id string) (*models.Attachment, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseAttachmentRow
+	err := r.retry.Do(ctx, log, "获取附件", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("attachments").
+			Select("*").
+			Eq("id", id).
+			Eq("todo_id", todoID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseAttachmentError("获取附件失败", err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrAttachmentNotFound
+	}
+
+	attachment := rows[0].toModel()
+	return &attachment, nil
+}
+
+// CreateAttachment 保存附件的元数据。先确认待办事项属于该用户，再由客户端生成 ID 插入，
+// 重试时遇到唯一约束冲突说明之前的尝试已经成功，直接读取已创建的附件
+func (r *SupabaseTodoRepository) CreateAttachment(ctx context.Context, userID string, attachment *models.Attachment) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("创建附件",
+		zap.String("userID", userID),
+		zap.String("todoID", attachment.TodoID),
+		zap.String("filename", attachment.Filename))
+
+	if _, err := r.Get(ctx, userID, attachment.TodoID); err != nil {
+		return err
+	}
+
+	if attachment.ID == "" {
+		attachment.ID = uuid.New().String()
+	}
+	if attachment.CreatedAt.IsZero() {
+		attachment.CreatedAt = time.Now()
+	}
+
+	data := map[string]interface{}{
+		"id":           attachment.ID,
+		"todo_id":      attachment.TodoID,
+		"user_id":      userID,
+		"filename":     attachment.Filename,
+		"content_type": attachment.ContentType,
+		"size":         attachment.Size,
+		"storage_key":  attachment.StorageKey,
+		"created_at":   attachment.CreatedAt,
+	}
+
+	var created []supabaseAttachmentRow
+	err := r.retry.Do(ctx, log, "创建附件", func(ctx context.Context, attempt int) error {
+		created = nil
+		_, err := r.client.From("attachments").Insert(data).ExecuteTo(ctx, &created)
+		if attempt > 1 && isSupabaseError(err, supabase.CodeUniqueViolation) {
+			log.Info("重试时发现附件已创建", zap.String("id", attachment.ID))
+			_, err = r.client.From("attachments").
+				Select("*").
+				Eq("id", attachment.ID).
+				Eq("user_id", userID).
+				ExecuteTo(ctx, &created)
+		}
+		return err
+	})
+	if err != nil {
+		// 检查之后待办事项被删除时违反外键
+		if isSupabaseError(err, supabase.CodeForeignKeyViolation) {
+			return ErrTodoNotFound
+		}
+		return fmt.Errorf("创建附件失败: %w", err)
+	}
+
+	if len(created) > 0 {
+		*attachment = created[0].toModel()
+	}
+	return nil
+}
+
+// DeleteAttachment 把附件与待办事项脱离
+func (r *SupabaseTodoRepository) DeleteAttachment(ctx context.Context, userID, todoID, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("删除附件",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.String("id", id))
+
+	var (
+		updated []supabaseAttachmentRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "删除附件", func(ctx context.Context, attempt int) error {
+		updated = nil
+		retried = attempt > 1
+		_, err := r.client.From("attachments").
+			Update(map[string]interface{}{"todo_id": nil}).
+			Eq("id", id).
+			Eq("todo_id", todoID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseAttachmentError("删除附件失败", err)
+	}
+
+	// 重试时没有修改任何行，说明之前失败的那次尝试实际已经成功
+	if len(updated) == 0 && !retried {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// ListDetachedAttachments 获取所有用户已经脱离待办事项的附件，需要使用 service_role 密钥绕过 RLS
+func (r *SupabaseTodoRepository) ListDetachedAttachments(ctx context.Context, limit int) ([]models.Attachment, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseAttachmentRow
+	err := r.retry.Do(ctx, log, "获取待清理的附件", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("attachments").
+			Select("*").
+			Filter("todo_id", "is", "null").
+			Order("created_at", true).
+			Order("id", true).
+			Limit(limit).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取待清理的附件失败: %w", err)
+	}
+	return toAttachments(rows), nil
+}
+
+// PurgeAttachment 删除已经脱离待办事项的附件的元数据，删除是幂等的，可以安全地重试
+func (r *SupabaseTodoRepository) PurgeAttachment(ctx context.Context, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+
+	err := r.retry.Do(ctx, log, "删除附件元数据", func(ctx context.Context, _ int) error {
+		_, err := r.client.From("attachments").
+			Delete().
+			Eq("id", id).
+			Filter("todo_id", "is", "null").
+			Execute(ctx)
+		return err
+	})
+	if err != nil && !isSupabaseError(err, supabase.CodeInvalidTextInput) {
+		return fmt.Errorf("删除附件元数据失败: %w", err)
+	}
+	return nil
+}
+
+// mapSupabaseAttachmentError 将 PostgREST 错误转换为仓库层错误，ID 不是合法的 UUID 视为附件不存在
+func mapSupabaseAttachmentError(operation string, err error) error {
+	if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+		return ErrAttachmentNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
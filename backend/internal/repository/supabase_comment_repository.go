@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var _ CommentRepository = (*SupabaseTodoRepository)(nil)
+
+// supabaseCommentRow comments 表中的一行
+type supabaseCommentRow struct {
+	ID        string    `json:"id"`
+	TodoID    string    `json:"todo_id"`
+	UserID    string    `json:"user_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// toModel 转换为 Comment 实体
+func (row supabaseCommentRow) toModel() models.Comment {
+	return models.Comment{
+		ID:        row.ID,
+		TodoID:    row.TodoID,
+		UserID:    row.UserID,
+		Body:      row.Body,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+// ListComments 按创建时间正序分页获取待办事项的评论
+func (r *SupabaseTodoRepository) ListComments(ctx context.Context, todoID string, opts models.CommentListOptions) (*models.CommentPage, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var keyset string
+	if opts.After != nil {
+		// 评论表同样有 created_at 和 id 列，复用待办事项按创建时间正序的条件
+		keys, err := todoSortKeys(models.SortByCreatedAt, models.SortAsc)
+		if err != nil {
+			return nil, err
+		}
+		if keyset, err = supabaseKeysetFilter(keys, opts.After); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows []supabaseCommentRow
+	err := r.retry.Do(ctx, log, "获取评论列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		query := r.client.From("comments").
+			Select("*").
+			Eq("todo_id", todoID).
+			Order("created_at", true).
+			Order("id", true).
+			Limit(opts.Limit + 1)
+		if keyset != "" {
+			query = query.Or(keyset)
+		}
+		_, err := query.ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return newCommentPage(nil, opts), nil
+		}
+		return nil, fmt.Errorf("获取评论列表失败: %w", err)
+	}
+
+	comments := make([]models.Comment, len(rows))
+	for i, row := range rows {
+		comments[i] = row.toModel()
+	}
+	return newCommentPage(comments, opts), nil
+}
+
+// CommentCounts 批量统计待办事项的评论数，只读取 todo_id 一列，在本地计数
+func (r *SupabaseTodoRepository) CommentCounts(ctx context.Context, todoIDs []string) (map[string]int, error) {
+	result := make(map[string]int)
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseCommentRow
+	err := r.retry.Do(ctx, log, "统计评论数", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("comments").
+			Select("todo_id").
+			In("todo_id", todoIDs).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("统计评论数失败: %w", err)
+	}
+
+	for _, row := range rows {
+		result[row.TodoID]++
+	}
+	return result, nil
+}
+
+// GetComment 获取待办事项的一条评论
+func (r *SupabaseTodoRepository) GetComment(ctx context.Context, todoID, id string) (*models.Comment, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseCommentRow
+	err := r.retry.Do(ctx, log, "获取评论", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("comments").
+			Select("*").
+			Eq("id", id).
+			Eq("todo_id", todoID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseCommentError("获取评论失败", err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrCommentNotFound
+	}
+
+	comment := rows[0].toModel()
+	return &comment, nil
+}
+
+// CreateComment 由客户端生成 ID 插入评论，待办事项不存在时违反外键。
+// 重试时遇到唯一约束冲突说明之前的尝试已经成功，直接读取已创建的评论
+func (r *SupabaseTodoRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("创建评论",
+		zap.String("userID", comment.UserID),
+		zap.String("todoID", comment.TodoID))
+
+	if comment.ID == "" {
+		comment.ID = uuid.New().String()
+	}
+	if comment.CreatedAt.IsZero() {
+		comment.CreatedAt = time.Now()
+	}
+
+	data := map[string]interface{}{
+		"id":         comment.ID,
+		"todo_id":    comment.TodoID,
+		"user_id":    comment.UserID,
+		"body":       comment.Body,
+		"created_at": comment.CreatedAt,
+		"updated_at": comment.CreatedAt,
+	}
+
+	var created []supabaseCommentRow
+	err := r.retry.Do(ctx, log, "创建评论", func(ctx context.Context, attempt int) error {
+		created = nil
+		_, err := r.client.From("comments").Insert(data).ExecuteTo(ctx, &created)
+		if attempt > 1 && isSupabaseError(err, supabase.CodeUniqueViolation) {
+			log.Info("重试时发现评论已创建", zap.String("id", comment.ID))
+			_, err = r.client.From("comments").
+				Select("*").
+				Eq("id", comment.ID).
+				Eq("user_id", comment.UserID).
+				ExecuteTo(ctx, &created)
+		}
+		return err
+	})
+	if err != nil {
+		// 待办事项不存在时违反外键，ID 不是合法的 UUID 时同样视为不存在
+		if isSupabaseError(err, supabase.CodeForeignKeyViolation) || isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return ErrTodoNotFound
+		}
+		return fmt.Errorf("创建评论失败: %w", err)
+	}
+
+	if len(created) > 0 {
+		*comment = created[0].toModel()
+	}
+	return nil
+}
+
+// UpdateComment 修改评论的内容，写入的是固定值，可以安全地重试
+func (r *SupabaseTodoRepository) UpdateComment(ctx context.Context, comment *models.Comment) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("更新评论",
+		zap.String("userID", comment.UserID),
+		zap.String("todoID", comment.TodoID),
+		zap.String("id", comment.ID))
+
+	data := map[string]interface{}{
+		"body":       comment.Body,
+		"updated_at": time.Now(),
+	}
+
+	var updated []supabaseCommentRow
+	err := r.retry.Do(ctx, log, "更新评论", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("comments").
+			Update(data).
+			Eq("id", comment.ID).
+			Eq("todo_id", comment.TodoID).
+			Eq("user_id", comment.UserID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseCommentError("更新评论失败", err)
+	}
+	if len(updated) == 0 {
+		return ErrCommentNotFound
+	}
+
+	*comment = updated[0].toModel()
+	return nil
+}
+
+// DeleteComment 删除 userID 作为作者的一条评论
+func (r *SupabaseTodoRepository) DeleteComment(ctx context.Context, userID, todoID, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("删除评论",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.String("id", id))
+
+	var (
+		deleted []supabaseCommentRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "删除评论", func(ctx context.Context, attempt int) error {
+		deleted = nil
+		retried = attempt > 1
+		_, err := r.client.From("comments").
+			Delete().
+			Eq("id", id).
+			Eq("todo_id", todoID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &deleted)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseCommentError("删除评论失败", err)
+	}
+
+	// 重试时没有删除任何行，说明之前失败的那次尝试实际已经删除成功
+	if len(deleted) == 0 && !retried {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// mapSupabaseCommentError 将 PostgREST 错误转换为仓库层错误，ID 不是合法的 UUID 视为评论不存在
+func mapSupabaseCommentError(operation string, err error) error {
+	if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+		return ErrCommentNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// supabaseProjectRow projects 表中的一行
+type supabaseProjectRow struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Icon      string    `json:"icon"`
+	Archived  bool      `json:"archived"`
+	IsInbox   bool      `json:"is_inbox"`
+	Position  string    `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// toModel 转换为 Project 实体
+func (row supabaseProjectRow) toModel() models.Project {
+	return models.Project{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Name:      row.Name,
+		Color:     row.Color,
+		Icon:      row.Icon,
+		Archived:  row.Archived,
+		Inbox:     row.IsInbox,
+		Position:  row.Position,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+// supabaseProjectCountRow project_todo_counts 函数返回的一行
+type supabaseProjectCountRow struct {
+	ProjectID string `json:"project_id"`
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+}
+
+// ListProjects 获取指定用户的所有项目，收件箱在最前，其余按位置排序
+func (r *SupabaseTodoRepository) ListProjects(ctx context.Context, userID string) ([]models.Project, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("获取项目列表", zap.String("userID", userID))
+
+	var rows []supabaseProjectRow
+	err := r.retry.Do(ctx, log, "获取项目列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("projects").
+			Select("*").
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return []models.Project{}, nil
+		}
+		return nil, fmt.Errorf("获取项目列表失败: %w", err)
+	}
+
+	projects := make([]models.Project, len(rows))
+	for i, row := range rows {
+		projects[i] = row.toModel()
+	}
+	models.SortProjects(projects)
+	return projects, nil
+}
+
+// GetProject 获取指定用户的单个项目
+func (r *SupabaseTodoRepository) GetProject(ctx context.Context, userID, id string) (*models.Project, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseProjectRow
+	err := r.retry.Do(ctx, log, "获取项目", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("projects").
+			Select("*").
+			Eq("id", id).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseProjectError("获取项目失败", err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrProjectNotFound
+	}
+
+	project := rows[0].toModel()
+	return &project, nil
+}
+
+// EnsureInbox 返回指定用户的收件箱，不存在时创建。
+// PostgREST 的请求之间没有事务：先创建收件箱，并发创建由唯一索引保证只有一个成功，
+// 冲突时读取已创建的收件箱；再把还没有分配项目的待办事项移入收件箱。
+// 移动是幂等的，每次创建时都执行，之前的请求在移动前失败也能在下一次补上。
+func (r *SupabaseTodoRepository) EnsureInbox(ctx context.Context, userID string) (*models.Project, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	inbox, err := r.findInbox(ctx, log, userID)
+	if err != nil || inbox != nil {
+		return inbox, err
+	}
+
+	log.Info("创建收件箱", zap.String("userID", userID))
+
+	now := time.Now()
+	data := map[string]interface{}{
+		"id":         uuid.New().String(),
+		"user_id":    userID,
+		"name":       models.InboxProjectName,
+		"color":      models.DefaultProjectColor,
+		"is_inbox":   true,
+		"created_at": now,
+		"updated_at": now,
+	}
+
+	var created []supabaseProjectRow
+	err = r.retry.Do(ctx, log, "创建收件箱", func(ctx context.Context, _ int) error {
+		created = nil
+		_, err := r.client.From("projects").Insert(data).ExecuteTo(ctx, &created)
+		return err
+	})
+	if err != nil && !isSupabaseError(err, supabase.CodeUniqueViolation) {
+		return nil, mapSupabaseProjectError("创建收件箱失败", err)
+	}
+	if len(created) > 0 {
+		project := created[0].toModel()
+		inbox = &project
+	} else if inbox, err = r.findInbox(ctx, log, userID); err != nil {
+		return nil, err
+	} else if inbox == nil {
+		return nil, fmt.Errorf("创建收件箱失败: %w", ErrProjectNotFound)
+	}
+
+	err = r.retry.Do(ctx, log, "移动待办事项到收件箱", func(ctx context.Context, _ int) error {
+		_, err := r.client.From("todos").
+			Update(map[string]interface{}{"project_id": inbox.ID}).
+			Eq("user_id", userID).
+			Filter("project_id", "is", "null").
+			ExecuteTo(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("移动待办事项到收件箱失败: %w", err)
+	}
+	return inbox, nil
+}
+
+// findInbox 查询指定用户的收件箱，没有时返回 nil
+func (r *SupabaseTodoRepository) findInbox(ctx context.Context, log *zap.Logger, userID string) (*models.Project, error) {
+	var rows []supabaseProjectRow
+	err := r.retry.Do(ctx, log, "获取收件箱", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("projects").
+			Select("*").
+			Eq("user_id", userID).
+			Eq("is_inbox", "true").
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseProjectError("获取收件箱失败", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	inbox := rows[0].toModel()
+	return &inbox, nil
+}
+
+// CreateProject 创建项目。与待办事项一样由客户端生成 ID：
+// 重试时遇到主键冲突，说明之前的尝试已经成功，读取已创建的记录
+func (r *SupabaseTodoRepository) CreateProject(ctx context.Context, userID string, project *models.Project) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("创建项目",
+		zap.String("userID", userID),
+		zap.String("name", project.Name))
+
+	if project.ID == "" {
+		project.ID = uuid.New().String()
+	}
+	if project.CreatedAt.IsZero() {
+		project.CreatedAt = time.Now()
+	}
+
+	data := map[string]interface{}{
+		"id":         project.ID,
+		"user_id":    userID,
+		"name":       project.Name,
+		"color":      project.Color,
+		"icon":       project.Icon,
+		"archived":   project.Archived,
+		"is_inbox":   false,
+		"position":   project.Position,
+		"created_at": project.CreatedAt,
+		"updated_at": project.CreatedAt,
+	}
+
+	var created []supabaseProjectRow
+	err := r.retry.Do(ctx, log, "创建项目", func(ctx context.Context, attempt int) error {
+		created = nil
+		_, err := r.client.From("projects").Insert(data).ExecuteTo(ctx, &created)
+		if attempt > 1 && isSupabaseError(err, supabase.CodeUniqueViolation) {
+			log.Info("重试时发现项目已创建", zap.String("id", project.ID))
+			_, err = r.client.From("projects").
+				Select("*").
+				Eq("id", project.ID).
+				Eq("user_id", userID).
+				ExecuteTo(ctx, &created)
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("创建项目失败: %w", err)
+	}
+
+	if len(created) > 0 {
+		*project = created[0].toModel()
+	}
+	return nil
+}
+
+// UpdateProject 修改项目的名称、颜色、图标、归档状态和位置，写入的是固定值，可以安全地重试
+func (r *SupabaseTodoRepository) UpdateProject(ctx context.Context, userID string, project *models.Project) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("更新项目",
+		zap.String("userID", userID),
+		zap.String("id", project.ID))
+
+	data := map[string]interface{}{
+		"name":       project.Name,
+		"color":      project.Color,
+		"icon":       project.Icon,
+		"archived":   project.Archived,
+		"position":   project.Position,
+		"updated_at": time.Now(),
+	}
+
+	var updated []supabaseProjectRow
+	err := r.retry.Do(ctx, log, "更新项目", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("projects").
+			Update(data).
+			Eq("id", project.ID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseProjectError("更新项目失败", err)
+	}
+	if len(updated) == 0 {
+		return ErrProjectNotFound
+	}
+
+	*project = updated[0].toModel()
+	return nil
+}
+
+// DeleteProject 删除项目。PostgREST 的请求之间没有事务：
+// moveTo 不为空时先把项目中的待办事项移动到 moveTo，再删除项目，
+// 两步都可以安全地重试；删除失败时待办事项已经移动，不会丢失。
+func (r *SupabaseTodoRepository) DeleteProject(ctx context.Context, userID, id, moveTo string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("删除项目",
+		zap.String("userID", userID),
+		zap.String("id", id),
+		zap.String("moveTo", moveTo))
+
+	if _, err := r.GetProject(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if moveTo != "" {
+		err := r.retry.Do(ctx, log, "移动项目中的待办事项", func(ctx context.Context, _ int) error {
+			_, err := r.client.From("todos").
+				Update(map[string]interface{}{"project_id": moveTo}).
+				Eq("project_id", id).
+				Eq("user_id", userID).
+				ExecuteTo(ctx, nil)
+			return err
+		})
+		if err != nil {
+			return mapSupabaseProjectError("移动项目中的待办事项失败", err)
+		}
+	}
+
+	err := r.retry.Do(ctx, log, "删除项目", func(ctx context.Context, _ int) error {
+		_, err := r.client.From("projects").
+			Delete().
+			Eq("id", id).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseProjectError("删除项目失败", err)
+	}
+	return nil
+}
+
+// ProjectCounts 通过 RPC 调用 project_todo_counts 函数统计每个项目中待办事项的数量
+func (r *SupabaseTodoRepository) ProjectCounts(ctx context.Context, userID string) (map[string]models.ProjectCounts, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseProjectCountRow
+	err := r.retry.Do(ctx, log, "统计项目中的待办事项", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.RPC("project_todo_counts", map[string]interface{}{
+			"p_user_id": userID,
+		}).ExecuteTo(ctx, &rows)
+		return err
+	})
+	counts := make(map[string]models.ProjectCounts)
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return counts, nil
+		}
+		return nil, fmt.Errorf("统计项目中的待办事项失败: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.ProjectID] = models.ProjectCounts{Total: row.Total, Completed: row.Completed}
+	}
+	return counts, nil
+}
+
+// MoveTodos 把指定用户的多个待办事项移动到 projectID，写入的是固定值，可以安全地重试。
+// 不是合法 UUID 的 ID 会让整条语句失败，这些 ID 不可能存在，请求之前先去掉
+func (r *SupabaseTodoRepository) MoveTodos(ctx context.Context, userID string, todoIDs []string, projectID string) (int, error) {
+	valid := make([]string, 0, len(todoIDs))
+	for _, id := range todoIDs {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	if len(valid) == 0 {
+		return 0, nil
+	}
+	todoIDs = valid
+
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("移动待办事项到项目",
+		zap.String("userID", userID),
+		zap.Strings("todoIDs", todoIDs),
+		zap.String("projectID", projectID))
+
+	var updated []supabaseTodoRow
+	err := r.retry.Do(ctx, log, "移动待办事项到项目", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("todos").
+			Update(map[string]interface{}{
+				"project_id": projectID,
+				"updated_at": time.Now(),
+			}).
+			Eq("user_id", userID).
+			In("id", todoIDs).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
+	if err != nil {
+		return 0, mapSupabaseProjectError("移动待办事项到项目失败", err)
+	}
+	return len(updated), nil
+}
+
+// mapSupabaseProjectError 将 PostgREST 错误转换为仓库层错误。
+// ID 不是合法的 UUID 或引用项目时违反外键，都视为项目不存在
+func mapSupabaseProjectError(operation string, err error) error {
+	if isSupabaseError(err, supabase.CodeInvalidTextInput) || isSupabaseError(err, supabase.CodeForeignKeyViolation) {
+		return ErrProjectNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// supabaseReminderRow claim_due_reminders 函数返回的一行
+type supabaseReminderRow struct {
+	TodoID   string     `json:"todo_id"`
+	UserID   string     `json:"user_id"`
+	Title    string     `json:"title"`
+	DueAt    *time.Time `json:"due_at"`
+	RemindAt time.Time  `json:"remind_at"`
+	Attempts int        `json:"attempts"`
+	// WorkspaceID 和 Recipients 只有工作区的提醒才有值
+	WorkspaceID *string  `json:"workspace_id"`
+	Recipients  []string `json:"recipients"`
+}
+
+// supabaseNotificationRow notifications 表中的一行
+type supabaseNotificationRow struct {
+	ID        string                  `json:"id"`
+	UserID    string                  `json:"user_id"`
+	TodoID    *string                 `json:"todo_id"`
+	Kind      models.NotificationKind `json:"kind"`
+	Title     string                  `json:"title"`
+	DueAt     *time.Time              `json:"due_at"`
+	Key       string                  `json:"key"`
+	CreatedAt time.Time               `json:"created_at"`
+	ReadAt    *time.Time              `json:"read_at"`
+}
+
+// toModel 转换为 Notification 实体
+func (row supabaseNotificationRow) toModel() models.Notification {
+	n := models.Notification{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Kind:      row.Kind,
+		Title:     row.Title,
+		DueAt:     row.DueAt,
+		Key:       row.Key,
+		CreatedAt: row.CreatedAt,
+		ReadAt:    row.ReadAt,
+	}
+	if row.TodoID != nil {
+		n.TodoID = *row.TodoID
+	}
+	return n
+}
+
+// ClaimDueReminders 通过 RPC 调用 claim_due_reminders 函数领取到期的提醒。
+// 领取会修改数据，请求失败时无法确定是否已经领取成功，因此不重试；
+// 已领取但没有返回的提醒会在租约到期后被重新领取。
+func (r *SupabaseTodoRepository) ClaimDueReminders(ctx context.Context, owner string, now time.Time, lease time.Duration, limit int) ([]models.Reminder, error) {
+	params := map[string]interface{}{
+		"p_owner":       owner,
+		"p_now":         now,
+		"p_lease_until": now.Add(lease),
+		"p_limit":       limit,
+	}
+
+	once := r.retry
+	once.MaxAttempts = 1
+
+	var rows []supabaseReminderRow
+	err := once.Do(ctx, logger.WithContext(ctx, r.logger), "领取提醒", func(ctx context.Context, _ int) error {
+		_, err := r.client.RPC("claim_due_reminders", params).ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("领取提醒失败: %w", err)
+	}
+
+	reminders := make([]models.Reminder, len(rows))
+	for i, row := range rows {
+		reminders[i] = models.Reminder{
+			TodoID:     row.TodoID,
+			UserID:     row.UserID,
+			Title:      row.Title,
+			DueAt:      row.DueAt,
+			RemindAt:   row.RemindAt,
+			Attempts:   row.Attempts,
+			Recipients: row.Recipients,
+		}
+		if row.WorkspaceID != nil {
+			reminders[i].WorkspaceID = *row.WorkspaceID
+		}
+	}
+	return reminders, nil
+}
+
+// CompleteReminder 将领取的提醒标记为已提醒并释放租约。写入的都是固定值，可以安全地重试。
+func (r *SupabaseTodoRepository) CompleteReminder(ctx context.Context, owner string, reminder models.Reminder) error {
+	log := logger.WithContext(ctx, r.logger)
+
+	data := map[string]interface{}{
+		"reminded_for": reminder.RemindAt,
+		"lease_owner":  nil,
+		"lease_until":  nil,
+		"attempts":     0,
+	}
+	err := r.retry.Do(ctx, log, "标记提醒", func(ctx context.Context, _ int) error {
+		_, err := r.client.From("todo_reminders").
+			Update(data).
+			Eq("todo_id", reminder.TodoID).
+			Eq("lease_owner", supabase.QuoteValue(owner)).
+			ExecuteTo(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("标记提醒失败: %w", err)
+	}
+	return nil
+}
+
+// ReleaseReminder 释放领取的提醒，retryAt 之前不会再被领取
+func (r *SupabaseTodoRepository) ReleaseReminder(ctx context.Context, owner string, reminder models.Reminder, retryAt time.Time) error {
+	log := logger.WithContext(ctx, r.logger)
+
+	data := map[string]interface{}{
+		"lease_owner": nil,
+		"lease_until": retryAt,
+		"attempts":    reminder.Attempts + 1,
+	}
+	err := r.retry.Do(ctx, log, "释放提醒", func(ctx context.Context, _ int) error {
+		_, err := r.client.From("todo_reminders").
+			Update(data).
+			Eq("todo_id", reminder.TodoID).
+			Eq("lease_owner", supabase.QuoteValue(owner)).
+			ExecuteTo(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("释放提醒失败: %w", err)
+	}
+	return nil
+}
+
+// CreateNotification 保存一条站内通知。
+// 按 (user_id, key) 忽略重复插入，之后读取该键对应的通知，因此重试不会产生重复的通知。
+func (r *SupabaseTodoRepository) CreateNotification(ctx context.Context, notification *models.Notification) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("创建站内通知",
+		zap.String("userID", notification.UserID),
+		zap.String("key", notification.Key))
+
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	data := map[string]interface{}{
+		"id":         notification.ID,
+		"user_id":    notification.UserID,
+		"kind":       notification.Kind,
+		"title":      notification.Title,
+		"due_at":     notification.DueAt,
+		"key":        notification.Key,
+		"created_at": notification.CreatedAt,
+	}
+	if notification.TodoID != "" {
+		data["todo_id"] = notification.TodoID
+	}
+
+	var rows []supabaseNotificationRow
+	err := r.retry.Do(ctx, log, "创建站内通知", func(ctx context.Context, _ int) error {
+		rows = nil
+		if _, err := r.client.From("notifications").Upsert(data, "user_id,key", true).ExecuteTo(ctx, nil); err != nil {
+			return err
+		}
+		_, err := r.client.From("notifications").
+			Select("*").
+			Eq("user_id", notification.UserID).
+			Eq("key", supabase.QuoteValue(notification.Key)).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("创建站内通知失败: %w", err)
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("创建站内通知失败: 未找到已保存的通知")
+	}
+
+	*notification = rows[0].toModel()
+	return nil
+}
+
+// ListNotifications 按创建时间倒序分页获取指定用户的站内通知
+func (r *SupabaseTodoRepository) ListNotifications(ctx context.Context, userID string, opts models.NotificationListOptions) (*models.NotificationPage, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("获取站内通知列表",
+		zap.String("userID", userID),
+		zap.Int("limit", opts.Limit))
+
+	var keyset string
+	if opts.After != nil {
+		// 通知表同样有 created_at 和 id 列，复用待办事项按创建时间倒序的条件
+		keys, err := todoSortKeys(models.SortByCreatedAt, models.SortDesc)
+		if err != nil {
+			return nil, err
+		}
+		if keyset, err = supabaseKeysetFilter(keys, opts.After); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		rows   []supabaseNotificationRow
+		unread int64
+	)
+	err := r.retry.Do(ctx, log, "获取站内通知列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		query := r.client.From("notifications").
+			Select("*").
+			Eq("user_id", userID).
+			Order("created_at", false).
+			Order("id", false).
+			Limit(opts.Limit + 1)
+		if opts.UnreadOnly {
+			query = query.Filter("read_at", "is", "null")
+		}
+		if keyset != "" {
+			query = query.Or(keyset)
+		}
+		if _, err := query.ExecuteTo(ctx, &rows); err != nil {
+			return err
+		}
+
+		var err error
+		unread, err = r.client.From("notifications").
+			Select("id").
+			Eq("user_id", userID).
+			Filter("read_at", "is", "null").
+			Limit(0).
+			Count().
+			ExecuteTo(ctx, &[]supabaseNotificationRow{})
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return newNotificationPage(nil, 0, opts), nil
+		}
+		return nil, fmt.Errorf("获取站内通知列表失败: %w", err)
+	}
+
+	notifications := make([]models.Notification, len(rows))
+	for i, row := range rows {
+		notifications[i] = row.toModel()
+	}
+
+	return newNotificationPage(notifications, int(unread), opts), nil
+}
+
+// MarkNotificationRead 将通知标记为已读。只更新未读的通知，已读的通知保持原来的已读时间。
+func (r *SupabaseTodoRepository) MarkNotificationRead(ctx context.Context, userID, id string, at time.Time) (*models.Notification, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("标记通知已读",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	var rows []supabaseNotificationRow
+	err := r.retry.Do(ctx, log, "标记通知已读", func(ctx context.Context, _ int) error {
+		rows = nil
+		if _, err := r.client.From("notifications").
+			Update(map[string]interface{}{"read_at": at}).
+			Eq("id", id).
+			Eq("user_id", userID).
+			Filter("read_at", "is", "null").
+			ExecuteTo(ctx, nil); err != nil {
+			return err
+		}
+		_, err := r.client.From("notifications").
+			Select("*").
+			Eq("id", id).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, fmt.Errorf("标记通知已读失败: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrNotificationNotFound
+	}
+
+	n := rows[0].toModel()
+	return &n, nil
+}
+
+// MarkAllNotificationsRead 将指定用户的所有未读通知标记为已读
+func (r *SupabaseTodoRepository) MarkAllNotificationsRead(ctx context.Context, userID string, at time.Time) (int, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("全部标记为已读", zap.String("userID", userID))
+
+	var updated []supabaseNotificationRow
+	err := r.retry.Do(ctx, log, "全部标记为已读", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("notifications").
+			Update(map[string]interface{}{"read_at": at}).
+			Eq("user_id", userID).
+			Filter("read_at", "is", "null").
+			ExecuteTo(ctx, &updated)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("标记通知已读失败: %w", err)
+	}
+	return len(updated), nil
+}
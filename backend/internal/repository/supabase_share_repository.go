@@ -0,0 +1,527 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var _ ShareRepository = (*SupabaseTodoRepository)(nil)
+
+// supabaseShareRow shares 表中的一行
+type supabaseShareRow struct {
+	ID        string    `json:"id"`
+	TodoID    *string   `json:"todo_id"`
+	ProjectID *string   `json:"project_id"`
+	OwnerID   string    `json:"owner_id"`
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// toModel 转换为 Share 实体
+func (row supabaseShareRow) toModel() models.Share {
+	share := models.Share{
+		ID:        row.ID,
+		OwnerID:   row.OwnerID,
+		UserID:    row.UserID,
+		Role:      models.ShareRole(row.Role),
+		CreatedBy: row.CreatedBy,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+	share.ResourceType, share.ResourceID = supabaseShareResource(row.TodoID, row.ProjectID)
+	return share
+}
+
+// supabaseInvitationRow invitations 表中的一行
+type supabaseInvitationRow struct {
+	ID        string    `json:"id"`
+	TodoID    *string   `json:"todo_id"`
+	ProjectID *string   `json:"project_id"`
+	OwnerID   string    `json:"owner_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	TokenHash string    `json:"token_hash"`
+	InvitedBy string    `json:"invited_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// toModel 转换为 Invitation 实体
+func (row supabaseInvitationRow) toModel() models.Invitation {
+	invitation := models.Invitation{
+		ID:        row.ID,
+		OwnerID:   row.OwnerID,
+		Email:     row.Email,
+		Role:      models.ShareRole(row.Role),
+		TokenHash: row.TokenHash,
+		InvitedBy: row.InvitedBy,
+		CreatedAt: row.CreatedAt,
+		ExpiresAt: row.ExpiresAt,
+	}
+	invitation.ResourceType, invitation.ResourceID = supabaseShareResource(row.TodoID, row.ProjectID)
+	return invitation
+}
+
+// supabaseShareResource 根据可为空的 todo_id 和 project_id 确定共享的资源
+func supabaseShareResource(todoID, projectID *string) (models.ShareResourceType, string) {
+	var todo, project string
+	if todoID != nil {
+		todo = *todoID
+	}
+	if projectID != nil {
+		project = *projectID
+	}
+	return shareResource(todo, project)
+}
+
+// TodoAccess 返回用户对待办事项的权限。先读取待办事项的所有者和项目，
+// 再查询用户对待办事项本身或所属项目的共享
+func (r *SupabaseTodoRepository) TodoAccess(ctx context.Context, userID, todoID string) (*models.Access, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var todos []supabaseTodoRow
+	err := r.retry.Do(ctx, log, "查询待办事项的权限", func(ctx context.Context, _ int) error {
+		todos = nil
+		_, err := r.client.From("todos").
+			Select("user_id,project_id").
+			Eq("id", todoID).
+			ExecuteTo(ctx, &todos)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseTodoError("查询待办事项的权限失败", err)
+	}
+	if len(todos) == 0 {
+		return nil, ErrTodoNotFound
+	}
+
+	ownerID := todos[0].UserID
+	if ownerID == userID {
+		access, _ := newAccess(userID, ownerID, nil)
+		return access, nil
+	}
+
+	filter := "todo_id.eq." + supabase.QuoteValue(todoID)
+	if todos[0].ProjectID != nil {
+		filter += ",project_id.eq." + supabase.QuoteValue(*todos[0].ProjectID)
+	}
+	roles, err := r.shareRoles(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("查询待办事项的权限失败: %w", err)
+	}
+	access, ok := newAccess(userID, ownerID, roles)
+	if !ok {
+		return nil, ErrTodoNotFound
+	}
+	return access, nil
+}
+
+// ProjectAccess 返回用户对项目的权限
+func (r *SupabaseTodoRepository) ProjectAccess(ctx context.Context, userID, projectID string) (*models.Access, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var projects []supabaseProjectRow
+	err := r.retry.Do(ctx, log, "查询项目的权限", func(ctx context.Context, _ int) error {
+		projects = nil
+		_, err := r.client.From("projects").
+			Select("user_id").
+			Eq("id", projectID).
+			ExecuteTo(ctx, &projects)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("查询项目的权限失败: %w", err)
+	}
+	if len(projects) == 0 {
+		return nil, ErrProjectNotFound
+	}
+
+	ownerID := projects[0].UserID
+	if ownerID == userID {
+		access, _ := newAccess(userID, ownerID, nil)
+		return access, nil
+	}
+
+	roles, err := r.shareRoles(ctx, userID, "project_id.eq."+supabase.QuoteValue(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("查询项目的权限失败: %w", err)
+	}
+	access, ok := newAccess(userID, ownerID, roles)
+	if !ok {
+		return nil, ErrProjectNotFound
+	}
+	return access, nil
+}
+
+// ListShares 获取资源的所有共享，按创建时间排序
+func (r *SupabaseTodoRepository) ListShares(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Share, error) {
+	return r.listShares(ctx, shareResourceColumn(resourceType), resourceID)
+}
+
+// ListSharedWith 获取共享给指定用户的所有资源，按创建时间排序
+func (r *SupabaseTodoRepository) ListSharedWith(ctx context.Context, userID string) ([]models.Share, error) {
+	return r.listShares(ctx, "user_id", userID)
+}
+
+// GetShare 获取资源的一条共享
+func (r *SupabaseTodoRepository) GetShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) (*models.Share, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseShareRow
+	err := r.retry.Do(ctx, log, "获取共享", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("shares").
+			Select("*").
+			Eq("id", id).
+			Eq(shareResourceColumn(resourceType), resourceID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseShareError("获取共享失败", err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrShareNotFound
+	}
+
+	share := rows[0].toModel()
+	return &share, nil
+}
+
+// DeleteShare 撤销资源的一条共享
+func (r *SupabaseTodoRepository) DeleteShare(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("撤销共享",
+		zap.String("resourceType", string(resourceType)),
+		zap.String("resourceID", resourceID),
+		zap.String("id", id))
+
+	var (
+		deleted []supabaseShareRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "撤销共享", func(ctx context.Context, attempt int) error {
+		deleted = nil
+		retried = attempt > 1
+		_, err := r.client.From("shares").
+			Delete().
+			Eq("id", id).
+			Eq(shareResourceColumn(resourceType), resourceID).
+			ExecuteTo(ctx, &deleted)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseShareError("撤销共享失败", err)
+	}
+
+	// 重试时没有删除任何行，说明之前失败的那次尝试实际已经删除成功
+	if len(deleted) == 0 && !retried {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// CreateInvitation 由客户端生成 ID 插入邀请，资源不存在时违反外键。
+// 重试时遇到唯一约束冲突说明之前的尝试已经成功，直接读取已创建的邀请
+func (r *SupabaseTodoRepository) CreateInvitation(ctx context.Context, invitation *models.Invitation) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("创建邀请",
+		zap.String("resourceType", string(invitation.ResourceType)),
+		zap.String("resourceID", invitation.ResourceID),
+		zap.String("invitedBy", invitation.InvitedBy))
+
+	if invitation.ID == "" {
+		invitation.ID = uuid.New().String()
+	}
+	if invitation.CreatedAt.IsZero() {
+		invitation.CreatedAt = time.Now()
+	}
+
+	data := map[string]interface{}{
+		"id":         invitation.ID,
+		"owner_id":   invitation.OwnerID,
+		"email":      invitation.Email,
+		"role":       string(invitation.Role),
+		"token_hash": invitation.TokenHash,
+		"invited_by": invitation.InvitedBy,
+		"created_at": invitation.CreatedAt,
+		"expires_at": invitation.ExpiresAt,
+		shareResourceColumn(invitation.ResourceType): invitation.ResourceID,
+	}
+
+	var created []supabaseInvitationRow
+	err := r.retry.Do(ctx, log, "创建邀请", func(ctx context.Context, attempt int) error {
+		created = nil
+		_, err := r.client.From("invitations").Insert(data).ExecuteTo(ctx, &created)
+		if attempt > 1 && isSupabaseError(err, supabase.CodeUniqueViolation) {
+			log.Info("重试时发现邀请已创建", zap.String("id", invitation.ID))
+			_, err = r.client.From("invitations").
+				Select("*").
+				Eq("id", invitation.ID).
+				ExecuteTo(ctx, &created)
+		}
+		return err
+	})
+	if err != nil {
+		// 资源不存在时违反外键，ID 不是合法的 UUID 时同样视为不存在
+		if isSupabaseError(err, supabase.CodeForeignKeyViolation) || isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return shareResourceNotFound(invitation.ResourceType)
+		}
+		return fmt.Errorf("创建邀请失败: %w", err)
+	}
+
+	if len(created) > 0 {
+		*invitation = created[0].toModel()
+	}
+	return nil
+}
+
+// ListInvitations 获取资源的所有邀请，按创建时间排序
+func (r *SupabaseTodoRepository) ListInvitations(ctx context.Context, resourceType models.ShareResourceType, resourceID string) ([]models.Invitation, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseInvitationRow
+	err := r.retry.Do(ctx, log, "获取邀请列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("invitations").
+			Select("*").
+			Eq(shareResourceColumn(resourceType), resourceID).
+			Order("created_at", true).
+			Order("id", true).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return []models.Invitation{}, nil
+		}
+		return nil, fmt.Errorf("获取邀请列表失败: %w", err)
+	}
+
+	invitations := make([]models.Invitation, len(rows))
+	for i, row := range rows {
+		invitations[i] = row.toModel()
+	}
+	return invitations, nil
+}
+
+// GetInvitationByToken 按令牌的 SHA-256 获取邀请
+func (r *SupabaseTodoRepository) GetInvitationByToken(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseInvitationRow
+	err := r.retry.Do(ctx, log, "获取邀请", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("invitations").
+			Select("*").
+			Eq("token_hash", tokenHash).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取邀请失败: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrInvitationNotFound
+	}
+
+	invitation := rows[0].toModel()
+	return &invitation, nil
+}
+
+// DeleteInvitation 撤销资源的一条邀请
+func (r *SupabaseTodoRepository) DeleteInvitation(ctx context.Context, resourceType models.ShareResourceType, resourceID, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("撤销邀请",
+		zap.String("resourceType", string(resourceType)),
+		zap.String("resourceID", resourceID),
+		zap.String("id", id))
+
+	var (
+		deleted []supabaseInvitationRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "撤销邀请", func(ctx context.Context, attempt int) error {
+		deleted = nil
+		retried = attempt > 1
+		_, err := r.client.From("invitations").
+			Delete().
+			Eq("id", id).
+			Eq(shareResourceColumn(resourceType), resourceID).
+			ExecuteTo(ctx, &deleted)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseInvitationError("撤销邀请失败", err)
+	}
+
+	if len(deleted) == 0 && !retried {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation 删除邀请并把资源共享给 userID。PostgREST 不支持事务，
+// 先删除邀请保证令牌只能使用一次，再插入共享；已有共享时只在邀请的角色更高时升级
+func (r *SupabaseTodoRepository) AcceptInvitation(ctx context.Context, invitation *models.Invitation, userID string) (*models.Share, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("接受邀请",
+		zap.String("userID", userID),
+		zap.String("invitationID", invitation.ID))
+
+	var (
+		deleted []supabaseInvitationRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "删除邀请", func(ctx context.Context, attempt int) error {
+		deleted = nil
+		retried = attempt > 1
+		_, err := r.client.From("invitations").
+			Delete().
+			Eq("id", invitation.ID).
+			ExecuteTo(ctx, &deleted)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseInvitationError("删除邀请失败", err)
+	}
+	if len(deleted) == 0 && !retried {
+		return nil, ErrInvitationNotFound
+	}
+
+	column := shareResourceColumn(invitation.ResourceType)
+	now := time.Now()
+	data := map[string]interface{}{
+		"id":         uuid.New().String(),
+		"owner_id":   invitation.OwnerID,
+		"user_id":    userID,
+		"role":       string(invitation.Role),
+		"created_by": invitation.InvitedBy,
+		"created_at": now,
+		"updated_at": now,
+		column:       invitation.ResourceID,
+	}
+
+	var shares []supabaseShareRow
+	err = r.retry.Do(ctx, log, "创建共享", func(ctx context.Context, _ int) error {
+		shares = nil
+		_, err := r.client.From("shares").Insert(data).ExecuteTo(ctx, &shares)
+		if !isSupabaseError(err, supabase.CodeUniqueViolation) {
+			return err
+		}
+
+		// 用户已经有这个资源的共享，或者之前的某次尝试已经插入成功
+		if below := shareRolesBelow(invitation.Role); len(below) > 0 {
+			if _, err := r.client.From("shares").
+				Update(map[string]interface{}{"role": string(invitation.Role), "updated_at": now}).
+				Eq(column, invitation.ResourceID).
+				Eq("user_id", userID).
+				In("role", below).
+				ExecuteTo(ctx, &shares); err != nil {
+				return err
+			}
+		}
+		shares = nil
+		_, err = r.client.From("shares").
+			Select("*").
+			Eq(column, invitation.ResourceID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &shares)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeForeignKeyViolation) {
+			return nil, shareResourceNotFound(invitation.ResourceType)
+		}
+		return nil, fmt.Errorf("创建共享失败: %w", err)
+	}
+	if len(shares) == 0 {
+		return nil, shareResourceNotFound(invitation.ResourceType)
+	}
+
+	share := shares[0].toModel()
+	return &share, nil
+}
+
+// shareRoles 获取用户满足 filter 条件的共享的角色
+func (r *SupabaseTodoRepository) shareRoles(ctx context.Context, userID, filter string) ([]models.ShareRole, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseShareRow
+	err := r.retry.Do(ctx, log, "获取共享角色", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("shares").
+			Select("role").
+			Eq("user_id", userID).
+			Or(filter).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]models.ShareRole, len(rows))
+	for i, row := range rows {
+		roles[i] = models.ShareRole(row.Role)
+	}
+	return roles, nil
+}
+
+// listShares 按一列的值获取共享，按创建时间排序
+func (r *SupabaseTodoRepository) listShares(ctx context.Context, column, value string) ([]models.Share, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseShareRow
+	err := r.retry.Do(ctx, log, "获取共享列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("shares").
+			Select("*").
+			Eq(column, value).
+			Order("created_at", true).
+			Order("id", true).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return []models.Share{}, nil
+		}
+		return nil, fmt.Errorf("获取共享列表失败: %w", err)
+	}
+
+	shares := make([]models.Share, len(rows))
+	for i, row := range rows {
+		shares[i] = row.toModel()
+	}
+	return shares, nil
+}
+
+// mapSupabaseShareError 将 PostgREST 错误转换为仓库层错误，ID 不是合法的 UUID 视为共享不存在
+func mapSupabaseShareError(operation string, err error) error {
+	if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+		return ErrShareNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
+// mapSupabaseInvitationError 将 PostgREST 错误转换为仓库层错误，ID 不是合法的 UUID 视为邀请不存在
+func mapSupabaseInvitationError(operation string, err error) error {
+	if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+		return ErrInvitationNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var _ SubtaskRepository = (*SupabaseTodoRepository)(nil)
+
+// supabaseSubtaskRow subtasks 表中的一行
+type supabaseSubtaskRow struct {
+	ID        string    `json:"id"`
+	TodoID    string    `json:"todo_id"`
+	UserID    string    `json:"user_id"`
+	Title     string    `json:"title"`
+	Completed bool      `json:"completed"`
+	Position  string    `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// toModel 转换为 Subtask 实体
+func (row supabaseSubtaskRow) toModel() models.Subtask {
+	return models.Subtask{
+		ID:        row.ID,
+		TodoID:    row.TodoID,
+		UserID:    row.UserID,
+		Title:     row.Title,
+		Completed: row.Completed,
+		Position:  row.Position,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+// ListSubtasks 获取待办事项的所有子任务，按位置排序
+func (r *SupabaseTodoRepository) ListSubtasks(ctx context.Context, userID, todoID string) ([]models.Subtask, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseSubtaskRow
+	err := r.retry.Do(ctx, log, "获取子任务列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("subtasks").
+			Select("*").
+			Eq("todo_id", todoID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return []models.Subtask{}, nil
+		}
+		return nil, fmt.Errorf("获取子任务列表失败: %w", err)
+	}
+
+	subtasks := make([]models.Subtask, len(rows))
+	for i, row := range rows {
+		subtasks[i] = row.toModel()
+	}
+	models.SortSubtasks(subtasks)
+	return subtasks, nil
+}
+
+// SubtaskProgress 批量统计待办事项的子任务进度，只读取完成状态一列，在本地计数
+func (r *SupabaseTodoRepository) SubtaskProgress(ctx context.Context, userID string, todoIDs []string) (map[string]models.SubtaskProgress, error) {
+	result := make(map[string]models.SubtaskProgress)
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseSubtaskRow
+	err := r.retry.Do(ctx, log, "统计子任务进度", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("subtasks").
+			Select("todo_id,completed").
+			Eq("user_id", userID).
+			In("todo_id", todoIDs).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("统计子任务进度失败: %w", err)
+	}
+
+	for _, row := range rows {
+		progress := result[row.TodoID]
+		progress.Total++
+		if row.Completed {
+			progress.Completed++
+		}
+		result[row.TodoID] = progress
+	}
+	return result, nil
+}
+
+// CreateSubtask 创建子任务。先确认待办事项属于该用户，再由客户端生成 ID 插入，
+// 重试时遇到唯一约束冲突说明之前的尝试已经成功，直接读取已创建的子任务
+func (r *SupabaseTodoRepository) CreateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("创建子任务",
+		zap.String("userID", userID),
+		zap.String("todoID", subtask.TodoID),
+		zap.String("title", subtask.Title))
+
+	if _, err := r.Get(ctx, userID, subtask.TodoID); err != nil {
+		return err
+	}
+
+	if subtask.ID == "" {
+		subtask.ID = uuid.New().String()
+	}
+	if subtask.CreatedAt.IsZero() {
+		subtask.CreatedAt = time.Now()
+	}
+
+	data := map[string]interface{}{
+		"id":         subtask.ID,
+		"todo_id":    subtask.TodoID,
+		"user_id":    userID,
+		"title":      subtask.Title,
+		"completed":  subtask.Completed,
+		"position":   subtask.Position,
+		"created_at": subtask.CreatedAt,
+		"updated_at": subtask.CreatedAt,
+	}
+
+	var created []supabaseSubtaskRow
+	err := r.retry.Do(ctx, log, "创建子任务", func(ctx context.Context, attempt int) error {
+		created = nil
+		_, err := r.client.From("subtasks").Insert(data).ExecuteTo(ctx, &created)
+		if attempt > 1 && isSupabaseError(err, supabase.CodeUniqueViolation) {
+			log.Info("重试时发现子任务已创建", zap.String("id", subtask.ID))
+			_, err = r.client.From("subtasks").
+				Select("*").
+				Eq("id", subtask.ID).
+				Eq("user_id", userID).
+				ExecuteTo(ctx, &created)
+		}
+		return err
+	})
+	if err != nil {
+		// 检查之后待办事项被删除时违反外键
+		if isSupabaseError(err, supabase.CodeForeignKeyViolation) {
+			return ErrTodoNotFound
+		}
+		return fmt.Errorf("创建子任务失败: %w", err)
+	}
+
+	if len(created) > 0 {
+		*subtask = created[0].toModel()
+	}
+	return nil
+}
+
+// UpdateSubtask 修改子任务的标题、完成状态和位置，写入的是固定值，可以安全地重试
+func (r *SupabaseTodoRepository) UpdateSubtask(ctx context.Context, userID string, subtask *models.Subtask) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("更新子任务",
+		zap.String("userID", userID),
+		zap.String("todoID", subtask.TodoID),
+		zap.String("id", subtask.ID))
+
+	data := map[string]interface{}{
+		"title":      subtask.Title,
+		"completed":  subtask.Completed,
+		"position":   subtask.Position,
+		"updated_at": time.Now(),
+	}
+
+	var updated []supabaseSubtaskRow
+	err := r.retry.Do(ctx, log, "更新子任务", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("subtasks").
+			Update(data).
+			Eq("id", subtask.ID).
+			Eq("todo_id", subtask.TodoID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseSubtaskError("更新子任务失败", err)
+	}
+	if len(updated) == 0 {
+		return ErrSubtaskNotFound
+	}
+
+	*subtask = updated[0].toModel()
+	return nil
+}
+
+// DeleteSubtask 删除待办事项的一个子任务
+func (r *SupabaseTodoRepository) DeleteSubtask(ctx context.Context, userID, todoID, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("删除子任务",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.String("id", id))
+
+	var (
+		deleted []supabaseSubtaskRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "删除子任务", func(ctx context.Context, attempt int) error {
+		deleted = nil
+		retried = attempt > 1
+		_, err := r.client.From("subtasks").
+			Delete().
+			Eq("id", id).
+			Eq("todo_id", todoID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &deleted)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseSubtaskError("删除子任务失败", err)
+	}
+
+	// 重试时没有删除任何行，说明之前失败的那次尝试实际已经删除成功
+	if len(deleted) == 0 && !retried {
+		return ErrSubtaskNotFound
+	}
+	return nil
+}
+
+// mapSupabaseSubtaskError 将 PostgREST 错误转换为仓库层错误，ID 不是合法的 UUID 视为子任务不存在
+func mapSupabaseSubtaskError(operation string, err error) error {
+	if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+		return ErrSubtaskNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// supabaseTagRow tags 表中的一行
+type supabaseTagRow struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// toModel 转换为 Tag 实体
+func (row supabaseTagRow) toModel() models.Tag {
+	return models.Tag{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Name:      row.Name,
+		Color:     row.Color,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+// supabaseTodoTagRow todo_tags 表中的一行，嵌入了关联的标签
+type supabaseTodoTagRow struct {
+	TodoID string         `json:"todo_id"`
+	Tag    supabaseTagRow `json:"tag"`
+}
+
+// ListTags 获取指定用户的所有标签，按名称排序
+func (r *SupabaseTodoRepository) ListTags(ctx context.Context, userID string) ([]models.Tag, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("获取标签列表", zap.String("userID", userID))
+
+	var rows []supabaseTagRow
+	err := r.retry.Do(ctx, log, "获取标签列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("tags").
+			Select("*").
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return []models.Tag{}, nil
+		}
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+
+	tags := make([]models.Tag, len(rows))
+	for i, row := range rows {
+		tags[i] = row.toModel()
+	}
+	models.SortTags(tags)
+	return tags, nil
+}
+
+// GetTag 获取指定用户的单个标签
+func (r *SupabaseTodoRepository) GetTag(ctx context.Context, userID, id string) (*models.Tag, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseTagRow
+	err := r.retry.Do(ctx, log, "获取标签", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("tags").
+			Select("*").
+			Eq("id", id).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseTagError("获取标签失败", err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrTagNotFound
+	}
+
+	tag := rows[0].toModel()
+	return &tag, nil
+}
+
+// CreateTag 创建标签。与待办事项一样由客户端生成 ID：重试时遇到唯一约束冲突，
+// 先按 ID 读取，读到说明之前的尝试已经成功，否则是同名标签已存在。
+func (r *SupabaseTodoRepository) CreateTag(ctx context.Context, userID string, tag *models.Tag) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("创建标签",
+		zap.String("userID", userID),
+		zap.String("name", tag.Name))
+
+	if tag.ID == "" {
+		tag.ID = uuid.New().String()
+	}
+	if tag.CreatedAt.IsZero() {
+		tag.CreatedAt = time.Now()
+	}
+
+	data := map[string]interface{}{
+		"id":         tag.ID,
+		"user_id":    userID,
+		"name":       tag.Name,
+		"color":      tag.Color,
+		"created_at": tag.CreatedAt,
+		"updated_at": tag.CreatedAt,
+	}
+
+	var created []supabaseTagRow
+	err := r.retry.Do(ctx, log, "创建标签", func(ctx context.Context, attempt int) error {
+		created = nil
+		_, err := r.client.From("tags").Insert(data).ExecuteTo(ctx, &created)
+		if attempt > 1 && isSupabaseError(err, supabase.CodeUniqueViolation) {
+			if _, readErr := r.client.From("tags").
+				Select("*").
+				Eq("id", tag.ID).
+				Eq("user_id", userID).
+				ExecuteTo(ctx, &created); readErr != nil {
+				return readErr
+			}
+			if len(created) > 0 {
+				log.Info("重试时发现标签已创建", zap.String("id", tag.ID))
+				return nil
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return mapSupabaseTagError("创建标签失败", err)
+	}
+
+	if len(created) > 0 {
+		*tag = created[0].toModel()
+	}
+	return nil
+}
+
+// UpdateTag 修改标签的名称和颜色，写入的是固定值，可以安全地重试
+func (r *SupabaseTodoRepository) UpdateTag(ctx context.Context, userID string, tag *models.Tag) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("更新标签",
+		zap.String("userID", userID),
+		zap.String("id", tag.ID))
+
+	data := map[string]interface{}{
+		"name":       tag.Name,
+		"color":      tag.Color,
+		"updated_at": time.Now(),
+	}
+
+	var updated []supabaseTagRow
+	err := r.retry.Do(ctx, log, "更新标签", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("tags").
+			Update(data).
+			Eq("id", tag.ID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseTagError("更新标签失败", err)
+	}
+	if len(updated) == 0 {
+		return ErrTagNotFound
+	}
+
+	*tag = updated[0].toModel()
+	return nil
+}
+
+// DeleteTag 删除标签，关联由外键级联删除
+func (r *SupabaseTodoRepository) DeleteTag(ctx context.Context, userID, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("删除标签",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	var (
+		deleted []supabaseTagRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "删除标签", func(ctx context.Context, attempt int) error {
+		deleted = nil
+		retried = attempt > 1
+		_, err := r.client.From("tags").
+			Delete().
+			Eq("id", id).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &deleted)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseTagError("删除标签失败", err)
+	}
+
+	// 重试时没有删除任何行，说明之前失败的那次尝试实际已经删除成功
+	if len(deleted) == 0 && !retried {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// TodoTags 批量获取待办事项的标签，通过外键嵌入关联的标签，一次请求完成
+func (r *SupabaseTodoRepository) TodoTags(ctx context.Context, userID string, todoIDs []string) (map[string][]models.Tag, error) {
+	result := make(map[string][]models.Tag)
+	if len(todoIDs) == 0 {
+		return result, nil
+	}
+
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseTodoTagRow
+	err := r.retry.Do(ctx, log, "获取待办事项的标签", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("todo_tags").
+			Select("todo_id,tag:tags(*)").
+			Eq("user_id", userID).
+			In("todo_id", todoIDs).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return result, nil
+		}
+		return nil, fmt.Errorf("获取待办事项的标签失败: %w", err)
+	}
+
+	for _, row := range rows {
+		result[row.TodoID] = append(result[row.TodoID], row.Tag.toModel())
+	}
+	for _, tags := range result {
+		models.SortTags(tags)
+	}
+	return result, nil
+}
+
+// SetTodoTags 把待办事项的标签替换为 tagIDs。
+// PostgREST 的请求之间没有事务：先添加新的关联，再删除不在 tagIDs 中的关联，
+// 两步都是幂等的，可以安全地重试；添加失败时原有的关联保持不变。
+func (r *SupabaseTodoRepository) SetTodoTags(ctx context.Context, userID, todoID string, tagIDs []string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("设置待办事项的标签",
+		zap.String("userID", userID),
+		zap.String("todoID", todoID),
+		zap.Strings("tagIDs", tagIDs))
+
+	if _, err := r.Get(ctx, userID, todoID); err != nil {
+		return err
+	}
+	if err := r.insertTodoTags(ctx, log, userID, todoID, tagIDs); err != nil {
+		return err
+	}
+
+	err := r.retry.Do(ctx, log, "设置待办事项的标签", func(ctx context.Context, _ int) error {
+		query := r.client.From("todo_tags").
+			Delete().
+			Eq("todo_id", todoID).
+			Eq("user_id", userID)
+		if len(tagIDs) > 0 {
+			query = query.NotIn("tag_id", tagIDs)
+		}
+		_, err := query.ExecuteTo(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseTagError("设置待办事项的标签失败", err)
+	}
+	return nil
+}
+
+// AddTodoTag 给待办事项添加一个标签
+func (r *SupabaseTodoRepository) AddTodoTag(ctx context.Context, userID, todoID, tagID string) error {
+	log := logger.WithContext(ctx, r.logger)
+
+	if _, err := r.Get(ctx, userID, todoID); err != nil {
+		return err
+	}
+	return r.insertTodoTags(ctx, log, userID, todoID, []string{tagID})
+}
+
+// RemoveTodoTag 去掉待办事项的一个标签
+func (r *SupabaseTodoRepository) RemoveTodoTag(ctx context.Context, userID, todoID, tagID string) error {
+	log := logger.WithContext(ctx, r.logger)
+
+	if _, err := r.Get(ctx, userID, todoID); err != nil {
+		return err
+	}
+
+	err := r.retry.Do(ctx, log, "去掉待办事项的标签", func(ctx context.Context, _ int) error {
+		_, err := r.client.From("todo_tags").
+			Delete().
+			Eq("todo_id", todoID).
+			Eq("tag_id", tagID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseTagError("去掉待办事项的标签失败", err)
+	}
+	return nil
+}
+
+// insertTodoTags 添加关联，按主键忽略已存在的关联，因此可以安全地重试
+func (r *SupabaseTodoRepository) insertTodoTags(ctx context.Context, log *zap.Logger, userID, todoID string, tagIDs []string) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	data := make([]map[string]interface{}, len(tagIDs))
+	for i, tagID := range tagIDs {
+		data[i] = map[string]interface{}{
+			"todo_id": todoID,
+			"tag_id":  tagID,
+			"user_id": userID,
+		}
+	}
+
+	err := r.retry.Do(ctx, log, "添加待办事项的标签", func(ctx context.Context, _ int) error {
+		_, err := r.client.From("todo_tags").Upsert(data, "todo_id,tag_id", true).ExecuteTo(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseTagError("添加待办事项的标签失败", err)
+	}
+	return nil
+}
+
+// mapSupabaseTagError 将 PostgREST 错误转换为仓库层错误。
+// ID 不是合法的 UUID 或关联时违反外键，都视为标签不存在
+func mapSupabaseTagError(operation string, err error) error {
+	switch {
+	case isSupabaseError(err, supabase.CodeInvalidTextInput), isSupabaseError(err, supabase.CodeForeignKeyViolation):
+		return ErrTagNotFound
+	case isSupabaseError(err, supabase.CodeUniqueViolation):
+		return ErrTagAlreadyExists
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
@@ -1,268 +1,596 @@
 package repository
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Brower/backend/internal/config"
 	"github.com/Brower/backend/internal/logger"
 	"github.com/Brower/backend/internal/models"
-	"github.com/supabase-community/postgrest-go"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
-const (
-	maxRetries = 3
-	retryDelay = 100 * time.Millisecond
-)
+// supabaseTodoRow todos 表中的一行，列名使用下划线命名
+type supabaseTodoRow struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Title     string     `json:"title"`
+	Completed bool       `json:"completed"`
+	DueAt     *time.Time `json:"due_at"`
+	RemindAt  *time.Time `json:"remind_at"`
+	SeriesID  *string    `json:"series_id"`
+	// 重复规则的三列要么都为空，要么都不为空
+	RecurrenceRule     *string    `json:"recurrence_rule"`
+	RecurrenceTimezone *string    `json:"recurrence_timezone"`
+	RecurrenceStart    *time.Time `json:"recurrence_start"`
+	// Priority 以整数存储，JSON 中同样是数值
+	Priority     int       `json:"priority"`
+	Position     string    `json:"position"`
+	ProjectID    *string   `json:"project_id"`
+	AutoComplete bool      `json:"auto_complete"`
+	Notes        string    `json:"notes"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// toModel 转换为 Todo 实体
+func (row supabaseTodoRow) toModel() models.Todo {
+	todo := models.Todo{
+		ID:           row.ID,
+		UserID:       row.UserID,
+		Title:        row.Title,
+		Completed:    row.Completed,
+		DueAt:        row.DueAt,
+		RemindAt:     row.RemindAt,
+		Priority:     models.Priority(row.Priority),
+		Position:     row.Position,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+		AutoComplete: row.AutoComplete,
+		Notes:        row.Notes,
+	}
+	if row.SeriesID != nil {
+		todo.SeriesID = *row.SeriesID
+	}
+	if row.ProjectID != nil {
+		todo.ProjectID = *row.ProjectID
+	}
+	todo.Recurrence = newRecurrence(row.RecurrenceRule, row.RecurrenceTimezone, row.RecurrenceStart)
+	return todo
+}
+
+// supabaseRecurrenceData 返回写入系列和重复规则列的数据
+func supabaseRecurrenceData(todo *models.Todo) map[string]interface{} {
+	seriesID, rule, timezone, start := recurrenceArgs(todo)
+	return map[string]interface{}{
+		"series_id":           seriesID,
+		"recurrence_rule":     rule,
+		"recurrence_timezone": timezone,
+		"recurrence_start":    start,
+	}
+}
+
+// supabaseSearchRow search_todos 函数返回的一行，todo 为 todos 表的整行
+type supabaseSearchRow struct {
+	Todo  supabaseTodoRow `json:"todo"`
+	Score float32         `json:"score"`
+	Total int64           `json:"total"`
+}
 
 // SupabaseTodoRepository 是一个使用 Supabase 实现的 TodoRepository
 type SupabaseTodoRepository struct {
-	client *postgrest.Client
+	client *supabase.Client
+	retry  RetryPolicy
 	logger *zap.Logger
 }
 
 // NewSupabaseTodoRepository 创建一个新的 SupabaseTodoRepository
 func NewSupabaseTodoRepository(cfg *config.Config) (*SupabaseTodoRepository, error) {
-	baseURL := fmt.Sprintf("https://%s/rest/v1", cfg.Supabase.BaseURL)
-	client := postgrest.NewClient(
-		baseURL,
-		cfg.Supabase.ServiceRoleKey,
-		map[string]string{
-			"apikey":          cfg.Supabase.ServiceRoleKey,
-			"Authorization":   "Bearer " + cfg.Supabase.ServiceRoleKey,
-			"Content-Type":    "application/json",
-			"Accept":          "application/json",
-			"Accept-Profile":  "public",
-			"Content-Profile": "public",
-			"Prefer":          "return=representation",
-		},
-	)
+	client, err := supabase.NewClient(&cfg.Supabase)
+	if err != nil {
+		return nil, err
+	}
 
 	logger.Info("初始化 Supabase Todo Repository",
-		zap.String("baseURL", baseURL),
+		zap.String("baseURL", client.RestURL()),
 		zap.String("projectID", cfg.Supabase.ProjectID))
 
 	return &SupabaseTodoRepository{
 		client: client,
+		retry:  NewRetryPolicy(cfg.Supabase.Retry, operationTimeout(cfg.Database.OperationTimeout)),
 		logger: logger.Log.With(zap.String("component", "SupabaseTodoRepository")),
 	}, nil
 }
 
-// withRetry 包装一个操作，添加重试机制
-func (r *SupabaseTodoRepository) withRetry(operation string, fn func() error) error {
-	var lastErr error
-	for i := 0; i < maxRetries; i++ {
-		if i > 0 {
-			logger.Warn("操作重试",
-				zap.String("operation", operation),
-				zap.Int("attempt", i+1),
-				zap.Error(lastErr),
-			)
-			time.Sleep(retryDelay * time.Duration(i))
-		}
+// List 按过滤条件、排序和游标分页获取指定用户的待办事项。
+// 第一页在同一个请求中统计总数；之后的页面带有游标条件，需要单独统计。
+func (r *SupabaseTodoRepository) List(ctx context.Context, userID string, opts models.TodoListOptions) (*models.TodoPage, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("获取待办事项列表",
+		zap.String("userID", userID),
+		zap.String("sortBy", string(opts.SortBy)),
+		zap.String("sortDir", string(opts.SortDir)),
+		zap.Int("limit", opts.Limit))
 
-		err := fn()
-		if err == nil {
-			return nil
+	keys, err := todoSortKeys(opts.SortBy, opts.SortDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keyset string
+	if opts.After != nil {
+		if keyset, err = supabaseKeysetFilter(keys, opts.After); err != nil {
+			return nil, err
 		}
-		lastErr = err
 	}
-	return fmt.Errorf("%s: 重试%d次后失败: %w", operation, maxRetries, lastErr)
-}
 
-// List 获取指定用户的所有待办事项
-func (r *SupabaseTodoRepository) List(userID string) ([]models.Todo, error) {
-	r.logger.Info("获取待办事项列表", zap.String("userID", userID))
+	var (
+		rows  []supabaseTodoRow
+		total int64
+	)
+	err = r.retry.Do(ctx, log, "获取待办事项列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		query := applySupabaseTodoFilter(r.client.From("todos").Select(supabaseTodoSelect("*", opts.Filter)), userID, opts.Filter)
+		for _, key := range keys {
+			query = query.Order(key.column, !key.desc)
+		}
+		query = query.Limit(opts.Limit + 1)
+		if keyset == "" {
+			query = query.Count()
+		} else {
+			query = query.Or(keyset)
+		}
 
-	var todos []*models.Todo
-	data, _, err := r.client.From("todos").
-		Select("*", "", false).
-		Filter("user_id", "eq", userID).
-		Order("created_at", &postgrest.OrderOpts{Ascending: false}).
-		Execute()
+		count, err := query.ExecuteTo(ctx, &rows)
+		if err != nil {
+			return err
+		}
+		total = count
+		if keyset == "" {
+			return nil
+		}
+
+		total, err = applySupabaseTodoFilter(r.client.From("todos").Select(supabaseTodoSelect("id", opts.Filter)), userID, opts.Filter).
+			Limit(0).
+			Count().
+			ExecuteTo(ctx, &[]supabaseTodoRow{})
+		return err
+	})
 	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return newTodoPage(nil, 0, opts), nil
+		}
 		return nil, fmt.Errorf("获取待办事项列表失败: %w", err)
 	}
 
-	if err := json.Unmarshal(data, &todos); err != nil {
-		return nil, fmt.Errorf("解析待办事项列表失败: %w", err)
+	todos := make([]models.Todo, len(rows))
+	for i, row := range rows {
+		todos[i] = row.toModel()
+	}
+
+	return newTodoPage(todos, int(total), opts), nil
+}
+
+// Search 通过 RPC 调用 search_todos 函数按相关度搜索指定用户的待办事项。
+// 函数只读取数据，失败时可以安全地重试。
+func (r *SupabaseTodoRepository) Search(ctx context.Context, userID string, opts models.TodoSearchOptions) (*models.TodoSearchPage, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("搜索待办事项",
+		zap.String("userID", userID),
+		zap.String("query", opts.Query),
+		zap.Int("limit", opts.Limit))
+
+	params := map[string]interface{}{
+		"p_user_id":   userID,
+		"p_query":     opts.Query,
+		"p_completed": opts.Completed,
+		"p_limit":     opts.Limit + 1,
+	}
+	if opts.After != nil {
+		score, err := opts.After.Score()
+		if err != nil {
+			return nil, err
+		}
+		params["p_after_score"] = float32(score)
+		params["p_after_id"] = opts.After.ID
+	}
+
+	var rows []supabaseSearchRow
+	err := r.retry.Do(ctx, log, "搜索待办事项", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.RPC("search_todos", params).ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return newTodoSearchPage(nil, 0, opts), nil
+		}
+		return nil, fmt.Errorf("搜索待办事项失败: %w", err)
 	}
 
-	// 转换为非指针切片
-	result := make([]models.Todo, len(todos))
-	for i, todo := range todos {
-		result[i] = *todo
+	// 总数随每一行返回，游标之后没有结果时无法得知，此时为 0
+	var total int64
+	hits := make([]models.TodoSearchHit, len(rows))
+	for i, row := range rows {
+		hits[i] = models.TodoSearchHit{Todo: row.Todo.toModel(), Score: float64(row.Score)}
+		total = row.Total
 	}
 
-	return result, nil
+	return newTodoSearchPage(hits, int(total), opts), nil
 }
 
 // Get 获取指定用户的单个待办事项
-func (r *SupabaseTodoRepository) Get(userID string, id string) (*models.Todo, error) {
-	r.logger.Info("获取待办事项",
+func (r *SupabaseTodoRepository) Get(ctx context.Context, userID string, id string) (*models.Todo, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("获取待办事项",
 		zap.String("userID", userID),
 		zap.String("id", id))
 
-	var todos []*models.Todo
-	data, _, err := r.client.From("todos").
-		Select("*", "", false).
-		Filter("id", "eq", id).
-		Filter("user_id", "eq", userID).
-		Execute()
+	var rows []supabaseTodoRow
+	err := r.retry.Do(ctx, log, "获取待办事项", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("todos").
+			Select("*").
+			Eq("id", id).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("获取待办事项失败: %w", err)
+		return nil, mapSupabaseTodoError("获取待办事项失败", err)
 	}
 
-	if err := json.Unmarshal(data, &todos); err != nil {
-		return nil, fmt.Errorf("解析待办事项失败: %w", err)
+	if len(rows) == 0 {
+		return nil, ErrTodoNotFound
 	}
 
-	if len(todos) == 0 {
-		return nil, fmt.Errorf("待办事项不存在")
-	}
-
-	return todos[0], nil
+	todo := rows[0].toModel()
+	return &todo, nil
 }
 
-// Create 创建一个新的待办事项
-func (r *SupabaseTodoRepository) Create(userID string, todo *models.Todo) error {
-	r.logger.Info("创建待办事项",
+// Create 创建一个新的待办事项。
+// 插入不是幂等操作，因此总是由客户端生成 ID：如果重试时遇到主键冲突，
+// 说明之前的某次尝试实际已经成功，直接读取已创建的记录，而不是重复插入。
+func (r *SupabaseTodoRepository) Create(ctx context.Context, userID string, todo *models.Todo) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("创建待办事项",
 		zap.String("userID", userID),
 		zap.String("title", todo.Title))
 
+	if todo.ID == "" {
+		todo.ID = uuid.New().String()
+	}
 	todo.UserID = userID
 
 	// 使用下划线命名的时间字段
 	now := time.Now()
 	todoData := map[string]interface{}{
-		"user_id":    todo.UserID,
-		"title":      todo.Title,
-		"completed":  todo.Completed,
-		"created_at": now,
-		"updated_at": now,
+		"id":            todo.ID,
+		"user_id":       todo.UserID,
+		"title":         todo.Title,
+		"completed":     todo.Completed,
+		"due_at":        todo.DueAt,
+		"remind_at":     todo.RemindAt,
+		"priority":      int(todo.Priority),
+		"position":      todo.Position,
+		"project_id":    projectArg(todo),
+		"created_at":    now,
+		"updated_at":    now,
+		"auto_complete": todo.AutoComplete,
+		"notes":         todo.Notes,
 	}
-
-	data, _, err := r.client.From("todos").
-		Insert(todoData, false, "", "", "").
-		Execute()
+	maps.Copy(todoData, supabaseRecurrenceData(todo))
+
+	var created []supabaseTodoRow
+	err := r.retry.Do(ctx, log, "创建待办事项", func(ctx context.Context, attempt int) error {
+		created = nil
+		_, err := r.client.From("todos").Insert(todoData).ExecuteTo(ctx, &created)
+		if attempt > 1 && isSupabaseError(err, supabase.CodeUniqueViolation) {
+			log.Info("重试时发现待办事项已创建", zap.String("id", todo.ID))
+			_, err = r.client.From("todos").
+				Select("*").
+				Eq("id", todo.ID).
+				Eq("user_id", userID).
+				ExecuteTo(ctx, &created)
+		}
+		return err
+	})
 	if err != nil {
+		if isSupabaseError(err, supabase.CodeUniqueViolation) {
+			return ErrTodoAlreadyExists
+		}
+		if isSupabaseError(err, supabase.CodeForeignKeyViolation) {
+			return ErrProjectNotFound
+		}
 		return fmt.Errorf("创建待办事项失败: %w", err)
 	}
 
-	var created []*models.Todo
-	if err := json.Unmarshal(data, &created); err != nil {
-		return fmt.Errorf("解析创建结果失败: %w", err)
-	}
-
 	if len(created) > 0 {
-		*todo = *created[0]
+		*todo = created[0].toModel()
 	}
 
 	return nil
 }
 
 // Update 更新待办事项
-func (r *SupabaseTodoRepository) Update(userID string, todo *models.Todo) error {
-	r.logger.Info("更新待办事项",
+func (r *SupabaseTodoRepository) Update(ctx context.Context, userID string, todo *models.Todo) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("更新待办事项",
 		zap.String("userID", userID),
 		zap.String("id", todo.ID),
 		zap.String("title", todo.Title))
 
 	todoData := map[string]interface{}{
-		"title":      todo.Title,
-		"completed":  todo.Completed,
-		"updated_at": time.Now(),
+		"title":         todo.Title,
+		"completed":     todo.Completed,
+		"due_at":        todo.DueAt,
+		"remind_at":     todo.RemindAt,
+		"priority":      int(todo.Priority),
+		"project_id":    projectArg(todo),
+		"updated_at":    time.Now(),
+		"auto_complete": todo.AutoComplete,
+		"notes":         todo.Notes,
 	}
-
-	data, _, err := r.client.From("todos").
-		Update(todoData, "", "").
-		Filter("id", "eq", todo.ID).
-		Filter("user_id", "eq", userID).
-		Execute()
+	maps.Copy(todoData, supabaseRecurrenceData(todo))
+
+	var updated []supabaseTodoRow
+	err := r.retry.Do(ctx, log, "更新待办事项", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("todos").
+			Update(todoData).
+			Eq("id", todo.ID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("更新待办事项失败: %w", err)
-	}
-
-	var updated []*models.Todo
-	if err := json.Unmarshal(data, &updated); err != nil {
-		return fmt.Errorf("解析更新结果失败: %w", err)
+		return mapSupabaseTodoError("更新待办事项失败", err)
 	}
 
 	if len(updated) == 0 {
-		return fmt.Errorf("待办事项不存在或无权限更新")
+		return ErrTodoNotFound
 	}
 
-	*todo = *updated[0]
+	*todo = updated[0].toModel()
 	return nil
 }
 
-// Toggle 切换待办事项的完成状态
-func (r *SupabaseTodoRepository) Toggle(userID string, id string) error {
-	r.logger.Info("切换待办事项状态",
+// Move 修改待办事项的位置，写入的是固定值，可以安全地重试
+func (r *SupabaseTodoRepository) Move(ctx context.Context, userID, id, position string) (*models.Todo, error) {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("移动待办事项",
 		zap.String("userID", userID),
-		zap.String("id", id))
+		zap.String("id", id),
+		zap.String("position", position))
 
-	var todos []*models.Todo
-	data, _, err := r.client.From("todos").
-		Select("*", "", false).
-		Filter("id", "eq", id).
-		Filter("user_id", "eq", userID).
-		Execute()
+	todoData := map[string]interface{}{
+		"position":   position,
+		"updated_at": time.Now(),
+	}
+
+	var updated []supabaseTodoRow
+	err := r.retry.Do(ctx, log, "移动待办事项", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("todos").
+			Update(todoData).
+			Eq("id", id).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("获取待办事项失败: %w", err)
+		return nil, mapSupabaseTodoError("移动待办事项失败", err)
 	}
 
-	if err := json.Unmarshal(data, &todos); err != nil {
-		return fmt.Errorf("解析待办事项失败: %w", err)
+	if len(updated) == 0 {
+		return nil, ErrTodoNotFound
 	}
 
-	if len(todos) == 0 {
-		return fmt.Errorf("待办事项不存在或无权限更新")
+	todo := updated[0].toModel()
+	return &todo, nil
+}
+
+// Toggle 切换待办事项的完成状态。
+// 先读取当前状态，再写入取反后的固定值，因此写入可以安全地重试。
+func (r *SupabaseTodoRepository) Toggle(ctx context.Context, userID string, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("切换待办事项状态",
+		zap.String("userID", userID),
+		zap.String("id", id))
+
+	todo, err := r.Get(ctx, userID, id)
+	if err != nil {
+		return err
 	}
 
-	todo := todos[0]
 	todoData := map[string]interface{}{
 		"completed":  !todo.Completed,
 		"updated_at": time.Now(),
 	}
 
-	data, _, err = r.client.From("todos").
-		Update(todoData, "", "").
-		Filter("id", "eq", id).
-		Filter("user_id", "eq", userID).
-		Execute()
+	var updated []supabaseTodoRow
+	err = r.retry.Do(ctx, log, "更新待办事项状态", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("todos").
+			Update(todoData).
+			Eq("id", id).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("更新待办事项状态失败: %w", err)
+		return mapSupabaseTodoError("更新待办事项状态失败", err)
+	}
+
+	if len(updated) == 0 {
+		return ErrTodoNotFound
 	}
 
 	return nil
 }
 
 // Delete 删除待办事项
-func (r *SupabaseTodoRepository) Delete(userID string, id string) error {
-	r.logger.Info("删除待办事项",
+func (r *SupabaseTodoRepository) Delete(ctx context.Context, userID string, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("删除待办事项",
 		zap.String("userID", userID),
 		zap.String("id", id))
 
-	data, _, err := r.client.From("todos").
-		Delete("", "").
-		Filter("id", "eq", id).
-		Filter("user_id", "eq", userID).
-		Execute()
+	var (
+		deleted []supabaseTodoRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "删除待办事项", func(ctx context.Context, attempt int) error {
+		deleted = nil
+		retried = attempt > 1
+		_, err := r.client.From("todos").
+			Delete().
+			Eq("id", id).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &deleted)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("删除待办事项失败: %w", err)
+		return mapSupabaseTodoError("删除待办事项失败", err)
 	}
 
-	var deleted []*models.Todo
-	if err := json.Unmarshal(data, &deleted); err != nil {
-		return fmt.Errorf("解析删除结果失败: %w", err)
+	// 重试时没有删除任何行，说明之前失败的那次尝试实际已经删除成功
+	if len(deleted) == 0 && !retried {
+		return ErrTodoNotFound
 	}
 
-	if len(deleted) == 0 {
-		return fmt.Errorf("待办事项不存在或无权限删除")
+	return nil
+}
+
+// applySupabaseTodoFilter 添加用户和列表过滤条件，时间范围包含起点、不包含终点
+func applySupabaseTodoFilter(query *supabase.Query, userID string, filter models.TodoFilter) *supabase.Query {
+	query = query.Eq("user_id", userID)
+	if filter.Completed != nil {
+		query = query.Eq("completed", strconv.FormatBool(*filter.Completed))
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Filter("created_at", "gte", formatSupabaseTime(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Filter("created_at", "lt", formatSupabaseTime(*filter.CreatedBefore))
+	}
+	if filter.UpdatedAfter != nil {
+		query = query.Filter("updated_at", "gte", formatSupabaseTime(*filter.UpdatedAfter))
+	}
+	if filter.UpdatedBefore != nil {
+		query = query.Filter("updated_at", "lt", formatSupabaseTime(*filter.UpdatedBefore))
+	}
+	if filter.DueAfter != nil {
+		query = query.Filter("due_at", "gte", formatSupabaseTime(*filter.DueAfter))
+	}
+	if filter.DueBefore != nil {
+		query = query.Filter("due_at", "lt", formatSupabaseTime(*filter.DueBefore))
+	}
+	if filter.SeriesID != "" {
+		query = query.Eq("series_id", filter.SeriesID)
+	}
+	if filter.ProjectID != "" {
+		query = query.Eq("project_id", filter.ProjectID)
+	}
+	if len(filter.TagIDs) > 0 {
+		if filter.TagMatch == models.TagMatchAll {
+			for i, tagID := range filter.TagIDs {
+				query = query.Eq(supabaseTagAlias(i)+".tag_id", tagID)
+			}
+		} else {
+			query = query.In(supabaseTagAlias(0)+".tag_id", filter.TagIDs)
+		}
 	}
+	return query
+}
 
-	return nil
+// supabaseTodoSelect 返回列表查询的 select 参数。按标签过滤时以内连接的方式嵌入 todo_tags，
+// 只保留有匹配关联的待办事项：任意一个标签时嵌入一次并用 in 过滤，
+// 全部标签时每个标签使用一个别名各嵌入一次，分别要求带有该标签
+func supabaseTodoSelect(columns string, filter models.TodoFilter) string {
+	embeds := 0
+	if len(filter.TagIDs) > 0 {
+		embeds = 1
+		if filter.TagMatch == models.TagMatchAll {
+			embeds = len(filter.TagIDs)
+		}
+	}
+	for i := 0; i < embeds; i++ {
+		columns += "," + supabaseTagAlias(i) + ":todo_tags!inner(tag_id)"
+	}
+	return columns
+}
+
+// supabaseTagAlias 按标签过滤时第 i 个嵌入的 todo_tags 的别名
+func supabaseTagAlias(i int) string {
+	return "tag_filter_" + strconv.Itoa(i)
+}
+
+// supabaseKeysetFilter 生成游标之后的记录的 OR 条件：
+// 前面的列都与游标相等，且当前列越过游标，最后一列为 id
+func supabaseKeysetFilter(keys []todoSortKey, cursor *models.Cursor) (string, error) {
+	values, err := cursorSortValues(cursor, keys)
+	if err != nil {
+		return "", err
+	}
+
+	quoted := make([]string, len(keys))
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			quoted[i] = supabase.QuoteValue(formatSupabaseTime(v))
+		default:
+			quoted[i] = supabase.QuoteValue(fmt.Sprint(v))
+		}
+	}
+	quoted[len(keys)-1] = supabase.QuoteValue(cursor.ID)
+
+	alternatives := make([]string, len(keys))
+	for i, key := range keys {
+		operator := "gt"
+		if key.desc {
+			operator = "lt"
+		}
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, keys[j].column+".eq."+quoted[j])
+		}
+		terms = append(terms, key.column+"."+operator+"."+quoted[i])
+		if len(terms) == 1 {
+			alternatives[i] = terms[0]
+		} else {
+			alternatives[i] = "and(" + strings.Join(terms, ",") + ")"
+		}
+	}
+	return strings.Join(alternatives, ","), nil
+}
+
+// formatSupabaseTime 将时间格式化为 PostgREST 过滤条件中使用的格式
+func formatSupabaseTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// mapSupabaseTodoError 将 PostgREST 错误转换为仓库层错误
+func mapSupabaseTodoError(operation string, err error) error {
+	// ID 不是合法的 UUID 时视为待办事项不存在，引用的项目不存在时违反外键
+	if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+		return ErrTodoNotFound
+	}
+	if isSupabaseError(err, supabase.CodeForeignKeyViolation) {
+		return ErrProjectNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
+// isSupabaseError 判断是否为指定错误码的 PostgREST 错误
+func isSupabaseError(err error, code string) bool {
+	var apiErr *supabase.Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
 }
@@ -0,0 +1,441 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository/supabase"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// supabaseWorkspaceRow workspaces 表中的一行
+type supabaseWorkspaceRow struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// toModel 转换为 Workspace 实体
+func (row supabaseWorkspaceRow) toModel() models.Workspace {
+	return models.Workspace{
+		ID:        row.ID,
+		Name:      row.Name,
+		CreatedBy: row.CreatedBy,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+// supabaseMemberRow workspace_members 表中的一行
+type supabaseMemberRow struct {
+	WorkspaceID string    `json:"workspace_id"`
+	UserID      string    `json:"user_id"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// toModel 转换为 Member 实体
+func (row supabaseMemberRow) toModel() models.Member {
+	return models.Member{
+		WorkspaceID: row.WorkspaceID,
+		UserID:      row.UserID,
+		Role:        models.WorkspaceRole(row.Role),
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+// ListWorkspaces 获取用户加入的所有工作区，先查询成员身份，再按 ID 读取工作区
+func (r *SupabaseTodoRepository) ListWorkspaces(ctx context.Context, userID string) ([]models.Workspace, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var members []supabaseMemberRow
+	err := r.retry.Do(ctx, log, "获取工作区成员身份", func(ctx context.Context, _ int) error {
+		members = nil
+		_, err := r.client.From("workspace_members").
+			Select("*").
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &members)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return []models.Workspace{}, nil
+		}
+		return nil, fmt.Errorf("获取工作区列表失败: %w", err)
+	}
+	if len(members) == 0 {
+		return []models.Workspace{}, nil
+	}
+
+	roles := make(map[string]models.WorkspaceRole, len(members))
+	ids := make([]string, len(members))
+	for i, member := range members {
+		roles[member.WorkspaceID] = models.WorkspaceRole(member.Role)
+		ids[i] = member.WorkspaceID
+	}
+
+	var rows []supabaseWorkspaceRow
+	err = r.retry.Do(ctx, log, "获取工作区列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("workspaces").
+			Select("*").
+			In("id", ids).
+			Order("created_at", true).
+			Order("id", true).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取工作区列表失败: %w", err)
+	}
+
+	workspaces := make([]models.Workspace, len(rows))
+	for i, row := range rows {
+		workspaces[i] = row.toModel()
+		workspaces[i].Role = roles[row.ID]
+	}
+	return workspaces, nil
+}
+
+// GetWorkspace 获取工作区
+func (r *SupabaseTodoRepository) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseWorkspaceRow
+	err := r.retry.Do(ctx, log, "获取工作区", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("workspaces").
+			Select("*").
+			Eq("id", id).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseWorkspaceError("获取工作区失败", err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrWorkspaceNotFound
+	}
+
+	workspace := rows[0].toModel()
+	return &workspace, nil
+}
+
+// CreateWorkspace 由客户端生成 ID 插入工作区，再把创建者添加为所有者。
+// PostgREST 不支持事务，添加所有者失败时删除刚创建的工作区
+func (r *SupabaseTodoRepository) CreateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("创建工作区",
+		zap.String("createdBy", workspace.CreatedBy))
+
+	if workspace.ID == "" {
+		workspace.ID = uuid.New().String()
+	}
+	if workspace.CreatedAt.IsZero() {
+		workspace.CreatedAt = time.Now()
+	}
+	workspace.UpdatedAt = workspace.CreatedAt
+	workspace.Role = ""
+
+	data := map[string]interface{}{
+		"id":         workspace.ID,
+		"name":       workspace.Name,
+		"created_by": workspace.CreatedBy,
+		"created_at": workspace.CreatedAt,
+		"updated_at": workspace.UpdatedAt,
+	}
+
+	err := r.retry.Do(ctx, log, "创建工作区", func(ctx context.Context, attempt int) error {
+		_, err := r.client.From("workspaces").Insert(data).Execute(ctx)
+		// 重试时遇到唯一约束冲突说明之前的尝试已经成功
+		if attempt > 1 && isSupabaseError(err, supabase.CodeUniqueViolation) {
+			log.Info("重试时发现工作区已创建", zap.String("id", workspace.ID))
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("创建工作区失败: %w", err)
+	}
+
+	owner := &models.Member{
+		WorkspaceID: workspace.ID,
+		UserID:      workspace.CreatedBy,
+		Role:        models.WorkspaceOwner,
+		CreatedAt:   workspace.CreatedAt,
+	}
+	if err := r.AddMember(ctx, owner); err != nil {
+		if deleteErr := r.deleteWorkspaceRow(ctx, workspace.ID); deleteErr != nil {
+			log.Error("删除未完成的工作区失败", zap.String("id", workspace.ID), zap.Error(deleteErr))
+		}
+		return fmt.Errorf("添加工作区所有者失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateWorkspace 修改工作区的名称
+func (r *SupabaseTodoRepository) UpdateWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("修改工作区",
+		zap.String("id", workspace.ID))
+
+	data := map[string]interface{}{
+		"name":       workspace.Name,
+		"updated_at": time.Now(),
+	}
+
+	var updated []supabaseWorkspaceRow
+	err := r.retry.Do(ctx, log, "修改工作区", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("workspaces").
+			Update(data).
+			Eq("id", workspace.ID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseWorkspaceError("修改工作区失败", err)
+	}
+	if len(updated) == 0 {
+		return ErrWorkspaceNotFound
+	}
+
+	*workspace = updated[0].toModel()
+	return nil
+}
+
+// DeleteWorkspace 删除工作区和它的数据。PostgREST 的请求之间没有事务：
+// 先删除以工作区为所有者的数据，最后删除工作区，成员由外键级联删除；
+// 中途失败时工作区仍然存在，可以再次删除
+func (r *SupabaseTodoRepository) DeleteWorkspace(ctx context.Context, id string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("删除工作区",
+		zap.String("id", id))
+
+	if _, err := r.GetWorkspace(ctx, id); err != nil {
+		return err
+	}
+
+	for _, table := range []string{"todos", "projects", "tags", "notifications"} {
+		err := r.retry.Do(ctx, log, "删除工作区的数据", func(ctx context.Context, _ int) error {
+			_, err := r.client.From(table).
+				Delete().
+				Eq("user_id", id).
+				Execute(ctx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("删除工作区的 %s 失败: %w", table, err)
+		}
+	}
+
+	if err := r.deleteWorkspaceRow(ctx, id); err != nil {
+		return mapSupabaseWorkspaceError("删除工作区失败", err)
+	}
+	return nil
+}
+
+// deleteWorkspaceRow 删除 workspaces 表中的一行，成员由外键级联删除
+func (r *SupabaseTodoRepository) deleteWorkspaceRow(ctx context.Context, id string) error {
+	return r.retry.Do(ctx, logger.WithContext(ctx, r.logger), "删除工作区", func(ctx context.Context, _ int) error {
+		_, err := r.client.From("workspaces").
+			Delete().
+			Eq("id", id).
+			Execute(ctx)
+		return err
+	})
+}
+
+// GetMember 获取用户在工作区中的成员身份
+func (r *SupabaseTodoRepository) GetMember(ctx context.Context, workspaceID, userID string) (*models.Member, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseMemberRow
+	err := r.retry.Do(ctx, log, "获取工作区成员", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("workspace_members").
+			Select("*").
+			Eq("workspace_id", workspaceID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		return nil, mapSupabaseMemberError("获取工作区成员失败", err)
+	}
+	if len(rows) == 0 {
+		return nil, ErrMemberNotFound
+	}
+
+	member := rows[0].toModel()
+	return &member, nil
+}
+
+// ListMembers 获取工作区的所有成员，按加入时间排序
+func (r *SupabaseTodoRepository) ListMembers(ctx context.Context, workspaceID string) ([]models.Member, error) {
+	log := logger.WithContext(ctx, r.logger)
+
+	var rows []supabaseMemberRow
+	err := r.retry.Do(ctx, log, "获取工作区成员列表", func(ctx context.Context, _ int) error {
+		rows = nil
+		_, err := r.client.From("workspace_members").
+			Select("*").
+			Eq("workspace_id", workspaceID).
+			Order("created_at", true).
+			Order("user_id", true).
+			ExecuteTo(ctx, &rows)
+		return err
+	})
+	if err != nil {
+		if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+			return []models.Member{}, nil
+		}
+		return nil, fmt.Errorf("获取工作区成员列表失败: %w", err)
+	}
+
+	members := make([]models.Member, len(rows))
+	for i, row := range rows {
+		members[i] = row.toModel()
+	}
+	return members, nil
+}
+
+// AddMember 添加成员。重试时遇到唯一约束冲突说明之前的尝试已经成功，直接读取已添加的成员
+func (r *SupabaseTodoRepository) AddMember(ctx context.Context, member *models.Member) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("添加工作区成员",
+		zap.String("workspaceID", member.WorkspaceID),
+		zap.String("userID", member.UserID))
+
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now()
+	}
+	member.UpdatedAt = member.CreatedAt
+
+	data := map[string]interface{}{
+		"workspace_id": member.WorkspaceID,
+		"user_id":      member.UserID,
+		"role":         string(member.Role),
+		"created_at":   member.CreatedAt,
+		"updated_at":   member.UpdatedAt,
+	}
+
+	var (
+		created []supabaseMemberRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "添加工作区成员", func(ctx context.Context, attempt int) error {
+		created = nil
+		retried = attempt > 1
+		_, err := r.client.From("workspace_members").Insert(data).ExecuteTo(ctx, &created)
+		return err
+	})
+	if retried && isSupabaseError(err, supabase.CodeUniqueViolation) {
+		log.Info("重试时发现工作区成员已添加", zap.String("userID", member.UserID))
+		return nil
+	}
+	if err != nil {
+		return mapSupabaseMemberError("添加工作区成员失败", err)
+	}
+
+	if len(created) > 0 {
+		*member = created[0].toModel()
+	}
+	return nil
+}
+
+// UpdateMember 修改成员的角色
+func (r *SupabaseTodoRepository) UpdateMember(ctx context.Context, member *models.Member) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("修改工作区成员",
+		zap.String("workspaceID", member.WorkspaceID),
+		zap.String("userID", member.UserID),
+		zap.String("role", string(member.Role)))
+
+	data := map[string]interface{}{
+		"role":       string(member.Role),
+		"updated_at": time.Now(),
+	}
+
+	var updated []supabaseMemberRow
+	err := r.retry.Do(ctx, log, "修改工作区成员", func(ctx context.Context, _ int) error {
+		updated = nil
+		_, err := r.client.From("workspace_members").
+			Update(data).
+			Eq("workspace_id", member.WorkspaceID).
+			Eq("user_id", member.UserID).
+			ExecuteTo(ctx, &updated)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseMemberError("修改工作区成员失败", err)
+	}
+	if len(updated) == 0 {
+		return ErrMemberNotFound
+	}
+
+	*member = updated[0].toModel()
+	return nil
+}
+
+// RemoveMember 移除成员
+func (r *SupabaseTodoRepository) RemoveMember(ctx context.Context, workspaceID, userID string) error {
+	log := logger.WithContext(ctx, r.logger)
+	log.Info("移除工作区成员",
+		zap.String("workspaceID", workspaceID),
+		zap.String("userID", userID))
+
+	var (
+		deleted []supabaseMemberRow
+		retried bool
+	)
+	err := r.retry.Do(ctx, log, "移除工作区成员", func(ctx context.Context, attempt int) error {
+		deleted = nil
+		retried = attempt > 1
+		_, err := r.client.From("workspace_members").
+			Delete().
+			Eq("workspace_id", workspaceID).
+			Eq("user_id", userID).
+			ExecuteTo(ctx, &deleted)
+		return err
+	})
+	if err != nil {
+		return mapSupabaseMemberError("移除工作区成员失败", err)
+	}
+
+	// 重试时没有删除任何行，说明之前失败的那次尝试实际已经删除成功
+	if len(deleted) == 0 && !retried {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// mapSupabaseWorkspaceError 将 PostgREST 错误转换为仓库层错误，ID 不是合法的 UUID 视为工作区不存在
+func mapSupabaseWorkspaceError(operation string, err error) error {
+	if isSupabaseError(err, supabase.CodeInvalidTextInput) {
+		return ErrWorkspaceNotFound
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
+
+// mapSupabaseMemberError 将 PostgREST 错误转换为仓库层错误。
+// ID 不是合法的 UUID 视为成员不存在，违反外键视为工作区不存在
+func mapSupabaseMemberError(operation string, err error) error {
+	switch {
+	case isSupabaseError(err, supabase.CodeInvalidTextInput):
+		return ErrMemberNotFound
+	case isSupabaseError(err, supabase.CodeForeignKeyViolation):
+		return ErrWorkspaceNotFound
+	case isSupabaseError(err, supabase.CodeUniqueViolation):
+		return ErrMemberAlreadyExists
+	}
+	return fmt.Errorf("%s: %w", operation, err)
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// TagRepository 标签的仓库接口，存储后端通过类型断言获取。
+//
+// 标签属于用户，同一用户的标签名称不区分大小写唯一。待办事项与标签是多对多关系，
+// 删除待办事项或标签时同时删除它们之间的关联。修改关联的方法不检查待办事项和标签的归属，
+// 由服务层在调用之前确认它们都属于该用户。
+type TagRepository interface {
+	// ListTags 获取指定用户的所有标签，按名称排序
+	ListTags(ctx context.Context, userID string) ([]models.Tag, error)
+
+	// GetTag 获取指定用户的单个标签，不存在时返回 ErrTagNotFound
+	GetTag(ctx context.Context, userID, id string) (*models.Tag, error)
+
+	// CreateTag 创建标签，未设置的 ID 和时间戳会自动填充；同名标签已存在时返回 ErrTagAlreadyExists
+	CreateTag(ctx context.Context, userID string, tag *models.Tag) error
+
+	// UpdateTag 修改标签的名称和颜色，并把保存后的标签写回 tag
+	UpdateTag(ctx context.Context, userID string, tag *models.Tag) error
+
+	// DeleteTag 删除标签及其与待办事项的关联
+	DeleteTag(ctx context.Context, userID, id string) error
+
+	// TodoTags 批量获取待办事项的标签，按待办事项 ID 索引，每个待办事项的标签按名称排序。
+	// 没有标签的待办事项不出现在结果中。
+	TodoTags(ctx context.Context, userID string, todoIDs []string) (map[string][]models.Tag, error)
+
+	// SetTodoTags 把待办事项的标签替换为 tagIDs，tagIDs 为空时去掉所有标签
+	SetTodoTags(ctx context.Context, userID, todoID string, tagIDs []string) error
+
+	// AddTodoTag 给待办事项添加一个标签，已经带有该标签时不做修改
+	AddTodoTag(ctx context.Context, userID, todoID, tagID string) error
+
+	// RemoveTodoTag 去掉待办事项的一个标签，没有该标签时不做修改
+	RemoveTodoTag(ctx context.Context, userID, todoID, tagID string) error
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// sqlDialect 生成列表查询时各数据库之间的差异
+type sqlDialect struct {
+	// placeholder 第 n 个参数的占位符，n 从 1 开始
+	placeholder func(n int) string
+	// idPlaceholder 与 id 列比较时使用的占位符，例如 PostgreSQL 需要转换为 uuid
+	idPlaceholder func(n int) string
+	// timeArg 将时间转换为数据库中存储的格式
+	timeArg func(t time.Time) any
+}
+
+// postgresDialect PostgreSQL 的列表查询方言
+var postgresDialect = sqlDialect{
+	placeholder:   func(n int) string { return "$" + strconv.Itoa(n) },
+	idPlaceholder: func(n int) string { return "$" + strconv.Itoa(n) + "::uuid" },
+	timeArg:       func(t time.Time) any { return t },
+}
+
+// sqliteDialect SQLite 的列表查询方言，时间以固定格式的文本存储
+var sqliteDialect = sqlDialect{
+	placeholder:   func(int) string { return "?" },
+	idPlaceholder: func(int) string { return "?" },
+	timeArg:       func(t time.Time) any { return formatSQLiteTime(t) },
+}
+
+// todoListQuery 构建列表查询的 WHERE 条件和参数
+type todoListQuery struct {
+	dialect sqlDialect
+	where   []string
+	args    []any
+}
+
+// add 添加一个参数，返回它的占位符
+func (q *todoListQuery) add(v any) string {
+	q.args = append(q.args, v)
+	return q.dialect.placeholder(len(q.args))
+}
+
+// addID 添加一个与 id 列比较的参数，返回它的占位符
+func (q *todoListQuery) addID(id string) string {
+	q.args = append(q.args, id)
+	return q.dialect.idPlaceholder(len(q.args))
+}
+
+// newTodoListQuery 根据用户和过滤条件生成查询条件，不包含游标
+func newTodoListQuery(dialect sqlDialect, userID string, filter models.TodoFilter) *todoListQuery {
+	q := &todoListQuery{dialect: dialect}
+	q.where = append(q.where, "user_id = "+q.add(userID))
+
+	if filter.Completed != nil {
+		q.where = append(q.where, "completed = "+q.add(*filter.Completed))
+	}
+	q.addTimeRange("created_at", filter.CreatedAfter, filter.CreatedBefore)
+	q.addTimeRange("updated_at", filter.UpdatedAfter, filter.UpdatedBefore)
+	q.addTimeRange("due_at", filter.DueAfter, filter.DueBefore)
+	if filter.SeriesID != "" {
+		q.where = append(q.where, "series_id = "+q.addID(filter.SeriesID))
+	}
+	if filter.ProjectID != "" {
+		q.where = append(q.where, "project_id = "+q.addID(filter.ProjectID))
+	}
+	q.addTags(filter.TagIDs, filter.TagMatch)
+	return q
+}
+
+// addTags 添加标签条件。要求带有全部标签时按待办事项分组统计匹配的标签数，
+// todo_tags 的主键保证同一标签只统计一次
+func (q *todoListQuery) addTags(tagIDs []string, match models.TagMatch) {
+	if len(tagIDs) == 0 {
+		return
+	}
+	placeholders := make([]string, len(tagIDs))
+	for i, id := range tagIDs {
+		placeholders[i] = q.addID(id)
+	}
+	subquery := "SELECT todo_id FROM todo_tags WHERE tag_id IN (" + strings.Join(placeholders, ", ") + ")"
+	if match == models.TagMatchAll {
+		subquery += " GROUP BY todo_id HAVING COUNT(*) = " + q.add(len(tagIDs))
+	}
+	q.where = append(q.where, "id IN ("+subquery+")")
+}
+
+// addTimeRange 添加时间范围条件，包含起点、不包含终点
+func (q *todoListQuery) addTimeRange(column string, after, before *time.Time) {
+	if after != nil {
+		q.where = append(q.where, column+" >= "+q.add(q.dialect.timeArg(*after)))
+	}
+	if before != nil {
+		q.where = append(q.where, column+" < "+q.add(q.dialect.timeArg(*before)))
+	}
+}
+
+// whereClause 返回 WHERE 子句
+func (q *todoListQuery) whereClause() string {
+	return " WHERE " + strings.Join(q.where, " AND ")
+}
+
+// countSQL 统计符合过滤条件的总数
+func (q *todoListQuery) countSQL() string {
+	return "SELECT COUNT(*) FROM todos" + q.whereClause()
+}
+
+// pageSQL 在过滤条件的基础上加入游标条件、排序和 LIMIT，返回查询语句和参数。
+// 调用后不应再使用 countSQL，因为游标条件已经加入 where。
+func (q *todoListQuery) pageSQL(columns string, opts models.TodoListOptions) (string, []any, error) {
+	keys, err := todoSortKeys(opts.SortBy, opts.SortDir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if opts.After != nil {
+		condition, err := q.keysetCondition(keys, opts.After)
+		if err != nil {
+			return "", nil, err
+		}
+		q.where = append(q.where, condition)
+	}
+
+	order := make([]string, len(keys))
+	for i, key := range keys {
+		order[i] = key.column + " ASC"
+		if key.desc {
+			order[i] = key.column + " DESC"
+		}
+	}
+
+	sql := fmt.Sprintf("SELECT %s FROM todos%s ORDER BY %s LIMIT %s",
+		columns, q.whereClause(), strings.Join(order, ", "), q.add(opts.Limit+1))
+	return sql, q.args, nil
+}
+
+// keysetCondition 生成游标之后的记录的条件。各列方向相同时使用行比较，便于使用索引；
+// 方向不同时展开为：前面的列都相等，且当前列越过游标
+func (q *todoListQuery) keysetCondition(keys []todoSortKey, cursor *models.Cursor) (string, error) {
+	values, err := cursorSortValues(cursor, keys)
+	if err != nil {
+		return "", err
+	}
+	for i, value := range values {
+		if t, ok := value.(time.Time); ok {
+			values[i] = q.dialect.timeArg(t)
+		}
+	}
+
+	// 每次引用都添加新的参数，SQLite 的占位符不能重复使用
+	placeholder := func(i int) string {
+		if i == len(keys)-1 {
+			return q.addID(cursor.ID)
+		}
+		return q.add(values[i])
+	}
+	operator := func(key todoSortKey) string {
+		if key.desc {
+			return "<"
+		}
+		return ">"
+	}
+
+	if sameDirection(keys) {
+		columns := make([]string, len(keys))
+		placeholders := make([]string, len(keys))
+		for i, key := range keys {
+			columns[i], placeholders[i] = key.column, placeholder(i)
+		}
+		return fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(columns, ", "), operator(keys[0]), strings.Join(placeholders, ", ")), nil
+	}
+
+	alternatives := make([]string, len(keys))
+	for i, key := range keys {
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, keys[j].column+" = "+placeholder(j))
+		}
+		terms = append(terms, key.column+" "+operator(key)+" "+placeholder(i))
+		alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", nil
+}
@@ -1,97 +1,92 @@
 package repository
 
-import "github.com/Brower/backend/internal/models"
+import (
+	"context"
+	"time"
 
-// TodoRepository 定义了待办事项仓库的接口
+	"github.com/Brower/backend/internal/models"
+)
+
+// defaultOperationTimeout 未配置 database.operation_timeout 时单次仓库操作的超时时间
+const defaultOperationTimeout = 5 * time.Second
+
+// TodoRepository 定义了待办事项仓库的接口。
+// 所有方法都接收请求的上下文，实现需要在上下文取消或超时后尽快返回。
 type TodoRepository interface {
-	// List 获取指定用户的所有待办事项
-	List(userID string) ([]models.Todo, error)
+	// List 按过滤条件、排序和游标分页获取指定用户的待办事项。
+	// 排序值相同时按 ID 以相同方向排序，保证分页结果稳定。
+	List(ctx context.Context, userID string, opts models.TodoListOptions) (*models.TodoPage, error)
+
+	// Search 按相关度搜索指定用户的待办事项，打分规则见 search 包。
+	// 相关度相同时按 ID 倒序，分页方式与 List 相同。
+	Search(ctx context.Context, userID string, opts models.TodoSearchOptions) (*models.TodoSearchPage, error)
 
 	// Get 获取指定用户的单个待办事项
-	Get(userID, id string) (*models.Todo, error)
+	Get(ctx context.Context, userID, id string) (*models.Todo, error)
 
 	// Create 创建一个新的待办事项
-	Create(userID string, todo *models.Todo) error
+	Create(ctx context.Context, userID string, todo *models.Todo) error
 
 	// Update 更新待办事项
-	Update(userID string, todo *models.Todo) error
+	Update(ctx context.Context, userID string, todo *models.Todo) error
+
+	// Move 只修改待办事项的位置，返回修改后的待办事项。
+	// 位置由服务层根据相邻的待办事项生成，移动不影响其他记录。
+	Move(ctx context.Context, userID, id, position string) (*models.Todo, error)
 
 	// Toggle 切换待办事项的完成状态
-	Toggle(userID, id string) error
+	Toggle(ctx context.Context, userID, id string) error
 
 	// Delete 删除待办事项
-	Delete(userID, id string) error
-}
-
-// InMemoryTodoRepository 是一个内存实现的 TodoRepository
-type InMemoryTodoRepository struct {
-	todos []models.Todo
+	Delete(ctx context.Context, userID, id string) error
 }
 
-// NewInMemoryTodoRepository 创建一个新的内存 TodoRepository
-func NewInMemoryTodoRepository() *InMemoryTodoRepository {
-	return &InMemoryTodoRepository{
-		todos: []models.Todo{},
+// operationTimeout 返回配置的单次操作超时时间
+func operationTimeout(configured time.Duration) time.Duration {
+	if configured > 0 {
+		return configured
 	}
+	return defaultOperationTimeout
 }
 
-// List 获取指定用户的所有待办事项
-func (r *InMemoryTodoRepository) List(userID string) ([]models.Todo, error) {
-	var result []models.Todo
-	for _, todo := range r.todos {
-		if todo.UserID == userID {
-			result = append(result, todo)
-		}
+// newTodoPage 根据多查询一条的结果生成分页：items 最多包含 opts.Limit+1 条，
+// 多出的一条说明还有下一页
+func newTodoPage(items []models.Todo, total int, opts models.TodoListOptions) *models.TodoPage {
+	page := &models.TodoPage{Items: items, Total: total}
+	if len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.Next = models.NewCursor(opts.SortBy, opts.SortDir, last)
 	}
-	return result, nil
-}
-
-// Get 获取指定用户的单个待办事项
-func (r *InMemoryTodoRepository) Get(userID, id string) (*models.Todo, error) {
-	for _, todo := range r.todos {
-		if todo.ID == id && todo.UserID == userID {
-			return &todo, nil
-		}
+	if page.Items == nil {
+		page.Items = []models.Todo{}
 	}
-	return nil, ErrTodoNotFound
+	return page
 }
 
-// Create 创建一个新的待办事项
-func (r *InMemoryTodoRepository) Create(userID string, todo *models.Todo) error {
-	todo.UserID = userID
-	r.todos = append(r.todos, *todo)
-	return nil
-}
-
-// Update 更新待办事项
-func (r *InMemoryTodoRepository) Update(userID string, todo *models.Todo) error {
-	for i, t := range r.todos {
-		if t.ID == todo.ID && t.UserID == userID {
-			r.todos[i] = *todo
-			return nil
-		}
+// recurrenceArgs 返回写入 series_id 和重复规则各列的参数，未设置的列为 nil
+func recurrenceArgs(todo *models.Todo) (seriesID, rule, timezone any, start *time.Time) {
+	if todo.SeriesID != "" {
+		seriesID = todo.SeriesID
+	}
+	if todo.Recurrence != nil {
+		rule, timezone, start = todo.Recurrence.Rule, todo.Recurrence.Timezone, &todo.Recurrence.Start
 	}
-	return ErrTodoNotFound
+	return seriesID, rule, timezone, start
 }
 
-// Toggle 切换待办事项的完成状态
-func (r *InMemoryTodoRepository) Toggle(userID, id string) error {
-	for i, todo := range r.todos {
-		if todo.ID == id && todo.UserID == userID {
-			r.todos[i].Completed = !r.todos[i].Completed
-			return nil
-		}
+// projectArg 返回写入 project_id 列的参数，没有分配项目时为 nil
+func projectArg(todo *models.Todo) any {
+	if todo.ProjectID == "" {
+		return nil
 	}
-	return ErrTodoNotFound
+	return todo.ProjectID
 }
 
-// Delete 删除待办事项
-func (r *InMemoryTodoRepository) Delete(userID, id string) error {
-	for i, todo := range r.todos {
-		if todo.ID == id && todo.UserID == userID {
-			r.todos = append(r.todos[:i], r.todos[i+1:]...)
-			return nil
-		}
+// newRecurrence 根据读取到的重复规则各列还原 Recurrence，任意一列为空时返回 nil
+func newRecurrence(rule, timezone *string, start *time.Time) *models.Recurrence {
+	if rule == nil || timezone == nil || start == nil {
+		return nil
 	}
-	return ErrTodoNotFound
+	return &models.Recurrence{Rule: *rule, Timezone: *timezone, Start: *start}
 }
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"cmp"
+	"sort"
+	"strings"
+
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/search"
+)
+
+// searchTodos 在 Go 中为候选待办事项打分、排序并分页，
+// 供没有 pg_trgm 的内存和 SQLite 仓库使用
+func searchTodos(candidates []models.Todo, opts models.TodoSearchOptions) (*models.TodoSearchPage, error) {
+	var after *models.TodoSearchHit
+	if opts.After != nil {
+		score, err := opts.After.Score()
+		if err != nil {
+			return nil, err
+		}
+		after = &models.TodoSearchHit{Todo: models.Todo{ID: opts.After.ID}, Score: score}
+	}
+
+	hits := make([]models.TodoSearchHit, 0, len(candidates))
+	for i := range candidates {
+		if score, ok := search.ScoreTodo(opts.Query, &candidates[i]); ok {
+			hits = append(hits, models.TodoSearchHit{Todo: candidates[i], Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		return compareSearchHits(&hits[i], &hits[j]) > 0
+	})
+
+	// 跳过游标及之前的结果
+	start := 0
+	if after != nil {
+		start = sort.Search(len(hits), func(i int) bool {
+			return compareSearchHits(&hits[i], after) < 0
+		})
+	}
+
+	end := min(start+opts.Limit+1, len(hits))
+	return newTodoSearchPage(hits[start:end], len(hits), opts), nil
+}
+
+// compareSearchHits 按相关度比较两条搜索结果，相关度相同时比较 ID
+func compareSearchHits(a, b *models.TodoSearchHit) int {
+	if c := cmp.Compare(a.Score, b.Score); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Todo.ID, b.Todo.ID)
+}
+
+// newTodoSearchPage 根据多查询一条的结果生成分页，与 newTodoPage 相同
+func newTodoSearchPage(hits []models.TodoSearchHit, total int, opts models.TodoSearchOptions) *models.TodoSearchPage {
+	page := &models.TodoSearchPage{Hits: hits, Total: total}
+	if len(hits) > opts.Limit {
+		page.Hits = hits[:opts.Limit]
+		page.Next = models.NewSearchCursor(page.Hits[len(page.Hits)-1])
+	}
+	if page.Hits == nil {
+		page.Hits = []models.TodoSearchHit{}
+	}
+	return page
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+func TestSearchTodosPaging(t *testing.T) {
+	// 五条得分相同的结果和两条得分更高、一条得分更低的结果
+	candidates := []models.Todo{
+		{ID: "exact-1", Title: "report"},
+		{ID: "exact-2", Title: "report"},
+		{ID: "fuzzy", Title: "repot"},
+		{ID: "unrelated", Title: "groceries"},
+	}
+	for i := 0; i < 5; i++ {
+		candidates = append(candidates, models.Todo{ID: fmt.Sprintf("same-%d", i), Title: "weekly report"})
+	}
+	want := []string{"exact-2", "exact-1", "same-4", "same-3", "same-2", "same-1", "same-0", "fuzzy"}
+
+	tests := []struct {
+		name  string
+		limit int
+	}{
+		{"one per page", 1},
+		{"page boundary inside equal scores", 3},
+		{"exact page size", len(want)},
+		{"larger than total", 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got   []string
+				after *models.Cursor
+			)
+			for pages := 0; ; pages++ {
+				if pages > len(want) {
+					t.Fatalf("paging did not terminate, got %v", got)
+				}
+				page, err := searchTodos(candidates, models.TodoSearchOptions{Query: "report", Limit: tt.limit, After: after})
+				if err != nil {
+					t.Fatalf("searchTodos() error = %v", err)
+				}
+				if page.Total != len(want) {
+					t.Errorf("Total = %d, want %d", page.Total, len(want))
+				}
+				if len(page.Hits) > tt.limit {
+					t.Errorf("len(Hits) = %d, want at most %d", len(page.Hits), tt.limit)
+				}
+				for _, hit := range page.Hits {
+					got = append(got, hit.Todo.ID)
+				}
+				if page.Next == nil {
+					break
+				}
+				// 游标经过格式化后仍然能精确定位到相同得分中的下一条
+				if _, err := page.Next.Score(); err != nil {
+					t.Fatalf("Next.Score() error = %v", err)
+				}
+				after = page.Next
+			}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("paged IDs = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSearchTodosScoreOrder(t *testing.T) {
+	candidates := []models.Todo{
+		{ID: "a", Title: "weekly report"},
+		{ID: "b", Title: "report"},
+		{ID: "c", Title: "misc", Notes: "attach the report"},
+	}
+	page, err := searchTodos(candidates, models.TodoSearchOptions{Query: "report", Limit: 10})
+	if err != nil {
+		t.Fatalf("searchTodos() error = %v", err)
+	}
+	for i := 1; i < len(page.Hits); i++ {
+		if compareSearchHits(&page.Hits[i-1], &page.Hits[i]) <= 0 {
+			t.Errorf("hits not in descending order: %+v before %+v", page.Hits[i-1], page.Hits[i])
+		}
+	}
+	if len(page.Hits) != 3 || page.Hits[0].Todo.ID != "b" {
+		t.Errorf("hits = %+v, want b first of 3", page.Hits)
+	}
+}
+
+func TestSearchTodosInvalidCursor(t *testing.T) {
+	after := &models.Cursor{SortBy: models.SortByRelevance, SortDir: models.SortDesc, Values: []string{"high"}, ID: "x"}
+	if _, err := searchTodos(nil, models.TodoSearchOptions{Query: "report", Limit: 10, After: after}); err == nil {
+		t.Error("searchTodos(invalid cursor) error = nil, want error")
+	}
+}
+
+func TestSearchTodosEmpty(t *testing.T) {
+	page, err := searchTodos(nil, models.TodoSearchOptions{Query: "report", Limit: 10})
+	if err != nil {
+		t.Fatalf("searchTodos() error = %v", err)
+	}
+	if page.Hits == nil || len(page.Hits) != 0 || page.Next != nil || page.Total != 0 {
+		t.Errorf("empty page = %+v, want no hits and no cursor", page)
+	}
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// todoSortKey 排序时比较的一列及其方向
+type todoSortKey struct {
+	column string
+	desc   bool
+}
+
+// todoSortColumn 排序字段对应的一列，reversed 为 true 时与请求的方向相反
+type todoSortColumn struct {
+	name     string
+	reversed bool
+}
+
+// todoSortColumns 排序字段依次比较的列，只允许白名单中的列出现在查询中
+var todoSortColumns = map[models.SortField][]todoSortColumn{
+	models.SortByCreatedAt: {{name: "created_at"}},
+	models.SortByUpdatedAt: {{name: "updated_at"}},
+	models.SortByTitle:     {{name: "title"}},
+	models.SortByPosition:  {{name: "position"}},
+	models.SortByPriority:  {{name: "priority"}, {name: "position", reversed: true}},
+}
+
+// todoSortKeys 返回排序字段依次比较的列，最后一列总是 id，方向与它前面的一列相同，
+// 保证排序值相同时分页结果稳定
+func todoSortKeys(field models.SortField, dir models.SortDirection) ([]todoSortKey, error) {
+	columns, ok := todoSortColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("不支持的排序字段: %s", field)
+	}
+
+	desc := dir == models.SortDesc
+	keys := make([]todoSortKey, 0, len(columns)+1)
+	for _, c := range columns {
+		keys = append(keys, todoSortKey{column: c.name, desc: desc != c.reversed})
+	}
+	return append(keys, todoSortKey{column: "id", desc: keys[len(keys)-1].desc}), nil
+}
+
+// sameDirection 判断各列的排序方向是否相同
+func sameDirection(keys []todoSortKey) bool {
+	for _, key := range keys {
+		if key.desc != keys[0].desc {
+			return false
+		}
+	}
+	return true
+}
+
+// cursorSortValues 将游标中的排序值按列解析为 time.Time、int 或 string，
+// 与 keys 中除 id 以外的列一一对应
+func cursorSortValues(cursor *models.Cursor, keys []todoSortKey) ([]any, error) {
+	columns := keys[:len(keys)-1]
+	if len(cursor.Values) != len(columns) {
+		return nil, fmt.Errorf("游标排序值的个数与排序字段不符")
+	}
+
+	values := make([]any, len(columns))
+	for i, key := range columns {
+		raw := cursor.Values[i]
+		switch key.column {
+		case "created_at", "updated_at":
+			t, err := time.Parse(time.RFC3339Nano, raw)
+			if err != nil {
+				return nil, fmt.Errorf("游标时间格式错误: %w", err)
+			}
+			values[i] = t
+		case "priority":
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("游标优先级格式错误: %w", err)
+			}
+			values[i] = n
+		default:
+			values[i] = raw
+		}
+	}
+	return values, nil
+}
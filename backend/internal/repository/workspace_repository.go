@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// WorkspaceRepository 工作区和成员的仓库接口，存储后端通过类型断言获取。
+//
+// 工作区中的项目、待办事项和标签以工作区 ID 作为所有者，通过其他仓库接口读写，
+// 这里只保存工作区和成员关系。仓库层不检查成员的角色，也不阻止移除最后一个所有者，
+// 由服务层检查
+type WorkspaceRepository interface {
+	// ListWorkspaces 获取用户加入的所有工作区，Role 为该用户的角色，按创建时间排序
+	ListWorkspaces(ctx context.Context, userID string) ([]models.Workspace, error)
+
+	// GetWorkspace 获取工作区，不存在时返回 ErrWorkspaceNotFound
+	GetWorkspace(ctx context.Context, id string) (*models.Workspace, error)
+
+	// CreateWorkspace 创建工作区，并在同一个事务中把 CreatedBy 添加为所有者。
+	// 未设置的 ID 和时间戳会自动填充
+	CreateWorkspace(ctx context.Context, workspace *models.Workspace) error
+
+	// UpdateWorkspace 修改工作区的名称，并把保存后的工作区写回 workspace
+	UpdateWorkspace(ctx context.Context, workspace *models.Workspace) error
+
+	// DeleteWorkspace 删除工作区、它的成员，以及以工作区为所有者的项目、待办事项和标签。
+	// 工作区不存在时返回 ErrWorkspaceNotFound
+	DeleteWorkspace(ctx context.Context, id string) error
+
+	// GetMember 获取用户在工作区中的成员身份，工作区不存在或用户不是成员时返回 ErrMemberNotFound
+	GetMember(ctx context.Context, workspaceID, userID string) (*models.Member, error)
+
+	// ListMembers 获取工作区的所有成员，按加入时间排序
+	ListMembers(ctx context.Context, workspaceID string) ([]models.Member, error)
+
+	// AddMember 添加成员，未设置的时间戳会自动填充。工作区不存在时返回 ErrWorkspaceNotFound，
+	// 用户已经是成员时返回 ErrMemberAlreadyExists
+	AddMember(ctx context.Context, member *models.Member) error
+
+	// UpdateMember 修改成员的角色，并把保存后的成员写回 member，不存在时返回 ErrMemberNotFound
+	UpdateMember(ctx context.Context, member *models.Member) error
+
+	// RemoveMember 移除成员，不存在时返回 ErrMemberNotFound
+	RemoveMember(ctx context.Context, workspaceID, userID string) error
+}
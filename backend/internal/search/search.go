@@ -0,0 +1,200 @@
+// Package search 实现待办事项的模糊搜索打分和高亮。
+//
+// 打分规则与 migrations/012_add_notes.up.sql 中的 search_todos 函数一致：
+// 忽略大小写的子串匹配得分为 1 + 三元组相似度，只有模糊匹配时得分为相似度，
+// 相似度低于 Threshold 且不包含查询串的文本不匹配，待办事项的得分取标题和备注中较高的一个。
+// PostgreSQL 使用 pg_trgm 计算，
+// 内存和 SQLite 仓库使用这里的纯 Go 实现。
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+// Threshold 模糊匹配的最低相似度，与 pg_trgm.similarity_threshold 的默认值一致
+const Threshold = 0.3
+
+// Field 参与搜索的一个文本字段
+type Field struct {
+	// Name 字段名，与响应中的 JSON 字段一致
+	Name string
+	Text string
+}
+
+// TodoFields 返回待办事项中参与搜索的字段
+func TodoFields(todo *models.Todo) []Field {
+	return []Field{
+		{Name: "title", Text: todo.Title},
+		{Name: "notes", Text: todo.Notes},
+	}
+}
+
+// ScoreTodo 计算待办事项与查询的相关度，取各字段得分的最大值；没有字段匹配时 ok 为 false
+func ScoreTodo(query string, todo *models.Todo) (score float64, ok bool) {
+	for _, field := range TodoFields(todo) {
+		if s, matched := Score(query, field.Text); matched && (!ok || s > score) {
+			score, ok = s, true
+		}
+	}
+	return score, ok
+}
+
+// HighlightTodo 返回各字段中需要高亮的区间，没有高亮的字段不出现在结果中
+func HighlightTodo(query string, todo *models.Todo) map[string][]models.TextRange {
+	highlights := make(map[string][]models.TextRange)
+	for _, field := range TodoFields(todo) {
+		if ranges := Highlight(query, field.Text); len(ranges) > 0 {
+			highlights[field.Name] = ranges
+		}
+	}
+	return highlights
+}
+
+// Score 计算文本与查询的相关度。
+// 得分按 float32 精度返回，与数据库中 real 类型的得分以及游标中的格式保持一致。
+func Score(query, text string) (float64, bool) {
+	similarity := Similarity(query, text)
+	if strings.Contains(fold(text), fold(query)) {
+		return float64(float32(1 + similarity)), true
+	}
+	if similarity >= Threshold {
+		return float64(float32(similarity)), true
+	}
+	return 0, false
+}
+
+// Similarity 计算两个字符串的三元组相似度，算法与 pg_trgm 的 similarity() 相同：
+// 共同三元组数量除以两者三元组并集的大小
+func Similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	common := 0
+	for trigram := range ta {
+		if _, ok := tb[trigram]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(ta)+len(tb)-common)
+}
+
+// trigrams 返回字符串的三元组集合。与 pg_trgm 一样按非字母数字字符分词，
+// 每个词转为小写后在前面补两个空格、后面补一个空格再切分
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, w := range words([]rune(fold(s))) {
+		padded := append([]rune("  "), w.text...)
+		padded = append(padded, ' ')
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// Highlight 返回文本中与查询匹配的区间，按起点排序且互不重叠。
+// 优先标出整个查询串的出现位置；查询串没有出现时标出各个查询词的出现位置；
+// 仍然没有时标出与某个查询词足够相似的词（模糊匹配）。
+func Highlight(query, text string) []models.TextRange {
+	q := []rune(fold(strings.TrimSpace(query)))
+	t := []rune(fold(text))
+	if len(q) == 0 {
+		return nil
+	}
+
+	ranges := occurrences(t, q)
+	if len(ranges) > 0 {
+		return ranges
+	}
+
+	queryWords := words(q)
+	for _, w := range queryWords {
+		ranges = append(ranges, occurrences(t, w.text)...)
+	}
+
+	if len(ranges) == 0 {
+		for _, tw := range words(t) {
+			for _, qw := range queryWords {
+				if Similarity(string(tw.text), string(qw.text)) >= Threshold {
+					ranges = append(ranges, models.TextRange{Start: tw.start, End: tw.start + len(tw.text)})
+					break
+				}
+			}
+		}
+	}
+
+	return mergeRanges(ranges)
+}
+
+// fold 转为小写，逐个字符转换，保证转换前后字符数量不变，高亮区间可以直接对应原文
+func fold(s string) string {
+	return strings.Map(unicode.ToLower, s)
+}
+
+// word 文本中的一个词及其起始位置（按字符计算）
+type word struct {
+	text  []rune
+	start int
+}
+
+// words 按非字母数字字符分词
+func words(s []rune) []word {
+	var result []word
+	start := -1
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			result = append(result, word{text: s[start:i], start: start})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		result = append(result, word{text: s[start:], start: start})
+	}
+	return result
+}
+
+// occurrences 返回 sub 在 s 中所有不重叠的出现位置
+func occurrences(s, sub []rune) []models.TextRange {
+	var ranges []models.TextRange
+	for i := 0; len(sub) > 0 && i+len(sub) <= len(s); {
+		if slices.Equal(s[i:i+len(sub)], sub) {
+			ranges = append(ranges, models.TextRange{Start: i, End: i + len(sub)})
+			i += len(sub)
+			continue
+		}
+		i++
+	}
+	return ranges
+}
+
+// mergeRanges 排序并合并重叠或相邻的区间
+func mergeRanges(ranges []models.TextRange) []models.TextRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Start < ranges[j].Start })
+
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/Brower/backend/internal/models"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		// pg_trgm 文档中的例子：similarity('word', 'two words') = 0.363636
+		{"pg_trgm example", "word", "two words", 4.0 / 11},
+		{"identical", "word", "word", 1},
+		{"case insensitive", "Word", "WORD", 1},
+		{"shared suffix only", "cat", "hat", 1.0 / 7},
+		{"padding favours prefix", "words", "word", 4.0 / 7},
+		{"punctuation splits words", "to-do", "to do", 1},
+		{"empty", "", "word", 0},
+		{"no alphanumerics", "!!", "word", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		query, text string
+		want        float64
+		wantOK      bool
+	}{
+		{"substring boost", "word", "two WORDS", float64(float32(1 + 4.0/11)), true},
+		{"substring inside word", "word", "sword", float64(float32(1 + 3.0/8)), true},
+		{"exact", "word", "word", 2, true},
+		{"fuzzy above threshold", "words", "word", float64(float32(4.0 / 7)), true},
+		{"fuzzy below threshold", "word", "two worts", 0, false},
+		{"no match", "word", "cat", 0, false},
+		{"cjk substring", "会议", "明天开会议程", float64(float32(1 + Similarity("会议", "明天开会议程"))), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Score(tt.query, tt.text)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Score(%q, %q) = %v, %v, want %v, %v", tt.query, tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestScoreTodo(t *testing.T) {
+	todo := &models.Todo{Title: "weekly report", Notes: "send the report to the team"}
+
+	// 取标题和备注中较高的得分
+	got, ok := ScoreTodo("report", todo)
+	want := max(mustScore(t, "report", todo.Title), mustScore(t, "report", todo.Notes))
+	if !ok || got != want {
+		t.Errorf("ScoreTodo(report) = %v, %v, want %v, true", got, ok, want)
+	}
+
+	if _, ok := ScoreTodo("invoice", todo); ok {
+		t.Errorf("ScoreTodo(invoice) matched, want no match")
+	}
+}
+
+func mustScore(t *testing.T, query, text string) float64 {
+	t.Helper()
+	score, ok := Score(query, text)
+	if !ok {
+		t.Fatalf("Score(%q, %q) did not match", query, text)
+	}
+	return score
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name        string
+		query, text string
+		want        []models.TextRange
+	}{
+		{"every occurrence", "word", "Two WORDS word", []models.TextRange{{Start: 4, End: 8}, {Start: 10, End: 14}}},
+		{"cjk", "会议", "明天开会议程", []models.TextRange{{Start: 3, End: 5}}},
+		{"multibyte fold", "äpfel", "ÄPFEL und äpfel", []models.TextRange{{Start: 0, End: 5}, {Start: 10, End: 15}}},
+		// İ 占两个字节，转为小写后的 i 只占一个，区间仍按字符计算
+		{"fold changes byte length", "istanbul", "nach İSTANBUL", []models.TextRange{{Start: 5, End: 13}}},
+		{"emoji before match", "party", "🎉 派对 party", []models.TextRange{{Start: 5, End: 10}}},
+		{"query words", "buy milk", "Milk and eggs, buy bread", []models.TextRange{{Start: 0, End: 4}, {Start: 15, End: 18}}},
+		{"adjacent words merged", "ab cd", "abcd", []models.TextRange{{Start: 0, End: 4}}},
+		{"fuzzy word", "meetng", "Team meeting", []models.TextRange{{Start: 5, End: 12}}},
+		{"surrounding spaces ignored", "  milk ", "oat milk", []models.TextRange{{Start: 4, End: 8}}},
+		{"no match", "invoice", "weekly report", nil},
+		{"blank query", "   ", "weekly report", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.query, tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Highlight(%q, %q) = %v, want %v", tt.query, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightTodo(t *testing.T) {
+	todo := &models.Todo{Title: "周报", Notes: "把周报发给团队"}
+	want := map[string][]models.TextRange{
+		"title": {{Start: 0, End: 2}},
+		"notes": {{Start: 1, End: 3}},
+	}
+	if got := HighlightTodo("周报", todo); !reflect.DeepEqual(got, want) {
+		t.Errorf("HighlightTodo() = %v, want %v", got, want)
+	}
+
+	// 没有高亮的字段不出现在结果中
+	if got := HighlightTodo("团队", todo); len(got) != 1 || got["notes"] == nil {
+		t.Errorf("HighlightTodo(团队) = %v, want notes only", got)
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+	"github.com/Brower/backend/internal/storage"
+	"github.com/google/uuid"
+)
+
+// 未配置时使用的默认值
+const (
+	defaultAttachmentMaxSize = 10 << 20
+	defaultAttachmentURLTTL  = 15 * time.Minute
+)
+
+// AttachmentService 定义了附件服务的接口，所有方法返回的错误都是 *errors.Error。
+// 返回的附件都带有有效期为 attachments.url_ttl 的下载链接。
+type AttachmentService interface {
+	// List 获取待办事项的所有附件，按上传时间排序
+	List(ctx context.Context, userID, todoID string) (*models.AttachmentListResponse, error)
+
+	// Upload 给待办事项上传附件。文件类型按内容识别，超过大小限制时返回 ErrAttachmentTooLarge，
+	// 类型不在允许的范围内时返回 ErrAttachmentType
+	Upload(ctx context.Context, userID, todoID string, req models.UploadAttachmentRequest) (*models.AttachmentResponse, error)
+
+	// Get 获取待办事项的一个附件
+	Get(ctx context.Context, userID, todoID, id string) (*models.AttachmentResponse, error)
+
+	// Delete 删除附件，文件随后由清理任务删除
+	Delete(ctx context.Context, userID, todoID, id string) error
+}
+
+type attachmentService struct {
+	repo         repository.TodoRepository
+	attachments  repository.AttachmentRepository
+	shares       repository.ShareRepository
+	store        storage.BlobStore
+	validator    *AttachmentValidator
+	maxSize      int64
+	allowedTypes []string
+	urlTTL       time.Duration
+	now          func() time.Time
+}
+
+// NewAttachmentService 创建一个新的附件服务，todos 用于确认待办事项存在。
+// shares 不为 nil 时被共享的用户可以查看附件，editor 及以上角色可以上传和删除
+func NewAttachmentService(todos repository.TodoRepository, attachments repository.AttachmentRepository, shares repository.ShareRepository, store storage.BlobStore, validator *AttachmentValidator, cfg config.AttachmentConfig) AttachmentService {
+	s := &attachmentService{
+		repo:        todos,
+		attachments: attachments,
+		shares:      shares,
+		store:       store,
+		validator:   validator,
+		maxSize:     cfg.MaxSize,
+		urlTTL:      cfg.URLTTL,
+		now:         time.Now,
+	}
+	if s.maxSize <= 0 {
+		s.maxSize = defaultAttachmentMaxSize
+	}
+	if s.urlTTL <= 0 {
+		s.urlTTL = defaultAttachmentURLTTL
+	}
+	for _, t := range cfg.AllowedTypes {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			s.allowedTypes = append(s.allowedTypes, t)
+		}
+	}
+	return s
+}
+
+// List 获取待办事项的所有附件，按上传时间排序
+func (s *attachmentService) List(ctx context.Context, userID, todoID string) (*models.AttachmentListResponse, error) {
+	if err := validateID(ctx, todoID); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Get(ctx, ownerID, todoID); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	attachments, err := s.attachments.ListAttachments(ctx, ownerID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	response := &models.AttachmentListResponse{Items: make([]models.AttachmentResponse, len(attachments))}
+	for i := range attachments {
+		item, err := s.response(ctx, &attachments[i])
+		if err != nil {
+			return nil, err
+		}
+		response.Items[i] = *item
+	}
+	return response, nil
+}
+
+// Upload 给待办事项上传附件。一个待办事项最多 maxAttachmentsPerTodo 个附件。
+// 先保存文件再保存元数据，保存元数据失败时删除刚保存的文件
+func (s *attachmentService) Upload(ctx context.Context, userID, todoID string, req models.UploadAttachmentRequest) (*models.AttachmentResponse, error) {
+	if err := s.validator.ValidateUpload(ctx, todoID, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.Get(ctx, ownerID, todoID); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	existing, err := s.attachments.ListAttachments(ctx, ownerID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if len(existing) >= maxAttachmentsPerTodo {
+		violations := newViolations(ctx)
+		violations.add("file", errors.RuleMaxItems, errors.MsgFieldTooMany, maxAttachmentsPerTodo)
+		return nil, violations.err()
+	}
+
+	// 多读一个字节用于判断是否超过大小限制，超过的部分不会被读取
+	data, err := io.ReadAll(io.LimitReader(req.Body, s.maxSize+1))
+	if err != nil {
+		return nil, errors.New(errors.ErrInvalidParams, fmt.Errorf("读取上传的文件失败: %w", err))
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, errors.NewWithData(errors.ErrAttachmentTooLarge, map[string]any{"maxSize": s.maxSize})
+	}
+	if len(data) == 0 {
+		violations := newViolations(ctx)
+		violations.add("file", errors.RuleRequired, errors.MsgFieldRequired)
+		return nil, violations.err()
+	}
+
+	contentType := http.DetectContentType(data)
+	if !s.allowed(contentType) {
+		return nil, errors.NewWithData(errors.ErrAttachmentType, map[string]any{
+			"contentType":  contentType,
+			"allowedTypes": s.allowedTypes,
+		})
+	}
+
+	attachment := &models.Attachment{
+		ID:          uuid.New().String(),
+		TodoID:      todoID,
+		UserID:      ownerID,
+		Filename:    req.Filename,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		CreatedAt:   s.now(),
+	}
+	attachment.StorageKey = ownerID + "/" + todoID + "/" + attachment.ID
+
+	if err := s.store.Put(ctx, attachment.StorageKey, data, contentType); err != nil {
+		return nil, storageError(err)
+	}
+	if err := s.attachments.CreateAttachment(ctx, ownerID, attachment); err != nil {
+		// 没有元数据的文件不会被清理任务发现，只能在这里删除；请求被取消时也要删除
+		_ = s.store.Delete(context.WithoutCancel(ctx), attachment.StorageKey)
+		return nil, wrapRepositoryError(err)
+	}
+	return s.response(ctx, attachment)
+}
+
+// Get 获取待办事项的一个附件
+func (s *attachmentService) Get(ctx context.Context, userID, todoID, id string) (*models.AttachmentResponse, error) {
+	if err := s.validator.ValidateAttachment(ctx, todoID, id); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachments.GetAttachment(ctx, ownerID, todoID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.response(ctx, attachment)
+}
+
+// Delete 把附件与待办事项脱离后立即尝试删除文件和元数据，失败时留给清理任务
+func (s *attachmentService) Delete(ctx context.Context, userID, todoID, id string) error {
+	if err := s.validator.ValidateAttachment(ctx, todoID, id); err != nil {
+		return err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleEditor)
+	if err != nil {
+		return err
+	}
+
+	attachment, err := s.attachments.GetAttachment(ctx, ownerID, todoID, id)
+	if err != nil {
+		return wrapRepositoryError(err)
+	}
+	if err := s.attachments.DeleteAttachment(ctx, ownerID, todoID, id); err != nil {
+		return wrapRepositoryError(err)
+	}
+
+	if err := s.store.Delete(ctx, attachment.StorageKey); err == nil {
+		_ = s.attachments.PurgeAttachment(ctx, attachment.ID)
+	}
+	return nil
+}
+
+// allowed 判断识别出的类型是否在允许的范围内，允许的类型可以是 image/* 形式的通配
+func (s *attachmentService) allowed(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range s.allowedTypes {
+		if t == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// response 生成带有下载链接的附件响应
+func (s *attachmentService) response(ctx context.Context, attachment *models.Attachment) (*models.AttachmentResponse, error) {
+	response := attachment.ToResponse()
+	response.URLExpiresAt = s.now().Add(s.urlTTL).Truncate(time.Second)
+
+	url, err := s.store.SignedURL(ctx, attachment.StorageKey, storage.URLOptions{
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Expires:     response.URLExpiresAt,
+	})
+	if err != nil {
+		return nil, storageError(err)
+	}
+	response.URL = url
+	return &response, nil
+}
+
+// storageError 把文件存储返回的错误转换为业务错误
+func storageError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errors.New(errors.ErrTimeout, err)
+	}
+	return errors.New(errors.ErrInternal, fmt.Errorf("附件存储失败: %w", err))
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+)
+
+// maxAttachmentsPerTodo 一个待办事项最多带有的附件数，
+// maxAttachmentFilenameLength 附件文件名的最大长度
+const (
+	maxAttachmentsPerTodo       = 20
+	maxAttachmentFilenameLength = 255
+	defaultAttachmentFilename   = "attachment"
+)
+
+// AttachmentValidator 校验并规范化附件请求
+type AttachmentValidator struct{}
+
+// NewAttachmentValidator 创建附件校验器
+func NewAttachmentValidator() *AttachmentValidator {
+	return &AttachmentValidator{}
+}
+
+// ValidateAttachment 校验路径中的待办事项 ID 和附件 ID
+func (v *AttachmentValidator) ValidateAttachment(ctx context.Context, todoID, id string) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, todoID)
+	if strings.TrimSpace(id) == "" {
+		violations.add("attachmentId", errors.RuleRequired, errors.MsgFieldRequired)
+	}
+	return violations.err()
+}
+
+// ValidateUpload 校验上传附件的请求：必须带有 file 字段，文件名去掉路径和控制字符后
+// 截断到 maxAttachmentFilenameLength 个字符，为空时使用 defaultAttachmentFilename
+func (v *AttachmentValidator) ValidateUpload(ctx context.Context, todoID string, req *models.UploadAttachmentRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, todoID)
+	if req.Body == nil {
+		violations.add("file", errors.RuleRequired, errors.MsgFieldRequired)
+	}
+	req.Filename = attachmentFilename(req.Filename)
+	return violations.err()
+}
+
+// attachmentFilename 只保留文件名中最后一个斜杠或反斜杠之后的部分，去掉控制字符和首尾空白
+func attachmentFilename(name string) string {
+	if i := strings.LastIndexAny(name, "/\\"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == ".." {
+		return defaultAttachmentFilename
+	}
+	if runes := []rune(name); len(runes) > maxAttachmentFilenameLength {
+		name = string(runes[:maxAttachmentFilenameLength])
+	}
+	return name
+}
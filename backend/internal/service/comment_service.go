@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// CommentService 定义了评论服务的接口，所有方法返回的错误都是 *errors.Error。
+// 能看到待办事项的用户就能查看和发表评论，待办事项不可见时返回 ErrTodoNotFound；
+// 只有作者能修改和删除评论，其他用户返回 ErrForbidden
+type CommentService interface {
+	// List 按创建时间正序分页获取待办事项的评论
+	List(ctx context.Context, userID, todoID string, req models.ListCommentsRequest) (*models.CommentListResponse, error)
+
+	// Create 以 userID 为作者给待办事项添加评论
+	Create(ctx context.Context, userID, todoID string, req models.CreateCommentRequest) (*models.CommentResponse, error)
+
+	// Update 修改自己发表的评论
+	Update(ctx context.Context, userID, todoID, id string, req models.UpdateCommentRequest) (*models.CommentResponse, error)
+
+	// Delete 删除自己发表的评论
+	Delete(ctx context.Context, userID, todoID, id string) error
+}
+
+type commentService struct {
+	repo      repository.TodoRepository
+	comments  repository.CommentRepository
+	shares    repository.ShareRepository
+	validator *CommentValidator
+}
+
+// NewCommentService 创建一个新的评论服务，todos 用于确认待办事项存在。
+// shares 不为 nil 时被共享的用户可以查看和发表评论
+func NewCommentService(todos repository.TodoRepository, comments repository.CommentRepository, shares repository.ShareRepository, validator *CommentValidator) CommentService {
+	return &commentService{
+		repo:      todos,
+		comments:  comments,
+		shares:    shares,
+		validator: validator,
+	}
+}
+
+// List 按创建时间正序分页获取待办事项的评论，Total 为待办事项的评论总数
+func (s *commentService) List(ctx context.Context, userID, todoID string, req models.ListCommentsRequest) (*models.CommentListResponse, error) {
+	opts, err := s.validator.ParseList(ctx, todoID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkVisible(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	page, err := s.comments.ListComments(ctx, todoID, opts)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	counts, err := s.comments.CommentCounts(ctx, []string{todoID})
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	response := &models.CommentListResponse{
+		Items: make([]models.CommentResponse, len(page.Items)),
+		Total: counts[todoID],
+	}
+	for i := range page.Items {
+		response.Items[i] = page.Items[i].ToResponse()
+	}
+	if page.Next != nil {
+		response.NextCursor = page.Next.Encode()
+	}
+	return response, nil
+}
+
+// Create 以 userID 为作者给待办事项添加评论
+func (s *commentService) Create(ctx context.Context, userID, todoID string, req models.CreateCommentRequest) (*models.CommentResponse, error) {
+	if err := s.validator.ValidateCreate(ctx, todoID, &req); err != nil {
+		return nil, err
+	}
+	if err := s.checkVisible(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		TodoID: todoID,
+		UserID: userID,
+		Body:   req.Body,
+	}
+	if err := s.comments.CreateComment(ctx, comment); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := comment.ToResponse()
+	return &response, nil
+}
+
+// Update 修改自己发表的评论
+func (s *commentService) Update(ctx context.Context, userID, todoID, id string, req models.UpdateCommentRequest) (*models.CommentResponse, error) {
+	if err := s.validator.ValidateUpdate(ctx, todoID, id, &req); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.authored(ctx, userID, todoID, id)
+	if err != nil {
+		return nil, err
+	}
+	comment.Body = req.Body
+	if err := s.comments.UpdateComment(ctx, comment); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := comment.ToResponse()
+	return &response, nil
+}
+
+// Delete 删除自己发表的评论
+func (s *commentService) Delete(ctx context.Context, userID, todoID, id string) error {
+	if err := s.validator.ValidateComment(ctx, todoID, id); err != nil {
+		return err
+	}
+
+	if _, err := s.authored(ctx, userID, todoID, id); err != nil {
+		return err
+	}
+	if err := s.comments.DeleteComment(ctx, userID, todoID, id); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// checkVisible 确认用户能看到待办事项，不可见和不存在一样返回 ErrTodoNotFound。
+// 共享给用户的待办事项不论角色都可以查看和发表评论
+func (s *commentService) checkVisible(ctx context.Context, userID, todoID string) error {
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleViewer)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.Get(ctx, ownerID, todoID); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// authored 获取用户能看到的一条评论，评论的作者不是该用户时返回 ErrForbidden
+func (s *commentService) authored(ctx context.Context, userID, todoID, id string) (*models.Comment, error) {
+	if err := s.checkVisible(ctx, userID, todoID); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.comments.GetComment(ctx, todoID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if comment.UserID != userID {
+		return nil, errors.NewWithKey(errors.ErrForbidden, errors.MsgNotCommentAuthor)
+	}
+	return comment, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+func TestCommentServiceSharedViewer(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	shares := NewShareService(repo, NewShareValidator(), nil, config.SharingConfig{})
+	comments := NewCommentService(repo, repo, repo, NewCommentValidator(NewTodoValidator(config.TodoValidationConfig{})))
+	ctx := context.Background()
+
+	// owner-1 把待办事项以 viewer 角色共享给 user-2
+	token := inviteToTodo(t, repo, shares)
+	share, err := shares.Accept(ctx, "user-2", "bob@example.com", models.AcceptInvitationRequest{Token: token})
+	if err != nil {
+		t.Fatalf("Accept() error = %v", err)
+	}
+	if share.Role != models.ShareRoleViewer {
+		t.Fatalf("share role = %s, want viewer", share.Role)
+	}
+	todoID := share.ResourceID
+
+	ownerComment, err := comments.Create(ctx, "owner-1", todoID, models.CreateCommentRequest{Body: "所有者的评论"})
+	if err != nil {
+		t.Fatalf("Create(owner) error = %v", err)
+	}
+	// viewer 虽然不能修改待办事项，但可以查看和发表评论
+	viewerComment, err := comments.Create(ctx, "user-2", todoID, models.CreateCommentRequest{Body: "viewer 的评论"})
+	if err != nil {
+		t.Fatalf("Create(viewer) error = %v", err)
+	}
+	list, err := comments.List(ctx, "user-2", todoID, models.ListCommentsRequest{})
+	if err != nil {
+		t.Fatalf("List(viewer) error = %v", err)
+	}
+	if list.Total != 2 || len(list.Items) != 2 || list.Items[0].ID != ownerComment.ID || list.Items[1].ID != viewerComment.ID {
+		t.Errorf("List(viewer) = %+v, want both comments in creation order", list)
+	}
+
+	tests := []struct {
+		name     string
+		userID   string
+		id       string
+		wantCode errors.ErrorCode
+	}{
+		// 只有作者能修改和删除评论，所有者同样不能修改 viewer 的评论
+		{"viewer edits owner's comment", "user-2", ownerComment.ID, errors.ErrForbidden},
+		{"owner edits viewer's comment", "owner-1", viewerComment.ID, errors.ErrForbidden},
+		// 没有共享的用户看不到待办事项
+		{"stranger", "user-3", viewerComment.ID, errors.ErrTodoNotFound},
+		{"viewer edits own comment", "user-2", viewerComment.ID, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := comments.Update(ctx, tt.userID, todoID, tt.id, models.UpdateCommentRequest{Body: "已修改"})
+			deleteErr := comments.Delete(ctx, tt.userID, todoID, tt.id)
+			if tt.wantCode == 0 {
+				if err != nil || deleteErr != nil {
+					t.Errorf("Update() error = %v, Delete() error = %v, want nil", err, deleteErr)
+				}
+				return
+			}
+			for op, err := range map[string]error{"Update": err, "Delete": deleteErr} {
+				if e, ok := errors.As(err); !ok || e.Code != tt.wantCode {
+					t.Errorf("%s() error = %v, want code %d", op, err, tt.wantCode)
+				}
+			}
+		})
+	}
+
+	if _, err := comments.Create(ctx, "user-3", todoID, models.CreateCommentRequest{Body: "陌生人"}); err == nil {
+		t.Error("Create(stranger) error = nil, want ErrTodoNotFound")
+	}
+	list, err = comments.List(ctx, "owner-1", todoID, models.ListCommentsRequest{})
+	if err != nil {
+		t.Fatalf("List(owner) error = %v", err)
+	}
+	if list.Total != 1 || list.Items[0].ID != ownerComment.ID || list.Items[0].Body != "所有者的评论" {
+		t.Errorf("List(owner) = %+v, want only the unchanged owner comment", list)
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+)
+
+// maxCommentLength 评论的最大长度
+const maxCommentLength = 5000
+
+// CommentValidator 校验并规范化评论请求，评论列表的单页条数限制与待办事项列表相同
+type CommentValidator struct {
+	todo *TodoValidator
+}
+
+// NewCommentValidator 创建评论校验器，单页条数限制来自 todo
+func NewCommentValidator(todo *TodoValidator) *CommentValidator {
+	return &CommentValidator{todo: todo}
+}
+
+// ParseList 校验评论列表的查询参数，游标必须是按创建时间正序生成的
+func (v *CommentValidator) ParseList(ctx context.Context, todoID string, req models.ListCommentsRequest) (models.CommentListOptions, error) {
+	violations := newViolations(ctx)
+	validateIDInto(violations, todoID)
+	opts := models.CommentListOptions{
+		Limit: v.todo.limit(violations, req.Limit),
+	}
+	if req.Cursor != "" {
+		cursor, err := models.DecodeCursor(req.Cursor)
+		if err != nil || cursor.SortBy != models.SortByCreatedAt || cursor.SortDir != models.SortAsc {
+			violations.add("cursor", errors.RuleCursor, errors.MsgInvalidCursor)
+		} else if _, err := cursor.Time(); err != nil {
+			violations.add("cursor", errors.RuleCursor, errors.MsgInvalidCursor)
+		} else {
+			opts.After = cursor
+		}
+	}
+	return opts, violations.err()
+}
+
+// ValidateCreate 校验添加评论的请求
+func (v *CommentValidator) ValidateCreate(ctx context.Context, todoID string, req *models.CreateCommentRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, todoID)
+	req.Body = commentBody(violations, req.Body)
+	return violations.err()
+}
+
+// ValidateUpdate 校验修改评论的请求，评论内容的规则与添加时相同
+func (v *CommentValidator) ValidateUpdate(ctx context.Context, todoID, id string, req *models.UpdateCommentRequest) error {
+	violations := newViolations(ctx)
+	validateCommentIDInto(violations, todoID, id)
+	req.Body = commentBody(violations, req.Body)
+	return violations.err()
+}
+
+// ValidateComment 校验路径中的待办事项 ID 和评论 ID
+func (v *CommentValidator) ValidateComment(ctx context.Context, todoID, id string) error {
+	violations := newViolations(ctx)
+	validateCommentIDInto(violations, todoID, id)
+	return violations.err()
+}
+
+// validateCommentIDInto 校验路径中的待办事项 ID 和评论 ID 不为空
+func validateCommentIDInto(violations *violations, todoID, id string) {
+	validateIDInto(violations, todoID)
+	if strings.TrimSpace(id) == "" {
+		violations.add("commentId", errors.RuleRequired, errors.MsgFieldRequired)
+	}
+}
+
+// commentBody 校验评论内容，返回规范化后的内容：换行统一为 \n，去掉首尾的空白。
+// 内容不能为空，除换行和制表符外不允许控制字符
+func commentBody(violations *violations, body string) string {
+	const field = "body"
+
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = strings.ReplaceAll(body, "\r", "\n")
+	body = strings.TrimSpace(body)
+
+	length := utf8.RuneCountInString(body)
+	switch {
+	case length == 0:
+		violations.add(field, errors.RuleRequired, errors.MsgFieldRequired)
+		return body
+	case length > maxCommentLength:
+		violations.add(field, errors.RuleMaxLength, errors.MsgFieldTooLong, maxCommentLength)
+	}
+	if strings.IndexFunc(body, func(r rune) bool { return unicode.IsControl(r) && r != '\n' && r != '\t' }) >= 0 {
+		violations.add(field, errors.RuleControlChars, errors.MsgFieldControlChars)
+	}
+	return body
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// NotificationService 定义了站内通知服务的接口，所有方法返回的错误都是 *errors.Error
+type NotificationService interface {
+	// List 按创建时间倒序分页获取指定用户的站内通知
+	List(ctx context.Context, userID string, req models.ListNotificationsRequest) (*models.NotificationListResponse, error)
+
+	// MarkRead 将一条通知标记为已读
+	MarkRead(ctx context.Context, userID, id string) (*models.NotificationResponse, error)
+
+	// MarkAllRead 将指定用户的所有未读通知标记为已读
+	MarkAllRead(ctx context.Context, userID string) (*models.MarkAllReadResponse, error)
+}
+
+type notificationService struct {
+	repo repository.NotificationRepository
+	// validator 复用待办事项列表的分页限制
+	validator *TodoValidator
+}
+
+// NewNotificationService 创建一个新的站内通知服务
+func NewNotificationService(repo repository.NotificationRepository, validator *TodoValidator) NotificationService {
+	return &notificationService{
+		repo:      repo,
+		validator: validator,
+	}
+}
+
+// List 按创建时间倒序分页获取指定用户的站内通知
+func (s *notificationService) List(ctx context.Context, userID string, req models.ListNotificationsRequest) (*models.NotificationListResponse, error) {
+	violations := newViolations(ctx)
+	opts := models.NotificationListOptions{
+		Limit: s.validator.limit(violations, req.Limit),
+	}
+	if unread := parseBoolParam(violations, "unread", req.Unread); unread != nil {
+		opts.UnreadOnly = *unread
+	}
+	if req.Cursor != "" {
+		cursor, err := models.DecodeCursor(req.Cursor)
+		if err != nil || cursor.SortBy != models.SortByCreatedAt || cursor.SortDir != models.SortDesc {
+			violations.add("cursor", errors.RuleCursor, errors.MsgInvalidCursor)
+		} else if _, err := cursor.Time(); err != nil {
+			violations.add("cursor", errors.RuleCursor, errors.MsgInvalidCursor)
+		} else {
+			opts.After = cursor
+		}
+	}
+	if err := violations.err(); err != nil {
+		return nil, err
+	}
+
+	page, err := s.repo.ListNotifications(ctx, userID, opts)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	response := &models.NotificationListResponse{
+		Items:  make([]models.NotificationResponse, len(page.Items)),
+		Unread: page.Unread,
+	}
+	for i := range page.Items {
+		response.Items[i] = page.Items[i].ToResponse()
+	}
+	if page.Next != nil {
+		response.NextCursor = page.Next.Encode()
+	}
+	return response, nil
+}
+
+// MarkRead 将一条通知标记为已读
+func (s *notificationService) MarkRead(ctx context.Context, userID, id string) (*models.NotificationResponse, error) {
+	if err := s.validator.ValidateID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	n, err := s.repo.MarkNotificationRead(ctx, userID, id, time.Now())
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := n.ToResponse()
+	return &response, nil
+}
+
+// MarkAllRead 将指定用户的所有未读通知标记为已读
+func (s *notificationService) MarkAllRead(ctx context.Context, userID string) (*models.MarkAllReadResponse, error) {
+	updated, err := s.repo.MarkAllNotificationsRead(ctx, userID, time.Now())
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return &models.MarkAllReadResponse{Updated: updated}, nil
+}
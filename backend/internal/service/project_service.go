@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/rank"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// ProjectService 定义了项目服务的接口，所有方法返回的错误都是 *errors.Error。
+// 收件箱在第一次使用项目时自动创建，不能删除、归档或移动。
+type ProjectService interface {
+	// List 获取指定用户的项目，收件箱在最前，默认不包括已归档的项目
+	List(ctx context.Context, userID string, req models.ListProjectsRequest) (*models.ProjectListResponse, error)
+
+	// Get 获取指定用户的单个项目，共享给该用户的项目同样可以获取
+	Get(ctx context.Context, userID, id string) (*models.ProjectResponse, error)
+
+	// Create 创建项目，排在所有项目之后
+	Create(ctx context.Context, userID string, req models.CreateProjectRequest) (*models.ProjectResponse, error)
+
+	// Update 部分更新项目，只修改请求中出现的字段
+	Update(ctx context.Context, userID, id string, req models.UpdateProjectRequest) (*models.ProjectResponse, error)
+
+	// Move 把项目移动到另一个项目之前或之后
+	Move(ctx context.Context, userID, id string, req models.MoveProjectRequest) (*models.ProjectResponse, error)
+
+	// Delete 删除项目，项目中的待办事项按请求删除或移动到其他项目
+	Delete(ctx context.Context, userID, id string, req models.DeleteProjectRequest) error
+
+	// MoveTodos 把多个待办事项移动到项目中
+	MoveTodos(ctx context.Context, userID, id string, req models.MoveTodosRequest) (*models.MoveTodosResponse, error)
+}
+
+type projectService struct {
+	repo repository.ProjectRepository
+	// shares 存储后端不支持共享时为 nil
+	shares    repository.ShareRepository
+	validator *ProjectValidator
+}
+
+// NewProjectService 创建一个新的项目服务。除 Get 外只能管理自己或请求选择的工作区的项目，
+// 共享的项目中的待办事项通过待办事项服务访问
+func NewProjectService(repo repository.ProjectRepository, validator *ProjectValidator) ProjectService {
+	shares, _ := repo.(repository.ShareRepository)
+	return &projectService{
+		repo:      repo,
+		shares:    shares,
+		validator: validator,
+	}
+}
+
+// List 获取指定用户的项目，第一次获取时创建收件箱
+func (s *projectService) List(ctx context.Context, userID string, req models.ListProjectsRequest) (*models.ProjectListResponse, error) {
+	includeArchived, err := s.validator.ParseList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.EnsureInbox(ctx, ownerID); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	projects, err := s.repo.ListProjects(ctx, ownerID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	counts, err := s.repo.ProjectCounts(ctx, ownerID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	items := make([]models.ProjectResponse, 0, len(projects))
+	for i := range projects {
+		if projects[i].Archived && !includeArchived {
+			continue
+		}
+		items = append(items, projects[i].ToResponse(counts[projects[i].ID]))
+	}
+	return &models.ProjectListResponse{Items: items}, nil
+}
+
+// Get 获取指定用户的单个项目，共享的项目以所有者的身份读取，待办事项数量同样是所有者的
+func (s *projectService) Get(ctx context.Context, userID, id string) (*models.ProjectResponse, error) {
+	if err := validateID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	access, err := authorize(ctx, s.shares, userID, models.ShareProject, id, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.repo.GetProject(ctx, access.OwnerID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.response(ctx, access.OwnerID, project)
+}
+
+// Create 创建项目，未指定颜色时使用默认颜色
+func (s *projectService) Create(ctx context.Context, userID string, req models.CreateProjectRequest) (*models.ProjectResponse, error) {
+	if err := s.validator.ValidateCreate(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.EnsureInbox(ctx, ownerID); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	projects, err := s.repo.ListProjects(ctx, ownerID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	// 收件箱的位置为空，只在其他项目中取最后的位置
+	var last string
+	if n := len(projects); n > 0 && !projects[n-1].Inbox {
+		last = projects[n-1].Position
+	}
+	position, err := rank.Between(last, "")
+	if err != nil {
+		return nil, errors.New(errors.ErrInternal, fmt.Errorf("生成位置失败: %w", err))
+	}
+
+	project := &models.Project{
+		UserID:   ownerID,
+		Name:     req.Name,
+		Color:    req.Color,
+		Icon:     req.Icon,
+		Position: position,
+	}
+	if err := s.repo.CreateProject(ctx, ownerID, project); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := project.ToResponse(models.ProjectCounts{})
+	return &response, nil
+}
+
+// Update 部分更新项目，只修改请求中出现的字段，收件箱不能归档
+func (s *projectService) Update(ctx context.Context, userID, id string, req models.UpdateProjectRequest) (*models.ProjectResponse, error) {
+	if err := s.validator.ValidateUpdate(ctx, id, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := s.repo.GetProject(ctx, ownerID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if req.Name != nil {
+		project.Name = *req.Name
+	}
+	if req.Color != nil {
+		project.Color = *req.Color
+	}
+	if req.Icon != nil {
+		project.Icon = *req.Icon
+	}
+	if req.Archived != nil {
+		if *req.Archived && project.Inbox {
+			return nil, errors.New(errors.ErrInboxProject, fmt.Errorf("归档收件箱 %s", id))
+		}
+		project.Archived = *req.Archived
+	}
+
+	if err := s.repo.UpdateProject(ctx, ownerID, project); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.response(ctx, ownerID, project)
+}
+
+// Move 把项目移动到另一个项目之前或之后，新位置取两个相邻项目的位置之间。
+// 收件箱总在最前，移动到收件箱之前或之后都表示排在第一个
+func (s *projectService) Move(ctx context.Context, userID, id string, req models.MoveProjectRequest) (*models.ProjectResponse, error) {
+	if err := s.validator.ValidateMove(ctx, id, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.repo.ListProjects(ctx, ownerID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	find := func(id string) (*models.Project, error) {
+		i := slices.IndexFunc(projects, func(p models.Project) bool { return p.ID == id })
+		if i < 0 {
+			return nil, wrapRepositoryError(repository.ErrProjectNotFound)
+		}
+		return &projects[i], nil
+	}
+
+	project, err := find(id)
+	if err != nil {
+		return nil, err
+	}
+	if project.Inbox {
+		return nil, errors.New(errors.ErrInboxProject, fmt.Errorf("移动收件箱 %s", id))
+	}
+	siblingID, after := req.After, true
+	if req.Before != "" {
+		siblingID, after = req.Before, false
+	}
+	sibling, err := find(siblingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 除去收件箱和被移动的项目，其余项目已经按位置排好序
+	others := slices.DeleteFunc(slices.Clone(projects), func(p models.Project) bool {
+		return p.Inbox || p.ID == id
+	})
+
+	var left, right string
+	switch {
+	case sibling.Inbox:
+		if len(others) > 0 {
+			right = others[0].Position
+		}
+	case after:
+		// 并发创建可能产生相同的位置，跳过它们保证新位置严格位于两者之间
+		left = sibling.Position
+		for _, p := range others {
+			if p.Position > left {
+				right = p.Position
+				break
+			}
+		}
+	default:
+		right = sibling.Position
+		for _, p := range slices.Backward(others) {
+			if p.Position < right {
+				left = p.Position
+				break
+			}
+		}
+	}
+	position, err := rank.Between(left, right)
+	if err != nil {
+		return nil, errors.New(errors.ErrInternal, fmt.Errorf("生成位置失败: %w", err))
+	}
+
+	project.Position = position
+	if err := s.repo.UpdateProject(ctx, ownerID, project); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.response(ctx, ownerID, project)
+}
+
+// Delete 删除项目。cascade 为 true 时同时删除项目中的待办事项，
+// 否则把它们移动到 move_to 指定的项目，未指定时移动到收件箱
+func (s *projectService) Delete(ctx context.Context, userID, id string, req models.DeleteProjectRequest) error {
+	cascade, moveTo, err := s.validator.ParseDelete(ctx, id, req)
+	if err != nil {
+		return err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.repo.GetProject(ctx, ownerID, id)
+	if err != nil {
+		return wrapRepositoryError(err)
+	}
+	if project.Inbox {
+		return errors.New(errors.ErrInboxProject, fmt.Errorf("删除收件箱 %s", id))
+	}
+
+	if !cascade && moveTo == "" {
+		inbox, err := s.repo.EnsureInbox(ctx, ownerID)
+		if err != nil {
+			return wrapRepositoryError(err)
+		}
+		moveTo = inbox.ID
+	}
+
+	if err := s.repo.DeleteProject(ctx, ownerID, id, moveTo); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// MoveTodos 把多个待办事项移动到项目中，不存在的待办事项被忽略
+func (s *projectService) MoveTodos(ctx context.Context, userID, id string, req models.MoveTodosRequest) (*models.MoveTodosResponse, error) {
+	if err := s.validator.ValidateMoveTodos(ctx, id, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetProject(ctx, ownerID, id); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	moved, err := s.repo.MoveTodos(ctx, ownerID, req.TodoIDs, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return &models.MoveTodosResponse{Moved: moved}, nil
+}
+
+// response 生成带有待办事项数量的项目响应
+func (s *projectService) response(ctx context.Context, userID string, project *models.Project) (*models.ProjectResponse, error) {
+	counts, err := s.repo.ProjectCounts(ctx, userID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := project.ToResponse(counts[project.ID])
+	return &response, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// projectCounts 返回项目现在的待办事项数量
+func projectCounts(t *testing.T, projects ProjectService, userID, id string) models.ProjectCounts {
+	t.Helper()
+
+	project, err := projects.Get(context.Background(), userID, id)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", id, err)
+	}
+	return project.Counts
+}
+
+func TestProjectServiceMoveTodosUpdatesCounts(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	projects := NewProjectService(repo, NewProjectValidator())
+	ctx := context.Background()
+
+	work, err := projects.Create(ctx, "user-1", models.CreateProjectRequest{Name: "工作"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	home, err := projects.Create(ctx, "user-1", models.CreateProjectRequest{Name: "家庭"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	var ids []string
+	for i, completed := range []bool{false, true, false} {
+		todo := &models.Todo{Title: "待办", Completed: completed, ProjectID: work.ID, Position: string(rune('A' + i))}
+		if err := repo.Create(ctx, "user-1", todo); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		ids = append(ids, todo.ID)
+	}
+	foreign := &models.Todo{Title: "其他用户的待办"}
+	if err := repo.Create(ctx, "user-2", foreign); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if got := projectCounts(t, projects, "user-1", work.ID); got != (models.ProjectCounts{Total: 3, Completed: 1}) {
+		t.Errorf("work counts = %+v, want 3 total, 1 completed", got)
+	}
+
+	// 其他用户的待办事项和不存在的 ID 被忽略
+	moved, err := projects.MoveTodos(ctx, "user-1", home.ID, models.MoveTodosRequest{
+		TodoIDs: []string{ids[0], ids[1], foreign.ID, "00000000-0000-4000-8000-000000000000"},
+	})
+	if err != nil {
+		t.Fatalf("MoveTodos() error = %v", err)
+	}
+	if moved.Moved != 2 {
+		t.Errorf("MoveTodos() moved = %d, want 2", moved.Moved)
+	}
+	if got := projectCounts(t, projects, "user-1", work.ID); got != (models.ProjectCounts{Total: 1, Completed: 0}) {
+		t.Errorf("work counts after move = %+v, want 1 total, 0 completed", got)
+	}
+	if got := projectCounts(t, projects, "user-1", home.ID); got != (models.ProjectCounts{Total: 2, Completed: 1}) {
+		t.Errorf("home counts after move = %+v, want 2 total, 1 completed", got)
+	}
+	if todo, err := repo.Get(ctx, "user-2", foreign.ID); err != nil || todo.ProjectID != "" {
+		t.Errorf("foreign todo = %+v, %v, want untouched", todo, err)
+	}
+
+	// 列表中的数量与单个项目一致，收件箱在最前
+	list, err := projects.List(ctx, "user-1", models.ListProjectsRequest{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list.Items) != 3 || !list.Items[0].Inbox {
+		t.Fatalf("List() = %+v, want inbox, 工作, 家庭", list.Items)
+	}
+	wantCounts := map[string]models.ProjectCounts{
+		list.Items[0].ID: {},
+		work.ID:          {Total: 1},
+		home.ID:          {Total: 2, Completed: 1},
+	}
+	for _, item := range list.Items {
+		if item.Counts != wantCounts[item.ID] {
+			t.Errorf("List() counts of %q = %+v, want %+v", item.Name, item.Counts, wantCounts[item.ID])
+		}
+	}
+
+	// 删除项目时待办事项默认移动到收件箱
+	if err := projects.Delete(ctx, "user-1", home.ID, models.DeleteProjectRequest{}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := projectCounts(t, projects, "user-1", list.Items[0].ID); got != (models.ProjectCounts{Total: 2, Completed: 1}) {
+		t.Errorf("inbox counts after delete = %+v, want 2 total, 1 completed", got)
+	}
+
+	// cascade 为 true 时同时删除项目中的待办事项
+	if err := projects.Delete(ctx, "user-1", work.ID, models.DeleteProjectRequest{Cascade: "true"}); err != nil {
+		t.Fatalf("Delete(cascade) error = %v", err)
+	}
+	if _, err := repo.Get(ctx, "user-1", ids[2]); err != repository.ErrTodoNotFound {
+		t.Errorf("Get() after cascade delete error = %v, want %v", err, repository.ErrTodoNotFound)
+	}
+
+	// 收件箱不能删除
+	err = projects.Delete(ctx, "user-1", list.Items[0].ID, models.DeleteProjectRequest{})
+	if e, ok := errors.As(err); !ok || e.Code != errors.ErrInboxProject {
+		t.Errorf("Delete(inbox) error = %v, want code %d", err, errors.ErrInboxProject)
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+)
+
+// maxProjectNameLength 项目名称的最大长度，maxProjectIconLength 图标的最大长度，
+// maxMoveTodos 一次最多移动到项目中的待办事项数
+const (
+	maxProjectNameLength = 100
+	maxProjectIconLength = 32
+	maxMoveTodos         = 100
+)
+
+// ProjectValidator 校验并规范化项目请求
+type ProjectValidator struct{}
+
+// NewProjectValidator 创建项目校验器
+func NewProjectValidator() *ProjectValidator {
+	return &ProjectValidator{}
+}
+
+// ParseList 解析项目列表的查询参数，返回是否包含已归档的项目
+func (v *ProjectValidator) ParseList(ctx context.Context, req models.ListProjectsRequest) (bool, error) {
+	violations := newViolations(ctx)
+	includeArchived := parseBoolParam(violations, "include_archived", req.IncludeArchived)
+	return includeArchived != nil && *includeArchived, violations.err()
+}
+
+// ValidateCreate 校验创建项目的请求，去掉名称和图标首尾空白，
+// 颜色为空时使用默认颜色并统一为小写
+func (v *ProjectValidator) ValidateCreate(ctx context.Context, req *models.CreateProjectRequest) error {
+	violations := newViolations(ctx)
+	req.Name = validateNameInto(violations, req.Name, maxProjectNameLength)
+	if req.Color == "" {
+		req.Color = models.DefaultProjectColor
+	}
+	req.Color = validateColorInto(violations, req.Color)
+	req.Icon = validateIconInto(violations, req.Icon)
+	return violations.err()
+}
+
+// ValidateUpdate 校验更新项目的请求，只校验请求中出现的字段
+func (v *ProjectValidator) ValidateUpdate(ctx context.Context, id string, req *models.UpdateProjectRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+	if req.Name != nil {
+		name := validateNameInto(violations, *req.Name, maxProjectNameLength)
+		req.Name = &name
+	}
+	if req.Color != nil {
+		color := validateColorInto(violations, *req.Color)
+		req.Color = &color
+	}
+	if req.Icon != nil {
+		icon := validateIconInto(violations, *req.Icon)
+		req.Icon = &icon
+	}
+	return violations.err()
+}
+
+// ValidateMove 校验移动项目的请求，before 和 after 必须且只能提供一个，且不能是项目自身
+func (v *ProjectValidator) ValidateMove(ctx context.Context, id string, req *models.MoveProjectRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+
+	req.Before = strings.TrimSpace(req.Before)
+	req.After = strings.TrimSpace(req.After)
+	switch {
+	case req.Before == "" && req.After == "":
+		violations.add("before", errors.RuleRequired, errors.MsgMoveTarget)
+	case req.Before != "" && req.After != "":
+		violations.add("after", errors.RuleMoveTarget, errors.MsgMoveTarget)
+	case req.Before == id:
+		violations.add("before", errors.RuleMoveTarget, errors.MsgMoveProjectSelf)
+	case req.After == id:
+		violations.add("after", errors.RuleMoveTarget, errors.MsgMoveProjectSelf)
+	}
+	return violations.err()
+}
+
+// ParseDelete 解析删除项目的查询参数，返回是否级联删除待办事项和接收待办事项的项目。
+// cascade 为 true 时不能指定 move_to，move_to 也不能是被删除的项目
+func (v *ProjectValidator) ParseDelete(ctx context.Context, id string, req models.DeleteProjectRequest) (bool, string, error) {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+
+	cascade := parseBoolParam(violations, "cascade", req.Cascade)
+	moveTo := strings.TrimSpace(req.MoveTo)
+	switch {
+	case moveTo == "":
+	case cascade != nil && *cascade:
+		violations.add("move_to", errors.RuleExclusive, errors.MsgCascadeMoveTo)
+	case moveTo == id:
+		violations.add("move_to", errors.RuleMoveTarget, errors.MsgMoveToDeleted)
+	}
+	return cascade != nil && *cascade, moveTo, violations.err()
+}
+
+// ValidateMoveTodos 校验批量移动待办事项到项目的请求，去掉重复的待办事项 ID
+func (v *ProjectValidator) ValidateMoveTodos(ctx context.Context, projectID string, req *models.MoveTodosRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, projectID)
+
+	todoIDs := make([]string, 0, len(req.TodoIDs))
+	for _, id := range req.TodoIDs {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			violations.add("todoIds", errors.RuleRequired, errors.MsgFieldRequired)
+			break
+		}
+		if !slices.Contains(todoIDs, id) {
+			todoIDs = append(todoIDs, id)
+		}
+	}
+	switch {
+	case len(req.TodoIDs) == 0:
+		violations.add("todoIds", errors.RuleRequired, errors.MsgFieldRequired)
+	case len(todoIDs) > maxMoveTodos:
+		violations.add("todoIds", errors.RuleMaxItems, errors.MsgFieldTooMany, maxMoveTodos)
+	}
+	req.TodoIDs = todoIDs
+	return violations.err()
+}
+
+// validateIconInto 校验项目图标，返回去掉首尾空白后的图标，空字符串表示没有图标
+func validateIconInto(violations *violations, icon string) string {
+	const field = "icon"
+
+	icon = strings.TrimSpace(icon)
+	if utf8.RuneCountInString(icon) > maxProjectIconLength {
+		violations.add(field, errors.RuleMaxLength, errors.MsgFieldTooLong, maxProjectIconLength)
+	}
+	if strings.IndexFunc(icon, unicode.IsControl) >= 0 {
+		violations.add(field, errors.RuleControlChars, errors.MsgFieldControlChars)
+	}
+	return icon
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// defaultInvitationTTL 未配置 sharing.invitation_ttl 时邀请的有效期
+const defaultInvitationTTL = 7 * 24 * time.Hour
+
+// invitationTokenBytes 邀请令牌的随机字节数
+const invitationTokenBytes = 32
+
+// ShareService 定义了共享服务的接口，所有方法返回的错误都是 *errors.Error。
+// 资源由 resourceType 和 resourceID 指定；看不到资源时返回 ErrTodoNotFound 或 ErrProjectNotFound，
+// 管理共享和邀请需要 owner 角色，角色不够时返回 ErrForbidden
+type ShareService interface {
+	// List 获取资源的共享和还没有接受的邀请
+	List(ctx context.Context, userID string, resourceType models.ShareResourceType, resourceID string) (*models.ShareListResponse, error)
+
+	// Invite 通过邮箱邀请用户共享资源，响应中带有只返回这一次的令牌
+	Invite(ctx context.Context, userID string, resourceType models.ShareResourceType, resourceID string, req models.CreateInvitationRequest) (*models.InvitationResponse, error)
+
+	// RevokeShare 撤销一条共享，被共享的用户也可以撤销自己的共享以退出
+	RevokeShare(ctx context.Context, userID string, resourceType models.ShareResourceType, resourceID, id string) error
+
+	// RevokeInvitation 撤销还没有接受的邀请
+	RevokeInvitation(ctx context.Context, userID string, resourceType models.ShareResourceType, resourceID, id string) error
+
+	// Accept 接受邀请。email 为登录用户的邮箱，不为空时必须与邀请的邮箱相同
+	Accept(ctx context.Context, userID, email string, req models.AcceptInvitationRequest) (*models.ShareResponse, error)
+
+	// SharedWithMe 获取共享给当前用户的待办事项和项目
+	SharedWithMe(ctx context.Context, userID string) (*models.SharedWithMeResponse, error)
+}
+
+// InvitationMailer 发送邀请邮件，link 为接受邀请的链接，未配置 accept_url 时为令牌本身
+type InvitationMailer interface {
+	SendInvitation(ctx context.Context, invitation models.Invitation, link string) error
+}
+
+type shareService struct {
+	shares    repository.ShareRepository
+	validator *ShareValidator
+	// mailer 未配置邮件服务器时为 nil，此时只在响应中返回令牌
+	mailer        InvitationMailer
+	invitationTTL time.Duration
+	acceptURL     string
+	// acceptWithoutEmail 访问令牌中没有邮箱时是否只凭邀请令牌接受邀请
+	acceptWithoutEmail bool
+	logger             *zap.Logger
+	now                func() time.Time
+}
+
+// NewShareService 创建一个新的共享服务，mailer 为 nil 时只在响应中返回邀请令牌
+func NewShareService(shares repository.ShareRepository, validator *ShareValidator, mailer InvitationMailer, cfg config.SharingConfig) ShareService {
+	s := &shareService{
+		shares:             shares,
+		validator:          validator,
+		mailer:             mailer,
+		invitationTTL:      cfg.InvitationTTL,
+		acceptURL:          cfg.AcceptURL,
+		acceptWithoutEmail: cfg.AcceptWithoutEmail,
+		logger:             logger.Log.With(zap.String("component", "ShareService")),
+		now:                time.Now,
+	}
+	if s.invitationTTL <= 0 {
+		s.invitationTTL = defaultInvitationTTL
+	}
+	return s
+}
+
+// List 获取资源的共享和还没有接受的邀请，过期的邀请不返回
+func (s *shareService) List(ctx context.Context, userID string, resourceType models.ShareResourceType, resourceID string) (*models.ShareListResponse, error) {
+	if err := validateID(ctx, resourceID); err != nil {
+		return nil, err
+	}
+	if _, err := authorize(ctx, s.shares, userID, resourceType, resourceID, models.ShareRoleOwner); err != nil {
+		return nil, err
+	}
+
+	shares, err := s.shares.ListShares(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	invitations, err := s.shares.ListInvitations(ctx, resourceType, resourceID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	response := &models.ShareListResponse{
+		Items:       make([]models.ShareResponse, len(shares)),
+		Invitations: make([]models.InvitationResponse, 0, len(invitations)),
+	}
+	for i := range shares {
+		response.Items[i] = shares[i].ToResponse()
+	}
+	now := s.now()
+	for i := range invitations {
+		if invitations[i].ExpiresAt.After(now) {
+			response.Invitations = append(response.Invitations, invitations[i].ToResponse())
+		}
+	}
+	return response, nil
+}
+
+// Invite 通过邮箱邀请用户共享资源。令牌只保存 SHA-256，明文只在这次响应和邀请邮件中出现；
+// 邮件发送失败不影响邀请本身，响应中的 emailSent 为 false
+func (s *shareService) Invite(ctx context.Context, userID string, resourceType models.ShareResourceType, resourceID string, req models.CreateInvitationRequest) (*models.InvitationResponse, error) {
+	if err := s.validator.ValidateCreateInvitation(ctx, resourceID, &req); err != nil {
+		return nil, err
+	}
+	access, err := authorize(ctx, s.shares, userID, resourceType, resourceID, models.ShareRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, errors.New(errors.ErrInternal, err)
+	}
+	role, _ := models.ParseShareRole(req.Role)
+	now := s.now()
+	invitation := &models.Invitation{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		OwnerID:      access.OwnerID,
+		Email:        req.Email,
+		Role:         role,
+		TokenHash:    hashInvitationToken(token),
+		InvitedBy:    userID,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(s.invitationTTL),
+	}
+	if err := s.shares.CreateInvitation(ctx, invitation); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	response := invitation.ToResponse()
+	response.Token = token
+	link := token
+	if s.acceptURL != "" {
+		response.AcceptURL = s.invitationLink(token)
+		link = response.AcceptURL
+	}
+
+	sent := false
+	if s.mailer != nil {
+		if err := s.mailer.SendInvitation(ctx, *invitation, link); err != nil {
+			logger.WithContext(ctx, s.logger).Warn("发送邀请邮件失败",
+				zap.String("invitationID", invitation.ID),
+				zap.Error(err))
+		} else {
+			sent = true
+		}
+	}
+	response.EmailSent = &sent
+	return &response, nil
+}
+
+// RevokeShare 撤销一条共享。owner 角色可以撤销任何共享，被共享的用户只能撤销自己的
+func (s *shareService) RevokeShare(ctx context.Context, userID string, resourceType models.ShareResourceType, resourceID, id string) error {
+	if err := s.validator.ValidateShare(ctx, resourceID, id); err != nil {
+		return err
+	}
+	access, err := authorize(ctx, s.shares, userID, resourceType, resourceID, models.ShareRoleViewer)
+	if err != nil {
+		return err
+	}
+
+	share, err := s.shares.GetShare(ctx, resourceType, resourceID, id)
+	if err != nil {
+		return wrapRepositoryError(err)
+	}
+	if share.UserID != userID && !access.Role.Allows(models.ShareRoleOwner) {
+		return errors.NewWithKey(errors.ErrForbidden, errors.MsgShareOwner)
+	}
+
+	if err := s.shares.DeleteShare(ctx, resourceType, resourceID, id); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// RevokeInvitation 撤销还没有接受的邀请
+func (s *shareService) RevokeInvitation(ctx context.Context, userID string, resourceType models.ShareResourceType, resourceID, id string) error {
+	if err := s.validator.ValidateInvitation(ctx, resourceID, id); err != nil {
+		return err
+	}
+	if _, err := authorize(ctx, s.shares, userID, resourceType, resourceID, models.ShareRoleOwner); err != nil {
+		return err
+	}
+
+	if err := s.shares.DeleteInvitation(ctx, resourceType, resourceID, id); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// Accept 接受邀请，邀请随即失效。已经有共享时保留权限较高的角色；
+// 过期的邀请和不存在的一样返回 ErrInvitationNotFound。email 必须与邀请的邮箱相同，
+// 为空时只有配置了 sharing.accept_without_email 才能接受
+func (s *shareService) Accept(ctx context.Context, userID, email string, req models.AcceptInvitationRequest) (*models.ShareResponse, error) {
+	if err := s.validator.ValidateAcceptInvitation(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.shares.GetInvitationByToken(ctx, hashInvitationToken(req.Token))
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if !invitation.ExpiresAt.After(s.now()) {
+		return nil, wrapRepositoryError(repository.ErrInvitationNotFound)
+	}
+	if invitation.OwnerID == userID {
+		return nil, errors.NewWithKey(errors.ErrForbidden, errors.MsgInvitationOwner)
+	}
+	if email == "" {
+		if !s.acceptWithoutEmail {
+			return nil, errors.NewWithKey(errors.ErrForbidden, errors.MsgInvitationNoEmail)
+		}
+	} else if !strings.EqualFold(email, invitation.Email) {
+		return nil, errors.NewWithKey(errors.ErrForbidden, errors.MsgInvitationEmail)
+	}
+
+	share, err := s.shares.AcceptInvitation(ctx, invitation, userID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := share.ToResponse()
+	return &response, nil
+}
+
+// SharedWithMe 获取共享给当前用户的待办事项和项目，按共享时间排序
+func (s *shareService) SharedWithMe(ctx context.Context, userID string) (*models.SharedWithMeResponse, error) {
+	shares, err := s.shares.ListSharedWith(ctx, userID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	response := &models.SharedWithMeResponse{Items: make([]models.ShareResponse, len(shares))}
+	for i := range shares {
+		response.Items[i] = shares[i].ToResponse()
+	}
+	return response, nil
+}
+
+// invitationLink 把令牌作为 token 查询参数附加到 accept_url 后面
+func (s *shareService) invitationLink(token string) string {
+	u, err := url.Parse(s.acceptURL)
+	if err != nil {
+		return s.acceptURL + "?token=" + url.QueryEscape(token)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// authorize 返回用户对资源的权限，之后以 access.OwnerID 的身份访问仓库层。
+// 请求选择了工作区时以工作区作为访问资源的身份，角色不超过成员角色对应的权限。
+// shares 为 nil 时存储后端不支持共享，只有所有者自己可以访问，资源是否存在由之后的查询判断；
+// 看不到资源时返回不存在，角色低于 need 时返回 ErrForbidden
+func authorize(ctx context.Context, shares repository.ShareRepository, userID string, resourceType models.ShareResourceType, resourceID string, need models.ShareRole) (*models.Access, error) {
+	principal, limit := userID, models.ShareRoleOwner
+	member, inWorkspace := models.MemberFromContext(ctx)
+	if inWorkspace {
+		principal, limit = member.WorkspaceID, member.Role.ShareRole()
+	}
+
+	var (
+		access *models.Access
+		err    error
+	)
+	switch {
+	case shares == nil:
+		access = &models.Access{OwnerID: principal, Role: limit}
+	case resourceType == models.ShareProject:
+		access, err = shares.ProjectAccess(ctx, principal, resourceID)
+	default:
+		access, err = shares.TodoAccess(ctx, principal, resourceID)
+	}
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if !limit.Allows(access.Role) {
+		access.Role = limit
+	}
+
+	if !access.Role.Allows(need) {
+		if inWorkspace && !limit.Allows(need) {
+			return nil, errors.NewWithKey(errors.ErrForbidden, errors.MsgWorkspaceRole)
+		}
+		key := errors.MsgShareOwner
+		if need == models.ShareRoleEditor {
+			key = errors.MsgShareEditor
+		}
+		return nil, errors.NewWithKey(errors.ErrForbidden, key)
+	}
+	return access, nil
+}
+
+// todoOwner 确认用户对待办事项至少有 need 角色，返回之后访问仓库层使用的所有者 ID
+func todoOwner(ctx context.Context, shares repository.ShareRepository, userID, todoID string, need models.ShareRole) (string, error) {
+	access, err := authorize(ctx, shares, userID, models.ShareTodo, todoID, need)
+	if err != nil {
+		return "", err
+	}
+	return access.OwnerID, nil
+}
+
+// scopeOwner 返回列出和创建资源时使用的所有者 ID：请求没有选择工作区时是用户自己，
+// 选择了工作区时是工作区，成员角色对应的权限低于 need 时返回 ErrForbidden
+func scopeOwner(ctx context.Context, userID string, need models.ShareRole) (string, error) {
+	member, ok := models.MemberFromContext(ctx)
+	if !ok {
+		return userID, nil
+	}
+	if !member.Role.ShareRole().Allows(need) {
+		return "", errors.NewWithKey(errors.ErrForbidden, errors.MsgWorkspaceRole)
+	}
+	return member.WorkspaceID, nil
+}
+
+// newInvitationToken 生成 URL 安全的随机邀请令牌
+func newInvitationToken() (string, error) {
+	b := make([]byte, invitationTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成邀请令牌失败: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashInvitationToken 计算邀请令牌的 SHA-256，数据库中只保存这个值
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/i18n"
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	os.Exit(m.Run())
+}
+
+// inviteToTodo 创建 owner-1 的待办事项并邀请 bob@example.com，返回邀请令牌
+func inviteToTodo(t *testing.T, repo *repository.InMemoryTodoRepository, shares ShareService) string {
+	t.Helper()
+
+	ctx := context.Background()
+	todo := &models.Todo{Title: "共享的待办事项"}
+	if err := repo.Create(ctx, "owner-1", todo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	invitation, err := shares.Invite(ctx, "owner-1", models.ShareTodo, todo.ID, models.CreateInvitationRequest{Email: "bob@example.com"})
+	if err != nil {
+		t.Fatalf("Invite() error = %v", err)
+	}
+	return invitation.Token
+}
+
+func TestShareServiceAcceptChecksEmail(t *testing.T) {
+	tests := []struct {
+		name               string
+		email              string
+		acceptWithoutEmail bool
+		wantKey            errors.MessageKey
+	}{
+		{name: "same email", email: "bob@example.com"},
+		{name: "email differs in case", email: "Bob@Example.com"},
+		{name: "other email", email: "mallory@example.com", wantKey: errors.MsgInvitationEmail},
+		{name: "missing email", wantKey: errors.MsgInvitationNoEmail},
+		{name: "missing email allowed by config", acceptWithoutEmail: true},
+		{name: "other email with config", email: "mallory@example.com", acceptWithoutEmail: true, wantKey: errors.MsgInvitationEmail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewInMemoryTodoRepository()
+			shares := NewShareService(repo, NewShareValidator(), nil, config.SharingConfig{AcceptWithoutEmail: tt.acceptWithoutEmail})
+			token := inviteToTodo(t, repo, shares)
+
+			share, err := shares.Accept(context.Background(), "user-2", tt.email, models.AcceptInvitationRequest{Token: token})
+			if tt.wantKey == "" {
+				if err != nil {
+					t.Fatalf("Accept() error = %v", err)
+				}
+				if share.UserID != "user-2" || share.Role != models.ShareRoleViewer {
+					t.Errorf("Accept() = %+v, want viewer share for user-2", share)
+				}
+				return
+			}
+
+			e, ok := errors.As(err)
+			if !ok || e.Code != errors.ErrForbidden || e.Message != errors.Detail(tt.wantKey, i18n.DefaultLocale) {
+				t.Fatalf("Accept() error = %v, want forbidden %s", err, tt.wantKey)
+			}
+			// 被拒绝的邀请仍然有效，被邀请的用户之后可以接受
+			if _, err := shares.Accept(context.Background(), "user-3", "bob@example.com", models.AcceptInvitationRequest{Token: token}); err != nil {
+				t.Errorf("Accept() by invitee after rejection error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeWorkspaceRole(t *testing.T) {
+	const workspaceID = "workspace-1"
+	repo := repository.NewInMemoryTodoRepository()
+	todo := &models.Todo{Title: "工作区的待办事项"}
+	if err := repo.Create(context.Background(), workspaceID, todo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	needs := []models.ShareRole{models.ShareRoleViewer, models.ShareRoleEditor, models.ShareRoleOwner}
+	tests := []struct {
+		role models.WorkspaceRole
+		// allowed 该角色满足的 needs
+		allowed []models.ShareRole
+	}{
+		{models.WorkspaceGuest, needs[:1]},
+		{models.WorkspaceMember, needs[:2]},
+		{models.WorkspaceAdmin, needs},
+		{models.WorkspaceOwner, needs},
+	}
+	for _, tt := range tests {
+		for _, need := range needs {
+			t.Run(string(tt.role)+"/"+string(need), func(t *testing.T) {
+				ctx := models.ContextWithMember(context.Background(), &models.Member{WorkspaceID: workspaceID, UserID: "user-1", Role: tt.role})
+				want := false
+				for _, allowed := range tt.allowed {
+					want = want || allowed == need
+				}
+
+				access, err := authorize(ctx, repo, "user-1", models.ShareTodo, todo.ID, need)
+				if want {
+					if err != nil {
+						t.Fatalf("authorize() error = %v", err)
+					}
+					if access.OwnerID != workspaceID || access.Role != tt.role.ShareRole() {
+						t.Errorf("authorize() = %+v, want owner %s with role %s", access, workspaceID, tt.role.ShareRole())
+					}
+				} else {
+					e, ok := errors.As(err)
+					if !ok || e.Code != errors.ErrForbidden || e.Message != errors.Detail(errors.MsgWorkspaceRole, i18n.DefaultLocale) {
+						t.Errorf("authorize() error = %v, want forbidden %s", err, errors.MsgWorkspaceRole)
+					}
+				}
+
+				owner, err := scopeOwner(ctx, "user-1", need)
+				if want != (err == nil) || (want && owner != workspaceID) {
+					t.Errorf("scopeOwner() = %q, %v, want allowed %v", owner, err, want)
+				}
+			})
+		}
+	}
+
+	// 没有选择工作区时以用户自己的身份访问，看不到工作区的待办事项
+	if _, err := authorize(context.Background(), repo, "user-1", models.ShareTodo, todo.ID, models.ShareRoleViewer); err == nil {
+		t.Error("authorize() outside the workspace error = nil, want not found")
+	}
+	if owner, err := scopeOwner(context.Background(), "user-1", models.ShareRoleOwner); err != nil || owner != "user-1" {
+		t.Errorf("scopeOwner() outside the workspace = %q, %v, want user-1", owner, err)
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+)
+
+// maxEmailLength 邀请邮箱的最大长度，maxInvitationTokenLength 邀请令牌的最大长度
+const (
+	maxEmailLength           = 254
+	maxInvitationTokenLength = 128
+)
+
+// ShareValidator 校验并规范化共享和邀请请求
+type ShareValidator struct{}
+
+// NewShareValidator 创建共享校验器
+func NewShareValidator() *ShareValidator {
+	return &ShareValidator{}
+}
+
+// ValidateCreateInvitation 校验邀请用户的请求。邮箱统一为小写，不接受带名称的地址；
+// 未指定角色时为 viewer
+func (v *ShareValidator) ValidateCreateInvitation(ctx context.Context, resourceID string, req *models.CreateInvitationRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, resourceID)
+	req.Email = invitationEmail(violations, req.Email)
+
+	req.Role = strings.TrimSpace(req.Role)
+	if req.Role == "" {
+		req.Role = string(models.ShareRoleViewer)
+	}
+	if _, ok := models.ParseShareRole(req.Role); !ok {
+		names := make([]string, len(models.ShareRoles))
+		for i, role := range models.ShareRoles {
+			names[i] = string(role)
+		}
+		violations.add("role", errors.RuleEnum, errors.MsgFieldEnum, strings.Join(names, ", "))
+	}
+	return violations.err()
+}
+
+// ValidateShare 校验路径中的资源 ID 和共享 ID
+func (v *ShareValidator) ValidateShare(ctx context.Context, resourceID, id string) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, resourceID)
+	if strings.TrimSpace(id) == "" {
+		violations.add("shareId", errors.RuleRequired, errors.MsgFieldRequired)
+	}
+	return violations.err()
+}
+
+// ValidateInvitation 校验路径中的资源 ID 和邀请 ID
+func (v *ShareValidator) ValidateInvitation(ctx context.Context, resourceID, id string) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, resourceID)
+	if strings.TrimSpace(id) == "" {
+		violations.add("invitationId", errors.RuleRequired, errors.MsgFieldRequired)
+	}
+	return violations.err()
+}
+
+// ValidateAcceptInvitation 校验接受邀请的请求
+func (v *ShareValidator) ValidateAcceptInvitation(ctx context.Context, req *models.AcceptInvitationRequest) error {
+	violations := newViolations(ctx)
+	req.Token = strings.TrimSpace(req.Token)
+	switch {
+	case req.Token == "":
+		violations.add("token", errors.RuleRequired, errors.MsgFieldRequired)
+	case len(req.Token) > maxInvitationTokenLength:
+		violations.add("token", errors.RuleMaxLength, errors.MsgFieldTooLong, maxInvitationTokenLength)
+	}
+	return violations.err()
+}
+
+// invitationEmail 校验邀请的邮箱，返回去掉首尾空白并转为小写的地址
+func invitationEmail(violations *violations, email string) string {
+	const field = "email"
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	switch {
+	case email == "":
+		violations.add(field, errors.RuleRequired, errors.MsgFieldRequired)
+	case len(email) > maxEmailLength:
+		violations.add(field, errors.RuleMaxLength, errors.MsgFieldTooLong, maxEmailLength)
+	default:
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+			violations.add(field, errors.RuleEmail, errors.MsgFieldEmail)
+		}
+	}
+	return email
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/rank"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// SubtaskService 定义了子任务服务的接口，所有方法返回的错误都是 *errors.Error。
+// 修改子任务的方法都返回修改后的整个清单和所属的待办事项。
+type SubtaskService interface {
+	// List 获取待办事项的子任务清单
+	List(ctx context.Context, userID, todoID string) (*models.SubtaskListResponse, error)
+
+	// Create 给待办事项添加子任务，排在清单的最后
+	Create(ctx context.Context, userID, todoID string, req models.CreateSubtaskRequest) (*models.SubtaskListResponse, error)
+
+	// Update 部分更新子任务，只修改请求中出现的字段
+	Update(ctx context.Context, userID, todoID, id string, req models.UpdateSubtaskRequest) (*models.SubtaskListResponse, error)
+
+	// Toggle 切换子任务的完成状态
+	Toggle(ctx context.Context, userID, todoID, id string) (*models.SubtaskListResponse, error)
+
+	// Move 把子任务移动到同一清单中另一个子任务之前或之后
+	Move(ctx context.Context, userID, todoID, id string, req models.MoveSubtaskRequest) (*models.SubtaskListResponse, error)
+
+	// Delete 删除子任务
+	Delete(ctx context.Context, userID, todoID, id string) (*models.SubtaskListResponse, error)
+}
+
+// subtaskService 复用待办事项服务完成待办事项的逻辑，
+// 自动完成重复系列的实例时同样会创建下一个实例
+type subtaskService struct {
+	*todoService
+	validator *SubtaskValidator
+}
+
+// NewSubtaskService 创建一个新的子任务服务，todos 用于读取和完成子任务所属的待办事项。
+// shares 为 nil 时只有所有者能访问自己的子任务
+func NewSubtaskService(todos repository.TodoRepository, subtasks repository.SubtaskRepository, shares repository.ShareRepository, validator *SubtaskValidator) SubtaskService {
+	todoService := newTodoService(todos, validator.todo)
+	todoService.subtasks = subtasks
+	todoService.shares = shares
+	return &subtaskService{
+		todoService: todoService,
+		validator:   validator,
+	}
+}
+
+// List 获取待办事项的子任务清单
+func (s *subtaskService) List(ctx context.Context, userID, todoID string) (*models.SubtaskListResponse, error) {
+	if err := validateID(ctx, todoID); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.repo.Get(ctx, ownerID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	subtasks, err := s.subtasks.ListSubtasks(ctx, ownerID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.checklistResponse(ctx, ownerID, todo, subtasks, nil)
+}
+
+// Create 给待办事项添加子任务，排在清单的最后。一个待办事项最多 maxSubtasksPerTodo 个子任务
+func (s *subtaskService) Create(ctx context.Context, userID, todoID string, req models.CreateSubtaskRequest) (*models.SubtaskListResponse, error) {
+	if err := s.validator.ValidateCreate(ctx, todoID, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.repo.Get(ctx, ownerID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	subtasks, err := s.subtasks.ListSubtasks(ctx, ownerID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if len(subtasks) >= maxSubtasksPerTodo {
+		violations := newViolations(ctx)
+		violations.add("subtasks", errors.RuleMaxItems, errors.MsgFieldTooMany, maxSubtasksPerTodo)
+		return nil, violations.err()
+	}
+
+	var last string
+	if n := len(subtasks); n > 0 {
+		last = subtasks[n-1].Position
+	}
+	position, err := rank.Between(last, "")
+	if err != nil {
+		return nil, errors.New(errors.ErrInternal, fmt.Errorf("生成位置失败: %w", err))
+	}
+
+	subtask := &models.Subtask{
+		TodoID:    todoID,
+		UserID:    ownerID,
+		Title:     req.Title,
+		Completed: req.Completed,
+		Position:  position,
+	}
+	if err := s.subtasks.CreateSubtask(ctx, ownerID, subtask); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.afterChange(ctx, ownerID, todo)
+}
+
+// Update 部分更新子任务，只修改请求中出现的字段
+func (s *subtaskService) Update(ctx context.Context, userID, todoID, id string, req models.UpdateSubtaskRequest) (*models.SubtaskListResponse, error) {
+	if err := s.validator.ValidateUpdate(ctx, todoID, id, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.modify(ctx, ownerID, todoID, id, func(subtask *models.Subtask) {
+		if req.Title != nil {
+			subtask.Title = *req.Title
+		}
+		if req.Completed != nil {
+			subtask.Completed = *req.Completed
+		}
+	})
+}
+
+// Toggle 切换子任务的完成状态
+func (s *subtaskService) Toggle(ctx context.Context, userID, todoID, id string) (*models.SubtaskListResponse, error) {
+	if err := s.validator.ValidateSubtask(ctx, todoID, id); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.modify(ctx, ownerID, todoID, id, func(subtask *models.Subtask) {
+		subtask.Completed = !subtask.Completed
+	})
+}
+
+// Move 把子任务移动到同一清单中另一个子任务之前或之后，新位置取两个相邻子任务的位置之间
+func (s *subtaskService) Move(ctx context.Context, userID, todoID, id string, req models.MoveSubtaskRequest) (*models.SubtaskListResponse, error) {
+	if err := s.validator.ValidateMove(ctx, todoID, id, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.repo.Get(ctx, ownerID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	subtasks, err := s.subtasks.ListSubtasks(ctx, ownerID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	find := func(id string) (*models.Subtask, error) {
+		i := slices.IndexFunc(subtasks, func(st models.Subtask) bool { return st.ID == id })
+		if i < 0 {
+			return nil, wrapRepositoryError(repository.ErrSubtaskNotFound)
+		}
+		return &subtasks[i], nil
+	}
+
+	subtask, err := find(id)
+	if err != nil {
+		return nil, err
+	}
+	siblingID, after := req.After, true
+	if req.Before != "" {
+		siblingID, after = req.Before, false
+	}
+	sibling, err := find(siblingID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 除去被移动的子任务，其余子任务已经按位置排好序
+	others := slices.DeleteFunc(slices.Clone(subtasks), func(st models.Subtask) bool { return st.ID == id })
+
+	var left, right string
+	if after {
+		// 并发添加可能产生相同的位置，跳过它们保证新位置严格位于两者之间
+		left = sibling.Position
+		for _, st := range others {
+			if st.Position > left {
+				right = st.Position
+				break
+			}
+		}
+	} else {
+		right = sibling.Position
+		for _, st := range slices.Backward(others) {
+			if st.Position < right {
+				left = st.Position
+				break
+			}
+		}
+	}
+	position, err := rank.Between(left, right)
+	if err != nil {
+		return nil, errors.New(errors.ErrInternal, fmt.Errorf("生成位置失败: %w", err))
+	}
+
+	subtask.Position = position
+	if err := s.subtasks.UpdateSubtask(ctx, ownerID, subtask); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	models.SortSubtasks(subtasks)
+	return s.checklistResponse(ctx, ownerID, todo, subtasks, nil)
+}
+
+// Delete 删除子任务，剩余的子任务全部完成时同样会自动完成待办事项
+func (s *subtaskService) Delete(ctx context.Context, userID, todoID, id string) (*models.SubtaskListResponse, error) {
+	if err := s.validator.ValidateSubtask(ctx, todoID, id); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, todoID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.repo.Get(ctx, ownerID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if err := s.subtasks.DeleteSubtask(ctx, ownerID, todoID, id); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.afterChange(ctx, ownerID, todo)
+}
+
+// modify 读取子任务，由 change 修改后保存
+func (s *subtaskService) modify(ctx context.Context, userID, todoID, id string, change func(subtask *models.Subtask)) (*models.SubtaskListResponse, error) {
+	todo, err := s.repo.Get(ctx, userID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	subtasks, err := s.subtasks.ListSubtasks(ctx, userID, todoID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	i := slices.IndexFunc(subtasks, func(st models.Subtask) bool { return st.ID == id })
+	if i < 0 {
+		return nil, wrapRepositoryError(repository.ErrSubtaskNotFound)
+	}
+
+	change(&subtasks[i])
+	if err := s.subtasks.UpdateSubtask(ctx, userID, &subtasks[i]); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.afterChange(ctx, userID, todo)
+}
+
+// afterChange 在子任务被添加、修改或删除之后调用。待办事项开启了自动完成、尚未完成，
+// 并且子任务全部完成时，按与 Update 相同的方式完成待办事项。
+// 取消完成子任务不会把已完成的待办事项改回未完成
+func (s *subtaskService) afterChange(ctx context.Context, userID string, todo *models.Todo) (*models.SubtaskListResponse, error) {
+	subtasks, err := s.subtasks.ListSubtasks(ctx, userID, todo.ID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	var next *models.Todo
+	if todo.AutoComplete && !todo.Completed && models.NewSubtaskProgress(subtasks).Done() {
+		todo.Completed = true
+		if next, err = s.completeOccurrence(ctx, userID, todo); err != nil {
+			return nil, err
+		}
+		if err := s.repo.Update(ctx, userID, todo); err != nil {
+			return nil, wrapRepositoryError(err)
+		}
+	}
+	return s.checklistResponse(ctx, userID, todo, subtasks, next)
+}
+
+// checklistResponse 生成子任务清单的响应，next 不为 nil 时附带自动创建的下一个实例
+func (s *subtaskService) checklistResponse(ctx context.Context, userID string, todo *models.Todo, subtasks []models.Subtask, next *models.Todo) (*models.SubtaskListResponse, error) {
+	response, err := s.occurrenceResponse(ctx, userID, todo, next)
+	if err != nil {
+		return nil, err
+	}
+	return &models.SubtaskListResponse{
+		Items: models.ToSubtaskResponseList(subtasks),
+		Todo:  *response,
+	}, nil
+}
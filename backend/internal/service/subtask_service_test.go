@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// newTestSubtaskService 返回使用内存存储的待办事项服务和子任务服务
+func newTestSubtaskService(repo *repository.InMemoryTodoRepository) (TodoService, SubtaskService) {
+	validator := NewTodoValidator(config.TodoValidationConfig{})
+	return NewTodoService(repo, validator), NewSubtaskService(repo, repo, repo, NewSubtaskValidator(validator))
+}
+
+func TestSubtaskServiceAutoComplete(t *testing.T) {
+	tests := []struct {
+		name         string
+		autoComplete bool
+		wantDone     bool
+	}{
+		{"auto complete", true, true},
+		{"manual", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repository.NewInMemoryTodoRepository()
+			todos, subtasks := newTestSubtaskService(repo)
+			ctx := context.Background()
+
+			todo, err := todos.Create(ctx, "user-1", models.CreateTodoRequest{Title: "搬家", AutoComplete: tt.autoComplete})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			var ids []string
+			for _, title := range []string{"打包", "退租"} {
+				list, err := subtasks.Create(ctx, "user-1", todo.ID, models.CreateSubtaskRequest{Title: title})
+				if err != nil {
+					t.Fatalf("Create(%q) error = %v", title, err)
+				}
+				ids = append(ids, list.Items[len(list.Items)-1].ID)
+			}
+
+			// 完成第一个子任务时还有未完成的子任务，待办事项保持未完成
+			list, err := subtasks.Toggle(ctx, "user-1", todo.ID, ids[0])
+			if err != nil {
+				t.Fatalf("Toggle() error = %v", err)
+			}
+			if list.Todo.Completed || list.Todo.Progress != (models.SubtaskProgress{Completed: 1, Total: 2}) {
+				t.Errorf("after first subtask todo = completed %v, progress %+v, want open 1/2", list.Todo.Completed, list.Todo.Progress)
+			}
+
+			// 完成最后一个子任务
+			list, err = subtasks.Toggle(ctx, "user-1", todo.ID, ids[1])
+			if err != nil {
+				t.Fatalf("Toggle() error = %v", err)
+			}
+			if list.Todo.Completed != tt.wantDone || list.Todo.Progress != (models.SubtaskProgress{Completed: 2, Total: 2}) {
+				t.Errorf("after last subtask todo = completed %v, progress %+v, want completed %v 2/2", list.Todo.Completed, list.Todo.Progress, tt.wantDone)
+			}
+			stored, err := repo.Get(ctx, "user-1", todo.ID)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if stored.Completed != tt.wantDone {
+				t.Errorf("stored completed = %v, want %v", stored.Completed, tt.wantDone)
+			}
+
+			// 取消完成子任务不会把待办事项改回未完成
+			list, err = subtasks.Toggle(ctx, "user-1", todo.ID, ids[1])
+			if err != nil {
+				t.Fatalf("Toggle() error = %v", err)
+			}
+			if list.Todo.Completed != tt.wantDone {
+				t.Errorf("after reopening a subtask todo completed = %v, want %v", list.Todo.Completed, tt.wantDone)
+			}
+		})
+	}
+}
+
+func TestSubtaskServiceAutoCompleteDeletingLastOpen(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	todos, subtasks := newTestSubtaskService(repo)
+	ctx := context.Background()
+
+	todo, err := todos.Create(ctx, "user-1", models.CreateTodoRequest{Title: "搬家", AutoComplete: true})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := subtasks.Create(ctx, "user-1", todo.ID, models.CreateSubtaskRequest{Title: "打包", Completed: true}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	list, err := subtasks.Create(ctx, "user-1", todo.ID, models.CreateSubtaskRequest{Title: "退租"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// 删除唯一未完成的子任务后其余子任务都已完成
+	list, err = subtasks.Delete(ctx, "user-1", todo.ID, list.Items[1].ID)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if !list.Todo.Completed || list.Todo.Progress != (models.SubtaskProgress{Completed: 1, Total: 1}) {
+		t.Errorf("todo = completed %v, progress %+v, want completed 1/1", list.Todo.Completed, list.Todo.Progress)
+	}
+}
+
+func TestSubtaskServiceAutoCompleteRecurring(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	todos, subtasks := newTestSubtaskService(repo)
+	ctx := context.Background()
+
+	due := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	todo, err := todos.Create(ctx, "user-1", models.CreateTodoRequest{
+		Title:        "每周清单",
+		AutoComplete: true,
+		DueAt:        models.NullableTime{Set: true, Time: &due},
+		Recurrence:   &models.RecurrenceRequest{Rule: "FREQ=WEEKLY", Timezone: "UTC"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	list, err := subtasks.Create(ctx, "user-1", todo.ID, models.CreateSubtaskRequest{Title: "检查"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// 自动完成重复系列的实例时与手动完成一样创建下一个实例
+	list, err = subtasks.Toggle(ctx, "user-1", todo.ID, list.Items[0].ID)
+	if err != nil {
+		t.Fatalf("Toggle() error = %v", err)
+	}
+	if !list.Todo.Completed || list.Todo.NextOccurrence == nil {
+		t.Fatalf("todo = completed %v, next %v, want completed with next occurrence", list.Todo.Completed, list.Todo.NextOccurrence)
+	}
+	next, err := repo.Get(ctx, "user-1", list.Todo.NextOccurrence.ID)
+	if err != nil {
+		t.Fatalf("Get(next) error = %v", err)
+	}
+	if want := due.AddDate(0, 0, 7); next.Completed || next.DueAt == nil || !next.DueAt.Equal(want) {
+		t.Errorf("next occurrence = completed %v, due %v, want open, due %v", next.Completed, next.DueAt, want)
+	}
+}
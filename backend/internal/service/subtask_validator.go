@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+)
+
+// maxSubtasksPerTodo 一个待办事项最多带有的子任务数
+const maxSubtasksPerTodo = 100
+
+// SubtaskValidator 校验并规范化子任务请求，标题的规则与待办事项相同
+type SubtaskValidator struct {
+	todo *TodoValidator
+}
+
+// NewSubtaskValidator 创建子任务校验器，标题规则来自 todo
+func NewSubtaskValidator(todo *TodoValidator) *SubtaskValidator {
+	return &SubtaskValidator{todo: todo}
+}
+
+// ValidateCreate 校验添加子任务的请求，标题的规则与待办事项相同
+func (v *SubtaskValidator) ValidateCreate(ctx context.Context, todoID string, req *models.CreateSubtaskRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, todoID)
+	req.Title = v.todo.title(violations, req.Title)
+	return violations.err()
+}
+
+// ValidateUpdate 校验更新子任务的请求，只校验请求中出现的字段
+func (v *SubtaskValidator) ValidateUpdate(ctx context.Context, todoID, id string, req *models.UpdateSubtaskRequest) error {
+	violations := newViolations(ctx)
+	validateSubtaskIDInto(violations, todoID, id)
+	if req.Title != nil {
+		title := v.todo.title(violations, *req.Title)
+		req.Title = &title
+	}
+	return violations.err()
+}
+
+// ValidateMove 校验移动子任务的请求，before 和 after 必须且只能提供一个，且不能是子任务自身
+func (v *SubtaskValidator) ValidateMove(ctx context.Context, todoID, id string, req *models.MoveSubtaskRequest) error {
+	violations := newViolations(ctx)
+	validateSubtaskIDInto(violations, todoID, id)
+
+	req.Before = strings.TrimSpace(req.Before)
+	req.After = strings.TrimSpace(req.After)
+	switch {
+	case req.Before == "" && req.After == "":
+		violations.add("before", errors.RuleRequired, errors.MsgMoveTarget)
+	case req.Before != "" && req.After != "":
+		violations.add("after", errors.RuleMoveTarget, errors.MsgMoveTarget)
+	case req.Before == id:
+		violations.add("before", errors.RuleMoveTarget, errors.MsgMoveSubtaskSelf)
+	case req.After == id:
+		violations.add("after", errors.RuleMoveTarget, errors.MsgMoveSubtaskSelf)
+	}
+	return violations.err()
+}
+
+// ValidateSubtask 校验路径中的待办事项 ID 和子任务 ID
+func (v *SubtaskValidator) ValidateSubtask(ctx context.Context, todoID, id string) error {
+	violations := newViolations(ctx)
+	validateSubtaskIDInto(violations, todoID, id)
+	return violations.err()
+}
+
+// validateSubtaskIDInto 校验路径中的待办事项 ID 和子任务 ID 不为空
+func validateSubtaskIDInto(violations *violations, todoID, id string) {
+	validateIDInto(violations, todoID)
+	if strings.TrimSpace(id) == "" {
+		violations.add("subtaskId", errors.RuleRequired, errors.MsgFieldRequired)
+	}
+}
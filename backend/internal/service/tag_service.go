@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// TagService 定义了标签服务的接口，所有方法返回的错误都是 *errors.Error
+type TagService interface {
+	// List 获取指定用户的所有标签，按名称排序
+	List(ctx context.Context, userID string) (*models.TagListResponse, error)
+
+	// Get 获取指定用户的单个标签
+	Get(ctx context.Context, userID, id string) (*models.TagResponse, error)
+
+	// Create 创建标签，同一用户的标签名称不区分大小写唯一
+	Create(ctx context.Context, userID string, req models.CreateTagRequest) (*models.TagResponse, error)
+
+	// Update 部分更新标签，只修改请求中出现的字段
+	Update(ctx context.Context, userID, id string, req models.UpdateTagRequest) (*models.TagResponse, error)
+
+	// Delete 删除标签，同时从所有待办事项上去掉该标签
+	Delete(ctx context.Context, userID, id string) error
+
+	// SetTodoTags 把待办事项的标签替换为请求中的标签，返回待办事项现在的标签
+	SetTodoTags(ctx context.Context, userID, todoID string, req models.SetTodoTagsRequest) (*models.TagListResponse, error)
+
+	// AddTodoTag 给待办事项添加一个标签，返回待办事项现在的标签
+	AddTodoTag(ctx context.Context, userID, todoID, tagID string) (*models.TagListResponse, error)
+
+	// RemoveTodoTag 去掉待办事项的一个标签，返回待办事项现在的标签
+	RemoveTodoTag(ctx context.Context, userID, todoID, tagID string) (*models.TagListResponse, error)
+}
+
+type tagService struct {
+	repo      repository.TagRepository
+	validator *TagValidator
+}
+
+// NewTagService 创建一个新的标签服务
+func NewTagService(repo repository.TagRepository, validator *TagValidator) TagService {
+	return &tagService{
+		repo:      repo,
+		validator: validator,
+	}
+}
+
+// List 获取指定用户的所有标签，按名称排序
+func (s *tagService) List(ctx context.Context, userID string) (*models.TagListResponse, error) {
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.repo.ListTags(ctx, ownerID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return &models.TagListResponse{Items: models.ToTagResponseList(tags)}, nil
+}
+
+// Get 获取指定用户的单个标签
+func (s *tagService) Get(ctx context.Context, userID, id string) (*models.TagResponse, error) {
+	if err := validateID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.repo.GetTag(ctx, ownerID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := tag.ToResponse()
+	return &response, nil
+}
+
+// Create 创建标签，未指定颜色时使用默认颜色
+func (s *tagService) Create(ctx context.Context, userID string, req models.CreateTagRequest) (*models.TagResponse, error) {
+	if err := s.validator.ValidateCreate(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := &models.Tag{
+		UserID: ownerID,
+		Name:   req.Name,
+		Color:  req.Color,
+	}
+	if err := s.repo.CreateTag(ctx, ownerID, tag); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := tag.ToResponse()
+	return &response, nil
+}
+
+// Update 部分更新标签，只修改请求中出现的字段
+func (s *tagService) Update(ctx context.Context, userID, id string, req models.UpdateTagRequest) (*models.TagResponse, error) {
+	if err := s.validator.ValidateUpdate(ctx, id, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.repo.GetTag(ctx, ownerID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if req.Name != nil {
+		tag.Name = *req.Name
+	}
+	if req.Color != nil {
+		tag.Color = *req.Color
+	}
+
+	if err := s.repo.UpdateTag(ctx, ownerID, tag); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := tag.ToResponse()
+	return &response, nil
+}
+
+// Delete 删除标签，同时从所有待办事项上去掉该标签
+func (s *tagService) Delete(ctx context.Context, userID, id string) error {
+	if err := validateID(ctx, id); err != nil {
+		return err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteTag(ctx, ownerID, id); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// SetTodoTags 把待办事项的标签替换为请求中的标签。
+// 仓库层不检查标签的归属，先确认所有标签都属于该用户，任何一个不存在时不做修改。
+func (s *tagService) SetTodoTags(ctx context.Context, userID, todoID string, req models.SetTodoTagsRequest) (*models.TagListResponse, error) {
+	if err := s.validator.ValidateSetTodoTags(ctx, todoID, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.TagIDs) > 0 {
+		owned, err := s.repo.ListTags(ctx, ownerID)
+		if err != nil {
+			return nil, wrapRepositoryError(err)
+		}
+		for _, tagID := range req.TagIDs {
+			if !slices.ContainsFunc(owned, func(tag models.Tag) bool { return tag.ID == tagID }) {
+				return nil, wrapRepositoryError(repository.ErrTagNotFound)
+			}
+		}
+	}
+
+	if err := s.repo.SetTodoTags(ctx, ownerID, todoID, req.TagIDs); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.todoTags(ctx, ownerID, todoID)
+}
+
+// AddTodoTag 给待办事项添加一个标签，已经带有该标签时不做修改。
+// 添加后标签数超过上限时返回校验错误。
+func (s *tagService) AddTodoTag(ctx context.Context, userID, todoID, tagID string) (*models.TagListResponse, error) {
+	if err := s.validator.ValidateTodoTag(ctx, todoID, tagID); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetTag(ctx, ownerID, tagID); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	current, err := s.repo.TodoTags(ctx, ownerID, []string{todoID})
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	tags := current[todoID]
+	if len(tags) >= maxTagsPerTodo && !slices.ContainsFunc(tags, func(tag models.Tag) bool { return tag.ID == tagID }) {
+		violations := newViolations(ctx)
+		violations.add("tagId", errors.RuleMaxItems, errors.MsgFieldTooMany, maxTagsPerTodo)
+		return nil, violations.err()
+	}
+
+	if err := s.repo.AddTodoTag(ctx, ownerID, todoID, tagID); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.todoTags(ctx, ownerID, todoID)
+}
+
+// RemoveTodoTag 去掉待办事项的一个标签，没有该标签时不做修改
+func (s *tagService) RemoveTodoTag(ctx context.Context, userID, todoID, tagID string) (*models.TagListResponse, error) {
+	if err := s.validator.ValidateTodoTag(ctx, todoID, tagID); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RemoveTodoTag(ctx, ownerID, todoID, tagID); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return s.todoTags(ctx, ownerID, todoID)
+}
+
+// todoTags 返回待办事项现在的标签
+func (s *tagService) todoTags(ctx context.Context, userID, todoID string) (*models.TagListResponse, error) {
+	tags, err := s.repo.TodoTags(ctx, userID, []string{todoID})
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	return &models.TagListResponse{Items: models.ToTagResponseList(tags[todoID])}, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// tagNames 返回标签列表中的名称
+func tagNames(list *models.TagListResponse) []string {
+	names := []string{}
+	for _, tag := range list.Items {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func TestTagServiceSetTodoTagsReplaces(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	tags := NewTagService(repo, NewTagValidator())
+	ctx := context.Background()
+
+	todo := &models.Todo{Title: "周报"}
+	if err := repo.Create(ctx, "user-1", todo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	ids := make(map[string]string)
+	for _, name := range []string{"工作", "紧急", "家庭"} {
+		tag, err := tags.Create(ctx, "user-1", models.CreateTagRequest{Name: name})
+		if err != nil {
+			t.Fatalf("Create(%q) error = %v", name, err)
+		}
+		ids[name] = tag.ID
+	}
+	foreign, err := tags.Create(ctx, "user-2", models.CreateTagRequest{Name: "别人的"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		tagIDs   []string
+		wantErr  errors.ErrorCode
+		wantTags []string
+	}{
+		{"assign", []string{ids["工作"], ids["紧急"]}, 0, []string{"工作", "紧急"}},
+		// 再次设置时替换原有的标签，而不是追加
+		{"replace", []string{ids["家庭"]}, 0, []string{"家庭"}},
+		{"duplicates are ignored", []string{ids["工作"], ids["工作"]}, 0, []string{"工作"}},
+		// 任何一个标签不属于该用户时不做修改
+		{"foreign tag", []string{ids["紧急"], foreign.ID}, errors.ErrTagNotFound, []string{"工作"}},
+		{"clear", []string{}, 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tags.SetTodoTags(ctx, "user-1", todo.ID, models.SetTodoTagsRequest{TagIDs: tt.tagIDs})
+			if tt.wantErr != 0 {
+				if e, ok := errors.As(err); !ok || e.Code != tt.wantErr {
+					t.Fatalf("SetTodoTags() error = %v, want code %d", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("SetTodoTags() error = %v", err)
+			} else if fmt.Sprint(tagNames(got)) != fmt.Sprint(tt.wantTags) {
+				t.Errorf("SetTodoTags() = %v, want %v", tagNames(got), tt.wantTags)
+			}
+
+			stored, err := repo.TodoTags(ctx, "user-1", []string{todo.ID})
+			if err != nil {
+				t.Fatalf("TodoTags() error = %v", err)
+			}
+			names := []string{}
+			for _, tag := range stored[todo.ID] {
+				names = append(names, tag.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tt.wantTags) {
+				t.Errorf("stored tags = %v, want %v", names, tt.wantTags)
+			}
+		})
+	}
+
+	// 其他用户不能给这个待办事项设置标签
+	_, err = tags.SetTodoTags(ctx, "user-2", todo.ID, models.SetTodoTagsRequest{TagIDs: []string{foreign.ID}})
+	if e, ok := errors.As(err); !ok || e.Code != errors.ErrTodoNotFound {
+		t.Errorf("SetTodoTags(other user) error = %v, want code %d", err, errors.ErrTodoNotFound)
+	}
+}
+
+func TestTagServiceAddAndRemoveTodoTag(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	tags := NewTagService(repo, NewTagValidator())
+	ctx := context.Background()
+
+	todo := &models.Todo{Title: "周报"}
+	if err := repo.Create(ctx, "user-1", todo); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	work, err := tags.Create(ctx, "user-1", models.CreateTagRequest{Name: "工作"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// 重复添加同一个标签不会产生重复的关联
+	for i := 0; i < 2; i++ {
+		got, err := tags.AddTodoTag(ctx, "user-1", todo.ID, work.ID)
+		if err != nil {
+			t.Fatalf("AddTodoTag() error = %v", err)
+		}
+		if fmt.Sprint(tagNames(got)) != "[工作]" {
+			t.Errorf("AddTodoTag() = %v, want [工作]", tagNames(got))
+		}
+	}
+
+	got, err := tags.RemoveTodoTag(ctx, "user-1", todo.ID, work.ID)
+	if err != nil {
+		t.Fatalf("RemoveTodoTag() error = %v", err)
+	}
+	if len(got.Items) != 0 {
+		t.Errorf("RemoveTodoTag() = %v, want none", tagNames(got))
+	}
+
+	// 删除标签时同时去掉它与待办事项的关联
+	if _, err := tags.AddTodoTag(ctx, "user-1", todo.ID, work.ID); err != nil {
+		t.Fatalf("AddTodoTag() error = %v", err)
+	}
+	if err := tags.Delete(ctx, "user-1", work.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	stored, err := repo.TodoTags(ctx, "user-1", []string{todo.ID})
+	if err != nil {
+		t.Fatalf("TodoTags() error = %v", err)
+	}
+	if len(stored[todo.ID]) != 0 {
+		t.Errorf("tags after deleting the tag = %+v, want none", stored[todo.ID])
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+)
+
+// maxTagNameLength 标签名称的最大长度，maxTagsPerTodo 一个待办事项最多带有的标签数，
+// 也是列表按标签过滤时最多指定的标签数
+const (
+	maxTagNameLength = 50
+	maxTagsPerTodo   = 20
+)
+
+// TagValidator 校验并规范化标签请求
+type TagValidator struct{}
+
+// NewTagValidator 创建标签校验器
+func NewTagValidator() *TagValidator {
+	return &TagValidator{}
+}
+
+// ValidateCreate 校验创建标签的请求，去掉名称首尾空白，颜色为空时使用默认颜色并统一为小写
+func (v *TagValidator) ValidateCreate(ctx context.Context, req *models.CreateTagRequest) error {
+	violations := newViolations(ctx)
+	req.Name = validateNameInto(violations, req.Name, maxTagNameLength)
+	if req.Color == "" {
+		req.Color = models.DefaultTagColor
+	}
+	req.Color = validateColorInto(violations, req.Color)
+	return violations.err()
+}
+
+// ValidateUpdate 校验更新标签的请求，只校验请求中出现的字段
+func (v *TagValidator) ValidateUpdate(ctx context.Context, id string, req *models.UpdateTagRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+	if req.Name != nil {
+		name := validateNameInto(violations, *req.Name, maxTagNameLength)
+		req.Name = &name
+	}
+	if req.Color != nil {
+		color := validateColorInto(violations, *req.Color)
+		req.Color = &color
+	}
+	return violations.err()
+}
+
+// ValidateSetTodoTags 校验替换待办事项标签的请求，去掉重复的标签 ID
+func (v *TagValidator) ValidateSetTodoTags(ctx context.Context, todoID string, req *models.SetTodoTagsRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, todoID)
+
+	tagIDs := make([]string, 0, len(req.TagIDs))
+	for _, id := range req.TagIDs {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			violations.add("tagIds", errors.RuleRequired, errors.MsgFieldRequired)
+			break
+		}
+		if !slices.Contains(tagIDs, id) {
+			tagIDs = append(tagIDs, id)
+		}
+	}
+	if len(tagIDs) > maxTagsPerTodo {
+		violations.add("tagIds", errors.RuleMaxItems, errors.MsgFieldTooMany, maxTagsPerTodo)
+	}
+	req.TagIDs = tagIDs
+	return violations.err()
+}
+
+// ValidateTodoTag 校验路径中的待办事项 ID 和标签 ID
+func (v *TagValidator) ValidateTodoTag(ctx context.Context, todoID, tagID string) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, todoID)
+	if strings.TrimSpace(tagID) == "" {
+		violations.add("tagId", errors.RuleRequired, errors.MsgFieldRequired)
+	}
+	return violations.err()
+}
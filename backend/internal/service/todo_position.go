@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/rank"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// neighborScanLimit 查找相邻待办事项时每页读取的条数
+const neighborScanLimit = 10
+
+// Move 把待办事项移动到另一个待办事项之前或之后。
+// 新位置取两个相邻待办事项的位置之间，只修改被移动的待办事项。
+func (s *todoService) Move(ctx context.Context, userID, id string, req models.MoveTodoRequest) (*models.TodoResponse, error) {
+	if err := s.validator.ValidateMove(ctx, id, &req); err != nil {
+		return nil, err
+	}
+
+	siblingID, dir := req.After, models.SortAsc
+	if req.Before != "" {
+		siblingID, dir = req.Before, models.SortDesc
+	}
+
+	// 位置是所有者的手动排序，被共享的用户只能参照同一所有者的、自己也能看到的待办事项移动
+	ownerID, err := todoOwner(ctx, s.shares, userID, id, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	siblingOwnerID, err := todoOwner(ctx, s.shares, userID, siblingID, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if siblingOwnerID != ownerID {
+		return nil, wrapRepositoryError(repository.ErrTodoNotFound)
+	}
+
+	sibling, err := s.repo.Get(ctx, ownerID, siblingID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	neighbor, err := s.neighborPosition(ctx, ownerID, sibling, dir, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var position string
+	if dir == models.SortAsc {
+		position, err = rank.Between(sibling.Position, neighbor)
+	} else {
+		position, err = rank.Between(neighbor, sibling.Position)
+	}
+	if err != nil {
+		return nil, errors.New(errors.ErrInternal, fmt.Errorf("生成位置失败: %w", err))
+	}
+
+	todo, err := s.repo.Move(ctx, ownerID, id, position)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	return s.occurrenceResponse(ctx, ownerID, todo, nil)
+}
+
+// appendPosition 返回排在指定用户所有待办事项之后的位置
+func (s *todoService) appendPosition(ctx context.Context, userID string) (string, error) {
+	page, err := s.repo.List(ctx, userID, models.TodoListOptions{
+		SortBy:  models.SortByPosition,
+		SortDir: models.SortDesc,
+		Limit:   1,
+	})
+	if err != nil {
+		return "", wrapRepositoryError(err)
+	}
+
+	var last string
+	if len(page.Items) > 0 {
+		last = page.Items[0].Position
+	}
+	position, err := rank.Between(last, "")
+	if err != nil {
+		return "", errors.New(errors.ErrInternal, fmt.Errorf("生成位置失败: %w", err))
+	}
+	return position, nil
+}
+
+// positionAfter 返回紧接在 todo 之后的位置
+func (s *todoService) positionAfter(ctx context.Context, userID string, todo *models.Todo) (string, error) {
+	neighbor, err := s.neighborPosition(ctx, userID, todo, models.SortAsc, todo.ID)
+	if err != nil {
+		return "", err
+	}
+	position, err := rank.Between(todo.Position, neighbor)
+	if err != nil {
+		return "", errors.New(errors.ErrInternal, fmt.Errorf("生成位置失败: %w", err))
+	}
+	return position, nil
+}
+
+// neighborPosition 按位置排序，返回 sibling 之后（dir 为 asc）或之前（dir 为 desc）
+// 第一个位置与它不同的待办事项的位置，跳过被移动的 skipID；没有时返回空字符串，表示列表的一端。
+// 并发创建可能产生相同的位置，跳过它们保证新位置严格位于两者之间。
+func (s *todoService) neighborPosition(ctx context.Context, userID string, sibling *models.Todo, dir models.SortDirection, skipID string) (string, error) {
+	cursor := models.NewCursor(models.SortByPosition, dir, *sibling)
+	for {
+		page, err := s.repo.List(ctx, userID, models.TodoListOptions{
+			SortBy:  models.SortByPosition,
+			SortDir: dir,
+			Limit:   neighborScanLimit,
+			After:   cursor,
+		})
+		if err != nil {
+			return "", wrapRepositoryError(err)
+		}
+
+		for _, todo := range page.Items {
+			if todo.ID != skipID && todo.Position != sibling.Position {
+				return todo.Position, nil
+			}
+		}
+		if page.Next == nil {
+			return "", nil
+		}
+		cursor = page.Next
+	}
+}
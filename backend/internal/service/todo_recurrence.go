@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/recurrence"
+	"github.com/Brower/backend/internal/repository"
+	"github.com/google/uuid"
+)
+
+// seriesHeadScanLimit 查找系列当前实例时最多检查的未完成实例数
+const seriesHeadScanLimit = 50
+
+// occurrenceNamespace 生成重复实例 ID 的 UUID 命名空间
+var occurrenceNamespace = uuid.MustParse("6f1c2a4e-7b0d-4c55-9a3e-2d8f1b6c9e07")
+
+// SetRecurrence 设置或修改待办事项所属重复系列的规则。
+// 待办事项不属于任何系列，或所属系列已经停止时，以它为当前实例开始新的系列。
+// 修改规则后系列从当前实例的截止时间重新开始，COUNT 也重新计数。
+func (s *todoService) SetRecurrence(ctx context.Context, userID, id string, req models.RecurrenceRequest) (*models.TodoResponse, error) {
+	if err := s.validator.ValidateRecurrence(ctx, id, &req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, id, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.repo.Get(ctx, ownerID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	head, err := s.seriesHead(ctx, ownerID, todo)
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		head = todo
+	}
+
+	// 已完成的实例不会再被完成，设置规则也不会产生下一个实例
+	if head.Completed {
+		return nil, errors.New(errors.ErrInvalidTodoStatus, fmt.Errorf("待办事项 %s 已完成，不能设置重复规则", head.ID))
+	}
+	if head.DueAt == nil {
+		return nil, errRecurrenceNeedsDue(ctx)
+	}
+
+	if head.SeriesID == "" {
+		head.SeriesID = uuid.New().String()
+	}
+	head.Recurrence = &models.Recurrence{
+		Rule:     req.Rule,
+		Timezone: req.Timezone,
+		Start:    *head.DueAt,
+	}
+
+	if err := s.repo.Update(ctx, ownerID, head); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	return s.occurrenceResponse(ctx, ownerID, head, nil)
+}
+
+// StopRecurrence 停止待办事项所属的重复系列：去掉当前实例上的规则，已有的实例都保留。
+// 系列已经停止时不做任何修改，返回待办事项本身。
+func (s *todoService) StopRecurrence(ctx context.Context, userID, id string) (*models.TodoResponse, error) {
+	if err := s.validator.ValidateID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, id, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.repo.Get(ctx, ownerID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	head, err := s.seriesHead(ctx, ownerID, todo)
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return s.occurrenceResponse(ctx, ownerID, todo, nil)
+	}
+
+	head.Recurrence = nil
+	if err := s.repo.Update(ctx, ownerID, head); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	return s.occurrenceResponse(ctx, ownerID, head, nil)
+}
+
+// seriesHead 返回待办事项所属系列中带有重复规则的当前实例，
+// 没有系列或系列已经停止时返回 nil。当前实例总是未完成的，按创建时间从新到旧查找。
+func (s *todoService) seriesHead(ctx context.Context, userID string, todo *models.Todo) (*models.Todo, error) {
+	if todo.Recurrence != nil {
+		return todo, nil
+	}
+	if todo.SeriesID == "" {
+		return nil, nil
+	}
+
+	completed := false
+	page, err := s.repo.List(ctx, userID, models.TodoListOptions{
+		Filter:  models.TodoFilter{SeriesID: todo.SeriesID, Completed: &completed},
+		SortBy:  models.SortByCreatedAt,
+		SortDir: models.SortDesc,
+		Limit:   seriesHeadScanLimit,
+	})
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	for i := range page.Items {
+		if page.Items[i].Recurrence != nil {
+			return &page.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// completeOccurrence 在 todo 被标记完成、保存之前调用。todo 是重复系列的当前实例时，
+// 创建下一个实例并把重复规则转移过去，返回创建的实例；系列已经结束时只去掉规则。
+//
+// 下一个实例的 ID 由系列和截止时间决定，保存 todo 失败后重试不会重复创建。
+// 提前完成时下一个实例紧接在当前实例之后；逾期完成时跳过已经过去的实例，只创建一个。
+// 下一个实例沿用当前实例的优先级、项目、备注、标签和子任务，手动排序时排在当前实例之后，
+// 复制的子任务都是未完成的。
+func (s *todoService) completeOccurrence(ctx context.Context, userID string, todo *models.Todo) (*models.Todo, error) {
+	current := todo.Recurrence
+	if current == nil || todo.DueAt == nil {
+		return nil, nil
+	}
+
+	rule, err := recurrence.Parse(current.Rule)
+	if err != nil {
+		return nil, errors.New(errors.ErrInternal, fmt.Errorf("解析重复规则失败: %w", err))
+	}
+	loc, err := time.LoadLocation(current.Timezone)
+	if err != nil {
+		return nil, errors.New(errors.ErrInternal, fmt.Errorf("加载时区 %s 失败: %w", current.Timezone, err))
+	}
+
+	after := *todo.DueAt
+	if now := time.Now(); now.After(after) {
+		after = now
+	}
+
+	todo.Recurrence = nil
+	due, ok := rule.Next(current.Start.In(loc), after)
+	if !ok {
+		return nil, nil
+	}
+
+	position, err := s.positionAfter(ctx, userID, todo)
+	if err != nil {
+		return nil, err
+	}
+
+	next := &models.Todo{
+		ID:           occurrenceID(todo.SeriesID, due),
+		UserID:       userID,
+		Title:        todo.Title,
+		DueAt:        &due,
+		SeriesID:     todo.SeriesID,
+		Recurrence:   &models.Recurrence{Rule: current.Rule, Timezone: current.Timezone, Start: current.Start},
+		Priority:     todo.Priority,
+		Position:     position,
+		ProjectID:    todo.ProjectID,
+		AutoComplete: todo.AutoComplete,
+		Notes:        todo.Notes,
+	}
+	// 提醒时间与截止时间保持相同的间隔
+	if todo.RemindAt != nil {
+		remindAt := due.Add(todo.RemindAt.Sub(*todo.DueAt))
+		next.RemindAt = &remindAt
+	}
+
+	err = s.repo.Create(ctx, userID, next)
+	if errors.Is(err, repository.ErrTodoAlreadyExists) {
+		// 之前的请求已经创建了下一个实例，但没有保存当前实例
+		next, err = s.repo.Get(ctx, userID, next.ID)
+	}
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if err := s.copyTags(ctx, userID, todo.ID, next.ID); err != nil {
+		return nil, err
+	}
+	if err := s.copySubtasks(ctx, userID, todo.ID, next.ID); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// requireSeriesDue 重复系列的当前实例必须保留截止时间，否则无法确定下一个实例
+func requireSeriesDue(ctx context.Context, todo *models.Todo) error {
+	if todo.Recurrence == nil || todo.DueAt != nil {
+		return nil
+	}
+	return errRecurrenceNeedsDue(ctx)
+}
+
+// errRecurrenceNeedsDue 返回缺少截止时间的校验错误
+func errRecurrenceNeedsDue(ctx context.Context) error {
+	violations := newViolations(ctx)
+	violations.add("dueAt", errors.RuleRequired, errors.MsgRecurrenceNeedsDue)
+	return violations.err()
+}
+
+// occurrenceID 返回系列中指定截止时间的实例 ID
+func occurrenceID(seriesID string, due time.Time) string {
+	return uuid.NewSHA1(occurrenceNamespace, []byte(seriesID+"/"+due.UTC().Format(time.RFC3339Nano))).String()
+}
+
+// occurrenceResponse 生成带有标签的待办事项响应，next 不为 nil 时附带自动创建的下一个实例
+func (s *todoService) occurrenceResponse(ctx context.Context, userID string, todo, next *models.Todo) (*models.TodoResponse, error) {
+	if err := s.withDetails(ctx, userID, todo, next); err != nil {
+		return nil, err
+	}
+	response := todo.ToResponse()
+	if next != nil {
+		nextResponse := next.ToResponse()
+		response.NextOccurrence = &nextResponse
+	}
+	return &response, nil
+}
+
+// copyTags 把 from 的标签复制到 to，存储后端不支持标签时不做任何事
+func (s *todoService) copyTags(ctx context.Context, userID, from, to string) error {
+	if s.tags == nil {
+		return nil
+	}
+
+	tags, err := s.tags.TodoTags(ctx, userID, []string{from})
+	if err != nil {
+		return wrapRepositoryError(err)
+	}
+	if len(tags[from]) == 0 {
+		return nil
+	}
+
+	tagIDs := make([]string, len(tags[from]))
+	for i, tag := range tags[from] {
+		tagIDs[i] = tag.ID
+	}
+	if err := s.tags.SetTodoTags(ctx, userID, to, tagIDs); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// copySubtasks 把 from 的子任务复制到 to，复制的子任务都是未完成的。
+// to 已经有子任务时说明之前的请求已经复制过，不再重复复制；存储后端不支持子任务时不做任何事
+func (s *todoService) copySubtasks(ctx context.Context, userID, from, to string) error {
+	if s.subtasks == nil {
+		return nil
+	}
+
+	progress, err := s.subtasks.SubtaskProgress(ctx, userID, []string{to})
+	if err != nil {
+		return wrapRepositoryError(err)
+	}
+	if progress[to].Total > 0 {
+		return nil
+	}
+
+	subtasks, err := s.subtasks.ListSubtasks(ctx, userID, from)
+	if err != nil {
+		return wrapRepositoryError(err)
+	}
+	for _, subtask := range subtasks {
+		copied := &models.Subtask{
+			TodoID:   to,
+			UserID:   userID,
+			Title:    subtask.Title,
+			Position: subtask.Position,
+		}
+		if err := s.subtasks.CreateSubtask(ctx, userID, copied); err != nil {
+			return wrapRepositoryError(err)
+		}
+	}
+	return nil
+}
@@ -1,91 +1,311 @@
 package service
 
 import (
+	"context"
+	"time"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/markdown"
 	"github.com/Brower/backend/internal/models"
 	"github.com/Brower/backend/internal/repository"
-	"time"
+	"github.com/Brower/backend/internal/search"
 	"github.com/google/uuid"
 )
 
-// TodoService 定义了待办事项服务的接口
+// TodoService 定义了待办事项服务的接口，ctx 为请求的上下文，会一直传递到仓库层。
+// 所有方法返回的错误都是 *errors.Error。
 type TodoService interface {
-	// List 获取指定用户的所有待办事项
-	List(userID string) ([]models.TodoResponse, error)
+	// List 按查询参数分页获取指定用户的待办事项
+	List(ctx context.Context, userID string, req models.ListTodosRequest) (*models.TodoListResponse, error)
+
+	// Search 按相关度搜索指定用户的待办事项，结果带有高亮区间
+	Search(ctx context.Context, userID string, req models.SearchTodosRequest) (*models.TodoSearchResponse, error)
 
 	// Get 获取指定用户的单个待办事项
-	Get(userID, id string) (*models.TodoResponse, error)
+	Get(ctx context.Context, userID, id string, req models.GetTodoRequest) (*models.TodoResponse, error)
 
 	// Create 创建一个新的待办事项
-	Create(userID string, req models.CreateTodoRequest) (*models.TodoResponse, error)
+	Create(ctx context.Context, userID string, req models.CreateTodoRequest) (*models.TodoResponse, error)
 
 	// Update 更新待办事项
-	Update(userID, id string, req models.UpdateTodoRequest) (*models.TodoResponse, error)
+	Update(ctx context.Context, userID, id string, req models.UpdateTodoRequest) (*models.TodoResponse, error)
+
+	// Replace 整体替换待办事项
+	Replace(ctx context.Context, userID, id string, req models.ReplaceTodoRequest) (*models.TodoResponse, error)
 
 	// Toggle 切换待办事项的完成状态
-	Toggle(userID, id string) (*models.TodoResponse, error)
+	Toggle(ctx context.Context, userID, id string) (*models.TodoResponse, error)
+
+	// SetRecurrence 设置或修改待办事项所属重复系列的规则
+	SetRecurrence(ctx context.Context, userID, id string, req models.RecurrenceRequest) (*models.TodoResponse, error)
+
+	// StopRecurrence 停止待办事项所属的重复系列，已有的实例保留
+	StopRecurrence(ctx context.Context, userID, id string) (*models.TodoResponse, error)
+
+	// Move 把待办事项移动到另一个待办事项之前或之后
+	Move(ctx context.Context, userID, id string, req models.MoveTodoRequest) (*models.TodoResponse, error)
 
 	// Delete 删除待办事项
-	Delete(userID, id string) error
+	Delete(ctx context.Context, userID, id string) error
 }
 
 type todoService struct {
 	repo repository.TodoRepository
+	// tags 存储后端不支持标签时为 nil，此时响应中的标签总是空数组
+	tags repository.TagRepository
+	// projects 存储后端不支持项目时为 nil，此时待办事项不属于任何项目
+	projects repository.ProjectRepository
+	// subtasks 存储后端不支持子任务时为 nil，此时响应中的进度总是 0/0
+	subtasks repository.SubtaskRepository
+	// comments 存储后端不支持评论时为 nil，此时响应中的评论数总是 0
+	comments repository.CommentRepository
+	// shares 存储后端不支持共享时为 nil，此时只有所有者能访问自己的待办事项
+	shares    repository.ShareRepository
+	validator *TodoValidator
+}
+
+// NewTodoService 创建一个新的待办事项服务，存储后端支持标签时在响应中附带标签，
+// 支持项目时每个待办事项都放入一个项目，未指定时放入收件箱，
+// 支持子任务时在响应中附带子任务的完成进度，支持评论时附带评论数。
+// 支持共享时被共享的用户按角色访问待办事项：viewer 只能查看，editor 可以修改，
+// owner 还可以删除和把待办事项移到其他项目；列表和搜索只包含自己的待办事项，
+// 按共享给自己的项目过滤时列出该项目中的待办事项
+func NewTodoService(repo repository.TodoRepository, validator *TodoValidator) TodoService {
+	return newTodoService(repo, validator)
 }
 
-// NewTodoService 创建一个新的待办事项服务
-func NewTodoService(repo repository.TodoRepository) TodoService {
+// newTodoService 创建待办事项服务的实现，子任务服务复用其中完成待办事项的逻辑
+func newTodoService(repo repository.TodoRepository, validator *TodoValidator) *todoService {
+	tags, _ := repo.(repository.TagRepository)
+	projects, _ := repo.(repository.ProjectRepository)
+	subtasks, _ := repo.(repository.SubtaskRepository)
+	comments, _ := repo.(repository.CommentRepository)
+	shares, _ := repo.(repository.ShareRepository)
 	return &todoService{
-		repo: repo,
+		repo:      repo,
+		tags:      tags,
+		projects:  projects,
+		subtasks:  subtasks,
+		comments:  comments,
+		shares:    shares,
+		validator: validator,
+	}
+}
+
+// List 按查询参数分页获取指定用户的待办事项
+func (s *todoService) List(ctx context.Context, userID string, req models.ListTodosRequest) (*models.TodoListResponse, error) {
+	opts, err := s.validator.ParseList(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按项目过滤时，共享给自己的项目以所有者的身份列出；
+	// 看不到的项目与之前一样按自己或工作区的待办事项过滤，结果为空
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Filter.ProjectID != "" && s.shares != nil {
+		access, err := authorize(ctx, s.shares, userID, models.ShareProject, opts.Filter.ProjectID, models.ShareRoleViewer)
+		switch {
+		case err == nil:
+			ownerID = access.OwnerID
+		case !errors.IsNotFound(err):
+			return nil, err
+		}
 	}
+
+	page, err := s.repo.List(ctx, ownerID, opts)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	todos := make([]*models.Todo, len(page.Items))
+	for i := range page.Items {
+		todos[i] = &page.Items[i]
+	}
+	if err := s.withDetails(ctx, ownerID, todos...); err != nil {
+		return nil, err
+	}
+
+	response := &models.TodoListResponse{
+		Items: models.ToResponseList(page.Items),
+		Total: page.Total,
+	}
+	if req.Render == models.RenderHTML {
+		for i := range response.Items {
+			renderNotes(&response.Items[i])
+		}
+	}
+	if page.Next != nil {
+		response.NextCursor = page.Next.Encode()
+	}
+	return response, nil
 }
 
-// List 获取指定用户的所有待办事项
-func (s *todoService) List(userID string) ([]models.TodoResponse, error) {
-	todos, err := s.repo.List(userID)
+// Search 按相关度搜索指定用户的待办事项。
+// 各存储后端只负责打分和分页，高亮区间统一在这里计算。
+func (s *todoService) Search(ctx context.Context, userID string, req models.SearchTodosRequest) (*models.TodoSearchResponse, error) {
+	opts, err := s.validator.ParseSearch(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	return models.ToResponseList(todos), nil
+
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.repo.Search(ctx, ownerID, opts)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	todos := make([]*models.Todo, len(page.Hits))
+	for i := range page.Hits {
+		todos[i] = &page.Hits[i].Todo
+	}
+	if err := s.withDetails(ctx, ownerID, todos...); err != nil {
+		return nil, err
+	}
+
+	response := &models.TodoSearchResponse{
+		Items: make([]models.TodoSearchResult, len(page.Hits)),
+		Total: page.Total,
+	}
+	for i, hit := range page.Hits {
+		response.Items[i] = models.TodoSearchResult{
+			TodoResponse: hit.Todo.ToResponse(),
+			Score:        float32(hit.Score),
+			Highlights:   search.HighlightTodo(opts.Query, &hit.Todo),
+		}
+		if req.Render == models.RenderHTML {
+			renderNotes(&response.Items[i].TodoResponse)
+		}
+	}
+	if page.Next != nil {
+		response.NextCursor = page.Next.Encode()
+	}
+	return response, nil
 }
 
-// Get 获取指定用户的单个待办事项
-func (s *todoService) Get(userID, id string) (*models.TodoResponse, error) {
-	todo, err := s.repo.Get(userID, id)
+// Get 获取指定用户的单个待办事项，render=html 时同时返回渲染后的备注
+func (s *todoService) Get(ctx context.Context, userID, id string, req models.GetTodoRequest) (*models.TodoResponse, error) {
+	if err := s.validator.ValidateGet(ctx, id, req); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, id, models.ShareRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := s.repo.Get(ctx, ownerID, id)
 	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if err := s.withDetails(ctx, ownerID, todo); err != nil {
 		return nil, err
 	}
 	response := todo.ToResponse()
+	if req.Render == models.RenderHTML {
+		renderNotes(&response)
+	}
 	return &response, nil
 }
 
-// Create 创建一个新的待办事项
-func (s *todoService) Create(userID string, req models.CreateTodoRequest) (*models.TodoResponse, error) {
+// Create 创建一个新的待办事项，排在手动排序的最后
+func (s *todoService) Create(ctx context.Context, userID string, req models.CreateTodoRequest) (*models.TodoResponse, error) {
+	if err := s.validator.ValidateCreate(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	// 在共享给自己的项目中创建时需要 editor 角色，待办事项属于项目的所有者；
+	// 不指定项目时属于自己，或者属于请求选择的工作区
+	ownerID, err := scopeOwner(ctx, userID, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if req.ProjectID != "" {
+		access, err := authorize(ctx, s.shares, userID, models.ShareProject, req.ProjectID, models.ShareRoleEditor)
+		if err != nil {
+			return nil, err
+		}
+		ownerID = access.OwnerID
+	}
+
+	position, err := s.appendPosition(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	projectID, err := s.resolveProject(ctx, ownerID, req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	priority, _ := models.ParsePriority(req.Priority)
 	now := time.Now()
 	todo := &models.Todo{
-		ID:        uuid.New().String(),
-		UserID:    userID,
-		Title:     req.Title,
-		Completed: req.Completed,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:           uuid.New().String(),
+		UserID:       ownerID,
+		Title:        req.Title,
+		Completed:    req.Completed,
+		DueAt:        req.DueAt.Time,
+		RemindAt:     req.RemindAt.Time,
+		Priority:     priority,
+		Position:     position,
+		ProjectID:    projectID,
+		AutoComplete: req.AutoComplete,
+		Notes:        req.Notes,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if req.Recurrence != nil {
+		todo.SeriesID = uuid.New().String()
+		todo.Recurrence = &models.Recurrence{
+			Rule:     req.Recurrence.Rule,
+			Timezone: req.Recurrence.Timezone,
+			Start:    *todo.DueAt,
+		}
 	}
 
-	err := s.repo.Create(userID, todo)
+	err = s.repo.Create(ctx, ownerID, todo)
 	if err != nil {
-		return nil, err
+		return nil, wrapRepositoryError(err)
 	}
 
-	response := todo.ToResponse()
-	return &response, nil
+	// 直接创建为已完成的重复待办事项时，同样需要生成下一个实例。
+	// 与 Update 一样先保存当前实例，再生成下一个实例，最后保存去掉规则的当前实例
+	var next *models.Todo
+	if todo.Completed && todo.Recurrence != nil {
+		if next, err = s.completeOccurrence(ctx, ownerID, todo); err != nil {
+			return nil, err
+		}
+		if err := s.repo.Update(ctx, ownerID, todo); err != nil {
+			return nil, wrapRepositoryError(err)
+		}
+	}
+
+	return s.occurrenceResponse(ctx, ownerID, todo, next)
 }
 
 // Update 更新待办事项
-func (s *todoService) Update(userID, id string, req models.UpdateTodoRequest) (*models.TodoResponse, error) {
-	// 先获取现有的待办事项
-	existingTodo, err := s.repo.Get(userID, id)
+func (s *todoService) Update(ctx context.Context, userID, id string, req models.UpdateTodoRequest) (*models.TodoResponse, error) {
+	if err := s.validator.ValidateUpdate(ctx, id, &req); err != nil {
+		return nil, err
+	}
+
+	access, err := authorize(ctx, s.shares, userID, models.ShareTodo, id, models.ShareRoleEditor)
 	if err != nil {
 		return nil, err
 	}
+	ownerID := access.OwnerID
+
+	// 先获取现有的待办事项
+	existingTodo, err := s.repo.Get(ctx, ownerID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	wasCompleted := existingTodo.Completed
 
 	// 更新字段
 	if req.Title != nil {
@@ -94,54 +314,289 @@ func (s *todoService) Update(userID, id string, req models.UpdateTodoRequest) (*
 	if req.Completed != nil {
 		existingTodo.Completed = *req.Completed
 	}
+	if req.DueAt.Set {
+		existingTodo.DueAt = req.DueAt.Time
+	}
+	if req.RemindAt.Set {
+		existingTodo.RemindAt = req.RemindAt.Time
+	}
+	if req.Priority != nil {
+		existingTodo.Priority, _ = models.ParsePriority(*req.Priority)
+	}
+	if req.ProjectID != nil {
+		projectID, err := s.resolveProject(ctx, ownerID, *req.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if err := requireProjectOwner(access, existingTodo.ProjectID, projectID); err != nil {
+			return nil, err
+		}
+		existingTodo.ProjectID = projectID
+	}
+	if req.AutoComplete != nil {
+		existingTodo.AutoComplete = *req.AutoComplete
+	}
+	if req.Notes != nil {
+		existingTodo.Notes = *req.Notes
+	}
+	if err := requireSeriesDue(ctx, existingTodo); err != nil {
+		return nil, err
+	}
+
+	var next *models.Todo
+	if !wasCompleted && existingTodo.Completed {
+		if next, err = s.completeOccurrence(ctx, ownerID, existingTodo); err != nil {
+			return nil, err
+		}
+	}
 
 	// 保存更新
-	err = s.repo.Update(userID, existingTodo)
+	err = s.repo.Update(ctx, ownerID, existingTodo)
 	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	return s.occurrenceResponse(ctx, ownerID, existingTodo, next)
+}
+
+// Replace 整体替换待办事项
+func (s *todoService) Replace(ctx context.Context, userID, id string, req models.ReplaceTodoRequest) (*models.TodoResponse, error) {
+	if err := s.validator.ValidateReplace(ctx, id, &req); err != nil {
 		return nil, err
 	}
 
-	response := existingTodo.ToResponse()
-	return &response, nil
+	access, err := authorize(ctx, s.shares, userID, models.ShareTodo, id, models.ShareRoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	ownerID := access.OwnerID
+
+	// 重复系列和位置不属于可替换的字段，从现有记录中保留
+	existingTodo, err := s.repo.Get(ctx, ownerID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	projectID, err := s.resolveProject(ctx, ownerID, req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireProjectOwner(access, existingTodo.ProjectID, projectID); err != nil {
+		return nil, err
+	}
+
+	todo := &models.Todo{
+		ID:           id,
+		UserID:       ownerID,
+		Title:        req.Title,
+		Completed:    *req.Completed,
+		DueAt:        req.DueAt.Time,
+		RemindAt:     req.RemindAt.Time,
+		SeriesID:     existingTodo.SeriesID,
+		Recurrence:   existingTodo.Recurrence,
+		Position:     existingTodo.Position,
+		ProjectID:    projectID,
+		AutoComplete: req.AutoComplete,
+		Notes:        req.Notes,
+	}
+	todo.Priority, _ = models.ParsePriority(req.Priority)
+	if err := requireSeriesDue(ctx, todo); err != nil {
+		return nil, err
+	}
+
+	var next *models.Todo
+	if !existingTodo.Completed && todo.Completed {
+		if next, err = s.completeOccurrence(ctx, ownerID, todo); err != nil {
+			return nil, err
+		}
+	}
+
+	// 仓库层只更新已存在的记录，不存在时返回 ErrTodoNotFound
+	err = s.repo.Update(ctx, ownerID, todo)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	return s.occurrenceResponse(ctx, ownerID, todo, next)
 }
 
 // Toggle 切换待办事项的完成状态
-func (s *todoService) Toggle(userID, id string) (*models.TodoResponse, error) {
-	// 先获取现有的待办事项
-	todo, err := s.repo.Get(userID, id)
+func (s *todoService) Toggle(ctx context.Context, userID, id string) (*models.TodoResponse, error) {
+	if err := s.validator.ValidateID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, id, models.ShareRoleEditor)
 	if err != nil {
 		return nil, err
 	}
 
+	// 先获取现有的待办事项
+	todo, err := s.repo.Get(ctx, ownerID, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
 	// 切换状态
 	todo.Completed = !todo.Completed
 
+	var next *models.Todo
+	if todo.Completed {
+		if next, err = s.completeOccurrence(ctx, ownerID, todo); err != nil {
+			return nil, err
+		}
+	}
+
 	// 保存更新
-	err = s.repo.Update(userID, todo)
+	err = s.repo.Update(ctx, ownerID, todo)
 	if err != nil {
-		return nil, err
+		return nil, wrapRepositoryError(err)
 	}
 
-	response := todo.ToResponse()
-	return &response, nil
+	return s.occurrenceResponse(ctx, ownerID, todo, next)
 }
 
 // Delete 删除待办事项
-func (s *todoService) Delete(userID, id string) error {
-	return s.repo.Delete(userID, id)
+func (s *todoService) Delete(ctx context.Context, userID, id string) error {
+	if err := s.validator.ValidateID(ctx, id); err != nil {
+		return err
+	}
+
+	ownerID, err := todoOwner(ctx, s.shares, userID, id, models.ShareRoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, ownerID, id); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// resolveProject 确认项目属于该用户并返回项目 ID，projectID 为空时返回收件箱的 ID。
+// 存储后端不支持项目时返回空字符串，指定了项目时返回项目不存在
+func (s *todoService) resolveProject(ctx context.Context, userID, projectID string) (string, error) {
+	switch {
+	case s.projects == nil && projectID == "":
+		return "", nil
+	case s.projects == nil:
+		return "", wrapRepositoryError(repository.ErrProjectNotFound)
+	case projectID == "":
+		inbox, err := s.projects.EnsureInbox(ctx, userID)
+		if err != nil {
+			return "", wrapRepositoryError(err)
+		}
+		return inbox.ID, nil
+	}
+
+	project, err := s.projects.GetProject(ctx, userID, projectID)
+	if err != nil {
+		return "", wrapRepositoryError(err)
+	}
+	return project.ID, nil
+}
+
+// requireProjectOwner 把待办事项移到其他项目需要 owner 角色，项目不变时不检查
+func requireProjectOwner(access *models.Access, from, to string) error {
+	if from != to && !access.Role.Allows(models.ShareRoleOwner) {
+		return errors.NewWithKey(errors.ErrForbidden, errors.MsgShareOwner)
+	}
+	return nil
+}
+
+// renderNotes 把响应中的备注渲染为 HTML，渲染结果已经过滤，客户端可以直接插入页面
+func renderNotes(response *models.TodoResponse) {
+	response.NotesHTML = markdown.ToHTML(response.Notes)
 }
 
-// generateID 生成一个唯一 ID
-func generateID() string {
-	return "todo-" + randomString(8)
+// withDetails 为待办事项填充标签、子任务进度和评论数，每种数据一次查询所有待办事项，nil 会被跳过
+func (s *todoService) withDetails(ctx context.Context, userID string, todos ...*models.Todo) error {
+	if s.tags == nil && s.subtasks == nil && s.comments == nil {
+		return nil
+	}
+
+	ids := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		if todo != nil {
+			ids = append(ids, todo.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if s.tags != nil {
+		tags, err := s.tags.TodoTags(ctx, userID, ids)
+		if err != nil {
+			return wrapRepositoryError(err)
+		}
+		for _, todo := range todos {
+			if todo != nil {
+				todo.Tags = tags[todo.ID]
+			}
+		}
+	}
+
+	if s.subtasks != nil {
+		progress, err := s.subtasks.SubtaskProgress(ctx, userID, ids)
+		if err != nil {
+			return wrapRepositoryError(err)
+		}
+		for _, todo := range todos {
+			if todo != nil {
+				todo.Progress = progress[todo.ID]
+			}
+		}
+	}
+
+	if s.comments != nil {
+		counts, err := s.comments.CommentCounts(ctx, ids)
+		if err != nil {
+			return wrapRepositoryError(err)
+		}
+		for _, todo := range todos {
+			if todo != nil {
+				todo.CommentCount = counts[todo.ID]
+			}
+		}
+	}
+	return nil
 }
 
-// randomString 生成指定长度的随机字符串
-func randomString(n int) string {
-	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = letters[i%len(letters)]
+// wrapRepositoryError 将仓库层错误转换为带错误码的 *errors.Error，
+// 原始错误保留在 Err 中用于日志，不会返回给客户端
+func wrapRepositoryError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrTodoNotFound):
+		return errors.New(errors.ErrTodoNotFound, err)
+	case errors.Is(err, repository.ErrTodoAlreadyExists):
+		return errors.New(errors.ErrTodoAlreadyExists, err)
+	case errors.Is(err, repository.ErrNotificationNotFound):
+		return errors.New(errors.ErrNotificationNotFound, err)
+	case errors.Is(err, repository.ErrTagNotFound):
+		return errors.New(errors.ErrTagNotFound, err)
+	case errors.Is(err, repository.ErrTagAlreadyExists):
+		return errors.New(errors.ErrTagAlreadyExists, err)
+	case errors.Is(err, repository.ErrProjectNotFound):
+		return errors.New(errors.ErrProjectNotFound, err)
+	case errors.Is(err, repository.ErrSubtaskNotFound):
+		return errors.New(errors.ErrSubtaskNotFound, err)
+	case errors.Is(err, repository.ErrAttachmentNotFound):
+		return errors.New(errors.ErrAttachmentNotFound, err)
+	case errors.Is(err, repository.ErrCommentNotFound):
+		return errors.New(errors.ErrCommentNotFound, err)
+	case errors.Is(err, repository.ErrShareNotFound):
+		return errors.New(errors.ErrShareNotFound, err)
+	case errors.Is(err, repository.ErrInvitationNotFound):
+		return errors.New(errors.ErrInvitationNotFound, err)
+	case errors.Is(err, repository.ErrWorkspaceNotFound):
+		return errors.New(errors.ErrWorkspaceNotFound, err)
+	case errors.Is(err, repository.ErrMemberNotFound):
+		return errors.New(errors.ErrMemberNotFound, err)
+	case errors.Is(err, repository.ErrMemberAlreadyExists):
+		return errors.New(errors.ErrMemberAlreadyExists, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return errors.New(errors.ErrTimeout, err)
+	default:
+		return errors.New(errors.ErrInternal, err)
 	}
-	return string(b)
 }
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+func TestTodoServiceCreateCompletedRecurring(t *testing.T) {
+	repo := repository.NewInMemoryTodoRepository()
+	todos := NewTodoService(repo, NewTodoValidator(config.TodoValidationConfig{}))
+	ctx := context.Background()
+
+	due := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	resp, err := todos.Create(ctx, "user-1", models.CreateTodoRequest{
+		Title:      "每日站会",
+		Completed:  true,
+		DueAt:      models.NullableTime{Set: true, Time: &due},
+		Recurrence: &models.RecurrenceRequest{Rule: "FREQ=DAILY", Timezone: "UTC"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if resp.NextOccurrence == nil {
+		t.Fatal("Create() NextOccurrence = nil, want next occurrence")
+	}
+
+	// 当前实例已保存为完成且去掉了规则，规则转移到下一个实例
+	stored, err := repo.Get(ctx, "user-1", resp.ID)
+	if err != nil {
+		t.Fatalf("Get(created) error = %v", err)
+	}
+	if !stored.Completed || stored.Recurrence != nil {
+		t.Errorf("stored todo = completed %v, recurrence %+v, want completed without recurrence", stored.Completed, stored.Recurrence)
+	}
+
+	next, err := repo.Get(ctx, "user-1", resp.NextOccurrence.ID)
+	if err != nil {
+		t.Fatalf("Get(next) error = %v", err)
+	}
+	if next.Completed || next.Recurrence == nil || next.SeriesID != stored.SeriesID {
+		t.Errorf("next occurrence = %+v, want open occurrence of series %q with recurrence", next, stored.SeriesID)
+	}
+	if want := due.AddDate(0, 0, 1); next.DueAt == nil || !next.DueAt.Equal(want) {
+		t.Errorf("next.DueAt = %v, want %v", next.DueAt, want)
+	}
+	// 下一个实例排在当前实例之后
+	if next.Position <= stored.Position {
+		t.Errorf("next.Position = %q, want after %q", next.Position, stored.Position)
+	}
+}
@@ -0,0 +1,435 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/recurrence"
+)
+
+// 未配置时使用的默认限制
+const (
+	defaultTitleMinLength   = 1
+	defaultTitleMaxLength   = 200
+	defaultListDefaultLimit = 50
+	defaultListMaxLimit     = 200
+	defaultNotesMaxLength   = 10000
+
+	// maxRecurrenceRuleLength 重复规则的最大长度
+	maxRecurrenceRuleLength = 500
+)
+
+// todoSortFields 列表允许的排序字段
+var todoSortFields = []models.SortField{
+	models.SortByCreatedAt, models.SortByUpdatedAt, models.SortByTitle, models.SortByPosition, models.SortByPriority,
+}
+
+// TodoValidator 校验并规范化待办事项请求，规则来自 validation.todo 配置
+type TodoValidator struct {
+	titleMinLength    int
+	titleMaxLength    int
+	trimTitle         bool
+	forbiddenChars    string
+	allowControlChars bool
+	listDefaultLimit  int
+	listMaxLimit      int
+	notesMaxLength    int
+}
+
+// NewTodoValidator 创建待办事项校验器，长度限制未配置时使用默认值
+func NewTodoValidator(cfg config.TodoValidationConfig) *TodoValidator {
+	v := &TodoValidator{
+		titleMinLength:    cfg.TitleMinLength,
+		titleMaxLength:    cfg.TitleMaxLength,
+		trimTitle:         cfg.TrimTitle,
+		forbiddenChars:    cfg.ForbiddenChars,
+		allowControlChars: cfg.AllowControlChars,
+		listDefaultLimit:  cfg.ListDefaultLimit,
+		listMaxLimit:      cfg.ListMaxLimit,
+		notesMaxLength:    cfg.NotesMaxLength,
+	}
+	if v.titleMinLength <= 0 {
+		v.titleMinLength = defaultTitleMinLength
+	}
+	if v.titleMaxLength <= 0 {
+		v.titleMaxLength = defaultTitleMaxLength
+	}
+	if v.listMaxLimit <= 0 {
+		v.listMaxLimit = defaultListMaxLimit
+	}
+	if v.listDefaultLimit <= 0 || v.listDefaultLimit > v.listMaxLimit {
+		v.listDefaultLimit = min(defaultListDefaultLimit, v.listMaxLimit)
+	}
+	if v.notesMaxLength <= 0 {
+		v.notesMaxLength = defaultNotesMaxLength
+	}
+	return v
+}
+
+// ValidateCreate 校验创建请求，并就地规范化字段（例如去掉标题首尾空白）
+func (v *TodoValidator) ValidateCreate(ctx context.Context, req *models.CreateTodoRequest) error {
+	violations := newViolations(ctx)
+	req.Title = v.title(violations, req.Title)
+	validateTimeInto(violations, "dueAt", req.DueAt)
+	validateTimeInto(violations, "remindAt", req.RemindAt)
+	req.Priority = validatePriorityInto(violations, req.Priority)
+	req.ProjectID = strings.TrimSpace(req.ProjectID)
+	req.Notes = v.notes(violations, req.Notes)
+	if req.Recurrence != nil {
+		validateRecurrenceInto(violations, "recurrence.", req.Recurrence)
+		if req.DueAt.Time == nil && !req.DueAt.Invalid {
+			violations.add("dueAt", errors.RuleRequired, errors.MsgRecurrenceNeedsDue)
+		}
+	}
+	return violations.err()
+}
+
+// ValidateUpdate 校验更新请求，只校验请求中出现的字段
+func (v *TodoValidator) ValidateUpdate(ctx context.Context, id string, req *models.UpdateTodoRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+	if req.Title != nil {
+		title := v.title(violations, *req.Title)
+		req.Title = &title
+	}
+	validateTimeInto(violations, "dueAt", req.DueAt)
+	validateTimeInto(violations, "remindAt", req.RemindAt)
+	if req.Priority != nil {
+		priority := validatePriorityInto(violations, *req.Priority)
+		req.Priority = &priority
+	}
+	if req.ProjectID != nil {
+		projectID := strings.TrimSpace(*req.ProjectID)
+		req.ProjectID = &projectID
+	}
+	if req.Notes != nil {
+		notes := v.notes(violations, *req.Notes)
+		req.Notes = &notes
+	}
+	return violations.err()
+}
+
+// ValidateReplace 校验整体替换请求，所有字段都必须提供
+func (v *TodoValidator) ValidateReplace(ctx context.Context, id string, req *models.ReplaceTodoRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+	req.Title = v.title(violations, req.Title)
+	if req.Completed == nil {
+		violations.add("completed", errors.RuleRequired, errors.MsgFieldRequired)
+	}
+	validateTimeInto(violations, "dueAt", req.DueAt)
+	validateTimeInto(violations, "remindAt", req.RemindAt)
+	req.Priority = validatePriorityInto(violations, req.Priority)
+	req.ProjectID = strings.TrimSpace(req.ProjectID)
+	req.Notes = v.notes(violations, req.Notes)
+	return violations.err()
+}
+
+// ValidateMove 校验移动请求，before 和 after 必须且只能提供一个，且不能是待办事项自身
+func (v *TodoValidator) ValidateMove(ctx context.Context, id string, req *models.MoveTodoRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+
+	req.Before = strings.TrimSpace(req.Before)
+	req.After = strings.TrimSpace(req.After)
+	switch {
+	case req.Before == "" && req.After == "":
+		violations.add("before", errors.RuleRequired, errors.MsgMoveTarget)
+	case req.Before != "" && req.After != "":
+		violations.add("after", errors.RuleMoveTarget, errors.MsgMoveTarget)
+	case req.Before == id:
+		violations.add("before", errors.RuleMoveTarget, errors.MsgMoveSelf)
+	case req.After == id:
+		violations.add("after", errors.RuleMoveTarget, errors.MsgMoveSelf)
+	}
+	return violations.err()
+}
+
+// ValidateRecurrence 校验设置重复规则的请求，并就地规范化规则和时区
+func (v *TodoValidator) ValidateRecurrence(ctx context.Context, id string, req *models.RecurrenceRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+	validateRecurrenceInto(violations, "", req)
+	return violations.err()
+}
+
+// ValidateGet 校验获取单个待办事项的 ID 和查询参数
+func (v *TodoValidator) ValidateGet(ctx context.Context, id string, req models.GetTodoRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+	validateRenderInto(violations, req.Render)
+	return violations.err()
+}
+
+// ValidateID 校验路径中的待办事项 ID
+func (v *TodoValidator) ValidateID(ctx context.Context, id string) error {
+	return validateID(ctx, id)
+}
+
+// ParseList 校验列表查询参数并转换为仓库层的查询参数。
+// 默认按创建时间倒序，按位置排序时默认升序，未指定 limit 时使用配置的默认值。
+func (v *TodoValidator) ParseList(ctx context.Context, req models.ListTodosRequest) (models.TodoListOptions, error) {
+	violations := newViolations(ctx)
+	opts := models.TodoListOptions{
+		SortBy:  models.SortByCreatedAt,
+		SortDir: models.SortDesc,
+		Limit:   v.limit(violations, req.Limit),
+	}
+
+	if req.Sort != "" {
+		field := models.SortField(req.Sort)
+		if slices.Contains(todoSortFields, field) {
+			opts.SortBy = field
+			if field == models.SortByPosition {
+				opts.SortDir = models.SortAsc
+			}
+		} else {
+			violations.add("sort", errors.RuleEnum, errors.MsgFieldEnum, joinValues(todoSortFields))
+		}
+	}
+
+	if req.Order != "" {
+		switch dir := models.SortDirection(strings.ToLower(req.Order)); dir {
+		case models.SortAsc, models.SortDesc:
+			opts.SortDir = dir
+		default:
+			violations.add("order", errors.RuleEnum, errors.MsgFieldEnum,
+				joinValues([]models.SortDirection{models.SortAsc, models.SortDesc}))
+		}
+	}
+
+	opts.Filter.Completed = parseBoolParam(violations, "completed", req.Completed)
+	opts.Filter.CreatedAfter = parseTimeParam(violations, "created_after", req.CreatedAfter)
+	opts.Filter.CreatedBefore = parseTimeParam(violations, "created_before", req.CreatedBefore)
+	opts.Filter.UpdatedAfter = parseTimeParam(violations, "updated_after", req.UpdatedAfter)
+	opts.Filter.UpdatedBefore = parseTimeParam(violations, "updated_before", req.UpdatedBefore)
+	opts.Filter.SeriesID = strings.TrimSpace(req.Series)
+	opts.Filter.ProjectID = strings.TrimSpace(req.Project)
+	opts.Filter.TagIDs, opts.Filter.TagMatch = parseTagsParam(violations, req.Tags, req.TagMatch)
+	validateRenderInto(violations, req.Render)
+
+	loc := parseLocationParam(violations, "tz", req.TZ)
+	switch due := models.DueFilter(req.Due); due {
+	case "":
+	case models.DueOverdue, models.DueToday, models.DueThisWeek:
+		opts.Filter.DueAfter, opts.Filter.DueBefore = dueRange(due, time.Now(), loc)
+		// 已完成的待办事项不算逾期，除非显式要求查看已完成的
+		if due == models.DueOverdue && opts.Filter.Completed == nil {
+			completed := false
+			opts.Filter.Completed = &completed
+		}
+	default:
+		violations.add("due", errors.RuleEnum, errors.MsgFieldEnum,
+			joinValues([]models.DueFilter{models.DueOverdue, models.DueToday, models.DueThisWeek}))
+	}
+
+	// 游标必须与本次请求的排序方式一致，否则分页结果没有意义
+	if req.Cursor != "" {
+		cursor, err := models.DecodeCursor(req.Cursor)
+		if err != nil || cursor.SortBy != opts.SortBy || cursor.SortDir != opts.SortDir {
+			violations.add("cursor", errors.RuleCursor, errors.MsgInvalidCursor)
+		} else {
+			opts.After = cursor
+		}
+	}
+
+	return opts, violations.err()
+}
+
+// ParseSearch 校验搜索参数并转换为仓库层的搜索参数。
+// 查询串去掉首尾空白后不能为空，长度不超过标题的最大长度。
+func (v *TodoValidator) ParseSearch(ctx context.Context, req models.SearchTodosRequest) (models.TodoSearchOptions, error) {
+	violations := newViolations(ctx)
+	opts := models.TodoSearchOptions{
+		Query: strings.TrimSpace(req.Query),
+		Limit: v.limit(violations, req.Limit),
+	}
+
+	switch length := utf8.RuneCountInString(opts.Query); {
+	case length == 0:
+		violations.add("q", errors.RuleRequired, errors.MsgFieldRequired)
+	case length > v.titleMaxLength:
+		violations.add("q", errors.RuleMaxLength, errors.MsgFieldTooLong, v.titleMaxLength)
+	}
+
+	opts.Completed = parseBoolParam(violations, "completed", req.Completed)
+	validateRenderInto(violations, req.Render)
+
+	if req.Cursor != "" {
+		cursor, err := models.DecodeCursor(req.Cursor)
+		if err != nil || cursor.SortBy != models.SortByRelevance || cursor.SortDir != models.SortDesc {
+			violations.add("cursor", errors.RuleCursor, errors.MsgInvalidCursor)
+		} else if _, err := cursor.Score(); err != nil {
+			violations.add("cursor", errors.RuleCursor, errors.MsgInvalidCursor)
+		} else {
+			opts.After = cursor
+		}
+	}
+
+	return opts, violations.err()
+}
+
+// limit 校验单页条数，未指定时返回配置的默认值
+func (v *TodoValidator) limit(violations *violations, value string) int {
+	if value == "" {
+		return v.listDefaultLimit
+	}
+	limit, err := strconv.Atoi(value)
+	switch {
+	case err != nil:
+		violations.add("limit", errors.RuleInteger, errors.MsgFieldInteger)
+	case limit < 1 || limit > v.listMaxLimit:
+		violations.add("limit", errors.RuleRange, errors.MsgFieldRange, 1, v.listMaxLimit)
+	default:
+		return limit
+	}
+	return v.listDefaultLimit
+}
+
+// title 校验标题，返回规范化后的标题。同一字段可能同时违反多条规则，全部记录下来
+func (v *TodoValidator) title(violations *violations, title string) string {
+	const field = "title"
+
+	if v.trimTitle {
+		title = strings.TrimSpace(title)
+	}
+
+	length := utf8.RuneCountInString(title)
+	switch {
+	case length == 0:
+		violations.add(field, errors.RuleRequired, errors.MsgFieldRequired)
+		return title
+	case length < v.titleMinLength:
+		violations.add(field, errors.RuleMinLength, errors.MsgFieldTooShort, v.titleMinLength)
+	case length > v.titleMaxLength:
+		violations.add(field, errors.RuleMaxLength, errors.MsgFieldTooLong, v.titleMaxLength)
+	}
+
+	if !v.allowControlChars && strings.IndexFunc(title, unicode.IsControl) >= 0 {
+		violations.add(field, errors.RuleControlChars, errors.MsgFieldControlChars)
+	}
+
+	if v.forbiddenChars != "" {
+		if i := strings.IndexAny(title, v.forbiddenChars); i >= 0 {
+			r, _ := utf8.DecodeRuneInString(title[i:])
+			violations.add(field, errors.RuleForbiddenChars, errors.MsgFieldForbiddenChars, string(r))
+		}
+	}
+
+	return title
+}
+
+// notes 校验备注，返回规范化后的备注：换行统一为 \n，去掉末尾的空白。
+// 备注可以为空，除换行和制表符外不允许控制字符
+func (v *TodoValidator) notes(violations *violations, notes string) string {
+	const field = "notes"
+
+	notes = strings.ReplaceAll(notes, "\r\n", "\n")
+	notes = strings.ReplaceAll(notes, "\r", "\n")
+	notes = strings.TrimRightFunc(notes, unicode.IsSpace)
+
+	if length := utf8.RuneCountInString(notes); length > v.notesMaxLength {
+		violations.add(field, errors.RuleMaxLength, errors.MsgFieldTooLong, v.notesMaxLength)
+	}
+	if strings.IndexFunc(notes, func(r rune) bool { return unicode.IsControl(r) && r != '\n' && r != '\t' }) >= 0 {
+		violations.add(field, errors.RuleControlChars, errors.MsgFieldControlChars)
+	}
+	return notes
+}
+
+// validateRenderInto 校验 render 参数，目前只支持 html
+func validateRenderInto(violations *violations, render string) {
+	if render != "" && render != models.RenderHTML {
+		violations.add("render", errors.RuleEnum, errors.MsgFieldEnum, models.RenderHTML)
+	}
+}
+
+// parseTagsParam 解析逗号分隔的标签 ID 和匹配方式，去掉空白和重复的 ID，匹配方式默认为 any
+func parseTagsParam(violations *violations, tags, match string) ([]string, models.TagMatch) {
+	var tagIDs []string
+	for _, id := range strings.Split(tags, ",") {
+		if id = strings.TrimSpace(id); id != "" && !slices.Contains(tagIDs, id) {
+			tagIDs = append(tagIDs, id)
+		}
+	}
+	if len(tagIDs) > maxTagsPerTodo {
+		violations.add("tags", errors.RuleMaxItems, errors.MsgFieldTooMany, maxTagsPerTodo)
+		tagIDs = nil
+	}
+
+	switch tagMatch := models.TagMatch(strings.ToLower(match)); tagMatch {
+	case "":
+		return tagIDs, models.TagMatchAny
+	case models.TagMatchAny, models.TagMatchAll:
+		return tagIDs, tagMatch
+	default:
+		violations.add("tag_match", errors.RuleEnum, errors.MsgFieldEnum,
+			joinValues([]models.TagMatch{models.TagMatchAny, models.TagMatchAll}))
+		return tagIDs, models.TagMatchAny
+	}
+}
+
+// dueRange 返回截止时间过滤对应的时间范围，包含起点、不包含终点，nil 表示不限
+func dueRange(due models.DueFilter, now time.Time, loc *time.Location) (after, before *time.Time) {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	var start, end time.Time
+	switch due {
+	case models.DueOverdue:
+		return nil, &now
+	case models.DueToday:
+		start, end = today, today.AddDate(0, 0, 1)
+	case models.DueThisWeek:
+		// Weekday 中周日为 0，换算为距离周一的天数
+		start = today.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+		end = start.AddDate(0, 0, 7)
+	}
+	return &start, &end
+}
+
+// validateTimeInto 校验请求体中的可选时间字段
+func validateTimeInto(violations *violations, field string, t models.NullableTime) {
+	if t.Invalid {
+		violations.add(field, errors.RuleDateTime, errors.MsgFieldDateTime)
+	}
+}
+
+// validatePriorityInto 校验优先级名称，返回规范化后的名称，为空时返回 none
+func validatePriorityInto(violations *violations, priority string) string {
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	if priority == "" {
+		return models.PriorityNone.String()
+	}
+	if _, ok := models.ParsePriority(priority); !ok {
+		violations.add("priority", errors.RuleEnum, errors.MsgFieldEnum, strings.Join(models.PriorityNames(), ", "))
+	}
+	return priority
+}
+
+// validateRecurrenceInto 校验重复规则和时区，字段名带有 prefix。
+// 规则改写为规范化的形式，空的时区改写为 UTC。
+func validateRecurrenceInto(violations *violations, prefix string, req *models.RecurrenceRequest) {
+	switch {
+	case strings.TrimSpace(req.Rule) == "":
+		violations.add(prefix+"rule", errors.RuleRequired, errors.MsgFieldRequired)
+	case utf8.RuneCountInString(req.Rule) > maxRecurrenceRuleLength:
+		violations.add(prefix+"rule", errors.RuleMaxLength, errors.MsgFieldTooLong, maxRecurrenceRuleLength)
+	default:
+		rule, err := recurrence.Parse(req.Rule)
+		if err != nil {
+			violations.add(prefix+"rule", errors.RuleRRule, errors.MsgFieldRRule)
+		} else {
+			req.Rule = rule.String()
+		}
+	}
+	req.Timezone = parseLocationParam(violations, prefix+"timezone", req.Timezone).String()
+}
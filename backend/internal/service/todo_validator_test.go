@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+)
+
+// violatedFields 返回校验错误中违反规则的字段
+func violatedFields(err error) []string {
+	var fields []string
+	for _, violation := range errors.Violations(err) {
+		fields = append(fields, violation.Field)
+	}
+	return fields
+}
+
+func TestParseListCursor(t *testing.T) {
+	v := NewTodoValidator(config.TodoValidationConfig{})
+	todo := models.Todo{ID: "todo-1", Title: "a", Position: "V", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	tests := []struct {
+		name    string
+		cursor  *models.Cursor
+		sort    string
+		order   string
+		wantErr bool
+	}{
+		{name: "default sort", cursor: models.NewCursor(models.SortByCreatedAt, models.SortDesc, todo)},
+		{name: "position defaults to asc", cursor: models.NewCursor(models.SortByPosition, models.SortAsc, todo), sort: "position"},
+		{name: "explicit order", cursor: models.NewCursor(models.SortByTitle, models.SortAsc, todo), sort: "title", order: "asc"},
+		{name: "field mismatch", cursor: models.NewCursor(models.SortByTitle, models.SortDesc, todo), wantErr: true},
+		{name: "direction mismatch", cursor: models.NewCursor(models.SortByCreatedAt, models.SortAsc, todo), wantErr: true},
+		{name: "sort changed", cursor: models.NewCursor(models.SortByCreatedAt, models.SortDesc, todo), sort: "updated_at", wantErr: true},
+		{name: "order changed", cursor: models.NewCursor(models.SortByPosition, models.SortAsc, todo), sort: "position", order: "desc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := models.ListTodosRequest{Cursor: tt.cursor.Encode(), Sort: tt.sort, Order: tt.order}
+			opts, err := v.ParseList(context.Background(), req)
+			if tt.wantErr {
+				if fields := violatedFields(err); len(fields) != 1 || fields[0] != "cursor" {
+					t.Fatalf("ParseList() violations = %v, want [cursor]", fields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseList() error = %v", err)
+			}
+			if opts.After == nil || opts.After.ID != todo.ID {
+				t.Errorf("After = %+v, want cursor for %s", opts.After, todo.ID)
+			}
+		})
+	}
+}
+
+func TestParseListInvalidCursor(t *testing.T) {
+	v := NewTodoValidator(config.TodoValidationConfig{})
+	_, err := v.ParseList(context.Background(), models.ListTodosRequest{Cursor: "not-a-cursor"})
+	if fields := violatedFields(err); len(fields) != 1 || fields[0] != "cursor" {
+		t.Errorf("ParseList() violations = %v, want [cursor]", fields)
+	}
+}
+
+func TestParseSearchCursor(t *testing.T) {
+	v := NewTodoValidator(config.TodoValidationConfig{})
+
+	// 搜索结果只能按相关度倒序分页，列表的游标不能用于搜索
+	listCursor := models.NewCursor(models.SortByCreatedAt, models.SortDesc, models.Todo{ID: "todo-1", CreatedAt: time.Now()})
+	_, err := v.ParseSearch(context.Background(), models.SearchTodosRequest{Query: "a", Cursor: listCursor.Encode()})
+	if fields := violatedFields(err); len(fields) != 1 || fields[0] != "cursor" {
+		t.Errorf("ParseSearch() violations = %v, want [cursor]", fields)
+	}
+}
+
+func TestParseListCommentsCursor(t *testing.T) {
+	v := NewCommentValidator(NewTodoValidator(config.TodoValidationConfig{}))
+	asc := &models.Cursor{SortBy: models.SortByCreatedAt, SortDir: models.SortAsc, Values: []string{time.Now().Format(time.RFC3339Nano)}, ID: "c1"}
+	desc := &models.Cursor{SortBy: models.SortByCreatedAt, SortDir: models.SortDesc, Values: asc.Values, ID: "c1"}
+	notTime := &models.Cursor{SortBy: models.SortByCreatedAt, SortDir: models.SortAsc, Values: []string{"yesterday"}, ID: "c1"}
+
+	if _, err := v.ParseList(context.Background(), "todo-1", models.ListCommentsRequest{Cursor: asc.Encode()}); err != nil {
+		t.Errorf("ParseList(asc) error = %v", err)
+	}
+	for name, cursor := range map[string]*models.Cursor{"desc": desc, "not time": notTime} {
+		_, err := v.ParseList(context.Background(), "todo-1", models.ListCommentsRequest{Cursor: cursor.Encode()})
+		if fields := violatedFields(err); len(fields) != 1 || fields[0] != "cursor" {
+			t.Errorf("ParseList(%s) violations = %v, want [cursor]", name, fields)
+		}
+	}
+}
+
+func TestDueRange(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("时区数据不可用: %v", err)
+	}
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("时区数据不可用: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		due        models.DueFilter
+		now        time.Time
+		loc        *time.Location
+		wantAfter  time.Time
+		wantBefore time.Time
+	}{
+		{
+			name:       "today",
+			due:        models.DueToday,
+			now:        time.Date(2026, 5, 6, 15, 4, 5, 0, time.UTC),
+			loc:        time.UTC,
+			wantAfter:  time.Date(2026, 5, 6, 0, 0, 0, 0, time.UTC),
+			wantBefore: time.Date(2026, 5, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// UTC 的周日晚上在上海已经是周一，日期和周都按用户所在时区计算
+			name:       "today in a zone ahead of UTC",
+			due:        models.DueToday,
+			now:        time.Date(2026, 5, 3, 17, 0, 0, 0, time.UTC),
+			loc:        shanghai,
+			wantAfter:  time.Date(2026, 5, 4, 0, 0, 0, 0, shanghai),
+			wantBefore: time.Date(2026, 5, 5, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name:       "week in a zone ahead of UTC",
+			due:        models.DueThisWeek,
+			now:        time.Date(2026, 5, 3, 17, 0, 0, 0, time.UTC),
+			loc:        shanghai,
+			wantAfter:  time.Date(2026, 5, 4, 0, 0, 0, 0, shanghai),
+			wantBefore: time.Date(2026, 5, 11, 0, 0, 0, 0, shanghai),
+		},
+		{
+			name:       "week starts on monday midway through the week",
+			due:        models.DueThisWeek,
+			now:        time.Date(2026, 5, 7, 12, 0, 0, 0, time.UTC),
+			loc:        time.UTC,
+			wantAfter:  time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC),
+			wantBefore: time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "sunday belongs to the week that started on monday",
+			due:        models.DueThisWeek,
+			now:        time.Date(2026, 5, 10, 23, 59, 59, 0, time.UTC),
+			loc:        time.UTC,
+			wantAfter:  time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC),
+			wantBefore: time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// 恰好是周一零点时，本周和今天都从此刻开始
+			name:       "monday midnight starts a new week",
+			due:        models.DueThisWeek,
+			now:        time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC),
+			loc:        time.UTC,
+			wantAfter:  time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC),
+			wantBefore: time.Date(2026, 5, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "midnight starts a new day",
+			due:        models.DueToday,
+			now:        time.Date(2026, 5, 11, 0, 0, 0, 0, newYork),
+			loc:        newYork,
+			wantAfter:  time.Date(2026, 5, 11, 0, 0, 0, 0, newYork),
+			wantBefore: time.Date(2026, 5, 12, 0, 0, 0, 0, newYork),
+		},
+		{
+			// 夏令时开始的当天只有 23 小时
+			name:       "daylight saving starts",
+			due:        models.DueToday,
+			now:        time.Date(2026, 3, 8, 12, 0, 0, 0, newYork),
+			loc:        newYork,
+			wantAfter:  time.Date(2026, 3, 8, 0, 0, 0, 0, newYork),
+			wantBefore: time.Date(2026, 3, 9, 0, 0, 0, 0, newYork),
+		},
+		{
+			// 夏令时结束的当天有 25 小时
+			name:       "daylight saving ends",
+			due:        models.DueToday,
+			now:        time.Date(2026, 11, 1, 23, 30, 0, 0, newYork),
+			loc:        newYork,
+			wantAfter:  time.Date(2026, 11, 1, 0, 0, 0, 0, newYork),
+			wantBefore: time.Date(2026, 11, 2, 0, 0, 0, 0, newYork),
+		},
+		{
+			name:       "week across daylight saving",
+			due:        models.DueThisWeek,
+			now:        time.Date(2026, 3, 8, 12, 0, 0, 0, newYork),
+			loc:        newYork,
+			wantAfter:  time.Date(2026, 3, 2, 0, 0, 0, 0, newYork),
+			wantBefore: time.Date(2026, 3, 9, 0, 0, 0, 0, newYork),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after, before := dueRange(tt.due, tt.now, tt.loc)
+			if after == nil || before == nil {
+				t.Fatalf("dueRange() = %v, %v, want both bounds", after, before)
+			}
+			if !after.Equal(tt.wantAfter) || !before.Equal(tt.wantBefore) {
+				t.Errorf("dueRange() = [%v, %v), want [%v, %v)", after, before, tt.wantAfter, tt.wantBefore)
+			}
+			// 范围包含起点、不包含终点，当前时间总在范围内
+			if tt.now.Before(*after) || !tt.now.Before(*before) {
+				t.Errorf("now %v is outside [%v, %v)", tt.now, after, before)
+			}
+		})
+	}
+
+	// 夏令时当天的长度不是 24 小时
+	after, before := dueRange(models.DueToday, time.Date(2026, 3, 8, 12, 0, 0, 0, newYork), newYork)
+	if got := before.Sub(*after); got != 23*time.Hour {
+		t.Errorf("length of the day daylight saving starts = %v, want 23h", got)
+	}
+	after, before = dueRange(models.DueToday, time.Date(2026, 11, 1, 12, 0, 0, 0, newYork), newYork)
+	if got := before.Sub(*after); got != 25*time.Hour {
+		t.Errorf("length of the day daylight saving ends = %v, want 25h", got)
+	}
+
+	// 已过期只有终点，恰好在当前时刻截止的待办事项不算过期
+	now := time.Date(2026, 5, 6, 15, 4, 5, 0, time.UTC)
+	after, before = dueRange(models.DueOverdue, now, shanghai)
+	if after != nil || before == nil || !before.Equal(now) {
+		t.Errorf("dueRange(overdue) = %v, %v, want nil, %v", after, before, now)
+	}
+}
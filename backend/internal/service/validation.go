@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/i18n"
+)
+
+// colorPattern 标签和项目颜色的格式
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// validateID 校验路径中的资源 ID
+func validateID(ctx context.Context, id string) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+	return violations.err()
+}
+
+// validateIDInto 校验 ID 不为空
+func validateIDInto(violations *violations, id string) {
+	if strings.TrimSpace(id) == "" {
+		violations.add("id", errors.RuleRequired, errors.MsgFieldRequired)
+	}
+}
+
+// validateNameInto 校验标签或项目的名称，返回去掉首尾空白后的名称
+func validateNameInto(violations *violations, name string, maxLength int) string {
+	const field = "name"
+
+	name = strings.TrimSpace(name)
+	switch length := utf8.RuneCountInString(name); {
+	case length == 0:
+		violations.add(field, errors.RuleRequired, errors.MsgFieldRequired)
+	case length > maxLength:
+		violations.add(field, errors.RuleMaxLength, errors.MsgFieldTooLong, maxLength)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		violations.add(field, errors.RuleControlChars, errors.MsgFieldControlChars)
+	}
+	return name
+}
+
+// validateColorInto 校验 #RRGGBB 格式的颜色，返回小写的颜色
+func validateColorInto(violations *violations, color string) string {
+	color = strings.TrimSpace(color)
+	if !colorPattern.MatchString(color) {
+		violations.add("color", errors.RuleColor, errors.MsgFieldColor)
+	}
+	return strings.ToLower(color)
+}
+
+// parseBoolParam 解析布尔参数，为空时返回 nil
+func parseBoolParam(violations *violations, field, value string) *bool {
+	if value == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		violations.add(field, errors.RuleBoolean, errors.MsgFieldBoolean)
+		return nil
+	}
+	return &b
+}
+
+// parseLocationParam 解析 IANA 时区参数，为空或无效时返回 UTC
+func parseLocationParam(violations *violations, field, value string) *time.Location {
+	if value == "" {
+		return time.UTC
+	}
+	// time.LoadLocation 把 Local 解析为服务器所在的时区，对客户端没有意义
+	loc, err := time.LoadLocation(value)
+	if err != nil || value == "Local" {
+		violations.add(field, errors.RuleTimezone, errors.MsgFieldTimezone)
+		return time.UTC
+	}
+	return loc
+}
+
+// parseTimeParam 解析 RFC 3339 格式的时间参数，为空时返回 nil
+func parseTimeParam(violations *violations, field, value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		violations.add(field, errors.RuleDateTime, errors.MsgFieldDateTime)
+		return nil
+	}
+	return &t
+}
+
+// joinValues 将允许的取值拼接为提示文本
+func joinValues[T ~string](values []T) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = string(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// violations 收集一次校验中违反的所有规则，消息按请求的语言生成
+type violations struct {
+	locale i18n.Locale
+	list   []errors.Violation
+}
+
+func newViolations(ctx context.Context) *violations {
+	return &violations{locale: i18n.FromContext(ctx)}
+}
+
+// add 记录一条违反的规则
+func (v *violations) add(field, rule string, key errors.MessageKey, args ...any) {
+	v.list = append(v.list, errors.Violation{
+		Field:   field,
+		Rule:    rule,
+		Message: errors.Detailf(key, v.locale, args...),
+	})
+}
+
+// err 没有违反任何规则时返回 nil
+func (v *violations) err() error {
+	if len(v.list) == 0 {
+		return nil
+	}
+	return errors.NewValidation(v.list...)
+}
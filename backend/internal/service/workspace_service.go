@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/Brower/backend/internal/repository"
+)
+
+// WorkspaceService 定义了工作区服务的接口，所有方法返回的错误都是 *errors.Error。
+// 不是成员的工作区和不存在的一样返回 ErrWorkspaceNotFound，角色不够时返回 ErrForbidden。
+// 工作区中的项目、待办事项和标签通过对应的服务访问，请求选择工作区后以工作区作为所有者
+type WorkspaceService interface {
+	// List 获取当前用户加入的工作区，带有当前用户的角色
+	List(ctx context.Context, userID string) (*models.WorkspaceListResponse, error)
+
+	// Create 创建工作区，创建者成为所有者
+	Create(ctx context.Context, userID string, req models.CreateWorkspaceRequest) (*models.WorkspaceResponse, error)
+
+	// Get 获取工作区，任何成员都可以查看
+	Get(ctx context.Context, userID, id string) (*models.WorkspaceResponse, error)
+
+	// Update 修改工作区的名称，需要 admin 角色
+	Update(ctx context.Context, userID, id string, req models.UpdateWorkspaceRequest) (*models.WorkspaceResponse, error)
+
+	// Delete 删除工作区和其中所有的数据，只有所有者可以删除
+	Delete(ctx context.Context, userID, id string) error
+
+	// ListMembers 获取工作区的成员，任何成员都可以查看
+	ListMembers(ctx context.Context, userID, id string) (*models.MemberListResponse, error)
+
+	// AddMember 添加成员，需要 admin 角色，添加管理员和所有者需要 owner 角色
+	AddMember(ctx context.Context, userID, id string, req models.AddMemberRequest) (*models.MemberResponse, error)
+
+	// UpdateMember 修改成员的角色，需要 admin 角色，涉及管理员和所有者时需要 owner 角色
+	UpdateMember(ctx context.Context, userID, id, memberID string, req models.UpdateMemberRequest) (*models.MemberResponse, error)
+
+	// RemoveMember 移除成员，需要 admin 角色，移除管理员和所有者需要 owner 角色；
+	// 任何成员都可以移除自己以退出工作区
+	RemoveMember(ctx context.Context, userID, id, memberID string) error
+}
+
+type workspaceService struct {
+	repo      repository.WorkspaceRepository
+	validator *WorkspaceValidator
+}
+
+// NewWorkspaceService 创建一个新的工作区服务
+func NewWorkspaceService(repo repository.WorkspaceRepository, validator *WorkspaceValidator) WorkspaceService {
+	return &workspaceService{
+		repo:      repo,
+		validator: validator,
+	}
+}
+
+// List 获取当前用户加入的工作区，按创建时间排序
+func (s *workspaceService) List(ctx context.Context, userID string) (*models.WorkspaceListResponse, error) {
+	workspaces, err := s.repo.ListWorkspaces(ctx, userID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+
+	response := &models.WorkspaceListResponse{Items: make([]models.WorkspaceResponse, len(workspaces))}
+	for i := range workspaces {
+		response.Items[i] = workspaces[i].ToResponse()
+	}
+	return response, nil
+}
+
+// Create 创建工作区，创建者成为所有者
+func (s *workspaceService) Create(ctx context.Context, userID string, req models.CreateWorkspaceRequest) (*models.WorkspaceResponse, error) {
+	if err := s.validator.ValidateCreate(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	workspace := &models.Workspace{
+		Name:      req.Name,
+		CreatedBy: userID,
+	}
+	if err := s.repo.CreateWorkspace(ctx, workspace); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	workspace.Role = models.WorkspaceOwner
+	response := workspace.ToResponse()
+	return &response, nil
+}
+
+// Get 获取工作区，Role 为当前用户的角色
+func (s *workspaceService) Get(ctx context.Context, userID, id string) (*models.WorkspaceResponse, error) {
+	if err := validateID(ctx, id); err != nil {
+		return nil, err
+	}
+	member, err := s.membership(ctx, id, userID, models.WorkspaceGuest)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.repo.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	workspace.Role = member.Role
+	response := workspace.ToResponse()
+	return &response, nil
+}
+
+// Update 修改工作区的名称，只修改请求中出现的字段
+func (s *workspaceService) Update(ctx context.Context, userID, id string, req models.UpdateWorkspaceRequest) (*models.WorkspaceResponse, error) {
+	if err := s.validator.ValidateUpdate(ctx, id, &req); err != nil {
+		return nil, err
+	}
+	member, err := s.membership(ctx, id, userID, models.WorkspaceAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.repo.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if req.Name != nil {
+		workspace.Name = *req.Name
+	}
+	if err := s.repo.UpdateWorkspace(ctx, workspace); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	workspace.Role = member.Role
+	response := workspace.ToResponse()
+	return &response, nil
+}
+
+// Delete 删除工作区，同时删除它的成员和以工作区为所有者的项目、待办事项和标签
+func (s *workspaceService) Delete(ctx context.Context, userID, id string) error {
+	if err := validateID(ctx, id); err != nil {
+		return err
+	}
+	if _, err := s.membership(ctx, id, userID, models.WorkspaceOwner); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteWorkspace(ctx, id); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// ListMembers 获取工作区的成员，按加入时间排序
+func (s *workspaceService) ListMembers(ctx context.Context, userID, id string) (*models.MemberListResponse, error) {
+	if err := validateID(ctx, id); err != nil {
+		return nil, err
+	}
+	if _, err := s.membership(ctx, id, userID, models.WorkspaceGuest); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, id)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := &models.MemberListResponse{Items: make([]models.MemberResponse, len(members))}
+	for i := range members {
+		response.Items[i] = members[i].ToResponse()
+	}
+	return response, nil
+}
+
+// AddMember 添加成员，未指定角色时为 member。
+// 仓库层无法确认用户是否存在，只要求用户 ID 是 UUID
+func (s *workspaceService) AddMember(ctx context.Context, userID, id string, req models.AddMemberRequest) (*models.MemberResponse, error) {
+	if err := s.validator.ValidateAddMember(ctx, id, &req); err != nil {
+		return nil, err
+	}
+	role, _ := models.ParseWorkspaceRole(req.Role)
+	actor, err := s.membership(ctx, id, userID, models.WorkspaceAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkManageRole(actor, role); err != nil {
+		return nil, err
+	}
+
+	member := &models.Member{
+		WorkspaceID: id,
+		UserID:      req.UserID,
+		Role:        role,
+	}
+	if err := s.repo.AddMember(ctx, member); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := member.ToResponse()
+	return &response, nil
+}
+
+// UpdateMember 修改成员的角色。把管理员或所有者改为其他角色、或者改为管理员或所有者，
+// 都需要 owner 角色；工作区的最后一个所有者不能降级
+func (s *workspaceService) UpdateMember(ctx context.Context, userID, id, memberID string, req models.UpdateMemberRequest) (*models.MemberResponse, error) {
+	if err := s.validator.ValidateUpdateMember(ctx, id, memberID, &req); err != nil {
+		return nil, err
+	}
+	role, _ := models.ParseWorkspaceRole(req.Role)
+	actor, err := s.membership(ctx, id, userID, models.WorkspaceAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.GetMember(ctx, id, memberID)
+	if err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	if err := checkManageRole(actor, member.Role); err != nil {
+		return nil, err
+	}
+	if err := checkManageRole(actor, role); err != nil {
+		return nil, err
+	}
+	if member.Role == models.WorkspaceOwner && role != models.WorkspaceOwner {
+		if err := s.checkOtherOwner(ctx, id, memberID); err != nil {
+			return nil, err
+		}
+	}
+
+	member.Role = role
+	if err := s.repo.UpdateMember(ctx, member); err != nil {
+		return nil, wrapRepositoryError(err)
+	}
+	response := member.ToResponse()
+	return &response, nil
+}
+
+// RemoveMember 移除成员。成员移除自己时不检查角色，但工作区的最后一个所有者不能移除
+func (s *workspaceService) RemoveMember(ctx context.Context, userID, id, memberID string) error {
+	if err := s.validator.ValidateMember(ctx, id, memberID); err != nil {
+		return err
+	}
+
+	need := models.WorkspaceAdmin
+	if memberID == userID {
+		need = models.WorkspaceGuest
+	}
+	actor, err := s.membership(ctx, id, userID, need)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.repo.GetMember(ctx, id, memberID)
+	if err != nil {
+		return wrapRepositoryError(err)
+	}
+	if memberID != userID {
+		if err := checkManageRole(actor, member.Role); err != nil {
+			return err
+		}
+	}
+	if member.Role == models.WorkspaceOwner {
+		if err := s.checkOtherOwner(ctx, id, memberID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.RemoveMember(ctx, id, memberID); err != nil {
+		return wrapRepositoryError(err)
+	}
+	return nil
+}
+
+// membership 返回用户在工作区中的成员身份，不是成员时返回 ErrWorkspaceNotFound，
+// 角色低于 need 时返回 ErrForbidden
+func (s *workspaceService) membership(ctx context.Context, workspaceID, userID string, need models.WorkspaceRole) (*models.Member, error) {
+	member, err := s.repo.GetMember(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrMemberNotFound) {
+			err = repository.ErrWorkspaceNotFound
+		}
+		return nil, wrapRepositoryError(err)
+	}
+	if !member.Role.Allows(need) {
+		return nil, errors.NewWithKey(errors.ErrForbidden, errors.MsgWorkspaceRole)
+	}
+	return member, nil
+}
+
+// checkOtherOwner 确认除 userID 外工作区还有其他所有者。
+// 先查询再修改，同时降级多个所有者时仍可能留下没有所有者的工作区
+func (s *workspaceService) checkOtherOwner(ctx context.Context, workspaceID, userID string) error {
+	members, err := s.repo.ListMembers(ctx, workspaceID)
+	if err != nil {
+		return wrapRepositoryError(err)
+	}
+	for _, member := range members {
+		if member.Role == models.WorkspaceOwner && member.UserID != userID {
+			return nil
+		}
+	}
+	return errors.New(errors.ErrLastWorkspaceOwner, fmt.Errorf("工作区 %s 的最后一个所有者 %s", workspaceID, userID))
+}
+
+// checkManageRole 确认 actor 可以授予、修改或移除 role 角色：管理员和所有者只能由所有者管理
+func checkManageRole(actor *models.Member, role models.WorkspaceRole) error {
+	if role.Allows(models.WorkspaceAdmin) && actor.Role != models.WorkspaceOwner {
+		return errors.NewWithKey(errors.ErrForbidden, errors.MsgWorkspaceAdmin)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Brower/backend/internal/errors"
+	"github.com/Brower/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxWorkspaceNameLength 工作区名称的最大长度
+const maxWorkspaceNameLength = 100
+
+// WorkspaceValidator 校验并规范化工作区和成员请求
+type WorkspaceValidator struct{}
+
+// NewWorkspaceValidator 创建工作区校验器
+func NewWorkspaceValidator() *WorkspaceValidator {
+	return &WorkspaceValidator{}
+}
+
+// ValidateCreate 校验创建工作区的请求，去掉名称首尾空白
+func (v *WorkspaceValidator) ValidateCreate(ctx context.Context, req *models.CreateWorkspaceRequest) error {
+	violations := newViolations(ctx)
+	req.Name = validateNameInto(violations, req.Name, maxWorkspaceNameLength)
+	return violations.err()
+}
+
+// ValidateUpdate 校验修改工作区的请求，只校验请求中出现的字段
+func (v *WorkspaceValidator) ValidateUpdate(ctx context.Context, id string, req *models.UpdateWorkspaceRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, id)
+	if req.Name != nil {
+		name := validateNameInto(violations, *req.Name, maxWorkspaceNameLength)
+		req.Name = &name
+	}
+	return violations.err()
+}
+
+// ValidateAddMember 校验添加工作区成员的请求。用户 ID 统一为小写的 UUID，未指定角色时为 member
+func (v *WorkspaceValidator) ValidateAddMember(ctx context.Context, workspaceID string, req *models.AddMemberRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, workspaceID)
+	req.UserID = memberUserID(violations, "userId", req.UserID)
+
+	req.Role = strings.TrimSpace(req.Role)
+	if req.Role == "" {
+		req.Role = string(models.WorkspaceMember)
+	}
+	validateWorkspaceRoleInto(violations, req.Role)
+	return violations.err()
+}
+
+// ValidateUpdateMember 校验修改工作区成员角色的请求
+func (v *WorkspaceValidator) ValidateUpdateMember(ctx context.Context, workspaceID, userID string, req *models.UpdateMemberRequest) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, workspaceID)
+	memberUserID(violations, "userId", userID)
+
+	req.Role = strings.TrimSpace(req.Role)
+	if req.Role == "" {
+		violations.add("role", errors.RuleRequired, errors.MsgFieldRequired)
+	} else {
+		validateWorkspaceRoleInto(violations, req.Role)
+	}
+	return violations.err()
+}
+
+// ValidateMember 校验路径中的工作区 ID 和成员的用户 ID
+func (v *WorkspaceValidator) ValidateMember(ctx context.Context, workspaceID, userID string) error {
+	violations := newViolations(ctx)
+	validateIDInto(violations, workspaceID)
+	memberUserID(violations, "userId", userID)
+	return violations.err()
+}
+
+// memberUserID 校验工作区成员的用户 ID，返回小写的 UUID
+func memberUserID(violations *violations, field, userID string) string {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		violations.add(field, errors.RuleRequired, errors.MsgFieldRequired)
+		return userID
+	}
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		violations.add(field, errors.RuleUUID, errors.MsgFieldUUID)
+		return userID
+	}
+	return id.String()
+}
+
+// validateWorkspaceRoleInto 校验工作区角色的名称
+func validateWorkspaceRoleInto(violations *violations, role string) {
+	if _, ok := models.ParseWorkspaceRole(role); !ok {
+		violations.add("role", errors.RuleEnum, errors.MsgFieldEnum, joinValues(models.WorkspaceRoles))
+	}
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Brower/backend/internal/logger"
+	"github.com/Brower/backend/internal/repository"
+	"go.uber.org/zap"
+)
+
+// 未配置时使用的默认值
+const (
+	defaultCleanupInterval  = time.Minute
+	defaultCleanupBatchSize = 100
+)
+
+// Cleaner 后台删除已脱离待办事项的附件：先删除文件，再删除元数据。
+// 删除文件失败的附件保留元数据，下一轮重试；多个实例同时清理同一个附件是安全的
+type Cleaner struct {
+	repo      repository.AttachmentRepository
+	store     BlobStore
+	interval  time.Duration
+	batchSize int
+	logger    *zap.Logger
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewCleaner 创建附件清理任务，interval 不大于 0 时使用默认值
+func NewCleaner(repo repository.AttachmentRepository, store BlobStore, interval time.Duration) *Cleaner {
+	if interval <= 0 {
+		interval = defaultCleanupInterval
+	}
+	return &Cleaner{
+		repo:      repo,
+		store:     store,
+		interval:  interval,
+		batchSize: defaultCleanupBatchSize,
+		logger:    logger.Log.With(zap.String("component", "AttachmentCleaner")),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start 在后台启动清理循环，Stop 后结束
+func (c *Cleaner) Start() {
+	c.logger.Info("启动附件清理任务",
+		zap.String("store", c.store.Name()),
+		zap.Duration("interval", c.interval))
+
+	go c.run()
+}
+
+// Stop 停止清理循环，并等待正在进行的清理结束
+func (c *Cleaner) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		<-c.done
+		c.logger.Info("附件清理任务已停止")
+	})
+}
+
+// run 清理循环。一批附件全部清理成功且领满时说明还有积压，不等待下一个间隔直接继续
+func (c *Cleaner) run() {
+	defer close(c.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-c.stop
+		cancel()
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-timer.C:
+		}
+
+		purged, err := c.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			c.logger.Error("清理附件失败", zap.Error(err))
+		}
+
+		wait := c.interval
+		if err == nil && purged >= c.batchSize {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// RunOnce 清理一批已脱离待办事项的附件，返回清理的数量
+func (c *Cleaner) RunOnce(ctx context.Context) (int, error) {
+	attachments, err := c.repo.ListDetachedAttachments(ctx, c.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("获取待清理的附件失败: %w", err)
+	}
+
+	purged := 0
+	var failed error
+	for _, attachment := range attachments {
+		if err := c.store.Delete(ctx, attachment.StorageKey); err != nil {
+			c.logger.Warn("删除附件文件失败，下次重试",
+				zap.String("attachmentID", attachment.ID), zap.Error(err))
+			failed = err
+			continue
+		}
+		if err := c.repo.PurgeAttachment(ctx, attachment.ID); err != nil {
+			c.logger.Warn("删除附件元数据失败，下次重试",
+				zap.String("attachmentID", attachment.ID), zap.Error(err))
+			failed = err
+			continue
+		}
+		purged++
+	}
+	if purged > 0 {
+		c.logger.Debug("清理附件", zap.Int("count", purged))
+	}
+	return purged, failed
+}
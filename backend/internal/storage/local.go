@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/logger"
+)
+
+// FilesPath 本地存储下载路由的前缀
+const FilesPath = "/files"
+
+// defaultLocalPath 未配置 local.path 时保存文件的目录
+const defaultLocalPath = "data/attachments"
+
+var (
+	// ErrInvalidSignature 下载链接的签名无效
+	ErrInvalidSignature = errors.New("下载链接的签名无效")
+	// ErrURLExpired 下载链接已过期
+	ErrURLExpired = errors.New("下载链接已过期")
+)
+
+// LocalStore 把文件保存在本地目录中，文件通过 FilesPath 路由下载。
+// 下载链接的查询参数为 expires（Unix 秒）、name、type 和 signature，
+// signature 是键和其余参数的 HMAC-SHA256 签名，修改任何一个参数都会使链接失效
+type LocalStore struct {
+	root    string
+	key     []byte
+	baseURL string
+}
+
+// NewLocalStore 创建本地文件存储，目录不存在时自动创建
+func NewLocalStore(cfg config.LocalStoreConfig) (*LocalStore, error) {
+	root := cfg.Path
+	if root == "" {
+		root = defaultLocalPath
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("创建附件目录 %s 失败: %w", root, err)
+	}
+
+	key := []byte(cfg.SigningKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("生成下载链接的签名密钥失败: %w", err)
+		}
+		logger.Log.Warn("未配置 attachments.local.signing_key，使用随机密钥，重启后之前的下载链接失效")
+	}
+
+	return &LocalStore{
+		root:    root,
+		key:     key,
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+	}, nil
+}
+
+// Name 存储名称
+func (s *LocalStore) Name() string {
+	return "local"
+}
+
+// Put 先写入临时文件再重命名，读取方不会看到写了一半的文件
+func (s *LocalStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("创建附件目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入附件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入附件失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("保存附件失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除文件，文件不存在时返回 nil
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("删除附件失败: %w", err)
+	}
+	return nil
+}
+
+// SignedURL 返回指向 FilesPath 路由的签名链接
+func (s *LocalStore) SignedURL(ctx context.Context, key string, opts URLOptions) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(opts.Expires.Unix(), 10))
+	query.Set("name", opts.Filename)
+	query.Set("type", opts.ContentType)
+	query.Set("signature", s.sign(key, query))
+
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.baseURL + FilesPath + "/" + strings.Join(segments, "/") + "?" + query.Encode(), nil
+}
+
+// Verify 校验下载链接的查询参数，返回链接中的文件名和类型
+func (s *LocalStore) Verify(key string, query url.Values, now time.Time) (URLOptions, error) {
+	if err := validateKey(key); err != nil {
+		return URLOptions{}, ErrInvalidSignature
+	}
+
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil {
+		return URLOptions{}, ErrInvalidSignature
+	}
+	expected, _ := hex.DecodeString(s.sign(key, query))
+	if !hmac.Equal(signature, expected) {
+		return URLOptions{}, ErrInvalidSignature
+	}
+
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return URLOptions{}, ErrInvalidSignature
+	}
+	if now.Unix() > expires {
+		return URLOptions{}, ErrURLExpired
+	}
+
+	return URLOptions{
+		Filename:    query.Get("name"),
+		ContentType: query.Get("type"),
+		Expires:     time.Unix(expires, 0),
+	}, nil
+}
+
+// Open 打开文件，调用方负责关闭。文件不存在时返回 fs.ErrNotExist
+func (s *LocalStore) Open(key string) (*os.File, error) {
+	if err := validateKey(key); err != nil {
+		return nil, fs.ErrNotExist
+	}
+	return os.Open(s.path(key))
+}
+
+// path 返回键对应的文件路径
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+// sign 计算键和 expires、name、type 参数的签名，各部分加引号后以换行分隔，参数中的换行不会与分隔符混淆
+func (s *LocalStore) sign(key string, query url.Values) string {
+	mac := hmac.New(sha256.New, s.key)
+	for _, part := range []string{key, query.Get("expires"), query.Get("name"), query.Get("type")} {
+		mac.Write([]byte(strconv.Quote(part)))
+		mac.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
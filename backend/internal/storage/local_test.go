@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+	"github.com/Brower/backend/internal/logger"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// invalidKeys 不能使用的键，包括各种试图跳出存储目录的写法
+var invalidKeys = []string{
+	"",
+	".",
+	"..",
+	"../secret",
+	"../../etc/passwd",
+	"user-1/../../secret",
+	"user-1/./a.txt",
+	"user-1/..",
+	"/etc/passwd",
+	"user-1//a.txt",
+	"user-1/",
+	`..\secret`,
+	`user-1\..\..\secret`,
+}
+
+// newTestLocalStore 在临时目录的 files 子目录中创建本地存储，返回存储和临时目录
+func newTestLocalStore(t *testing.T) (*LocalStore, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	s, err := NewLocalStore(config.LocalStoreConfig{
+		Path:       filepath.Join(dir, "files"),
+		SigningKey: "local-signing-key",
+		BaseURL:    "https://api.example.com/",
+	})
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+	return s, dir
+}
+
+func TestLocalStorePutOpenDelete(t *testing.T) {
+	s, _ := newTestLocalStore(t)
+	ctx := context.Background()
+	key := "user-1/todo-1/a b.txt"
+
+	if err := s.Put(ctx, key, []byte("hello"), "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	f, err := s.Open(key)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	data, _ := io.ReadAll(f)
+	f.Close()
+	if string(data) != "hello" {
+		t.Errorf("Open() content = %q, want hello", data)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Open(key); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open() after Delete() error = %v, want fs.ErrNotExist", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() missing file error = %v", err)
+	}
+}
+
+func TestLocalStoreRejectsPathTraversal(t *testing.T) {
+	s, dir := newTestLocalStore(t)
+	ctx := context.Background()
+
+	// 存储目录之外的文件不能被读取或删除
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	for _, key := range invalidKeys {
+		if err := s.Put(ctx, key, []byte("x"), "text/plain"); err == nil {
+			t.Errorf("Put(%q) error = nil, want invalid key", key)
+		}
+		if err := s.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) error = nil, want invalid key", key)
+		}
+		if f, err := s.Open(key); !errors.Is(err, fs.ErrNotExist) {
+			if f != nil {
+				f.Close()
+			}
+			t.Errorf("Open(%q) error = %v, want fs.ErrNotExist", key, err)
+		}
+		if _, err := s.SignedURL(ctx, key, URLOptions{Expires: time.Now().Add(time.Minute)}); err == nil {
+			t.Errorf("SignedURL(%q) error = nil, want invalid key", key)
+		}
+		if _, err := s.Verify(key, url.Values{}, time.Now()); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Verify(%q) error = %v, want ErrInvalidSignature", key, err)
+		}
+	}
+
+	if data, err := os.ReadFile(secret); err != nil || string(data) != "secret" {
+		t.Errorf("file outside the store = %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("temp dir entries = %v, want files and secret only", entries)
+	}
+}
+
+func TestLocalStoreSignedURL(t *testing.T) {
+	s, _ := newTestLocalStore(t)
+	key := "user-1/todo-1/报告.txt"
+	expires := time.Date(2026, 5, 1, 12, 15, 0, 0, time.UTC)
+	opts := URLOptions{Filename: "报告.txt", ContentType: "text/plain", Expires: expires}
+
+	signed, err := s.SignedURL(context.Background(), key, opts)
+	if err != nil {
+		t.Fatalf("SignedURL() error = %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if !strings.HasPrefix(signed, "https://api.example.com"+FilesPath+"/") {
+		t.Errorf("SignedURL() = %q, want prefix https://api.example.com%s/", signed, FilesPath)
+	}
+	if got := strings.TrimPrefix(u.Path, FilesPath+"/"); got != key {
+		t.Errorf("key in URL = %q, want %q", got, key)
+	}
+
+	got, err := s.Verify(key, u.Query(), expires)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if got.Filename != opts.Filename || got.ContentType != opts.ContentType || !got.Expires.Equal(expires) {
+		t.Errorf("Verify() = %+v, want %+v", got, opts)
+	}
+
+	tamper := func(name, value string) url.Values {
+		query := u.Query()
+		query.Set(name, value)
+		return query
+	}
+	other, _ := NewLocalStore(config.LocalStoreConfig{Path: t.TempDir(), SigningKey: "other-key"})
+
+	tests := []struct {
+		name  string
+		store *LocalStore
+		key   string
+		query url.Values
+		at    time.Time
+		want  error
+	}{
+		{"expired", s, key, u.Query(), expires.Add(time.Second), ErrURLExpired},
+		{"expires extended", s, key, tamper("expires", "4102444800"), expires, ErrInvalidSignature},
+		{"name changed", s, key, tamper("name", "evil.html"), expires, ErrInvalidSignature},
+		{"type changed", s, key, tamper("type", "text/html"), expires, ErrInvalidSignature},
+		{"signature changed", s, key, tamper("signature", strings.Repeat("0", 64)), expires, ErrInvalidSignature},
+		{"signature not hex", s, key, tamper("signature", "zz"), expires, ErrInvalidSignature},
+		{"signature missing", s, key, tamper("signature", ""), expires, ErrInvalidSignature},
+		{"other key", s, "user-1/todo-1/other.txt", u.Query(), expires, ErrInvalidSignature},
+		{"other signing key", other, key, u.Query(), expires, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.store.Verify(tt.key, tt.query, tt.at); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
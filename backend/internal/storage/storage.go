@@ -0,0 +1,66 @@
+// Package storage 实现保存附件文件的 BlobStore：本地文件系统和 S3 兼容的对象存储。
+//
+// 文件只能通过有效期有限的签名链接下载。本地存储的链接指向服务自身的 /files 路由，
+// 由 LocalStore.Verify 校验签名；S3 存储的链接是对象存储的预签名地址，下载不经过服务。
+// 附件的元数据保存在存储后端，删除待办事项或附件时只解除元数据的关联，
+// 文件由 Cleaner 在后台删除，见 repository.AttachmentRepository。
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brower/backend/internal/config"
+)
+
+// BlobStore 文件存储
+type BlobStore interface {
+	// Name 存储名称，用于日志
+	Name() string
+
+	// Put 保存文件，键已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+
+	// Delete 删除文件，文件不存在时返回 nil
+	Delete(ctx context.Context, key string) error
+
+	// SignedURL 返回在 opts.Expires 之前有效的下载链接
+	SignedURL(ctx context.Context, key string, opts URLOptions) (string, error)
+}
+
+// URLOptions 下载链接的参数
+type URLOptions struct {
+	// Filename 下载时 Content-Disposition 中的文件名
+	Filename string
+	// ContentType 下载时的 Content-Type
+	ContentType string
+	// Expires 链接的过期时间
+	Expires time.Time
+}
+
+// FromConfig 根据 attachments 配置创建文件存储
+func FromConfig(cfg config.AttachmentConfig) (BlobStore, error) {
+	switch cfg.Store {
+	case "", "local":
+		return NewLocalStore(cfg.Local)
+	case "s3":
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("不支持的附件存储: %s", cfg.Store)
+	}
+}
+
+// validateKey 检查键只由斜杠分隔的非空段组成，且不包含 . 和 .. 段
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("无效的文件键: %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("无效的文件键: %q", key)
+		}
+	}
+	return nil
+}
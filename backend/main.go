@@ -1,16 +1,34 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// 内嵌时区数据库，精简的容器镜像中可能没有 /usr/share/zoneinfo
+	_ "time/tzdata"
+
 	"github.com/Brower/backend/internal/config"
 	"github.com/Brower/backend/internal/handler"
 	"github.com/Brower/backend/internal/logger"
 	"github.com/Brower/backend/internal/middleware"
+	"github.com/Brower/backend/internal/migrate"
+	"github.com/Brower/backend/internal/notify"
+	"github.com/Brower/backend/internal/reminder"
 	"github.com/Brower/backend/internal/repository"
 	"github.com/Brower/backend/internal/service"
+	"github.com/Brower/backend/internal/storage"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// shutdownTimeout 优雅关闭时等待进行中请求完成的最长时间
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	// 加载配置
 	cfg, err := config.LoadConfig("./config")
@@ -24,8 +42,23 @@ func main() {
 	}
 	defer logger.Log.Sync()
 
+	// migrate 子命令：执行数据库迁移后退出
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate.RunCommand(context.Background(), cfg, os.Args[2:], os.Stdout); err != nil {
+			logger.Fatal("数据库迁移失败", zap.Error(err))
+		}
+		return
+	}
+
 	logger.Info("应用启动", zap.String("环境", cfg.Server.Mode))
 
+	// 启动时自动执行迁移
+	if cfg.Database.AutoMigrate && cfg.Database.Type == repository.BackendPostgres {
+		if err := migrate.RunCommand(context.Background(), cfg, []string{"up"}, os.Stdout); err != nil {
+			logger.Fatal("数据库迁移失败", zap.Error(err))
+		}
+	}
+
 	// 设置 Gin 模式
 	gin.SetMode(cfg.Server.Mode)
 
@@ -34,6 +67,8 @@ func main() {
 
 	// 添加中间件
 	r.Use(gin.Recovery())            // 恢复中间件
+	r.Use(middleware.RequestID())    // 请求 ID 中间件
+	r.Use(middleware.Locale())       // 语言中间件
 	r.Use(middleware.ErrorHandler()) // 错误处理中间件
 	r.Use(middleware.CORS(cfg))      // CORS 中间件
 
@@ -45,28 +80,214 @@ func main() {
 		})
 	})
 
-	// 初始化仓储层
-	todoRepo, err := repository.NewSupabaseTodoRepository(cfg)
+	// 初始化仓储层，存储后端由 database.type 决定
+	todoRepo, err := repository.NewTodoRepository(cfg)
 	if err != nil {
-		logger.Fatal("无法初始化 Todo 仓储层", zap.Error(err))
+		logger.Fatal("无法初始化 Todo 仓储层",
+			zap.String("database.type", cfg.Database.Type),
+			zap.Error(err))
 	}
+	defer closeRepository(todoRepo)
+	logger.Info("存储后端已就绪", zap.String("database.type", cfg.Database.Type))
 
 	// 初始化服务层
-	todoService := service.NewTodoService(todoRepo)
+	todoValidator := service.NewTodoValidator(cfg.Validation.Todo)
+	todoService := service.NewTodoService(todoRepo, todoValidator)
 
-	// 初始化处理器
-	todoHandler := handler.NewTodoHandler(todoService)
+	// 存储后端支持工作区时，认证中间件同时确认请求所选工作区的成员身份
+	workspaceRepo, _ := todoRepo.(repository.WorkspaceRepository)
+	// 存储后端支持共享时，被共享的用户按角色访问子任务等待办事项的附属数据，不支持时为 nil
+	shareRepo, _ := todoRepo.(repository.ShareRepository)
 
 	// 创建 API 路由组，应用认证中间件
 	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware(cfg)) // 添加认证中间件
+	api.Use(middleware.AuthMiddleware(cfg, workspaceRepo)) // 添加认证中间件
+
+	// 注册路由：/api/v1 下为 REST 路由，/api 下保留旧版 POST 路由
+	v1 := api.Group("/v1")
+	var legacySunset time.Time
+	if cfg.Server.LegacySunset != "" {
+		legacySunset, err = time.Parse(time.DateOnly, cfg.Server.LegacySunset)
+		if err != nil {
+			logger.Fatal("server.legacy_sunset 格式错误", zap.String("legacy_sunset", cfg.Server.LegacySunset), zap.Error(err))
+		}
+	}
+	handler.NewTodoHandler(todoService).RegisterLegacyRoutes(api, v1.BasePath()+"/todos", legacySunset)
+
+	// scopes 待办事项、项目和标签等数据路由所在的路由组。/api/v1 下访问自己的数据，
+	// 或者 X-Workspace-ID 请求头选择的工作区；存储后端支持工作区时，
+	// /api/v1/workspaces/:workspaceId 下访问路径中的工作区
+	scopes := []*gin.RouterGroup{v1}
+	if workspaceRepo != nil {
+		workspaceService := service.NewWorkspaceService(workspaceRepo, service.NewWorkspaceValidator())
+		handler.NewWorkspaceHandler(workspaceService).RegisterRoutes(v1)
+		scopes = append(scopes, v1.Group("/workspaces/:workspaceId"))
+	}
+	// 处理器按路由组记录生成 Location 的路径，每个路由组使用单独的处理器
+	for _, scope := range scopes {
+		handler.NewTodoHandler(todoService).RegisterRoutes(scope)
+	}
+
+	// 站内通知需要存储后端支持，不支持时不注册相关路由。通知总是发给用户自己，不按工作区注册
+	notificationRepo, _ := todoRepo.(repository.NotificationRepository)
+	if notificationRepo != nil {
+		notificationService := service.NewNotificationService(notificationRepo, todoValidator)
+		handler.NewNotificationHandler(notificationService).RegisterRoutes(v1)
+	}
+
+	// 标签同样需要存储后端支持
+	if tagRepo, _ := todoRepo.(repository.TagRepository); tagRepo != nil {
+		tagService := service.NewTagService(tagRepo, service.NewTagValidator())
+		for _, scope := range scopes {
+			handler.NewTagHandler(tagService).RegisterRoutes(scope)
+		}
+	}
 
-	// 注册路由
-	todoHandler.RegisterRoutes(api)
+	// 项目同样需要存储后端支持
+	if projectRepo, _ := todoRepo.(repository.ProjectRepository); projectRepo != nil {
+		projectService := service.NewProjectService(projectRepo, service.NewProjectValidator())
+		for _, scope := range scopes {
+			handler.NewProjectHandler(projectService).RegisterRoutes(scope)
+		}
+	}
+
+	// 子任务同样需要存储后端支持
+	if subtaskRepo, _ := todoRepo.(repository.SubtaskRepository); subtaskRepo != nil {
+		subtaskService := service.NewSubtaskService(todoRepo, subtaskRepo, shareRepo, service.NewSubtaskValidator(todoValidator))
+		for _, scope := range scopes {
+			handler.NewSubtaskHandler(subtaskService).RegisterRoutes(scope)
+		}
+	}
+
+	// 评论同样需要存储后端支持
+	if commentRepo, _ := todoRepo.(repository.CommentRepository); commentRepo != nil {
+		commentService := service.NewCommentService(todoRepo, commentRepo, shareRepo, service.NewCommentValidator(todoValidator))
+		for _, scope := range scopes {
+			handler.NewCommentHandler(commentService).RegisterRoutes(scope)
+		}
+	}
+
+	// 共享同样需要存储后端支持，配置了邮件服务器时通过邮件发送邀请
+	if shareRepo != nil {
+		shareService := service.NewShareService(shareRepo, service.NewShareValidator(), newInvitationMailer(cfg), cfg.Sharing)
+		for _, scope := range scopes {
+			handler.NewShareHandler(shareService).RegisterRoutes(scope)
+		}
+	}
+
+	// 附件需要启用并且存储后端支持，清理任务在关闭仓储层之前停止
+	if cleaner := registerAttachments(cfg, r, scopes, todoRepo, shareRepo); cleaner != nil {
+		cleaner.Start()
+		defer cleaner.Stop()
+	}
+
+	// 启动提醒调度器，在关闭仓储层之前停止
+	if scheduler := newReminderScheduler(cfg, todoRepo, notificationRepo); scheduler != nil {
+		scheduler.Start()
+		defer scheduler.Stop()
+	}
 
 	// 启动服务器
-	logger.Infof("服务器启动在 %s", cfg.GetServerAddress())
-	if err := r.Run(cfg.GetServerAddress()); err != nil {
-		logger.Fatal("服务器启动失败", zap.Error(err))
+	srv := &http.Server{
+		Addr:    cfg.GetServerAddress(),
+		Handler: r,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Infof("服务器启动在 %s", cfg.GetServerAddress())
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("服务器启动失败", zap.Error(err))
+		}
+	case <-ctx.Done():
+		logger.Info("收到退出信号，开始关闭服务器")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Error("服务器关闭失败", zap.Error(err))
+		}
+	}
+}
+
+// newReminderScheduler 根据 reminders 配置创建提醒调度器，未启用或存储后端不支持时返回 nil
+func newReminderScheduler(cfg *config.Config, repo repository.TodoRepository, notifications repository.NotificationRepository) *reminder.Scheduler {
+	if !cfg.Reminders.Enabled {
+		return nil
+	}
+
+	reminderRepo, ok := repo.(repository.ReminderRepository)
+	if !ok {
+		logger.Warn("存储后端不支持提醒，提醒调度器未启动", zap.String("database.type", cfg.Database.Type))
+		return nil
+	}
+
+	notifiers, err := notify.FromConfig(cfg, notifications)
+	if err != nil {
+		logger.Fatal("无法初始化通知渠道", zap.Error(err))
+	}
+	if len(notifiers) == 0 {
+		logger.Warn("没有启用任何通知渠道，提醒调度器未启动")
+		return nil
+	}
+
+	return reminder.NewScheduler(reminderRepo, notifiers, cfg.Reminders)
+}
+
+// newInvitationMailer 使用 reminders.smtp 配置的邮件服务器发送邀请，未配置时返回 nil
+func newInvitationMailer(cfg *config.Config) service.InvitationMailer {
+	if cfg.Reminders.SMTP.Host == "" || cfg.Reminders.SMTP.From == "" {
+		return nil
+	}
+	return notify.NewSMTPNotifier(cfg.Reminders.SMTP, nil)
+}
+
+// registerAttachments 根据 attachments 配置在 scopes 的每个路由组下注册附件路由，
+// 本地存储时同时注册下载路由，返回附件清理任务；未启用或存储后端不支持时返回 nil。
+// shares 为 nil 时只有所有者能访问附件
+func registerAttachments(cfg *config.Config, r *gin.Engine, scopes []*gin.RouterGroup, repo repository.TodoRepository, shares repository.ShareRepository) *storage.Cleaner {
+	if !cfg.Attachments.Enabled {
+		return nil
+	}
+
+	attachmentRepo, ok := repo.(repository.AttachmentRepository)
+	if !ok {
+		logger.Warn("存储后端不支持附件，附件路由未注册", zap.String("database.type", cfg.Database.Type))
+		return nil
+	}
+
+	store, err := storage.FromConfig(cfg.Attachments)
+	if err != nil {
+		logger.Fatal("无法初始化附件存储", zap.Error(err))
+	}
+	if localStore, ok := store.(*storage.LocalStore); ok {
+		handler.NewFileHandler(localStore).RegisterRoutes(r)
+	}
+
+	attachmentService := service.NewAttachmentService(repo, attachmentRepo, shares, store, service.NewAttachmentValidator(), cfg.Attachments)
+	for _, scope := range scopes {
+		handler.NewAttachmentHandler(attachmentService).RegisterRoutes(scope)
+	}
+
+	return storage.NewCleaner(attachmentRepo, store, cfg.Attachments.CleanupInterval)
+}
+
+// closeRepository 关闭需要释放资源的仓储层，例如连接池或内存快照
+func closeRepository(repo repository.TodoRepository) {
+	closer, ok := repo.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		logger.Error("关闭 Todo 仓储层失败", zap.Error(err))
+		return
 	}
+	logger.Info("Todo 仓储层已关闭")
 }
@@ -0,0 +1,3 @@
+-- 删除 todos 表及其触发器函数
+DROP TABLE IF EXISTS todos;
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,6 @@
+-- 删除 002 创建的索引
+DROP INDEX IF EXISTS idx_todos_completed_created_at;
+DROP INDEX IF EXISTS idx_todos_title_trgm;
+
+-- 恢复标题列的默认统计信息
+ALTER TABLE todos ALTER COLUMN title SET STATISTICS -1;
@@ -0,0 +1,4 @@
+-- 列表分页按 (排序字段, id) 做键集分页，为每个排序字段创建按用户划分的复合索引
+CREATE INDEX IF NOT EXISTS idx_todos_user_created_at ON todos(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_todos_user_updated_at ON todos(user_id, updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_todos_user_title ON todos(user_id, title, id);
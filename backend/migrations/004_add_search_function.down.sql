@@ -0,0 +1,2 @@
+-- 删除 004 创建的搜索函数
+DROP FUNCTION IF EXISTS search_todos(UUID, TEXT, BOOLEAN, INTEGER, REAL, UUID);
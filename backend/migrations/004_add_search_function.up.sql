@@ -0,0 +1,49 @@
+-- 按相关度搜索待办事项，PostgreSQL 存储后端直接调用，Supabase 通过 /rest/v1/rpc/search_todos 调用。
+-- 打分规则与 internal/search 包中的纯 Go 实现一致：
+--   标题包含查询串（忽略大小写）得分为 1 + similarity，否则得分为 similarity；
+--   ILIKE 和 % 运算符都可以使用 002 创建的 idx_todos_title_trgm 索引。
+-- 结果按 (score, id) 倒序排列，p_after_score/p_after_id 为上一页最后一条结果的游标。
+-- total 为匹配的总条数，与游标无关。
+CREATE OR REPLACE FUNCTION search_todos(
+    p_user_id UUID,
+    p_query TEXT,
+    p_completed BOOLEAN DEFAULT NULL,
+    p_limit INTEGER DEFAULT 50,
+    p_after_score REAL DEFAULT NULL,
+    p_after_id UUID DEFAULT NULL
+)
+RETURNS TABLE (
+    id UUID,
+    user_id UUID,
+    title TEXT,
+    completed BOOLEAN,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    score REAL,
+    total BIGINT
+)
+LANGUAGE sql
+STABLE
+AS $$
+    WITH matched AS (
+        SELECT
+            t.*,
+            ((CASE WHEN strpos(lower(t.title), lower(p_query)) > 0 THEN 1 ELSE 0 END)
+                + similarity(t.title, p_query))::REAL AS score
+        FROM todos t
+        WHERE t.user_id = p_user_id
+          AND (p_completed IS NULL OR t.completed = p_completed)
+          AND (
+              t.title ILIKE '%' || replace(replace(replace(p_query, '\', '\\'), '%', '\%'), '_', '\_') || '%'
+              OR t.title % p_query
+          )
+    )
+    SELECT m.id, m.user_id, m.title, m.completed, m.created_at, m.updated_at, m.score,
+           (SELECT COUNT(*) FROM matched)
+    FROM matched m
+    WHERE p_after_score IS NULL OR (m.score, m.id) < (p_after_score, p_after_id)
+    ORDER BY m.score DESC, m.id DESC
+    LIMIT p_limit;
+$$;
+
+COMMENT ON FUNCTION search_todos(UUID, TEXT, BOOLEAN, INTEGER, REAL, UUID) IS '按标题模糊搜索待办事项，结果按相关度排序并分页';
@@ -0,0 +1,52 @@
+-- 恢复 004 中返回独立列的 search_todos 函数
+DROP FUNCTION IF EXISTS search_todos(UUID, TEXT, BOOLEAN, INTEGER, REAL, UUID);
+
+CREATE FUNCTION search_todos(
+    p_user_id UUID,
+    p_query TEXT,
+    p_completed BOOLEAN DEFAULT NULL,
+    p_limit INTEGER DEFAULT 50,
+    p_after_score REAL DEFAULT NULL,
+    p_after_id UUID DEFAULT NULL
+)
+RETURNS TABLE (
+    id UUID,
+    user_id UUID,
+    title TEXT,
+    completed BOOLEAN,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    score REAL,
+    total BIGINT
+)
+LANGUAGE sql
+STABLE
+AS $$
+    WITH matched AS (
+        SELECT
+            t.*,
+            ((CASE WHEN strpos(lower(t.title), lower(p_query)) > 0 THEN 1 ELSE 0 END)
+                + similarity(t.title, p_query))::REAL AS score
+        FROM todos t
+        WHERE t.user_id = p_user_id
+          AND (p_completed IS NULL OR t.completed = p_completed)
+          AND (
+              t.title ILIKE '%' || replace(replace(replace(p_query, '\', '\\'), '%', '\%'), '_', '\_') || '%'
+              OR t.title % p_query
+          )
+    )
+    SELECT m.id, m.user_id, m.title, m.completed, m.created_at, m.updated_at, m.score,
+           (SELECT COUNT(*) FROM matched)
+    FROM matched m
+    WHERE p_after_score IS NULL OR (m.score, m.id) < (p_after_score, p_after_id)
+    ORDER BY m.score DESC, m.id DESC
+    LIMIT p_limit;
+$$;
+
+COMMENT ON FUNCTION search_todos(UUID, TEXT, BOOLEAN, INTEGER, REAL, UUID) IS '按标题模糊搜索待办事项，结果按相关度排序并分页';
+
+-- 删除 005 添加的索引和列
+DROP INDEX IF EXISTS idx_todos_remind_at;
+DROP INDEX IF EXISTS idx_todos_user_due_at;
+ALTER TABLE todos DROP COLUMN IF EXISTS remind_at;
+ALTER TABLE todos DROP COLUMN IF EXISTS due_at;
@@ -0,0 +1,54 @@
+-- 添加截止时间和提醒时间，两者都是可选的
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS remind_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN todos.due_at IS '截止时间';
+COMMENT ON COLUMN todos.remind_at IS '提醒时间';
+
+-- 按截止时间过滤（逾期、今天、本周）
+CREATE INDEX IF NOT EXISTS idx_todos_user_due_at ON todos(user_id, due_at) WHERE due_at IS NOT NULL;
+
+-- 查找需要发送提醒的未完成待办事项
+CREATE INDEX IF NOT EXISTS idx_todos_remind_at ON todos(remind_at) WHERE remind_at IS NOT NULL AND NOT completed;
+
+-- search_todos 改为返回 todos 表的整行，以后给 todos 添加列时不需要再修改函数的返回类型。
+-- 修改返回类型需要先删除旧函数
+DROP FUNCTION IF EXISTS search_todos(UUID, TEXT, BOOLEAN, INTEGER, REAL, UUID);
+
+CREATE FUNCTION search_todos(
+    p_user_id UUID,
+    p_query TEXT,
+    p_completed BOOLEAN DEFAULT NULL,
+    p_limit INTEGER DEFAULT 50,
+    p_after_score REAL DEFAULT NULL,
+    p_after_id UUID DEFAULT NULL
+)
+RETURNS TABLE (
+    todo todos,
+    score REAL,
+    total BIGINT
+)
+LANGUAGE sql
+STABLE
+AS $$
+    WITH matched AS (
+        SELECT
+            t AS todo,
+            ((CASE WHEN strpos(lower(t.title), lower(p_query)) > 0 THEN 1 ELSE 0 END)
+                + similarity(t.title, p_query))::REAL AS score
+        FROM todos t
+        WHERE t.user_id = p_user_id
+          AND (p_completed IS NULL OR t.completed = p_completed)
+          AND (
+              t.title ILIKE '%' || replace(replace(replace(p_query, '\', '\\'), '%', '\%'), '_', '\_') || '%'
+              OR t.title % p_query
+          )
+    )
+    SELECT m.todo, m.score, (SELECT COUNT(*) FROM matched)
+    FROM matched m
+    WHERE p_after_score IS NULL OR (m.score, (m.todo).id) < (p_after_score, p_after_id)
+    ORDER BY m.score DESC, (m.todo).id DESC
+    LIMIT p_limit;
+$$;
+
+COMMENT ON FUNCTION search_todos(UUID, TEXT, BOOLEAN, INTEGER, REAL, UUID) IS '按标题模糊搜索待办事项，结果按相关度排序并分页';
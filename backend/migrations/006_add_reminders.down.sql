@@ -0,0 +1,4 @@
+-- 删除 006 创建的函数和表
+DROP FUNCTION IF EXISTS claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER);
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS todo_reminders;
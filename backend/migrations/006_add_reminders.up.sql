@@ -0,0 +1,123 @@
+-- 提醒状态，每个提醒过的待办事项一行。
+-- 与 todos 表分开存放，领取和确认提醒时不会触发 todos 的 updated_at 更新
+CREATE TABLE IF NOT EXISTS todo_reminders (
+    todo_id UUID PRIMARY KEY REFERENCES todos(id) ON DELETE CASCADE,
+    reminded_for TIMESTAMPTZ,
+    lease_owner TEXT,
+    lease_until TIMESTAMPTZ,
+    attempts INTEGER NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE todo_reminders IS '待办事项的提醒状态，由提醒调度器维护';
+COMMENT ON COLUMN todo_reminders.reminded_for IS '最近一次已提醒的提醒时间，与 todos.remind_at 不同时说明需要再次提醒';
+COMMENT ON COLUMN todo_reminders.lease_owner IS '领取该提醒的调度器实例';
+COMMENT ON COLUMN todo_reminders.lease_until IS '租约到期时间，到期前其他实例不会领取；发送失败后作为下次重试的时间';
+COMMENT ON COLUMN todo_reminders.attempts IS '当前提醒发送失败的次数';
+
+-- 站内通知
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    todo_id UUID REFERENCES todos(id) ON DELETE SET NULL,
+    kind TEXT NOT NULL,
+    title TEXT NOT NULL,
+    due_at TIMESTAMPTZ,
+    key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMPTZ,
+    CONSTRAINT notifications_user_key UNIQUE (user_id, key)
+);
+
+COMMENT ON TABLE notifications IS '站内通知';
+COMMENT ON COLUMN notifications.kind IS '通知类型，例如 reminder';
+COMMENT ON COLUMN notifications.key IS '幂等键，同一用户相同键的通知只保存一次';
+COMMENT ON COLUMN notifications.read_at IS '已读时间，未读时为空';
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created_at ON notifications(user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- 领取到期的提醒。先为到期的待办事项补齐提醒状态行，再锁定这些行：
+-- FOR UPDATE SKIP LOCKED 让多个实例同时领取时互不等待，
+-- 锁等待结束后 PostgreSQL 会按最新的行重新检查条件，已被其他实例领取的提醒不会重复领取
+CREATE OR REPLACE FUNCTION claim_due_reminders(
+    p_owner TEXT,
+    p_now TIMESTAMPTZ,
+    p_lease_until TIMESTAMPTZ,
+    p_limit INTEGER
+)
+RETURNS TABLE (
+    todo_id UUID,
+    user_id UUID,
+    title TEXT,
+    due_at TIMESTAMPTZ,
+    remind_at TIMESTAMPTZ,
+    attempts INTEGER
+)
+LANGUAGE plpgsql
+AS $$
+#variable_conflict use_column
+BEGIN
+    INSERT INTO todo_reminders (todo_id)
+    SELECT t.id
+    FROM todos t
+    WHERE t.remind_at <= p_now
+      AND NOT t.completed
+      AND NOT EXISTS (SELECT 1 FROM todo_reminders r WHERE r.todo_id = t.id)
+    ON CONFLICT (todo_id) DO NOTHING;
+
+    RETURN QUERY
+    WITH due AS (
+        SELECT r.todo_id
+        FROM todo_reminders r
+        JOIN todos t ON t.id = r.todo_id
+        WHERE t.remind_at <= p_now
+          AND NOT t.completed
+          AND (r.reminded_for IS NULL OR r.reminded_for <> t.remind_at)
+          AND (r.lease_until IS NULL OR r.lease_until <= p_now)
+        ORDER BY t.remind_at, t.id
+        LIMIT p_limit
+        FOR UPDATE OF r SKIP LOCKED
+    )
+    UPDATE todo_reminders r
+    SET lease_owner = p_owner,
+        lease_until = p_lease_until
+    FROM due, todos t
+    WHERE r.todo_id = due.todo_id
+      AND t.id = r.todo_id
+    RETURNING t.id, t.user_id, t.title, t.due_at, t.remind_at, r.attempts;
+END;
+$$;
+
+COMMENT ON FUNCTION claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER) IS '领取到期的提醒并设置租约，只供提醒调度器使用';
+
+-- 用户可以查看自己的通知并标记为已读；通知只由服务端创建。
+-- 提醒状态和领取函数只供服务端（service_role）使用
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        ALTER TABLE todo_reminders ENABLE ROW LEVEL SECURITY;
+        ALTER TABLE notifications ENABLE ROW LEVEL SECURITY;
+
+        DROP POLICY IF EXISTS "用户可以查看自己的通知" ON notifications;
+        DROP POLICY IF EXISTS "用户可以更新自己的通知" ON notifications;
+
+        CREATE POLICY "用户可以查看自己的通知"
+        ON notifications FOR SELECT
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以更新自己的通知"
+        ON notifications FOR UPDATE
+        TO authenticated
+        USING (auth.uid() = user_id)
+        WITH CHECK (auth.uid() = user_id);
+
+        GRANT SELECT, UPDATE ON notifications TO authenticated;
+
+        REVOKE EXECUTE ON FUNCTION claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER) FROM PUBLIC;
+        REVOKE EXECUTE ON FUNCTION claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER) FROM anon, authenticated;
+        GRANT EXECUTE ON FUNCTION claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER) TO service_role;
+    END IF;
+END
+$$;
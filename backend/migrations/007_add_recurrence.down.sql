@@ -0,0 +1,7 @@
+-- 删除 007 添加的约束、索引和列
+DROP INDEX IF EXISTS idx_todos_user_series;
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_recurrence_check;
+ALTER TABLE todos DROP COLUMN IF EXISTS recurrence_start;
+ALTER TABLE todos DROP COLUMN IF EXISTS recurrence_timezone;
+ALTER TABLE todos DROP COLUMN IF EXISTS recurrence_rule;
+ALTER TABLE todos DROP COLUMN IF EXISTS series_id;
@@ -0,0 +1,21 @@
+-- 重复系列：同一系列的实例共享 series_id，只有系列当前未完成的实例带有重复规则
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS series_id UUID;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS recurrence_rule TEXT;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS recurrence_timezone TEXT;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS recurrence_start TIMESTAMPTZ;
+
+COMMENT ON COLUMN todos.series_id IS '所属重复系列的 ID';
+COMMENT ON COLUMN todos.recurrence_rule IS 'RFC 5545 RRULE 重复规则，不带 RRULE: 前缀';
+COMMENT ON COLUMN todos.recurrence_timezone IS '计算重复实例使用的 IANA 时区';
+COMMENT ON COLUMN todos.recurrence_start IS '重复系列的起始时间（DTSTART）';
+
+-- 重复规则的各列要么都为空，要么都不为空，并且必须属于某个系列
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_recurrence_check;
+ALTER TABLE todos ADD CONSTRAINT todos_recurrence_check CHECK (
+    (recurrence_rule IS NULL AND recurrence_timezone IS NULL AND recurrence_start IS NULL)
+    OR (recurrence_rule IS NOT NULL AND recurrence_timezone IS NOT NULL AND recurrence_start IS NOT NULL
+        AND series_id IS NOT NULL)
+);
+
+-- 按系列查询实例
+CREATE INDEX IF NOT EXISTS idx_todos_user_series ON todos(user_id, series_id) WHERE series_id IS NOT NULL;
@@ -0,0 +1,7 @@
+-- 删除 008 添加的索引、约束和列
+DROP INDEX IF EXISTS idx_todos_user_priority;
+DROP INDEX IF EXISTS idx_todos_user_position;
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_position_check;
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_priority_check;
+ALTER TABLE todos DROP COLUMN IF EXISTS position;
+ALTER TABLE todos DROP COLUMN IF EXISTS priority;
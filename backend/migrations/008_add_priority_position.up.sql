@@ -0,0 +1,31 @@
+-- 优先级：0 none、1 low、2 medium、3 high、4 urgent，数值越大优先级越高
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_priority_check;
+ALTER TABLE todos ADD CONSTRAINT todos_priority_check CHECK (priority BETWEEN 0 AND 4);
+
+-- 手动排序的位置，由 0-9A-Za-z 组成且不以 0 结尾，必须按字节排序
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS position TEXT COLLATE "C";
+
+-- 已有的待办事项按创建时间依次分配位置，回填时不刷新 updated_at
+ALTER TABLE todos DISABLE TRIGGER update_todos_updated_at;
+UPDATE todos t
+SET position = lpad(r.rn::text, 12, '0') || 'V'
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY user_id ORDER BY created_at, id) AS rn
+    FROM todos
+) r
+WHERE t.id = r.id AND t.position IS NULL;
+ALTER TABLE todos ENABLE TRIGGER update_todos_updated_at;
+
+ALTER TABLE todos ALTER COLUMN position SET NOT NULL;
+
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_position_check;
+ALTER TABLE todos ADD CONSTRAINT todos_position_check CHECK (position ~ '^[0-9A-Za-z]*[1-9A-Za-z]$');
+
+COMMENT ON COLUMN todos.priority IS '优先级，0 到 4 分别为 none、low、medium、high、urgent';
+COMMENT ON COLUMN todos.position IS '手动排序的字典序位置';
+
+-- 按位置排序，以及按优先级从高到低、同一优先级内按位置排序
+CREATE INDEX IF NOT EXISTS idx_todos_user_position ON todos(user_id, position, id);
+CREATE INDEX IF NOT EXISTS idx_todos_user_priority ON todos(user_id, priority DESC, position, id);
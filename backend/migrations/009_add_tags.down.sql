@@ -0,0 +1,3 @@
+-- 删除 009 创建的表，关联表依赖 tags，先删除
+DROP TABLE IF EXISTS todo_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,103 @@
+-- 用户的标签，同一用户的标签名称不区分大小写唯一
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    color TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT tags_color_check CHECK (color ~ '^#[0-9a-f]{6}$')
+);
+
+COMMENT ON TABLE tags IS '用户的标签';
+COMMENT ON COLUMN tags.name IS '标签名称，同一用户内不区分大小写唯一';
+COMMENT ON COLUMN tags.color IS '#rrggbb 格式的小写颜色';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name ON tags(user_id, lower(name));
+
+-- 复用 001 创建的更新时间触发器函数
+DROP TRIGGER IF EXISTS update_tags_updated_at ON tags;
+CREATE TRIGGER update_tags_updated_at
+    BEFORE UPDATE ON tags
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- 待办事项与标签的多对多关联，删除任意一方时关联随之删除。
+-- user_id 冗余存储关联所属的用户，便于 RLS 策略和按用户查询
+CREATE TABLE IF NOT EXISTS todo_tags (
+    todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+COMMENT ON TABLE todo_tags IS '待办事项与标签的关联';
+COMMENT ON COLUMN todo_tags.user_id IS '关联所属的用户 ID，与待办事项和标签的用户相同';
+
+-- 主键覆盖按待办事项查询标签，此索引用于按标签过滤待办事项
+CREATE INDEX IF NOT EXISTS idx_todo_tags_tag ON todo_tags(tag_id, todo_id);
+
+-- 与 001 相同，RLS 策略只在 Supabase 中创建。
+-- 创建关联时还要求待办事项和标签都属于当前用户，防止关联到他人的数据
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        ALTER TABLE tags ENABLE ROW LEVEL SECURITY;
+        ALTER TABLE todo_tags ENABLE ROW LEVEL SECURITY;
+
+        DROP POLICY IF EXISTS "用户可以查看自己的标签" ON tags;
+        DROP POLICY IF EXISTS "用户可以创建自己的标签" ON tags;
+        DROP POLICY IF EXISTS "用户可以更新自己的标签" ON tags;
+        DROP POLICY IF EXISTS "用户可以删除自己的标签" ON tags;
+
+        CREATE POLICY "用户可以查看自己的标签"
+        ON tags FOR SELECT
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以创建自己的标签"
+        ON tags FOR INSERT
+        TO authenticated
+        WITH CHECK (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以更新自己的标签"
+        ON tags FOR UPDATE
+        TO authenticated
+        USING (auth.uid() = user_id)
+        WITH CHECK (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以删除自己的标签"
+        ON tags FOR DELETE
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        DROP POLICY IF EXISTS "用户可以查看自己的标签关联" ON todo_tags;
+        DROP POLICY IF EXISTS "用户可以创建自己的标签关联" ON todo_tags;
+        DROP POLICY IF EXISTS "用户可以删除自己的标签关联" ON todo_tags;
+
+        CREATE POLICY "用户可以查看自己的标签关联"
+        ON todo_tags FOR SELECT
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以创建自己的标签关联"
+        ON todo_tags FOR INSERT
+        TO authenticated
+        WITH CHECK (
+            auth.uid() = user_id
+            AND EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id AND t.user_id = auth.uid())
+            AND EXISTS (SELECT 1 FROM tags g WHERE g.id = tag_id AND g.user_id = auth.uid())
+        );
+
+        CREATE POLICY "用户可以删除自己的标签关联"
+        ON todo_tags FOR DELETE
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        GRANT ALL ON tags TO authenticated;
+        GRANT SELECT, INSERT, DELETE ON todo_tags TO authenticated;
+    END IF;
+END
+$$;
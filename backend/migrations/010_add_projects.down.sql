@@ -0,0 +1,6 @@
+-- 删除 010 创建的函数、列和表，todos.project_id 依赖 projects，先删除
+DROP FUNCTION IF EXISTS project_todo_counts(UUID);
+DROP POLICY IF EXISTS "用户只能把待办事项放入自己的项目" ON todos;
+DROP INDEX IF EXISTS idx_todos_project;
+ALTER TABLE todos DROP COLUMN IF EXISTS project_id;
+DROP TABLE IF EXISTS projects;
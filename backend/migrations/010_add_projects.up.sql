@@ -0,0 +1,109 @@
+-- 用户的项目（清单），每个用户最多一个收件箱
+CREATE TABLE IF NOT EXISTS projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    name TEXT NOT NULL,
+    color TEXT NOT NULL,
+    icon TEXT NOT NULL DEFAULT '',
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    is_inbox BOOLEAN NOT NULL DEFAULT FALSE,
+    position TEXT COLLATE "C" NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT projects_color_check CHECK (color ~ '^#[0-9a-f]{6}$'),
+    CONSTRAINT projects_position_check CHECK (position = '' OR position ~ '^[0-9A-Za-z]*[1-9A-Za-z]$'),
+    CONSTRAINT projects_inbox_check CHECK (NOT (is_inbox AND archived))
+);
+
+COMMENT ON TABLE projects IS '用户的项目，每个待办事项属于一个项目';
+COMMENT ON COLUMN projects.icon IS '图标名称或 emoji，为空表示没有图标';
+COMMENT ON COLUMN projects.is_inbox IS '是否是收件箱，收件箱不能删除或归档';
+COMMENT ON COLUMN projects.position IS '侧边栏中手动排序的字典序位置，收件箱为空';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_user_inbox ON projects(user_id) WHERE is_inbox;
+CREATE INDEX IF NOT EXISTS idx_projects_user_position ON projects(user_id, position, id);
+
+-- 复用 001 创建的更新时间触发器函数
+DROP TRIGGER IF EXISTS update_projects_updated_at ON projects;
+CREATE TRIGGER update_projects_updated_at
+    BEFORE UPDATE ON projects
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- 已有的待办事项暂时没有项目，用户第一次使用项目时创建收件箱并移入收件箱。
+-- 删除项目时级联删除其中的待办事项，需要保留时由服务端先移动到其他项目
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS project_id UUID REFERENCES projects(id) ON DELETE CASCADE;
+
+COMMENT ON COLUMN todos.project_id IS '所属项目，为空表示还没有分配项目';
+
+-- 按项目过滤待办事项和统计每个项目中的数量
+CREATE INDEX IF NOT EXISTS idx_todos_project ON todos(project_id, completed);
+
+-- 统计每个项目中待办事项的总数和已完成的数量，PostgreSQL 存储后端直接调用，
+-- Supabase 通过 /rest/v1/rpc/project_todo_counts 调用。以调用者的权限执行，受 RLS 约束
+CREATE OR REPLACE FUNCTION project_todo_counts(p_user_id UUID)
+RETURNS TABLE (
+    project_id UUID,
+    total BIGINT,
+    completed BIGINT
+)
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT t.project_id, COUNT(*), COUNT(*) FILTER (WHERE t.completed)
+    FROM todos t
+    WHERE t.user_id = p_user_id AND t.project_id IS NOT NULL
+    GROUP BY t.project_id;
+$$;
+
+COMMENT ON FUNCTION project_todo_counts(UUID) IS '统计每个项目中待办事项的总数和已完成的数量';
+
+-- 与 001 相同，RLS 策略只在 Supabase 中创建。
+-- 待办事项只能放入自己的项目
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        ALTER TABLE projects ENABLE ROW LEVEL SECURITY;
+
+        DROP POLICY IF EXISTS "用户可以查看自己的项目" ON projects;
+        DROP POLICY IF EXISTS "用户可以创建自己的项目" ON projects;
+        DROP POLICY IF EXISTS "用户可以更新自己的项目" ON projects;
+        DROP POLICY IF EXISTS "用户可以删除自己的项目" ON projects;
+
+        CREATE POLICY "用户可以查看自己的项目"
+        ON projects FOR SELECT
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以创建自己的项目"
+        ON projects FOR INSERT
+        TO authenticated
+        WITH CHECK (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以更新自己的项目"
+        ON projects FOR UPDATE
+        TO authenticated
+        USING (auth.uid() = user_id)
+        WITH CHECK (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以删除自己的项目"
+        ON projects FOR DELETE
+        TO authenticated
+        USING (auth.uid() = user_id AND NOT is_inbox);
+
+        DROP POLICY IF EXISTS "用户只能把待办事项放入自己的项目" ON todos;
+
+        CREATE POLICY "用户只能把待办事项放入自己的项目"
+        ON todos AS RESTRICTIVE FOR ALL
+        TO authenticated
+        USING (TRUE)
+        WITH CHECK (
+            project_id IS NULL
+            OR EXISTS (SELECT 1 FROM projects p WHERE p.id = project_id AND p.user_id = auth.uid())
+        );
+
+        GRANT ALL ON projects TO authenticated;
+    END IF;
+END
+$$;
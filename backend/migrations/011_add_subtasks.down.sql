@@ -0,0 +1,3 @@
+-- 删除 011 创建的列和表
+ALTER TABLE todos DROP COLUMN IF EXISTS auto_complete;
+DROP TABLE IF EXISTS subtasks;
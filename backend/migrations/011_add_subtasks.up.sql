@@ -0,0 +1,74 @@
+-- 待办事项的子任务（检查清单），删除待办事项时级联删除。
+-- user_id 冗余存储子任务所属的用户，便于 RLS 策略和按用户查询
+CREATE TABLE IF NOT EXISTS subtasks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    title TEXT NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    position TEXT COLLATE "C" NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT subtasks_position_check CHECK (position = '' OR position ~ '^[0-9A-Za-z]*[1-9A-Za-z]$')
+);
+
+COMMENT ON TABLE subtasks IS '待办事项的子任务';
+COMMENT ON COLUMN subtasks.user_id IS '子任务所属的用户 ID，与待办事项的用户相同';
+COMMENT ON COLUMN subtasks.position IS '清单中手动排序的字典序位置';
+
+-- 按位置获取清单，同时覆盖按待办事项统计进度
+CREATE INDEX IF NOT EXISTS idx_subtasks_todo_position ON subtasks(todo_id, position, id);
+
+-- 复用 001 创建的更新时间触发器函数
+DROP TRIGGER IF EXISTS update_subtasks_updated_at ON subtasks;
+CREATE TRIGGER update_subtasks_updated_at
+    BEFORE UPDATE ON subtasks
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- 为 TRUE 时子任务全部完成后自动完成待办事项
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS auto_complete BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN todos.auto_complete IS '子任务全部完成后是否自动完成待办事项';
+
+-- 与 001 相同，RLS 策略只在 Supabase 中创建。
+-- 创建子任务时还要求待办事项属于当前用户，防止添加到他人的待办事项
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        ALTER TABLE subtasks ENABLE ROW LEVEL SECURITY;
+
+        DROP POLICY IF EXISTS "用户可以查看自己的子任务" ON subtasks;
+        DROP POLICY IF EXISTS "用户可以创建自己的子任务" ON subtasks;
+        DROP POLICY IF EXISTS "用户可以更新自己的子任务" ON subtasks;
+        DROP POLICY IF EXISTS "用户可以删除自己的子任务" ON subtasks;
+
+        CREATE POLICY "用户可以查看自己的子任务"
+        ON subtasks FOR SELECT
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以创建自己的子任务"
+        ON subtasks FOR INSERT
+        TO authenticated
+        WITH CHECK (
+            auth.uid() = user_id
+            AND EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id AND t.user_id = auth.uid())
+        );
+
+        CREATE POLICY "用户可以更新自己的子任务"
+        ON subtasks FOR UPDATE
+        TO authenticated
+        USING (auth.uid() = user_id)
+        WITH CHECK (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以删除自己的子任务"
+        ON subtasks FOR DELETE
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        GRANT ALL ON subtasks TO authenticated;
+    END IF;
+END
+$$;
@@ -0,0 +1,42 @@
+-- 恢复 005 中只搜索标题的 search_todos 函数
+CREATE OR REPLACE FUNCTION search_todos(
+    p_user_id UUID,
+    p_query TEXT,
+    p_completed BOOLEAN DEFAULT NULL,
+    p_limit INTEGER DEFAULT 50,
+    p_after_score REAL DEFAULT NULL,
+    p_after_id UUID DEFAULT NULL
+)
+RETURNS TABLE (
+    todo todos,
+    score REAL,
+    total BIGINT
+)
+LANGUAGE sql
+STABLE
+AS $$
+    WITH matched AS (
+        SELECT
+            t AS todo,
+            ((CASE WHEN strpos(lower(t.title), lower(p_query)) > 0 THEN 1 ELSE 0 END)
+                + similarity(t.title, p_query))::REAL AS score
+        FROM todos t
+        WHERE t.user_id = p_user_id
+          AND (p_completed IS NULL OR t.completed = p_completed)
+          AND (
+              t.title ILIKE '%' || replace(replace(replace(p_query, '\', '\\'), '%', '\%'), '_', '\_') || '%'
+              OR t.title % p_query
+          )
+    )
+    SELECT m.todo, m.score, (SELECT COUNT(*) FROM matched)
+    FROM matched m
+    WHERE p_after_score IS NULL OR (m.score, (m.todo).id) < (p_after_score, p_after_id)
+    ORDER BY m.score DESC, (m.todo).id DESC
+    LIMIT p_limit;
+$$;
+
+COMMENT ON FUNCTION search_todos(UUID, TEXT, BOOLEAN, INTEGER, REAL, UUID) IS '按标题模糊搜索待办事项，结果按相关度排序并分页';
+
+-- 删除 012 创建的索引和列
+DROP INDEX IF EXISTS idx_todos_notes_trgm;
+ALTER TABLE todos DROP COLUMN IF EXISTS notes;
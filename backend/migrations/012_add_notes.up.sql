@@ -0,0 +1,58 @@
+-- 待办事项的 Markdown 备注，保存原文，渲染在服务端完成
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN todos.notes IS 'Markdown 格式的备注';
+
+-- 与 002 的 idx_todos_title_trgm 相同，供搜索备注时的 ILIKE 和 % 运算符使用
+CREATE INDEX IF NOT EXISTS idx_todos_notes_trgm ON todos USING GIN (notes gin_trgm_ops);
+
+-- search_todos 同时搜索标题和备注，得分取两者中较高的一个，没有匹配的字段不参与比较。
+-- 返回类型与 005 相同，可以直接替换
+CREATE OR REPLACE FUNCTION search_todos(
+    p_user_id UUID,
+    p_query TEXT,
+    p_completed BOOLEAN DEFAULT NULL,
+    p_limit INTEGER DEFAULT 50,
+    p_after_score REAL DEFAULT NULL,
+    p_after_id UUID DEFAULT NULL
+)
+RETURNS TABLE (
+    todo todos,
+    score REAL,
+    total BIGINT
+)
+LANGUAGE sql
+STABLE
+AS $$
+    WITH params AS (
+        SELECT '%' || replace(replace(replace(p_query, '\', '\\'), '%', '\%'), '_', '\_') || '%' AS pattern
+    ),
+    matched AS (
+        SELECT
+            t AS todo,
+            GREATEST(
+                CASE WHEN t.title ILIKE p.pattern OR t.title % p_query THEN
+                    ((CASE WHEN strpos(lower(t.title), lower(p_query)) > 0 THEN 1 ELSE 0 END)
+                        + similarity(t.title, p_query))::REAL
+                END,
+                CASE WHEN t.notes ILIKE p.pattern OR t.notes % p_query THEN
+                    ((CASE WHEN strpos(lower(t.notes), lower(p_query)) > 0 THEN 1 ELSE 0 END)
+                        + similarity(t.notes, p_query))::REAL
+                END
+            ) AS score
+        FROM todos t, params p
+        WHERE t.user_id = p_user_id
+          AND (p_completed IS NULL OR t.completed = p_completed)
+          AND (
+              t.title ILIKE p.pattern OR t.title % p_query
+              OR t.notes ILIKE p.pattern OR t.notes % p_query
+          )
+    )
+    SELECT m.todo, m.score, (SELECT COUNT(*) FROM matched)
+    FROM matched m
+    WHERE p_after_score IS NULL OR (m.score, (m.todo).id) < (p_after_score, p_after_id)
+    ORDER BY m.score DESC, (m.todo).id DESC
+    LIMIT p_limit;
+$$;
+
+COMMENT ON FUNCTION search_todos(UUID, TEXT, BOOLEAN, INTEGER, REAL, UUID) IS '按标题和备注模糊搜索待办事项，结果按相关度排序并分页';
@@ -0,0 +1,2 @@
+-- 删除 013 创建的表，附件存储中的文件需要另行清理
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,62 @@
+-- 待办事项的附件，这里只保存元数据，文件保存在配置的附件存储中。
+-- 删除待办事项时 todo_id 置空而不是级联删除，后台的清理任务删除文件后再删除这一行；
+-- user_id 冗余存储附件所属的用户，便于 RLS 策略和按用户查询
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    todo_id UUID REFERENCES todos(id) ON DELETE SET NULL,
+    user_id UUID NOT NULL,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT attachments_size_check CHECK (size >= 0)
+);
+
+COMMENT ON TABLE attachments IS '待办事项的附件';
+COMMENT ON COLUMN attachments.todo_id IS '所属的待办事项，为空表示附件已被删除，等待清理文件';
+COMMENT ON COLUMN attachments.content_type IS '按文件内容识别的 MIME 类型';
+COMMENT ON COLUMN attachments.storage_key IS '文件在附件存储中的键';
+
+-- 按上传时间获取待办事项的附件
+CREATE INDEX IF NOT EXISTS idx_attachments_todo_created_at ON attachments(todo_id, created_at, id);
+
+-- 清理任务查找已删除的附件
+CREATE INDEX IF NOT EXISTS idx_attachments_detached ON attachments(created_at) WHERE todo_id IS NULL;
+
+-- 与 001 相同，RLS 策略只在 Supabase 中创建。
+-- 创建附件时还要求待办事项属于当前用户；更新只用于删除附件时置空 todo_id，
+-- 不能把附件移到其他待办事项。清理任务需要使用 service_role 密钥绕过 RLS
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        ALTER TABLE attachments ENABLE ROW LEVEL SECURITY;
+
+        DROP POLICY IF EXISTS "用户可以查看自己的附件" ON attachments;
+        DROP POLICY IF EXISTS "用户可以创建自己的附件" ON attachments;
+        DROP POLICY IF EXISTS "用户可以删除自己的附件" ON attachments;
+
+        CREATE POLICY "用户可以查看自己的附件"
+        ON attachments FOR SELECT
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        CREATE POLICY "用户可以创建自己的附件"
+        ON attachments FOR INSERT
+        TO authenticated
+        WITH CHECK (
+            auth.uid() = user_id
+            AND EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id AND t.user_id = auth.uid())
+        );
+
+        CREATE POLICY "用户可以删除自己的附件"
+        ON attachments FOR UPDATE
+        TO authenticated
+        USING (auth.uid() = user_id)
+        WITH CHECK (auth.uid() = user_id AND todo_id IS NULL);
+
+        GRANT SELECT, INSERT, UPDATE ON attachments TO authenticated;
+    END IF;
+END
+$$;
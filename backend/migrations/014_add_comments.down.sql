@@ -0,0 +1,2 @@
+-- 删除 014 创建的表，触发器和 RLS 策略随表一起删除
+DROP TABLE IF EXISTS comments;
@@ -0,0 +1,70 @@
+-- 待办事项的评论，删除待办事项时级联删除。
+-- user_id 是评论的作者，查看评论的权限跟随待办事项，修改和删除只允许作者本人
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    todo_id UUID NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT comments_body_check CHECK (body <> '')
+);
+
+COMMENT ON TABLE comments IS '待办事项的评论';
+COMMENT ON COLUMN comments.user_id IS '评论的作者';
+
+-- 按创建时间分页获取评论，同时覆盖按待办事项统计评论数
+CREATE INDEX IF NOT EXISTS idx_comments_todo_created_at ON comments(todo_id, created_at, id);
+
+-- 复用 001 创建的更新时间触发器函数
+DROP TRIGGER IF EXISTS update_comments_updated_at ON comments;
+CREATE TRIGGER update_comments_updated_at
+    BEFORE UPDATE ON comments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- 与 001 相同，RLS 策略只在 Supabase 中创建。
+-- 能看到待办事项的用户就能看到并发表它的评论，目前即待办事项的所有者；
+-- 修改和删除只允许作者本人，且不能把评论移到其他待办事项
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        ALTER TABLE comments ENABLE ROW LEVEL SECURITY;
+
+        DROP POLICY IF EXISTS "用户可以查看可见待办事项的评论" ON comments;
+        DROP POLICY IF EXISTS "用户可以评论可见的待办事项" ON comments;
+        DROP POLICY IF EXISTS "用户可以更新自己的评论" ON comments;
+        DROP POLICY IF EXISTS "用户可以删除自己的评论" ON comments;
+
+        CREATE POLICY "用户可以查看可见待办事项的评论"
+        ON comments FOR SELECT
+        TO authenticated
+        USING (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id AND t.user_id = auth.uid()));
+
+        CREATE POLICY "用户可以评论可见的待办事项"
+        ON comments FOR INSERT
+        TO authenticated
+        WITH CHECK (
+            auth.uid() = user_id
+            AND EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id AND t.user_id = auth.uid())
+        );
+
+        CREATE POLICY "用户可以更新自己的评论"
+        ON comments FOR UPDATE
+        TO authenticated
+        USING (auth.uid() = user_id)
+        WITH CHECK (
+            auth.uid() = user_id
+            AND EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id AND t.user_id = auth.uid())
+        );
+
+        CREATE POLICY "用户可以删除自己的评论"
+        ON comments FOR DELETE
+        TO authenticated
+        USING (auth.uid() = user_id);
+
+        GRANT ALL ON comments TO authenticated;
+    END IF;
+END
+$$;
@@ -0,0 +1,44 @@
+-- 恢复 010 和 014 只允许所有者的策略，删除 015 添加的策略、函数和表
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        DROP POLICY IF EXISTS "用户可以查看共享给自己的待办事项" ON todos;
+        DROP POLICY IF EXISTS "用户可以更新共享给自己的待办事项" ON todos;
+        DROP POLICY IF EXISTS "用户可以删除共享给自己的待办事项" ON todos;
+        DROP POLICY IF EXISTS "用户可以查看共享给自己的子任务" ON subtasks;
+        DROP POLICY IF EXISTS "用户可以修改共享给自己的子任务" ON subtasks;
+        DROP POLICY IF EXISTS "用户可以查看共享给自己的附件" ON attachments;
+
+        DROP POLICY IF EXISTS "用户只能把待办事项放入自己的项目" ON todos;
+        CREATE POLICY "用户只能把待办事项放入自己的项目"
+        ON todos AS RESTRICTIVE FOR ALL
+        TO authenticated
+        USING (TRUE)
+        WITH CHECK (
+            project_id IS NULL
+            OR EXISTS (SELECT 1 FROM projects p WHERE p.id = project_id AND p.user_id = auth.uid())
+        );
+
+        DROP POLICY IF EXISTS "用户可以查看可见待办事项的评论" ON comments;
+        DROP POLICY IF EXISTS "用户可以评论可见的待办事项" ON comments;
+
+        CREATE POLICY "用户可以查看可见待办事项的评论"
+        ON comments FOR SELECT
+        TO authenticated
+        USING (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id AND t.user_id = auth.uid()));
+
+        CREATE POLICY "用户可以评论可见的待办事项"
+        ON comments FOR INSERT
+        TO authenticated
+        WITH CHECK (
+            auth.uid() = user_id
+            AND EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id AND t.user_id = auth.uid())
+        );
+    END IF;
+END
+$$;
+
+DROP FUNCTION IF EXISTS todo_share_role(UUID);
+DROP TABLE IF EXISTS invitations;
+DROP TABLE IF EXISTS shares;
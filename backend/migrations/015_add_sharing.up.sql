@@ -0,0 +1,177 @@
+-- 把待办事项或项目共享给其他用户，todo_id 和 project_id 有且只有一个不为空，删除资源时级联删除。
+-- owner_id 冗余保存资源的所有者，user_id 是被共享的用户，同一用户对同一资源只有一条共享
+CREATE TABLE IF NOT EXISTS shares (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    todo_id UUID REFERENCES todos(id) ON DELETE CASCADE,
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    owner_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    role TEXT NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT shares_resource_check CHECK ((todo_id IS NULL) <> (project_id IS NULL)),
+    CONSTRAINT shares_role_check CHECK (role IN ('viewer', 'editor', 'owner'))
+);
+
+COMMENT ON TABLE shares IS '共享给其他用户的待办事项和项目';
+COMMENT ON COLUMN shares.role IS 'viewer 只能查看，editor 可以修改内容，owner 还可以删除和管理共享';
+COMMENT ON COLUMN shares.created_by IS '发出邀请的用户';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shares_todo_user ON shares(todo_id, user_id) WHERE todo_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shares_project_user ON shares(project_id, user_id) WHERE project_id IS NOT NULL;
+-- 列出共享给当前用户的资源
+CREATE INDEX IF NOT EXISTS idx_shares_user ON shares(user_id, created_at);
+
+-- 还没有接受的邀请，只保存令牌的 SHA-256，接受后删除
+CREATE TABLE IF NOT EXISTS invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    todo_id UUID REFERENCES todos(id) ON DELETE CASCADE,
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    owner_id UUID NOT NULL,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT invitations_resource_check CHECK ((todo_id IS NULL) <> (project_id IS NULL)),
+    CONSTRAINT invitations_role_check CHECK (role IN ('viewer', 'editor', 'owner')),
+    CONSTRAINT invitations_email_check CHECK (email = lower(email))
+);
+
+COMMENT ON TABLE invitations IS '通过邮箱发出的共享邀请';
+COMMENT ON COLUMN invitations.token_hash IS '邀请令牌的 SHA-256，令牌本身只在创建时返回';
+
+CREATE INDEX IF NOT EXISTS idx_invitations_todo ON invitations(todo_id) WHERE todo_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_invitations_project ON invitations(project_id) WHERE project_id IS NOT NULL;
+
+-- 复用 001 创建的更新时间触发器函数
+DROP TRIGGER IF EXISTS update_shares_updated_at ON shares;
+CREATE TRIGGER update_shares_updated_at
+    BEFORE UPDATE ON shares
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- 与 001 相同，RLS 策略只在 Supabase 中创建。
+-- 共享对所有者和被共享的用户可见，邀请只对所有者可见；
+-- 被共享的用户按角色获得待办事项及其子任务、评论和附件的权限。
+-- 判断角色的函数以 SECURITY DEFINER 执行，避免 todos 和 shares 的策略互相递归
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        EXECUTE $fn$
+            CREATE OR REPLACE FUNCTION todo_share_role(p_todo_id UUID)
+            RETURNS TEXT
+            LANGUAGE sql
+            STABLE
+            SECURITY DEFINER
+            SET search_path = public
+            AS $body$
+                SELECT CASE MAX(CASE s.role WHEN 'viewer' THEN 1 WHEN 'editor' THEN 2 WHEN 'owner' THEN 3 END)
+                    WHEN 1 THEN 'viewer' WHEN 2 THEN 'editor' WHEN 3 THEN 'owner' END
+                FROM todos t
+                JOIN shares s ON s.user_id = auth.uid()
+                    AND (s.todo_id = t.id OR (t.project_id IS NOT NULL AND s.project_id = t.project_id))
+                WHERE t.id = p_todo_id
+            $body$
+        $fn$;
+
+        ALTER TABLE shares ENABLE ROW LEVEL SECURITY;
+        ALTER TABLE invitations ENABLE ROW LEVEL SECURITY;
+
+        DROP POLICY IF EXISTS "用户可以查看与自己有关的共享" ON shares;
+        DROP POLICY IF EXISTS "被共享的用户可以退出共享" ON shares;
+        DROP POLICY IF EXISTS "所有者可以管理邀请" ON invitations;
+
+        CREATE POLICY "用户可以查看与自己有关的共享"
+        ON shares FOR SELECT
+        TO authenticated
+        USING (auth.uid() = owner_id OR auth.uid() = user_id);
+
+        CREATE POLICY "被共享的用户可以退出共享"
+        ON shares FOR DELETE
+        TO authenticated
+        USING (auth.uid() = owner_id OR auth.uid() = user_id);
+
+        CREATE POLICY "所有者可以管理邀请"
+        ON invitations FOR ALL
+        TO authenticated
+        USING (auth.uid() = owner_id)
+        WITH CHECK (auth.uid() = owner_id);
+
+        DROP POLICY IF EXISTS "用户可以查看共享给自己的待办事项" ON todos;
+        DROP POLICY IF EXISTS "用户可以更新共享给自己的待办事项" ON todos;
+        DROP POLICY IF EXISTS "用户可以删除共享给自己的待办事项" ON todos;
+
+        CREATE POLICY "用户可以查看共享给自己的待办事项"
+        ON todos FOR SELECT
+        TO authenticated
+        USING (todo_share_role(id) IS NOT NULL);
+
+        CREATE POLICY "用户可以更新共享给自己的待办事项"
+        ON todos FOR UPDATE
+        TO authenticated
+        USING (todo_share_role(id) IN ('editor', 'owner'))
+        WITH CHECK (todo_share_role(id) IN ('editor', 'owner'));
+
+        CREATE POLICY "用户可以删除共享给自己的待办事项"
+        ON todos FOR DELETE
+        TO authenticated
+        USING (todo_share_role(id) = 'owner');
+
+        -- 被共享的用户修改待办事项时，项目同样必须属于待办事项的所有者
+        DROP POLICY IF EXISTS "用户只能把待办事项放入自己的项目" ON todos;
+        CREATE POLICY "用户只能把待办事项放入自己的项目"
+        ON todos AS RESTRICTIVE FOR ALL
+        TO authenticated
+        USING (TRUE)
+        WITH CHECK (
+            project_id IS NULL
+            OR EXISTS (SELECT 1 FROM projects p WHERE p.id = project_id AND p.user_id = todos.user_id)
+        );
+
+        DROP POLICY IF EXISTS "用户可以查看共享给自己的子任务" ON subtasks;
+        DROP POLICY IF EXISTS "用户可以修改共享给自己的子任务" ON subtasks;
+
+        CREATE POLICY "用户可以查看共享给自己的子任务"
+        ON subtasks FOR SELECT
+        TO authenticated
+        USING (todo_share_role(todo_id) IS NOT NULL);
+
+        CREATE POLICY "用户可以修改共享给自己的子任务"
+        ON subtasks FOR ALL
+        TO authenticated
+        USING (todo_share_role(todo_id) IN ('editor', 'owner'))
+        WITH CHECK (todo_share_role(todo_id) IN ('editor', 'owner'));
+
+        DROP POLICY IF EXISTS "用户可以查看共享给自己的附件" ON attachments;
+
+        CREATE POLICY "用户可以查看共享给自己的附件"
+        ON attachments FOR SELECT
+        TO authenticated
+        USING (todo_id IS NOT NULL AND todo_share_role(todo_id) IS NOT NULL);
+
+        -- 评论跟随待办事项的可见性，被共享的用户同样可以查看和发表评论
+        DROP POLICY IF EXISTS "用户可以查看可见待办事项的评论" ON comments;
+        DROP POLICY IF EXISTS "用户可以评论可见的待办事项" ON comments;
+
+        CREATE POLICY "用户可以查看可见待办事项的评论"
+        ON comments FOR SELECT
+        TO authenticated
+        USING (EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id));
+
+        CREATE POLICY "用户可以评论可见的待办事项"
+        ON comments FOR INSERT
+        TO authenticated
+        WITH CHECK (
+            auth.uid() = user_id
+            AND EXISTS (SELECT 1 FROM todos t WHERE t.id = todo_id)
+        );
+
+        GRANT ALL ON shares TO authenticated;
+        GRANT ALL ON invitations TO authenticated;
+    END IF;
+END
+$$;
@@ -0,0 +1,39 @@
+-- 删除 016 添加的策略，恢复 015 只考虑共享的 todo_share_role，再删除函数和表。
+-- 以工作区为所有者的数据不会被删除
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        DROP POLICY IF EXISTS "工作区成员可以创建待办事项" ON todos;
+        DROP POLICY IF EXISTS "工作区成员可以查看项目" ON projects;
+        DROP POLICY IF EXISTS "工作区成员可以创建项目" ON projects;
+        DROP POLICY IF EXISTS "工作区成员可以更新项目" ON projects;
+        DROP POLICY IF EXISTS "工作区管理员可以删除项目" ON projects;
+        DROP POLICY IF EXISTS "工作区成员可以查看标签" ON tags;
+        DROP POLICY IF EXISTS "工作区成员可以管理标签" ON tags;
+        DROP POLICY IF EXISTS "工作区成员可以查看标签关联" ON todo_tags;
+        DROP POLICY IF EXISTS "工作区成员可以管理标签关联" ON todo_tags;
+
+        EXECUTE $fn$
+            CREATE OR REPLACE FUNCTION todo_share_role(p_todo_id UUID)
+            RETURNS TEXT
+            LANGUAGE sql
+            STABLE
+            SECURITY DEFINER
+            SET search_path = public
+            AS $body$
+                SELECT CASE MAX(CASE s.role WHEN 'viewer' THEN 1 WHEN 'editor' THEN 2 WHEN 'owner' THEN 3 END)
+                    WHEN 1 THEN 'viewer' WHEN 2 THEN 'editor' WHEN 3 THEN 'owner' END
+                FROM todos t
+                JOIN shares s ON s.user_id = auth.uid()
+                    AND (s.todo_id = t.id OR (t.project_id IS NOT NULL AND s.project_id = t.project_id))
+                WHERE t.id = p_todo_id
+            $body$
+        $fn$;
+    END IF;
+END
+$$;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
+DROP FUNCTION IF EXISTS workspace_role(UUID);
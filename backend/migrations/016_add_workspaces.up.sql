@@ -0,0 +1,168 @@
+-- 团队工作区。工作区中的项目、待办事项和标签以工作区 ID 作为 user_id 保存，
+-- 删除工作区时由后端一并删除
+CREATE TABLE IF NOT EXISTS workspaces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE workspaces IS '团队工作区，工作区的数据以工作区 ID 作为所有者';
+
+-- 工作区的成员，同一用户在同一工作区中只有一条记录，删除工作区时级联删除
+CREATE TABLE IF NOT EXISTS workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    role TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id),
+    CONSTRAINT workspace_members_role_check CHECK (role IN ('guest', 'member', 'admin', 'owner'))
+);
+
+COMMENT ON COLUMN workspace_members.role IS 'guest 只能查看，member 可以修改内容，admin 还可以删除和管理成员，owner 还可以管理管理员和删除工作区';
+
+-- 列出用户加入的工作区
+CREATE INDEX IF NOT EXISTS idx_workspace_members_user ON workspace_members(user_id);
+
+-- 复用 001 创建的更新时间触发器函数
+DROP TRIGGER IF EXISTS update_workspaces_updated_at ON workspaces;
+CREATE TRIGGER update_workspaces_updated_at
+    BEFORE UPDATE ON workspaces
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_workspace_members_updated_at ON workspace_members;
+CREATE TRIGGER update_workspace_members_updated_at
+    BEFORE UPDATE ON workspace_members
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- 与 001 相同，RLS 策略只在 Supabase 中创建。
+-- 成员可以查看工作区和其他成员，成员的管理只通过后端进行；
+-- 工作区的待办事项、项目和标签按成员的角色开放，guest 相当于 viewer，member 相当于 editor，
+-- admin 和 owner 相当于 owner。todo_share_role 同时考虑工作区的角色，
+-- 015 中按它设置的待办事项、子任务和附件的策略因此同样适用于工作区
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        EXECUTE $fn$
+            CREATE OR REPLACE FUNCTION workspace_role(p_workspace_id UUID)
+            RETURNS TEXT
+            LANGUAGE sql
+            STABLE
+            SECURITY DEFINER
+            SET search_path = public
+            AS $body$
+                SELECT m.role FROM workspace_members m
+                WHERE m.workspace_id = p_workspace_id AND m.user_id = auth.uid()
+            $body$
+        $fn$;
+
+        EXECUTE $fn$
+            CREATE OR REPLACE FUNCTION todo_share_role(p_todo_id UUID)
+            RETURNS TEXT
+            LANGUAGE sql
+            STABLE
+            SECURITY DEFINER
+            SET search_path = public
+            AS $body$
+                SELECT CASE MAX(r.rank) WHEN 1 THEN 'viewer' WHEN 2 THEN 'editor' WHEN 3 THEN 'owner' END
+                FROM (
+                    SELECT CASE s.role WHEN 'viewer' THEN 1 WHEN 'editor' THEN 2 WHEN 'owner' THEN 3 END AS rank
+                    FROM todos t
+                    JOIN shares s ON s.user_id = auth.uid()
+                        AND (s.todo_id = t.id OR (t.project_id IS NOT NULL AND s.project_id = t.project_id))
+                    WHERE t.id = p_todo_id
+                    UNION ALL
+                    SELECT CASE m.role WHEN 'guest' THEN 1 WHEN 'member' THEN 2 ELSE 3 END
+                    FROM todos t
+                    JOIN workspace_members m ON m.workspace_id = t.user_id AND m.user_id = auth.uid()
+                    WHERE t.id = p_todo_id
+                ) r
+            $body$
+        $fn$;
+
+        ALTER TABLE workspaces ENABLE ROW LEVEL SECURITY;
+        ALTER TABLE workspace_members ENABLE ROW LEVEL SECURITY;
+
+        DROP POLICY IF EXISTS "成员可以查看自己的工作区" ON workspaces;
+        DROP POLICY IF EXISTS "成员可以查看同一工作区的成员" ON workspace_members;
+
+        CREATE POLICY "成员可以查看自己的工作区"
+        ON workspaces FOR SELECT
+        TO authenticated
+        USING (workspace_role(id) IS NOT NULL);
+
+        CREATE POLICY "成员可以查看同一工作区的成员"
+        ON workspace_members FOR SELECT
+        TO authenticated
+        USING (workspace_role(workspace_id) IS NOT NULL);
+
+        DROP POLICY IF EXISTS "工作区成员可以创建待办事项" ON todos;
+
+        CREATE POLICY "工作区成员可以创建待办事项"
+        ON todos FOR INSERT
+        TO authenticated
+        WITH CHECK (workspace_role(user_id) IN ('member', 'admin', 'owner'));
+
+        DROP POLICY IF EXISTS "工作区成员可以查看项目" ON projects;
+        DROP POLICY IF EXISTS "工作区成员可以创建项目" ON projects;
+        DROP POLICY IF EXISTS "工作区成员可以更新项目" ON projects;
+        DROP POLICY IF EXISTS "工作区管理员可以删除项目" ON projects;
+
+        CREATE POLICY "工作区成员可以查看项目"
+        ON projects FOR SELECT
+        TO authenticated
+        USING (workspace_role(user_id) IS NOT NULL);
+
+        CREATE POLICY "工作区成员可以创建项目"
+        ON projects FOR INSERT
+        TO authenticated
+        WITH CHECK (workspace_role(user_id) IN ('member', 'admin', 'owner'));
+
+        CREATE POLICY "工作区成员可以更新项目"
+        ON projects FOR UPDATE
+        TO authenticated
+        USING (workspace_role(user_id) IN ('member', 'admin', 'owner'))
+        WITH CHECK (workspace_role(user_id) IN ('member', 'admin', 'owner'));
+
+        CREATE POLICY "工作区管理员可以删除项目"
+        ON projects FOR DELETE
+        TO authenticated
+        USING (workspace_role(user_id) IN ('admin', 'owner'));
+
+        DROP POLICY IF EXISTS "工作区成员可以查看标签" ON tags;
+        DROP POLICY IF EXISTS "工作区成员可以管理标签" ON tags;
+        DROP POLICY IF EXISTS "工作区成员可以查看标签关联" ON todo_tags;
+        DROP POLICY IF EXISTS "工作区成员可以管理标签关联" ON todo_tags;
+
+        CREATE POLICY "工作区成员可以查看标签"
+        ON tags FOR SELECT
+        TO authenticated
+        USING (workspace_role(user_id) IS NOT NULL);
+
+        CREATE POLICY "工作区成员可以管理标签"
+        ON tags FOR ALL
+        TO authenticated
+        USING (workspace_role(user_id) IN ('member', 'admin', 'owner'))
+        WITH CHECK (workspace_role(user_id) IN ('member', 'admin', 'owner'));
+
+        CREATE POLICY "工作区成员可以查看标签关联"
+        ON todo_tags FOR SELECT
+        TO authenticated
+        USING (workspace_role(user_id) IS NOT NULL);
+
+        CREATE POLICY "工作区成员可以管理标签关联"
+        ON todo_tags FOR ALL
+        TO authenticated
+        USING (workspace_role(user_id) IN ('member', 'admin', 'owner'))
+        WITH CHECK (workspace_role(user_id) IN ('member', 'admin', 'owner'));
+
+        GRANT SELECT ON workspaces TO authenticated;
+        GRANT SELECT ON workspace_members TO authenticated;
+    END IF;
+END
+$$;
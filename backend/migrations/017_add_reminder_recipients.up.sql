@@ -0,0 +1,72 @@
+-- claim_due_reminders 同时返回工作区和成员：工作区的待办事项以工作区 ID 作为 user_id，
+-- 领取时关联 workspaces 表查出工作区的成员，调度器不需要为每个提醒再查询一次成员。
+-- 返回的列发生变化，需要先删除旧函数再创建
+DROP FUNCTION IF EXISTS claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER);
+
+CREATE FUNCTION claim_due_reminders(
+    p_owner TEXT,
+    p_now TIMESTAMPTZ,
+    p_lease_until TIMESTAMPTZ,
+    p_limit INTEGER
+)
+RETURNS TABLE (
+    todo_id UUID,
+    user_id UUID,
+    title TEXT,
+    due_at TIMESTAMPTZ,
+    remind_at TIMESTAMPTZ,
+    attempts INTEGER,
+    workspace_id UUID,
+    recipients UUID[]
+)
+LANGUAGE plpgsql
+AS $$
+#variable_conflict use_column
+BEGIN
+    INSERT INTO todo_reminders (todo_id)
+    SELECT t.id
+    FROM todos t
+    WHERE t.remind_at <= p_now
+      AND NOT t.completed
+      AND NOT EXISTS (SELECT 1 FROM todo_reminders r WHERE r.todo_id = t.id)
+    ON CONFLICT (todo_id) DO NOTHING;
+
+    RETURN QUERY
+    WITH due AS (
+        SELECT r.todo_id
+        FROM todo_reminders r
+        JOIN todos t ON t.id = r.todo_id
+        WHERE t.remind_at <= p_now
+          AND NOT t.completed
+          AND (r.reminded_for IS NULL OR r.reminded_for <> t.remind_at)
+          AND (r.lease_until IS NULL OR r.lease_until <= p_now)
+        ORDER BY t.remind_at, t.id
+        LIMIT p_limit
+        FOR UPDATE OF r SKIP LOCKED
+    )
+    UPDATE todo_reminders r
+    SET lease_owner = p_owner,
+        lease_until = p_lease_until
+    FROM due
+    JOIN todos t ON t.id = due.todo_id
+    LEFT JOIN workspaces w ON w.id = t.user_id
+    WHERE r.todo_id = due.todo_id
+    RETURNING t.id, t.user_id, t.title, t.due_at, t.remind_at, r.attempts, w.id,
+        CASE WHEN w.id IS NOT NULL THEN
+            ARRAY(SELECT m.user_id FROM workspace_members m WHERE m.workspace_id = w.id ORDER BY m.user_id)
+        END;
+END;
+$$;
+
+COMMENT ON FUNCTION claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER) IS '领取到期的提醒并设置租约，工作区的提醒同时返回成员，只供提醒调度器使用';
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = 'auth')
+       AND EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'authenticated') THEN
+        REVOKE EXECUTE ON FUNCTION claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER) FROM PUBLIC;
+        REVOKE EXECUTE ON FUNCTION claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER) FROM anon, authenticated;
+        GRANT EXECUTE ON FUNCTION claim_due_reminders(TEXT, TIMESTAMPTZ, TIMESTAMPTZ, INTEGER) TO service_role;
+    END IF;
+END
+$$;
@@ -0,0 +1,12 @@
+// Package migrations 内嵌数据库迁移文件。
+//
+// 文件命名格式为 <版本号>_<名称>.up.sql 和 <版本号>_<名称>.down.sql，
+// 由 internal/migrate 按版本号顺序执行。
+package migrations
+
+import "embed"
+
+// FS 所有迁移文件
+//
+//go:embed *.sql
+var FS embed.FS
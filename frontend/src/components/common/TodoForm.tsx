@@ -6,11 +6,13 @@ import { Card } from "@/components/ui/card";
 import { Plus, Loader2 } from "lucide-react";
 import { cn } from "@/lib/utils";
 import { useTranslation } from 'react-i18next';
+import { getFieldViolations } from '@/services/http/errors';
 
 export const TodoForm: React.FC<TodoFormProps> = ({ onAdd }) => {
   const { t } = useTranslation('todos');
   const [title, setTitle] = useState('');
   const [submitting, setSubmitting] = useState(false);
+  const [titleErrors, setTitleErrors] = useState<string[]>([]);
 
   const handleSubmit = async (e: FormEvent) => {
     e.preventDefault();
@@ -18,10 +20,12 @@ export const TodoForm: React.FC<TodoFormProps> = ({ onAdd }) => {
 
     try {
       setSubmitting(true);
+      setTitleErrors([]);
       await onAdd(title);
       setTitle('');
     } catch (error) {
       console.error('Failed to add todo:', error);
+      setTitleErrors(getFieldViolations(error, 'title').map((violation) => violation.message));
     } finally {
       setSubmitting(false);
     }
@@ -39,14 +43,20 @@ export const TodoForm: React.FC<TodoFormProps> = ({ onAdd }) => {
               type="text"
               id="title"
               value={title}
-              onChange={(e) => setTitle(e.target.value)}
+              onChange={(e) => {
+                setTitle(e.target.value);
+                setTitleErrors([]);
+              }}
               placeholder={t('form.title.placeholder')}
               disabled={submitting}
+              aria-invalid={titleErrors.length > 0}
+              aria-describedby={titleErrors.length > 0 ? 'title-errors' : undefined}
               className={cn(
                 "flex-1 transition-all duration-200",
                 "focus-visible:ring-0 focus-visible:ring-offset-0",
                 "border border-input hover:border-accent",
-                "focus-visible:border-accent focus-visible:bg-accent/5"
+                "focus-visible:border-accent focus-visible:bg-accent/5",
+                titleErrors.length > 0 && "border-destructive hover:border-destructive"
               )}
             />
             <Button
@@ -67,6 +77,13 @@ export const TodoForm: React.FC<TodoFormProps> = ({ onAdd }) => {
               )}
             </Button>
           </div>
+          {titleErrors.length > 0 && (
+            <ul id="title-errors" className="space-y-1 text-sm text-destructive">
+              {titleErrors.map((message) => (
+                <li key={message}>{message}</li>
+              ))}
+            </ul>
+          )}
         </div>
       </form>
     </Card>
@@ -1,12 +1,33 @@
-import { TodoItemProps } from '../../types/todo';
+import { TextRange, TodoItemProps } from '../../types/todo';
 import { Button } from "@/components/ui/button";
 import { Checkbox } from "@/components/ui/checkbox";
 import { Trash2 } from "lucide-react";
 import { cn } from "@/lib/utils";
 import { useTranslation } from 'react-i18next';
 
-export const TodoItem: React.FC<TodoItemProps> = ({ todo, onToggle, onDelete }) => {
+// 按高亮区间拆分文本，区间按字符计算，因此先转为字符数组
+function highlightText(text: string, ranges?: TextRange[]) {
+  if (!ranges || ranges.length === 0) return text;
+
+  const chars = Array.from(text);
+  const parts: React.ReactNode[] = [];
+  let last = 0;
+  ranges.forEach(({ start, end }, i) => {
+    if (start > last) parts.push(chars.slice(last, start).join(''));
+    parts.push(
+      <mark key={i} className="rounded-sm bg-primary/20 text-inherit">
+        {chars.slice(start, end).join('')}
+      </mark>
+    );
+    last = end;
+  });
+  if (last < chars.length) parts.push(chars.slice(last).join(''));
+  return parts;
+}
+
+export const TodoItem: React.FC<TodoItemProps> = ({ todo, highlights, onToggle, onDelete }) => {
   const { t } = useTranslation('todos');
+  const overdue = !todo.completed && !!todo.dueAt && new Date(todo.dueAt).getTime() < Date.now();
 
   return (
     <li className="group relative transition-all duration-200 hover:bg-accent/50">
@@ -30,11 +51,18 @@ export const TodoItem: React.FC<TodoItemProps> = ({ todo, onToggle, onDelete })
                   todo.completed ? "text-muted-foreground line-through" : "text-foreground"
                 )}
               >
-                {todo.title}
+                {highlightText(todo.title, highlights)}
               </span>
             </div>
             <p className="mt-1 text-xs text-muted-foreground/70">
               {t('item.created', { date: new Date(todo.createdAt).toLocaleString() })}
+              {todo.dueAt && (
+                <span className={cn("ml-3", overdue && "font-medium text-destructive")}>
+                  {overdue
+                    ? t('item.overdue', { date: new Date(todo.dueAt).toLocaleString() })
+                    : t('item.due', { date: new Date(todo.dueAt).toLocaleString() })}
+                </span>
+              )}
             </p>
           </div>
         </div>
@@ -1,10 +1,11 @@
 import { TodoListProps } from '../../types/todo';
 import { TodoItem } from './TodoItem';
 import { Card } from "@/components/ui/card";
-import { ClipboardList } from "lucide-react";
+import { Button } from "@/components/ui/button";
+import { ClipboardList, Loader2 } from "lucide-react";
 import { useTranslation } from 'react-i18next';
 
-export const TodoList: React.FC<TodoListProps> = ({ todos, onToggle, onDelete }) => {
+export const TodoList: React.FC<TodoListProps> = ({ todos, onToggle, onDelete, highlights, hasMore, loadingMore, onLoadMore }) => {
   const { t } = useTranslation('todos');
 
   if (todos.length === 0) {
@@ -22,17 +23,28 @@ export const TodoList: React.FC<TodoListProps> = ({ todos, onToggle, onDelete })
   }
 
   return (
-    <Card className="divide-y divide-border/50 border-dashed hover:border-solid transition-all duration-300">
-      <ul className="divide-y divide-border/50">
-        {todos.map((todo) => (
-          <TodoItem
-            key={todo.id}
-            todo={todo}
-            onToggle={() => onToggle(todo.id)}
-            onDelete={() => onDelete(todo.id)}
-          />
-        ))}
-      </ul>
-    </Card>
+    <div className="space-y-4">
+      <Card className="divide-y divide-border/50 border-dashed hover:border-solid transition-all duration-300">
+        <ul className="divide-y divide-border/50">
+          {todos.map((todo) => (
+            <TodoItem
+              key={todo.id}
+              todo={todo}
+              highlights={highlights?.[todo.id]}
+              onToggle={() => onToggle(todo.id)}
+              onDelete={() => onDelete(todo.id)}
+            />
+          ))}
+        </ul>
+      </Card>
+      {hasMore && onLoadMore && (
+        <div className="flex justify-center">
+          <Button variant="outline" onClick={onLoadMore} disabled={loadingMore}>
+            {loadingMore && <Loader2 className="mr-2 h-4 w-4 animate-spin" />}
+            {t('list.loadMore')}
+          </Button>
+        </div>
+      )}
+    </div>
   );
 }; 
\ No newline at end of file
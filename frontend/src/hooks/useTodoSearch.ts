@@ -0,0 +1,83 @@
+import { useCallback, useEffect, useRef, useState } from 'react';
+import { todoApi } from '@/services/api/todo';
+import { TodoSearchResult } from '../types/todo';
+
+// 输入停止后等待的时间，避免每输入一个字符都发起请求
+const SEARCH_DELAY = 300;
+
+// query 为空时不搜索，返回空结果
+export function useTodoSearch(query: string) {
+  const [results, setResults] = useState<TodoSearchResult[]>([]);
+  const [total, setTotal] = useState(0);
+  const [nextCursor, setNextCursor] = useState<string>();
+  const [loading, setLoading] = useState(false);
+  const [loadingMore, setLoadingMore] = useState(false);
+  const [error, setError] = useState<Error | null>(null);
+
+  // 只保留最近一次搜索的结果，防止较早的请求晚返回时覆盖新结果
+  const latest = useRef(0);
+  const q = query.trim();
+
+  const search = useCallback(async () => {
+    const id = ++latest.current;
+    if (!q) {
+      setResults([]);
+      setTotal(0);
+      setNextCursor(undefined);
+      setLoading(false);
+      return;
+    }
+
+    setLoading(true);
+    setError(null);
+    try {
+      const page = await todoApi.search({ q });
+      if (id !== latest.current) return;
+      setResults(page.items);
+      setTotal(page.total);
+      setNextCursor(page.nextCursor);
+    } catch (error) {
+      if (id === latest.current) setError(error as Error);
+    } finally {
+      if (id === latest.current) setLoading(false);
+    }
+  }, [q]);
+
+  useEffect(() => {
+    const timer = setTimeout(search, SEARCH_DELAY);
+    return () => clearTimeout(timer);
+  }, [search]);
+
+  const loadMore = async () => {
+    if (!nextCursor || loadingMore) return;
+
+    const id = latest.current;
+    setLoadingMore(true);
+    try {
+      const page = await todoApi.search({ q, cursor: nextCursor });
+      if (id !== latest.current) return;
+      setResults((items) => [...items, ...page.items.filter((item) => !items.some((todo) => todo.id === item.id))]);
+      setTotal(page.total);
+      setNextCursor(page.nextCursor);
+    } catch (error) {
+      setError(error as Error);
+    } finally {
+      setLoadingMore(false);
+    }
+  };
+
+  // 高亮区间按待办事项 ID 索引，供 TodoList 使用
+  const highlights = Object.fromEntries(results.map((result) => [result.id, result.highlights.title ?? []]));
+
+  return {
+    results,
+    highlights,
+    total,
+    hasMore: !!nextCursor,
+    loading,
+    loadingMore,
+    loadMore,
+    error,
+    refresh: search,
+  };
+}
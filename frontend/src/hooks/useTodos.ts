@@ -1,18 +1,26 @@
 import { useEffect } from 'react';
 import useTodoStore from '../stores/todo';
+import { ListTodosParams } from '../types/todo';
 
-export function useTodos() {
+// params 为列表的过滤和排序条件，例如 { completed: false }
+export function useTodos(params: ListTodosParams = {}) {
   const todos = useTodoStore(state => state.todos);
+  const total = useTodoStore(state => state.total);
+  const nextCursor = useTodoStore(state => state.nextCursor);
   const loading = useTodoStore(state => state.loading);
+  const loadingMore = useTodoStore(state => state.loadingMore);
   const error = useTodoStore(state => state.error);
   const fetchTodos = useTodoStore(state => state.fetchTodos);
+  const fetchMoreTodos = useTodoStore(state => state.fetchMoreTodos);
   const createTodo = useTodoStore(state => state.createTodo);
   const storeToogleTodo = useTodoStore(state => state.toggleTodo);
   const storeDeleteTodo = useTodoStore(state => state.deleteTodo);
 
+  // 以序列化后的参数作为依赖，避免每次渲染传入新对象时重复请求
+  const paramsKey = JSON.stringify(params);
   useEffect(() => {
-    fetchTodos();
-  }, [fetchTodos]);
+    fetchTodos(JSON.parse(paramsKey));
+  }, [fetchTodos, paramsKey]);
 
   // 为了保持与现有代码的兼容性，我们提供一个 addTodo 函数
   const addTodo = async (title: string) => {
@@ -31,7 +39,11 @@ export function useTodos() {
 
   return {
     todos,
+    total,
+    hasMore: !!nextCursor,
     loading,
+    loadingMore,
+    loadMore: fetchMoreTodos,
     error,
     addTodo,
     toggleTodo,
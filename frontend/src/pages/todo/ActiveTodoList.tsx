@@ -6,7 +6,7 @@ import { Card } from "@/components/ui/card";
 import { useTranslation } from 'react-i18next';
 
 const ActiveTodoList: React.FC = () => {
-  const { todos, loading, error, toggleTodo, deleteTodo } = useTodos();
+  const { todos, total, hasMore, loading, loadingMore, loadMore, error, toggleTodo, deleteTodo } = useTodos({ completed: false });
   const { t } = useTranslation('todos');
   const activeTodos = todos.filter(todo => !todo.completed);
 
@@ -61,7 +61,7 @@ const ActiveTodoList: React.FC = () => {
               {t('list.active')}
             </h2>
             <p className="text-sm text-muted-foreground">
-              {t('list.activeCount', { count: activeTodos.length })}
+              {t('list.activeCount', { count: total })}
             </p>
           </div>
         </div>
@@ -70,6 +70,9 @@ const ActiveTodoList: React.FC = () => {
           todos={activeTodos}
           onToggle={toggleTodo}
           onDelete={deleteTodo}
+          hasMore={hasMore}
+          loadingMore={loadingMore}
+          onLoadMore={loadMore}
         />
       </div>
     </div>
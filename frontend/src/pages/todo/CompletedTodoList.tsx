@@ -7,7 +7,7 @@ import { useTranslation } from 'react-i18next';
 
 const CompletedTodoList: React.FC = () => {
   const { t } = useTranslation('todos');
-  const { todos, loading, error, toggleTodo, deleteTodo } = useTodos();
+  const { todos, total, hasMore, loading, loadingMore, loadMore, error, toggleTodo, deleteTodo } = useTodos({ completed: true });
   const completedTodos = todos.filter(todo => todo.completed);
 
   if (loading) {
@@ -57,7 +57,7 @@ const CompletedTodoList: React.FC = () => {
           <div className="space-y-1">
             <h2 className="text-xl font-semibold tracking-tight">{t('list.completed')}</h2>
             <p className="text-sm text-muted-foreground">
-              {t('list.completedCount', { count: completedTodos.length })}
+              {t('list.completedCount', { count: total })}
             </p>
           </div>
         </div>
@@ -66,6 +66,9 @@ const CompletedTodoList: React.FC = () => {
           todos={completedTodos}
           onToggle={toggleTodo}
           onDelete={deleteTodo}
+          hasMore={hasMore}
+          loadingMore={loadingMore}
+          onLoadMore={loadMore}
         />
       </div>
     </div>
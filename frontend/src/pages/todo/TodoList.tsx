@@ -1,14 +1,20 @@
+import { useState } from 'react';
 import { TodoForm } from '../../components/common/TodoForm';
 import { TodoList } from '../../components/common/TodoList';
 import { useTodos } from '../../hooks/useTodos';
-import { Loader2, AlertCircle } from "lucide-react";
+import { useTodoSearch } from '../../hooks/useTodoSearch';
+import { Loader2, AlertCircle, Search } from "lucide-react";
 import { Alert, AlertDescription } from "@/components/ui/alert";
 import { Card } from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
 import { useTranslation } from 'react-i18next';
 
 const TodoListPage: React.FC = () => {
-  const { todos, loading, error, addTodo, toggleTodo, deleteTodo } = useTodos();
+  const { todos, total, hasMore, loading, loadingMore, loadMore, error, addTodo, toggleTodo, deleteTodo } = useTodos();
+  const [query, setQuery] = useState('');
+  const search = useTodoSearch(query);
   const { t } = useTranslation('todos');
+  const searching = query.trim() !== '';
 
   if (loading) {
     return (
@@ -38,6 +44,16 @@ const TodoListPage: React.FC = () => {
 
   const completedCount = todos.filter(todo => todo.completed).length;
 
+  // 在搜索结果中修改后重新搜索，保持结果与列表一致
+  const toggleSearchResult = async (id: string) => {
+    await toggleTodo(id);
+    await search.refresh();
+  };
+  const deleteSearchResult = async (id: string) => {
+    await deleteTodo(id);
+    await search.refresh();
+  };
+
   return (
     <div className="max-w-4xl mx-auto p-6 md:p-8 space-y-8">
       <Card className="p-6 md:p-8 border-none bg-gradient-to-br from-primary/10 via-primary/5 to-background">
@@ -59,16 +75,54 @@ const TodoListPage: React.FC = () => {
             <div className="space-y-1">
               <h2 className="text-xl font-semibold tracking-tight">{t('title')}</h2>
               <p className="text-sm text-muted-foreground">
-                {t('list.summary', { total: todos.length, completed: completedCount })}
+                {searching
+                  ? t('search.summary', { count: search.total })
+                  : t('list.summary', { total, completed: completedCount })}
               </p>
             </div>
+            <div className="relative w-full max-w-xs">
+              <Search className="absolute left-3 top-1/2 h-4 w-4 -translate-y-1/2 text-muted-foreground" />
+              <Input
+                type="search"
+                value={query}
+                onChange={(e) => setQuery(e.target.value)}
+                placeholder={t('search.placeholder')}
+                className="pl-9"
+              />
+            </div>
           </div>
 
-          <TodoList
-            todos={todos}
-            onToggle={toggleTodo}
-            onDelete={deleteTodo}
-          />
+          {searching ? (
+            search.loading && search.results.length === 0 ? (
+              <div className="flex justify-center py-8">
+                <Loader2 className="h-6 w-6 animate-spin text-primary" />
+              </div>
+            ) : search.error ? (
+              <Alert variant="destructive">
+                <AlertCircle className="h-4 w-4" />
+                <AlertDescription>{search.error.message}</AlertDescription>
+              </Alert>
+            ) : (
+              <TodoList
+                todos={search.results}
+                highlights={search.highlights}
+                hasMore={search.hasMore}
+                loadingMore={search.loadingMore}
+                onLoadMore={search.loadMore}
+                onToggle={toggleSearchResult}
+                onDelete={deleteSearchResult}
+              />
+            )
+          ) : (
+            <TodoList
+              todos={todos}
+              hasMore={hasMore}
+              loadingMore={loadingMore}
+              onLoadMore={loadMore}
+              onToggle={toggleTodo}
+              onDelete={deleteTodo}
+            />
+          )}
         </div>
       </div>
     </div>
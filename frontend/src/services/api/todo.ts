@@ -1,22 +1,41 @@
 import http from '@/services/http';
-import { Todo, CreateTodoRequest, UpdateTodoRequest } from '@/types/todo';
+import {
+  Todo,
+  CreateTodoRequest,
+  UpdateTodoRequest,
+  ListTodosParams,
+  TodoListResponse,
+  SearchTodosParams,
+  TodoSearchResponse,
+} from '@/types/todo';
 
 export const todoApi = {
-  // 获取所有待办事项
-  async list(): Promise<Todo[]> {
+  // 分页获取待办事项
+  async list(params: ListTodosParams = {}): Promise<TodoListResponse> {
     try {
-      const response = await http.post<{ items: Todo[] }>('/api/todos/list');
-      return response.data.items;
+      const response = await http.get<TodoListResponse>('/api/v1/todos', { params });
+      return response.data;
     } catch (error) {
       console.error('获取待办事项列表失败:', error);
       throw error;
     }
   },
 
+  // 按相关度搜索待办事项
+  async search(params: SearchTodosParams): Promise<TodoSearchResponse> {
+    try {
+      const response = await http.get<TodoSearchResponse>('/api/v1/todos/search', { params });
+      return response.data;
+    } catch (error) {
+      console.error('搜索待办事项失败:', error);
+      throw error;
+    }
+  },
+
   // 获取单个待办事项
   async get(id: string): Promise<Todo> {
     try {
-      const response = await http.post<Todo>(`/api/todos/get/${id}`);
+      const response = await http.get<Todo>(`/api/v1/todos/${id}`);
       return response.data;
     } catch (error) {
       console.error(`获取待办事项 ${id} 失败:`, error);
@@ -27,7 +46,7 @@ export const todoApi = {
   // 创建待办事项
   async create(data: CreateTodoRequest): Promise<Todo> {
     try {
-      const response = await http.post<Todo>('/api/todos/create', data);
+      const response = await http.post<Todo>('/api/v1/todos', data);
       return response.data;
     } catch (error) {
       console.error('创建待办事项失败:', error);
@@ -38,7 +57,7 @@ export const todoApi = {
   // 更新待办事项
   async update(id: string, data: UpdateTodoRequest): Promise<Todo> {
     try {
-      const response = await http.post<Todo>(`/api/todos/update/${id}`, data);
+      const response = await http.patch<Todo>(`/api/v1/todos/${id}`, data);
       return response.data;
     } catch (error) {
       console.error(`更新待办事项 ${id} 失败:`, error);
@@ -49,7 +68,7 @@ export const todoApi = {
   // 切换待办事项状态
   async toggle(id: string): Promise<Todo> {
     try {
-      const response = await http.post<Todo>(`/api/todos/toggle/${id}`);
+      const response = await http.post<Todo>(`/api/v1/todos/${id}/toggle`);
       return response.data;
     } catch (error) {
       console.error(`切换待办事项 ${id} 状态失败:`, error);
@@ -60,7 +79,7 @@ export const todoApi = {
   // 删除待办事项
   async delete(id: string): Promise<void> {
     try {
-      await http.post(`/api/todos/delete/${id}`);
+      await http.delete(`/api/v1/todos/${id}`);
     } catch (error) {
       console.error(`删除待办事项 ${id} 失败:`, error);
       throw error;
@@ -0,0 +1,16 @@
+import { isAxiosError } from 'axios';
+import { ApiError, Violation } from '@/types/api';
+
+// 从请求错误中取出服务端返回的错误响应
+export function getApiError(error: unknown): ApiError | undefined {
+  if (isAxiosError<ApiError>(error) && error.response?.data?.code) {
+    return error.response.data;
+  }
+  return undefined;
+}
+
+// 从参数校验错误中取出指定字段违反的规则，消息已由服务端按当前语言本地化
+export function getFieldViolations(error: unknown, field: string): Violation[] {
+  const data = getApiError(error)?.data as { violations?: Violation[] } | undefined;
+  return (data?.violations ?? []).filter((violation) => violation.field === field);
+}
@@ -1,5 +1,6 @@
 import axios, { InternalAxiosRequestConfig, AxiosResponse } from 'axios';
 import useAuthStore from '@/stores/auth';
+import i18n from '@/config/i18n';
 
 // 创建 axios 实例
 const http = axios.create({
@@ -23,6 +24,11 @@ http.interceptors.request.use(
       isAuthenticated
     });
 
+    // 使用界面当前语言，服务端据此返回本地化的错误消息
+    if (i18n.language) {
+      config.headers['Accept-Language'] = i18n.language;
+    }
+
     // 如果有访问令牌，添加到请求头
     if (accessToken) {
       config.headers.Authorization = `Bearer ${accessToken}`;
@@ -1,14 +1,24 @@
 import { create } from 'zustand';
 import { todoApi } from '@/services/api/todo';
-import { Todo, CreateTodoRequest, UpdateTodoRequest } from '@/types/todo';
+import { Todo, CreateTodoRequest, UpdateTodoRequest, ListTodosParams } from '@/types/todo';
 
 interface TodoState {
   todos: Todo[];
+  // 符合当前过滤条件的总数
+  total: number;
+  // 下一页的游标，为空时没有更多数据
+  nextCursor?: string;
+  // 当前列表的查询参数，加载下一页时沿用
+  params: ListTodosParams;
   loading: boolean;
+  loadingMore: boolean;
   error: Error | null;
 
-  // 获取所有待办事项
-  fetchTodos: () => Promise<void>;
+  // 按查询参数获取第一页待办事项
+  fetchTodos: (params?: ListTodosParams) => Promise<void>;
+
+  // 加载下一页
+  fetchMoreTodos: () => Promise<void>;
   
   // 获取单个待办事项
   fetchTodo: (id: string) => Promise<Todo | undefined>;
@@ -28,19 +38,41 @@ interface TodoState {
 
 const useTodoStore = create<TodoState>((set, get) => ({
   todos: [],
+  total: 0,
+  nextCursor: undefined,
+  params: {},
   loading: false,
+  loadingMore: false,
   error: null,
 
-  fetchTodos: async () => {
-    set({ loading: true, error: null });
+  fetchTodos: async (params: ListTodosParams = {}) => {
+    set({ loading: true, error: null, params });
     try {
-      const todos = await todoApi.list();
-      set({ todos, loading: false });
+      const page = await todoApi.list(params);
+      set({ todos: page.items, total: page.total, nextCursor: page.nextCursor, loading: false });
     } catch (error) {
       set({ error: error as Error, loading: false });
     }
   },
 
+  fetchMoreTodos: async () => {
+    const { nextCursor, params, loadingMore } = get();
+    if (!nextCursor || loadingMore) return;
+
+    set({ loadingMore: true, error: null });
+    try {
+      const page = await todoApi.list({ ...params, cursor: nextCursor });
+      set((state) => ({
+        todos: [...state.todos, ...page.items.filter((item) => !state.todos.some((todo) => todo.id === item.id))],
+        total: page.total,
+        nextCursor: page.nextCursor,
+        loadingMore: false,
+      }));
+    } catch (error) {
+      set({ error: error as Error, loadingMore: false });
+    }
+  },
+
   fetchTodo: async (id: string) => {
     set({ loading: true, error: null });
     try {
@@ -58,7 +90,8 @@ const useTodoStore = create<TodoState>((set, get) => ({
     try {
       const newTodo = await todoApi.create(data);
       set((state) => ({ 
-        todos: [...state.todos, newTodo],
+        todos: [newTodo, ...state.todos],
+        total: state.total + 1,
         loading: false 
       }));
       return newTodo;
@@ -108,6 +141,7 @@ const useTodoStore = create<TodoState>((set, get) => ({
       await todoApi.delete(id);
       set((state) => ({
         todos: state.todos.filter((todo) => todo.id !== id),
+        total: Math.max(0, state.total - 1),
         loading: false
       }));
     } catch (error) {
@@ -0,0 +1,13 @@
+// 服务端统一的错误响应
+export interface ApiError {
+  code: number;
+  message: string;
+  data?: unknown;
+}
+
+// 参数校验失败时违反的单条规则
+export interface Violation {
+  field: string;
+  rule: string;
+  message: string;
+}
@@ -2,6 +2,9 @@ export interface Todo {
   id: string;
   title: string;
   completed: boolean;
+  // 截止时间和提醒时间，未设置时为 null
+  dueAt: string | null;
+  remindAt: string | null;
   createdAt: string;
   updatedAt: string;
 }
@@ -9,11 +12,66 @@ export interface Todo {
 export interface CreateTodoRequest {
   title: string;
   completed?: boolean;
+  dueAt?: string | null;
+  remindAt?: string | null;
 }
 
+// 时间字段为 null 时清除，不传时保持不变
 export interface UpdateTodoRequest {
   title?: string;
   completed?: boolean;
+  dueAt?: string | null;
+  remindAt?: string | null;
+}
+
+// 列表查询参数，时间使用 ISO 8601 格式
+export interface ListTodosParams {
+  limit?: number;
+  cursor?: string;
+  completed?: boolean;
+  created_after?: string;
+  created_before?: string;
+  updated_after?: string;
+  updated_before?: string;
+  // 按截止时间过滤，今天和本周按 tz 时区计算
+  due?: 'overdue' | 'today' | 'week';
+  tz?: string;
+  sort?: 'created_at' | 'updated_at' | 'title';
+  order?: 'asc' | 'desc';
+}
+
+// 列表响应，nextCursor 为空表示没有更多数据
+export interface TodoListResponse {
+  items: Todo[];
+  nextCursor?: string;
+  total: number;
+}
+
+// 搜索参数，q 为查询串
+export interface SearchTodosParams {
+  q: string;
+  limit?: number;
+  cursor?: string;
+  completed?: boolean;
+}
+
+// 文本中需要高亮的区间，按 Unicode 字符计算，包含 start、不包含 end
+export interface TextRange {
+  start: number;
+  end: number;
+}
+
+// 一条搜索结果，highlights 按字段名列出高亮区间
+export interface TodoSearchResult extends Todo {
+  score: number;
+  highlights: Record<string, TextRange[]>;
+}
+
+// 搜索响应，分页方式与列表相同
+export interface TodoSearchResponse {
+  items: TodoSearchResult[];
+  nextCursor?: string;
+  total: number;
 }
 
 export interface TodoFormProps {
@@ -24,10 +82,16 @@ export interface TodoListProps {
   todos: Todo[];
   onToggle: (id: string) => Promise<void>;
   onDelete: (id: string) => Promise<void>;
+  // 按待办事项 ID 列出标题中需要高亮的区间，用于显示搜索结果
+  highlights?: Record<string, TextRange[]>;
+  hasMore?: boolean;
+  loadingMore?: boolean;
+  onLoadMore?: () => Promise<void>;
 }
 
 export interface TodoItemProps {
   todo: Todo;
+  highlights?: TextRange[];
   onToggle: () => Promise<void>;
   onDelete: () => Promise<void>;
 } 
\ No newline at end of file